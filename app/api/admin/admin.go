@@ -0,0 +1,427 @@
+package admin
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/n3k0fi5t/wallet/app/api/apierror"
+	"github.com/n3k0fi5t/wallet/app/middleware"
+	mAdmin "github.com/n3k0fi5t/wallet/app/models/admin"
+	mAudit "github.com/n3k0fi5t/wallet/app/models/audit"
+	mBank "github.com/n3k0fi5t/wallet/app/models/bank"
+	"github.com/n3k0fi5t/wallet/app/money"
+	"github.com/n3k0fi5t/wallet/app/service/admin"
+)
+
+const (
+	defaultLimit = 50
+	maxLimit     = 500
+)
+
+var (
+	errInvalidOffset = fmt.Errorf("invalid offset")
+	errInvalidLimit  = fmt.Errorf("invalid limit")
+	errInvalidTime   = fmt.Errorf("invalid time range")
+)
+
+// NewHandler ...
+func NewHandler(a admin.Service) *Handler {
+	return &Handler{
+		adminSrv: a,
+	}
+}
+
+type Handler struct {
+	adminSrv admin.Service
+}
+
+func (h *Handler) Handle(routerGroup *gin.RouterGroup) {
+	rg := routerGroup.Group("/admin")
+
+	// APIs are only for authed staff, permission of each action is checked by role in service
+	rg.Use(middleware.GetAdminOperator())
+
+	// account relative
+	arg := rg.Group("/accounts/:accountID")
+	arg.Handle("GET", "", h.getAccount)
+	arg.Handle("GET", "/transactions", h.listTransactions)
+	arg.Handle("POST", "/adjust", h.adjustBalance)
+	arg.Handle("POST", "/freeze", h.freezeAccount)
+	arg.Handle("POST", "/unfreeze", h.unfreezeAccount)
+	arg.Handle("POST", "/credit", h.setCreditLimit)
+	arg.Handle("POST", "/credit/revoke", h.revokeCreditLimit)
+	rg.Handle("GET", "/overdrafts", h.listOverdrawn)
+	rg.Handle("GET", "/system-accounts", h.listSystemAccounts)
+
+	// trade relative
+	trg := rg.Group("/trades/:tradeID")
+	trg.Handle("POST", "/refund", h.refund)
+
+	// audit trail
+	rg.Handle("GET", "/audit", h.listAuditEntries)
+}
+
+// errorStatus maps service errors to HTTP status code
+func errorStatus(err error) int {
+	switch err {
+	case admin.ErrPermissionDenied:
+		return http.StatusForbidden
+	case admin.ErrInvalidReasonCode, admin.ErrInvalidAmount, admin.ErrInvalidCreditLimit:
+		return http.StatusBadRequest
+	case admin.ErrTradeNotRefundable, admin.ErrCreditNotAllowed:
+		return http.StatusConflict
+	default:
+		return apierror.Status(err)
+	}
+}
+
+func responseError(c *gin.Context, code int, err error) {
+	apierror.Response(c, code, err, nil)
+}
+
+// parsePage parses offset and limit from query string
+func parsePage(c *gin.Context) (int, int, error) {
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		return 0, 0, errInvalidOffset
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultLimit)))
+	if err != nil || limit <= 0 || limit > maxLimit {
+		return 0, 0, errInvalidLimit
+	}
+	return offset, limit, nil
+}
+
+type accountResp struct {
+	AccountID   string      `json:"accountID"`
+	Balance     money.Money `json:"balance"`
+	CreditLimit money.Money `json:"creditLimit"`
+	Available   money.Money `json:"available"`
+	Overdrawn   bool        `json:"overdrawn"`
+	Frozen      bool        `json:"frozen"`
+	// AllowNegative is set on system accounts whose balance may go below zero by policy
+	AllowNegative bool `json:"allowNegative"`
+}
+
+func newAccountResp(account *mBank.Account) accountResp {
+	return accountResp{
+		AccountID:     account.AccountID,
+		Balance:       account.Balance,
+		CreditLimit:   account.CreditLimit,
+		Available:     account.Available(),
+		Overdrawn:     account.Overdrawn(),
+		Frozen:        account.Status == mBank.AccountStatus_FROZEN,
+		AllowNegative: account.AllowNegative,
+	}
+}
+
+func (h *Handler) getAccount(c *gin.Context) {
+	ctx := c.MustGet("ctx").(context.Context)
+	operator := c.MustGet("operator").(*mAdmin.Operator)
+
+	account, err := h.adminSrv.GetAccount(ctx, operator, c.Param("accountID"))
+	if err != nil {
+		responseError(c, errorStatus(err), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newAccountResp(account))
+}
+
+type transactionResp struct {
+	TradeID     string      `json:"tradeID"`
+	Debit       bool        `json:"debit"`
+	Amount      money.Money `json:"amount"`
+	TimestampMs int64       `json:"timestampMs"`
+}
+
+type listTransactionsResp struct {
+	Transactions []transactionResp `json:"transactions"`
+}
+
+func (h *Handler) listTransactions(c *gin.Context) {
+	ctx := c.MustGet("ctx").(context.Context)
+	operator := c.MustGet("operator").(*mAdmin.Operator)
+
+	offset, limit, err := parsePage(c)
+	if err != nil {
+		responseError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	txs, err := h.adminSrv.ListTransactions(ctx, operator, c.Param("accountID"), offset, limit)
+	if err != nil {
+		responseError(c, errorStatus(err), err)
+		return
+	}
+
+	resp := listTransactionsResp{
+		Transactions: make([]transactionResp, 0, len(txs)),
+	}
+	for _, tx := range txs {
+		resp.Transactions = append(resp.Transactions, transactionResp{
+			TradeID:     tx.TradeID,
+			Debit:       tx.IsDebit(),
+			Amount:      tx.Amount,
+			TimestampMs: tx.TimestampMs,
+		})
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+type adjustBalanceParam struct {
+	Amount     money.Money       `json:"amount" binding:"required"`
+	ReasonCode mAdmin.ReasonCode `json:"reasonCode" binding:"required"`
+	Note       string            `json:"note"`
+}
+
+type tradeResp struct {
+	TradeID string `json:"tradeID"`
+}
+
+func (h *Handler) adjustBalance(c *gin.Context) {
+	ctx := c.MustGet("ctx").(context.Context)
+	operator := c.MustGet("operator").(*mAdmin.Operator)
+
+	param := adjustBalanceParam{}
+	if err := c.ShouldBindBodyWith(&param, binding.JSON); err != nil {
+		responseError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	tradeID, err := h.adminSrv.AdjustBalance(ctx, operator, c.Param("accountID"), param.Amount, param.ReasonCode, param.Note)
+	if err != nil {
+		responseError(c, errorStatus(err), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tradeResp{TradeID: tradeID})
+}
+
+type reasonParam struct {
+	ReasonCode mAdmin.ReasonCode `json:"reasonCode" binding:"required"`
+	Note       string            `json:"note"`
+}
+
+func (h *Handler) freezeAccount(c *gin.Context) {
+	ctx := c.MustGet("ctx").(context.Context)
+	operator := c.MustGet("operator").(*mAdmin.Operator)
+
+	param := reasonParam{}
+	if err := c.ShouldBindBodyWith(&param, binding.JSON); err != nil {
+		responseError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.adminSrv.FreezeAccount(ctx, operator, c.Param("accountID"), param.ReasonCode, param.Note); err != nil {
+		responseError(c, errorStatus(err), err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handler) unfreezeAccount(c *gin.Context) {
+	ctx := c.MustGet("ctx").(context.Context)
+	operator := c.MustGet("operator").(*mAdmin.Operator)
+
+	param := reasonParam{}
+	if err := c.ShouldBindBodyWith(&param, binding.JSON); err != nil {
+		responseError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.adminSrv.UnfreezeAccount(ctx, operator, c.Param("accountID"), param.ReasonCode, param.Note); err != nil {
+		responseError(c, errorStatus(err), err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handler) refund(c *gin.Context) {
+	ctx := c.MustGet("ctx").(context.Context)
+	operator := c.MustGet("operator").(*mAdmin.Operator)
+
+	param := reasonParam{}
+	if err := c.ShouldBindBodyWith(&param, binding.JSON); err != nil {
+		responseError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	tradeID, err := h.adminSrv.Refund(ctx, operator, c.Param("tradeID"), param.ReasonCode, param.Note)
+	if err != nil {
+		responseError(c, errorStatus(err), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tradeResp{TradeID: tradeID})
+}
+
+type creditLimitParam struct {
+	CreditLimit money.Money       `json:"creditLimit"`
+	ReasonCode  mAdmin.ReasonCode `json:"reasonCode" binding:"required"`
+	Note        string            `json:"note"`
+}
+
+func (h *Handler) setCreditLimit(c *gin.Context) {
+	ctx := c.MustGet("ctx").(context.Context)
+	operator := c.MustGet("operator").(*mAdmin.Operator)
+
+	param := creditLimitParam{}
+	if err := c.ShouldBindBodyWith(&param, binding.JSON); err != nil {
+		responseError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	account, err := h.adminSrv.SetCreditLimit(ctx, operator, c.Param("accountID"), param.CreditLimit, param.ReasonCode, param.Note)
+	if err != nil {
+		responseError(c, errorStatus(err), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newAccountResp(account))
+}
+
+// revokeCreditLimit sets the credit limit to 0, it fails while the account is overdrawn
+func (h *Handler) revokeCreditLimit(c *gin.Context) {
+	ctx := c.MustGet("ctx").(context.Context)
+	operator := c.MustGet("operator").(*mAdmin.Operator)
+
+	param := reasonParam{}
+	if err := c.ShouldBindBodyWith(&param, binding.JSON); err != nil {
+		responseError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	account, err := h.adminSrv.RevokeCreditLimit(ctx, operator, c.Param("accountID"), param.ReasonCode, param.Note)
+	if err != nil {
+		responseError(c, errorStatus(err), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newAccountResp(account))
+}
+
+type listAccountsResp struct {
+	Accounts []accountResp `json:"accounts"`
+}
+
+// listOverdrawn lists accounts with a negative balance, the most overdrawn first
+func (h *Handler) listOverdrawn(c *gin.Context) {
+	ctx := c.MustGet("ctx").(context.Context)
+	operator := c.MustGet("operator").(*mAdmin.Operator)
+
+	offset, limit, err := parsePage(c)
+	if err != nil {
+		responseError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	accounts, err := h.adminSrv.ListOverdrawn(ctx, operator, offset, limit)
+	if err != nil {
+		responseError(c, errorStatus(err), err)
+		return
+	}
+
+	resp := listAccountsResp{
+		Accounts: make([]accountResp, 0, len(accounts)),
+	}
+	for _, account := range accounts {
+		resp.Accounts = append(resp.Accounts, newAccountResp(account))
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// listSystemAccounts lists the chart of system accounts, per currency all balances of the bank add up to zero
+func (h *Handler) listSystemAccounts(c *gin.Context) {
+	ctx := c.MustGet("ctx").(context.Context)
+	operator := c.MustGet("operator").(*mAdmin.Operator)
+
+	accounts, err := h.adminSrv.ListSystemAccounts(ctx, operator)
+	if err != nil {
+		responseError(c, errorStatus(err), err)
+		return
+	}
+
+	resp := listAccountsResp{
+		Accounts: make([]accountResp, 0, len(accounts)),
+	}
+	for _, account := range accounts {
+		resp.Accounts = append(resp.Accounts, newAccountResp(account))
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+type auditEntryResp struct {
+	ActorType   mAudit.ActorType `json:"actorType"`
+	ActorID     string           `json:"actorID"`
+	Principal   string           `json:"principal"`
+	RequestID   string           `json:"requestID"`
+	ClientIP    string           `json:"clientIP"`
+	UserAgent   string           `json:"userAgent"`
+	Action      mAudit.Action    `json:"action"`
+	Resource    string           `json:"resource"`
+	Before      string           `json:"before"`
+	After       string           `json:"after"`
+	TimestampMs int64            `json:"timestampMs"`
+}
+
+type listAuditEntriesResp struct {
+	Entries []auditEntryResp `json:"entries"`
+}
+
+func (h *Handler) listAuditEntries(c *gin.Context) {
+	ctx := c.MustGet("ctx").(context.Context)
+	operator := c.MustGet("operator").(*mAdmin.Operator)
+
+	offset, limit, err := parsePage(c)
+	if err != nil {
+		responseError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	filter := &mAudit.Filter{
+		ActorID:  c.Query("actorID"),
+		Action:   mAudit.Action(c.Query("action")),
+		Resource: c.Query("resource"),
+	}
+	if filter.FromMs, err = strconv.ParseInt(c.DefaultQuery("fromMs", "0"), 10, 64); err != nil {
+		responseError(c, http.StatusBadRequest, errInvalidTime)
+		return
+	}
+	if filter.ToMs, err = strconv.ParseInt(c.DefaultQuery("toMs", "0"), 10, 64); err != nil {
+		responseError(c, http.StatusBadRequest, errInvalidTime)
+		return
+	}
+
+	entries, err := h.adminSrv.ListAuditEntries(ctx, operator, filter, offset, limit)
+	if err != nil {
+		responseError(c, errorStatus(err), err)
+		return
+	}
+
+	resp := listAuditEntriesResp{
+		Entries: make([]auditEntryResp, 0, len(entries)),
+	}
+	for _, e := range entries {
+		resp.Entries = append(resp.Entries, auditEntryResp{
+			ActorType:   e.ActorType,
+			ActorID:     e.ActorID,
+			Principal:   e.Principal,
+			RequestID:   e.RequestID,
+			ClientIP:    e.ClientIP,
+			UserAgent:   e.UserAgent,
+			Action:      e.Action,
+			Resource:    e.Resource,
+			Before:      e.Before,
+			After:       e.After,
+			TimestampMs: e.TimestampMs,
+		})
+	}
+	c.JSON(http.StatusOK, resp)
+}
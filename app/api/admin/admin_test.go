@@ -0,0 +1,470 @@
+package admin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/n3k0fi5t/wallet/app/api/apitest"
+	mdAdmin "github.com/n3k0fi5t/wallet/app/models/admin"
+	mdAudit "github.com/n3k0fi5t/wallet/app/models/audit"
+	mdBank "github.com/n3k0fi5t/wallet/app/models/bank"
+	"github.com/n3k0fi5t/wallet/app/money"
+	"github.com/n3k0fi5t/wallet/app/repository/bank"
+	"github.com/n3k0fi5t/wallet/app/service/admin"
+	mockSrv "github.com/n3k0fi5t/wallet/app/service/admin/mocks"
+)
+
+var (
+	mockCtx       = context.Background()
+	mockAccountID = "935f871a-660f-4f19-801e-916c04bb0324"
+	mockTradeID   = "a89b7b78-b9c1-4129-8cff-380bf53f3a49"
+	mockSupport   = &mdAdmin.Operator{OperatorID: "Mori", Role: mdAdmin.Role_SUPPORT}
+	mockFinance   = &mdAdmin.Operator{OperatorID: "Fauna", Role: mdAdmin.Role_FINANCE}
+	mockAuditor   = &mdAdmin.Operator{OperatorID: "Bae", Role: mdAdmin.Role_AUDITOR}
+	mockAccount   = &mdBank.Account{
+		AccountID:   mockAccountID,
+		Balance:     usd(3345678),
+		CreditLimit: usd(0),
+		Status:      mdBank.AccountStatus_FROZEN,
+	}
+
+	mockHandleCtxMiddleware = func() gin.HandlerFunc {
+		return func(c *gin.Context) {
+			c.Set("ctx", mockCtx)
+			c.Next()
+		}
+	}
+)
+
+type testSuite struct {
+	suite.Suite
+
+	router  *gin.Engine
+	mockSrv *mockSrv.Service
+	asrv    admin.Service
+}
+
+func (s *testSuite) SetupSuite() {
+	s.router = gin.Default()
+	s.mockSrv = &mockSrv.Service{}
+	s.asrv = s.mockSrv
+	handler := NewHandler(s.asrv)
+	rg := s.router.Group("/api/v1")
+	rg.Use(mockHandleCtxMiddleware())
+	handler.Handle(rg)
+}
+
+func (s *testSuite) TearDownSuite() {
+	s.mockSrv.AssertExpectations(s.T())
+}
+
+func TestSuite(t *testing.T) {
+	suite.Run(t, new(testSuite))
+}
+
+func (s *testSuite) TestGetAccount() {
+	tests := []struct {
+		Desc       string
+		ExpCode    int
+		Auth       string
+		setup      func()
+		ExpAccount accountResp
+	}{
+		{
+			Desc: "normal case",
+			setup: func() {
+				s.mockSrv.On("GetAccount", mockCtx, mockSupport, mockAccountID).Return(mockAccount, nil).Once()
+			},
+			Auth:       "Mori",
+			ExpCode:    http.StatusOK,
+			ExpAccount: accountResp{AccountID: mockAccountID, Balance: mockAccount.Balance, CreditLimit: usd(0), Available: mockAccount.Balance, Frozen: true},
+		},
+		{
+			Desc: "not found case",
+			setup: func() {
+				s.mockSrv.On("GetAccount", mockCtx, mockSupport, mockAccountID).Return(nil, bank.ErrAccountNotExist).Once()
+			},
+			Auth:    "Mori",
+			ExpCode: http.StatusNotFound,
+		},
+		{
+			Desc:    "user token is not a staff token",
+			Auth:    "Tim",
+			ExpCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, t := range tests {
+		if t.setup != nil {
+			t.setup()
+		}
+
+		header := apitest.RequestHeader()
+		header.Set("Authorization", t.Auth)
+
+		req, err := http.NewRequest("GET", "/api/v1/admin/accounts/"+mockAccountID, nil)
+		s.Require().NoError(err, t.Desc)
+		req.Header = header
+
+		rr := httptest.NewRecorder()
+		s.router.ServeHTTP(rr, req)
+		s.Require().Equal(t.ExpCode, rr.Code, t.Desc)
+
+		if t.ExpCode == http.StatusOK {
+			var resp accountResp
+			err = json.Unmarshal(rr.Body.Bytes(), &resp)
+			s.Require().NoError(err)
+			s.Require().Equal(t.ExpAccount, resp, t.Desc)
+		}
+	}
+}
+
+func (s *testSuite) TestListTransactions() {
+	tests := []struct {
+		Desc    string
+		Query   string
+		ExpCode int
+		setup   func()
+	}{
+		{
+			Desc:  "normal case",
+			Query: "?offset=10&limit=5",
+			setup: func() {
+				s.mockSrv.On("ListTransactions", mockCtx, mockSupport, mockAccountID, 10, 5).Return([]*mdBank.Transaction{}, nil).Once()
+			},
+			ExpCode: http.StatusOK,
+		},
+		{
+			Desc:    "bad limit",
+			Query:   "?limit=100000",
+			ExpCode: http.StatusBadRequest,
+		},
+		{
+			Desc:    "bad offset",
+			Query:   "?offset=-1",
+			ExpCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, t := range tests {
+		if t.setup != nil {
+			t.setup()
+		}
+
+		header := apitest.RequestHeader()
+		header.Set("Authorization", "Mori")
+
+		req, err := http.NewRequest("GET", "/api/v1/admin/accounts/"+mockAccountID+"/transactions"+t.Query, nil)
+		s.Require().NoError(err, t.Desc)
+		req.Header = header
+
+		rr := httptest.NewRecorder()
+		s.router.ServeHTTP(rr, req)
+		s.Require().Equal(t.ExpCode, rr.Code, t.Desc)
+	}
+}
+
+func (s *testSuite) TestAdjustBalance() {
+	genPayload := func(d adjustBalanceParam) []byte {
+		b, err := json.Marshal(d)
+		s.Require().NoError(err)
+		return b
+	}
+
+	tests := []struct {
+		Desc    string
+		Payload []byte
+		ExpCode int
+		Auth    string
+		setup   func()
+	}{
+		{
+			Desc: "normal case",
+			setup: func() {
+				s.mockSrv.On("AdjustBalance", mockCtx, mockFinance, mockAccountID, usd(-1000), mdAdmin.ReasonCode_CORRECTION, "typo").Return(mockTradeID, nil).Once()
+			},
+			Payload: genPayload(adjustBalanceParam{Amount: usd(-1000), ReasonCode: mdAdmin.ReasonCode_CORRECTION, Note: "typo"}),
+			Auth:    "Fauna",
+			ExpCode: http.StatusOK,
+		},
+		{
+			Desc: "forbidden case",
+			setup: func() {
+				s.mockSrv.On("AdjustBalance", mockCtx, mockSupport, mockAccountID, usd(1000), mdAdmin.ReasonCode_GOODWILL, "").Return("", admin.ErrPermissionDenied).Once()
+			},
+			Payload: genPayload(adjustBalanceParam{Amount: usd(1000), ReasonCode: mdAdmin.ReasonCode_GOODWILL}),
+			Auth:    "Mori",
+			ExpCode: http.StatusForbidden,
+		},
+		{
+			Desc: "failed case",
+			setup: func() {
+				s.mockSrv.On("AdjustBalance", mockCtx, mockFinance, mockAccountID, usd(1), mdAdmin.ReasonCode_GOODWILL, "").Return("", fmt.Errorf("")).Once()
+			},
+			Payload: genPayload(adjustBalanceParam{Amount: usd(1), ReasonCode: mdAdmin.ReasonCode_GOODWILL}),
+			Auth:    "Fauna",
+			ExpCode: http.StatusInternalServerError,
+		},
+		{
+			Desc:    "bad param",
+			Payload: genPayload(adjustBalanceParam{Amount: usd(1000)}),
+			Auth:    "Fauna",
+			ExpCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, t := range tests {
+		if t.setup != nil {
+			t.setup()
+		}
+
+		header := apitest.RequestHeader()
+		header.Set("Authorization", t.Auth)
+
+		req, err := http.NewRequest("POST", "/api/v1/admin/accounts/"+mockAccountID+"/adjust", bytes.NewBuffer(t.Payload))
+		s.Require().NoError(err, t.Desc)
+		req.Header = header
+
+		rr := httptest.NewRecorder()
+		s.router.ServeHTTP(rr, req)
+		s.Require().Equal(t.ExpCode, rr.Code, t.Desc)
+	}
+}
+
+func (s *testSuite) TestRefund() {
+	genPayload := func(d reasonParam) []byte {
+		b, err := json.Marshal(d)
+		s.Require().NoError(err)
+		return b
+	}
+
+	tests := []struct {
+		Desc    string
+		Payload []byte
+		ExpCode int
+		setup   func()
+	}{
+		{
+			Desc: "normal case",
+			setup: func() {
+				s.mockSrv.On("Refund", mockCtx, mockFinance, mockTradeID, mdAdmin.ReasonCode_CHARGEBACK, "").Return(mockAccountID, nil).Once()
+			},
+			Payload: genPayload(reasonParam{ReasonCode: mdAdmin.ReasonCode_CHARGEBACK}),
+			ExpCode: http.StatusOK,
+		},
+		{
+			Desc: "already refunded",
+			setup: func() {
+				s.mockSrv.On("Refund", mockCtx, mockFinance, mockTradeID, mdAdmin.ReasonCode_FRAUD, "").Return("", bank.ErrTradeAlreadyReversed).Once()
+			},
+			Payload: genPayload(reasonParam{ReasonCode: mdAdmin.ReasonCode_FRAUD}),
+			ExpCode: http.StatusConflict,
+		},
+	}
+
+	for _, t := range tests {
+		if t.setup != nil {
+			t.setup()
+		}
+
+		header := apitest.RequestHeader()
+		header.Set("Authorization", "Fauna")
+
+		req, err := http.NewRequest("POST", "/api/v1/admin/trades/"+mockTradeID+"/refund", bytes.NewBuffer(t.Payload))
+		s.Require().NoError(err, t.Desc)
+		req.Header = header
+
+		rr := httptest.NewRecorder()
+		s.router.ServeHTTP(rr, req)
+		s.Require().Equal(t.ExpCode, rr.Code, t.Desc)
+	}
+}
+
+func (s *testSuite) TestSetCreditLimit() {
+	genPayload := func(d interface{}) []byte {
+		b, err := json.Marshal(d)
+		s.Require().NoError(err)
+		return b
+	}
+
+	tests := []struct {
+		Desc       string
+		Path       string
+		Payload    []byte
+		ExpCode    int
+		setup      func()
+		ExpAccount accountResp
+	}{
+		{
+			Desc: "normal case",
+			Path: "/credit",
+			setup: func() {
+				account := &mdBank.Account{AccountID: mockAccountID, Balance: usd(-300), CreditLimit: usd(1000)}
+				s.mockSrv.On("SetCreditLimit", mockCtx, mockFinance, mockAccountID, usd(1000), mdAdmin.ReasonCode_CORRECTION, "").Return(account, nil).Once()
+			},
+			Payload:    genPayload(creditLimitParam{CreditLimit: usd(1000), ReasonCode: mdAdmin.ReasonCode_CORRECTION}),
+			ExpCode:    http.StatusOK,
+			ExpAccount: accountResp{AccountID: mockAccountID, Balance: usd(-300), CreditLimit: usd(1000), Available: usd(700), Overdrawn: true},
+		},
+		{
+			Desc: "negative limit",
+			Path: "/credit",
+			setup: func() {
+				s.mockSrv.On("SetCreditLimit", mockCtx, mockFinance, mockAccountID, usd(-1), mdAdmin.ReasonCode_CORRECTION, "").Return(nil, admin.ErrInvalidCreditLimit).Once()
+			},
+			Payload: genPayload(creditLimitParam{CreditLimit: usd(-1), ReasonCode: mdAdmin.ReasonCode_CORRECTION}),
+			ExpCode: http.StatusBadRequest,
+		},
+		{
+			Desc:    "missing reason code",
+			Path:    "/credit",
+			Payload: genPayload(creditLimitParam{CreditLimit: usd(1000)}),
+			ExpCode: http.StatusBadRequest,
+		},
+		{
+			Desc: "revoke while overdrawn",
+			Path: "/credit/revoke",
+			setup: func() {
+				s.mockSrv.On("RevokeCreditLimit", mockCtx, mockFinance, mockAccountID, mdAdmin.ReasonCode_FRAUD, "").Return(nil, bank.ErrCreditLimitInUse).Once()
+			},
+			Payload: genPayload(reasonParam{ReasonCode: mdAdmin.ReasonCode_FRAUD}),
+			ExpCode: http.StatusConflict,
+		},
+	}
+
+	for _, t := range tests {
+		if t.setup != nil {
+			t.setup()
+		}
+
+		header := apitest.RequestHeader()
+		header.Set("Authorization", "Fauna")
+
+		req, err := http.NewRequest("POST", "/api/v1/admin/accounts/"+mockAccountID+t.Path, bytes.NewBuffer(t.Payload))
+		s.Require().NoError(err, t.Desc)
+		req.Header = header
+
+		rr := httptest.NewRecorder()
+		s.router.ServeHTTP(rr, req)
+		s.Require().Equal(t.ExpCode, rr.Code, t.Desc)
+
+		if t.ExpCode == http.StatusOK {
+			var resp accountResp
+			err = json.Unmarshal(rr.Body.Bytes(), &resp)
+			s.Require().NoError(err)
+			s.Require().Equal(t.ExpAccount, resp, t.Desc)
+		}
+	}
+}
+
+func (s *testSuite) TestListOverdrawn() {
+	accounts := []*mdBank.Account{{AccountID: mockAccountID, Balance: usd(-300), CreditLimit: usd(1000)}}
+	s.mockSrv.On("ListOverdrawn", mockCtx, mockSupport, 0, defaultLimit).Return(accounts, nil).Once()
+
+	header := apitest.RequestHeader()
+	header.Set("Authorization", "Mori")
+
+	req, err := http.NewRequest("GET", "/api/v1/admin/overdrafts", nil)
+	s.Require().NoError(err)
+	req.Header = header
+
+	rr := httptest.NewRecorder()
+	s.router.ServeHTTP(rr, req)
+	s.Require().Equal(http.StatusOK, rr.Code)
+
+	var resp listAccountsResp
+	s.Require().NoError(json.Unmarshal(rr.Body.Bytes(), &resp))
+	s.Require().Equal([]accountResp{
+		{AccountID: mockAccountID, Balance: usd(-300), CreditLimit: usd(1000), Available: usd(700), Overdrawn: true},
+	}, resp.Accounts)
+}
+
+func (s *testSuite) TestListSystemAccounts() {
+	accounts := []*mdBank.Account{{AccountID: "sys-suspense-USD", Balance: usd(-300), CreditLimit: usd(0), Type: mdBank.AccountType_SYSTEM, AllowNegative: true}}
+	s.mockSrv.On("ListSystemAccounts", mockCtx, mockSupport).Return(accounts, nil).Once()
+
+	header := apitest.RequestHeader()
+	header.Set("Authorization", "Mori")
+
+	req, err := http.NewRequest("GET", "/api/v1/admin/system-accounts", nil)
+	s.Require().NoError(err)
+	req.Header = header
+
+	rr := httptest.NewRecorder()
+	s.router.ServeHTTP(rr, req)
+	s.Require().Equal(http.StatusOK, rr.Code)
+
+	var resp listAccountsResp
+	s.Require().NoError(json.Unmarshal(rr.Body.Bytes(), &resp))
+	s.Require().Equal([]accountResp{
+		{AccountID: "sys-suspense-USD", Balance: usd(-300), CreditLimit: usd(0), Available: usd(math.MaxInt64), AllowNegative: true},
+	}, resp.Accounts)
+}
+
+func (s *testSuite) TestListAuditEntries() {
+	tests := []struct {
+		Desc    string
+		Auth    string
+		Query   string
+		ExpCode int
+		setup   func()
+	}{
+		{
+			Desc:  "normal case",
+			Auth:  "Bae",
+			Query: "?actorID=Fauna&action=TRADE&fromMs=1&toMs=2",
+			setup: func() {
+				filter := &mdAudit.Filter{ActorID: "Fauna", Action: mdAudit.Action_TRADE, FromMs: 1, ToMs: 2}
+				s.mockSrv.On("ListAuditEntries", mockCtx, mockAuditor, filter, 0, defaultLimit).Return([]*mdAudit.Entry{}, nil).Once()
+			},
+			ExpCode: http.StatusOK,
+		},
+		{
+			Desc:  "forbidden case",
+			Auth:  "Mori",
+			Query: "?resource=account:1",
+			setup: func() {
+				filter := &mdAudit.Filter{Resource: "account:1"}
+				s.mockSrv.On("ListAuditEntries", mockCtx, mockSupport, filter, 0, defaultLimit).Return(nil, admin.ErrPermissionDenied).Once()
+			},
+			ExpCode: http.StatusForbidden,
+		},
+		{
+			Desc:    "bad time range",
+			Auth:    "Bae",
+			Query:   "?fromMs=yesterday",
+			ExpCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, t := range tests {
+		if t.setup != nil {
+			t.setup()
+		}
+
+		header := apitest.RequestHeader()
+		header.Set("Authorization", t.Auth)
+
+		req, err := http.NewRequest("GET", "/api/v1/admin/audit"+t.Query, nil)
+		s.Require().NoError(err, t.Desc)
+		req.Header = header
+
+		rr := httptest.NewRecorder()
+		s.router.ServeHTTP(rr, req)
+		s.Require().Equal(t.ExpCode, rr.Code, t.Desc)
+	}
+}
+
+func usd(amount int64) money.Money {
+	return money.New(amount, "USD")
+}
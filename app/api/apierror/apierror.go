@@ -0,0 +1,83 @@
+package apierror
+
+import (
+	"net/http"
+	"reflect"
+
+	"github.com/gin-gonic/gin"
+	"github.com/n3k0fi5t/wallet/app/money"
+	"github.com/n3k0fi5t/wallet/app/repository/bank"
+	"github.com/n3k0fi5t/wallet/app/repository/member"
+	"github.com/n3k0fi5t/wallet/app/service/wallet"
+)
+
+// Status maps errors of bank, wallet and money, which every handler may get, to HTTP status code.
+// Handlers map errors of their own service first and fall back to it
+func Status(err error) int {
+	switch err {
+	case bank.ErrInvalidDealing, bank.ErrSelfTransfer, bank.ErrCurrencyMismatch, wallet.ErrInvalidWalletName, wallet.ErrInvalidMember,
+		wallet.ErrInvalidCurrency, money.ErrOverflow, money.ErrInvalidCurrency, money.ErrInvalidAmount, money.ErrInvalidRatios,
+		money.ErrInvalidParts:
+		return http.StatusBadRequest
+	case wallet.ErrPermissionDenied:
+		return http.StatusForbidden
+	case bank.ErrAccountNotExist, bank.ErrTradeNotExist, member.ErrMemberNotExist:
+		return http.StatusNotFound
+	case bank.ErrBalanceNotEnough, bank.ErrAccountFrozen, bank.ErrIdempotencyKeyReused, bank.ErrBalanceOverflow, bank.ErrTradeAlreadyReversed,
+		bank.ErrCreditLimitInUse, wallet.ErrWalletNameTaken, wallet.ErrTooManyWallets, wallet.ErrDailyCapExceeded, wallet.ErrWalletOwner,
+		wallet.ErrTooManyMembers, wallet.ErrApprovalRequired, wallet.ErrApprovalNotRequired, wallet.ErrNotEnoughApprovers:
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// codes are machine readable codes of errors mapped by Status, clients match errors by them
+var codes = map[error]string{
+	bank.ErrInvalidDealing:        "INVALID_DEALING",
+	bank.ErrSelfTransfer:          "SELF_TRANSFER",
+	bank.ErrAccountNotExist:       "ACCOUNT_NOT_EXIST",
+	bank.ErrTradeNotExist:         "TRADE_NOT_EXIST",
+	bank.ErrBalanceNotEnough:      "BALANCE_NOT_ENOUGH",
+	bank.ErrAccountFrozen:         "ACCOUNT_FROZEN",
+	bank.ErrIdempotencyKeyReused:  "IDEMPOTENCY_KEY_REUSED",
+	bank.ErrCurrencyMismatch:      "CURRENCY_MISMATCH",
+	bank.ErrBalanceOverflow:       "BALANCE_OVERFLOW",
+	bank.ErrTradeAlreadyReversed:  "TRADE_ALREADY_REVERSED",
+	bank.ErrCreditLimitInUse:      "CREDIT_LIMIT_IN_USE",
+	wallet.ErrInvalidWalletName:   "INVALID_WALLET_NAME",
+	wallet.ErrWalletNameTaken:     "WALLET_NAME_TAKEN",
+	wallet.ErrTooManyWallets:      "TOO_MANY_WALLETS",
+	wallet.ErrPermissionDenied:    "PERMISSION_DENIED",
+	wallet.ErrDailyCapExceeded:    "DAILY_CAP_EXCEEDED",
+	wallet.ErrInvalidMember:       "INVALID_MEMBER",
+	wallet.ErrWalletOwner:         "WALLET_OWNER",
+	wallet.ErrTooManyMembers:      "TOO_MANY_MEMBERS",
+	wallet.ErrApprovalRequired:    "APPROVAL_REQUIRED",
+	wallet.ErrApprovalNotRequired: "APPROVAL_NOT_REQUIRED",
+	wallet.ErrNotEnoughApprovers:  "NOT_ENOUGH_APPROVERS",
+	wallet.ErrInvalidCurrency:     "INVALID_CURRENCY",
+	member.ErrMemberNotExist:      "MEMBER_NOT_EXIST",
+	money.ErrOverflow:             "AMOUNT_OVERFLOW",
+	money.ErrInvalidCurrency:      "INVALID_CURRENCY",
+	money.ErrInvalidAmount:        "INVALID_AMOUNT",
+	money.ErrInvalidRatios:        "INVALID_RATIOS",
+	money.ErrInvalidParts:         "INVALID_PARTS",
+}
+
+// Response responds the error with its machine readable code, looked up in codes of the handler and then in the
+// codes of errors mapped by Status
+func Response(c *gin.Context, code int, err error, handlerCodes map[error]string) {
+	resp := map[string]string{
+		"errMessage": err.Error(),
+	}
+	// errors of binding are slices, which can not be map keys
+	if reflect.TypeOf(err).Comparable() {
+		if errCode, ok := handlerCodes[err]; ok {
+			resp["errCode"] = errCode
+		} else if errCode, ok := codes[err]; ok {
+			resp["errCode"] = errCode
+		}
+	}
+	c.JSON(code, resp)
+}
@@ -0,0 +1,48 @@
+package apierror
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/n3k0fi5t/wallet/app/money"
+	"github.com/n3k0fi5t/wallet/app/repository/bank"
+	"github.com/n3k0fi5t/wallet/app/service/wallet"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatus(t *testing.T) {
+	require.Equal(t, http.StatusBadRequest, Status(money.ErrOverflow))
+	require.Equal(t, http.StatusForbidden, Status(wallet.ErrPermissionDenied))
+	require.Equal(t, http.StatusNotFound, Status(bank.ErrAccountNotExist))
+	require.Equal(t, http.StatusConflict, Status(wallet.ErrApprovalRequired))
+	require.Equal(t, http.StatusInternalServerError, Status(fmt.Errorf("")))
+}
+
+func TestResponse(t *testing.T) {
+	errHandler := fmt.Errorf("Handler error")
+	respond := func(err error) map[string]string {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		Response(c, http.StatusBadRequest, err, map[error]string{errHandler: "HANDLER_ERROR"})
+
+		resp := map[string]string{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		return resp
+	}
+
+	require.Equal(t, map[string]string{"errCode": "HANDLER_ERROR", "errMessage": "Handler error"}, respond(errHandler))
+	require.Equal(t, map[string]string{"errCode": "CURRENCY_MISMATCH", "errMessage": "Currency mismatch"}, respond(bank.ErrCurrencyMismatch))
+	// errors without code, of any type, only carry the message
+	require.Equal(t, map[string]string{"errMessage": "a, b"}, respond(errorList{"a", "b"}))
+}
+
+// errorList is an error which can not be a map key, as binding errors
+type errorList []string
+
+func (e errorList) Error() string {
+	return e[0] + ", " + e[1]
+}
@@ -0,0 +1,14 @@
+package apitest
+
+import (
+	"net/http"
+)
+
+// RequestHeader return a default header with content type indicating request body type, tests add the Authorization
+// of the user they act as
+func RequestHeader() http.Header {
+	header := http.Header{}
+
+	header.Add("Content-Type", "application/json")
+	return header
+}
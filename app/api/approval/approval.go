@@ -0,0 +1,334 @@
+package approval
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/n3k0fi5t/wallet/app/api/apierror"
+	"github.com/n3k0fi5t/wallet/app/middleware"
+	mApproval "github.com/n3k0fi5t/wallet/app/models/approval"
+	"github.com/n3k0fi5t/wallet/app/money"
+	rApproval "github.com/n3k0fi5t/wallet/app/repository/approval"
+	"github.com/n3k0fi5t/wallet/app/service/approval"
+)
+
+const (
+	defaultLimit = 50
+	maxLimit     = 500
+)
+
+var (
+	errInvalidOffset = fmt.Errorf("invalid offset")
+	errInvalidLimit  = fmt.Errorf("invalid limit")
+	errInvalidStatus = fmt.Errorf("invalid status")
+)
+
+// NewHandler ...
+func NewHandler(a approval.Service) *Handler {
+	return &Handler{
+		approvalSrv: a,
+	}
+}
+
+type Handler struct {
+	approvalSrv approval.Service
+}
+
+func (h *Handler) Handle(routerGroup *gin.RouterGroup) {
+	// APIs are only for authed user
+	prg := routerGroup.Group("/wallet/wallets/:walletID/approval-policy")
+	prg.Use(middleware.GetUserAccount())
+	prg.Handle("GET", "", h.getPolicy)
+	prg.Handle("PUT", "", h.setPolicy)
+	prg.Handle("DELETE", "", h.deletePolicy)
+
+	rg := routerGroup.Group("/wallet/intents")
+	rg.Use(middleware.GetUserAccount())
+	rg.Handle("POST", "", h.requestApproval)
+	rg.Handle("GET", "", h.listIntents)
+
+	// intent relative
+	irg := rg.Group("/:intentID")
+	irg.Handle("GET", "", h.getIntent)
+	irg.Handle("POST", "/approve", h.approve)
+	irg.Handle("POST", "/reject", h.reject)
+}
+
+// errorStatus maps service errors to HTTP status code
+func errorStatus(err error) int {
+	switch err {
+	case approval.ErrInvalidPolicy, approval.ErrInvalidApprover, approval.ErrInvalidAmount, approval.ErrCommentTooLong:
+		return http.StatusBadRequest
+	case rApproval.ErrPolicyNotExist, rApproval.ErrIntentNotExist:
+		return http.StatusNotFound
+	case approval.ErrApprovalNotRequired, approval.ErrNotEnoughApprovers, approval.ErrIntentNotPending, approval.ErrSelfApproval,
+		approval.ErrAlreadyDecided:
+		return http.StatusConflict
+	default:
+		return apierror.Status(err)
+	}
+}
+
+// errorCodes are machine readable codes of service errors, clients match errors by them
+var errorCodes = map[error]string{
+	approval.ErrInvalidPolicy:       "INVALID_POLICY",
+	approval.ErrInvalidApprover:     "INVALID_APPROVER",
+	approval.ErrInvalidAmount:       "INVALID_AMOUNT",
+	approval.ErrCommentTooLong:      "COMMENT_TOO_LONG",
+	approval.ErrApprovalNotRequired: "APPROVAL_NOT_REQUIRED",
+	approval.ErrNotEnoughApprovers:  "NOT_ENOUGH_APPROVERS",
+	approval.ErrIntentNotPending:    "INTENT_NOT_PENDING",
+	approval.ErrSelfApproval:        "SELF_APPROVAL",
+	approval.ErrAlreadyDecided:      "ALREADY_DECIDED",
+	rApproval.ErrPolicyNotExist:     "POLICY_NOT_EXIST",
+	rApproval.ErrIntentNotExist:     "INTENT_NOT_EXIST",
+}
+
+func responseError(c *gin.Context, code int, err error) {
+	apierror.Response(c, code, err, errorCodes)
+}
+
+type policyResp struct {
+	WalletID  string      `json:"walletID"`
+	Threshold money.Money `json:"threshold"`
+	Required  int         `json:"required"`
+	Approvers []string    `json:"approvers"`
+	UpdatedBy string      `json:"updatedBy"`
+	UpdatedMs int64       `json:"updatedMs"`
+}
+
+func toPolicyResp(p *mApproval.Policy) policyResp {
+	return policyResp{
+		WalletID:  p.WalletID,
+		Threshold: p.Threshold,
+		Required:  p.Required,
+		Approvers: mApproval.ApproverIDs(p.Approvers),
+		UpdatedBy: p.UpdatedBy,
+		UpdatedMs: p.UpdatedMs,
+	}
+}
+
+func (h *Handler) getPolicy(c *gin.Context) {
+	ctx := c.MustGet("ctx").(context.Context)
+	userID := c.MustGet("userID").(string)
+
+	p, err := h.approvalSrv.GetPolicy(ctx, userID, c.Param("walletID"))
+	if err != nil {
+		responseError(c, errorStatus(err), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toPolicyResp(p))
+}
+
+type setPolicyParam struct {
+	// Threshold is the max amount paid out without approvals
+	Threshold money.Money `json:"threshold"`
+	Required  int         `json:"required"`
+	Approvers []string    `json:"approvers"`
+}
+
+func (h *Handler) setPolicy(c *gin.Context) {
+	ctx := c.MustGet("ctx").(context.Context)
+	userID := c.MustGet("userID").(string)
+
+	param := setPolicyParam{}
+	if err := c.ShouldBindBodyWith(&param, binding.JSON); err != nil {
+		responseError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	p, err := h.approvalSrv.SetPolicy(ctx, userID, c.Param("walletID"), param.Threshold, param.Required, param.Approvers)
+	if err != nil {
+		responseError(c, errorStatus(err), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toPolicyResp(p))
+}
+
+func (h *Handler) deletePolicy(c *gin.Context) {
+	ctx := c.MustGet("ctx").(context.Context)
+	userID := c.MustGet("userID").(string)
+
+	if err := h.approvalSrv.DeletePolicy(ctx, userID, c.Param("walletID")); err != nil {
+		responseError(c, errorStatus(err), err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+type approvalResp struct {
+	ApproverID string             `json:"approverID"`
+	Decision   mApproval.Decision `json:"decision"`
+	Comment    string             `json:"comment,omitempty"`
+	CreatedMs  int64              `json:"createdMs"`
+}
+
+type intentResp struct {
+	IntentID      string           `json:"intentID"`
+	WalletID      string           `json:"walletID"`
+	RequestedBy   string           `json:"requestedBy"`
+	ToAccountID   string           `json:"toAccount,omitempty"`
+	Amount        money.Money      `json:"amount"`
+	Status        mApproval.Status `json:"status"`
+	Required      int              `json:"required"`
+	Approvers     []string         `json:"approvers"`
+	HoldTradeID   string           `json:"holdTradeID"`
+	SettleTradeID string           `json:"settleTradeID,omitempty"`
+	ExpiresAtMs   int64            `json:"expiresAtMs"`
+	CreatedMs     int64            `json:"createdMs"`
+	UpdatedMs     int64            `json:"updatedMs"`
+	// Approvals are the decisions on the intent, only responded for a single intent
+	Approvals []approvalResp `json:"approvals,omitempty"`
+}
+
+func toIntentResp(i *mApproval.Intent) intentResp {
+	return intentResp{
+		IntentID:      i.IntentID,
+		WalletID:      i.WalletID,
+		RequestedBy:   i.RequestedBy,
+		ToAccountID:   i.ToAccountID,
+		Amount:        i.Amount,
+		Status:        i.Status,
+		Required:      i.Required,
+		Approvers:     mApproval.ApproverIDs(i.Approvers),
+		HoldTradeID:   i.HoldTradeID,
+		SettleTradeID: i.SettleTradeID,
+		ExpiresAtMs:   i.ExpiresAtMs,
+		CreatedMs:     i.CreatedMs,
+		UpdatedMs:     i.UpdatedMs,
+	}
+}
+
+type requestApprovalParam struct {
+	// WalletID is the wallet paying, omitted for the default wallet
+	WalletID string `json:"walletID"`
+	// ToAccountID is the payee, omitted for a withdrawal
+	ToAccountID string      `json:"toAccount"`
+	Amount      money.Money `json:"amount"`
+}
+
+func (h *Handler) requestApproval(c *gin.Context) {
+	ctx := c.MustGet("ctx").(context.Context)
+	userID := c.MustGet("userID").(string)
+
+	param := requestApprovalParam{}
+	if err := c.ShouldBindBodyWith(&param, binding.JSON); err != nil {
+		responseError(c, http.StatusBadRequest, err)
+		return
+	}
+	if param.WalletID == "" {
+		param.WalletID = c.MustGet("accountID").(string)
+	}
+
+	i, err := h.approvalSrv.RequestApproval(ctx, userID, param.WalletID, param.ToAccountID, param.Amount)
+	if err != nil {
+		responseError(c, errorStatus(err), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toIntentResp(i))
+}
+
+type listIntentsResp struct {
+	Intents []intentResp `json:"intents"`
+}
+
+func (h *Handler) listIntents(c *gin.Context) {
+	ctx := c.MustGet("ctx").(context.Context)
+	userID := c.MustGet("userID").(string)
+
+	walletID := c.DefaultQuery("walletID", c.MustGet("accountID").(string))
+	status := mApproval.Status(c.Query("status"))
+	if status != "" && !status.IsValid() {
+		responseError(c, http.StatusBadRequest, errInvalidStatus)
+		return
+	}
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		responseError(c, http.StatusBadRequest, errInvalidOffset)
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultLimit)))
+	if err != nil || limit <= 0 || limit > maxLimit {
+		responseError(c, http.StatusBadRequest, errInvalidLimit)
+		return
+	}
+
+	intents, err := h.approvalSrv.ListIntents(ctx, userID, walletID, status, offset, limit)
+	if err != nil {
+		responseError(c, errorStatus(err), err)
+		return
+	}
+
+	resp := listIntentsResp{
+		Intents: make([]intentResp, 0, len(intents)),
+	}
+	for _, i := range intents {
+		resp.Intents = append(resp.Intents, toIntentResp(i))
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *Handler) getIntent(c *gin.Context) {
+	ctx := c.MustGet("ctx").(context.Context)
+	userID := c.MustGet("userID").(string)
+
+	i, approvals, err := h.approvalSrv.GetIntent(ctx, userID, c.Param("intentID"))
+	if err != nil {
+		responseError(c, errorStatus(err), err)
+		return
+	}
+
+	resp := toIntentResp(i)
+	resp.Approvals = make([]approvalResp, 0, len(approvals))
+	for _, a := range approvals {
+		resp.Approvals = append(resp.Approvals, approvalResp{
+			ApproverID: a.ApproverID,
+			Decision:   a.Decision,
+			Comment:    a.Comment,
+			CreatedMs:  a.CreatedMs,
+		})
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+type decideParam struct {
+	Comment string `json:"comment"`
+}
+
+func (h *Handler) approve(c *gin.Context) {
+	h.decide(c, h.approvalSrv.Approve)
+}
+
+func (h *Handler) reject(c *gin.Context) {
+	h.decide(c, h.approvalSrv.Reject)
+}
+
+// decide responds the intent in path after the decision of the user on it, the body is optional
+func (h *Handler) decide(c *gin.Context, action func(ctx context.Context, userID, intentID, comment string) (*mApproval.Intent, error)) {
+	ctx := c.MustGet("ctx").(context.Context)
+	userID := c.MustGet("userID").(string)
+
+	param := decideParam{}
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindBodyWith(&param, binding.JSON); err != nil {
+			responseError(c, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	i, err := action(ctx, userID, c.Param("intentID"), param.Comment)
+	if err != nil {
+		responseError(c, errorStatus(err), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toIntentResp(i))
+}
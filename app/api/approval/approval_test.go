@@ -0,0 +1,269 @@
+package approval
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/n3k0fi5t/wallet/app/api/apitest"
+	mdApproval "github.com/n3k0fi5t/wallet/app/models/approval"
+	"github.com/n3k0fi5t/wallet/app/money"
+	rApproval "github.com/n3k0fi5t/wallet/app/repository/approval"
+	"github.com/n3k0fi5t/wallet/app/service/approval"
+	mockSrv "github.com/n3k0fi5t/wallet/app/service/approval/mocks"
+	"github.com/n3k0fi5t/wallet/app/service/wallet"
+)
+
+var (
+	mockCtx        = context.Background()
+	mockAccountID1 = "935f871a-660f-4f19-801e-916c04bb0324"
+	mockAccountID2 = "a89b7b78-b9c1-4129-8cff-380bf53f3a49"
+	mockAuth1      = "Tim"
+	mockAuth2      = "Alex"
+	mockWalletID   = "4b0f5b8e-8f0c-4d3e-9a7d-2f6c1e0b9a55"
+	mockIntentID   = "5e0e8b3c-1f6a-4c39-9a52-6b4a8f1b0d7e"
+	mockTradeID    = "c1b2d7a4-3f2e-4a8b-9d55-0e6f7a8b9c01"
+	mockTimeMs     = int64(1650000000000)
+	mockPolicy     = &mdApproval.Policy{
+		WalletID:  mockWalletID,
+		Threshold: usd(1000),
+		Required:  1,
+		Approvers: mockAccountID2,
+		UpdatedBy: mockAccountID1,
+		UpdatedMs: mockTimeMs,
+	}
+	mockIntent = &mdApproval.Intent{
+		IntentID:      mockIntentID,
+		WalletID:      mockWalletID,
+		RequestedBy:   mockAccountID1,
+		Amount:        usd(5000),
+		HoldAccountID: "hold-" + mockIntentID,
+		Status:        mdApproval.Status_PENDING,
+		Required:      1,
+		Approvers:     mockAccountID2,
+		HoldTradeID:   mockTradeID,
+		ExpiresAtMs:   mockTimeMs + 1000,
+		CreatedMs:     mockTimeMs,
+		UpdatedMs:     mockTimeMs,
+	}
+
+	mockHandleCtxMiddleware = func() gin.HandlerFunc {
+		return func(c *gin.Context) {
+			c.Set("ctx", mockCtx)
+			c.Next()
+		}
+	}
+)
+
+type testSuite struct {
+	suite.Suite
+
+	router  *gin.Engine
+	mockSrv *mockSrv.Service
+	asrv    approval.Service
+}
+
+func (s *testSuite) SetupSuite() {
+	s.router = gin.Default()
+	s.mockSrv = &mockSrv.Service{}
+	s.asrv = s.mockSrv
+	handler := NewHandler(s.asrv)
+	rg := s.router.Group("/api/v1")
+	rg.Use(mockHandleCtxMiddleware())
+	handler.Handle(rg)
+}
+
+func (s *testSuite) TearDownSuite() {
+	s.mockSrv.AssertExpectations(s.T())
+}
+
+func TestSuite(t *testing.T) {
+	suite.Run(t, new(testSuite))
+}
+
+func (s *testSuite) TestApprovals() {
+	policyPath := "/api/v1/wallet/wallets/" + mockWalletID + "/approval-policy"
+	intentPath := "/api/v1/wallet/intents/" + mockIntentID
+
+	tests := []struct {
+		Desc       string
+		Method     string
+		Path       string
+		Payload    string
+		Auth       string
+		ExpCode    int
+		ExpErrCode string
+		setup      func()
+	}{
+		{
+			Desc:    "set policy",
+			Method:  "PUT",
+			Path:    policyPath,
+			Payload: `{"threshold": {"amount": "10.00", "currency": "USD"}, "required": 1, "approvers": ["` + mockAccountID2 + `"]}`,
+			Auth:    mockAuth1,
+			ExpCode: http.StatusOK,
+			setup: func() {
+				s.mockSrv.On("SetPolicy", mockCtx, mockAccountID1, mockWalletID, usd(1000), 1, []string{mockAccountID2}).Return(mockPolicy, nil).Once()
+			},
+		},
+		{
+			Desc:       "set policy requiring too many",
+			Method:     "PUT",
+			Path:       policyPath,
+			Payload:    `{"threshold": {"amount": "10.00", "currency": "USD"}, "required": 2, "approvers": ["` + mockAccountID2 + `"]}`,
+			Auth:       mockAuth1,
+			ExpCode:    http.StatusBadRequest,
+			ExpErrCode: "INVALID_POLICY",
+			setup: func() {
+				s.mockSrv.On("SetPolicy", mockCtx, mockAccountID1, mockWalletID, usd(1000), 2, []string{mockAccountID2}).Return(nil, approval.ErrInvalidPolicy).Once()
+			},
+		},
+		{
+			Desc:       "get policy of wallet without one",
+			Method:     "GET",
+			Path:       policyPath,
+			Auth:       mockAuth2,
+			ExpCode:    http.StatusNotFound,
+			ExpErrCode: "POLICY_NOT_EXIST",
+			setup: func() {
+				s.mockSrv.On("GetPolicy", mockCtx, mockAccountID2, mockWalletID).Return(nil, rApproval.ErrPolicyNotExist).Once()
+			},
+		},
+		{
+			Desc:       "approver deletes policy",
+			Method:     "DELETE",
+			Path:       policyPath,
+			Auth:       mockAuth2,
+			ExpCode:    http.StatusForbidden,
+			ExpErrCode: "PERMISSION_DENIED",
+			setup: func() {
+				s.mockSrv.On("DeletePolicy", mockCtx, mockAccountID2, mockWalletID).Return(wallet.ErrPermissionDenied).Once()
+			},
+		},
+		{
+			Desc:    "request withdrawal approval",
+			Method:  "POST",
+			Path:    "/api/v1/wallet/intents",
+			Payload: `{"walletID": "` + mockWalletID + `", "amount": {"amount": "50.00", "currency": "USD"}}`,
+			Auth:    mockAuth1,
+			ExpCode: http.StatusOK,
+			setup: func() {
+				s.mockSrv.On("RequestApproval", mockCtx, mockAccountID1, mockWalletID, "", usd(5000)).Return(mockIntent, nil).Once()
+			},
+		},
+		{
+			Desc:       "request transfer within threshold from default wallet",
+			Method:     "POST",
+			Path:       "/api/v1/wallet/intents",
+			Payload:    `{"toAccount": "` + mockAccountID2 + `", "amount": {"amount": "5.00", "currency": "USD"}}`,
+			Auth:       mockAuth1,
+			ExpCode:    http.StatusConflict,
+			ExpErrCode: "APPROVAL_NOT_REQUIRED",
+			setup: func() {
+				s.mockSrv.On("RequestApproval", mockCtx, mockAccountID1, mockAccountID1, mockAccountID2, usd(500)).Return(nil, approval.ErrApprovalNotRequired).Once()
+			},
+		},
+		{
+			Desc:    "list pending intents",
+			Method:  "GET",
+			Path:    "/api/v1/wallet/intents?walletID=" + mockWalletID + "&status=PENDING",
+			Auth:    mockAuth2,
+			ExpCode: http.StatusOK,
+			setup: func() {
+				s.mockSrv.On("ListIntents", mockCtx, mockAccountID2, mockWalletID, mdApproval.Status_PENDING, 0, defaultLimit).Return([]*mdApproval.Intent{mockIntent}, nil).Once()
+			},
+		},
+		{
+			Desc:    "list intents by bad status",
+			Method:  "GET",
+			Path:    "/api/v1/wallet/intents?status=OPEN",
+			Auth:    mockAuth2,
+			ExpCode: http.StatusBadRequest,
+		},
+		{
+			Desc:    "get intent with history",
+			Method:  "GET",
+			Path:    intentPath,
+			Auth:    mockAuth2,
+			ExpCode: http.StatusOK,
+			setup: func() {
+				history := []*mdApproval.Approval{{IntentID: mockIntentID, ApproverID: mockAccountID2, Decision: mdApproval.Decision_APPROVE}}
+				s.mockSrv.On("GetIntent", mockCtx, mockAccountID2, mockIntentID).Return(mockIntent, history, nil).Once()
+			},
+		},
+		{
+			Desc:    "approve without comment",
+			Method:  "POST",
+			Path:    intentPath + "/approve",
+			Auth:    mockAuth2,
+			ExpCode: http.StatusOK,
+			setup: func() {
+				s.mockSrv.On("Approve", mockCtx, mockAccountID2, mockIntentID, "").Return(mockIntent, nil).Once()
+			},
+		},
+		{
+			Desc:       "approve own intent",
+			Method:     "POST",
+			Path:       intentPath + "/approve",
+			Payload:    `{"comment": "urgent"}`,
+			Auth:       mockAuth1,
+			ExpCode:    http.StatusConflict,
+			ExpErrCode: "SELF_APPROVAL",
+			setup: func() {
+				s.mockSrv.On("Approve", mockCtx, mockAccountID1, mockIntentID, "urgent").Return(nil, approval.ErrSelfApproval).Once()
+			},
+		},
+		{
+			Desc:       "reject expired intent",
+			Method:     "POST",
+			Path:       intentPath + "/reject",
+			Payload:    `{"comment": "wrong payee"}`,
+			Auth:       mockAuth2,
+			ExpCode:    http.StatusConflict,
+			ExpErrCode: "INTENT_NOT_PENDING",
+			setup: func() {
+				s.mockSrv.On("Reject", mockCtx, mockAccountID2, mockIntentID, "wrong payee").Return(nil, approval.ErrIntentNotPending).Once()
+			},
+		},
+		{
+			Desc:    "unauthorized case",
+			Method:  "POST",
+			Path:    intentPath + "/approve",
+			ExpCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, t := range tests {
+		if t.setup != nil {
+			t.setup()
+		}
+
+		header := apitest.RequestHeader()
+		header.Set("Authorization", t.Auth)
+
+		req, err := http.NewRequest(t.Method, t.Path, bytes.NewBufferString(t.Payload))
+		req.Header = header
+		s.Require().NoError(err, t.Desc)
+
+		rr := httptest.NewRecorder()
+		s.router.ServeHTTP(rr, req)
+		s.Require().Equal(t.ExpCode, rr.Code, t.Desc)
+
+		if t.ExpErrCode != "" {
+			resp := map[string]string{}
+			s.Require().NoError(json.Unmarshal(rr.Body.Bytes(), &resp), t.Desc)
+			s.Require().Equal(t.ExpErrCode, resp["errCode"], t.Desc)
+		}
+	}
+}
+
+func usd(amount int64) money.Money {
+	return money.New(amount, "USD")
+}
@@ -0,0 +1,255 @@
+package bill
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/n3k0fi5t/wallet/app/api/apierror"
+	"github.com/n3k0fi5t/wallet/app/middleware"
+	mBill "github.com/n3k0fi5t/wallet/app/models/bill"
+	"github.com/n3k0fi5t/wallet/app/money"
+	rBill "github.com/n3k0fi5t/wallet/app/repository/bill"
+	"github.com/n3k0fi5t/wallet/app/service/bill"
+)
+
+const (
+	defaultLimit = 50
+	maxLimit     = 500
+)
+
+var (
+	errInvalidOffset = fmt.Errorf("invalid offset")
+	errInvalidLimit  = fmt.Errorf("invalid limit")
+	errInvalidRole   = fmt.Errorf("invalid role")
+	errInvalidStatus = fmt.Errorf("invalid status")
+)
+
+// NewHandler ...
+func NewHandler(b bill.Service) *Handler {
+	return &Handler{
+		billSrv: b,
+	}
+}
+
+type Handler struct {
+	billSrv bill.Service
+}
+
+func (h *Handler) Handle(routerGroup *gin.RouterGroup) {
+	rg := routerGroup.Group("/wallet/bills")
+
+	// APIs are only for authed user
+	rg.Use(middleware.GetUserAccount())
+
+	rg.Handle("POST", "", h.createBill)
+	rg.Handle("GET", "", h.listBills)
+
+	// bill relative
+	brg := rg.Group("/:billID")
+	brg.Handle("GET", "", h.getBill)
+	brg.Handle("POST", "/pay", h.payShare)
+	brg.Handle("POST", "/remind", h.remind)
+	brg.Handle("POST", "/cancel", h.cancelBill)
+}
+
+// errorStatus maps service errors to HTTP status code
+func errorStatus(err error) int {
+	switch err {
+	case bill.ErrInvalidTotal, bill.ErrInvalidSplitType, bill.ErrInvalidShares, bill.ErrInvalidParticipants, bill.ErrMemoTooLong:
+		return http.StatusBadRequest
+	case rBill.ErrBillNotExist:
+		return http.StatusNotFound
+	case bill.ErrBillNotOpen, bill.ErrShareNotPending:
+		return http.StatusConflict
+	default:
+		return apierror.Status(err)
+	}
+}
+
+// errorCodes are machine readable codes of service errors, clients match errors by them
+var errorCodes = map[error]string{
+	bill.ErrInvalidTotal:        "INVALID_TOTAL",
+	bill.ErrInvalidSplitType:    "INVALID_SPLIT_TYPE",
+	bill.ErrInvalidShares:       "INVALID_SHARES",
+	bill.ErrInvalidParticipants: "INVALID_PARTICIPANTS",
+	bill.ErrMemoTooLong:         "MEMO_TOO_LONG",
+	bill.ErrBillNotOpen:         "BILL_NOT_OPEN",
+	bill.ErrShareNotPending:     "SHARE_NOT_PENDING",
+	rBill.ErrBillNotExist:       "BILL_NOT_EXIST",
+}
+
+func responseError(c *gin.Context, code int, err error) {
+	apierror.Response(c, code, err, errorCodes)
+}
+
+type shareResp struct {
+	ParticipantID string            `json:"participantID"`
+	Amount        money.Money       `json:"amount"`
+	Status        mBill.ShareStatus `json:"status"`
+	TradeID       string            `json:"tradeID,omitempty"`
+	Reminders     int               `json:"reminders"`
+	RemindedMs    int64             `json:"remindedMs,omitempty"`
+	UpdatedMs     int64             `json:"updatedMs"`
+}
+
+type billResp struct {
+	BillID      string          `json:"billID"`
+	OrganizerID string          `json:"organizerID"`
+	Total       money.Money     `json:"total"`
+	Memo        string          `json:"memo"`
+	SplitType   mBill.SplitType `json:"splitType"`
+	Status      mBill.Status    `json:"status"`
+	Shares      []shareResp     `json:"shares"`
+	CreatedMs   int64           `json:"createdMs"`
+	UpdatedMs   int64           `json:"updatedMs"`
+}
+
+func toBillResp(b *mBill.Bill) billResp {
+	resp := billResp{
+		BillID:      b.BillID,
+		OrganizerID: b.OrganizerID,
+		Total:       b.Total,
+		Memo:        b.Memo,
+		SplitType:   b.SplitType,
+		Status:      b.Status,
+		Shares:      make([]shareResp, 0, len(b.Shares)),
+		CreatedMs:   b.CreatedMs,
+		UpdatedMs:   b.UpdatedMs,
+	}
+	for _, share := range b.Shares {
+		resp.Shares = append(resp.Shares, shareResp{
+			ParticipantID: share.ParticipantID,
+			Amount:        share.Amount,
+			Status:        share.Status,
+			TradeID:       share.TradeID,
+			Reminders:     share.Reminders,
+			RemindedMs:    share.RemindedMs,
+			UpdatedMs:     share.UpdatedMs,
+		})
+	}
+	return resp
+}
+
+type participantParam struct {
+	AccountID string `json:"accountID"`
+	// Amount is for EXACT split, omitted for other splits
+	Amount *money.Money `json:"amount,omitempty"`
+	// BasisPoints is for PERCENTAGE split, 100 basis points are 1 percent
+	BasisPoints int64 `json:"basisPoints"`
+}
+
+type createBillParam struct {
+	Total        money.Money        `json:"total"`
+	Memo         string             `json:"memo"`
+	SplitType    mBill.SplitType    `json:"splitType"`
+	Participants []participantParam `json:"participants"`
+}
+
+func (h *Handler) createBill(c *gin.Context) {
+	ctx := c.MustGet("ctx").(context.Context)
+	accountID := c.MustGet("accountID").(string)
+
+	param := createBillParam{}
+	if err := c.ShouldBindBodyWith(&param, binding.JSON); err != nil {
+		responseError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	participants := make([]*mBill.Participant, 0, len(param.Participants))
+	for _, p := range param.Participants {
+		participant := &mBill.Participant{
+			AccountID:   p.AccountID,
+			BasisPoints: p.BasisPoints,
+		}
+		if p.Amount != nil {
+			participant.Amount = *p.Amount
+		}
+		participants = append(participants, participant)
+	}
+
+	b, err := h.billSrv.CreateBill(ctx, accountID, param.Total, param.Memo, param.SplitType, participants)
+	if err != nil {
+		responseError(c, errorStatus(err), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toBillResp(b))
+}
+
+type listBillsResp struct {
+	Bills []billResp `json:"bills"`
+}
+
+func (h *Handler) listBills(c *gin.Context) {
+	ctx := c.MustGet("ctx").(context.Context)
+	accountID := c.MustGet("accountID").(string)
+
+	role := mBill.Role(c.DefaultQuery("role", string(mBill.Role_PARTICIPANT)))
+	if !role.IsValid() {
+		responseError(c, http.StatusBadRequest, errInvalidRole)
+		return
+	}
+	status := mBill.Status(c.Query("status"))
+	if status != "" && !status.IsValid() {
+		responseError(c, http.StatusBadRequest, errInvalidStatus)
+		return
+	}
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		responseError(c, http.StatusBadRequest, errInvalidOffset)
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultLimit)))
+	if err != nil || limit <= 0 || limit > maxLimit {
+		responseError(c, http.StatusBadRequest, errInvalidLimit)
+		return
+	}
+
+	bills, err := h.billSrv.ListBills(ctx, accountID, role, status, offset, limit)
+	if err != nil {
+		responseError(c, errorStatus(err), err)
+		return
+	}
+
+	resp := listBillsResp{
+		Bills: make([]billResp, 0, len(bills)),
+	}
+	for _, b := range bills {
+		resp.Bills = append(resp.Bills, toBillResp(b))
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *Handler) getBill(c *gin.Context) {
+	h.handleBill(c, h.billSrv.GetBill)
+}
+
+func (h *Handler) payShare(c *gin.Context) {
+	h.handleBill(c, h.billSrv.PayShare)
+}
+
+func (h *Handler) remind(c *gin.Context) {
+	h.handleBill(c, h.billSrv.Remind)
+}
+
+func (h *Handler) cancelBill(c *gin.Context) {
+	h.handleBill(c, h.billSrv.CancelBill)
+}
+
+// handleBill responds the bill in path after the action of the user on it
+func (h *Handler) handleBill(c *gin.Context, action func(ctx context.Context, accountID, billID string) (*mBill.Bill, error)) {
+	ctx := c.MustGet("ctx").(context.Context)
+	accountID := c.MustGet("accountID").(string)
+
+	b, err := action(ctx, accountID, c.Param("billID"))
+	if err != nil {
+		responseError(c, errorStatus(err), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toBillResp(b))
+}
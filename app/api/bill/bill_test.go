@@ -0,0 +1,335 @@
+package bill
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/n3k0fi5t/wallet/app/api/apitest"
+	mdBill "github.com/n3k0fi5t/wallet/app/models/bill"
+	"github.com/n3k0fi5t/wallet/app/money"
+	"github.com/n3k0fi5t/wallet/app/repository/bank"
+	rBill "github.com/n3k0fi5t/wallet/app/repository/bill"
+	"github.com/n3k0fi5t/wallet/app/service/bill"
+	mockSrv "github.com/n3k0fi5t/wallet/app/service/bill/mocks"
+)
+
+var (
+	mockCtx        = context.Background()
+	mockAccountID1 = "935f871a-660f-4f19-801e-916c04bb0324"
+	mockAccountID2 = "a89b7b78-b9c1-4129-8cff-380bf53f3a49"
+	mockAuth1      = "Tim"
+	mockBillID     = "5e0e8b3c-1f6a-4c39-9a52-6b4a8f1b0d7e"
+	mockTradeID    = "c1b2d7a4-3f2e-4a8b-9d55-0e6f7a8b9c01"
+	mockTimeMs     = int64(1650000000000)
+	mockBill       = &mdBill.Bill{
+		BillID:      mockBillID,
+		OrganizerID: mockAccountID2,
+		Total:       usd(1000),
+		Memo:        "dinner",
+		SplitType:   mdBill.SplitType_EQUAL,
+		Status:      mdBill.Status_OPEN,
+		CreatedMs:   mockTimeMs,
+		UpdatedMs:   mockTimeMs,
+		Shares: []*mdBill.Share{
+			{BillID: mockBillID, ParticipantID: mockAccountID2, Amount: usd(500), Status: mdBill.ShareStatus_PAID, UpdatedMs: mockTimeMs},
+			{BillID: mockBillID, ParticipantID: mockAccountID1, Amount: usd(500), Status: mdBill.ShareStatus_PAID, TradeID: mockTradeID, UpdatedMs: mockTimeMs},
+		},
+	}
+
+	mockHandleCtxMiddleware = func() gin.HandlerFunc {
+		return func(c *gin.Context) {
+			c.Set("ctx", mockCtx)
+			c.Next()
+		}
+	}
+)
+
+type testSuite struct {
+	suite.Suite
+
+	router  *gin.Engine
+	mockSrv *mockSrv.Service
+	bsrv    bill.Service
+}
+
+func (s *testSuite) SetupSuite() {
+	s.router = gin.Default()
+	s.mockSrv = &mockSrv.Service{}
+	s.bsrv = s.mockSrv
+	handler := NewHandler(s.bsrv)
+	rg := s.router.Group("/api/v1")
+	rg.Use(mockHandleCtxMiddleware())
+	handler.Handle(rg)
+}
+
+func (s *testSuite) TearDownSuite() {
+	s.mockSrv.AssertExpectations(s.T())
+}
+
+func TestSuite(t *testing.T) {
+	suite.Run(t, new(testSuite))
+}
+
+func (s *testSuite) TestCreateBill() {
+	genPayload := func(d createBillParam) []byte {
+		b, err := json.Marshal(d)
+		s.Require().NoError(err)
+		return b
+	}
+	exactShare := usd(1000)
+
+	tests := []struct {
+		Desc       string
+		Payload    []byte
+		ExpCode    int
+		ExpErrCode string
+		Auth       string
+		setup      func()
+	}{
+		{
+			Desc: "normal case",
+			setup: func() {
+				s.mockSrv.On("CreateBill", mockCtx, mockAccountID1, usd(1000), "dinner", mdBill.SplitType_PERCENTAGE, []*mdBill.Participant{
+					{AccountID: mockAccountID1, BasisPoints: 4000},
+					{AccountID: mockAccountID2, BasisPoints: 6000},
+				}).Return(mockBill, nil).Once()
+			},
+			Payload: genPayload(createBillParam{Total: usd(1000), Memo: "dinner", SplitType: mdBill.SplitType_PERCENTAGE, Participants: []participantParam{
+				{AccountID: mockAccountID1, BasisPoints: 4000},
+				{AccountID: mockAccountID2, BasisPoints: 6000},
+			}}),
+			Auth:    mockAuth1,
+			ExpCode: http.StatusOK,
+		},
+		{
+			Desc: "shares not adding up",
+			setup: func() {
+				s.mockSrv.On("CreateBill", mockCtx, mockAccountID1, usd(999), "", mdBill.SplitType_EXACT, []*mdBill.Participant{
+					{AccountID: mockAccountID2, Amount: usd(1000)},
+				}).Return(nil, bill.ErrInvalidShares).Once()
+			},
+			Payload: genPayload(createBillParam{Total: usd(999), SplitType: mdBill.SplitType_EXACT, Participants: []participantParam{
+				{AccountID: mockAccountID2, Amount: &exactShare},
+			}}),
+			Auth:       mockAuth1,
+			ExpCode:    http.StatusBadRequest,
+			ExpErrCode: "INVALID_SHARES",
+		},
+		{
+			Desc:    "unauthorized case",
+			Auth:    "",
+			ExpCode: http.StatusUnauthorized,
+		},
+		{
+			Desc:    "bad param",
+			Auth:    mockAuth1,
+			ExpCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, t := range tests {
+		if t.setup != nil {
+			t.setup()
+		}
+
+		header := apitest.RequestHeader()
+		header.Set("Authorization", t.Auth)
+
+		req, err := http.NewRequest("POST", "/api/v1/wallet/bills", bytes.NewBuffer(t.Payload))
+		req.Header = header
+		s.Require().NoError(err, t.Desc)
+
+		rr := httptest.NewRecorder()
+		s.router.ServeHTTP(rr, req)
+		s.Require().Equal(t.ExpCode, rr.Code, t.Desc)
+
+		if t.ExpErrCode != "" {
+			resp := map[string]string{}
+			s.Require().NoError(json.Unmarshal(rr.Body.Bytes(), &resp), t.Desc)
+			s.Require().Equal(t.ExpErrCode, resp["errCode"], t.Desc)
+		}
+	}
+}
+
+func (s *testSuite) TestListBills() {
+	tests := []struct {
+		Desc    string
+		Query   string
+		ExpCode int
+		setup   func()
+	}{
+		{
+			Desc:  "normal case, participant by default",
+			Query: "",
+			setup: func() {
+				s.mockSrv.On("ListBills", mockCtx, mockAccountID1, mdBill.Role_PARTICIPANT, mdBill.Status(""), 0, defaultLimit).Return([]*mdBill.Bill{mockBill}, nil).Once()
+			},
+			ExpCode: http.StatusOK,
+		},
+		{
+			Desc:  "normal case, organized open bills",
+			Query: "?role=organizer&status=OPEN&offset=10&limit=5",
+			setup: func() {
+				s.mockSrv.On("ListBills", mockCtx, mockAccountID1, mdBill.Role_ORGANIZER, mdBill.Status_OPEN, 10, 5).Return([]*mdBill.Bill{}, nil).Once()
+			},
+			ExpCode: http.StatusOK,
+		},
+		{
+			Desc:    "bad role",
+			Query:   "?role=guest",
+			ExpCode: http.StatusBadRequest,
+		},
+		{
+			Desc:    "bad status",
+			Query:   "?status=PAID",
+			ExpCode: http.StatusBadRequest,
+		},
+		{
+			Desc:    "bad offset",
+			Query:   "?offset=-1",
+			ExpCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, t := range tests {
+		if t.setup != nil {
+			t.setup()
+		}
+
+		header := apitest.RequestHeader()
+		header.Set("Authorization", mockAuth1)
+
+		req, err := http.NewRequest("GET", "/api/v1/wallet/bills"+t.Query, nil)
+		req.Header = header
+		s.Require().NoError(err, t.Desc)
+
+		rr := httptest.NewRecorder()
+		s.router.ServeHTTP(rr, req)
+		s.Require().Equal(t.ExpCode, rr.Code, t.Desc)
+	}
+}
+
+func (s *testSuite) TestActions() {
+	tests := []struct {
+		Desc       string
+		Method     string
+		Path       string
+		ExpCode    int
+		ExpErrCode string
+		ExpResp    *billResp
+		setup      func()
+	}{
+		{
+			Desc:   "get bill not exist",
+			Method: "GET",
+			Path:   "",
+			setup: func() {
+				s.mockSrv.On("GetBill", mockCtx, mockAccountID1, mockBillID).Return(nil, rBill.ErrBillNotExist).Once()
+			},
+			ExpCode:    http.StatusNotFound,
+			ExpErrCode: "BILL_NOT_EXIST",
+		},
+		{
+			Desc:   "pay share",
+			Method: "POST",
+			Path:   "/pay",
+			setup: func() {
+				s.mockSrv.On("PayShare", mockCtx, mockAccountID1, mockBillID).Return(mockBill, nil).Once()
+			},
+			ExpCode: http.StatusOK,
+			ExpResp: &billResp{
+				BillID:      mockBillID,
+				OrganizerID: mockAccountID2,
+				Total:       usd(1000),
+				Memo:        "dinner",
+				SplitType:   mdBill.SplitType_EQUAL,
+				Status:      mdBill.Status_OPEN,
+				Shares: []shareResp{
+					{ParticipantID: mockAccountID2, Amount: usd(500), Status: mdBill.ShareStatus_PAID, UpdatedMs: mockTimeMs},
+					{ParticipantID: mockAccountID1, Amount: usd(500), Status: mdBill.ShareStatus_PAID, TradeID: mockTradeID, UpdatedMs: mockTimeMs},
+				},
+				CreatedMs: mockTimeMs,
+				UpdatedMs: mockTimeMs,
+			},
+		},
+		{
+			Desc:   "pay share twice",
+			Method: "POST",
+			Path:   "/pay",
+			setup: func() {
+				s.mockSrv.On("PayShare", mockCtx, mockAccountID1, mockBillID).Return(nil, bill.ErrShareNotPending).Once()
+			},
+			ExpCode:    http.StatusConflict,
+			ExpErrCode: "SHARE_NOT_PENDING",
+		},
+		{
+			Desc:   "pay share without enough balance",
+			Method: "POST",
+			Path:   "/pay",
+			setup: func() {
+				s.mockSrv.On("PayShare", mockCtx, mockAccountID1, mockBillID).Return(nil, bank.ErrBalanceNotEnough).Once()
+			},
+			ExpCode:    http.StatusConflict,
+			ExpErrCode: "BALANCE_NOT_ENOUGH",
+		},
+		{
+			Desc:   "remind",
+			Method: "POST",
+			Path:   "/remind",
+			setup: func() {
+				s.mockSrv.On("Remind", mockCtx, mockAccountID1, mockBillID).Return(mockBill, nil).Once()
+			},
+			ExpCode: http.StatusOK,
+		},
+		{
+			Desc:   "cancel settled bill",
+			Method: "POST",
+			Path:   "/cancel",
+			setup: func() {
+				s.mockSrv.On("CancelBill", mockCtx, mockAccountID1, mockBillID).Return(nil, bill.ErrBillNotOpen).Once()
+			},
+			ExpCode:    http.StatusConflict,
+			ExpErrCode: "BILL_NOT_OPEN",
+		},
+	}
+
+	for _, t := range tests {
+		if t.setup != nil {
+			t.setup()
+		}
+
+		header := apitest.RequestHeader()
+		header.Set("Authorization", mockAuth1)
+
+		req, err := http.NewRequest(t.Method, "/api/v1/wallet/bills/"+mockBillID+t.Path, nil)
+		req.Header = header
+		s.Require().NoError(err, t.Desc)
+
+		rr := httptest.NewRecorder()
+		s.router.ServeHTTP(rr, req)
+		s.Require().Equal(t.ExpCode, rr.Code, t.Desc)
+
+		if t.ExpErrCode != "" {
+			resp := map[string]string{}
+			s.Require().NoError(json.Unmarshal(rr.Body.Bytes(), &resp), t.Desc)
+			s.Require().Equal(t.ExpErrCode, resp["errCode"], t.Desc)
+		}
+		if t.ExpResp != nil {
+			resp := billResp{}
+			s.Require().NoError(json.Unmarshal(rr.Body.Bytes(), &resp), t.Desc)
+			s.Require().Equal(*t.ExpResp, resp, t.Desc)
+		}
+	}
+}
+
+func usd(amount int64) money.Money {
+	return money.New(amount, "USD")
+}
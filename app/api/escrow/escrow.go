@@ -0,0 +1,257 @@
+package escrow
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/n3k0fi5t/wallet/app/api/apierror"
+	"github.com/n3k0fi5t/wallet/app/middleware"
+	mAdmin "github.com/n3k0fi5t/wallet/app/models/admin"
+	mEscrow "github.com/n3k0fi5t/wallet/app/models/escrow"
+	"github.com/n3k0fi5t/wallet/app/money"
+	rEscrow "github.com/n3k0fi5t/wallet/app/repository/escrow"
+	"github.com/n3k0fi5t/wallet/app/service/admin"
+	"github.com/n3k0fi5t/wallet/app/service/escrow"
+)
+
+const (
+	defaultLimit = 50
+	maxLimit     = 500
+)
+
+var (
+	errInvalidOffset = fmt.Errorf("invalid offset")
+	errInvalidLimit  = fmt.Errorf("invalid limit")
+	errInvalidRole   = fmt.Errorf("invalid role")
+	errInvalidStatus = fmt.Errorf("invalid status")
+)
+
+// NewHandler ...
+func NewHandler(e escrow.Service) *Handler {
+	return &Handler{
+		escrowSrv: e,
+	}
+}
+
+type Handler struct {
+	escrowSrv escrow.Service
+}
+
+func (h *Handler) Handle(routerGroup *gin.RouterGroup) {
+	rg := routerGroup.Group("/wallet/escrows")
+
+	// APIs are only for authed user
+	rg.Use(middleware.GetUserAccount())
+
+	rg.Handle("POST", "", h.createEscrow)
+	rg.Handle("GET", "", h.listEscrows)
+
+	// escrow relative
+	erg := rg.Group("/:escrowID")
+	erg.Handle("GET", "", h.getEscrow)
+	erg.Handle("POST", "/confirm", h.confirm)
+	erg.Handle("POST", "/cancel", h.cancel)
+	erg.Handle("POST", "/dispute", h.dispute)
+
+	// disputes are resolved by authed staff, permission is checked by role in service
+	arg := routerGroup.Group("/admin/escrows")
+	arg.Use(middleware.GetAdminOperator())
+	arg.Handle("POST", "/:escrowID/resolve", h.resolve)
+}
+
+// errorStatus maps service errors to HTTP status code
+func errorStatus(err error) int {
+	switch err {
+	case admin.ErrPermissionDenied:
+		return http.StatusForbidden
+	case escrow.ErrInvalidAmount, escrow.ErrInvalidReleaseTime, escrow.ErrMemoTooLong, escrow.ErrSelfEscrow,
+		escrow.ErrInvalidOutcome, admin.ErrInvalidReasonCode:
+		return http.StatusBadRequest
+	case rEscrow.ErrEscrowNotExist:
+		return http.StatusNotFound
+	case escrow.ErrEscrowNotFunded, escrow.ErrEscrowNotDisputed:
+		return http.StatusConflict
+	default:
+		return apierror.Status(err)
+	}
+}
+
+// errorCodes are machine readable codes of service errors, clients match errors by them
+var errorCodes = map[error]string{
+	escrow.ErrInvalidAmount:      "INVALID_AMOUNT",
+	escrow.ErrInvalidReleaseTime: "INVALID_RELEASE_TIME",
+	escrow.ErrMemoTooLong:        "MEMO_TOO_LONG",
+	escrow.ErrSelfEscrow:         "SELF_ESCROW",
+	escrow.ErrInvalidOutcome:     "INVALID_OUTCOME",
+	escrow.ErrEscrowNotFunded:    "ESCROW_NOT_FUNDED",
+	escrow.ErrEscrowNotDisputed:  "ESCROW_NOT_DISPUTED",
+	rEscrow.ErrEscrowNotExist:    "ESCROW_NOT_EXIST",
+	admin.ErrPermissionDenied:    "PERMISSION_DENIED",
+	admin.ErrInvalidReasonCode:   "INVALID_REASON_CODE",
+}
+
+func responseError(c *gin.Context, code int, err error) {
+	apierror.Response(c, code, err, errorCodes)
+}
+
+type escrowResp struct {
+	EscrowID      string         `json:"escrowID"`
+	BuyerID       string         `json:"buyerID"`
+	SellerID      string         `json:"sellerID"`
+	Amount        money.Money    `json:"amount"`
+	Memo          string         `json:"memo"`
+	Status        mEscrow.Status `json:"status"`
+	FundTradeID   string         `json:"fundTradeID"`
+	SettleTradeID string         `json:"settleTradeID,omitempty"`
+	DisputedBy    string         `json:"disputedBy,omitempty"`
+	ReleaseAtMs   int64          `json:"releaseAtMs"`
+	CreatedMs     int64          `json:"createdMs"`
+	UpdatedMs     int64          `json:"updatedMs"`
+}
+
+func toEscrowResp(e *mEscrow.Escrow) escrowResp {
+	return escrowResp{
+		EscrowID:      e.EscrowID,
+		BuyerID:       e.BuyerID,
+		SellerID:      e.SellerID,
+		Amount:        e.Amount,
+		Memo:          e.Memo,
+		Status:        e.Status,
+		FundTradeID:   e.FundTradeID,
+		SettleTradeID: e.SettleTradeID,
+		DisputedBy:    e.DisputedBy,
+		ReleaseAtMs:   e.ReleaseAtMs,
+		CreatedMs:     e.CreatedMs,
+		UpdatedMs:     e.UpdatedMs,
+	}
+}
+
+type createEscrowParam struct {
+	SellerID string      `json:"sellerID"`
+	Amount   money.Money `json:"amount"`
+	Memo     string      `json:"memo"`
+	// ReleaseAtMs is when the money goes to the seller without confirmation, omitted for the default hold period
+	ReleaseAtMs int64 `json:"releaseAtMs"`
+}
+
+func (h *Handler) createEscrow(c *gin.Context) {
+	ctx := c.MustGet("ctx").(context.Context)
+	accountID := c.MustGet("accountID").(string)
+
+	param := createEscrowParam{}
+	if err := c.ShouldBindBodyWith(&param, binding.JSON); err != nil {
+		responseError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	e, err := h.escrowSrv.CreateEscrow(ctx, accountID, param.SellerID, param.Amount, param.Memo, param.ReleaseAtMs)
+	if err != nil {
+		responseError(c, errorStatus(err), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toEscrowResp(e))
+}
+
+type listEscrowsResp struct {
+	Escrows []escrowResp `json:"escrows"`
+}
+
+func (h *Handler) listEscrows(c *gin.Context) {
+	ctx := c.MustGet("ctx").(context.Context)
+	accountID := c.MustGet("accountID").(string)
+
+	role := mEscrow.Role(c.DefaultQuery("role", string(mEscrow.Role_BUYER)))
+	if !role.IsValid() {
+		responseError(c, http.StatusBadRequest, errInvalidRole)
+		return
+	}
+	status := mEscrow.Status(c.Query("status"))
+	if status != "" && !status.IsValid() {
+		responseError(c, http.StatusBadRequest, errInvalidStatus)
+		return
+	}
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		responseError(c, http.StatusBadRequest, errInvalidOffset)
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultLimit)))
+	if err != nil || limit <= 0 || limit > maxLimit {
+		responseError(c, http.StatusBadRequest, errInvalidLimit)
+		return
+	}
+
+	escrows, err := h.escrowSrv.ListEscrows(ctx, accountID, role, status, offset, limit)
+	if err != nil {
+		responseError(c, errorStatus(err), err)
+		return
+	}
+
+	resp := listEscrowsResp{
+		Escrows: make([]escrowResp, 0, len(escrows)),
+	}
+	for _, e := range escrows {
+		resp.Escrows = append(resp.Escrows, toEscrowResp(e))
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *Handler) getEscrow(c *gin.Context) {
+	h.handleEscrow(c, h.escrowSrv.GetEscrow)
+}
+
+func (h *Handler) confirm(c *gin.Context) {
+	h.handleEscrow(c, h.escrowSrv.Confirm)
+}
+
+func (h *Handler) cancel(c *gin.Context) {
+	h.handleEscrow(c, h.escrowSrv.Cancel)
+}
+
+func (h *Handler) dispute(c *gin.Context) {
+	h.handleEscrow(c, h.escrowSrv.Dispute)
+}
+
+// handleEscrow responds the escrow in path after the action of the user on it
+func (h *Handler) handleEscrow(c *gin.Context, action func(ctx context.Context, accountID, escrowID string) (*mEscrow.Escrow, error)) {
+	ctx := c.MustGet("ctx").(context.Context)
+	accountID := c.MustGet("accountID").(string)
+
+	e, err := action(ctx, accountID, c.Param("escrowID"))
+	if err != nil {
+		responseError(c, errorStatus(err), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toEscrowResp(e))
+}
+
+type resolveParam struct {
+	Outcome    mEscrow.Outcome   `json:"outcome"`
+	ReasonCode mAdmin.ReasonCode `json:"reasonCode"`
+	Note       string            `json:"note"`
+}
+
+func (h *Handler) resolve(c *gin.Context) {
+	ctx := c.MustGet("ctx").(context.Context)
+	operator := c.MustGet("operator").(*mAdmin.Operator)
+
+	param := resolveParam{}
+	if err := c.ShouldBindBodyWith(&param, binding.JSON); err != nil {
+		responseError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	e, err := h.escrowSrv.Resolve(ctx, operator, c.Param("escrowID"), param.Outcome, param.ReasonCode, param.Note)
+	if err != nil {
+		responseError(c, errorStatus(err), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toEscrowResp(e))
+}
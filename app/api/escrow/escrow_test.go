@@ -0,0 +1,410 @@
+package escrow
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/n3k0fi5t/wallet/app/api/apitest"
+	mdAdmin "github.com/n3k0fi5t/wallet/app/models/admin"
+	mdEscrow "github.com/n3k0fi5t/wallet/app/models/escrow"
+	"github.com/n3k0fi5t/wallet/app/money"
+	"github.com/n3k0fi5t/wallet/app/repository/bank"
+	rEscrow "github.com/n3k0fi5t/wallet/app/repository/escrow"
+	"github.com/n3k0fi5t/wallet/app/service/admin"
+	"github.com/n3k0fi5t/wallet/app/service/escrow"
+	mockSrv "github.com/n3k0fi5t/wallet/app/service/escrow/mocks"
+)
+
+var (
+	mockCtx        = context.Background()
+	mockAccountID1 = "935f871a-660f-4f19-801e-916c04bb0324"
+	mockAccountID2 = "a89b7b78-b9c1-4129-8cff-380bf53f3a49"
+	mockAuth1      = "Tim"
+	mockEscrowID   = "5e0e8b3c-1f6a-4c39-9a52-6b4a8f1b0d7e"
+	mockTradeID    = "c1b2d7a4-3f2e-4a8b-9d55-0e6f7a8b9c01"
+	mockTimeMs     = int64(1650000000000)
+	mockOperator   = &mdAdmin.Operator{OperatorID: "Kronii", Role: mdAdmin.Role_OPERATOR}
+	mockSupport    = &mdAdmin.Operator{OperatorID: "Mori", Role: mdAdmin.Role_SUPPORT}
+	mockEscrow     = &mdEscrow.Escrow{
+		EscrowID:    mockEscrowID,
+		AccountID:   "escrow-" + mockEscrowID,
+		BuyerID:     mockAccountID1,
+		SellerID:    mockAccountID2,
+		Amount:      usd(500),
+		Memo:        "vintage camera",
+		Status:      mdEscrow.Status_FUNDED,
+		FundTradeID: mockTradeID,
+		ReleaseAtMs: mockTimeMs + 1000,
+		CreatedMs:   mockTimeMs,
+		UpdatedMs:   mockTimeMs,
+	}
+
+	mockHandleCtxMiddleware = func() gin.HandlerFunc {
+		return func(c *gin.Context) {
+			c.Set("ctx", mockCtx)
+			c.Next()
+		}
+	}
+)
+
+type testSuite struct {
+	suite.Suite
+
+	router  *gin.Engine
+	mockSrv *mockSrv.Service
+	esrv    escrow.Service
+}
+
+func (s *testSuite) SetupSuite() {
+	s.router = gin.Default()
+	s.mockSrv = &mockSrv.Service{}
+	s.esrv = s.mockSrv
+	handler := NewHandler(s.esrv)
+	rg := s.router.Group("/api/v1")
+	rg.Use(mockHandleCtxMiddleware())
+	handler.Handle(rg)
+}
+
+func (s *testSuite) TearDownSuite() {
+	s.mockSrv.AssertExpectations(s.T())
+}
+
+func TestSuite(t *testing.T) {
+	suite.Run(t, new(testSuite))
+}
+
+func (s *testSuite) TestCreateEscrow() {
+	genPayload := func(d createEscrowParam) []byte {
+		b, err := json.Marshal(d)
+		s.Require().NoError(err)
+		return b
+	}
+
+	tests := []struct {
+		Desc       string
+		Payload    []byte
+		ExpCode    int
+		ExpErrCode string
+		Auth       string
+		setup      func()
+	}{
+		{
+			Desc: "normal case",
+			setup: func() {
+				s.mockSrv.On("CreateEscrow", mockCtx, mockAccountID1, mockAccountID2, usd(500), "vintage camera", int64(0)).Return(mockEscrow, nil).Once()
+			},
+			Payload: genPayload(createEscrowParam{SellerID: mockAccountID2, Amount: usd(500), Memo: "vintage camera"}),
+			Auth:    mockAuth1,
+			ExpCode: http.StatusOK,
+		},
+		{
+			Desc: "release time too late",
+			setup: func() {
+				s.mockSrv.On("CreateEscrow", mockCtx, mockAccountID1, mockAccountID2, usd(500), "", mockTimeMs).Return(nil, escrow.ErrInvalidReleaseTime).Once()
+			},
+			Payload:    genPayload(createEscrowParam{SellerID: mockAccountID2, Amount: usd(500), ReleaseAtMs: mockTimeMs}),
+			Auth:       mockAuth1,
+			ExpCode:    http.StatusBadRequest,
+			ExpErrCode: "INVALID_RELEASE_TIME",
+		},
+		{
+			Desc: "balance not enough",
+			setup: func() {
+				s.mockSrv.On("CreateEscrow", mockCtx, mockAccountID1, mockAccountID2, usd(99999), "", int64(0)).Return(nil, bank.ErrBalanceNotEnough).Once()
+			},
+			Payload:    genPayload(createEscrowParam{SellerID: mockAccountID2, Amount: usd(99999)}),
+			Auth:       mockAuth1,
+			ExpCode:    http.StatusConflict,
+			ExpErrCode: "BALANCE_NOT_ENOUGH",
+		},
+		{
+			Desc:    "unauthorized case",
+			Auth:    "",
+			ExpCode: http.StatusUnauthorized,
+		},
+		{
+			Desc:    "bad param",
+			Auth:    mockAuth1,
+			ExpCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, t := range tests {
+		if t.setup != nil {
+			t.setup()
+		}
+
+		header := apitest.RequestHeader()
+		header.Set("Authorization", t.Auth)
+
+		req, err := http.NewRequest("POST", "/api/v1/wallet/escrows", bytes.NewBuffer(t.Payload))
+		req.Header = header
+		s.Require().NoError(err, t.Desc)
+
+		rr := httptest.NewRecorder()
+		s.router.ServeHTTP(rr, req)
+		s.Require().Equal(t.ExpCode, rr.Code, t.Desc)
+
+		if t.ExpErrCode != "" {
+			resp := map[string]string{}
+			s.Require().NoError(json.Unmarshal(rr.Body.Bytes(), &resp), t.Desc)
+			s.Require().Equal(t.ExpErrCode, resp["errCode"], t.Desc)
+		}
+	}
+}
+
+func (s *testSuite) TestListEscrows() {
+	tests := []struct {
+		Desc    string
+		Query   string
+		ExpCode int
+		setup   func()
+	}{
+		{
+			Desc:  "normal case, buyer by default",
+			Query: "",
+			setup: func() {
+				s.mockSrv.On("ListEscrows", mockCtx, mockAccountID1, mdEscrow.Role_BUYER, mdEscrow.Status(""), 0, defaultLimit).Return([]*mdEscrow.Escrow{mockEscrow}, nil).Once()
+			},
+			ExpCode: http.StatusOK,
+		},
+		{
+			Desc:  "normal case, disputed sales",
+			Query: "?role=seller&status=DISPUTED&offset=10&limit=5",
+			setup: func() {
+				s.mockSrv.On("ListEscrows", mockCtx, mockAccountID1, mdEscrow.Role_SELLER, mdEscrow.Status_DISPUTED, 10, 5).Return([]*mdEscrow.Escrow{}, nil).Once()
+			},
+			ExpCode: http.StatusOK,
+		},
+		{
+			Desc:    "bad role",
+			Query:   "?role=broker",
+			ExpCode: http.StatusBadRequest,
+		},
+		{
+			Desc:    "bad status",
+			Query:   "?status=OPEN",
+			ExpCode: http.StatusBadRequest,
+		},
+		{
+			Desc:    "bad limit",
+			Query:   "?limit=501",
+			ExpCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, t := range tests {
+		if t.setup != nil {
+			t.setup()
+		}
+
+		header := apitest.RequestHeader()
+		header.Set("Authorization", mockAuth1)
+
+		req, err := http.NewRequest("GET", "/api/v1/wallet/escrows"+t.Query, nil)
+		req.Header = header
+		s.Require().NoError(err, t.Desc)
+
+		rr := httptest.NewRecorder()
+		s.router.ServeHTTP(rr, req)
+		s.Require().Equal(t.ExpCode, rr.Code, t.Desc)
+	}
+}
+
+func (s *testSuite) TestActions() {
+	tests := []struct {
+		Desc       string
+		Method     string
+		Path       string
+		ExpCode    int
+		ExpErrCode string
+		ExpResp    *escrowResp
+		setup      func()
+	}{
+		{
+			Desc:   "get escrow not exist",
+			Method: "GET",
+			Path:   "",
+			setup: func() {
+				s.mockSrv.On("GetEscrow", mockCtx, mockAccountID1, mockEscrowID).Return(nil, rEscrow.ErrEscrowNotExist).Once()
+			},
+			ExpCode:    http.StatusNotFound,
+			ExpErrCode: "ESCROW_NOT_EXIST",
+		},
+		{
+			Desc:   "get escrow",
+			Method: "GET",
+			Path:   "",
+			setup: func() {
+				s.mockSrv.On("GetEscrow", mockCtx, mockAccountID1, mockEscrowID).Return(mockEscrow, nil).Once()
+			},
+			ExpCode: http.StatusOK,
+			ExpResp: &escrowResp{
+				EscrowID:    mockEscrowID,
+				BuyerID:     mockAccountID1,
+				SellerID:    mockAccountID2,
+				Amount:      usd(500),
+				Memo:        "vintage camera",
+				Status:      mdEscrow.Status_FUNDED,
+				FundTradeID: mockTradeID,
+				ReleaseAtMs: mockTimeMs + 1000,
+				CreatedMs:   mockTimeMs,
+				UpdatedMs:   mockTimeMs,
+			},
+		},
+		{
+			Desc:   "confirm",
+			Method: "POST",
+			Path:   "/confirm",
+			setup: func() {
+				s.mockSrv.On("Confirm", mockCtx, mockAccountID1, mockEscrowID).Return(mockEscrow, nil).Once()
+			},
+			ExpCode: http.StatusOK,
+		},
+		{
+			Desc:   "cancel disputed escrow",
+			Method: "POST",
+			Path:   "/cancel",
+			setup: func() {
+				s.mockSrv.On("Cancel", mockCtx, mockAccountID1, mockEscrowID).Return(nil, escrow.ErrEscrowNotFunded).Once()
+			},
+			ExpCode:    http.StatusConflict,
+			ExpErrCode: "ESCROW_NOT_FUNDED",
+		},
+		{
+			Desc:   "dispute",
+			Method: "POST",
+			Path:   "/dispute",
+			setup: func() {
+				s.mockSrv.On("Dispute", mockCtx, mockAccountID1, mockEscrowID).Return(mockEscrow, nil).Once()
+			},
+			ExpCode: http.StatusOK,
+		},
+	}
+
+	for _, t := range tests {
+		if t.setup != nil {
+			t.setup()
+		}
+
+		header := apitest.RequestHeader()
+		header.Set("Authorization", mockAuth1)
+
+		req, err := http.NewRequest(t.Method, "/api/v1/wallet/escrows/"+mockEscrowID+t.Path, nil)
+		req.Header = header
+		s.Require().NoError(err, t.Desc)
+
+		rr := httptest.NewRecorder()
+		s.router.ServeHTTP(rr, req)
+		s.Require().Equal(t.ExpCode, rr.Code, t.Desc)
+
+		if t.ExpErrCode != "" {
+			resp := map[string]string{}
+			s.Require().NoError(json.Unmarshal(rr.Body.Bytes(), &resp), t.Desc)
+			s.Require().Equal(t.ExpErrCode, resp["errCode"], t.Desc)
+		}
+		if t.ExpResp != nil {
+			resp := escrowResp{}
+			s.Require().NoError(json.Unmarshal(rr.Body.Bytes(), &resp), t.Desc)
+			s.Require().Equal(*t.ExpResp, resp, t.Desc)
+		}
+	}
+}
+
+func (s *testSuite) TestResolve() {
+	genPayload := func(d resolveParam) []byte {
+		b, err := json.Marshal(d)
+		s.Require().NoError(err)
+		return b
+	}
+
+	tests := []struct {
+		Desc       string
+		Payload    []byte
+		Auth       string
+		ExpCode    int
+		ExpErrCode string
+		setup      func()
+	}{
+		{
+			Desc: "normal case",
+			setup: func() {
+				s.mockSrv.On("Resolve", mockCtx, mockOperator, mockEscrowID, mdEscrow.Outcome_REFUND, mdAdmin.ReasonCode_FRAUD, "never shipped").Return(mockEscrow, nil).Once()
+			},
+			Payload: genPayload(resolveParam{Outcome: mdEscrow.Outcome_REFUND, ReasonCode: mdAdmin.ReasonCode_FRAUD, Note: "never shipped"}),
+			Auth:    "Kronii",
+			ExpCode: http.StatusOK,
+		},
+		{
+			Desc: "permission denied",
+			setup: func() {
+				s.mockSrv.On("Resolve", mockCtx, mockSupport, mockEscrowID, mdEscrow.Outcome_RELEASE, mdAdmin.ReasonCode_CORRECTION, "").Return(nil, admin.ErrPermissionDenied).Once()
+			},
+			Payload:    genPayload(resolveParam{Outcome: mdEscrow.Outcome_RELEASE, ReasonCode: mdAdmin.ReasonCode_CORRECTION}),
+			Auth:       "Mori",
+			ExpCode:    http.StatusForbidden,
+			ExpErrCode: "PERMISSION_DENIED",
+		},
+		{
+			Desc: "not disputed",
+			setup: func() {
+				s.mockSrv.On("Resolve", mockCtx, mockOperator, mockEscrowID, mdEscrow.Outcome_RELEASE, mdAdmin.ReasonCode_CORRECTION, "").Return(nil, escrow.ErrEscrowNotDisputed).Once()
+			},
+			Payload:    genPayload(resolveParam{Outcome: mdEscrow.Outcome_RELEASE, ReasonCode: mdAdmin.ReasonCode_CORRECTION}),
+			Auth:       "Kronii",
+			ExpCode:    http.StatusConflict,
+			ExpErrCode: "ESCROW_NOT_DISPUTED",
+		},
+		{
+			Desc: "missing outcome",
+			setup: func() {
+				s.mockSrv.On("Resolve", mockCtx, mockOperator, mockEscrowID, mdEscrow.Outcome(""), mdAdmin.ReasonCode_CORRECTION, "").Return(nil, escrow.ErrInvalidOutcome).Once()
+			},
+			Payload:    genPayload(resolveParam{ReasonCode: mdAdmin.ReasonCode_CORRECTION}),
+			Auth:       "Kronii",
+			ExpCode:    http.StatusBadRequest,
+			ExpErrCode: "INVALID_OUTCOME",
+		},
+		{
+			Desc:    "user is not staff",
+			Payload: genPayload(resolveParam{Outcome: mdEscrow.Outcome_RELEASE, ReasonCode: mdAdmin.ReasonCode_CORRECTION}),
+			Auth:    mockAuth1,
+			ExpCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, t := range tests {
+		if t.setup != nil {
+			t.setup()
+		}
+
+		header := apitest.RequestHeader()
+		header.Set("Authorization", t.Auth)
+
+		req, err := http.NewRequest("POST", "/api/v1/admin/escrows/"+mockEscrowID+"/resolve", bytes.NewBuffer(t.Payload))
+		req.Header = header
+		s.Require().NoError(err, t.Desc)
+
+		rr := httptest.NewRecorder()
+		s.router.ServeHTTP(rr, req)
+		s.Require().Equal(t.ExpCode, rr.Code, t.Desc)
+
+		if t.ExpErrCode != "" {
+			resp := map[string]string{}
+			s.Require().NoError(json.Unmarshal(rr.Body.Bytes(), &resp), t.Desc)
+			s.Require().Equal(t.ExpErrCode, resp["errCode"], t.Desc)
+		}
+	}
+}
+
+func usd(amount int64) money.Money {
+	return money.New(amount, "USD")
+}
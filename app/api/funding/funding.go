@@ -0,0 +1,252 @@
+package funding
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/n3k0fi5t/wallet/app/api/apierror"
+	"github.com/n3k0fi5t/wallet/app/middleware"
+	mBank "github.com/n3k0fi5t/wallet/app/models/bank"
+	mFunding "github.com/n3k0fi5t/wallet/app/models/funding"
+	"github.com/n3k0fi5t/wallet/app/money"
+	"github.com/n3k0fi5t/wallet/app/rail"
+	rFunding "github.com/n3k0fi5t/wallet/app/repository/funding"
+	"github.com/n3k0fi5t/wallet/app/service/funding"
+)
+
+const (
+	defaultLimit = 50
+	maxLimit     = 500
+
+	// maxCallbackBytes bounds the body of rail callbacks read before they are authenticated
+	maxCallbackBytes = 64 << 10
+)
+
+var (
+	errInvalidOffset = fmt.Errorf("invalid offset")
+	errInvalidLimit  = fmt.Errorf("invalid limit")
+)
+
+// NewHandler ...
+func NewHandler(f funding.Service) *Handler {
+	return &Handler{
+		fundingSrv: f,
+	}
+}
+
+type Handler struct {
+	fundingSrv funding.Service
+}
+
+func (h *Handler) Handle(routerGroup *gin.RouterGroup) {
+	// APIs are only for authed user
+	rg := routerGroup.Group("/wallet/funding")
+	rg.Use(middleware.GetUserAccount())
+	rg.Handle("POST", "/deposits", h.deposit)
+	rg.Handle("POST", "/withdrawals", h.withdraw)
+	rg.Handle("GET", "/transfers", h.listTransfers)
+	rg.Handle("GET", "/transfers/:transferID", h.getTransfer)
+
+	// rails are authenticated by the signature of their callbacks
+	routerGroup.Handle("POST", "/rails/:rail/callbacks", h.callback)
+}
+
+// errorStatus maps service errors to HTTP status code
+func errorStatus(err error) int {
+	switch err {
+	case funding.ErrInvalidAmount, rail.ErrInvalidCallback:
+		return http.StatusBadRequest
+	case rail.ErrInvalidSignature:
+		return http.StatusUnauthorized
+	case funding.ErrUnknownRail, rFunding.ErrTransferNotExist:
+		return http.StatusNotFound
+	case funding.ErrInvalidTransition:
+		return http.StatusConflict
+	case funding.ErrRailUnavailable:
+		return http.StatusBadGateway
+	default:
+		return apierror.Status(err)
+	}
+}
+
+// errorCodes are machine readable codes of service errors, clients match errors by them
+var errorCodes = map[error]string{
+	funding.ErrInvalidAmount:     "INVALID_AMOUNT",
+	funding.ErrUnknownRail:       "UNKNOWN_RAIL",
+	funding.ErrRailUnavailable:   "RAIL_UNAVAILABLE",
+	funding.ErrInvalidTransition: "INVALID_TRANSITION",
+	rail.ErrInvalidSignature:     "INVALID_SIGNATURE",
+	rail.ErrInvalidCallback:      "INVALID_CALLBACK",
+	rFunding.ErrTransferNotExist: "TRANSFER_NOT_EXIST",
+}
+
+func responseError(c *gin.Context, code int, err error) {
+	apierror.Response(c, code, err, errorCodes)
+}
+
+type transferResp struct {
+	TransferID    string             `json:"transferID"`
+	WalletID      string             `json:"walletID"`
+	UserID        string             `json:"userID"`
+	Direction     mFunding.Direction `json:"direction"`
+	Rail          mBank.Rail         `json:"rail"`
+	Amount        money.Money        `json:"amount"`
+	Status        mFunding.Status    `json:"status"`
+	Reference     string             `json:"reference,omitempty"`
+	FailureReason string             `json:"failureReason,omitempty"`
+	ClearTradeID  string             `json:"clearTradeID,omitempty"`
+	SettleTradeID string             `json:"settleTradeID,omitempty"`
+	ReturnTradeID string             `json:"returnTradeID,omitempty"`
+	RefundTradeID string             `json:"refundTradeID,omitempty"`
+	// ShortfallTradeID and ShortfallAmount are the part of a returned deposit the wallet had spent already
+	ShortfallTradeID string       `json:"shortfallTradeID,omitempty"`
+	ShortfallAmount  *money.Money `json:"shortfallAmount,omitempty"`
+	CreatedMs        int64        `json:"createdMs"`
+	UpdatedMs        int64        `json:"updatedMs"`
+}
+
+func toTransferResp(t *mFunding.Transfer) transferResp {
+	resp := transferResp{
+		TransferID:       t.TransferID,
+		WalletID:         t.WalletID,
+		UserID:           t.UserID,
+		Direction:        t.Direction,
+		Rail:             t.Rail,
+		Amount:           t.Amount,
+		Status:           t.Status,
+		Reference:        t.Reference,
+		FailureReason:    t.FailureReason,
+		ClearTradeID:     t.ClearTradeID,
+		SettleTradeID:    t.SettleTradeID,
+		ReturnTradeID:    t.ReturnTradeID,
+		RefundTradeID:    t.RefundTradeID,
+		ShortfallTradeID: t.ShortfallTradeID,
+		CreatedMs:        t.CreatedMs,
+		UpdatedMs:        t.UpdatedMs,
+	}
+	if t.ShortfallAmount.IsPositive() {
+		shortfall := t.ShortfallAmount
+		resp.ShortfallAmount = &shortfall
+	}
+	return resp
+}
+
+type transferParam struct {
+	// WalletID is the wallet the money enters or leaves, omitted for the default wallet
+	WalletID string      `json:"walletID"`
+	Rail     mBank.Rail  `json:"rail"`
+	Amount   money.Money `json:"amount"`
+}
+
+func (h *Handler) deposit(c *gin.Context) {
+	h.handleTransfer(c, h.fundingSrv.Deposit)
+}
+
+func (h *Handler) withdraw(c *gin.Context) {
+	h.handleTransfer(c, h.fundingSrv.Withdraw)
+}
+
+// handleTransfer starts a transfer of the user by the body and responds it, pending until the rail calls back
+func (h *Handler) handleTransfer(c *gin.Context, start func(ctx context.Context, userID, walletID string, rail mBank.Rail, amount money.Money) (*mFunding.Transfer, error)) {
+	ctx := c.MustGet("ctx").(context.Context)
+	userID := c.MustGet("userID").(string)
+
+	param := transferParam{}
+	if err := c.ShouldBindBodyWith(&param, binding.JSON); err != nil {
+		responseError(c, http.StatusBadRequest, err)
+		return
+	}
+	if param.WalletID == "" {
+		param.WalletID = c.MustGet("accountID").(string)
+	}
+
+	t, err := start(ctx, userID, param.WalletID, param.Rail, param.Amount)
+	if err != nil {
+		responseError(c, errorStatus(err), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toTransferResp(t))
+}
+
+type listTransfersResp struct {
+	Transfers []transferResp `json:"transfers"`
+}
+
+func (h *Handler) listTransfers(c *gin.Context) {
+	ctx := c.MustGet("ctx").(context.Context)
+	userID := c.MustGet("userID").(string)
+
+	walletID := c.DefaultQuery("walletID", c.MustGet("accountID").(string))
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		responseError(c, http.StatusBadRequest, errInvalidOffset)
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultLimit)))
+	if err != nil || limit <= 0 || limit > maxLimit {
+		responseError(c, http.StatusBadRequest, errInvalidLimit)
+		return
+	}
+
+	transfers, err := h.fundingSrv.ListTransfers(ctx, userID, walletID, offset, limit)
+	if err != nil {
+		responseError(c, errorStatus(err), err)
+		return
+	}
+
+	resp := listTransfersResp{
+		Transfers: make([]transferResp, 0, len(transfers)),
+	}
+	for _, t := range transfers {
+		resp.Transfers = append(resp.Transfers, toTransferResp(t))
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *Handler) getTransfer(c *gin.Context) {
+	ctx := c.MustGet("ctx").(context.Context)
+	userID := c.MustGet("userID").(string)
+
+	t, err := h.fundingSrv.GetTransfer(ctx, userID, c.Param("transferID"))
+	if err != nil {
+		responseError(c, errorStatus(err), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toTransferResp(t))
+}
+
+type callbackResp struct {
+	TransferID string          `json:"transferID"`
+	Status     mFunding.Status `json:"status"`
+}
+
+// callback ingests a status callback of the rail, rails retry callbacks not acknowledged with 2xx
+func (h *Handler) callback(c *gin.Context) {
+	ctx := c.MustGet("ctx").(context.Context)
+
+	// the signature covers the raw body, it is read as is
+	body, err := ioutil.ReadAll(io.LimitReader(c.Request.Body, maxCallbackBytes))
+	if err != nil {
+		responseError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	t, err := h.fundingSrv.HandleCallback(ctx, mBank.Rail(c.Param("rail")), c.Request.Header, body)
+	if err != nil {
+		responseError(c, errorStatus(err), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, callbackResp{
+		TransferID: t.TransferID,
+		Status:     t.Status,
+	})
+}
@@ -0,0 +1,298 @@
+package funding
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/n3k0fi5t/wallet/app/api/apitest"
+	mdBank "github.com/n3k0fi5t/wallet/app/models/bank"
+	mdFunding "github.com/n3k0fi5t/wallet/app/models/funding"
+	"github.com/n3k0fi5t/wallet/app/money"
+	"github.com/n3k0fi5t/wallet/app/rail"
+	rFunding "github.com/n3k0fi5t/wallet/app/repository/funding"
+	"github.com/n3k0fi5t/wallet/app/service/funding"
+	mockSrv "github.com/n3k0fi5t/wallet/app/service/funding/mocks"
+	"github.com/n3k0fi5t/wallet/app/service/wallet"
+)
+
+var (
+	mockCtx        = context.Background()
+	mockAccountID1 = "935f871a-660f-4f19-801e-916c04bb0324"
+	mockAccountID2 = "a89b7b78-b9c1-4129-8cff-380bf53f3a49"
+	mockAuth1      = "Tim"
+	mockAuth2      = "Alex"
+	mockWalletID   = "4b0f5b8e-8f0c-4d3e-9a7d-2f6c1e0b9a55"
+	mockTransferID = "5e0e8b3c-1f6a-4c39-9a52-6b4a8f1b0d7e"
+	mockTimeMs     = int64(1650000000000)
+	mockTransfer   = &mdFunding.Transfer{
+		TransferID:   mockTransferID,
+		WalletID:     mockAccountID1,
+		UserID:       mockAccountID1,
+		Direction:    mdFunding.Direction_DEPOSIT,
+		Rail:         mdBank.Rail_SIMULATOR,
+		Amount:       usd(10000),
+		Status:       mdFunding.Status_PENDING,
+		Reference:    "sim_c1b2d7a4-3f2e-4a8b-9d55-0e6f7a8b9c01",
+		ClearTradeID: "7e1f2d3c-4b5a-4a8e-9b0c-5d2c9a4e3f61",
+		CreatedMs:    mockTimeMs,
+		UpdatedMs:    mockTimeMs,
+	}
+
+	mockHandleCtxMiddleware = func() gin.HandlerFunc {
+		return func(c *gin.Context) {
+			c.Set("ctx", mockCtx)
+			c.Next()
+		}
+	}
+)
+
+type testSuite struct {
+	suite.Suite
+
+	router  *gin.Engine
+	mockSrv *mockSrv.Service
+	fsrv    funding.Service
+}
+
+func (s *testSuite) SetupSuite() {
+	s.router = gin.Default()
+	s.mockSrv = &mockSrv.Service{}
+	s.fsrv = s.mockSrv
+	handler := NewHandler(s.fsrv)
+	rg := s.router.Group("/api/v1")
+	rg.Use(mockHandleCtxMiddleware())
+	handler.Handle(rg)
+}
+
+func (s *testSuite) TearDownSuite() {
+	s.mockSrv.AssertExpectations(s.T())
+}
+
+func TestSuite(t *testing.T) {
+	suite.Run(t, new(testSuite))
+}
+
+func (s *testSuite) TestTransfers() {
+	fundingPath := "/api/v1/wallet/funding"
+	transferPath := fundingPath + "/transfers/" + mockTransferID
+
+	tests := []struct {
+		Desc       string
+		Method     string
+		Path       string
+		Payload    string
+		Auth       string
+		ExpCode    int
+		ExpErrCode string
+		ExpStatus  string
+		setup      func()
+	}{
+		{
+			Desc:      "deposit to the default wallet",
+			Method:    "POST",
+			Path:      fundingPath + "/deposits",
+			Payload:   `{"rail": "simulator", "amount": {"amount": "100.00", "currency": "USD"}}`,
+			Auth:      mockAuth1,
+			ExpCode:   http.StatusOK,
+			ExpStatus: "PENDING",
+			setup: func() {
+				s.mockSrv.On("Deposit", mockCtx, mockAccountID1, mockAccountID1, mdBank.Rail_SIMULATOR, usd(10000)).Return(mockTransfer, nil).Once()
+			},
+		},
+		{
+			Desc:       "deposit over unknown rail",
+			Method:     "POST",
+			Path:       fundingPath + "/deposits",
+			Payload:    `{"rail": "direct", "amount": {"amount": "100.00", "currency": "USD"}}`,
+			Auth:       mockAuth1,
+			ExpCode:    http.StatusNotFound,
+			ExpErrCode: "UNKNOWN_RAIL",
+			setup: func() {
+				s.mockSrv.On("Deposit", mockCtx, mockAccountID1, mockAccountID1, mdBank.Rail_DIRECT, usd(10000)).Return(nil, funding.ErrUnknownRail).Once()
+			},
+		},
+		{
+			Desc:       "withdraw from a wallet of others",
+			Method:     "POST",
+			Path:       fundingPath + "/withdrawals",
+			Payload:    `{"walletID": "` + mockWalletID + `", "rail": "simulator", "amount": {"amount": "100.00", "currency": "USD"}}`,
+			Auth:       mockAuth2,
+			ExpCode:    http.StatusForbidden,
+			ExpErrCode: "PERMISSION_DENIED",
+			setup: func() {
+				s.mockSrv.On("Withdraw", mockCtx, mockAccountID2, mockWalletID, mdBank.Rail_SIMULATOR, usd(10000)).Return(nil, wallet.ErrPermissionDenied).Once()
+			},
+		},
+		{
+			Desc:       "withdraw over the approval threshold",
+			Method:     "POST",
+			Path:       fundingPath + "/withdrawals",
+			Payload:    `{"rail": "simulator", "amount": {"amount": "900.00", "currency": "USD"}}`,
+			Auth:       mockAuth1,
+			ExpCode:    http.StatusConflict,
+			ExpErrCode: "APPROVAL_REQUIRED",
+			setup: func() {
+				s.mockSrv.On("Withdraw", mockCtx, mockAccountID1, mockAccountID1, mdBank.Rail_SIMULATOR, usd(90000)).Return(nil, wallet.ErrApprovalRequired).Once()
+			},
+		},
+		{
+			Desc:       "withdraw while the rail is down",
+			Method:     "POST",
+			Path:       fundingPath + "/withdrawals",
+			Payload:    `{"rail": "simulator", "amount": {"amount": "50.00", "currency": "USD"}}`,
+			Auth:       mockAuth1,
+			ExpCode:    http.StatusBadGateway,
+			ExpErrCode: "RAIL_UNAVAILABLE",
+			setup: func() {
+				s.mockSrv.On("Withdraw", mockCtx, mockAccountID1, mockAccountID1, mdBank.Rail_SIMULATOR, usd(5000)).Return(nil, funding.ErrRailUnavailable).Once()
+			},
+		},
+		{
+			Desc:      "get transfer",
+			Method:    "GET",
+			Path:      transferPath,
+			Auth:      mockAuth1,
+			ExpCode:   http.StatusOK,
+			ExpStatus: "PENDING",
+			setup: func() {
+				s.mockSrv.On("GetTransfer", mockCtx, mockAccountID1, mockTransferID).Return(mockTransfer, nil).Once()
+			},
+		},
+		{
+			Desc:       "get transfer of others",
+			Method:     "GET",
+			Path:       transferPath,
+			Auth:       mockAuth2,
+			ExpCode:    http.StatusNotFound,
+			ExpErrCode: "TRANSFER_NOT_EXIST",
+			setup: func() {
+				s.mockSrv.On("GetTransfer", mockCtx, mockAccountID2, mockTransferID).Return(nil, rFunding.ErrTransferNotExist).Once()
+			},
+		},
+		{
+			Desc:    "list transfers",
+			Method:  "GET",
+			Path:    fundingPath + "/transfers?offset=10&limit=5",
+			Auth:    mockAuth1,
+			ExpCode: http.StatusOK,
+			setup: func() {
+				s.mockSrv.On("ListTransfers", mockCtx, mockAccountID1, mockAccountID1, 10, 5).Return([]*mdFunding.Transfer{mockTransfer}, nil).Once()
+			},
+		},
+		{
+			Desc:    "list transfers over max limit",
+			Method:  "GET",
+			Path:    fundingPath + "/transfers?limit=501",
+			Auth:    mockAuth1,
+			ExpCode: http.StatusBadRequest,
+		},
+		{
+			Desc:    "unauthorized case",
+			Method:  "POST",
+			Path:    fundingPath + "/deposits",
+			Payload: `{"rail": "simulator", "amount": {"amount": "100.00", "currency": "USD"}}`,
+			ExpCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, t := range tests {
+		if t.setup != nil {
+			t.setup()
+		}
+
+		header := apitest.RequestHeader()
+		header.Set("Authorization", t.Auth)
+
+		req, err := http.NewRequest(t.Method, t.Path, bytes.NewBufferString(t.Payload))
+		req.Header = header
+		s.Require().NoError(err, t.Desc)
+
+		rr := httptest.NewRecorder()
+		s.router.ServeHTTP(rr, req)
+		s.Require().Equal(t.ExpCode, rr.Code, t.Desc)
+
+		resp := map[string]interface{}{}
+		s.Require().NoError(json.Unmarshal(rr.Body.Bytes(), &resp), t.Desc)
+		if t.ExpErrCode != "" {
+			s.Require().Equal(t.ExpErrCode, resp["errCode"], t.Desc)
+		}
+		if t.ExpStatus != "" {
+			s.Require().Equal(t.ExpStatus, resp["status"], t.Desc)
+		}
+	}
+}
+
+func (s *testSuite) TestCallback() {
+	callbackPath := "/api/v1/rails/simulator/callbacks"
+	body := `{"transferID": "` + mockTransferID + `", "status": "SETTLED"}`
+
+	tests := []struct {
+		Desc       string
+		ExpCode    int
+		ExpErrCode string
+		ExpStatus  string
+		setup      func()
+	}{
+		{
+			Desc:      "settled",
+			ExpCode:   http.StatusOK,
+			ExpStatus: "SETTLED",
+			setup: func() {
+				settled := *mockTransfer
+				settled.Status = mdFunding.Status_SETTLED
+				s.mockSrv.On("HandleCallback", mockCtx, mdBank.Rail_SIMULATOR, mock.AnythingOfType("http.Header"), []byte(body)).Return(&settled, nil).Once()
+			},
+		},
+		{
+			Desc:       "forged",
+			ExpCode:    http.StatusUnauthorized,
+			ExpErrCode: "INVALID_SIGNATURE",
+			setup: func() {
+				s.mockSrv.On("HandleCallback", mockCtx, mdBank.Rail_SIMULATOR, mock.AnythingOfType("http.Header"), []byte(body)).Return(nil, rail.ErrInvalidSignature).Once()
+			},
+		},
+		{
+			Desc:       "failed after settled",
+			ExpCode:    http.StatusConflict,
+			ExpErrCode: "INVALID_TRANSITION",
+			setup: func() {
+				s.mockSrv.On("HandleCallback", mockCtx, mdBank.Rail_SIMULATOR, mock.AnythingOfType("http.Header"), []byte(body)).Return(nil, funding.ErrInvalidTransition).Once()
+			},
+		},
+	}
+
+	for _, t := range tests {
+		t.setup()
+
+		// callbacks carry no user authorization
+		req, err := http.NewRequest("POST", callbackPath, bytes.NewBufferString(body))
+		req.Header = apitest.RequestHeader()
+		s.Require().NoError(err, t.Desc)
+
+		rr := httptest.NewRecorder()
+		s.router.ServeHTTP(rr, req)
+		s.Require().Equal(t.ExpCode, rr.Code, t.Desc)
+
+		resp := map[string]interface{}{}
+		s.Require().NoError(json.Unmarshal(rr.Body.Bytes(), &resp), t.Desc)
+		if t.ExpErrCode != "" {
+			s.Require().Equal(t.ExpErrCode, resp["errCode"], t.Desc)
+		}
+		if t.ExpStatus != "" {
+			s.Require().Equal(t.ExpStatus, resp["status"], t.Desc)
+		}
+	}
+}
+
+func usd(amount int64) money.Money {
+	return money.New(amount, "USD")
+}
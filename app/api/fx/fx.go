@@ -0,0 +1,164 @@
+package fx
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/n3k0fi5t/wallet/app/api/apierror"
+	"github.com/n3k0fi5t/wallet/app/fxrate"
+	"github.com/n3k0fi5t/wallet/app/middleware"
+	mFx "github.com/n3k0fi5t/wallet/app/models/fx"
+	"github.com/n3k0fi5t/wallet/app/money"
+	rFx "github.com/n3k0fi5t/wallet/app/repository/fx"
+	"github.com/n3k0fi5t/wallet/app/service/fx"
+	"github.com/n3k0fi5t/wallet/app/util"
+)
+
+var (
+	timeNowMs = util.TimeNowMs
+)
+
+// NewHandler ...
+func NewHandler(f fx.Service) *Handler {
+	return &Handler{
+		fxSrv: f,
+	}
+}
+
+type Handler struct {
+	fxSrv fx.Service
+}
+
+func (h *Handler) Handle(routerGroup *gin.RouterGroup) {
+	// APIs are only for authed user
+	rg := routerGroup.Group("/wallet/fx/quotes")
+	rg.Use(middleware.GetUserAccount())
+	rg.Handle("POST", "", h.createQuote)
+
+	// quote relative
+	qrg := rg.Group("/:quoteID")
+	qrg.Handle("GET", "", h.getQuote)
+	qrg.Handle("POST", "/execute", h.executeQuote)
+}
+
+// errorStatus maps service errors to HTTP status code
+func errorStatus(err error) int {
+	switch err {
+	case fx.ErrInvalidAmount, fx.ErrSameCurrency, fx.ErrDifferentOwners, fx.ErrAmountTooSmall:
+		return http.StatusBadRequest
+	case rFx.ErrQuoteNotExist:
+		return http.StatusNotFound
+	case fx.ErrQuoteExpired, fx.ErrQuoteNotOpen, fx.ErrInsufficientLiquidity:
+		return http.StatusConflict
+	case fxrate.ErrRateNotFound:
+		return http.StatusUnprocessableEntity
+	default:
+		return apierror.Status(err)
+	}
+}
+
+// errorCodes are machine readable codes of service errors, clients match errors by them
+var errorCodes = map[error]string{
+	fx.ErrInvalidAmount:         "INVALID_AMOUNT",
+	fx.ErrSameCurrency:          "SAME_CURRENCY",
+	fx.ErrDifferentOwners:       "DIFFERENT_OWNERS",
+	fx.ErrAmountTooSmall:        "AMOUNT_TOO_SMALL",
+	fx.ErrQuoteExpired:          "QUOTE_EXPIRED",
+	fx.ErrQuoteNotOpen:          "QUOTE_NOT_OPEN",
+	fx.ErrInsufficientLiquidity: "INSUFFICIENT_LIQUIDITY",
+	fxrate.ErrRateNotFound:      "RATE_NOT_FOUND",
+	rFx.ErrQuoteNotExist:        "QUOTE_NOT_EXIST",
+}
+
+func responseError(c *gin.Context, code int, err error) {
+	apierror.Response(c, code, err, errorCodes)
+}
+
+type quoteResp struct {
+	QuoteID      string `json:"quoteID"`
+	FromWalletID string `json:"fromWalletID"`
+	ToWalletID   string `json:"toWalletID"`
+	// SellAmount is in the currency of the from wallet, BuyAmount in the currency of the to wallet
+	SellAmount  money.Money `json:"sellAmount"`
+	BuyAmount   money.Money `json:"buyAmount"`
+	Rate        string      `json:"rate"`
+	SpreadBps   int64       `json:"spreadBps"`
+	Status      string      `json:"status"`
+	TradeID     string      `json:"tradeID,omitempty"`
+	ExpiresAtMs int64       `json:"expiresAtMs"`
+	CreatedMs   int64       `json:"createdMs"`
+}
+
+func toQuoteResp(q *mFx.Quote) quoteResp {
+	return quoteResp{
+		QuoteID:      q.QuoteID,
+		FromWalletID: q.FromWalletID,
+		ToWalletID:   q.ToWalletID,
+		SellAmount:   q.SellAmount,
+		BuyAmount:    q.BuyAmount,
+		Rate:         q.Rate,
+		SpreadBps:    q.SpreadBps,
+		Status:       string(q.StatusAt(timeNowMs())),
+		TradeID:      q.TradeID,
+		ExpiresAtMs:  q.ExpiresAtMs,
+		CreatedMs:    q.CreatedMs,
+	}
+}
+
+type createQuoteParam struct {
+	// FromWalletID is the default wallet when empty
+	FromWalletID string `json:"fromWalletID"`
+	ToWalletID   string `json:"toWalletID"`
+	// Amount is sold out of the from wallet, in its currency
+	Amount money.Money `json:"amount"`
+}
+
+func (h *Handler) createQuote(c *gin.Context) {
+	ctx := c.MustGet("ctx").(context.Context)
+	userID := c.MustGet("userID").(string)
+
+	param := createQuoteParam{}
+	if err := c.ShouldBindBodyWith(&param, binding.JSON); err != nil {
+		responseError(c, http.StatusBadRequest, err)
+		return
+	}
+	if param.FromWalletID == "" {
+		param.FromWalletID = c.MustGet("accountID").(string)
+	}
+
+	q, err := h.fxSrv.CreateQuote(ctx, userID, param.FromWalletID, param.ToWalletID, param.Amount)
+	if err != nil {
+		responseError(c, errorStatus(err), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toQuoteResp(q))
+}
+
+func (h *Handler) getQuote(c *gin.Context) {
+	ctx := c.MustGet("ctx").(context.Context)
+	userID := c.MustGet("userID").(string)
+
+	q, err := h.fxSrv.GetQuote(ctx, userID, c.Param("quoteID"))
+	if err != nil {
+		responseError(c, errorStatus(err), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toQuoteResp(q))
+}
+
+func (h *Handler) executeQuote(c *gin.Context) {
+	ctx := c.MustGet("ctx").(context.Context)
+	userID := c.MustGet("userID").(string)
+
+	q, err := h.fxSrv.ExecuteQuote(ctx, userID, c.Param("quoteID"))
+	if err != nil {
+		responseError(c, errorStatus(err), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toQuoteResp(q))
+}
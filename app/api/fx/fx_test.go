@@ -0,0 +1,222 @@
+package fx
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/n3k0fi5t/wallet/app/api/apitest"
+	"github.com/n3k0fi5t/wallet/app/fxrate"
+	mdFx "github.com/n3k0fi5t/wallet/app/models/fx"
+	"github.com/n3k0fi5t/wallet/app/money"
+	rFx "github.com/n3k0fi5t/wallet/app/repository/fx"
+	"github.com/n3k0fi5t/wallet/app/service/fx"
+	mockSrv "github.com/n3k0fi5t/wallet/app/service/fx/mocks"
+)
+
+var (
+	mockCtx        = context.Background()
+	mockAccountID1 = "935f871a-660f-4f19-801e-916c04bb0324"
+	mockAccountID2 = "a89b7b78-b9c1-4129-8cff-380bf53f3a49"
+	mockAuth1      = "Tim"
+	mockAuth2      = "Alex"
+	mockWalletID   = "4b0f5b8e-8f0c-4d3e-9a7d-2f6c1e0b9a55"
+	mockQuoteID    = "5e0e8b3c-1f6a-4c39-9a52-6b4a8f1b0d7e"
+	mockTradeID    = "c1b2d7a4-3f2e-4a8b-9d55-0e6f7a8b9c01"
+	mockTimeMs     = int64(1650000000000)
+	mockQuote      = &mdFx.Quote{
+		QuoteID:      mockQuoteID,
+		UserID:       mockAccountID1,
+		FromWalletID: mockAccountID1,
+		ToWalletID:   mockWalletID,
+		SellAmount:   usd(10000),
+		BuyAmount:    money.New(9154, "EUR"),
+		Rate:         "0.92000000",
+		SpreadBps:    50,
+		Status:       mdFx.Status_OPEN,
+		ExpiresAtMs:  mockTimeMs + 30000,
+		CreatedMs:    mockTimeMs,
+		UpdatedMs:    mockTimeMs,
+	}
+
+	mockHandleCtxMiddleware = func() gin.HandlerFunc {
+		return func(c *gin.Context) {
+			c.Set("ctx", mockCtx)
+			c.Next()
+		}
+	}
+)
+
+type testSuite struct {
+	suite.Suite
+
+	router  *gin.Engine
+	mockSrv *mockSrv.Service
+	fsrv    fx.Service
+}
+
+func (s *testSuite) SetupSuite() {
+	timeNowMs = func() int64 { return mockTimeMs }
+
+	s.router = gin.Default()
+	s.mockSrv = &mockSrv.Service{}
+	s.fsrv = s.mockSrv
+	handler := NewHandler(s.fsrv)
+	rg := s.router.Group("/api/v1")
+	rg.Use(mockHandleCtxMiddleware())
+	handler.Handle(rg)
+}
+
+func (s *testSuite) TearDownSuite() {
+	s.mockSrv.AssertExpectations(s.T())
+}
+
+func TestSuite(t *testing.T) {
+	suite.Run(t, new(testSuite))
+}
+
+func (s *testSuite) TestQuotes() {
+	quotesPath := "/api/v1/wallet/fx/quotes"
+	quotePath := quotesPath + "/" + mockQuoteID
+
+	tests := []struct {
+		Desc       string
+		Method     string
+		Path       string
+		Payload    string
+		Auth       string
+		ExpCode    int
+		ExpErrCode string
+		ExpStatus  string
+		setup      func()
+	}{
+		{
+			Desc:      "quote from the default wallet",
+			Method:    "POST",
+			Path:      quotesPath,
+			Payload:   `{"toWalletID": "` + mockWalletID + `", "amount": {"amount": "100.00", "currency": "USD"}}`,
+			Auth:      mockAuth1,
+			ExpCode:   http.StatusOK,
+			ExpStatus: "OPEN",
+			setup: func() {
+				s.mockSrv.On("CreateQuote", mockCtx, mockAccountID1, mockAccountID1, mockWalletID, usd(10000)).Return(mockQuote, nil).Once()
+			},
+		},
+		{
+			Desc:       "quote between wallets of one currency",
+			Method:     "POST",
+			Path:       quotesPath,
+			Payload:    `{"fromWalletID": "` + mockWalletID + `", "toWalletID": "` + mockAccountID1 + `", "amount": {"amount": "100.00", "currency": "USD"}}`,
+			Auth:       mockAuth1,
+			ExpCode:    http.StatusBadRequest,
+			ExpErrCode: "SAME_CURRENCY",
+			setup: func() {
+				s.mockSrv.On("CreateQuote", mockCtx, mockAccountID1, mockWalletID, mockAccountID1, usd(10000)).Return(nil, fx.ErrSameCurrency).Once()
+			},
+		},
+		{
+			Desc:       "quote of unpriced pair",
+			Method:     "POST",
+			Path:       quotesPath,
+			Payload:    `{"toWalletID": "` + mockWalletID + `", "amount": {"amount": "5.00", "currency": "USD"}}`,
+			Auth:       mockAuth1,
+			ExpCode:    http.StatusUnprocessableEntity,
+			ExpErrCode: "RATE_NOT_FOUND",
+			setup: func() {
+				s.mockSrv.On("CreateQuote", mockCtx, mockAccountID1, mockAccountID1, mockWalletID, usd(500)).Return(nil, fxrate.ErrRateNotFound).Once()
+			},
+		},
+		{
+			Desc:      "get expired quote",
+			Method:    "GET",
+			Path:      quotePath,
+			Auth:      mockAuth1,
+			ExpCode:   http.StatusOK,
+			ExpStatus: "EXPIRED",
+			setup: func() {
+				expired := *mockQuote
+				expired.ExpiresAtMs = mockTimeMs
+				s.mockSrv.On("GetQuote", mockCtx, mockAccountID1, mockQuoteID).Return(&expired, nil).Once()
+			},
+		},
+		{
+			Desc:       "get quote of others",
+			Method:     "GET",
+			Path:       quotePath,
+			Auth:       mockAuth2,
+			ExpCode:    http.StatusNotFound,
+			ExpErrCode: "QUOTE_NOT_EXIST",
+			setup: func() {
+				s.mockSrv.On("GetQuote", mockCtx, mockAccountID2, mockQuoteID).Return(nil, rFx.ErrQuoteNotExist).Once()
+			},
+		},
+		{
+			Desc:      "execute",
+			Method:    "POST",
+			Path:      quotePath + "/execute",
+			Auth:      mockAuth1,
+			ExpCode:   http.StatusOK,
+			ExpStatus: "EXECUTED",
+			setup: func() {
+				executed := *mockQuote
+				executed.Status = mdFx.Status_EXECUTED
+				executed.TradeID = mockTradeID
+				s.mockSrv.On("ExecuteQuote", mockCtx, mockAccountID1, mockQuoteID).Return(&executed, nil).Once()
+			},
+		},
+		{
+			Desc:       "execute expired",
+			Method:     "POST",
+			Path:       quotePath + "/execute",
+			Auth:       mockAuth2,
+			ExpCode:    http.StatusConflict,
+			ExpErrCode: "QUOTE_EXPIRED",
+			setup: func() {
+				s.mockSrv.On("ExecuteQuote", mockCtx, mockAccountID2, mockQuoteID).Return(nil, fx.ErrQuoteExpired).Once()
+			},
+		},
+		{
+			Desc:    "unauthorized case",
+			Method:  "POST",
+			Path:    quotePath + "/execute",
+			ExpCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, t := range tests {
+		if t.setup != nil {
+			t.setup()
+		}
+
+		header := apitest.RequestHeader()
+		header.Set("Authorization", t.Auth)
+
+		req, err := http.NewRequest(t.Method, t.Path, bytes.NewBufferString(t.Payload))
+		req.Header = header
+		s.Require().NoError(err, t.Desc)
+
+		rr := httptest.NewRecorder()
+		s.router.ServeHTTP(rr, req)
+		s.Require().Equal(t.ExpCode, rr.Code, t.Desc)
+
+		resp := map[string]interface{}{}
+		s.Require().NoError(json.Unmarshal(rr.Body.Bytes(), &resp), t.Desc)
+		if t.ExpErrCode != "" {
+			s.Require().Equal(t.ExpErrCode, resp["errCode"], t.Desc)
+		}
+		if t.ExpStatus != "" {
+			s.Require().Equal(t.ExpStatus, resp["status"], t.Desc)
+		}
+	}
+}
+
+func usd(amount int64) money.Money {
+	return money.New(amount, "USD")
+}
@@ -0,0 +1,68 @@
+package health
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	mHealth "github.com/n3k0fi5t/wallet/app/models/health"
+	"github.com/n3k0fi5t/wallet/app/service/health"
+)
+
+// NewHandler ...
+func NewHandler(h health.Service) *Handler {
+	return &Handler{
+		healthSrv: h,
+	}
+}
+
+type Handler struct {
+	healthSrv health.Service
+}
+
+// Handle registers probes, they are served without auth for orchestrators and load balancers
+func (h *Handler) Handle(routerGroup *gin.RouterGroup) {
+	routerGroup.Handle("GET", "/healthz", h.live)
+	routerGroup.Handle("GET", "/readyz", h.ready)
+}
+
+type checkResp struct {
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"durationMs"`
+}
+
+type reportResp struct {
+	Status string      `json:"status"`
+	Checks []checkResp `json:"checks"`
+}
+
+// response writes the report, 503 makes probes fail if any check is down
+func response(c *gin.Context, report *mHealth.Report) {
+	resp := reportResp{
+		Status: string(report.Status),
+		Checks: make([]checkResp, 0, len(report.Checks)),
+	}
+	for _, res := range report.Checks {
+		resp.Checks = append(resp.Checks, checkResp{
+			Name:       res.Name,
+			Status:     string(res.Status),
+			Error:      res.Error,
+			DurationMs: res.DurationMs,
+		})
+	}
+
+	code := http.StatusOK
+	if report.Status != mHealth.Status_UP {
+		code = http.StatusServiceUnavailable
+	}
+	c.JSON(code, resp)
+}
+
+func (h *Handler) live(c *gin.Context) {
+	response(c, h.healthSrv.Live(c.Request.Context()))
+}
+
+func (h *Handler) ready(c *gin.Context) {
+	response(c, h.healthSrv.Ready(c.Request.Context()))
+}
@@ -0,0 +1,102 @@
+package health
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	mdHealth "github.com/n3k0fi5t/wallet/app/models/health"
+	mockSrv "github.com/n3k0fi5t/wallet/app/service/health/mocks"
+)
+
+type testSuite struct {
+	suite.Suite
+
+	router  *gin.Engine
+	mockSrv *mockSrv.Service
+}
+
+func (s *testSuite) SetupSuite() {
+	gin.SetMode(gin.TestMode)
+	s.router = gin.New()
+	s.mockSrv = &mockSrv.Service{}
+	NewHandler(s.mockSrv).Handle(&s.router.RouterGroup)
+}
+
+func (s *testSuite) TearDownSuite() {
+	s.mockSrv.AssertExpectations(s.T())
+}
+
+func TestSuite(t *testing.T) {
+	suite.Run(t, new(testSuite))
+}
+
+func (s *testSuite) TestProbes() {
+	tests := []struct {
+		Desc      string
+		Path      string
+		ExpStatus int
+		ExpResp   reportResp
+		setup     func()
+	}{
+		{
+			Desc:      "live",
+			Path:      "/healthz",
+			ExpStatus: http.StatusOK,
+			ExpResp:   reportResp{Status: "UP", Checks: []checkResp{}},
+			setup: func() {
+				s.mockSrv.On("Live", mock.Anything).Return(&mdHealth.Report{Status: mdHealth.Status_UP}).Once()
+			},
+		},
+		{
+			Desc:      "ready",
+			Path:      "/readyz",
+			ExpStatus: http.StatusOK,
+			ExpResp: reportResp{Status: "UP", Checks: []checkResp{
+				{Name: "mysql", Status: "UP", DurationMs: 3},
+			}},
+			setup: func() {
+				s.mockSrv.On("Ready", mock.Anything).Return(&mdHealth.Report{
+					Status: mdHealth.Status_UP,
+					Checks: []*mdHealth.CheckResult{{Name: "mysql", Status: mdHealth.Status_UP, DurationMs: 3}},
+				}).Once()
+			},
+		},
+		{
+			Desc:      "not ready",
+			Path:      "/readyz",
+			ExpStatus: http.StatusServiceUnavailable,
+			ExpResp: reportResp{Status: "DOWN", Checks: []checkResp{
+				{Name: "mysql", Status: "UP", DurationMs: 3},
+				{Name: "shutdown", Status: "DOWN", Error: "shutting down"},
+			}},
+			setup: func() {
+				s.mockSrv.On("Ready", mock.Anything).Return(&mdHealth.Report{
+					Status: mdHealth.Status_DOWN,
+					Checks: []*mdHealth.CheckResult{
+						{Name: "mysql", Status: mdHealth.Status_UP, DurationMs: 3},
+						{Name: "shutdown", Status: mdHealth.Status_DOWN, Error: "shutting down"},
+					},
+				}).Once()
+			},
+		},
+	}
+
+	for _, t := range tests {
+		t.setup()
+
+		req := httptest.NewRequest("GET", t.Path, nil)
+		w := httptest.NewRecorder()
+		s.router.ServeHTTP(w, req)
+		s.Require().Equal(t.ExpStatus, w.Code, t.Desc)
+
+		resp := reportResp{}
+		s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &resp), t.Desc)
+		s.Require().Equal(t.ExpResp, resp, t.Desc)
+	}
+}
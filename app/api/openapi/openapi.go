@@ -0,0 +1,28 @@
+package openapi
+
+import (
+	_ "embed"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Spec is the OpenAPI 3 document of the REST API, paths are relative to /api/v1
+//
+//go:embed openapi.json
+var Spec []byte
+
+// NewHandler ...
+func NewHandler() *Handler {
+	return &Handler{}
+}
+
+type Handler struct{}
+
+func (h *Handler) Handle(routerGroup *gin.RouterGroup) {
+	routerGroup.Handle("GET", "/openapi.json", h.getSpec)
+}
+
+func (h *Handler) getSpec(c *gin.Context) {
+	c.Data(http.StatusOK, "application/json", Spec)
+}
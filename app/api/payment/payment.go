@@ -0,0 +1,217 @@
+package payment
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/n3k0fi5t/wallet/app/api/apierror"
+	"github.com/n3k0fi5t/wallet/app/middleware"
+	mPayment "github.com/n3k0fi5t/wallet/app/models/payment"
+	"github.com/n3k0fi5t/wallet/app/money"
+	rPayment "github.com/n3k0fi5t/wallet/app/repository/payment"
+	"github.com/n3k0fi5t/wallet/app/service/payment"
+	"github.com/n3k0fi5t/wallet/app/util"
+)
+
+const (
+	defaultLimit = 50
+	maxLimit     = 500
+)
+
+var (
+	errInvalidOffset    = fmt.Errorf("invalid offset")
+	errInvalidLimit     = fmt.Errorf("invalid limit")
+	errInvalidDirection = fmt.Errorf("invalid direction")
+	errInvalidStatus    = fmt.Errorf("invalid status")
+
+	timeNowMs = util.TimeNowMs
+)
+
+// NewHandler ...
+func NewHandler(p payment.Service) *Handler {
+	return &Handler{
+		paymentSrv: p,
+	}
+}
+
+type Handler struct {
+	paymentSrv payment.Service
+}
+
+func (h *Handler) Handle(routerGroup *gin.RouterGroup) {
+	rg := routerGroup.Group("/wallet/payment-requests")
+
+	// APIs are only for authed user
+	rg.Use(middleware.GetUserAccount())
+
+	rg.Handle("POST", "", h.createRequest)
+	rg.Handle("GET", "", h.listRequests)
+
+	// request relative
+	prg := rg.Group("/:requestID")
+	prg.Handle("GET", "", h.getRequest)
+	prg.Handle("POST", "/accept", h.accept)
+	prg.Handle("POST", "/decline", h.decline)
+	prg.Handle("POST", "/cancel", h.cancel)
+}
+
+// errorStatus maps service errors to HTTP status code
+func errorStatus(err error) int {
+	switch err {
+	case payment.ErrInvalidAmount, payment.ErrInvalidExpiry, payment.ErrMemoTooLong, payment.ErrSelfRequest:
+		return http.StatusBadRequest
+	case rPayment.ErrRequestNotExist:
+		return http.StatusNotFound
+	case payment.ErrRequestNotPending:
+		return http.StatusConflict
+	default:
+		return apierror.Status(err)
+	}
+}
+
+// errorCodes are machine readable codes of service errors, clients match errors by them
+var errorCodes = map[error]string{
+	payment.ErrInvalidAmount:     "INVALID_AMOUNT",
+	payment.ErrInvalidExpiry:     "INVALID_EXPIRY",
+	payment.ErrMemoTooLong:       "MEMO_TOO_LONG",
+	payment.ErrSelfRequest:       "SELF_REQUEST",
+	payment.ErrRequestNotPending: "REQUEST_NOT_PENDING",
+	rPayment.ErrRequestNotExist:  "REQUEST_NOT_EXIST",
+}
+
+func responseError(c *gin.Context, code int, err error) {
+	apierror.Response(c, code, err, errorCodes)
+}
+
+type requestResp struct {
+	RequestID   string          `json:"requestID"`
+	RequesterID string          `json:"requesterID"`
+	PayerID     string          `json:"payerID"`
+	Amount      money.Money     `json:"amount"`
+	Memo        string          `json:"memo"`
+	Status      mPayment.Status `json:"status"`
+	TradeID     string          `json:"tradeID,omitempty"`
+	ExpiresMs   int64           `json:"expiresMs"`
+	CreatedMs   int64           `json:"createdMs"`
+	UpdatedMs   int64           `json:"updatedMs"`
+}
+
+func toRequestResp(r *mPayment.Request) requestResp {
+	return requestResp{
+		RequestID:   r.RequestID,
+		RequesterID: r.RequesterID,
+		PayerID:     r.PayerID,
+		Amount:      r.Amount,
+		Memo:        r.Memo,
+		Status:      r.StatusAt(timeNowMs()),
+		TradeID:     r.TradeID,
+		ExpiresMs:   r.ExpiresMs,
+		CreatedMs:   r.CreatedMs,
+		UpdatedMs:   r.UpdatedMs,
+	}
+}
+
+type createRequestParam struct {
+	PayerID string      `json:"payerID"`
+	Amount  money.Money `json:"amount"`
+	Memo    string      `json:"memo"`
+	// ExpiresMs is optional, requests expire in 7 days by default
+	ExpiresMs int64 `json:"expiresMs"`
+}
+
+func (h *Handler) createRequest(c *gin.Context) {
+	ctx := c.MustGet("ctx").(context.Context)
+	accountID := c.MustGet("accountID").(string)
+
+	param := createRequestParam{}
+	if err := c.ShouldBindBodyWith(&param, binding.JSON); err != nil {
+		responseError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	request, err := h.paymentSrv.CreateRequest(ctx, accountID, param.PayerID, param.Amount, param.Memo, param.ExpiresMs)
+	if err != nil {
+		responseError(c, errorStatus(err), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toRequestResp(request))
+}
+
+type listRequestsResp struct {
+	Requests []requestResp `json:"requests"`
+}
+
+func (h *Handler) listRequests(c *gin.Context) {
+	ctx := c.MustGet("ctx").(context.Context)
+	accountID := c.MustGet("accountID").(string)
+
+	direction := mPayment.Direction(c.DefaultQuery("direction", string(mPayment.Direction_INCOMING)))
+	if !direction.IsValid() {
+		responseError(c, http.StatusBadRequest, errInvalidDirection)
+		return
+	}
+	status := mPayment.Status(c.Query("status"))
+	if status != "" && !status.IsValid() {
+		responseError(c, http.StatusBadRequest, errInvalidStatus)
+		return
+	}
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		responseError(c, http.StatusBadRequest, errInvalidOffset)
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultLimit)))
+	if err != nil || limit <= 0 || limit > maxLimit {
+		responseError(c, http.StatusBadRequest, errInvalidLimit)
+		return
+	}
+
+	requests, err := h.paymentSrv.ListRequests(ctx, accountID, direction, status, offset, limit)
+	if err != nil {
+		responseError(c, errorStatus(err), err)
+		return
+	}
+
+	resp := listRequestsResp{
+		Requests: make([]requestResp, 0, len(requests)),
+	}
+	for _, request := range requests {
+		resp.Requests = append(resp.Requests, toRequestResp(request))
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *Handler) getRequest(c *gin.Context) {
+	h.handleRequest(c, h.paymentSrv.GetRequest)
+}
+
+func (h *Handler) accept(c *gin.Context) {
+	h.handleRequest(c, h.paymentSrv.Accept)
+}
+
+func (h *Handler) decline(c *gin.Context) {
+	h.handleRequest(c, h.paymentSrv.Decline)
+}
+
+func (h *Handler) cancel(c *gin.Context) {
+	h.handleRequest(c, h.paymentSrv.Cancel)
+}
+
+// handleRequest responds the request in path after the action of the user on it
+func (h *Handler) handleRequest(c *gin.Context, action func(ctx context.Context, accountID, requestID string) (*mPayment.Request, error)) {
+	ctx := c.MustGet("ctx").(context.Context)
+	accountID := c.MustGet("accountID").(string)
+
+	request, err := action(ctx, accountID, c.Param("requestID"))
+	if err != nil {
+		responseError(c, errorStatus(err), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toRequestResp(request))
+}
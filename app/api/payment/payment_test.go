@@ -0,0 +1,359 @@
+package payment
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/n3k0fi5t/wallet/app/api/apitest"
+	mdPayment "github.com/n3k0fi5t/wallet/app/models/payment"
+	"github.com/n3k0fi5t/wallet/app/money"
+	"github.com/n3k0fi5t/wallet/app/repository/bank"
+	rPayment "github.com/n3k0fi5t/wallet/app/repository/payment"
+	"github.com/n3k0fi5t/wallet/app/service/payment"
+	mockSrv "github.com/n3k0fi5t/wallet/app/service/payment/mocks"
+)
+
+var (
+	mockCtx        = context.Background()
+	mockAccountID1 = "935f871a-660f-4f19-801e-916c04bb0324"
+	mockAccountID2 = "a89b7b78-b9c1-4129-8cff-380bf53f3a49"
+	mockAuth1      = "Tim"
+	mockRequestID  = "5e0e8b3c-1f6a-4c39-9a52-6b4a8f1b0d7e"
+	mockTradeID    = "c1b2d7a4-3f2e-4a8b-9d55-0e6f7a8b9c01"
+	mockTimeMs     = int64(1650000000000)
+	mockRequest    = &mdPayment.Request{
+		RequestID:   mockRequestID,
+		RequesterID: mockAccountID2,
+		PayerID:     mockAccountID1,
+		Amount:      usd(1000),
+		Memo:        "lunch",
+		Status:      mdPayment.Status_PENDING,
+		ExpiresMs:   mockTimeMs + 1000,
+		CreatedMs:   mockTimeMs - 1000,
+		UpdatedMs:   mockTimeMs - 1000,
+	}
+
+	mockHandleCtxMiddleware = func() gin.HandlerFunc {
+		return func(c *gin.Context) {
+			c.Set("ctx", mockCtx)
+			c.Next()
+		}
+	}
+)
+
+type testSuite struct {
+	suite.Suite
+
+	router  *gin.Engine
+	mockSrv *mockSrv.Service
+	psrv    payment.Service
+}
+
+func (s *testSuite) SetupSuite() {
+	timeNowMs = func() int64 { return mockTimeMs }
+
+	s.router = gin.Default()
+	s.mockSrv = &mockSrv.Service{}
+	s.psrv = s.mockSrv
+	handler := NewHandler(s.psrv)
+	rg := s.router.Group("/api/v1")
+	rg.Use(mockHandleCtxMiddleware())
+	handler.Handle(rg)
+}
+
+func (s *testSuite) TearDownSuite() {
+	s.mockSrv.AssertExpectations(s.T())
+}
+
+func TestSuite(t *testing.T) {
+	suite.Run(t, new(testSuite))
+}
+
+func (s *testSuite) TestCreateRequest() {
+	genPayload := func(d createRequestParam) []byte {
+		b, err := json.Marshal(d)
+		s.Require().NoError(err)
+		return b
+	}
+
+	tests := []struct {
+		Desc       string
+		Payload    []byte
+		ExpCode    int
+		ExpErrCode string
+		Auth       string
+		setup      func()
+	}{
+		{
+			Desc: "normal case",
+			setup: func() {
+				s.mockSrv.On("CreateRequest", mockCtx, mockAccountID1, mockAccountID2, usd(1000), "lunch", int64(0)).Return(mockRequest, nil).Once()
+			},
+			Payload: genPayload(createRequestParam{PayerID: mockAccountID2, Amount: usd(1000), Memo: "lunch"}),
+			Auth:    mockAuth1,
+			ExpCode: http.StatusOK,
+		},
+		{
+			Desc: "self request",
+			setup: func() {
+				s.mockSrv.On("CreateRequest", mockCtx, mockAccountID1, mockAccountID1, usd(1000), "", int64(0)).Return(nil, payment.ErrSelfRequest).Once()
+			},
+			Payload:    genPayload(createRequestParam{PayerID: mockAccountID1, Amount: usd(1000)}),
+			Auth:       mockAuth1,
+			ExpCode:    http.StatusBadRequest,
+			ExpErrCode: "SELF_REQUEST",
+		},
+		{
+			Desc: "payer not exist",
+			setup: func() {
+				s.mockSrv.On("CreateRequest", mockCtx, mockAccountID1, "nobody", usd(1000), "", int64(0)).Return(nil, bank.ErrAccountNotExist).Once()
+			},
+			Payload:    genPayload(createRequestParam{PayerID: "nobody", Amount: usd(1000)}),
+			Auth:       mockAuth1,
+			ExpCode:    http.StatusNotFound,
+			ExpErrCode: "ACCOUNT_NOT_EXIST",
+		},
+		{
+			Desc:    "unauthorized case",
+			Auth:    "",
+			ExpCode: http.StatusUnauthorized,
+		},
+		{
+			Desc:    "bad param",
+			Auth:    mockAuth1,
+			ExpCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, t := range tests {
+		if t.setup != nil {
+			t.setup()
+		}
+
+		header := apitest.RequestHeader()
+		header.Set("Authorization", t.Auth)
+
+		req, err := http.NewRequest("POST", "/api/v1/wallet/payment-requests", bytes.NewBuffer(t.Payload))
+		req.Header = header
+		s.Require().NoError(err, t.Desc)
+
+		rr := httptest.NewRecorder()
+		s.router.ServeHTTP(rr, req)
+		s.Require().Equal(t.ExpCode, rr.Code, t.Desc)
+
+		if t.ExpErrCode != "" {
+			resp := map[string]string{}
+			s.Require().NoError(json.Unmarshal(rr.Body.Bytes(), &resp), t.Desc)
+			s.Require().Equal(t.ExpErrCode, resp["errCode"], t.Desc)
+		}
+	}
+}
+
+func (s *testSuite) TestListRequests() {
+	expired := *mockRequest
+	expired.ExpiresMs = mockTimeMs
+
+	tests := []struct {
+		Desc        string
+		Query       string
+		ExpCode     int
+		ExpStatuses []mdPayment.Status
+		setup       func()
+	}{
+		{
+			Desc:  "normal case, incoming by default",
+			Query: "",
+			setup: func() {
+				s.mockSrv.On("ListRequests", mockCtx, mockAccountID1, mdPayment.Direction_INCOMING, mdPayment.Status(""), 0, defaultLimit).Return([]*mdPayment.Request{mockRequest, &expired}, nil).Once()
+			},
+			ExpCode:     http.StatusOK,
+			ExpStatuses: []mdPayment.Status{mdPayment.Status_PENDING, mdPayment.Status_EXPIRED},
+		},
+		{
+			Desc:  "normal case, outgoing paid",
+			Query: "?direction=outgoing&status=PAID&offset=10&limit=5",
+			setup: func() {
+				s.mockSrv.On("ListRequests", mockCtx, mockAccountID1, mdPayment.Direction_OUTGOING, mdPayment.Status_PAID, 10, 5).Return([]*mdPayment.Request{}, nil).Once()
+			},
+			ExpCode:     http.StatusOK,
+			ExpStatuses: []mdPayment.Status{},
+		},
+		{
+			Desc:    "bad direction",
+			Query:   "?direction=sideways",
+			ExpCode: http.StatusBadRequest,
+		},
+		{
+			Desc:    "bad status",
+			Query:   "?status=LOST",
+			ExpCode: http.StatusBadRequest,
+		},
+		{
+			Desc:    "bad limit",
+			Query:   "?limit=100000",
+			ExpCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, t := range tests {
+		if t.setup != nil {
+			t.setup()
+		}
+
+		header := apitest.RequestHeader()
+		header.Set("Authorization", mockAuth1)
+
+		req, err := http.NewRequest("GET", "/api/v1/wallet/payment-requests"+t.Query, nil)
+		req.Header = header
+		s.Require().NoError(err, t.Desc)
+
+		rr := httptest.NewRecorder()
+		s.router.ServeHTTP(rr, req)
+		s.Require().Equal(t.ExpCode, rr.Code, t.Desc)
+
+		if t.ExpCode == http.StatusOK {
+			resp := listRequestsResp{}
+			s.Require().NoError(json.Unmarshal(rr.Body.Bytes(), &resp), t.Desc)
+			statuses := []mdPayment.Status{}
+			for _, r := range resp.Requests {
+				statuses = append(statuses, r.Status)
+			}
+			s.Require().Equal(t.ExpStatuses, statuses, t.Desc)
+		}
+	}
+}
+
+func (s *testSuite) TestActions() {
+	paid := *mockRequest
+	paid.Status = mdPayment.Status_PAID
+	paid.TradeID = mockTradeID
+
+	tests := []struct {
+		Desc       string
+		Method     string
+		Path       string
+		ExpCode    int
+		ExpErrCode string
+		ExpResp    *requestResp
+		setup      func()
+	}{
+		{
+			Desc:   "get request",
+			Method: "GET",
+			Path:   "",
+			setup: func() {
+				s.mockSrv.On("GetRequest", mockCtx, mockAccountID1, mockRequestID).Return(mockRequest, nil).Once()
+			},
+			ExpCode: http.StatusOK,
+		},
+		{
+			Desc:   "get request not exist",
+			Method: "GET",
+			Path:   "",
+			setup: func() {
+				s.mockSrv.On("GetRequest", mockCtx, mockAccountID1, mockRequestID).Return(nil, rPayment.ErrRequestNotExist).Once()
+			},
+			ExpCode:    http.StatusNotFound,
+			ExpErrCode: "REQUEST_NOT_EXIST",
+		},
+		{
+			Desc:   "accept",
+			Method: "POST",
+			Path:   "/accept",
+			setup: func() {
+				s.mockSrv.On("Accept", mockCtx, mockAccountID1, mockRequestID).Return(&paid, nil).Once()
+			},
+			ExpCode: http.StatusOK,
+			ExpResp: &requestResp{
+				RequestID:   mockRequestID,
+				RequesterID: mockAccountID2,
+				PayerID:     mockAccountID1,
+				Amount:      usd(1000),
+				Memo:        "lunch",
+				Status:      mdPayment.Status_PAID,
+				TradeID:     mockTradeID,
+				ExpiresMs:   mockRequest.ExpiresMs,
+				CreatedMs:   mockRequest.CreatedMs,
+				UpdatedMs:   mockRequest.UpdatedMs,
+			},
+		},
+		{
+			Desc:   "accept without enough balance",
+			Method: "POST",
+			Path:   "/accept",
+			setup: func() {
+				s.mockSrv.On("Accept", mockCtx, mockAccountID1, mockRequestID).Return(nil, bank.ErrBalanceNotEnough).Once()
+			},
+			ExpCode:    http.StatusConflict,
+			ExpErrCode: "BALANCE_NOT_ENOUGH",
+		},
+		{
+			Desc:   "accept twice",
+			Method: "POST",
+			Path:   "/accept",
+			setup: func() {
+				s.mockSrv.On("Accept", mockCtx, mockAccountID1, mockRequestID).Return(nil, payment.ErrRequestNotPending).Once()
+			},
+			ExpCode:    http.StatusConflict,
+			ExpErrCode: "REQUEST_NOT_PENDING",
+		},
+		{
+			Desc:   "decline",
+			Method: "POST",
+			Path:   "/decline",
+			setup: func() {
+				s.mockSrv.On("Decline", mockCtx, mockAccountID1, mockRequestID).Return(mockRequest, nil).Once()
+			},
+			ExpCode: http.StatusOK,
+		},
+		{
+			Desc:   "cancel",
+			Method: "POST",
+			Path:   "/cancel",
+			setup: func() {
+				s.mockSrv.On("Cancel", mockCtx, mockAccountID1, mockRequestID).Return(mockRequest, nil).Once()
+			},
+			ExpCode: http.StatusOK,
+		},
+	}
+
+	for _, t := range tests {
+		if t.setup != nil {
+			t.setup()
+		}
+
+		header := apitest.RequestHeader()
+		header.Set("Authorization", mockAuth1)
+
+		req, err := http.NewRequest(t.Method, "/api/v1/wallet/payment-requests/"+mockRequestID+t.Path, nil)
+		req.Header = header
+		s.Require().NoError(err, t.Desc)
+
+		rr := httptest.NewRecorder()
+		s.router.ServeHTTP(rr, req)
+		s.Require().Equal(t.ExpCode, rr.Code, t.Desc)
+
+		if t.ExpErrCode != "" {
+			resp := map[string]string{}
+			s.Require().NoError(json.Unmarshal(rr.Body.Bytes(), &resp), t.Desc)
+			s.Require().Equal(t.ExpErrCode, resp["errCode"], t.Desc)
+		}
+		if t.ExpResp != nil {
+			resp := requestResp{}
+			s.Require().NoError(json.Unmarshal(rr.Body.Bytes(), &resp), t.Desc)
+			s.Require().Equal(*t.ExpResp, resp, t.Desc)
+		}
+	}
+}
+
+func usd(amount int64) money.Money {
+	return money.New(amount, "USD")
+}
@@ -1,32 +1,201 @@
 package api
 
 import (
+	"sync"
+	"time"
+
 	"github.com/gin-gonic/gin"
+	"github.com/n3k0fi5t/wallet/app/api/admin"
+	"github.com/n3k0fi5t/wallet/app/api/approval"
+	"github.com/n3k0fi5t/wallet/app/api/bill"
+	"github.com/n3k0fi5t/wallet/app/api/escrow"
+	"github.com/n3k0fi5t/wallet/app/api/funding"
+	"github.com/n3k0fi5t/wallet/app/api/fx"
+	"github.com/n3k0fi5t/wallet/app/api/health"
+	"github.com/n3k0fi5t/wallet/app/api/openapi"
+	"github.com/n3k0fi5t/wallet/app/api/payment"
+	"github.com/n3k0fi5t/wallet/app/api/rpc"
 	"github.com/n3k0fi5t/wallet/app/api/wallet"
+	"github.com/n3k0fi5t/wallet/app/api/webhook"
+	"github.com/n3k0fi5t/wallet/app/broker"
 	"github.com/n3k0fi5t/wallet/app/middleware"
+	"github.com/n3k0fi5t/wallet/app/ratelimit"
+	rAdmin "github.com/n3k0fi5t/wallet/app/repository/admin"
+	rApproval "github.com/n3k0fi5t/wallet/app/repository/approval"
+	"github.com/n3k0fi5t/wallet/app/repository/audit"
 	"github.com/n3k0fi5t/wallet/app/repository/bank"
+	rBill "github.com/n3k0fi5t/wallet/app/repository/bill"
+	rEscrow "github.com/n3k0fi5t/wallet/app/repository/escrow"
+	rFunding "github.com/n3k0fi5t/wallet/app/repository/funding"
+	rFx "github.com/n3k0fi5t/wallet/app/repository/fx"
+	"github.com/n3k0fi5t/wallet/app/repository/member"
+	"github.com/n3k0fi5t/wallet/app/repository/outbox"
+	rPayment "github.com/n3k0fi5t/wallet/app/repository/payment"
+	rWebhook "github.com/n3k0fi5t/wallet/app/repository/webhook"
+	aSrv "github.com/n3k0fi5t/wallet/app/service/admin"
+	apSrv "github.com/n3k0fi5t/wallet/app/service/approval"
+	bSrv "github.com/n3k0fi5t/wallet/app/service/bill"
+	eSrv "github.com/n3k0fi5t/wallet/app/service/escrow"
+	fdSrv "github.com/n3k0fi5t/wallet/app/service/funding"
+	fSrv "github.com/n3k0fi5t/wallet/app/service/fx"
+	hSrv "github.com/n3k0fi5t/wallet/app/service/health"
+	pSrv "github.com/n3k0fi5t/wallet/app/service/payment"
+	"github.com/n3k0fi5t/wallet/app/service/relay"
 	wSrv "github.com/n3k0fi5t/wallet/app/service/wallet"
+	wbSrv "github.com/n3k0fi5t/wallet/app/service/webhook"
+	sFx "github.com/n3k0fi5t/wallet/app/setup/fx"
 	"github.com/n3k0fi5t/wallet/app/setup/mysql"
+	"github.com/n3k0fi5t/wallet/app/setup/publisher"
+	sRail "github.com/n3k0fi5t/wallet/app/setup/rail"
+	sRateLimit "github.com/n3k0fi5t/wallet/app/setup/ratelimit"
+	"github.com/n3k0fi5t/wallet/app/setup/tracing"
+	"github.com/n3k0fi5t/wallet/common/sql"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"google.golang.org/grpc"
+)
+
+const (
+	webhookTimeout = 10 * time.Second
+
+	// liabilitiesTimeout bounds the query of the liabilities gauge on each scrape
+	liabilitiesTimeout = 5 * time.Second
+
+	// healthCheckTimeout bounds each readiness check, probes time out after a few seconds
+	healthCheckTimeout = 2 * time.Second
+
+	// updatesBuffer is the number of balance updates a slow event stream may fall behind before it is dropped
+	updatesBuffer = 64
 )
 
-func BuildWalletHandler() *wallet.Handler {
+var (
+	webhookSrv     wbSrv.Service
+	webhookSrvOnce sync.Once
+)
+
+// Services are the dependencies of API handlers, tests can fill them with fakes
+type Services struct {
+	Wallet   wSrv.Service
+	Admin    aSrv.Service
+	Webhook  wbSrv.Service
+	Audit    audit.Audit
+	Health   hSrv.Service
+	Payment  pSrv.Service
+	Bill     bSrv.Service
+	Escrow   eSrv.Service
+	Approval apSrv.Service
+	FX       fSrv.Service
+	Funding  fdSrv.Service
+
+	// Broker fans out balance updates to event streams, it is closed on shutdown to end them
+	Broker broker.Broker
+
+	// Limiter limits API requests by RateLimits, nil means unlimited
+	Limiter    ratelimit.Limiter
+	RateLimits ratelimit.Policy
+}
+
+// BuildServices builds services on top of MySQL
+func BuildServices() *Services {
 	// It's could be better if we use DI container
 	db := mysql.GetMySQL()
-	b := bank.NewBank(db)
-	walletSrv := wSrv.NewWallet(b)
-	return wallet.NewHandler(walletSrv)
+	au := audit.NewAudit(db)
+	br := broker.NewBroker(updatesBuffer)
+	b := bank.NewInstrumentedBank(bank.NewBank(db, au, outbox.NewOutbox(db), br))
+	a := rAdmin.NewAdmin(db, au)
+
+	prometheus.MustRegister(
+		collectors.NewDBStatsCollector(db.DB, "wallet"),
+		bank.NewLiabilitiesCollector(b, liabilitiesTimeout),
+	)
+
+	ap := rApproval.NewApproval(db, au)
+	w := wSrv.NewTracedWallet(wSrv.NewInstrumentedWallet(wSrv.NewWallet(sql.NewTransactor(db), b, member.NewMember(db, au), ap, br)))
+
+	return &Services{
+		Wallet:   w,
+		Admin:    aSrv.NewAdmin(sql.NewTransactor(db), b, a, au),
+		Payment:  pSrv.NewPayment(sql.NewTransactor(db), rPayment.NewPayment(db), w),
+		Bill:     bSrv.NewBill(sql.NewTransactor(db), rBill.NewBill(db), outbox.NewOutbox(db), w),
+		Escrow:   eSrv.NewEscrow(sql.NewTransactor(db), b, rEscrow.NewEscrow(db), a, w, eSrv.DefaultConfig),
+		Approval: apSrv.NewApproval(sql.NewTransactor(db), b, ap, w, apSrv.DefaultConfig),
+		FX:       fSrv.NewFX(sql.NewTransactor(db), b, rFx.NewFX(db), w, sFx.GetProvider(), sFx.GetConfig()),
+		Funding:  fdSrv.NewFunding(sql.NewTransactor(db), b, rFunding.NewFunding(db), w, sRail.GetAdapters()),
+		Webhook:  GetWebhookService(),
+		Audit:    au,
+		Broker:   br,
+		Health: hSrv.NewHealth(healthCheckTimeout,
+			hSrv.Check{Name: "mysql", Check: db.PingContext},
+			hSrv.Check{Name: "migration", Check: mysql.CheckSchemaVersion},
+		),
+		Limiter:    sRateLimit.GetLimiter(),
+		RateLimits: sRateLimit.GetPolicy(),
+	}
+}
+
+// BuildRelay builds the relay publishing outbox events to downstream
+func BuildRelay() relay.Relay {
+	db := mysql.GetMySQL()
+	return relay.NewRelay(sql.NewTransactor(db), outbox.NewOutbox(db), publisher.GetPublisher(), publisher.GetPollInterval(), publisher.GetBatchSize())
 }
 
-func BuildRouter() *gin.Engine {
-	router := gin.Default()
+// GetWebhookService builds the webhook service once, it subscribes trade events relayed in process
+func GetWebhookService() wbSrv.Service {
+	webhookSrvOnce.Do(func() {
+		db := mysql.GetMySQL()
+		webhookSrv = wbSrv.NewWebhook(rWebhook.NewWebhook(db, audit.NewAudit(db)), wbSrv.NewClient(webhookTimeout), wbSrv.DefaultConfig)
+		publisher.GetInProcess().Subscribe(webhookSrv.HandleEvent)
+	})
+	return webhookSrv
+}
+
+func BuildRouter(s *Services) *gin.Engine {
+	router := gin.New()
+	router.Use(gin.Recovery(), middleware.RequestID(), middleware.AccessLog("/healthz", "/readyz", "/metrics"), middleware.Metrics())
+	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
+	health.NewHandler(s.Health).Handle(&router.RouterGroup)
 
 	api := router.Group("/api/v1")
 
+	// spans of API requests join the W3C trace context of incoming headers
+	api.Use(otelgin.Middleware(tracing.ServiceName))
+
 	// set context for following process
 	api.Use(middleware.SetHandleContext())
+	api.Use(middleware.AuditAuthFailure(s.Audit))
+	if s.Limiter != nil {
+		api.Use(middleware.RateLimit(s.Limiter, s.RateLimits))
+	}
 
-	walletHandler := BuildWalletHandler()
-	walletHandler.Handle(api)
+	wallet.NewHandler(s.Wallet).Handle(api)
+	admin.NewHandler(s.Admin).Handle(api)
+	webhook.NewHandler(s.Webhook, s.Wallet).Handle(api)
+	payment.NewHandler(s.Payment).Handle(api)
+	bill.NewHandler(s.Bill).Handle(api)
+	escrow.NewHandler(s.Escrow).Handle(api)
+	approval.NewHandler(s.Approval).Handle(api)
+	fx.NewHandler(s.FX).Handle(api)
+	funding.NewHandler(s.Funding).Handle(api)
+	openapi.NewHandler().Handle(api)
 
 	return router
 }
+
+// BuildGRPCServer builds the gRPC server sharing wallet.Service with REST handlers
+func BuildGRPCServer(s *Services) *grpc.Server {
+	interceptors := []grpc.UnaryServerInterceptor{
+		rpc.SetHandleContext(),
+		rpc.AuditAuthFailure(s.Audit),
+	}
+	// calls share the budgets of REST requests
+	if s.Limiter != nil {
+		interceptors = append(interceptors, rpc.RateLimit(s.Limiter, s.RateLimits))
+	}
+	interceptors = append(interceptors, rpc.GetUserAccount())
+	gs := grpc.NewServer(grpc.ChainUnaryInterceptor(interceptors...))
+
+	rpc.NewServer(s.Wallet).Register(gs)
+	return gs
+}
@@ -0,0 +1,306 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	mdAudit "github.com/n3k0fi5t/wallet/app/models/audit"
+	mdBank "github.com/n3k0fi5t/wallet/app/models/bank"
+	mdWallet "github.com/n3k0fi5t/wallet/app/models/wallet"
+	"github.com/n3k0fi5t/wallet/app/ratelimit"
+	mockAudit "github.com/n3k0fi5t/wallet/app/repository/audit/mocks"
+	mockAdmin "github.com/n3k0fi5t/wallet/app/service/admin/mocks"
+	mockWallet "github.com/n3k0fi5t/wallet/app/service/wallet/mocks"
+	mockWebhook "github.com/n3k0fi5t/wallet/app/service/webhook/mocks"
+)
+
+const basePath = "/api/v1"
+
+var pathParam = regexp.MustCompile(`:([^/]+)`)
+
+type spec struct {
+	OpenAPI string                                `json:"openapi"`
+	Servers []struct{ URL string }                `json:"servers"`
+	Paths   map[string]map[string]json.RawMessage `json:"paths"`
+}
+
+type testSuite struct {
+	suite.Suite
+
+	router *gin.Engine
+}
+
+func (s *testSuite) SetupSuite() {
+	gin.SetMode(gin.TestMode)
+	s.router = BuildRouter(&Services{
+		Wallet:  &mockWallet.Service{},
+		Admin:   &mockAdmin.Service{},
+		Webhook: &mockWebhook.Service{},
+		Audit:   &mockAudit.Audit{},
+	})
+}
+
+func TestSuite(t *testing.T) {
+	suite.Run(t, new(testSuite))
+}
+
+func (s *testSuite) getSpec() *spec {
+	req := httptest.NewRequest("GET", basePath+"/openapi.json", nil)
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, req)
+	s.Require().Equal(http.StatusOK, w.Code)
+	s.Require().Equal("application/json", w.Header().Get("Content-Type"))
+
+	doc := &spec{}
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), doc))
+	return doc
+}
+
+func (s *testSuite) TestSpec() {
+	doc := s.getSpec()
+	s.Require().True(strings.HasPrefix(doc.OpenAPI, "3."), doc.OpenAPI)
+	s.Require().Len(doc.Servers, 1)
+	s.Require().Equal(basePath, doc.Servers[0].URL)
+}
+
+// TestSpecCoversRoutes fails when a route is registered without being documented
+func (s *testSuite) TestSpecCoversRoutes() {
+	doc := s.getSpec()
+
+	// operational endpoints like /metrics are not part of the REST API
+	routes := gin.RoutesInfo{}
+	for _, r := range s.router.Routes() {
+		if strings.HasPrefix(r.Path, basePath+"/") {
+			routes = append(routes, r)
+		}
+	}
+	s.Require().NotEmpty(routes)
+	for _, r := range routes {
+		path := pathParam.ReplaceAllString(strings.TrimPrefix(r.Path, basePath), "{$1}")
+
+		ops, ok := doc.Paths[path]
+		s.Require().True(ok, "%s %s is not in openapi.json", r.Method, path)
+		_, ok = ops[strings.ToLower(r.Method)]
+		s.Require().True(ok, "%s %s is not in openapi.json", r.Method, path)
+	}
+
+	// documented operations exist as well
+	registered := map[string]bool{}
+	for _, r := range routes {
+		registered[r.Method+" "+pathParam.ReplaceAllString(strings.TrimPrefix(r.Path, basePath), "{$1}")] = true
+	}
+	for path, ops := range doc.Paths {
+		for method := range ops {
+			op := strings.ToUpper(method) + " " + path
+			s.Require().True(registered[op], "%s is documented but not registered", op)
+		}
+	}
+}
+
+func (s *testSuite) TestMetrics() {
+	s.getSpec()
+
+	req := httptest.NewRequest("GET", "/metrics", nil)
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, req)
+	s.Require().Equal(http.StatusOK, w.Code)
+	s.Require().Contains(w.Body.String(), `http_request_duration_seconds_count{method="GET",route="/api/v1/openapi.json",status="200"}`)
+}
+
+func (s *testSuite) TestTracing() {
+	exporter := tracetest.NewInMemoryExporter()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	mWallet := &mockWallet.Service{}
+	router := BuildRouter(&Services{
+		Wallet:  mWallet,
+		Admin:   &mockAdmin.Service{},
+		Webhook: &mockWebhook.Service{},
+		Audit:   &mockAudit.Audit{},
+	})
+
+	// the service is called under the span of the request
+	traceID := "4bf92f3577b34da6a3ce929d0e0e4736"
+	inTrace := mock.MatchedBy(func(ctx context.Context) bool {
+		return trace.SpanContextFromContext(ctx).TraceID().String() == traceID
+	})
+	mWallet.On("Authorize", inTrace, "935f871a-660f-4f19-801e-916c04bb0324", "935f871a-660f-4f19-801e-916c04bb0324").Return(&mdWallet.Member{}, nil).Once()
+	mWallet.On("GetAccount", inTrace, "935f871a-660f-4f19-801e-916c04bb0324").Return(&mdBank.Account{}, nil).Once()
+
+	req := httptest.NewRequest("GET", basePath+"/wallet/account", nil)
+	req.Header.Set("Authorization", "Tim")
+	req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	s.Require().Equal(http.StatusOK, w.Code)
+	mWallet.AssertExpectations(s.T())
+
+	spans := exporter.GetSpans()
+	s.Require().Len(spans, 1)
+	s.Require().Equal(basePath+"/wallet/account", spans[0].Name)
+	s.Require().Equal(traceID, spans[0].SpanContext.TraceID().String())
+	s.Require().Equal("00f067aa0ba902b7", spans[0].Parent.SpanID().String())
+}
+
+func (s *testSuite) TestRateLimit() {
+	mWallet := &mockWallet.Service{}
+	router := BuildRouter(&Services{
+		Wallet:  mWallet,
+		Admin:   &mockAdmin.Service{},
+		Webhook: &mockWebhook.Service{},
+		Audit:   &mockAudit.Audit{},
+		Limiter: ratelimit.NewMemory(),
+		RateLimits: ratelimit.Policy{
+			AccountRead:  ratelimit.Limit{Rate: 1, Burst: 1},
+			AccountWrite: ratelimit.Limit{Rate: 1, Burst: 1},
+			IPRead:       ratelimit.Limit{Rate: 1, Burst: 2},
+		},
+	})
+	mWallet.On("Authorize", mock.Anything, mock.Anything, mock.Anything).Return(&mdWallet.Member{}, nil)
+	mWallet.On("GetAccount", mock.Anything, mock.Anything).Return(&mdBank.Account{}, nil)
+
+	get := func(token string, remoteAddr ...string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", basePath+"/wallet/account", nil)
+		req.Header.Set("Authorization", token)
+		if len(remoteAddr) > 0 {
+			req.RemoteAddr = remoteAddr[0]
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get("Tim")
+	s.Require().Equal(http.StatusOK, w.Code)
+	s.Require().Equal("1", w.Header().Get("X-RateLimit-Limit"))
+	s.Require().Equal("0", w.Header().Get("X-RateLimit-Remaining"))
+	s.Require().Equal("1", w.Header().Get("X-RateLimit-Reset"))
+
+	// the account runs out of its budget
+	w = get("Tim")
+	s.Require().Equal(http.StatusTooManyRequests, w.Code)
+	s.Require().Equal("1", w.Header().Get("Retry-After"))
+	s.Require().JSONEq(`{"errCode": "RATE_LIMITED", "errMessage": "Rate limit exceeded"}`, w.Body.String())
+
+	// another account from the same IP takes the last token of the IP, rejected requests do not take one
+	s.Require().Equal(http.StatusOK, get("Alex").Code)
+	w = get("Arthur")
+	s.Require().Equal(http.StatusTooManyRequests, w.Code)
+	s.Require().Equal("2", w.Header().Get("X-RateLimit-Limit"))
+
+	// the account is not charged for the request rejected by the IP, so it goes on from another IP
+	s.Require().Equal(http.StatusOK, get("Arthur", "192.0.2.10:4321").Code)
+}
+
+func (s *testSuite) TestAccessLog() {
+	buf := &bytes.Buffer{}
+	out, formatter, level := logrus.StandardLogger().Out, logrus.StandardLogger().Formatter, logrus.GetLevel()
+	logrus.SetOutput(buf)
+	logrus.SetFormatter(&logrus.JSONFormatter{})
+	logrus.SetLevel(logrus.InfoLevel)
+	defer func() {
+		logrus.SetOutput(out)
+		logrus.SetFormatter(formatter)
+		logrus.SetLevel(level)
+	}()
+
+	mWallet := &mockWallet.Service{}
+	router := BuildRouter(&Services{
+		Wallet:  mWallet,
+		Admin:   &mockAdmin.Service{},
+		Webhook: &mockWebhook.Service{},
+		Audit:   &mockAudit.Audit{},
+	})
+	mWallet.On("Authorize", mock.Anything, "935f871a-660f-4f19-801e-916c04bb0324", "935f871a-660f-4f19-801e-916c04bb0324").Return(&mdWallet.Member{}, nil)
+	mWallet.On("GetAccount", mock.Anything, "935f871a-660f-4f19-801e-916c04bb0324").Return(&mdBank.Account{}, nil)
+
+	tests := []struct {
+		Desc      string
+		RequestID string
+		ExpEcho   bool
+	}{
+		{
+			Desc:      "honor request ID",
+			RequestID: "req-7f3a",
+			ExpEcho:   true,
+		},
+		{
+			Desc:      "replace invalid request ID",
+			RequestID: "req\nforged",
+			ExpEcho:   false,
+		},
+		{
+			Desc:    "generate request ID",
+			ExpEcho: false,
+		},
+	}
+
+	for _, t := range tests {
+		buf.Reset()
+		req := httptest.NewRequest("GET", basePath+"/wallet/account?access_token=leaked-secret", nil)
+		req.Header.Set("Authorization", "Tim")
+		req.Header.Set("X-Request-ID", t.RequestID)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		s.Require().Equal(http.StatusOK, w.Code, t.Desc)
+
+		requestID := w.Header().Get("X-Request-ID")
+		s.Require().NotEmpty(requestID, t.Desc)
+		s.Require().Equal(t.ExpEcho, requestID == t.RequestID, t.Desc)
+
+		entry := map[string]interface{}{}
+		s.Require().NoError(json.Unmarshal(buf.Bytes(), &entry), t.Desc)
+		s.Require().Equal("access", entry["msg"], t.Desc)
+		s.Require().Equal(requestID, entry["requestID"], t.Desc)
+		s.Require().Equal("935f871a-660f-4f19-801e-916c04bb0324", entry["accountID"], t.Desc)
+		s.Require().Equal(basePath+"/wallet/account", entry["route"], t.Desc)
+		s.Require().EqualValues(http.StatusOK, entry["status"], t.Desc)
+		s.Require().NotContains(buf.String(), "Tim", t.Desc)
+		s.Require().NotContains(buf.String(), "leaked-secret", t.Desc)
+		s.Require().NotContains(entry, "path", t.Desc)
+	}
+}
+
+func (s *testSuite) TestAuditAuthFailure() {
+	mWallet := &mockWallet.Service{}
+	mAudit := &mockAudit.Audit{}
+	router := BuildRouter(&Services{
+		Wallet:  mWallet,
+		Admin:   &mockAdmin.Service{},
+		Webhook: &mockWebhook.Service{},
+		Audit:   mAudit,
+	})
+
+	// the rejected request stops at auth, and the token is neither an actor nor a principal
+	noActor := mock.MatchedBy(func(ctx context.Context) bool {
+		md := mdAudit.MetadataFrom(ctx)
+		return md != nil && md.ActorType == mdAudit.ActorType_SYSTEM && md.ActorID == "" && md.Principal == ""
+	})
+	mAudit.On("Record", noActor, mdAudit.Action_AUTH_FAILURE, "GET "+basePath+"/wallet/account", nil, map[string]int{"status": http.StatusUnauthorized}).Return(nil).Once()
+
+	req := httptest.NewRequest("GET", basePath+"/wallet/account", nil)
+	req.Header.Set("Authorization", "forged-secret")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	s.Require().Equal(http.StatusUnauthorized, w.Code)
+
+	mWallet.AssertExpectations(s.T())
+	mAudit.AssertExpectations(s.T())
+}
@@ -0,0 +1,181 @@
+package rpc
+
+import (
+	"context"
+	"math"
+	"net"
+	"strconv"
+
+	"github.com/n3k0fi5t/wallet/app/middleware"
+	mAudit "github.com/n3k0fi5t/wallet/app/models/audit"
+	mBank "github.com/n3k0fi5t/wallet/app/models/bank"
+	"github.com/n3k0fi5t/wallet/app/ratelimit"
+	"github.com/n3k0fi5t/wallet/app/repository/audit"
+	"github.com/n3k0fi5t/wallet/app/util"
+	"github.com/n3k0fi5t/wallet/common/logger"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+const (
+	metadataAuthorization = "authorization"
+	metadataRequestID     = "x-request-id"
+	metadataUserAgent     = "user-agent"
+
+	// rate limit metadata mirrors the headers of the REST API
+	metadataRateLimitLimit     = "x-ratelimit-limit"
+	metadataRateLimitRemaining = "x-ratelimit-remaining"
+	metadataRateLimitReset     = "x-ratelimit-reset"
+
+	// metadataIdempotencyKey makes retried trades execute once
+	metadataIdempotencyKey  = "idempotency-key"
+	maxIdempotencyKeyLength = 128
+)
+
+// readMethods take tokens from the read budgets of the rate limit, other methods from the write budgets
+var readMethods = map[string]bool{
+	"/wallet.v1.WalletService/GetAccount":       true,
+	"/wallet.v1.WalletService/ListTransactions": true,
+}
+
+type accountIDKey struct{}
+
+type userIDKey struct{}
+
+// accountIDFrom returns the authed account set by GetUserAccount
+func accountIDFrom(ctx context.Context) string {
+	accountID, _ := ctx.Value(accountIDKey{}).(string)
+	return accountID
+}
+
+// userIDFrom returns the authed user set by GetUserAccount
+func userIDFrom(ctx context.Context) string {
+	userID, _ := ctx.Value(userIDKey{}).(string)
+	return userID
+}
+
+// firstMetadata returns the first value of the key in incoming metadata
+func firstMetadata(ctx context.Context, key string) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// SetHandleContext is the gRPC counterpart of middleware.SetHandleContext
+func SetHandleContext() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		requestID := firstMetadata(ctx, metadataRequestID)
+		if !middleware.ValidRequestID(requestID) {
+			// fallback to empty request ID if uuid fails, it should not block the request
+			requestID, _ = util.GetUUIDv4()
+		}
+		ctx = logger.WithFields(ctx, logrus.Fields{"requestID": requestID, "method": info.FullMethod})
+		if err := grpc.SetHeader(ctx, metadata.Pairs(metadataRequestID, requestID)); err != nil {
+			logger.FromContext(ctx).WithField("err", err).Warn("grpc.SetHeader failed in SetHandleContext")
+		}
+
+		md := &mAudit.Metadata{
+			ActorType: mAudit.ActorType_SYSTEM,
+			RequestID: requestID,
+			UserAgent: firstMetadata(ctx, metadataUserAgent),
+		}
+		if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+			md.ClientIP = p.Addr.String()
+			if host, _, err := net.SplitHostPort(md.ClientIP); err == nil {
+				md.ClientIP = host
+			}
+		}
+		return handler(mAudit.WithMetadata(ctx, md), req)
+	}
+}
+
+// GetUserAccount is the gRPC counterpart of middleware.GetUserAccount
+func GetUserAccount() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		token := firstMetadata(ctx, metadataAuthorization)
+		userID, ok := middleware.LookupUser(token)
+		if !ok {
+			logger.FromContext(ctx).Warn("token not found")
+			return nil, status.Error(codes.Unauthenticated, "token not found")
+		}
+
+		if md := mAudit.MetadataFrom(ctx); md != nil {
+			md.ActorType = mAudit.ActorType_USER
+			md.ActorID = userID
+			md.Principal = "user:" + userID
+		}
+
+		// calls act on the default wallet of the user unless they name another wallet
+		accountID := mBank.DefaultWalletID(userID)
+		ctx = logger.WithFields(ctx, logrus.Fields{"userID": userID, "accountID": accountID})
+		ctx = context.WithValue(ctx, userIDKey{}, userID)
+		return handler(context.WithValue(ctx, accountIDKey{}, accountID), req)
+	}
+}
+
+// RateLimit is the gRPC counterpart of middleware.RateLimit, calls take tokens from the same buckets as REST requests.
+// A rejected call gets ResourceExhausted with the retry delay in RetryInfo
+func RateLimit(l ratelimit.Limiter, p ratelimit.Policy) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		userID, _ := middleware.LookupUser(firstMetadata(ctx, metadataAuthorization))
+		clientIP := ""
+		if md := mAudit.MetadataFrom(ctx); md != nil {
+			clientIP = md.ClientIP
+		}
+
+		tightest := middleware.TakeRateLimit(ctx, l, p, userID, clientIP, readMethods[info.FullMethod])
+		if tightest == nil {
+			return handler(ctx, req)
+		}
+
+		header := metadata.Pairs(
+			metadataRateLimitLimit, strconv.Itoa(tightest.Limit),
+			metadataRateLimitRemaining, strconv.Itoa(tightest.Remaining),
+			metadataRateLimitReset, strconv.Itoa(int(math.Ceil(tightest.ResetAfter.Seconds()))),
+		)
+		if err := grpc.SetHeader(ctx, header); err != nil {
+			logger.FromContext(ctx).WithField("err", err).Warn("grpc.SetHeader failed in RateLimit")
+		}
+		if tightest.Allowed {
+			return handler(ctx, req)
+		}
+
+		st, err := status.New(codes.ResourceExhausted, "rate limit exceeded").WithDetails(&errdetails.RetryInfo{
+			RetryDelay: durationpb.New(tightest.RetryAfter),
+		})
+		if err != nil {
+			logger.FromContext(ctx).WithField("err", err).Error("status.WithDetails failed in RateLimit")
+			return nil, status.Error(codes.ResourceExhausted, "rate limit exceeded")
+		}
+		return nil, st.Err()
+	}
+}
+
+// AuditAuthFailure records rejected calls (Unauthenticated and PermissionDenied) into the audit trail
+func AuditAuthFailure(a audit.Audit) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		resp, err := handler(ctx, req)
+
+		code := status.Code(err)
+		if code != codes.Unauthenticated && code != codes.PermissionDenied {
+			return resp, err
+		}
+
+		after := map[string]string{"code": code.String()}
+		if err := a.Record(ctx, mAudit.Action_AUTH_FAILURE, info.FullMethod, nil, after); err != nil {
+			logger.FromContext(ctx).WithField("err", err).Error("audit.Record failed in AuditAuthFailure")
+		}
+		return resp, err
+	}
+}
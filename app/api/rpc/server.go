@@ -0,0 +1,233 @@
+package rpc
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/n3k0fi5t/wallet/app/api/apierror"
+	mApproval "github.com/n3k0fi5t/wallet/app/models/approval"
+	mBank "github.com/n3k0fi5t/wallet/app/models/bank"
+	"github.com/n3k0fi5t/wallet/app/money"
+	"github.com/n3k0fi5t/wallet/app/repository/bank"
+	"github.com/n3k0fi5t/wallet/app/service/wallet"
+	walletpb "github.com/n3k0fi5t/wallet/proto/wallet/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultLimit = 50
+	maxLimit     = 500
+)
+
+// NewServer ...
+func NewServer(w wallet.Service) *Server {
+	return &Server{
+		walletSrv: w,
+	}
+}
+
+// Server serves walletpb.WalletService on top of wallet.Service
+type Server struct {
+	walletpb.UnimplementedWalletServiceServer
+
+	walletSrv wallet.Service
+}
+
+func (s *Server) Register(gs *grpc.Server) {
+	walletpb.RegisterWalletServiceServer(gs, s)
+}
+
+// httpCodes are the gRPC codes of the HTTP status of errors shared by REST handlers
+var httpCodes = map[int]codes.Code{
+	http.StatusBadRequest: codes.InvalidArgument,
+	http.StatusForbidden:  codes.PermissionDenied,
+	http.StatusNotFound:   codes.NotFound,
+	http.StatusConflict:   codes.FailedPrecondition,
+}
+
+// errorStatus maps service errors to gRPC status, errors shared by REST handlers take the code of their HTTP status
+// unless a gRPC code tells them better
+func errorStatus(err error) error {
+	switch err {
+	case bank.ErrTradeAlreadyReversed, bank.ErrIdempotencyKeyReused:
+		return status.Error(codes.AlreadyExists, err.Error())
+	case bank.ErrUpdateBalance:
+		return status.Error(codes.Aborted, err.Error())
+	}
+
+	if code, ok := httpCodes[apierror.Status(err)]; ok {
+		return status.Error(code, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+// tradeContext attaches the idempotency key of the call to the context
+func tradeContext(ctx context.Context) (context.Context, error) {
+	key := firstMetadata(ctx, metadataIdempotencyKey)
+	if key == "" {
+		return ctx, nil
+	} else if len(key) > maxIdempotencyKeyLength {
+		return nil, status.Error(codes.InvalidArgument, "invalid idempotency key")
+	}
+	return mBank.WithIdempotencyKey(ctx, key), nil
+}
+
+// walletOf returns the wallet named by the call, the default wallet of the user if none is named
+func walletOf(ctx context.Context, walletID string) string {
+	if walletID == "" {
+		return accountIDFrom(ctx)
+	}
+	return walletID
+}
+
+// authorize returns the wallet the call acts on if the user is a member of it
+func (s *Server) authorize(ctx context.Context, walletID string) (string, error) {
+	walletID = walletOf(ctx, walletID)
+	if _, err := s.walletSrv.Authorize(ctx, userIDFrom(ctx), walletID); err != nil {
+		return "", err
+	}
+	return walletID, nil
+}
+
+// walletAmount returns the amount of minor units in the currency of the wallet, amounts of the proto carry no currency
+func (s *Server) walletAmount(ctx context.Context, walletID string, amount int64) (money.Money, error) {
+	account, err := s.walletSrv.GetAccount(ctx, walletID)
+	if err != nil {
+		return money.Money{}, err
+	}
+	return money.New(amount, account.Currency()), nil
+}
+
+func (s *Server) Deposit(ctx context.Context, req *walletpb.DepositRequest) (*walletpb.TradeResponse, error) {
+	ctx, err := tradeContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	walletID, err := s.authorize(ctx, req.GetWalletId())
+	if err != nil {
+		return nil, errorStatus(err)
+	}
+
+	amount, err := s.walletAmount(ctx, walletID, req.GetAmount())
+	if err != nil {
+		return nil, errorStatus(err)
+	}
+
+	tradeID, err := s.walletSrv.Deposit(ctx, walletID, amount)
+	if err != nil {
+		return nil, errorStatus(err)
+	}
+	return &walletpb.TradeResponse{TradeId: tradeID}, nil
+}
+
+// tradeResponse carries the intent instead of the trade when the amount is held until approved
+func tradeResponse(tradeID string, intent *mApproval.Intent) *walletpb.TradeResponse {
+	if intent != nil {
+		return &walletpb.TradeResponse{IntentId: intent.IntentID}
+	}
+	return &walletpb.TradeResponse{TradeId: tradeID}
+}
+
+func (s *Server) Withdraw(ctx context.Context, req *walletpb.WithdrawRequest) (*walletpb.TradeResponse, error) {
+	ctx, err := tradeContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// the membership, the role and the daily cap of the user are checked by WithdrawAs
+	walletID := walletOf(ctx, req.GetWalletId())
+	amount, err := s.walletAmount(ctx, walletID, req.GetAmount())
+	if err != nil {
+		return nil, errorStatus(err)
+	}
+
+	tradeID, intent, err := s.walletSrv.WithdrawAs(ctx, userIDFrom(ctx), walletID, amount)
+	if err != nil {
+		return nil, errorStatus(err)
+	}
+	return tradeResponse(tradeID, intent), nil
+}
+
+func (s *Server) Transfer(ctx context.Context, req *walletpb.TransferRequest) (*walletpb.TradeResponse, error) {
+	ctx, err := tradeContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.GetToAccount() == "" {
+		return nil, status.Error(codes.InvalidArgument, "to_account is required")
+	}
+
+	walletID := walletOf(ctx, req.GetWalletId())
+	amount, err := s.walletAmount(ctx, walletID, req.GetAmount())
+	if err != nil {
+		return nil, errorStatus(err)
+	}
+
+	tradeID, intent, err := s.walletSrv.TransferAs(ctx, userIDFrom(ctx), walletID, req.GetToAccount(), amount)
+	if err != nil {
+		return nil, errorStatus(err)
+	}
+	return tradeResponse(tradeID, intent), nil
+}
+
+func (s *Server) GetAccount(ctx context.Context, req *walletpb.GetAccountRequest) (*walletpb.Account, error) {
+	walletID, err := s.authorize(ctx, req.GetWalletId())
+	if err != nil {
+		return nil, errorStatus(err)
+	}
+
+	account, err := s.walletSrv.GetAccount(ctx, walletID)
+	if err != nil {
+		return nil, errorStatus(err)
+	}
+
+	resp := &walletpb.Account{
+		AccountId: account.AccountID,
+		Balance:   account.Balance.Amount,
+		Status:    walletpb.AccountStatus_ACCOUNT_STATUS_ACTIVE,
+	}
+	if account.Status == mBank.AccountStatus_FROZEN {
+		resp.Status = walletpb.AccountStatus_ACCOUNT_STATUS_FROZEN
+	}
+	return resp, nil
+}
+
+func (s *Server) ListTransactions(ctx context.Context, req *walletpb.ListTransactionsRequest) (*walletpb.ListTransactionsResponse, error) {
+	offset, limit := int(req.GetOffset()), int(req.GetLimit())
+	if limit == 0 {
+		limit = defaultLimit
+	}
+	if offset < 0 {
+		return nil, status.Error(codes.InvalidArgument, "invalid offset")
+	}
+	if limit < 0 || limit > maxLimit {
+		return nil, status.Error(codes.InvalidArgument, "invalid limit")
+	}
+
+	walletID, err := s.authorize(ctx, req.GetWalletId())
+	if err != nil {
+		return nil, errorStatus(err)
+	}
+
+	txs, err := s.walletSrv.ListTransactions(ctx, walletID, offset, limit)
+	if err != nil {
+		return nil, errorStatus(err)
+	}
+
+	resp := &walletpb.ListTransactionsResponse{
+		Transactions: make([]*walletpb.Transaction, 0, len(txs)),
+	}
+	for _, tx := range txs {
+		resp.Transactions = append(resp.Transactions, &walletpb.Transaction{
+			TradeId:     tx.TradeID,
+			Debit:       tx.IsDebit(),
+			Amount:      tx.Amount.Amount,
+			TimestampMs: tx.TimestampMs,
+		})
+	}
+	return resp, nil
+}
@@ -0,0 +1,475 @@
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	mdApproval "github.com/n3k0fi5t/wallet/app/models/approval"
+	mdAudit "github.com/n3k0fi5t/wallet/app/models/audit"
+	mdBank "github.com/n3k0fi5t/wallet/app/models/bank"
+	mdWallet "github.com/n3k0fi5t/wallet/app/models/wallet"
+	"github.com/n3k0fi5t/wallet/app/money"
+	"github.com/n3k0fi5t/wallet/app/ratelimit"
+	mockAudit "github.com/n3k0fi5t/wallet/app/repository/audit/mocks"
+	"github.com/n3k0fi5t/wallet/app/repository/bank"
+	"github.com/n3k0fi5t/wallet/app/service/wallet"
+	mockSrv "github.com/n3k0fi5t/wallet/app/service/wallet/mocks"
+	walletpb "github.com/n3k0fi5t/wallet/proto/wallet/v1"
+)
+
+var (
+	mockAccountID1 = "935f871a-660f-4f19-801e-916c04bb0324"
+	mockAccountID2 = "a89b7b78-b9c1-4129-8cff-380bf53f3a49"
+	mockAuth1      = "Tim"
+	mockTradeID    = "935f871a-660f-4f19-801e-916c04bb0324"
+	mockIntentID   = "5d2c9a4e-3f61-4a8e-9b0c-7e1f2d3c4b5a"
+	mockAccount    = &mdBank.Account{
+		AccountID: mockAccountID1,
+		Balance:   money.New(3345678, "USD"),
+		Status:    mdBank.AccountStatus_FROZEN,
+	}
+	mockMember = &mdWallet.Member{WalletID: mockAccountID1, UserID: mockAccountID1, Role: mdWallet.Role_OWNER}
+
+	// authedCtx matches the context carrying audit metadata of the authed user
+	authedCtx = mock.MatchedBy(func(ctx context.Context) bool {
+		md := mdAudit.MetadataFrom(ctx)
+		return md != nil && md.ActorID == mockAccountID1 && md.Principal == "user:"+mockAccountID1 && md.RequestID != ""
+	})
+)
+
+type testSuite struct {
+	suite.Suite
+
+	mockSrv   *mockSrv.Service
+	mockAudit *mockAudit.Audit
+	gs        *grpc.Server
+	conn      *grpc.ClientConn
+	client    walletpb.WalletServiceClient
+}
+
+func (s *testSuite) SetupSuite() {
+	s.mockSrv = &mockSrv.Service{}
+	s.mockAudit = &mockAudit.Audit{}
+
+	s.gs = grpc.NewServer(grpc.ChainUnaryInterceptor(
+		SetHandleContext(),
+		AuditAuthFailure(s.mockAudit),
+		GetUserAccount(),
+	))
+	NewServer(s.mockSrv).Register(s.gs)
+
+	lis := bufconn.Listen(1 << 20)
+	go s.gs.Serve(lis)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.Dial() }),
+		grpc.WithInsecure(),
+	)
+	s.Require().NoError(err)
+	s.conn = conn
+	s.client = walletpb.NewWalletServiceClient(conn)
+}
+
+func (s *testSuite) TearDownSuite() {
+	s.conn.Close()
+	s.gs.Stop()
+	s.mockSrv.AssertExpectations(s.T())
+	s.mockAudit.AssertExpectations(s.T())
+}
+
+func TestSuite(t *testing.T) {
+	suite.Run(t, new(testSuite))
+}
+
+// authContext returns an outgoing context with the user token
+func authContext(token string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), metadataAuthorization, token)
+}
+
+func (s *testSuite) TestAuth() {
+	s.mockAudit.On("Record", mock.Anything, mdAudit.Action_AUTH_FAILURE, "/wallet.v1.WalletService/GetAccount", nil, map[string]string{"code": "Unauthenticated"}).Return(nil).Once()
+
+	_, err := s.client.GetAccount(authContext("unknown"), &walletpb.GetAccountRequest{})
+	s.Require().Equal(codes.Unauthenticated, status.Code(err))
+
+	// request ID is echoed back
+	s.mockSrv.On("Authorize", authedCtx, mockAccountID1, mockAccountID1).Return(mockMember, nil).Once()
+	s.mockSrv.On("GetAccount", authedCtx, mockAccountID1).Return(mockAccount, nil).Once()
+	header := metadata.MD{}
+	ctx := metadata.AppendToOutgoingContext(authContext(mockAuth1), metadataRequestID, "req-1")
+	_, err = s.client.GetAccount(ctx, &walletpb.GetAccountRequest{}, grpc.Header(&header))
+	s.Require().NoError(err)
+	s.Require().Equal([]string{"req-1"}, header.Get(metadataRequestID))
+}
+
+func (s *testSuite) TestDeposit() {
+	tests := []struct {
+		Desc       string
+		Amount     int64
+		ExpCode    codes.Code
+		ExpTradeID string
+		setup      func()
+	}{
+		{
+			Desc:   "normal case",
+			Amount: 1000,
+			setup: func() {
+				s.mockSrv.On("Authorize", authedCtx, mockAccountID1, mockAccountID1).Return(mockMember, nil).Once()
+				s.mockSrv.On("GetAccount", authedCtx, mockAccountID1).Return(mockAccount, nil).Once()
+				s.mockSrv.On("Deposit", authedCtx, mockAccountID1, money.New(1000, "USD")).Return(mockTradeID, nil).Once()
+			},
+			ExpCode:    codes.OK,
+			ExpTradeID: mockTradeID,
+		},
+		{
+			Desc:   "invalid dealing case",
+			Amount: -1,
+			setup: func() {
+				s.mockSrv.On("Authorize", authedCtx, mockAccountID1, mockAccountID1).Return(mockMember, nil).Once()
+				s.mockSrv.On("GetAccount", authedCtx, mockAccountID1).Return(mockAccount, nil).Once()
+				s.mockSrv.On("Deposit", authedCtx, mockAccountID1, money.New(-1, "USD")).Return("", bank.ErrInvalidDealing).Once()
+			},
+			ExpCode: codes.InvalidArgument,
+		},
+		{
+			Desc:   "failed case",
+			Amount: 1001,
+			setup: func() {
+				s.mockSrv.On("Authorize", authedCtx, mockAccountID1, mockAccountID1).Return(mockMember, nil).Once()
+				s.mockSrv.On("GetAccount", authedCtx, mockAccountID1).Return(mockAccount, nil).Once()
+				s.mockSrv.On("Deposit", authedCtx, mockAccountID1, money.New(1001, "USD")).Return("", fmt.Errorf("")).Once()
+			},
+			ExpCode: codes.Internal,
+		},
+		{
+			Desc:   "wallet not exist case",
+			Amount: 1000,
+			setup: func() {
+				s.mockSrv.On("Authorize", authedCtx, mockAccountID1, mockAccountID1).Return(nil, bank.ErrAccountNotExist).Once()
+			},
+			ExpCode: codes.NotFound,
+		},
+	}
+
+	for _, t := range tests {
+		if t.setup != nil {
+			t.setup()
+		}
+
+		resp, err := s.client.Deposit(authContext(mockAuth1), &walletpb.DepositRequest{Amount: t.Amount})
+		s.Require().Equal(t.ExpCode, status.Code(err), t.Desc)
+		s.Require().Equal(t.ExpTradeID, resp.GetTradeId(), t.Desc)
+	}
+}
+
+func (s *testSuite) TestWithdraw() {
+	tests := []struct {
+		Desc    string
+		Amount  int64
+		ExpCode codes.Code
+		setup   func()
+	}{
+		{
+			Desc:   "normal case",
+			Amount: 1000,
+			setup: func() {
+				s.mockSrv.On("GetAccount", authedCtx, mockAccountID1).Return(mockAccount, nil).Once()
+				s.mockSrv.On("WithdrawAs", authedCtx, mockAccountID1, mockAccountID1, money.New(1000, "USD")).Return(mockTradeID, nil, nil).Once()
+			},
+			ExpCode: codes.OK,
+		},
+		{
+			Desc:   "balance not enough case",
+			Amount: 1001,
+			setup: func() {
+				s.mockSrv.On("GetAccount", authedCtx, mockAccountID1).Return(mockAccount, nil).Once()
+				s.mockSrv.On("WithdrawAs", authedCtx, mockAccountID1, mockAccountID1, money.New(1001, "USD")).Return("", nil, bank.ErrBalanceNotEnough).Once()
+			},
+			ExpCode: codes.FailedPrecondition,
+		},
+		{
+			Desc:   "frozen case",
+			Amount: 1002,
+			setup: func() {
+				s.mockSrv.On("GetAccount", authedCtx, mockAccountID1).Return(mockAccount, nil).Once()
+				s.mockSrv.On("WithdrawAs", authedCtx, mockAccountID1, mockAccountID1, money.New(1002, "USD")).Return("", nil, bank.ErrAccountFrozen).Once()
+			},
+			ExpCode: codes.FailedPrecondition,
+		},
+		{
+			Desc:   "daily cap exceeded case",
+			Amount: 1003,
+			setup: func() {
+				s.mockSrv.On("GetAccount", authedCtx, mockAccountID1).Return(mockAccount, nil).Once()
+				s.mockSrv.On("WithdrawAs", authedCtx, mockAccountID1, mockAccountID1, money.New(1003, "USD")).Return("", nil, wallet.ErrDailyCapExceeded).Once()
+			},
+			ExpCode: codes.FailedPrecondition,
+		},
+		{
+			Desc:   "permission denied case",
+			Amount: 1004,
+			setup: func() {
+				s.mockSrv.On("GetAccount", authedCtx, mockAccountID1).Return(mockAccount, nil).Once()
+				s.mockSrv.On("WithdrawAs", authedCtx, mockAccountID1, mockAccountID1, money.New(1004, "USD")).Return("", nil, wallet.ErrPermissionDenied).Once()
+				s.mockAudit.On("Record", mock.Anything, mdAudit.Action_AUTH_FAILURE, "/wallet.v1.WalletService/Withdraw", nil, map[string]string{"code": "PermissionDenied"}).Return(nil).Once()
+			},
+			ExpCode: codes.PermissionDenied,
+		},
+		{
+			Desc:   "amount overflow case",
+			Amount: 1005,
+			setup: func() {
+				s.mockSrv.On("GetAccount", authedCtx, mockAccountID1).Return(mockAccount, nil).Once()
+				s.mockSrv.On("WithdrawAs", authedCtx, mockAccountID1, mockAccountID1, money.New(1005, "USD")).Return("", nil, money.ErrOverflow).Once()
+			},
+			ExpCode: codes.InvalidArgument,
+		},
+	}
+
+	for _, t := range tests {
+		if t.setup != nil {
+			t.setup()
+		}
+
+		_, err := s.client.Withdraw(authContext(mockAuth1), &walletpb.WithdrawRequest{Amount: t.Amount})
+		s.Require().Equal(t.ExpCode, status.Code(err), t.Desc)
+	}
+}
+
+func (s *testSuite) TestWithdrawHeld() {
+	s.mockSrv.On("GetAccount", authedCtx, mockAccountID1).Return(mockAccount, nil).Once()
+	s.mockSrv.On("WithdrawAs", authedCtx, mockAccountID1, mockAccountID1, money.New(5000, "USD")).Return("", &mdApproval.Intent{IntentID: mockIntentID}, nil).Once()
+
+	resp, err := s.client.Withdraw(authContext(mockAuth1), &walletpb.WithdrawRequest{Amount: 5000})
+	s.Require().NoError(err)
+	s.Require().Empty(resp.GetTradeId())
+	s.Require().Equal(mockIntentID, resp.GetIntentId())
+}
+
+func (s *testSuite) TestTransfer() {
+	tests := []struct {
+		Desc      string
+		ToAccount string
+		ExpCode   codes.Code
+		setup     func()
+	}{
+		{
+			Desc:      "normal case",
+			ToAccount: mockAccountID2,
+			setup: func() {
+				s.mockSrv.On("GetAccount", authedCtx, mockAccountID1).Return(mockAccount, nil).Once()
+				s.mockSrv.On("TransferAs", authedCtx, mockAccountID1, mockAccountID1, mockAccountID2, money.New(1000, "USD")).Return(mockTradeID, nil, nil).Once()
+			},
+			ExpCode: codes.OK,
+		},
+		{
+			Desc:      "self transfer case",
+			ToAccount: mockAccountID1,
+			setup: func() {
+				s.mockSrv.On("GetAccount", authedCtx, mockAccountID1).Return(mockAccount, nil).Once()
+				s.mockSrv.On("TransferAs", authedCtx, mockAccountID1, mockAccountID1, mockAccountID1, money.New(1000, "USD")).Return("", nil, bank.ErrSelfTransfer).Once()
+			},
+			ExpCode: codes.InvalidArgument,
+		},
+		{
+			Desc:      "account not exist case",
+			ToAccount: "unknown",
+			setup: func() {
+				s.mockSrv.On("GetAccount", authedCtx, mockAccountID1).Return(mockAccount, nil).Once()
+				s.mockSrv.On("TransferAs", authedCtx, mockAccountID1, mockAccountID1, "unknown", money.New(1000, "USD")).Return("", nil, bank.ErrAccountNotExist).Once()
+			},
+			ExpCode: codes.NotFound,
+		},
+		{
+			Desc:      "currency mismatch case",
+			ToAccount: "eur-wallet",
+			setup: func() {
+				s.mockSrv.On("GetAccount", authedCtx, mockAccountID1).Return(mockAccount, nil).Once()
+				s.mockSrv.On("TransferAs", authedCtx, mockAccountID1, mockAccountID1, "eur-wallet", money.New(1000, "USD")).Return("", nil, bank.ErrCurrencyMismatch).Once()
+			},
+			ExpCode: codes.InvalidArgument,
+		},
+		{
+			Desc:    "missing to account",
+			ExpCode: codes.InvalidArgument,
+		},
+	}
+
+	for _, t := range tests {
+		if t.setup != nil {
+			t.setup()
+		}
+
+		_, err := s.client.Transfer(authContext(mockAuth1), &walletpb.TransferRequest{Amount: 1000, ToAccount: t.ToAccount})
+		s.Require().Equal(t.ExpCode, status.Code(err), t.Desc)
+	}
+}
+
+func (s *testSuite) TestGetAccount() {
+	s.mockSrv.On("Authorize", authedCtx, mockAccountID1, mockAccountID1).Return(mockMember, nil).Once()
+	s.mockSrv.On("GetAccount", authedCtx, mockAccountID1).Return(mockAccount, nil).Once()
+
+	resp, err := s.client.GetAccount(authContext(mockAuth1), &walletpb.GetAccountRequest{})
+	s.Require().NoError(err)
+	s.Require().Equal(mockAccountID1, resp.GetAccountId())
+	s.Require().Equal(mockAccount.Balance.Amount, resp.GetBalance())
+	s.Require().Equal(walletpb.AccountStatus_ACCOUNT_STATUS_FROZEN, resp.GetStatus())
+}
+
+func (s *testSuite) TestListTransactions() {
+	tests := []struct {
+		Desc    string
+		Req     *walletpb.ListTransactionsRequest
+		ExpCode codes.Code
+		ExpLen  int
+		setup   func()
+	}{
+		{
+			Desc: "normal case",
+			Req:  &walletpb.ListTransactionsRequest{Offset: 10, Limit: 5},
+			setup: func() {
+				s.mockSrv.On("Authorize", authedCtx, mockAccountID1, mockAccountID1).Return(mockMember, nil).Once()
+				s.mockSrv.On("ListTransactions", authedCtx, mockAccountID1, 10, 5).Return([]*mdBank.Transaction{
+					{AccountID: mockAccountID1, Action: mdBank.Action_DECREASE, Amount: money.New(100, "USD"), TradeID: mockTradeID},
+				}, nil).Once()
+			},
+			ExpCode: codes.OK,
+			ExpLen:  1,
+		},
+		{
+			Desc: "default limit",
+			Req:  &walletpb.ListTransactionsRequest{},
+			setup: func() {
+				s.mockSrv.On("Authorize", authedCtx, mockAccountID1, mockAccountID1).Return(mockMember, nil).Once()
+				s.mockSrv.On("ListTransactions", authedCtx, mockAccountID1, 0, defaultLimit).Return([]*mdBank.Transaction{}, nil).Once()
+			},
+			ExpCode: codes.OK,
+		},
+		{
+			Desc:    "bad limit",
+			Req:     &walletpb.ListTransactionsRequest{Limit: 100000},
+			ExpCode: codes.InvalidArgument,
+		},
+		{
+			Desc:    "bad offset",
+			Req:     &walletpb.ListTransactionsRequest{Offset: -1},
+			ExpCode: codes.InvalidArgument,
+		},
+	}
+
+	for _, t := range tests {
+		if t.setup != nil {
+			t.setup()
+		}
+
+		resp, err := s.client.ListTransactions(authContext(mockAuth1), t.Req)
+		s.Require().Equal(t.ExpCode, status.Code(err), t.Desc)
+		s.Require().Len(resp.GetTransactions(), t.ExpLen, t.Desc)
+	}
+}
+
+func (s *testSuite) TestJointWallet() {
+	jointWallet := "7c1e4b2a-9d3f-4e6a-8b5c-2f1a0d9e8c7b"
+	jointAccount := &mdBank.Account{AccountID: jointWallet, Balance: money.New(5000, "EUR")}
+
+	// deposit, get and list authorize the member before acting on the wallet
+	s.mockSrv.On("Authorize", authedCtx, mockAccountID1, jointWallet).Return(&mdWallet.Member{WalletID: jointWallet, UserID: mockAccountID1, Role: mdWallet.Role_VIEWER}, nil).Times(3)
+	s.mockSrv.On("GetAccount", authedCtx, jointWallet).Return(jointAccount, nil).Times(3)
+	s.mockSrv.On("Deposit", authedCtx, jointWallet, money.New(1000, "EUR")).Return(mockTradeID, nil).Once()
+	s.mockSrv.On("ListTransactions", authedCtx, jointWallet, 0, defaultLimit).Return([]*mdBank.Transaction{}, nil).Once()
+	// spending checks the role of the member by itself
+	s.mockSrv.On("WithdrawAs", authedCtx, mockAccountID1, jointWallet, money.New(1000, "EUR")).Return("", nil, wallet.ErrPermissionDenied).Once()
+	s.mockAudit.On("Record", mock.Anything, mdAudit.Action_AUTH_FAILURE, "/wallet.v1.WalletService/Withdraw", nil, map[string]string{"code": "PermissionDenied"}).Return(nil).Once()
+
+	deposit, err := s.client.Deposit(authContext(mockAuth1), &walletpb.DepositRequest{Amount: 1000, WalletId: jointWallet})
+	s.Require().NoError(err)
+	s.Require().Equal(mockTradeID, deposit.GetTradeId())
+
+	account, err := s.client.GetAccount(authContext(mockAuth1), &walletpb.GetAccountRequest{WalletId: jointWallet})
+	s.Require().NoError(err)
+	s.Require().Equal(jointWallet, account.GetAccountId())
+
+	_, err = s.client.ListTransactions(authContext(mockAuth1), &walletpb.ListTransactionsRequest{WalletId: jointWallet})
+	s.Require().NoError(err)
+
+	_, err = s.client.Withdraw(authContext(mockAuth1), &walletpb.WithdrawRequest{Amount: 1000, WalletId: jointWallet})
+	s.Require().Equal(codes.PermissionDenied, status.Code(err))
+
+	// wallets the user is not a member of are not found
+	s.mockSrv.On("Authorize", authedCtx, mockAccountID1, mockAccountID2).Return(nil, bank.ErrAccountNotExist).Once()
+	_, err = s.client.GetAccount(authContext(mockAuth1), &walletpb.GetAccountRequest{WalletId: mockAccountID2})
+	s.Require().Equal(codes.NotFound, status.Code(err))
+}
+
+func (s *testSuite) TestIdempotencyKey() {
+	keyedCtx := mock.MatchedBy(func(ctx context.Context) bool {
+		return mdBank.IdempotencyKeyFrom(ctx) == "retry-1"
+	})
+	s.mockSrv.On("GetAccount", keyedCtx, mockAccountID1).Return(mockAccount, nil).Once()
+	s.mockSrv.On("WithdrawAs", keyedCtx, mockAccountID1, mockAccountID1, money.New(2000, "USD")).Return("", nil, bank.ErrIdempotencyKeyReused).Once()
+
+	ctx := metadata.AppendToOutgoingContext(authContext(mockAuth1), metadataIdempotencyKey, "retry-1")
+	_, err := s.client.Withdraw(ctx, &walletpb.WithdrawRequest{Amount: 2000})
+	s.Require().Equal(codes.AlreadyExists, status.Code(err))
+}
+
+func (s *testSuite) TestRateLimit() {
+	mWallet := &mockSrv.Service{}
+	gs := grpc.NewServer(grpc.ChainUnaryInterceptor(
+		SetHandleContext(),
+		RateLimit(ratelimit.NewMemory(), ratelimit.Policy{
+			AccountRead:  ratelimit.Limit{Rate: 1, Burst: 1},
+			AccountWrite: ratelimit.Limit{Rate: 1, Burst: 1},
+		}),
+		GetUserAccount(),
+	))
+	NewServer(mWallet).Register(gs)
+	defer gs.Stop()
+
+	lis := bufconn.Listen(1 << 20)
+	go gs.Serve(lis)
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.Dial() }),
+		grpc.WithInsecure(),
+	)
+	s.Require().NoError(err)
+	defer conn.Close()
+	client := walletpb.NewWalletServiceClient(conn)
+
+	mWallet.On("Authorize", mock.Anything, mockAccountID1, mockAccountID1).Return(mockMember, nil)
+	mWallet.On("GetAccount", mock.Anything, mockAccountID1).Return(mockAccount, nil)
+	mWallet.On("Deposit", mock.Anything, mockAccountID1, money.New(1000, "USD")).Return(mockTradeID, nil).Once()
+
+	header := metadata.MD{}
+	_, err = client.GetAccount(authContext(mockAuth1), &walletpb.GetAccountRequest{}, grpc.Header(&header))
+	s.Require().NoError(err)
+	s.Require().Equal([]string{"1"}, header.Get(metadataRateLimitLimit))
+	s.Require().Equal([]string{"0"}, header.Get(metadataRateLimitRemaining))
+
+	// the account runs out of its read budget
+	_, err = client.GetAccount(authContext(mockAuth1), &walletpb.GetAccountRequest{})
+	s.Require().Equal(codes.ResourceExhausted, status.Code(err))
+	details := status.Convert(err).Details()
+	s.Require().Len(details, 1)
+	retry, ok := details[0].(*errdetails.RetryInfo)
+	s.Require().True(ok)
+	s.Require().True(retry.GetRetryDelay().AsDuration() > 0)
+
+	// writes have a budget of their own
+	_, err = client.Deposit(authContext(mockAuth1), &walletpb.DepositRequest{Amount: 1000})
+	s.Require().NoError(err)
+	_, err = client.Deposit(authContext(mockAuth1), &walletpb.DepositRequest{Amount: 1000})
+	s.Require().Equal(codes.ResourceExhausted, status.Code(err))
+	mWallet.AssertExpectations(s.T())
+}
@@ -0,0 +1,222 @@
+package wallet
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/n3k0fi5t/wallet/app/middleware"
+	mBank "github.com/n3k0fi5t/wallet/app/models/bank"
+	"github.com/n3k0fi5t/wallet/app/money"
+)
+
+const (
+	headerLastEventID = "Last-Event-ID"
+
+	// maxReplay is the number of updates replayed to a resumed stream, a client missing more gets reset instead
+	maxReplay = 500
+
+	// retryMs tells EventSource how long to wait before reconnecting
+	retryMs = 3000
+
+	eventBalance = "balance"
+	eventTrade   = "trade"
+	eventReset   = "reset"
+)
+
+var (
+	// heartbeatInterval keeps idle streams from being closed by proxies
+	heartbeatInterval = 15 * time.Second
+
+	// writeTimeout bounds each write of a stream, a client not reading is disconnected
+	writeTimeout = 10 * time.Second
+
+	errInvalidLastEventID = fmt.Errorf("invalid Last-Event-ID")
+)
+
+type balanceEvent struct {
+	AccountID string      `json:"accountID"`
+	Balance   money.Money `json:"balance"`
+}
+
+type tradeEvent struct {
+	TradeID        string      `json:"tradeID"`
+	CounterpartyID string      `json:"counterpartyID"`
+	Debit          bool        `json:"debit"`
+	Amount         money.Money `json:"amount"`
+	BalanceAfter   money.Money `json:"balanceAfter"`
+	TimestampMs    int64       `json:"timestampMs"`
+}
+
+// sseEvent is one Server-Sent Event, ID is empty for events which are not in the trade log
+type sseEvent struct {
+	ID    string
+	Event string
+	Data  interface{}
+}
+
+func newTradeEvent(u *mBank.Update) sseEvent {
+	return sseEvent{
+		ID:    strconv.FormatInt(u.ID, 10),
+		Event: eventTrade,
+		Data: tradeEvent{
+			TradeID:        u.TradeID,
+			CounterpartyID: u.CounterpartyID,
+			Debit:          u.IsDebit(),
+			Amount:         u.Amount,
+			BalanceAfter:   u.BalanceAfter,
+			TimestampMs:    u.TimestampMs,
+		},
+	}
+}
+
+func newBalanceEvent(accountID string, balance money.Money) sseEvent {
+	return sseEvent{
+		Event: eventBalance,
+		Data: balanceEvent{
+			AccountID: accountID,
+			Balance:   balance,
+		},
+	}
+}
+
+// writeEvents writes events and flushes them to the client
+func writeEvents(c *gin.Context, events ...sseEvent) error {
+	if err := middleware.ExtendDeadline(c, writeTimeout); err != nil {
+		return err
+	}
+
+	for _, e := range events {
+		data, err := json.Marshal(e.Data)
+		if err != nil {
+			return err
+		}
+
+		msg := ""
+		if e.ID != "" {
+			msg += "id: " + e.ID + "\n"
+		}
+		msg += "event: " + e.Event + "\ndata: " + string(data) + "\n\n"
+		if _, err := c.Writer.WriteString(msg); err != nil {
+			return err
+		}
+	}
+	c.Writer.Flush()
+	return nil
+}
+
+// writeComment writes an SSE comment, clients ignore it
+func writeComment(c *gin.Context, comment string) error {
+	if err := middleware.ExtendDeadline(c, writeTimeout); err != nil {
+		return err
+	}
+
+	if _, err := c.Writer.WriteString(": " + comment + "\n\n"); err != nil {
+		return err
+	}
+	c.Writer.Flush()
+	return nil
+}
+
+// firstEvents returns events catching the client up and the trade log ID they reach.
+// A new client gets its balance, a resumed one gets the trades it missed, or reset with the balance if it missed too many
+func (h *Handler) firstEvents(ctx context.Context, accountID string, lastID int64, resume bool) ([]sseEvent, int64, error) {
+	if !resume {
+		account, err := h.walletSrv.GetAccount(ctx, accountID)
+		if err != nil {
+			return nil, 0, err
+		}
+		return []sseEvent{newBalanceEvent(accountID, account.Balance)}, lastID, nil
+	}
+
+	updates, err := h.walletSrv.ListUpdates(ctx, accountID, lastID, maxReplay+1)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if len(updates) > maxReplay {
+		// the client reloads its history by ListTransactions
+		last := updates[len(updates)-1]
+		reset := sseEvent{ID: strconv.FormatInt(last.ID, 10), Event: eventReset, Data: struct{}{}}
+		return []sseEvent{reset, newBalanceEvent(accountID, last.BalanceAfter)}, last.ID, nil
+	}
+
+	events := make([]sseEvent, 0, len(updates))
+	for _, u := range updates {
+		events = append(events, newTradeEvent(u))
+		lastID = u.ID
+	}
+	return events, lastID, nil
+}
+
+func (h *Handler) events(c *gin.Context) {
+	ctx := c.MustGet("ctx").(context.Context)
+
+	lastID, resume := int64(0), false
+	if v := c.Request.Header.Get(headerLastEventID); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || id < 0 {
+			responseError(c, http.StatusBadRequest, errInvalidLastEventID)
+			return
+		}
+		lastID, resume = id, true
+	}
+
+	// members watch any wallet they can see, e.g. joint wallets and wallets of other currencies
+	accountID, err := h.authorize(ctx, c, c.Query("walletID"))
+	if err != nil {
+		responseError(c, errorStatus(err), err)
+		return
+	}
+
+	// subscribe before reading the trade log, so no update falls in between
+	sub := h.walletSrv.Subscribe(ctx, accountID)
+	defer sub.Close()
+
+	first, lastID, err := h.firstEvents(ctx, accountID, lastID, resume)
+	if err != nil {
+		responseError(c, errorStatus(err), err)
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	if _, err := c.Writer.WriteString("retry: " + strconv.Itoa(retryMs) + "\n\n"); err != nil {
+		return
+	}
+	if err := writeEvents(c, first...); err != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case u, ok := <-sub.Updates():
+			if !ok {
+				// the stream fell behind or the server is shutting down, the client resumes with Last-Event-ID
+				return
+			} else if u.ID <= lastID {
+				// already sent from the trade log
+				continue
+			}
+
+			if err := writeEvents(c, newTradeEvent(u)); err != nil {
+				return
+			}
+			lastID = u.ID
+		case <-heartbeat.C:
+			if err := writeComment(c, "heartbeat"); err != nil {
+				return
+			}
+		}
+	}
+}
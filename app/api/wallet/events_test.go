@@ -0,0 +1,258 @@
+package wallet
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/n3k0fi5t/wallet/app/broker"
+	"github.com/n3k0fi5t/wallet/app/middleware"
+	mdBank "github.com/n3k0fi5t/wallet/app/models/bank"
+	mdWallet "github.com/n3k0fi5t/wallet/app/models/wallet"
+	"github.com/n3k0fi5t/wallet/app/repository/bank"
+)
+
+func mockUpdate(id int64) *mdBank.Update {
+	return &mdBank.Update{
+		ID:             id,
+		AccountID:      mockAccountID1,
+		TradeID:        fmt.Sprintf("trade-%d", id),
+		CounterpartyID: mockAccountID2,
+		Action:         mdBank.Action_INCREASE,
+		Amount:         usd(100),
+		BalanceAfter:   usd(id * 100),
+		TimestampMs:    1650000000000,
+	}
+}
+
+func tradeData(id int64) string {
+	return fmt.Sprintf(`{"tradeID":"trade-%d","counterpartyID":"%s","debit":false,"amount":{"amount":"1.00","currency":"USD"},"balanceAfter":{"amount":"%s","currency":"USD"},"timestampMs":1650000000000}`, id, mockAccountID2, usd(id*100).Decimal())
+}
+
+// stream is an event stream opened against a test server
+type stream struct {
+	resp   *http.Response
+	reader *bufio.Reader
+	cancel context.CancelFunc
+}
+
+func (s *testSuite) openStream(url, walletID, lastEventID string) *stream {
+	ctx, cancel := context.WithCancel(context.Background())
+	req, err := http.NewRequestWithContext(ctx, "GET", url+"/api/v1/wallet/events?walletID="+walletID, nil)
+	s.Require().NoError(err)
+	req.Header.Set("Authorization", mockAuth1)
+	if lastEventID != "" {
+		req.Header.Set(headerLastEventID, lastEventID)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	s.Require().NoError(err)
+	return &stream{resp: resp, reader: bufio.NewReader(resp.Body), cancel: cancel}
+}
+
+func (st *stream) Close() {
+	st.cancel()
+	st.resp.Body.Close()
+}
+
+// next returns the lines of the next event block, e.g. ["id: 1", "event: trade", "data: {...}"]
+func (s *testSuite) next(st *stream) []string {
+	lines := []string{}
+	for {
+		line, err := st.reader.ReadString('\n')
+		s.Require().NoError(err)
+
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" {
+			return lines
+		}
+		lines = append(lines, line)
+	}
+}
+
+func (s *testSuite) TestEvents() {
+	server := httptest.NewServer(s.router)
+	defer server.Close()
+
+	tests := []struct {
+		Desc        string
+		WalletID    string
+		LastEventID string
+		Published   []*mdBank.Update
+		ExpEvents   [][]string
+		setup       func()
+	}{
+		{
+			Desc:      "new stream starts with the balance",
+			Published: []*mdBank.Update{mockUpdate(3)},
+			ExpEvents: [][]string{
+				{"event: balance", `data: {"accountID":"` + mockAccountID1 + `","balance":{"amount":"33456.78","currency":"USD"}}`},
+				{"id: 3", "event: trade", "data: " + tradeData(3)},
+			},
+			setup: func() {
+				s.mockSrv.On("GetAccount", mockCtx, mockAccountID1).Return(mockAccount, nil).Once()
+			},
+		},
+		{
+			Desc:     "stream of a joint wallet the user is a member of",
+			WalletID: mockWalletID,
+			ExpEvents: [][]string{
+				{"event: balance", `data: {"accountID":"` + mockWalletID + `","balance":{"amount":"5.00","currency":"USD"}}`},
+			},
+			setup: func() {
+				s.mockSrv.On("GetAccount", mockCtx, mockWalletID).Return(&mdBank.Account{AccountID: mockWalletID, Balance: usd(500)}, nil).Once()
+			},
+		},
+		{
+			Desc:        "resumed stream replays missed trades once",
+			LastEventID: "5",
+			Published:   []*mdBank.Update{mockUpdate(7), mockUpdate(8)},
+			ExpEvents: [][]string{
+				{"id: 6", "event: trade", "data: " + tradeData(6)},
+				{"id: 7", "event: trade", "data: " + tradeData(7)},
+				{"id: 8", "event: trade", "data: " + tradeData(8)},
+			},
+			setup: func() {
+				s.mockSrv.On("ListUpdates", mockCtx, mockAccountID1, int64(5), maxReplay+1).Return([]*mdBank.Update{mockUpdate(6), mockUpdate(7)}, nil).Once()
+			},
+		},
+		{
+			Desc:        "stream missing too many trades is reset",
+			LastEventID: "0",
+			ExpEvents: [][]string{
+				{fmt.Sprintf("id: %d", maxReplay+1), "event: reset", "data: {}"},
+				{"event: balance", fmt.Sprintf(`data: {"accountID":"%s","balance":{"amount":"%s","currency":"USD"}}`, mockAccountID1, usd((maxReplay+1)*100).Decimal())},
+			},
+			setup: func() {
+				updates := []*mdBank.Update{}
+				for id := int64(1); id <= maxReplay+1; id++ {
+					updates = append(updates, mockUpdate(id))
+				}
+				s.mockSrv.On("ListUpdates", mockCtx, mockAccountID1, int64(0), maxReplay+1).Return(updates, nil).Once()
+			},
+		},
+	}
+
+	for _, t := range tests {
+		walletID := t.WalletID
+		if walletID == "" {
+			walletID = mockAccountID1
+		}
+
+		br := broker.NewBroker(4)
+		s.mockSrv.On("Authorize", mockCtx, mockAccountID1, walletID).Return(&mdWallet.Member{WalletID: walletID, UserID: mockAccountID1, Role: mdWallet.Role_VIEWER}, nil).Once()
+		s.mockSrv.On("Subscribe", mockCtx, walletID).Return(func(ctx context.Context, accountID string) *broker.Subscription {
+			return br.Subscribe(accountID)
+		}).Once()
+		if t.setup != nil {
+			t.setup()
+		}
+
+		st := s.openStream(server.URL, t.WalletID, t.LastEventID)
+		s.Require().Equal(http.StatusOK, st.resp.StatusCode, t.Desc)
+		s.Require().Equal("text/event-stream", st.resp.Header.Get("Content-Type"), t.Desc)
+		s.Require().Equal([]string{"retry: 3000"}, s.next(st), t.Desc)
+
+		// the first events are written once subscribed
+		events := [][]string{s.next(st)}
+		br.Publish(t.Published...)
+		for len(events) < len(t.ExpEvents) {
+			events = append(events, s.next(st))
+		}
+		s.Require().Equal(t.ExpEvents, events, t.Desc)
+
+		// the stream ends when the server drops it, e.g. on shutdown
+		br.Close()
+		_, err := st.reader.ReadString('\n')
+		s.Require().Error(err, t.Desc)
+		st.Close()
+	}
+}
+
+func (s *testSuite) TestEventsBadRequest() {
+	tests := []struct {
+		Desc        string
+		WalletID    string
+		LastEventID string
+		ExpCode     int
+		setup       func()
+	}{
+		{
+			Desc:        "invalid Last-Event-ID",
+			LastEventID: "abc",
+			ExpCode:     http.StatusBadRequest,
+		},
+		{
+			Desc:        "negative Last-Event-ID",
+			LastEventID: "-1",
+			ExpCode:     http.StatusBadRequest,
+		},
+		{
+			Desc:        "account not exist",
+			LastEventID: "1",
+			ExpCode:     http.StatusNotFound,
+			setup: func() {
+				s.mockSrv.On("Authorize", mockCtx, mockAccountID1, mockAccountID1).Return(mockOwner1, nil).Once()
+				s.mockSrv.On("Subscribe", mockCtx, mockAccountID1).Return(broker.NewBroker(1).Subscribe(mockAccountID1)).Once()
+				s.mockSrv.On("ListUpdates", mockCtx, mockAccountID1, int64(1), maxReplay+1).Return(nil, bank.ErrAccountNotExist).Once()
+			},
+		},
+		{
+			Desc:     "wallet of others",
+			WalletID: mockAccountID2,
+			ExpCode:  http.StatusNotFound,
+			setup: func() {
+				s.mockSrv.On("Authorize", mockCtx, mockAccountID1, mockAccountID2).Return(nil, bank.ErrAccountNotExist).Once()
+			},
+		},
+	}
+
+	for _, t := range tests {
+		if t.setup != nil {
+			t.setup()
+		}
+
+		req, err := http.NewRequest("GET", "/api/v1/wallet/events?walletID="+t.WalletID, nil)
+		s.Require().NoError(err, t.Desc)
+		req.Header.Set("Authorization", mockAuth1)
+		req.Header.Set(headerLastEventID, t.LastEventID)
+
+		rr := httptest.NewRecorder()
+		s.router.ServeHTTP(rr, req)
+		s.Require().Equal(t.ExpCode, rr.Code, t.Desc)
+	}
+}
+
+// TestEventsHeartbeat keeps a stream open longer than the timeouts of the server
+func (s *testSuite) TestEventsHeartbeat() {
+	interval, timeout := heartbeatInterval, writeTimeout
+	heartbeatInterval, writeTimeout = 20*time.Millisecond, 100*time.Millisecond
+	defer func() {
+		heartbeatInterval, writeTimeout = interval, timeout
+	}()
+
+	server := httptest.NewUnstartedServer(s.router)
+	server.Config.ConnContext = middleware.ConnContext
+	server.Config.ReadTimeout = 100 * time.Millisecond
+	server.Config.WriteTimeout = 100 * time.Millisecond
+	server.Start()
+	defer server.Close()
+
+	s.mockSrv.On("Authorize", mockCtx, mockAccountID1, mockAccountID1).Return(mockOwner1, nil).Once()
+	s.mockSrv.On("Subscribe", mockCtx, mockAccountID1).Return(broker.NewBroker(1).Subscribe(mockAccountID1)).Once()
+	s.mockSrv.On("GetAccount", mockCtx, mockAccountID1).Return(mockAccount, nil).Once()
+
+	st := s.openStream(server.URL, "", "")
+	defer st.Close()
+	s.next(st)
+	s.next(st)
+
+	deadline := time.Now().Add(300 * time.Millisecond)
+	for time.Now().Before(deadline) {
+		s.Require().Equal([]string{": heartbeat"}, s.next(st))
+	}
+}
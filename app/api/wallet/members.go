@@ -0,0 +1,112 @@
+package wallet
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	mWallet "github.com/n3k0fi5t/wallet/app/models/wallet"
+	"github.com/n3k0fi5t/wallet/app/money"
+	"github.com/n3k0fi5t/wallet/app/util"
+)
+
+var (
+	timeNowMs = util.TimeNowMs
+)
+
+type memberResp struct {
+	WalletID   string      `json:"walletID"`
+	UserID     string      `json:"userID"`
+	Role       string      `json:"role"`
+	DailyCap   money.Money `json:"dailyCap"`
+	SpentToday money.Money `json:"spentToday"`
+}
+
+func toMemberResp(m *mWallet.Member, nowMs int64) memberResp {
+	return memberResp{
+		WalletID:   m.WalletID,
+		UserID:     m.UserID,
+		Role:       string(m.Role),
+		DailyCap:   m.DailyCap,
+		SpentToday: m.Spent(nowMs),
+	}
+}
+
+type listMembersResp struct {
+	Members []memberResp `json:"members"`
+}
+
+func toListMembersResp(members []*mWallet.Member) listMembersResp {
+	nowMs := timeNowMs()
+	resp := listMembersResp{
+		Members: make([]memberResp, 0, len(members)),
+	}
+	for _, m := range members {
+		resp.Members = append(resp.Members, toMemberResp(m, nowMs))
+	}
+	return resp
+}
+
+func (h *Handler) listMembers(c *gin.Context) {
+	ctx := c.MustGet("ctx").(context.Context)
+	userID := c.MustGet("userID").(string)
+
+	members, err := h.walletSrv.ListMembers(ctx, userID, c.Param("walletID"))
+	if err != nil {
+		responseError(c, errorStatus(err), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toListMembersResp(members))
+}
+
+type setMemberParam struct {
+	Role string `json:"role"`
+	// DailyCap is in the currency of the wallet, 0 for no cap
+	DailyCap money.Money `json:"dailyCap"`
+}
+
+func (h *Handler) setMember(c *gin.Context) {
+	ctx := c.MustGet("ctx").(context.Context)
+	userID := c.MustGet("userID").(string)
+
+	param := setMemberParam{}
+	if err := c.ShouldBindBodyWith(&param, binding.JSON); err != nil {
+		responseError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	m, err := h.walletSrv.SetMember(ctx, userID, c.Param("walletID"), c.Param("userID"), mWallet.Role(param.Role), param.DailyCap)
+	if err != nil {
+		responseError(c, errorStatus(err), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toMemberResp(m, timeNowMs()))
+}
+
+func (h *Handler) removeMember(c *gin.Context) {
+	ctx := c.MustGet("ctx").(context.Context)
+	userID := c.MustGet("userID").(string)
+
+	if err := h.walletSrv.RemoveMember(ctx, userID, c.Param("walletID"), c.Param("userID")); err != nil {
+		responseError(c, errorStatus(err), err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handler) listMemberships(c *gin.Context) {
+	ctx := c.MustGet("ctx").(context.Context)
+	userID := c.MustGet("userID").(string)
+
+	members, err := h.walletSrv.ListMemberships(ctx, userID)
+	if err != nil {
+		responseError(c, errorStatus(err), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toListMembersResp(members))
+}
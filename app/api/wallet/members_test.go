@@ -0,0 +1,267 @@
+package wallet
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/n3k0fi5t/wallet/app/api/apitest"
+	mdApproval "github.com/n3k0fi5t/wallet/app/models/approval"
+	mdBank "github.com/n3k0fi5t/wallet/app/models/bank"
+	mdWallet "github.com/n3k0fi5t/wallet/app/models/wallet"
+	"github.com/n3k0fi5t/wallet/app/repository/bank"
+	"github.com/n3k0fi5t/wallet/app/service/wallet"
+)
+
+var (
+	// mockSpender spends from the joint wallet of account 1 without cap
+	mockSpender = &mdWallet.Member{WalletID: mockWalletID, UserID: mockAccountID2, Role: mdWallet.Role_SPENDER}
+
+	// mockIntent holds 5000 of the joint wallet until approved
+	mockIntent = &mdApproval.Intent{
+		IntentID:    "5d2c9a4e-3f61-4a8e-9b0c-7e1f2d3c4b5a",
+		WalletID:    mockWalletID,
+		RequestedBy: mockAccountID2,
+		Amount:      usd(5000),
+		Status:      mdApproval.Status_PENDING,
+		ExpiresAtMs: 1650172800000,
+	}
+)
+
+func (s *testSuite) TestMembers() {
+	genPayload := func(d setMemberParam) []byte {
+		b, err := json.Marshal(d)
+		s.Require().NoError(err)
+		return b
+	}
+	membersPath := "/api/v1/wallet/wallets/" + mockWalletID + "/members"
+
+	tests := []struct {
+		Desc       string
+		Method     string
+		Path       string
+		Payload    []byte
+		Auth       string
+		ExpCode    int
+		ExpErrCode string
+		setup      func()
+	}{
+		{
+			Desc:    "list members",
+			Method:  "GET",
+			Path:    membersPath,
+			Auth:    mockAuth2,
+			ExpCode: http.StatusOK,
+			setup: func() {
+				owner := &mdWallet.Member{WalletID: mockWalletID, UserID: mockAccountID1, Role: mdWallet.Role_OWNER}
+				s.mockSrv.On("ListMembers", mockCtx, mockAccountID2, mockWalletID).Return([]*mdWallet.Member{owner, mockSpender}, nil).Once()
+			},
+		},
+		{
+			Desc:    "add spender",
+			Method:  "PUT",
+			Path:    membersPath + "/" + mockAccountID2,
+			Payload: genPayload(setMemberParam{Role: "SPENDER", DailyCap: usd(500)}),
+			Auth:    mockAuth1,
+			ExpCode: http.StatusOK,
+			setup: func() {
+				s.mockSrv.On("SetMember", mockCtx, mockAccountID1, mockWalletID, mockAccountID2, mdWallet.Role_SPENDER, usd(500)).Return(mockSpender, nil).Once()
+			},
+		},
+		{
+			Desc:       "add unknown role",
+			Method:     "PUT",
+			Path:       membersPath + "/" + mockAccountID2,
+			Payload:    genPayload(setMemberParam{Role: "ADMIN", DailyCap: usd(0)}),
+			Auth:       mockAuth1,
+			ExpCode:    http.StatusBadRequest,
+			ExpErrCode: "INVALID_MEMBER",
+			setup: func() {
+				s.mockSrv.On("SetMember", mockCtx, mockAccountID1, mockWalletID, mockAccountID2, mdWallet.Role("ADMIN"), usd(0)).Return(nil, wallet.ErrInvalidMember).Once()
+			},
+		},
+		{
+			Desc:       "spender adds member",
+			Method:     "PUT",
+			Path:       membersPath + "/" + mockAccountID1,
+			Payload:    genPayload(setMemberParam{Role: "VIEWER", DailyCap: usd(0)}),
+			Auth:       mockAuth2,
+			ExpCode:    http.StatusForbidden,
+			ExpErrCode: "PERMISSION_DENIED",
+			setup: func() {
+				s.mockSrv.On("SetMember", mockCtx, mockAccountID2, mockWalletID, mockAccountID1, mdWallet.Role_VIEWER, usd(0)).Return(nil, wallet.ErrPermissionDenied).Once()
+			},
+		},
+		{
+			Desc:    "member leaves",
+			Method:  "DELETE",
+			Path:    membersPath + "/" + mockAccountID2,
+			Auth:    mockAuth2,
+			ExpCode: http.StatusNoContent,
+			setup: func() {
+				s.mockSrv.On("RemoveMember", mockCtx, mockAccountID2, mockWalletID, mockAccountID2).Return(nil).Once()
+			},
+		},
+		{
+			Desc:       "remove owner",
+			Method:     "DELETE",
+			Path:       membersPath + "/" + mockAccountID1,
+			Auth:       mockAuth2,
+			ExpCode:    http.StatusConflict,
+			ExpErrCode: "WALLET_OWNER",
+			setup: func() {
+				s.mockSrv.On("RemoveMember", mockCtx, mockAccountID2, mockWalletID, mockAccountID1).Return(wallet.ErrWalletOwner).Once()
+			},
+		},
+		{
+			Desc:    "list memberships",
+			Method:  "GET",
+			Path:    "/api/v1/wallet/memberships",
+			Auth:    mockAuth2,
+			ExpCode: http.StatusOK,
+			setup: func() {
+				s.mockSrv.On("ListMemberships", mockCtx, mockAccountID2).Return([]*mdWallet.Member{mockSpender}, nil).Once()
+			},
+		},
+	}
+
+	for _, t := range tests {
+		t.setup()
+
+		header := apitest.RequestHeader()
+		header.Set("Authorization", t.Auth)
+
+		req, err := http.NewRequest(t.Method, t.Path, bytes.NewBuffer(t.Payload))
+		req.Header = header
+		s.Require().NoError(err, t.Desc)
+
+		rr := httptest.NewRecorder()
+		s.router.ServeHTTP(rr, req)
+		s.Require().Equal(t.ExpCode, rr.Code, t.Desc)
+
+		if t.ExpErrCode != "" {
+			resp := map[string]string{}
+			s.Require().NoError(json.Unmarshal(rr.Body.Bytes(), &resp), t.Desc)
+			s.Require().Equal(t.ExpErrCode, resp["errCode"], t.Desc)
+		}
+	}
+}
+
+func (s *testSuite) TestJointWallet() {
+	tests := []struct {
+		Desc       string
+		Method     string
+		Path       string
+		Payload    string
+		ExpCode    int
+		ExpErrCode string
+		setup      func()
+	}{
+		{
+			Desc:    "spender withdraws",
+			Method:  "POST",
+			Path:    "/api/v1/wallet/withdraw",
+			Payload: `{"amount": {"amount": "1.00", "currency": "USD"}, "walletID": "` + mockWalletID + `"}`,
+			ExpCode: http.StatusOK,
+			setup: func() {
+				s.mockSrv.On("WithdrawAs", mockCtx, mockAccountID2, mockWalletID, usd(100)).Return(mockTradeID, nil, nil).Once()
+			},
+		},
+		{
+			Desc:       "spender exceeds cap",
+			Method:     "POST",
+			Path:       "/api/v1/wallet/transfer",
+			Payload:    `{"amount": {"amount": "1.00", "currency": "USD"}, "toAccount": "` + mockAccountID1 + `", "walletID": "` + mockWalletID + `"}`,
+			ExpCode:    http.StatusConflict,
+			ExpErrCode: "DAILY_CAP_EXCEEDED",
+			setup: func() {
+				s.mockSrv.On("TransferAs", mockCtx, mockAccountID2, mockWalletID, mockAccountID1, usd(100)).Return("", nil, wallet.ErrDailyCapExceeded).Once()
+			},
+		},
+		{
+			Desc:    "spender exceeds approval threshold",
+			Method:  "POST",
+			Path:    "/api/v1/wallet/withdraw",
+			Payload: `{"amount": {"amount": "50.00", "currency": "USD"}, "walletID": "` + mockWalletID + `"}`,
+			ExpCode: http.StatusAccepted,
+			setup: func() {
+				s.mockSrv.On("WithdrawAs", mockCtx, mockAccountID2, mockWalletID, usd(5000)).Return("", mockIntent, nil).Once()
+			},
+		},
+		{
+			Desc:       "spender exceeds approval threshold without other approvers",
+			Method:     "POST",
+			Path:       "/api/v1/wallet/transfer",
+			Payload:    `{"amount": {"amount": "50.00", "currency": "USD"}, "toAccount": "` + mockAccountID1 + `", "walletID": "` + mockWalletID + `"}`,
+			ExpCode:    http.StatusConflict,
+			ExpErrCode: "NOT_ENOUGH_APPROVERS",
+			setup: func() {
+				s.mockSrv.On("TransferAs", mockCtx, mockAccountID2, mockWalletID, mockAccountID1, usd(5000)).Return("", nil, wallet.ErrNotEnoughApprovers).Once()
+			},
+		},
+		{
+			Desc:    "member sees balance",
+			Method:  "GET",
+			Path:    "/api/v1/wallet/account?walletID=" + mockWalletID,
+			ExpCode: http.StatusOK,
+			setup: func() {
+				s.mockSrv.On("Authorize", mockCtx, mockAccountID2, mockWalletID).Return(mockSpender, nil).Once()
+				s.mockSrv.On("GetAccount", mockCtx, mockWalletID).Return(&mdBank.Account{AccountID: mockWalletID, Balance: usd(700)}, nil).Once()
+			},
+		},
+		{
+			Desc:       "stranger sees history",
+			Method:     "GET",
+			Path:       "/api/v1/wallet/account/transactions?walletID=" + mockAccountID1,
+			ExpCode:    http.StatusNotFound,
+			ExpErrCode: "ACCOUNT_NOT_EXIST",
+			setup: func() {
+				s.mockSrv.On("Authorize", mockCtx, mockAccountID2, mockAccountID1).Return(nil, bank.ErrAccountNotExist).Once()
+			},
+		},
+	}
+
+	for _, t := range tests {
+		t.setup()
+
+		header := apitest.RequestHeader()
+		header.Set("Authorization", mockAuth2)
+
+		req, err := http.NewRequest(t.Method, t.Path, bytes.NewBufferString(t.Payload))
+		req.Header = header
+		s.Require().NoError(err, t.Desc)
+
+		rr := httptest.NewRecorder()
+		s.router.ServeHTTP(rr, req)
+		s.Require().Equal(t.ExpCode, rr.Code, t.Desc)
+
+		if t.ExpErrCode != "" {
+			resp := map[string]string{}
+			s.Require().NoError(json.Unmarshal(rr.Body.Bytes(), &resp), t.Desc)
+			s.Require().Equal(t.ExpErrCode, resp["errCode"], t.Desc)
+		}
+	}
+}
+
+func (s *testSuite) TestHeldWithdraw() {
+	s.mockSrv.On("WithdrawAs", mockCtx, mockAccountID2, mockWalletID, usd(5000)).Return("", mockIntent, nil).Once()
+
+	header := apitest.RequestHeader()
+	header.Set("Authorization", mockAuth2)
+	req, err := http.NewRequest("POST", "/api/v1/wallet/withdraw", bytes.NewBufferString(`{"amount": {"amount": "50.00", "currency": "USD"}, "walletID": "`+mockWalletID+`"}`))
+	s.Require().NoError(err)
+	req.Header = header
+
+	rr := httptest.NewRecorder()
+	s.router.ServeHTTP(rr, req)
+	s.Require().Equal(http.StatusAccepted, rr.Code)
+
+	resp := withdrawResp{}
+	s.Require().NoError(json.Unmarshal(rr.Body.Bytes(), &resp))
+	s.Require().Equal(withdrawResp{
+		IntentID:    mockIntent.IntentID,
+		Status:      mdApproval.Status_PENDING,
+		ExpiresAtMs: mockIntent.ExpiresAtMs,
+	}, resp)
+}
@@ -2,14 +2,35 @@ package wallet
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gin-gonic/gin/binding"
+	"github.com/n3k0fi5t/wallet/app/api/apierror"
 	"github.com/n3k0fi5t/wallet/app/middleware"
+	mApproval "github.com/n3k0fi5t/wallet/app/models/approval"
+	mBank "github.com/n3k0fi5t/wallet/app/models/bank"
+	"github.com/n3k0fi5t/wallet/app/money"
 	"github.com/n3k0fi5t/wallet/app/service/wallet"
 )
 
+const (
+	defaultLimit = 50
+	maxLimit     = 500
+
+	// HeaderIdempotencyKey makes retried trades execute once
+	HeaderIdempotencyKey    = "Idempotency-Key"
+	maxIdempotencyKeyLength = 128
+)
+
+var (
+	errInvalidOffset         = fmt.Errorf("invalid offset")
+	errInvalidLimit          = fmt.Errorf("invalid limit")
+	errInvalidIdempotencyKey = fmt.Errorf("invalid idempotency key")
+)
+
 // NewHandler ...
 func NewHandler(w wallet.Service) *Handler {
 	return &Handler{
@@ -31,14 +52,75 @@ func (h *Handler) Handle(routerGroup *gin.RouterGroup) {
 	rg.Handle("POST", "/deposit", h.deposit)
 	rg.Handle("POST", "/withdraw", h.withdraw)
 	rg.Handle("POST", "/transfer", h.transfer)
+	rg.Handle("POST", "/move", h.move)
+
+	// sub-wallets of the user
+	wrg := rg.Group("/wallets")
+	wrg.Handle("GET", "", h.listWallets)
+	wrg.Handle("POST", "", h.createWallet)
+	wrg.Handle("POST", "/:walletID/rename", h.renameWallet)
+
+	// members sharing a wallet
+	wrg.Handle("GET", "/:walletID/members", h.listMembers)
+	wrg.Handle("PUT", "/:walletID/members/:userID", h.setMember)
+	wrg.Handle("DELETE", "/:walletID/members/:userID", h.removeMember)
+	rg.Handle("GET", "/memberships", h.listMemberships)
 
 	// account relative
 	arg := rg.Group("/account")
 	arg.Handle("GET", "", h.getAccountInfo)
+	arg.Handle("GET", "/transactions", h.listTransactions)
+
+	// balance and trade updates pushed as Server-Sent Events
+	rg.Handle("GET", "/events", h.events)
+
+	// trade relative
+	trg := rg.Group("/trades")
+	trg.Handle("GET", "/:tradeID", h.getTrade)
+}
+
+// errorStatus maps service errors to HTTP status code, the wallet service only returns errors shared by handlers
+func errorStatus(err error) int {
+	return apierror.Status(err)
+}
+
+func responseError(c *gin.Context, code int, err error) {
+	apierror.Response(c, code, err, nil)
+}
+
+// tradeContext attaches the idempotency key of the request to the context
+func tradeContext(c *gin.Context) (context.Context, error) {
+	ctx := c.MustGet("ctx").(context.Context)
+
+	key := c.Request.Header.Get(HeaderIdempotencyKey)
+	if key == "" {
+		return ctx, nil
+	} else if len(key) > maxIdempotencyKeyLength {
+		return nil, errInvalidIdempotencyKey
+	}
+	return mBank.WithIdempotencyKey(ctx, key), nil
+}
+
+// sourceWallet returns the wallet the request acts on, the default wallet of the user if the request does not name one
+func sourceWallet(c *gin.Context, walletID string) string {
+	if walletID == "" {
+		return mBank.DefaultWalletID(c.MustGet("userID").(string))
+	}
+	return walletID
+}
+
+// authorize returns the wallet the request acts on if the user is a member of it
+func (h *Handler) authorize(ctx context.Context, c *gin.Context, walletID string) (string, error) {
+	walletID = sourceWallet(c, walletID)
+	if _, err := h.walletSrv.Authorize(ctx, c.MustGet("userID").(string), walletID); err != nil {
+		return "", err
+	}
+	return walletID, nil
 }
 
 type depositParam struct {
-	Amount int64 `json:"amount"`
+	Amount   money.Money `json:"amount"`
+	WalletID string      `json:"walletID"`
 }
 
 type depositResp struct {
@@ -46,55 +128,75 @@ type depositResp struct {
 }
 
 func (h *Handler) deposit(c *gin.Context) {
-	ctx := c.MustGet("ctx").(context.Context)
-	accountID := c.MustGet("accountID").(string)
+	ctx, err := tradeContext(c)
+	if err != nil {
+		responseError(c, http.StatusBadRequest, err)
+		return
+	}
 
-	param := withdrawParam{}
+	param := depositParam{}
 	if err := c.ShouldBindBodyWith(&param, binding.JSON); err != nil {
-		c.JSON(http.StatusBadRequest, map[string]string{
-			"errMessage": err.Error(),
-		})
+		responseError(c, http.StatusBadRequest, err)
 		return
 	}
 
-	tradeID, err := h.walletSrv.Deposit(ctx, accountID, param.Amount)
+	walletID, err := h.authorize(ctx, c, param.WalletID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, map[string]string{
-			"errMessage": err.Error(),
-		})
+		responseError(c, errorStatus(err), err)
 		return
 	}
 
-	resp := withdrawResp{
+	tradeID, err := h.walletSrv.Deposit(ctx, walletID, param.Amount)
+	if err != nil {
+		responseError(c, errorStatus(err), err)
+		return
+	}
+
+	resp := depositResp{
 		TradeID: tradeID,
 	}
 	c.JSON(http.StatusOK, resp)
 }
 
 type withdrawParam struct {
-	Amount int64 `json:"amount"`
+	Amount   money.Money `json:"amount"`
+	WalletID string      `json:"walletID"`
 }
 
 type withdrawResp struct {
-	TradeID string `json:"tradeID"`
+	TradeID string `json:"tradeID,omitempty"`
+	// IntentID is the intent holding the amount until approved, in place of TradeID
+	IntentID    string           `json:"intentID,omitempty"`
+	Status      mApproval.Status `json:"status,omitempty"`
+	ExpiresAtMs int64            `json:"expiresAtMs,omitempty"`
 }
 
 func (h *Handler) withdraw(c *gin.Context) {
-	ctx := c.MustGet("ctx").(context.Context)
-	accountID := c.MustGet("accountID").(string)
+	userID := c.MustGet("userID").(string)
+	ctx, err := tradeContext(c)
+	if err != nil {
+		responseError(c, http.StatusBadRequest, err)
+		return
+	}
 
 	param := withdrawParam{}
 	if err := c.ShouldBindBodyWith(&param, binding.JSON); err != nil {
-		c.JSON(http.StatusBadRequest, map[string]string{
-			"errMessage": err.Error(),
-		})
+		responseError(c, http.StatusBadRequest, err)
 		return
 	}
 
-	tradeID, err := h.walletSrv.Withdraw(ctx, accountID, param.Amount)
+	tradeID, intent, err := h.walletSrv.WithdrawAs(ctx, userID, sourceWallet(c, param.WalletID), param.Amount)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, map[string]string{
-			"errMessage": err.Error(),
+		responseError(c, errorStatus(err), err)
+		return
+	}
+
+	// the amount is held until approved, the intent is followed through the approval APIs
+	if intent != nil {
+		c.JSON(http.StatusAccepted, withdrawResp{
+			IntentID:    intent.IntentID,
+			Status:      intent.Status,
+			ExpiresAtMs: intent.ExpiresAtMs,
 		})
 		return
 	}
@@ -106,30 +208,45 @@ func (h *Handler) withdraw(c *gin.Context) {
 }
 
 type transferParam struct {
-	Amount    int64  `json:"amount"`
-	ToAccount string `json:"toAccount"`
+	Amount    money.Money `json:"amount"`
+	ToAccount string      `json:"toAccount"`
+	WalletID  string      `json:"walletID"`
 }
 
 type transferResp struct {
-	TradeID string `json:"tradeID"`
+	TradeID string `json:"tradeID,omitempty"`
+	// IntentID is the intent holding the amount until approved, in place of TradeID
+	IntentID    string           `json:"intentID,omitempty"`
+	Status      mApproval.Status `json:"status,omitempty"`
+	ExpiresAtMs int64            `json:"expiresAtMs,omitempty"`
 }
 
 func (h *Handler) transfer(c *gin.Context) {
-	ctx := c.MustGet("ctx").(context.Context)
-	accountID := c.MustGet("accountID").(string)
+	userID := c.MustGet("userID").(string)
+	ctx, err := tradeContext(c)
+	if err != nil {
+		responseError(c, http.StatusBadRequest, err)
+		return
+	}
 
 	param := transferParam{}
 	if err := c.ShouldBindBodyWith(&param, binding.JSON); err != nil {
-		c.JSON(http.StatusBadRequest, map[string]string{
-			"errMessage": err.Error(),
-		})
+		responseError(c, http.StatusBadRequest, err)
 		return
 	}
 
-	tradeID, err := h.walletSrv.Transfer(ctx, accountID, param.ToAccount, param.Amount)
+	tradeID, intent, err := h.walletSrv.TransferAs(ctx, userID, sourceWallet(c, param.WalletID), param.ToAccount, param.Amount)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, map[string]string{
-			"errMessage": err.Error(),
+		responseError(c, errorStatus(err), err)
+		return
+	}
+
+	// the amount is held until approved, the intent is followed through the approval APIs
+	if intent != nil {
+		c.JSON(http.StatusAccepted, transferResp{
+			IntentID:    intent.IntentID,
+			Status:      intent.Status,
+			ExpiresAtMs: intent.ExpiresAtMs,
 		})
 		return
 	}
@@ -141,25 +258,126 @@ func (h *Handler) transfer(c *gin.Context) {
 }
 
 type accountInfoResp struct {
-	AccountID string `json:"accountID"`
-	Balance   int64  `json:"balance"`
+	AccountID string      `json:"accountID"`
+	Balance   money.Money `json:"balance"`
+	// Available is the balance plus the credit limit, what can be spent now
+	CreditLimit money.Money `json:"creditLimit"`
+	Available   money.Money `json:"available"`
+	Overdrawn   bool        `json:"overdrawn"`
 }
 
 func (h *Handler) getAccountInfo(c *gin.Context) {
 	ctx := c.MustGet("ctx").(context.Context)
-	accountID := c.MustGet("accountID").(string)
+	accountID, err := h.authorize(ctx, c, c.Query("walletID"))
+	if err != nil {
+		responseError(c, errorStatus(err), err)
+		return
+	}
 
 	account, err := h.walletSrv.GetAccount(ctx, accountID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, map[string]string{
-			"errMessage": err.Error(),
-		})
+		responseError(c, errorStatus(err), err)
 		return
 	}
 
 	resp := accountInfoResp{
-		AccountID: account.AccountID,
-		Balance:   account.Balance,
+		AccountID:   account.AccountID,
+		Balance:     account.Balance,
+		CreditLimit: account.CreditLimit,
+		Available:   account.Available(),
+		Overdrawn:   account.Overdrawn(),
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+type transactionResp struct {
+	TradeID     string      `json:"tradeID"`
+	Debit       bool        `json:"debit"`
+	Amount      money.Money `json:"amount"`
+	TimestampMs int64       `json:"timestampMs"`
+}
+
+type listTransactionsResp struct {
+	Transactions []transactionResp `json:"transactions"`
+}
+
+func (h *Handler) listTransactions(c *gin.Context) {
+	ctx := c.MustGet("ctx").(context.Context)
+
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		responseError(c, http.StatusBadRequest, errInvalidOffset)
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultLimit)))
+	if err != nil || limit <= 0 || limit > maxLimit {
+		responseError(c, http.StatusBadRequest, errInvalidLimit)
+		return
+	}
+
+	accountID, err := h.authorize(ctx, c, c.Query("walletID"))
+	if err != nil {
+		responseError(c, errorStatus(err), err)
+		return
+	}
+
+	txs, err := h.walletSrv.ListTransactions(ctx, accountID, offset, limit)
+	if err != nil {
+		responseError(c, errorStatus(err), err)
+		return
+	}
+
+	resp := listTransactionsResp{
+		Transactions: make([]transactionResp, 0, len(txs)),
+	}
+	for _, tx := range txs {
+		resp.Transactions = append(resp.Transactions, transactionResp{
+			TradeID:     tx.TradeID,
+			Debit:       tx.IsDebit(),
+			Amount:      tx.Amount,
+			TimestampMs: tx.TimestampMs,
+		})
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+type tradeEntryResp struct {
+	AccountID string      `json:"accountID"`
+	Debit     bool        `json:"debit"`
+	Amount    money.Money `json:"amount"`
+}
+
+type tradeResp struct {
+	TradeID     string           `json:"tradeID"`
+	TimestampMs int64            `json:"timestampMs"`
+	Entries     []tradeEntryResp `json:"entries"`
+}
+
+func (h *Handler) getTrade(c *gin.Context) {
+	ctx := c.MustGet("ctx").(context.Context)
+	accountID, err := h.authorize(ctx, c, c.Query("walletID"))
+	if err != nil {
+		responseError(c, errorStatus(err), err)
+		return
+	}
+
+	txs, err := h.walletSrv.GetTrade(ctx, accountID, c.Param("tradeID"))
+	if err != nil {
+		responseError(c, errorStatus(err), err)
+		return
+	}
+
+	resp := tradeResp{
+		TradeID: c.Param("tradeID"),
+		Entries: make([]tradeEntryResp, 0, len(txs)),
+	}
+	for _, tx := range txs {
+		resp.TimestampMs = tx.TimestampMs
+		resp.Entries = append(resp.Entries, tradeEntryResp{
+			AccountID: tx.AccountID,
+			Debit:     tx.IsDebit(),
+			Amount:    tx.Amount,
+		})
 	}
 	c.JSON(http.StatusOK, resp)
 }
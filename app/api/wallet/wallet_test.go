@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/gin-gonic/gin"
@@ -14,7 +15,11 @@ import (
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 
+	"github.com/n3k0fi5t/wallet/app/api/apitest"
 	mdBank "github.com/n3k0fi5t/wallet/app/models/bank"
+	mdWallet "github.com/n3k0fi5t/wallet/app/models/wallet"
+	"github.com/n3k0fi5t/wallet/app/money"
+	"github.com/n3k0fi5t/wallet/app/repository/bank"
 	"github.com/n3k0fi5t/wallet/app/service/wallet"
 	mockSrv "github.com/n3k0fi5t/wallet/app/service/wallet/mocks"
 )
@@ -27,10 +32,14 @@ var (
 	mockAuth2      = "Alex"
 	mockTradeID    = "935f871a-660f-4f19-801e-916c04bb0324"
 	mockAccount    = &mdBank.Account{
-		AccountID: mockAccountID1,
-		Balance:   3345678,
+		AccountID:   mockAccountID1,
+		Balance:     usd(3345678),
+		CreditLimit: usd(0),
 	}
 
+	mockOwner1 = &mdWallet.Member{WalletID: mockAccountID1, UserID: mockAccountID1, Role: mdWallet.Role_OWNER}
+	mockOwner2 = &mdWallet.Member{WalletID: mockAccountID2, UserID: mockAccountID2, Role: mdWallet.Role_OWNER}
+
 	mockAnyCtx              = mock.AnythingOfType("*context.Context")
 	mockHandleCtxMiddleware = func() gin.HandlerFunc {
 		return func(c *gin.Context) {
@@ -74,14 +83,6 @@ func TestSuite(t *testing.T) {
 	suite.Run(t, new(testSuite))
 }
 
-// requestHeader return a default header with content type indicating request body type
-func requestHeader() http.Header {
-	header := http.Header{}
-
-	header.Add("Content-Type", "application/json")
-	return header
-}
-
 func (s *testSuite) TestDeposit() {
 	genPayload := func(d depositParam) []byte {
 		b, err := json.Marshal(d)
@@ -99,18 +100,20 @@ func (s *testSuite) TestDeposit() {
 		{
 			Desc: "normal case",
 			setup: func() {
-				s.mockSrv.On("Deposit", mockCtx, mockAccountID1, int64(1000)).Return(mockTradeID, nil).Once()
+				s.mockSrv.On("Authorize", mockCtx, mockAccountID1, mockAccountID1).Return(mockOwner1, nil).Once()
+				s.mockSrv.On("Deposit", mockCtx, mockAccountID1, usd(1000)).Return(mockTradeID, nil).Once()
 			},
-			Payload: genPayload(depositParam{Amount: 1000}),
+			Payload: genPayload(depositParam{Amount: usd(1000)}),
 			Auth:    mockAuth1,
 			ExpCode: http.StatusOK,
 		},
 		{
 			Desc: "failed case",
 			setup: func() {
-				s.mockSrv.On("Deposit", mockCtx, mockAccountID1, int64(1000)).Return("", fmt.Errorf("")).Once()
+				s.mockSrv.On("Authorize", mockCtx, mockAccountID1, mockAccountID1).Return(mockOwner1, nil).Once()
+				s.mockSrv.On("Deposit", mockCtx, mockAccountID1, usd(1000)).Return("", fmt.Errorf("")).Once()
 			},
-			Payload: genPayload(depositParam{Amount: 1000}),
+			Payload: genPayload(depositParam{Amount: usd(1000)}),
 			Auth:    mockAuth1,
 			ExpCode: http.StatusInternalServerError,
 		},
@@ -135,7 +138,7 @@ func (s *testSuite) TestDeposit() {
 			t.setup()
 		}
 
-		header := requestHeader()
+		header := apitest.RequestHeader()
 		header.Set("Authorization", t.Auth)
 
 		req, err := http.NewRequest("POST", "/api/v1/wallet/deposit", bytes.NewBuffer(t.Payload))
@@ -166,18 +169,18 @@ func (s *testSuite) TestWithdraw() {
 		{
 			Desc: "normal case",
 			setup: func() {
-				s.mockSrv.On("Withdraw", mockCtx, mockAccountID1, int64(1000)).Return(mockTradeID, nil).Once()
+				s.mockSrv.On("WithdrawAs", mockCtx, mockAccountID1, mockAccountID1, usd(1000)).Return(mockTradeID, nil, nil).Once()
 			},
-			Payload: genPayload(withdrawParam{Amount: 1000}),
+			Payload: genPayload(withdrawParam{Amount: usd(1000)}),
 			Auth:    mockAuth1,
 			ExpCode: http.StatusOK,
 		},
 		{
 			Desc: "failed case",
 			setup: func() {
-				s.mockSrv.On("Withdraw", mockCtx, mockAccountID1, int64(1000)).Return("", fmt.Errorf("")).Once()
+				s.mockSrv.On("WithdrawAs", mockCtx, mockAccountID1, mockAccountID1, usd(1000)).Return("", nil, fmt.Errorf("")).Once()
 			},
-			Payload: genPayload(withdrawParam{Amount: 1000}),
+			Payload: genPayload(withdrawParam{Amount: usd(1000)}),
 			Auth:    mockAuth1,
 			ExpCode: http.StatusInternalServerError,
 		},
@@ -202,7 +205,7 @@ func (s *testSuite) TestWithdraw() {
 			t.setup()
 		}
 
-		header := requestHeader()
+		header := apitest.RequestHeader()
 		header.Set("Authorization", t.Auth)
 
 		req, err := http.NewRequest("POST", "/api/v1/wallet/withdraw", bytes.NewBuffer(t.Payload))
@@ -233,18 +236,18 @@ func (s *testSuite) TestTransfer() {
 		{
 			Desc: "normal case",
 			setup: func() {
-				s.mockSrv.On("Transfer", mockCtx, mockAccountID1, mockAccountID2, int64(1000)).Return(mockTradeID, nil).Once()
+				s.mockSrv.On("TransferAs", mockCtx, mockAccountID1, mockAccountID1, mockAccountID2, usd(1000)).Return(mockTradeID, nil, nil).Once()
 			},
-			Payload: genPayload(transferParam{ToAccount: mockAccountID2, Amount: 1000}),
+			Payload: genPayload(transferParam{ToAccount: mockAccountID2, Amount: usd(1000)}),
 			Auth:    mockAuth1,
 			ExpCode: http.StatusOK,
 		},
 		{
 			Desc: "failed case",
 			setup: func() {
-				s.mockSrv.On("Transfer", mockCtx, mockAccountID1, mockAccountID2, int64(1000)).Return("", fmt.Errorf("")).Once()
+				s.mockSrv.On("TransferAs", mockCtx, mockAccountID1, mockAccountID1, mockAccountID2, usd(1000)).Return("", nil, fmt.Errorf("")).Once()
 			},
-			Payload: genPayload(transferParam{ToAccount: mockAccountID2, Amount: 1000}),
+			Payload: genPayload(transferParam{ToAccount: mockAccountID2, Amount: usd(1000)}),
 			Auth:    mockAuth1,
 			ExpCode: http.StatusInternalServerError,
 		},
@@ -269,7 +272,7 @@ func (s *testSuite) TestTransfer() {
 			t.setup()
 		}
 
-		header := requestHeader()
+		header := apitest.RequestHeader()
 		header.Set("Authorization", t.Auth)
 
 		req, err := http.NewRequest("POST", "/api/v1/wallet/transfer", bytes.NewBuffer(t.Payload))
@@ -294,15 +297,17 @@ func (s *testSuite) TestGetAccount() {
 		{
 			Desc: "normal case",
 			setup: func() {
+				s.mockSrv.On("Authorize", mockCtx, mockAccountID1, mockAccountID1).Return(mockOwner1, nil).Once()
 				s.mockSrv.On("GetAccount", mockCtx, mockAccountID1).Return(mockAccount, nil).Once()
 			},
 			Auth:       mockAuth1,
 			ExpCode:    http.StatusOK,
-			ExpAccount: accountInfoResp{AccountID: mockAccountID1, Balance: mockAccount.Balance},
+			ExpAccount: accountInfoResp{AccountID: mockAccountID1, Balance: usd(3345678), CreditLimit: usd(0), Available: usd(3345678)},
 		},
 		{
 			Desc: "failed case",
 			setup: func() {
+				s.mockSrv.On("Authorize", mockCtx, mockAccountID1, mockAccountID1).Return(mockOwner1, nil).Once()
 				s.mockSrv.On("GetAccount", mockCtx, mockAccountID1).Return(nil, fmt.Errorf("")).Once()
 			},
 			Auth:    mockAuth1,
@@ -322,7 +327,7 @@ func (s *testSuite) TestGetAccount() {
 			t.setup()
 		}
 
-		header := requestHeader()
+		header := apitest.RequestHeader()
 		header.Set("Authorization", t.Auth)
 
 		req, err := http.NewRequest("GET", "/api/v1/wallet/account", nil)
@@ -341,3 +346,208 @@ func (s *testSuite) TestGetAccount() {
 		}
 	}
 }
+
+func (s *testSuite) TestListTransactions() {
+	tests := []struct {
+		Desc    string
+		Query   string
+		ExpCode int
+		Auth    string
+		setup   func()
+		ExpResp listTransactionsResp
+	}{
+		{
+			Desc:  "normal case",
+			Query: "?offset=10&limit=5",
+			setup: func() {
+				s.mockSrv.On("Authorize", mockCtx, mockAccountID1, mockAccountID1).Return(mockOwner1, nil).Once()
+				s.mockSrv.On("ListTransactions", mockCtx, mockAccountID1, 10, 5).Return([]*mdBank.Transaction{
+					{AccountID: mockAccountID1, Action: mdBank.Action_DECREASE, Amount: usd(100), TimestampMs: 1650000000000, TradeID: mockTradeID},
+				}, nil).Once()
+			},
+			Auth:    mockAuth1,
+			ExpCode: http.StatusOK,
+			ExpResp: listTransactionsResp{Transactions: []transactionResp{
+				{TradeID: mockTradeID, Debit: true, Amount: usd(100), TimestampMs: 1650000000000},
+			}},
+		},
+		{
+			Desc: "failed case",
+			setup: func() {
+				s.mockSrv.On("Authorize", mockCtx, mockAccountID2, mockAccountID2).Return(mockOwner2, nil).Once()
+				s.mockSrv.On("ListTransactions", mockCtx, mockAccountID2, 0, defaultLimit).Return(nil, fmt.Errorf("")).Once()
+			},
+			Auth:    mockAuth2,
+			ExpCode: http.StatusInternalServerError,
+		},
+		{
+			Desc:    "bad limit",
+			Query:   "?limit=100000",
+			Auth:    mockAuth1,
+			ExpCode: http.StatusBadRequest,
+		},
+		{
+			Desc:    "bad offset",
+			Query:   "?offset=-1",
+			Auth:    mockAuth1,
+			ExpCode: http.StatusBadRequest,
+		},
+		{
+			Desc:    "unauthorized case",
+			Auth:    "",
+			ExpCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, t := range tests {
+		if t.setup != nil {
+			t.setup()
+		}
+
+		header := apitest.RequestHeader()
+		header.Set("Authorization", t.Auth)
+
+		req, err := http.NewRequest("GET", "/api/v1/wallet/account/transactions"+t.Query, nil)
+		req.Header = header
+		s.Require().NoError(err, t.Desc)
+
+		rr := httptest.NewRecorder()
+		s.router.ServeHTTP(rr, req)
+		s.Require().Equal(t.ExpCode, rr.Code, t.Desc)
+
+		if t.ExpCode == http.StatusOK {
+			var resp listTransactionsResp
+			err = json.Unmarshal(rr.Body.Bytes(), &resp)
+			s.Require().NoError(err)
+			s.Require().Equal(t.ExpResp, resp, t.Desc)
+		}
+	}
+}
+
+func (s *testSuite) TestGetTrade() {
+	tests := []struct {
+		Desc    string
+		TradeID string
+		ExpCode int
+		Auth    string
+		setup   func()
+		ExpResp tradeResp
+	}{
+		{
+			Desc:    "normal case",
+			TradeID: mockTradeID,
+			setup: func() {
+				s.mockSrv.On("Authorize", mockCtx, mockAccountID1, mockAccountID1).Return(mockOwner1, nil).Once()
+				s.mockSrv.On("GetTrade", mockCtx, mockAccountID1, mockTradeID).Return([]*mdBank.Transaction{
+					{AccountID: mockAccountID1, Action: mdBank.Action_DECREASE, Amount: usd(100), TimestampMs: 1650000000000, TradeID: mockTradeID},
+					{AccountID: mockAccountID2, Action: mdBank.Action_INCREASE, Amount: usd(100), TimestampMs: 1650000000000, TradeID: mockTradeID},
+				}, nil).Once()
+			},
+			Auth:    mockAuth1,
+			ExpCode: http.StatusOK,
+			ExpResp: tradeResp{TradeID: mockTradeID, TimestampMs: 1650000000000, Entries: []tradeEntryResp{
+				{AccountID: mockAccountID1, Debit: true, Amount: usd(100)},
+				{AccountID: mockAccountID2, Debit: false, Amount: usd(100)},
+			}},
+		},
+		{
+			Desc:    "not exist case",
+			TradeID: "unknown",
+			setup: func() {
+				s.mockSrv.On("Authorize", mockCtx, mockAccountID1, mockAccountID1).Return(mockOwner1, nil).Once()
+				s.mockSrv.On("GetTrade", mockCtx, mockAccountID1, "unknown").Return(nil, bank.ErrTradeNotExist).Once()
+			},
+			Auth:    mockAuth1,
+			ExpCode: http.StatusNotFound,
+		},
+		{
+			Desc:    "unauthorized case",
+			TradeID: mockTradeID,
+			Auth:    "",
+			ExpCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, t := range tests {
+		if t.setup != nil {
+			t.setup()
+		}
+
+		header := apitest.RequestHeader()
+		header.Set("Authorization", t.Auth)
+
+		req, err := http.NewRequest("GET", "/api/v1/wallet/trades/"+t.TradeID, nil)
+		req.Header = header
+		s.Require().NoError(err, t.Desc)
+
+		rr := httptest.NewRecorder()
+		s.router.ServeHTTP(rr, req)
+		s.Require().Equal(t.ExpCode, rr.Code, t.Desc)
+
+		if t.ExpCode == http.StatusOK {
+			var resp tradeResp
+			err = json.Unmarshal(rr.Body.Bytes(), &resp)
+			s.Require().NoError(err)
+			s.Require().Equal(t.ExpResp, resp, t.Desc)
+		}
+	}
+}
+
+func (s *testSuite) TestIdempotencyKey() {
+	keyedCtx := mock.MatchedBy(func(ctx context.Context) bool {
+		return mdBank.IdempotencyKeyFrom(ctx) == "retry-1"
+	})
+
+	tests := []struct {
+		Desc    string
+		Key     string
+		ExpCode int
+		setup   func()
+	}{
+		{
+			Desc: "normal case",
+			Key:  "retry-1",
+			setup: func() {
+				s.mockSrv.On("TransferAs", keyedCtx, mockAccountID1, mockAccountID1, mockAccountID2, usd(3000)).Return(mockTradeID, nil, nil).Once()
+			},
+			ExpCode: http.StatusOK,
+		},
+		{
+			Desc: "reused case",
+			Key:  "retry-1",
+			setup: func() {
+				s.mockSrv.On("TransferAs", keyedCtx, mockAccountID1, mockAccountID1, mockAccountID2, usd(3000)).Return("", nil, bank.ErrIdempotencyKeyReused).Once()
+			},
+			ExpCode: http.StatusConflict,
+		},
+		{
+			Desc:    "too long key",
+			Key:     strings.Repeat("k", maxIdempotencyKeyLength+1),
+			ExpCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, t := range tests {
+		if t.setup != nil {
+			t.setup()
+		}
+
+		header := apitest.RequestHeader()
+		header.Set("Authorization", mockAuth1)
+		header.Set(HeaderIdempotencyKey, t.Key)
+
+		payload, err := json.Marshal(transferParam{Amount: usd(3000), ToAccount: mockAccountID2})
+		s.Require().NoError(err)
+		req, err := http.NewRequest("POST", "/api/v1/wallet/transfer", bytes.NewBuffer(payload))
+		req.Header = header
+		s.Require().NoError(err, t.Desc)
+
+		rr := httptest.NewRecorder()
+		s.router.ServeHTTP(rr, req)
+		s.Require().Equal(t.ExpCode, rr.Code, t.Desc)
+	}
+}
+
+func usd(amount int64) money.Money {
+	return money.New(amount, "USD")
+}
@@ -0,0 +1,139 @@
+package wallet
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	mBank "github.com/n3k0fi5t/wallet/app/models/bank"
+	"github.com/n3k0fi5t/wallet/app/money"
+)
+
+type walletResp struct {
+	WalletID    string      `json:"walletID"`
+	Name        string      `json:"name"`
+	Balance     money.Money `json:"balance"`
+	CreditLimit money.Money `json:"creditLimit"`
+	Available   money.Money `json:"available"`
+	Overdrawn   bool        `json:"overdrawn"`
+	Default     bool        `json:"default"`
+	Frozen      bool        `json:"frozen"`
+}
+
+func toWalletResp(w *mBank.Account) walletResp {
+	return walletResp{
+		WalletID:    w.AccountID,
+		Name:        w.Name,
+		Balance:     w.Balance,
+		CreditLimit: w.CreditLimit,
+		Available:   w.Available(),
+		Overdrawn:   w.Overdrawn(),
+		Default:     w.IsDefault(),
+		Frozen:      w.Status == mBank.AccountStatus_FROZEN,
+	}
+}
+
+type listWalletsResp struct {
+	Wallets []walletResp `json:"wallets"`
+}
+
+func (h *Handler) listWallets(c *gin.Context) {
+	ctx := c.MustGet("ctx").(context.Context)
+	userID := c.MustGet("userID").(string)
+
+	wallets, err := h.walletSrv.ListWallets(ctx, userID)
+	if err != nil {
+		responseError(c, errorStatus(err), err)
+		return
+	}
+
+	resp := listWalletsResp{
+		Wallets: make([]walletResp, 0, len(wallets)),
+	}
+	for _, w := range wallets {
+		resp.Wallets = append(resp.Wallets, toWalletResp(w))
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+type walletNameParam struct {
+	Name string `json:"name"`
+}
+
+type createWalletParam struct {
+	Name string `json:"name"`
+	// Currency is the default currency when empty
+	Currency string `json:"currency"`
+}
+
+func (h *Handler) createWallet(c *gin.Context) {
+	ctx := c.MustGet("ctx").(context.Context)
+	userID := c.MustGet("userID").(string)
+
+	param := createWalletParam{}
+	if err := c.ShouldBindBodyWith(&param, binding.JSON); err != nil {
+		responseError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	w, err := h.walletSrv.CreateWallet(ctx, userID, param.Name, param.Currency)
+	if err != nil {
+		responseError(c, errorStatus(err), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toWalletResp(w))
+}
+
+func (h *Handler) renameWallet(c *gin.Context) {
+	ctx := c.MustGet("ctx").(context.Context)
+	userID := c.MustGet("userID").(string)
+
+	param := walletNameParam{}
+	if err := c.ShouldBindBodyWith(&param, binding.JSON); err != nil {
+		responseError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	w, err := h.walletSrv.RenameWallet(ctx, userID, c.Param("walletID"), param.Name)
+	if err != nil {
+		responseError(c, errorStatus(err), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toWalletResp(w))
+}
+
+type moveParam struct {
+	FromWalletID string      `json:"fromWalletID"`
+	ToWalletID   string      `json:"toWalletID"`
+	Amount       money.Money `json:"amount"`
+}
+
+type moveResp struct {
+	TradeID string `json:"tradeID"`
+}
+
+func (h *Handler) move(c *gin.Context) {
+	userID := c.MustGet("userID").(string)
+	ctx, err := tradeContext(c)
+	if err != nil {
+		responseError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	param := moveParam{}
+	if err := c.ShouldBindBodyWith(&param, binding.JSON); err != nil {
+		responseError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	tradeID, err := h.walletSrv.Move(ctx, userID, param.FromWalletID, param.ToWalletID, param.Amount)
+	if err != nil {
+		responseError(c, errorStatus(err), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, moveResp{TradeID: tradeID})
+}
@@ -0,0 +1,193 @@
+package wallet
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/n3k0fi5t/wallet/app/api/apitest"
+	mdBank "github.com/n3k0fi5t/wallet/app/models/bank"
+	"github.com/n3k0fi5t/wallet/app/money"
+	"github.com/n3k0fi5t/wallet/app/repository/bank"
+	"github.com/n3k0fi5t/wallet/app/service/wallet"
+)
+
+var (
+	mockWalletID = "5e0e8b3c-1f6a-4c39-9a52-6b4a8f1b0d7e"
+	mockWallets  = []*mdBank.Account{
+		{AccountID: mockAccountID1, OwnerID: mockAccountID1, Name: "Main", Balance: usd(1000), CreditLimit: usd(0)},
+		{AccountID: mockWalletID, OwnerID: mockAccountID1, Name: "Savings", Status: mdBank.AccountStatus_FROZEN, Balance: money.New(0, "JPY"), CreditLimit: money.New(0, "JPY")},
+	}
+)
+
+func (s *testSuite) TestListWallets() {
+	s.mockSrv.On("ListWallets", mockCtx, mockAccountID1).Return(mockWallets, nil).Once()
+
+	header := apitest.RequestHeader()
+	header.Set("Authorization", mockAuth1)
+	req, err := http.NewRequest("GET", "/api/v1/wallet/wallets", nil)
+	s.Require().NoError(err)
+	req.Header = header
+
+	rr := httptest.NewRecorder()
+	s.router.ServeHTTP(rr, req)
+	s.Require().Equal(http.StatusOK, rr.Code)
+
+	resp := listWalletsResp{}
+	s.Require().NoError(json.Unmarshal(rr.Body.Bytes(), &resp))
+	s.Require().Equal(listWalletsResp{Wallets: []walletResp{
+		{WalletID: mockAccountID1, Name: "Main", Balance: usd(1000), CreditLimit: usd(0), Available: usd(1000), Default: true},
+		{WalletID: mockWalletID, Name: "Savings", Balance: money.New(0, "JPY"), CreditLimit: money.New(0, "JPY"), Available: money.New(0, "JPY"), Frozen: true},
+	}}, resp)
+}
+
+func (s *testSuite) TestCreateAndRenameWallet() {
+	genPayload := func(d interface{}) []byte {
+		b, err := json.Marshal(d)
+		s.Require().NoError(err)
+		return b
+	}
+
+	tests := []struct {
+		Desc       string
+		Path       string
+		Payload    []byte
+		ExpCode    int
+		ExpErrCode string
+		setup      func()
+	}{
+		{
+			Desc:    "create",
+			Path:    "/api/v1/wallet/wallets",
+			Payload: genPayload(walletNameParam{Name: "Travel"}),
+			ExpCode: http.StatusOK,
+			setup: func() {
+				s.mockSrv.On("CreateWallet", mockCtx, mockAccountID1, "Travel", "").Return(&mdBank.Account{AccountID: mockWalletID, OwnerID: mockAccountID1, Name: "Travel", Balance: usd(0)}, nil).Once()
+			},
+		},
+		{
+			Desc:       "create of unknown currency",
+			Path:       "/api/v1/wallet/wallets",
+			Payload:    genPayload(createWalletParam{Name: "Travel", Currency: "XYZ"}),
+			ExpCode:    http.StatusBadRequest,
+			ExpErrCode: "INVALID_CURRENCY",
+			setup: func() {
+				s.mockSrv.On("CreateWallet", mockCtx, mockAccountID1, "Travel", "XYZ").Return(nil, wallet.ErrInvalidCurrency).Once()
+			},
+		},
+		{
+			Desc:       "create too many",
+			Path:       "/api/v1/wallet/wallets",
+			Payload:    genPayload(walletNameParam{Name: "Eleventh"}),
+			ExpCode:    http.StatusConflict,
+			ExpErrCode: "TOO_MANY_WALLETS",
+			setup: func() {
+				s.mockSrv.On("CreateWallet", mockCtx, mockAccountID1, "Eleventh", "").Return(nil, wallet.ErrTooManyWallets).Once()
+			},
+		},
+		{
+			Desc:       "rename to taken name",
+			Path:       "/api/v1/wallet/wallets/" + mockWalletID + "/rename",
+			Payload:    genPayload(walletNameParam{Name: "Main"}),
+			ExpCode:    http.StatusConflict,
+			ExpErrCode: "WALLET_NAME_TAKEN",
+			setup: func() {
+				s.mockSrv.On("RenameWallet", mockCtx, mockAccountID1, mockWalletID, "Main").Return(nil, wallet.ErrWalletNameTaken).Once()
+			},
+		},
+		{
+			Desc:       "rename wallet of others",
+			Path:       "/api/v1/wallet/wallets/" + mockAccountID2 + "/rename",
+			Payload:    genPayload(walletNameParam{Name: "Mine"}),
+			ExpCode:    http.StatusNotFound,
+			ExpErrCode: "ACCOUNT_NOT_EXIST",
+			setup: func() {
+				s.mockSrv.On("RenameWallet", mockCtx, mockAccountID1, mockAccountID2, "Mine").Return(nil, bank.ErrAccountNotExist).Once()
+			},
+		},
+		{
+			Desc:    "bad param",
+			Path:    "/api/v1/wallet/wallets",
+			ExpCode: http.StatusBadRequest,
+			setup:   func() {},
+		},
+	}
+
+	for _, t := range tests {
+		t.setup()
+
+		header := apitest.RequestHeader()
+		header.Set("Authorization", mockAuth1)
+
+		req, err := http.NewRequest("POST", t.Path, bytes.NewBuffer(t.Payload))
+		req.Header = header
+		s.Require().NoError(err, t.Desc)
+
+		rr := httptest.NewRecorder()
+		s.router.ServeHTTP(rr, req)
+		s.Require().Equal(t.ExpCode, rr.Code, t.Desc)
+
+		if t.ExpErrCode != "" {
+			resp := map[string]string{}
+			s.Require().NoError(json.Unmarshal(rr.Body.Bytes(), &resp), t.Desc)
+			s.Require().Equal(t.ExpErrCode, resp["errCode"], t.Desc)
+		}
+	}
+}
+
+func (s *testSuite) TestMove() {
+	genPayload := func(d moveParam) []byte {
+		b, err := json.Marshal(d)
+		s.Require().NoError(err)
+		return b
+	}
+
+	tests := []struct {
+		Desc    string
+		Payload []byte
+		Auth    string
+		ExpCode int
+		setup   func()
+	}{
+		{
+			Desc:    "normal case",
+			Payload: genPayload(moveParam{FromWalletID: mockAccountID1, ToWalletID: mockWalletID, Amount: usd(500)}),
+			Auth:    mockAuth1,
+			ExpCode: http.StatusOK,
+			setup: func() {
+				s.mockSrv.On("Move", mockCtx, mockAccountID1, mockAccountID1, mockWalletID, usd(500)).Return(mockTradeID, nil).Once()
+			},
+		},
+		{
+			Desc:    "frozen wallet",
+			Payload: genPayload(moveParam{FromWalletID: mockWalletID, ToWalletID: mockAccountID1, Amount: usd(500)}),
+			Auth:    mockAuth1,
+			ExpCode: http.StatusConflict,
+			setup: func() {
+				s.mockSrv.On("Move", mockCtx, mockAccountID1, mockWalletID, mockAccountID1, usd(500)).Return("", bank.ErrAccountFrozen).Once()
+			},
+		},
+		{
+			Desc:    "unauthorized case",
+			Auth:    "",
+			ExpCode: http.StatusUnauthorized,
+			setup:   func() {},
+		},
+	}
+
+	for _, t := range tests {
+		t.setup()
+
+		header := apitest.RequestHeader()
+		header.Set("Authorization", t.Auth)
+
+		req, err := http.NewRequest("POST", "/api/v1/wallet/move", bytes.NewBuffer(t.Payload))
+		req.Header = header
+		s.Require().NoError(err, t.Desc)
+
+		rr := httptest.NewRecorder()
+		s.router.ServeHTTP(rr, req)
+		s.Require().Equal(t.ExpCode, rr.Code, t.Desc)
+	}
+}
@@ -0,0 +1,269 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/n3k0fi5t/wallet/app/api/apierror"
+	"github.com/n3k0fi5t/wallet/app/middleware"
+	mBank "github.com/n3k0fi5t/wallet/app/models/bank"
+	mWebhook "github.com/n3k0fi5t/wallet/app/models/webhook"
+	rWebhook "github.com/n3k0fi5t/wallet/app/repository/webhook"
+	"github.com/n3k0fi5t/wallet/app/service/wallet"
+	"github.com/n3k0fi5t/wallet/app/service/webhook"
+)
+
+const (
+	defaultLimit = 50
+	maxLimit     = 500
+)
+
+var (
+	errInvalidOffset = fmt.Errorf("invalid offset")
+	errInvalidLimit  = fmt.Errorf("invalid limit")
+)
+
+// NewHandler ...
+func NewHandler(w webhook.Service, ws wallet.Service) *Handler {
+	return &Handler{
+		webhookSrv: w,
+		walletSrv:  ws,
+	}
+}
+
+type Handler struct {
+	webhookSrv webhook.Service
+	walletSrv  wallet.Service
+}
+
+func (h *Handler) Handle(routerGroup *gin.RouterGroup) {
+	rg := routerGroup.Group("/wallet/webhooks")
+
+	// APIs are only for authed user
+	rg.Use(middleware.GetUserAccount())
+
+	// endpoint relative
+	rg.Handle("POST", "", h.registerEndpoint)
+	rg.Handle("GET", "", h.listEndpoints)
+	rg.Handle("DELETE", "/:endpointID", h.deleteEndpoint)
+
+	// delivery relative
+	rg.Handle("GET", "/:endpointID/deliveries", h.listDeliveries)
+	rg.Handle("POST", "/:endpointID/deliveries/:deliveryID/redeliver", h.redeliver)
+}
+
+// errorStatus maps service errors to HTTP status code
+func errorStatus(err error) int {
+	switch err {
+	case webhook.ErrInvalidURL, webhook.ErrInvalidEventType:
+		return http.StatusBadRequest
+	case webhook.ErrTooManyEndpoints:
+		return http.StatusConflict
+	case rWebhook.ErrEndpointNotExist, rWebhook.ErrDeliveryNotExist:
+		return http.StatusNotFound
+	default:
+		return apierror.Status(err)
+	}
+}
+
+func responseError(c *gin.Context, code int, err error) {
+	apierror.Response(c, code, err, nil)
+}
+
+// authorize returns the wallet whose endpoints the request manages if the user is a member of it, the default wallet
+// of the user when none is given
+func (h *Handler) authorize(ctx context.Context, c *gin.Context, walletID string) (string, error) {
+	userID := c.MustGet("userID").(string)
+	if walletID == "" {
+		walletID = mBank.DefaultWalletID(userID)
+	}
+	if _, err := h.walletSrv.Authorize(ctx, userID, walletID); err != nil {
+		return "", err
+	}
+	return walletID, nil
+}
+
+type registerEndpointParam struct {
+	URL        string               `json:"url" binding:"required"`
+	EventTypes []mWebhook.EventType `json:"eventTypes" binding:"required"`
+	// WalletID is the wallet whose events the endpoint receives, the default wallet if empty
+	WalletID string `json:"walletID"`
+}
+
+type endpointResp struct {
+	EndpointID string               `json:"endpointID"`
+	URL        string               `json:"url"`
+	EventTypes []mWebhook.EventType `json:"eventTypes"`
+	// Secret is only returned on registration
+	Secret    string `json:"secret,omitempty"`
+	CreatedMs int64  `json:"createdMs"`
+}
+
+func toEndpointResp(e *mWebhook.Endpoint) endpointResp {
+	resp := endpointResp{
+		EndpointID: e.EndpointID,
+		URL:        e.URL,
+		EventTypes: []mWebhook.EventType{},
+		CreatedMs:  e.CreatedMs,
+	}
+	for _, t := range mWebhook.EventTypes {
+		if e.Subscribes(t) {
+			resp.EventTypes = append(resp.EventTypes, t)
+		}
+	}
+	return resp
+}
+
+func (h *Handler) registerEndpoint(c *gin.Context) {
+	ctx := c.MustGet("ctx").(context.Context)
+
+	param := registerEndpointParam{}
+	if err := c.ShouldBindBodyWith(&param, binding.JSON); err != nil {
+		responseError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	accountID, err := h.authorize(ctx, c, param.WalletID)
+	if err != nil {
+		responseError(c, errorStatus(err), err)
+		return
+	}
+
+	endpoint, err := h.webhookSrv.RegisterEndpoint(ctx, accountID, param.URL, param.EventTypes)
+	if err != nil {
+		responseError(c, errorStatus(err), err)
+		return
+	}
+
+	resp := toEndpointResp(endpoint)
+	resp.Secret = endpoint.Secret
+	c.JSON(http.StatusOK, resp)
+}
+
+type listEndpointsResp struct {
+	Endpoints []endpointResp `json:"endpoints"`
+}
+
+func (h *Handler) listEndpoints(c *gin.Context) {
+	ctx := c.MustGet("ctx").(context.Context)
+
+	accountID, err := h.authorize(ctx, c, c.Query("walletID"))
+	if err != nil {
+		responseError(c, errorStatus(err), err)
+		return
+	}
+
+	endpoints, err := h.webhookSrv.ListEndpoints(ctx, accountID)
+	if err != nil {
+		responseError(c, errorStatus(err), err)
+		return
+	}
+
+	resp := listEndpointsResp{
+		Endpoints: make([]endpointResp, 0, len(endpoints)),
+	}
+	for _, e := range endpoints {
+		resp.Endpoints = append(resp.Endpoints, toEndpointResp(e))
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *Handler) deleteEndpoint(c *gin.Context) {
+	ctx := c.MustGet("ctx").(context.Context)
+
+	accountID, err := h.authorize(ctx, c, c.Query("walletID"))
+	if err != nil {
+		responseError(c, errorStatus(err), err)
+		return
+	}
+
+	if err := h.webhookSrv.DeleteEndpoint(ctx, accountID, c.Param("endpointID")); err != nil {
+		responseError(c, errorStatus(err), err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+type deliveryResp struct {
+	DeliveryID     string                  `json:"deliveryID"`
+	EventID        string                  `json:"eventID"`
+	EventType      mWebhook.EventType      `json:"eventType"`
+	Status         mWebhook.DeliveryStatus `json:"status"`
+	Attempts       int                     `json:"attempts"`
+	NextAttemptMs  int64                   `json:"nextAttemptMs"`
+	LastStatusCode int                     `json:"lastStatusCode"`
+	LastError      string                  `json:"lastError"`
+	CreatedMs      int64                   `json:"createdMs"`
+}
+
+type listDeliveriesResp struct {
+	Deliveries []deliveryResp `json:"deliveries"`
+}
+
+func (h *Handler) listDeliveries(c *gin.Context) {
+	ctx := c.MustGet("ctx").(context.Context)
+
+	accountID, err := h.authorize(ctx, c, c.Query("walletID"))
+	if err != nil {
+		responseError(c, errorStatus(err), err)
+		return
+	}
+
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		responseError(c, http.StatusBadRequest, errInvalidOffset)
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultLimit)))
+	if err != nil || limit <= 0 || limit > maxLimit {
+		responseError(c, http.StatusBadRequest, errInvalidLimit)
+		return
+	}
+
+	status := mWebhook.DeliveryStatus(c.Query("status"))
+	deliveries, err := h.webhookSrv.ListDeliveries(ctx, accountID, c.Param("endpointID"), status, offset, limit)
+	if err != nil {
+		responseError(c, errorStatus(err), err)
+		return
+	}
+
+	resp := listDeliveriesResp{
+		Deliveries: make([]deliveryResp, 0, len(deliveries)),
+	}
+	for _, d := range deliveries {
+		resp.Deliveries = append(resp.Deliveries, deliveryResp{
+			DeliveryID:     d.DeliveryID,
+			EventID:        d.EventID,
+			EventType:      d.EventType,
+			Status:         d.Status,
+			Attempts:       d.Attempts,
+			NextAttemptMs:  d.NextAttemptMs,
+			LastStatusCode: d.LastStatusCode,
+			LastError:      d.LastError,
+			CreatedMs:      d.CreatedMs,
+		})
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *Handler) redeliver(c *gin.Context) {
+	ctx := c.MustGet("ctx").(context.Context)
+
+	accountID, err := h.authorize(ctx, c, c.Query("walletID"))
+	if err != nil {
+		responseError(c, errorStatus(err), err)
+		return
+	}
+
+	if err := h.webhookSrv.Redeliver(ctx, accountID, c.Param("endpointID"), c.Param("deliveryID")); err != nil {
+		responseError(c, errorStatus(err), err)
+		return
+	}
+
+	c.Status(http.StatusAccepted)
+}
@@ -0,0 +1,356 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/n3k0fi5t/wallet/app/api/apitest"
+	mdWallet "github.com/n3k0fi5t/wallet/app/models/wallet"
+	mdWebhook "github.com/n3k0fi5t/wallet/app/models/webhook"
+	"github.com/n3k0fi5t/wallet/app/repository/bank"
+	rWebhook "github.com/n3k0fi5t/wallet/app/repository/webhook"
+	mockWallet "github.com/n3k0fi5t/wallet/app/service/wallet/mocks"
+	"github.com/n3k0fi5t/wallet/app/service/webhook"
+	mockSrv "github.com/n3k0fi5t/wallet/app/service/webhook/mocks"
+)
+
+var (
+	mockCtx        = context.Background()
+	mockAccountID  = "935f871a-660f-4f19-801e-916c04bb0324"
+	mockAuth       = "Tim"
+	mockWalletID   = "4b0f5b8e-8f0c-4d3e-9a7d-2f6c1e0b9a55"
+	mockStranger   = "0d7f3c1a-5b6e-4f8d-9c2a-1e3b5d7f9a0c"
+	mockEndpointID = "a89b7b78-b9c1-4129-8cff-380bf53f3a49"
+	mockDeliveryID = "a98cd0f5-d6b2-4899-a1fb-ddf308d6f5c8"
+	mockURL        = "https://partner.example.com/hook"
+	mockEndpoint   = &mdWebhook.Endpoint{
+		EndpointID: mockEndpointID,
+		AccountID:  mockAccountID,
+		URL:        mockURL,
+		Secret:     "whsec_test",
+		EventTypes: "deposit,transfer.received",
+	}
+
+	mockHandleCtxMiddleware = func() gin.HandlerFunc {
+		return func(c *gin.Context) {
+			c.Set("ctx", mockCtx)
+			c.Next()
+		}
+	}
+)
+
+type testSuite struct {
+	suite.Suite
+
+	router     *gin.Engine
+	mockSrv    *mockSrv.Service
+	mockWallet *mockWallet.Service
+	wsrv       webhook.Service
+}
+
+func (s *testSuite) SetupSuite() {
+	s.router = gin.Default()
+	s.mockSrv = &mockSrv.Service{}
+	s.mockWallet = &mockWallet.Service{}
+	s.wsrv = s.mockSrv
+	handler := NewHandler(s.wsrv, s.mockWallet)
+	rg := s.router.Group("/api/v1")
+	rg.Use(mockHandleCtxMiddleware())
+	handler.Handle(rg)
+}
+
+func (s *testSuite) TearDownSuite() {
+	s.mockSrv.AssertExpectations(s.T())
+	s.mockWallet.AssertExpectations(s.T())
+}
+
+func (s *testSuite) SetupTest() {
+	// endpoints of the default wallet unless a test names another one
+	s.mockWallet.On("Authorize", mockCtx, mockAccountID, mockAccountID).Return(&mdWallet.Member{WalletID: mockAccountID, UserID: mockAccountID, Role: mdWallet.Role_OWNER}, nil).Maybe()
+}
+
+func TestSuite(t *testing.T) {
+	suite.Run(t, new(testSuite))
+}
+
+func (s *testSuite) TestRegisterEndpoint() {
+	genPayload := func(d registerEndpointParam) []byte {
+		b, err := json.Marshal(d)
+		s.Require().NoError(err)
+		return b
+	}
+	types := []mdWebhook.EventType{mdWebhook.EventType_DEPOSIT, mdWebhook.EventType_TRANSFER_RECEIVED}
+
+	tests := []struct {
+		Desc      string
+		Payload   []byte
+		ExpCode   int
+		ExpSecret string
+		setup     func()
+	}{
+		{
+			Desc: "normal case",
+			setup: func() {
+				s.mockSrv.On("RegisterEndpoint", mockCtx, mockAccountID, mockURL, types).Return(mockEndpoint, nil).Once()
+			},
+			Payload:   genPayload(registerEndpointParam{URL: mockURL, EventTypes: types}),
+			ExpCode:   http.StatusOK,
+			ExpSecret: mockEndpoint.Secret,
+		},
+		{
+			Desc: "invalid url case",
+			setup: func() {
+				s.mockSrv.On("RegisterEndpoint", mockCtx, mockAccountID, "nope", types).Return(nil, webhook.ErrInvalidURL).Once()
+			},
+			Payload: genPayload(registerEndpointParam{URL: "nope", EventTypes: types}),
+			ExpCode: http.StatusBadRequest,
+		},
+		{
+			Desc: "too many endpoints case",
+			setup: func() {
+				s.mockSrv.On("RegisterEndpoint", mockCtx, mockAccountID, mockURL+"/2", types).Return(nil, webhook.ErrTooManyEndpoints).Once()
+			},
+			Payload: genPayload(registerEndpointParam{URL: mockURL + "/2", EventTypes: types}),
+			ExpCode: http.StatusConflict,
+		},
+		{
+			Desc:    "bad param",
+			Payload: genPayload(registerEndpointParam{EventTypes: types}),
+			ExpCode: http.StatusBadRequest,
+		},
+		{
+			Desc: "joint wallet case",
+			setup: func() {
+				s.mockWallet.On("Authorize", mockCtx, mockAccountID, mockWalletID).Return(&mdWallet.Member{WalletID: mockWalletID, UserID: mockAccountID, Role: mdWallet.Role_VIEWER}, nil).Once()
+				s.mockSrv.On("RegisterEndpoint", mockCtx, mockWalletID, mockURL, types).Return(mockEndpoint, nil).Once()
+			},
+			Payload:   genPayload(registerEndpointParam{URL: mockURL, EventTypes: types, WalletID: mockWalletID}),
+			ExpCode:   http.StatusOK,
+			ExpSecret: mockEndpoint.Secret,
+		},
+		{
+			Desc: "not a member case",
+			setup: func() {
+				s.mockWallet.On("Authorize", mockCtx, mockAccountID, mockStranger).Return(nil, bank.ErrAccountNotExist).Once()
+			},
+			Payload: genPayload(registerEndpointParam{URL: mockURL, EventTypes: types, WalletID: mockStranger}),
+			ExpCode: http.StatusNotFound,
+		},
+	}
+
+	for _, t := range tests {
+		if t.setup != nil {
+			t.setup()
+		}
+
+		req, err := http.NewRequest("POST", "/api/v1/wallet/webhooks", bytes.NewBuffer(t.Payload))
+		s.Require().NoError(err, t.Desc)
+		req.Header = apitest.RequestHeader()
+		req.Header.Set("Authorization", mockAuth)
+
+		rr := httptest.NewRecorder()
+		s.router.ServeHTTP(rr, req)
+		s.Require().Equal(t.ExpCode, rr.Code, t.Desc)
+
+		if t.ExpCode == http.StatusOK {
+			resp := endpointResp{}
+			s.Require().NoError(json.Unmarshal(rr.Body.Bytes(), &resp), t.Desc)
+			s.Require().Equal(t.ExpSecret, resp.Secret, t.Desc)
+			s.Require().Equal(types, resp.EventTypes, t.Desc)
+		}
+	}
+}
+
+func (s *testSuite) TestListEndpoints() {
+	tests := []struct {
+		Desc     string
+		WalletID string
+		ExpCode  int
+		setup    func()
+	}{
+		{
+			Desc: "normal case",
+			setup: func() {
+				s.mockSrv.On("ListEndpoints", mockCtx, mockAccountID).Return([]*mdWebhook.Endpoint{mockEndpoint}, nil).Once()
+			},
+			ExpCode: http.StatusOK,
+		},
+		{
+			Desc:     "joint wallet case",
+			WalletID: mockWalletID,
+			setup: func() {
+				s.mockWallet.On("Authorize", mockCtx, mockAccountID, mockWalletID).Return(&mdWallet.Member{WalletID: mockWalletID, UserID: mockAccountID, Role: mdWallet.Role_SPENDER}, nil).Once()
+				s.mockSrv.On("ListEndpoints", mockCtx, mockWalletID).Return([]*mdWebhook.Endpoint{mockEndpoint}, nil).Once()
+			},
+			ExpCode: http.StatusOK,
+		},
+		{
+			Desc:     "not a member case",
+			WalletID: mockStranger,
+			setup: func() {
+				s.mockWallet.On("Authorize", mockCtx, mockAccountID, mockStranger).Return(nil, bank.ErrAccountNotExist).Once()
+			},
+			ExpCode: http.StatusNotFound,
+		},
+	}
+
+	for _, t := range tests {
+		if t.setup != nil {
+			t.setup()
+		}
+
+		req, err := http.NewRequest("GET", "/api/v1/wallet/webhooks?walletID="+t.WalletID, nil)
+		s.Require().NoError(err, t.Desc)
+		req.Header = apitest.RequestHeader()
+		req.Header.Set("Authorization", mockAuth)
+
+		rr := httptest.NewRecorder()
+		s.router.ServeHTTP(rr, req)
+		s.Require().Equal(t.ExpCode, rr.Code, t.Desc)
+
+		if t.ExpCode == http.StatusOK {
+			// secret is never listed
+			resp := listEndpointsResp{}
+			s.Require().NoError(json.Unmarshal(rr.Body.Bytes(), &resp), t.Desc)
+			s.Require().Len(resp.Endpoints, 1, t.Desc)
+			s.Require().Empty(resp.Endpoints[0].Secret, t.Desc)
+		}
+	}
+}
+
+func (s *testSuite) TestDeleteEndpoint() {
+	tests := []struct {
+		Desc       string
+		EndpointID string
+		ExpCode    int
+		setup      func()
+	}{
+		{
+			Desc:       "normal case",
+			EndpointID: mockEndpointID,
+			setup: func() {
+				s.mockSrv.On("DeleteEndpoint", mockCtx, mockAccountID, mockEndpointID).Return(nil).Once()
+			},
+			ExpCode: http.StatusNoContent,
+		},
+		{
+			Desc:       "not exist case",
+			EndpointID: "unknown",
+			setup: func() {
+				s.mockSrv.On("DeleteEndpoint", mockCtx, mockAccountID, "unknown").Return(rWebhook.ErrEndpointNotExist).Once()
+			},
+			ExpCode: http.StatusNotFound,
+		},
+	}
+
+	for _, t := range tests {
+		if t.setup != nil {
+			t.setup()
+		}
+
+		req, err := http.NewRequest("DELETE", "/api/v1/wallet/webhooks/"+t.EndpointID, nil)
+		s.Require().NoError(err, t.Desc)
+		req.Header = apitest.RequestHeader()
+		req.Header.Set("Authorization", mockAuth)
+
+		rr := httptest.NewRecorder()
+		s.router.ServeHTTP(rr, req)
+		s.Require().Equal(t.ExpCode, rr.Code, t.Desc)
+	}
+}
+
+func (s *testSuite) TestListDeliveries() {
+	tests := []struct {
+		Desc    string
+		Query   string
+		ExpCode int
+		setup   func()
+	}{
+		{
+			Desc:  "normal case",
+			Query: "?status=DEAD&offset=10&limit=5",
+			setup: func() {
+				s.mockSrv.On("ListDeliveries", mockCtx, mockAccountID, mockEndpointID, mdWebhook.DeliveryStatus_DEAD, 10, 5).Return([]*mdWebhook.Delivery{{DeliveryID: mockDeliveryID}}, nil).Once()
+			},
+			ExpCode: http.StatusOK,
+		},
+		{
+			Desc:  "failed case",
+			Query: "",
+			setup: func() {
+				s.mockSrv.On("ListDeliveries", mockCtx, mockAccountID, mockEndpointID, mdWebhook.DeliveryStatus(""), 0, defaultLimit).Return(nil, fmt.Errorf("")).Once()
+			},
+			ExpCode: http.StatusInternalServerError,
+		},
+		{
+			Desc:    "bad limit",
+			Query:   "?limit=0",
+			ExpCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, t := range tests {
+		if t.setup != nil {
+			t.setup()
+		}
+
+		req, err := http.NewRequest("GET", "/api/v1/wallet/webhooks/"+mockEndpointID+"/deliveries"+t.Query, nil)
+		s.Require().NoError(err, t.Desc)
+		req.Header = apitest.RequestHeader()
+		req.Header.Set("Authorization", mockAuth)
+
+		rr := httptest.NewRecorder()
+		s.router.ServeHTTP(rr, req)
+		s.Require().Equal(t.ExpCode, rr.Code, t.Desc)
+	}
+}
+
+func (s *testSuite) TestRedeliver() {
+	tests := []struct {
+		Desc       string
+		DeliveryID string
+		ExpCode    int
+		setup      func()
+	}{
+		{
+			Desc:       "normal case",
+			DeliveryID: mockDeliveryID,
+			setup: func() {
+				s.mockSrv.On("Redeliver", mockCtx, mockAccountID, mockEndpointID, mockDeliveryID).Return(nil).Once()
+			},
+			ExpCode: http.StatusAccepted,
+		},
+		{
+			Desc:       "not exist case",
+			DeliveryID: "unknown",
+			setup: func() {
+				s.mockSrv.On("Redeliver", mockCtx, mockAccountID, mockEndpointID, "unknown").Return(rWebhook.ErrDeliveryNotExist).Once()
+			},
+			ExpCode: http.StatusNotFound,
+		},
+	}
+
+	for _, t := range tests {
+		if t.setup != nil {
+			t.setup()
+		}
+
+		req, err := http.NewRequest("POST", "/api/v1/wallet/webhooks/"+mockEndpointID+"/deliveries/"+t.DeliveryID+"/redeliver", nil)
+		s.Require().NoError(err, t.Desc)
+		req.Header = apitest.RequestHeader()
+		req.Header.Set("Authorization", mockAuth)
+
+		rr := httptest.NewRecorder()
+		s.router.ServeHTTP(rr, req)
+		s.Require().Equal(t.ExpCode, rr.Code, t.Desc)
+	}
+}
@@ -0,0 +1,144 @@
+package broker
+
+import (
+	"sync"
+
+	mBank "github.com/n3k0fi5t/wallet/app/models/bank"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	subscribers = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "broker_subscribers",
+		Help: "Subscribers of account updates in this process",
+	})
+
+	dropped = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "broker_dropped_subscribers_total",
+		Help: "Subscribers dropped since they fell behind",
+	})
+)
+
+// Broker fans out committed account updates to subscribers in the same process.
+// Subscribers of other replicas are not reached, they catch up from the trade log when they resume
+type Broker interface {
+	// Publish hands updates to subscribers of their accounts without blocking, subscribers falling behind are dropped
+	Publish(updates ...*mBank.Update)
+
+	// Subscribe subscribes updates of the account published from now on, the caller closes the subscription
+	Subscribe(accountID string) *Subscription
+
+	// Close drops all subscribers and the ones subscribing later, e.g. to end streams on shutdown
+	Close()
+}
+
+// Subscription receives updates of one account in publish order
+type Subscription struct {
+	accountID string
+	ch        chan *mBank.Update
+	broker    *broker
+
+	// lagged is guarded by broker.mu
+	lagged bool
+}
+
+// Updates is closed when the subscription is closed or dropped
+func (s *Subscription) Updates() <-chan *mBank.Update {
+	return s.ch
+}
+
+// Lagged reports whether the subscription was dropped since its buffer was full
+func (s *Subscription) Lagged() bool {
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+
+	return s.lagged
+}
+
+// Close stops the subscription, it is safe to close more than once
+func (s *Subscription) Close() {
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+
+	if _, ok := s.broker.subs[s.accountID][s]; ok {
+		s.broker.remove(s)
+	}
+}
+
+// NewBroker returns a broker buffering up to buffer updates per subscriber
+func NewBroker(buffer int) Broker {
+	return &broker{
+		buffer: buffer,
+		subs:   map[string]map[*Subscription]struct{}{},
+	}
+}
+
+type broker struct {
+	mu     sync.Mutex
+	buffer int
+	subs   map[string]map[*Subscription]struct{}
+	closed bool
+}
+
+func (b *broker) Publish(updates ...*mBank.Update) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, u := range updates {
+		for sub := range b.subs[u.AccountID] {
+			select {
+			case sub.ch <- u:
+			default:
+				// a slow subscriber must not block trades, it resumes from the trade log instead
+				sub.lagged = true
+				dropped.Inc()
+				b.remove(sub)
+			}
+		}
+	}
+}
+
+func (b *broker) Subscribe(accountID string) *Subscription {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	sub := &Subscription{
+		accountID: accountID,
+		ch:        make(chan *mBank.Update, b.buffer),
+		broker:    b,
+	}
+	if b.closed {
+		close(sub.ch)
+		return sub
+	}
+
+	if b.subs[accountID] == nil {
+		b.subs[accountID] = map[*Subscription]struct{}{}
+	}
+	b.subs[accountID][sub] = struct{}{}
+	subscribers.Inc()
+	return sub
+}
+
+func (b *broker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	for _, subs := range b.subs {
+		for sub := range subs {
+			b.remove(sub)
+		}
+	}
+}
+
+// remove drops the subscription, the caller holds mu
+func (b *broker) remove(sub *Subscription) {
+	delete(b.subs[sub.accountID], sub)
+	if len(b.subs[sub.accountID]) == 0 {
+		delete(b.subs, sub.accountID)
+	}
+	close(sub.ch)
+	subscribers.Dec()
+}
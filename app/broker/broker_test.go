@@ -0,0 +1,103 @@
+package broker
+
+import (
+	"testing"
+
+	mdBank "github.com/n3k0fi5t/wallet/app/models/bank"
+	"github.com/n3k0fi5t/wallet/app/money"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	mockAccountID1 = "935f871a-660f-4f19-801e-916c04bb0324"
+	mockAccountID2 = "a89b7b78-b9c1-4129-8cff-380bf53f3a49"
+)
+
+func mockUpdate(id int64, accountID string) *mdBank.Update {
+	return &mdBank.Update{
+		ID:        id,
+		AccountID: accountID,
+		TradeID:   "a98cd0f5-d6b2-4899-a1fb-ddf308d6f5c8",
+		Action:    mdBank.Action_INCREASE,
+		Amount:    money.New(100, "USD"),
+	}
+}
+
+// drain returns updates received until the channel is closed or empty
+func drain(sub *Subscription) (updates []*mdBank.Update, closed bool) {
+	for {
+		select {
+		case u, ok := <-sub.Updates():
+			if !ok {
+				return updates, true
+			}
+			updates = append(updates, u)
+		default:
+			return updates, false
+		}
+	}
+}
+
+func TestPublish(t *testing.T) {
+	b := NewBroker(4)
+	sub1 := b.Subscribe(mockAccountID1)
+	sub2 := b.Subscribe(mockAccountID1)
+	other := b.Subscribe(mockAccountID2)
+
+	u1, u2 := mockUpdate(1, mockAccountID1), mockUpdate(2, mockAccountID2)
+	b.Publish(u1, u2)
+
+	for _, sub := range []*Subscription{sub1, sub2} {
+		updates, closed := drain(sub)
+		require.Equal(t, []*mdBank.Update{u1}, updates)
+		require.False(t, closed)
+	}
+	updates, _ := drain(other)
+	require.Equal(t, []*mdBank.Update{u2}, updates)
+
+	// closed subscriptions receive nothing
+	sub1.Close()
+	sub1.Close()
+	b.Publish(mockUpdate(3, mockAccountID1))
+	updates, closed := drain(sub1)
+	require.Empty(t, updates)
+	require.True(t, closed)
+	require.False(t, sub1.Lagged())
+
+	updates, _ = drain(sub2)
+	require.Len(t, updates, 1)
+}
+
+func TestSlowSubscriber(t *testing.T) {
+	b := NewBroker(2)
+	slow := b.Subscribe(mockAccountID1)
+
+	// publishing never blocks, the subscriber is dropped once its buffer is full
+	for id := int64(1); id <= 3; id++ {
+		b.Publish(mockUpdate(id, mockAccountID1))
+	}
+
+	updates, closed := drain(slow)
+	require.Len(t, updates, 2)
+	require.True(t, closed)
+	require.True(t, slow.Lagged())
+
+	// a new subscription starts over
+	sub := b.Subscribe(mockAccountID1)
+	b.Publish(mockUpdate(4, mockAccountID1))
+	updates, _ = drain(sub)
+	require.Equal(t, int64(4), updates[0].ID)
+}
+
+func TestClose(t *testing.T) {
+	b := NewBroker(2)
+	sub := b.Subscribe(mockAccountID1)
+
+	b.Close()
+	_, closed := drain(sub)
+	require.True(t, closed)
+	require.False(t, sub.Lagged())
+
+	_, closed = drain(b.Subscribe(mockAccountID1))
+	require.True(t, closed)
+}
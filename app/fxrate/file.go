@@ -0,0 +1,39 @@
+package fxrate
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+)
+
+// File prices currencies with rates in a JSON file of pairs to decimal strings, e.g. {"USD/EUR": "0.92"}.
+// The file is read on every quote, so rates are updated by rewriting it
+type File struct {
+	path string
+}
+
+// NewFile ...
+func NewFile(path string) *File {
+	return &File{
+		path: path,
+	}
+}
+
+func (p *File) Rate(ctx context.Context, from, to string) (*big.Rat, error) {
+	b, err := ioutil.ReadFile(p.path)
+	if err != nil {
+		return nil, err
+	}
+
+	raw := map[string]string{}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return nil, err
+	}
+
+	rates, err := ParseRates(raw)
+	if err != nil {
+		return nil, err
+	}
+	return lookup(rates, from, to)
+}
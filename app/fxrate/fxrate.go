@@ -0,0 +1,54 @@
+package fxrate
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+)
+
+var (
+	// ErrRateNotFound means the provider does not price the currency pair
+	ErrRateNotFound = fmt.Errorf("Rate not found")
+)
+
+// Provider prices currencies, Rate returns the mid rate of one major unit of from in to, e.g. 0.92 for USD to EUR
+type Provider interface {
+	Rate(ctx context.Context, from, to string) (*big.Rat, error)
+}
+
+// Pair is the key of a rate, e.g. USD/EUR
+func Pair(from, to string) string {
+	return from + "/" + to
+}
+
+// ParseRate parses a positive decimal string, rates are never floats so they price amounts exactly
+func ParseRate(s string) (*big.Rat, error) {
+	rate, ok := new(big.Rat).SetString(s)
+	if !ok || rate.Sign() <= 0 {
+		return nil, fmt.Errorf("invalid rate %q", s)
+	}
+	return rate, nil
+}
+
+// ParseRates parses rates keyed by pair
+func ParseRates(rates map[string]string) (map[string]*big.Rat, error) {
+	parsed := map[string]*big.Rat{}
+	for pair, s := range rates {
+		rate, err := ParseRate(s)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", pair, err)
+		}
+		parsed[pair] = rate
+	}
+	return parsed, nil
+}
+
+// lookup finds the rate of the pair, or inverts the rate of the reverse pair
+func lookup(rates map[string]*big.Rat, from, to string) (*big.Rat, error) {
+	if rate, ok := rates[Pair(from, to)]; ok {
+		return new(big.Rat).Set(rate), nil
+	} else if rate, ok := rates[Pair(to, from)]; ok {
+		return new(big.Rat).Inv(rate), nil
+	}
+	return nil, ErrRateNotFound
+}
@@ -0,0 +1,103 @@
+package fxrate
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	mockCtx = context.Background()
+)
+
+func TestParseRates(t *testing.T) {
+	rates, err := ParseRates(map[string]string{"USD/EUR": "0.92", "USD/JPY": "151.25"})
+	require.NoError(t, err)
+	require.Equal(t, big.NewRat(23, 25), rates["USD/EUR"])
+	require.Equal(t, big.NewRat(605, 4), rates["USD/JPY"])
+
+	for _, s := range []string{"", "abc", "0", "-1.5"} {
+		_, err := ParseRates(map[string]string{"USD/EUR": s})
+		require.Error(t, err, s)
+	}
+}
+
+func TestStatic(t *testing.T) {
+	p := NewStatic(map[string]*big.Rat{"USD/EUR": big.NewRat(4, 5)})
+
+	rate, err := p.Rate(mockCtx, "USD", "EUR")
+	require.NoError(t, err)
+	require.Equal(t, big.NewRat(4, 5), rate)
+
+	// the reverse pair is the inverse
+	rate, err = p.Rate(mockCtx, "EUR", "USD")
+	require.NoError(t, err)
+	require.Equal(t, big.NewRat(5, 4), rate)
+
+	_, err = p.Rate(mockCtx, "USD", "JPY")
+	require.Equal(t, ErrRateNotFound, err)
+}
+
+func TestFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fxrate")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "rates.json")
+	write := func(rates map[string]string) {
+		b, err := json.Marshal(rates)
+		require.NoError(t, err)
+		require.NoError(t, ioutil.WriteFile(path, b, 0644))
+	}
+
+	p := NewFile(path)
+	_, err = p.Rate(mockCtx, "USD", "EUR")
+	require.Error(t, err)
+
+	write(map[string]string{"USD/EUR": "0.92"})
+	rate, err := p.Rate(mockCtx, "USD", "EUR")
+	require.NoError(t, err)
+	require.Equal(t, big.NewRat(23, 25), rate)
+
+	// rewritten rates apply to the next quote
+	write(map[string]string{"USD/EUR": "0.9"})
+	rate, err = p.Rate(mockCtx, "USD", "EUR")
+	require.NoError(t, err)
+	require.Equal(t, big.NewRat(9, 10), rate)
+
+	_, err = p.Rate(mockCtx, "USD", "GBP")
+	require.Equal(t, ErrRateNotFound, err)
+}
+
+func TestHTTP(t *testing.T) {
+	status := http.StatusOK
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("from") != "USD" || r.URL.Query().Get("to") != "EUR" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(status)
+		require.NoError(t, json.NewEncoder(w).Encode(rateResp{Rate: "0.92"}))
+	}))
+	defer srv.Close()
+
+	p := NewHTTP(srv.URL, srv.Client())
+	rate, err := p.Rate(mockCtx, "USD", "EUR")
+	require.NoError(t, err)
+	require.Equal(t, big.NewRat(23, 25), rate)
+
+	_, err = p.Rate(mockCtx, "USD", "JPY")
+	require.Equal(t, ErrRateNotFound, err)
+
+	status = http.StatusServiceUnavailable
+	_, err = p.Rate(mockCtx, "USD", "EUR")
+	require.Error(t, err)
+}
@@ -0,0 +1,59 @@
+package fxrate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+)
+
+// HTTP prices currencies with a rate service, GET url?from=USD&to=EUR responds {"rate": "0.92"} and 404 for unknown pairs
+type HTTP struct {
+	url    string
+	client *http.Client
+}
+
+// NewHTTP ...
+func NewHTTP(url string, client *http.Client) *HTTP {
+	return &HTTP{
+		url:    url,
+		client: client,
+	}
+}
+
+type rateResp struct {
+	Rate string `json:"rate"`
+}
+
+func (p *HTTP) Rate(ctx context.Context, from, to string) (*big.Rat, error) {
+	query := url.Values{}
+	query.Set("from", from)
+	query.Set("to", to)
+
+	req, err := http.NewRequest(http.MethodGet, p.url+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrRateNotFound
+	} else if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("rate %s: unexpected status %d", Pair(from, to), resp.StatusCode)
+	}
+
+	r := rateResp{}
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return nil, err
+	}
+	return ParseRate(r.Rate)
+}
@@ -0,0 +1,22 @@
+package fxrate
+
+import (
+	"context"
+	"math/big"
+)
+
+// Static prices currencies with rates set in config, they change on restart
+type Static struct {
+	rates map[string]*big.Rat
+}
+
+// NewStatic ...
+func NewStatic(rates map[string]*big.Rat) *Static {
+	return &Static{
+		rates: rates,
+	}
+}
+
+func (p *Static) Rate(ctx context.Context, from, to string) (*big.Rat, error) {
+	return lookup(p.rates, from, to)
+}
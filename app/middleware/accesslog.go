@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// AccessLog logs each request as structured fields, requests of quietPaths (e.g. probes) are logged at debug level.
+// Only the route template is logged, paths and query strings may carry IDs, personal data and tokens
+func AccessLog(quietPaths ...string) gin.HandlerFunc {
+	quiet := map[string]bool{}
+	for _, p := range quietPaths {
+		quiet[p] = true
+	}
+
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		fields := logrus.Fields{
+			"requestID": c.GetString("requestID"),
+			"method":    c.Request.Method,
+			"route":     c.FullPath(),
+			"status":    c.Writer.Status(),
+			"latencyMs": time.Since(start).Milliseconds(),
+			"bytes":     c.Writer.Size(),
+			"clientIP":  c.ClientIP(),
+			"userAgent": c.Request.UserAgent(),
+		}
+		if accountID := c.GetString("accountID"); accountID != "" {
+			fields["accountID"] = accountID
+		}
+		if len(c.Errors) > 0 {
+			fields["errors"] = c.Errors.String()
+		}
+
+		level := logrus.InfoLevel
+		if quiet[c.Request.URL.Path] {
+			level = logrus.DebugLevel
+		}
+		logrus.WithFields(fields).Log(level, "access")
+	}
+}
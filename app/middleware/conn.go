@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type connKey struct{}
+
+// ConnContext is set as http.Server.ConnContext, so streaming handlers can reach their connection
+func ConnContext(ctx context.Context, c net.Conn) context.Context {
+	return context.WithValue(ctx, connKey{}, c)
+}
+
+// ExtendDeadline moves the read and write deadlines of the request's connection, which http.Server sets from
+// ReadTimeout and WriteTimeout when the request starts. Streaming responses call it before each write,
+// it is a no-op if the server does not set ConnContext
+func ExtendDeadline(c *gin.Context, d time.Duration) error {
+	if conn, ok := c.Request.Context().Value(connKey{}).(net.Conn); ok {
+		return conn.SetDeadline(time.Now().Add(d))
+	}
+	return nil
+}
@@ -5,7 +5,14 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	mAdmin "github.com/n3k0fi5t/wallet/app/models/admin"
+	mAudit "github.com/n3k0fi5t/wallet/app/models/audit"
+	mBank "github.com/n3k0fi5t/wallet/app/models/bank"
+	"github.com/n3k0fi5t/wallet/app/repository/audit"
+	"github.com/n3k0fi5t/wallet/app/util"
+	"github.com/n3k0fi5t/wallet/common/logger"
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
 )
 
 var (
@@ -21,31 +28,168 @@ var (
 		"gura":   "084e135f-78c7-406e-a347-94e38fa55b60",
 		"Ame":    "8a180d2b-0965-4095-ba17-a880d196f04d",
 	}
+
+	name2Operator = map[string]*mAdmin.Operator{
+		"Mori":   {OperatorID: "Mori", Role: mAdmin.Role_SUPPORT},
+		"Kronii": {OperatorID: "Kronii", Role: mAdmin.Role_OPERATOR},
+		"Fauna":  {OperatorID: "Fauna", Role: mAdmin.Role_FINANCE},
+		"Bae":    {OperatorID: "Bae", Role: mAdmin.Role_AUDITOR},
+	}
 )
 
-const ()
+const (
+	headerRequestID    = "X-Request-ID"
+	maxRequestIDLength = 128
+)
 
-// simulate auth middleware to get user's information
+// LookupUser returns the user of the token, it is shared by REST and gRPC auth
+func LookupUser(token string) (string, bool) {
+	userID, ok := name2ID[token]
+	return userID, ok
+}
+
+// simulate auth middleware to get user's information, handlers act on the default wallet of the user
 func GetUserAccount() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		token := c.Request.Header.Get("Authorization")
-		accountID, ok := name2ID[token]
+		userID, ok := LookupUser(token)
 		if !ok {
-			logrus.WithField("token", token).Warn("token not found")
+			logger.FromContext(handleContext(c)).Warn("token not found")
 			c.JSON(http.StatusUnauthorized, map[string]string{
 				"errMessage": "token not found",
 			})
 			c.Abort()
+			return
+		}
+
+		// the principal names the user, tokens are credentials and never reach the audit trail
+		if md := mAudit.MetadataFrom(handleContext(c)); md != nil {
+			md.ActorType = mAudit.ActorType_USER
+			md.ActorID = userID
+			md.Principal = "user:" + userID
 		}
 
+		accountID := mBank.DefaultWalletID(userID)
+		setLoggerField(c, "userID", userID)
+		setLoggerField(c, "accountID", accountID)
+		c.Set("userID", userID)
 		c.Set("accountID", accountID)
 		c.Next()
 	}
 }
 
+// simulate staff auth middleware to get operator's information
+func GetAdminOperator() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := c.Request.Header.Get("Authorization")
+		operator, ok := name2Operator[token]
+		if !ok {
+			logger.FromContext(handleContext(c)).Warn("operator token not found")
+			c.JSON(http.StatusUnauthorized, map[string]string{
+				"errMessage": "token not found",
+			})
+			c.Abort()
+			return
+		}
+
+		if md := mAudit.MetadataFrom(handleContext(c)); md != nil {
+			md.ActorType = mAudit.ActorType_STAFF
+			md.ActorID = operator.OperatorID
+			md.Principal = "staff:" + operator.OperatorID + "/" + string(operator.Role)
+		}
+
+		setLoggerField(c, "operatorID", operator.OperatorID)
+		c.Set("operator", operator)
+		c.Next()
+	}
+}
+
+// RequestID honors X-Request-ID of the request or generates one, and echoes it in the response
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestIDOf(c)
+		c.Next()
+	}
+}
+
+// requestIDOf returns the request ID set by RequestID, it sets one if RequestID is not used
+func requestIDOf(c *gin.Context) string {
+	if requestID := c.GetString("requestID"); requestID != "" {
+		return requestID
+	}
+
+	requestID := c.Request.Header.Get(headerRequestID)
+	if !ValidRequestID(requestID) {
+		// fallback to empty request ID if uuid fails, it should not block the request
+		requestID, _ = util.GetUUIDv4()
+	}
+	c.Header(headerRequestID, requestID)
+	c.Set("requestID", requestID)
+	return requestID
+}
+
+// ValidRequestID rejects IDs which could forge log lines or blow up logs, it is shared by REST and gRPC
+func ValidRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range requestID {
+		if r < 0x21 || r > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
 func SetHandleContext() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		c.Set("ctx", context.Background())
+		requestID := requestIDOf(c)
+
+		md := &mAudit.Metadata{
+			ActorType: mAudit.ActorType_SYSTEM,
+			RequestID: requestID,
+			ClientIP:  c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+		}
+		// keep the span of the request but not its cancellation, a trade should not stop halfway when the client leaves
+		ctx := trace.ContextWithSpan(context.Background(), trace.SpanFromContext(c.Request.Context()))
+		ctx = logger.WithFields(ctx, logrus.Fields{"requestID": requestID})
+		c.Set("ctx", mAudit.WithMetadata(ctx, md))
 		c.Next()
 	}
 }
+
+// setLoggerField adds the field to the logger of the handle context set by SetHandleContext
+func setLoggerField(c *gin.Context, key string, value interface{}) {
+	ctx := handleContext(c)
+	if entry, ok := logger.Lookup(ctx); ok {
+		c.Set("ctx", logger.WithLogger(ctx, entry.WithField(key, value)))
+	}
+}
+
+// AuditAuthFailure records rejected requests (401 and 403) into the audit trail
+func AuditAuthFailure(a audit.Audit) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		status := c.Writer.Status()
+		if status != http.StatusUnauthorized && status != http.StatusForbidden {
+			return
+		}
+
+		resource := c.Request.Method + " " + c.FullPath()
+		after := map[string]int{"status": status}
+		if err := a.Record(handleContext(c), mAudit.Action_AUTH_FAILURE, resource, nil, after); err != nil {
+			logger.FromContext(handleContext(c)).WithField("err", err).Error("audit.Record failed in AuditAuthFailure")
+		}
+	}
+}
+
+func handleContext(c *gin.Context) context.Context {
+	if ctx, ok := c.Get("ctx"); ok {
+		if ctx, ok := ctx.(context.Context); ok {
+			return ctx
+		}
+	}
+	return context.Background()
+}
@@ -0,0 +1,120 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/n3k0fi5t/wallet/app/ratelimit"
+	"github.com/n3k0fi5t/wallet/common/logger"
+)
+
+const (
+	headerRateLimitLimit     = "X-RateLimit-Limit"
+	headerRateLimitRemaining = "X-RateLimit-Remaining"
+	headerRateLimitReset     = "X-RateLimit-Reset"
+	headerRetryAfter         = "Retry-After"
+
+	errCodeRateLimited = "RATE_LIMITED"
+)
+
+var (
+	errRateLimited = fmt.Errorf("Rate limit exceeded")
+)
+
+type rateLimitBucket struct {
+	key   string
+	limit ratelimit.Limit
+}
+
+// rateLimitBuckets returns the buckets of the request, userID is empty if the user token is not valid
+func rateLimitBuckets(userID, clientIP string, read bool, p ratelimit.Policy) []rateLimitBucket {
+	op, accountLimit, ipLimit := "write", p.AccountWrite, p.IPWrite
+	if read {
+		op, accountLimit, ipLimit = "read", p.AccountRead, p.IPRead
+	}
+
+	buckets := []rateLimitBucket{}
+	// wallets of a user share the limit of the user
+	if userID != "" && accountLimit.Enabled() {
+		buckets = append(buckets, rateLimitBucket{key: "account:" + userID + ":" + op, limit: accountLimit})
+	}
+	if ipLimit.Enabled() {
+		buckets = append(buckets, rateLimitBucket{key: "ip:" + clientIP + ":" + op, limit: ipLimit})
+	}
+	return buckets
+}
+
+// TakeRateLimit takes a token from the buckets of the account and the client IP of a request, REST and gRPC requests
+// share them. It returns the result of the bucket closest to its limit, the rejecting one if any, or nil if no bucket
+// applies or the limiter errors
+func TakeRateLimit(ctx context.Context, l ratelimit.Limiter, p ratelimit.Policy, userID, clientIP string, read bool) *ratelimit.Result {
+	var tightest *ratelimit.Result
+	taken := []rateLimitBucket{}
+	for _, b := range rateLimitBuckets(userID, clientIP, read, p) {
+		res, err := l.Allow(ctx, b.key, b.limit)
+		if err != nil {
+			logger.FromContext(ctx).WithField("err", err).Error("limiter.Allow failed in TakeRateLimit")
+			continue
+		}
+
+		if tightest == nil || !res.Allowed || (tightest.Allowed && res.Remaining < tightest.Remaining) {
+			tightest = res
+		}
+		if !res.Allowed {
+			break
+		}
+		taken = append(taken, b)
+	}
+
+	// a rejected request costs nothing, tokens taken from the other buckets are given back
+	if tightest != nil && !tightest.Allowed {
+		for _, b := range taken {
+			if err := l.Refund(ctx, b.key, b.limit); err != nil {
+				logger.FromContext(ctx).WithField("err", err).Error("limiter.Refund failed in TakeRateLimit")
+			}
+		}
+	}
+	return tightest
+}
+
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
+
+// RateLimit limits requests by the account of the user token and by client IP with token buckets.
+// Headers describe the bucket closest to its limit, and the request is rejected with 429 if any bucket is empty.
+// It fails open if the limiter errors, a broken backend should not take the API down
+func RateLimit(l ratelimit.Limiter, p ratelimit.Policy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, _ := LookupUser(c.Request.Header.Get("Authorization"))
+		read := false
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			read = true
+		}
+
+		tightest := TakeRateLimit(handleContext(c), l, p, userID, c.ClientIP(), read)
+		if tightest == nil {
+			c.Next()
+			return
+		}
+
+		c.Header(headerRateLimitLimit, strconv.Itoa(tightest.Limit))
+		c.Header(headerRateLimitRemaining, strconv.Itoa(tightest.Remaining))
+		c.Header(headerRateLimitReset, ceilSeconds(tightest.ResetAfter))
+		if !tightest.Allowed {
+			c.Header(headerRetryAfter, ceilSeconds(tightest.RetryAfter))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, map[string]string{
+				"errCode":    errCodeRateLimited,
+				"errMessage": errRateLimited.Error(),
+			})
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,116 @@
+package admin
+
+import "github.com/n3k0fi5t/wallet/app/money"
+
+type Role string
+
+const (
+	// Role_SUPPORT can only look up accounts and their transactions
+	Role_SUPPORT Role = "support"
+	// Role_OPERATOR can additionally freeze accounts, trigger refunds and resolve escrow disputes
+	Role_OPERATOR Role = "operator"
+	// Role_FINANCE can additionally adjust balances, grant credit lines, trigger refunds and resolve escrow disputes
+	Role_FINANCE Role = "finance"
+	// Role_AUDITOR can only query the audit trail
+	Role_AUDITOR Role = "auditor"
+)
+
+type Permission int32
+
+const (
+	Permission_UNKNOWN_PERMISSION Permission = 0
+	Permission_VIEW_ACCOUNT       Permission = 1
+	Permission_ADJUST_BALANCE     Permission = 2
+	Permission_FREEZE_ACCOUNT     Permission = 3
+	Permission_REFUND             Permission = 4
+	Permission_VIEW_AUDIT         Permission = 5
+	Permission_RESOLVE_DISPUTE    Permission = 6
+	Permission_GRANT_CREDIT       Permission = 7
+)
+
+var (
+	rolePermissions = map[Role][]Permission{
+		Role_SUPPORT: {
+			Permission_VIEW_ACCOUNT,
+		},
+		Role_OPERATOR: {
+			Permission_VIEW_ACCOUNT,
+			Permission_FREEZE_ACCOUNT,
+			Permission_REFUND,
+			Permission_RESOLVE_DISPUTE,
+		},
+		Role_FINANCE: {
+			Permission_VIEW_ACCOUNT,
+			Permission_ADJUST_BALANCE,
+			Permission_GRANT_CREDIT,
+			Permission_REFUND,
+			Permission_RESOLVE_DISPUTE,
+		},
+		Role_AUDITOR: {
+			Permission_VIEW_AUDIT,
+		},
+	}
+)
+
+// Can reports whether the role is granted the permission
+func (r Role) Can(p Permission) bool {
+	for _, perm := range rolePermissions[r] {
+		if perm == p {
+			return true
+		}
+	}
+	return false
+}
+
+type ReasonCode string
+
+const (
+	ReasonCode_CORRECTION ReasonCode = "CORRECTION"
+	ReasonCode_GOODWILL   ReasonCode = "GOODWILL"
+	ReasonCode_CHARGEBACK ReasonCode = "CHARGEBACK"
+	ReasonCode_FRAUD      ReasonCode = "FRAUD"
+	ReasonCode_COMPLIANCE ReasonCode = "COMPLIANCE"
+)
+
+func (r ReasonCode) IsValid() bool {
+	switch r {
+	case ReasonCode_CORRECTION, ReasonCode_GOODWILL, ReasonCode_CHARGEBACK, ReasonCode_FRAUD, ReasonCode_COMPLIANCE:
+		return true
+	}
+	return false
+}
+
+type ActionType string
+
+const (
+	ActionType_ADJUST_BALANCE ActionType = "ADJUST_BALANCE"
+	ActionType_FREEZE         ActionType = "FREEZE"
+	ActionType_UNFREEZE       ActionType = "UNFREEZE"
+	ActionType_REFUND         ActionType = "REFUND"
+	ActionType_RESOLVE_ESCROW ActionType = "RESOLVE_ESCROW"
+	// ActionType_CREDIT_LIMIT grants, changes or revokes (limit 0) a credit line, Amount is the new limit
+	ActionType_CREDIT_LIMIT ActionType = "CREDIT_LIMIT"
+	// ActionType_VIEW_ACCOUNT and ActionType_VIEW_TRANSACTIONS are reads of customer data, they move no money
+	ActionType_VIEW_ACCOUNT      ActionType = "VIEW_ACCOUNT"
+	ActionType_VIEW_TRANSACTIONS ActionType = "VIEW_TRANSACTIONS"
+)
+
+// Operator is the authenticated staff member calling admin APIs
+type Operator struct {
+	OperatorID string
+	Role       Role
+}
+
+// Action is the record of one admin action
+type Action struct {
+	ID          int          `db:"id" json:"-"`
+	OperatorID  string       `db:"operatorID" json:"operatorID"`
+	Role        Role         `db:"role" json:"role"`
+	Action      ActionType   `db:"action" json:"action"`
+	AccountID   string       `db:"accountID" json:"accountID"`
+	TradeID     string       `db:"tradeID" json:"tradeID,omitempty"`
+	Amount      *money.Money `db:"amount" json:"amount,omitempty"`
+	ReasonCode  ReasonCode   `db:"reasonCode" json:"reasonCode"`
+	Note        string       `db:"note" json:"note,omitempty"`
+	TimestampMs int64        `db:"timestampMs" json:"timestampMs"`
+}
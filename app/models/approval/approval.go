@@ -0,0 +1,111 @@
+package approval
+
+import (
+	"strings"
+
+	"github.com/n3k0fi5t/wallet/app/money"
+)
+
+// Policy requires Required of the approvers to approve withdrawals and transfers over Threshold from the wallet,
+// Threshold is in the currency of the wallet
+type Policy struct {
+	ID        int         `db:"id"`
+	WalletID  string      `db:"walletID"`
+	Threshold money.Money `db:"threshold"`
+	Required  int         `db:"required"`
+	// Approvers is comma separated user IDs
+	Approvers string `db:"approvers"`
+	UpdatedBy string `db:"updatedBy"`
+	CreatedMs int64  `db:"createdMs"`
+	UpdatedMs int64  `db:"updatedMs"`
+}
+
+// Requires tells whether paying amount out of the wallet needs approvals
+func (p *Policy) Requires(amount money.Money) bool {
+	return amount.Amount > p.Threshold.Amount
+}
+
+// ApproverIDs splits Approvers
+func ApproverIDs(approvers string) []string {
+	if approvers == "" {
+		return []string{}
+	}
+	return strings.Split(approvers, ",")
+}
+
+type Status string
+
+const (
+	// Status_PENDING means the money is held until enough approvers approve
+	Status_PENDING Status = "PENDING"
+	// Status_EXECUTED means the withdrawal or transfer is done
+	Status_EXECUTED Status = "EXECUTED"
+	// Status_REJECTED means an approver or the requester rejected it, the money went back to the wallet
+	Status_REJECTED Status = "REJECTED"
+	// Status_EXPIRED means it was not approved in time, the money went back to the wallet
+	Status_EXPIRED Status = "EXPIRED"
+)
+
+func (s Status) IsValid() bool {
+	switch s {
+	case Status_PENDING, Status_EXECUTED, Status_REJECTED, Status_EXPIRED:
+		return true
+	}
+	return false
+}
+
+// Intent is a withdrawal or transfer waiting for approvals, the money is held in a system owned account
+type Intent struct {
+	ID          int    `db:"id"`
+	IntentID    string `db:"intentID"`
+	WalletID    string `db:"walletID"`
+	RequestedBy string `db:"requestedBy"`
+	// ToAccountID is the payee of transfers, empty for withdrawals
+	ToAccountID string      `db:"toAccountID"`
+	Amount      money.Money `db:"amount"`
+	// HoldAccountID is the account holding the money
+	HoldAccountID string `db:"holdAccountID"`
+	Status        Status `db:"status"`
+	// Required and Approvers are copied from the policy when requested
+	Required      int    `db:"required"`
+	Approvers     string `db:"approvers"`
+	HoldTradeID   string `db:"holdTradeID"`
+	SettleTradeID string `db:"settleTradeID"`
+	// IdempotencyKey is the key of the request held, scoped to the wallet. Retries with it get this intent back
+	IdempotencyKey string `db:"idempotencyKey"`
+	ExpiresAtMs    int64  `db:"expiresAtMs"`
+	CreatedMs      int64  `db:"createdMs"`
+	UpdatedMs      int64  `db:"updatedMs"`
+}
+
+// IsWithdrawal tells whether the intent pays out of the bank instead of to a payee
+func (i *Intent) IsWithdrawal() bool {
+	return i.ToAccountID == ""
+}
+
+// IsApprover tells whether the user is designated to approve the intent
+func (i *Intent) IsApprover(userID string) bool {
+	for _, id := range ApproverIDs(i.Approvers) {
+		if id == userID {
+			return true
+		}
+	}
+	return false
+}
+
+type Decision string
+
+const (
+	Decision_APPROVE Decision = "APPROVE"
+	Decision_REJECT  Decision = "REJECT"
+)
+
+// Approval is one decision on an intent, they are the history of the intent
+type Approval struct {
+	ID         int      `db:"id"`
+	IntentID   string   `db:"intentID"`
+	ApproverID string   `db:"approverID"`
+	Decision   Decision `db:"decision"`
+	Comment    string   `db:"comment"`
+	CreatedMs  int64    `db:"createdMs"`
+}
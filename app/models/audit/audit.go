@@ -0,0 +1,74 @@
+package audit
+
+import (
+	"context"
+)
+
+type ActorType string
+
+const (
+	ActorType_SYSTEM ActorType = "system"
+	ActorType_USER   ActorType = "user"
+	ActorType_STAFF  ActorType = "staff"
+)
+
+type Action string
+
+const (
+	Action_TRADE          Action = "TRADE"
+	Action_ACCOUNT_STATUS Action = "ACCOUNT_STATUS"
+	Action_CREDIT_LIMIT   Action = "CREDIT_LIMIT"
+	Action_ADMIN_ACTION   Action = "ADMIN_ACTION"
+	Action_AUTH_FAILURE   Action = "AUTH_FAILURE"
+	Action_CONFIG_CHANGE  Action = "CONFIG_CHANGE"
+)
+
+// Metadata describes who does the change and from where
+type Metadata struct {
+	ActorType ActorType
+	ActorID   string
+	// Principal is the authenticated identity, e.g. token subject and role
+	Principal string
+	RequestID string
+	ClientIP  string
+	UserAgent string
+}
+
+// Entry is one append-only audit record
+type Entry struct {
+	ID          int       `db:"id"`
+	ActorType   ActorType `db:"actorType"`
+	ActorID     string    `db:"actorID"`
+	Principal   string    `db:"principal"`
+	RequestID   string    `db:"requestID"`
+	ClientIP    string    `db:"clientIP"`
+	UserAgent   string    `db:"userAgent"`
+	Action      Action    `db:"action"`
+	Resource    string    `db:"resource"`
+	Before      string    `db:"before"`
+	After       string    `db:"after"`
+	TimestampMs int64     `db:"timestampMs"`
+}
+
+// Filter narrows down audit entries, zero value fields are ignored
+type Filter struct {
+	ActorID  string
+	Action   Action
+	Resource string
+	FromMs   int64
+	ToMs     int64
+}
+
+type metadataKey struct{}
+
+// WithMetadata returns a context carrying the metadata, the metadata is shared by the whole request
+// so that auth middlewares can fill the actor after the request is accepted
+func WithMetadata(ctx context.Context, md *Metadata) context.Context {
+	return context.WithValue(ctx, metadataKey{}, md)
+}
+
+// MetadataFrom returns the metadata carried by context, or nil if absent
+func MetadataFrom(ctx context.Context) *Metadata {
+	md, _ := ctx.Value(metadataKey{}).(*Metadata)
+	return md
+}
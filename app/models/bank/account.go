@@ -1,7 +1,79 @@
 package bank
 
+import (
+	"math"
+
+	"github.com/n3k0fi5t/wallet/app/money"
+)
+
+type AccountStatus int32
+
+const (
+	AccountStatus_ACTIVE AccountStatus = 0
+	AccountStatus_FROZEN AccountStatus = 1
+)
+
+// AccountType tells who owns the account
+type AccountType int32
+
+const (
+	// AccountType_USER is the wallet of a user
+	AccountType_USER AccountType = 0
+	// AccountType_ESCROW is owned by the system and parks the money of one escrow
+	AccountType_ESCROW AccountType = 1
+	// AccountType_HOLD is owned by the system and holds the money of a transfer waiting for approvals
+	AccountType_HOLD AccountType = 2
+	// AccountType_FX is owned by the system and is the liquidity of one currency the exchange buys and sells
+	AccountType_FX AccountType = 3
+	// AccountType_SYSTEM is in the chart of system accounts, it books money entering, leaving or lost by the bank
+	AccountType_SYSTEM AccountType = 4
+)
+
+// Account is a wallet when it is owned by a user, system accounts have no owner
 type Account struct {
-	ID        int    `db:"id"`
-	AccountID string `db:"accountID"`
-	Balance   int64  `db:"balance"`
+	ID        int           `db:"id"`
+	AccountID string        `db:"accountID"`
+	Status    AccountStatus `db:"status"`
+	Type      AccountType   `db:"type"`
+	OwnerID   string        `db:"ownerID"`
+	Name      string        `db:"name"`
+	// Balance is in the currency of the account, which never changes
+	Balance money.Money `db:"balance"`
+	// CreditLimit is how far the balance may go below zero, 0 for accounts without a credit line
+	CreditLimit money.Money `db:"creditLimit"`
+	// AllowNegative is the policy of system accounts whose balance may go below zero without limit, e.g. funding
+	AllowNegative bool `db:"allowNegative"`
+}
+
+// DefaultWalletID returns the wallet opened with the user, payments addressed to the user land in it
+func DefaultWalletID(userID string) string {
+	return userID
+}
+
+// IsDefault tells whether the account is the default wallet of its owner
+func (a *Account) IsDefault() bool {
+	return a.OwnerID != "" && a.AccountID == DefaultWalletID(a.OwnerID)
+}
+
+// Currency is the ISO 4217 code of the account
+func (a *Account) Currency() string {
+	return a.Balance.Currency
+}
+
+// Available is the most the account can pay out, the balance plus the credit line. Held money is not in it,
+// holds move money to system accounts so the balance is net of them
+func (a *Account) Available() money.Money {
+	if a.AllowNegative {
+		return money.New(math.MaxInt64, a.Currency())
+	}
+	available, err := money.AddInt64(a.Balance.Amount, a.CreditLimit.Amount)
+	if err != nil {
+		return money.New(math.MaxInt64, a.Currency())
+	}
+	return money.New(available, a.Currency())
+}
+
+// Overdrawn tells whether the account is using its credit line, accounts allowed to go negative never are
+func (a *Account) Overdrawn() bool {
+	return a.Balance.IsNegative() && !a.AllowNegative
 }
@@ -0,0 +1,32 @@
+package bank
+
+// FXAccountPrefix prefixes the FX account of each currency, e.g. fx-USD
+const FXAccountPrefix = "fx-"
+
+// FXAccountID returns the FX account of the currency
+func FXAccountID(currency string) string {
+	return FXAccountPrefix + currency
+}
+
+// Exchange converts money between currencies, both legs are booked as one trade through the FX accounts
+type Exchange struct {
+	QuoteID string
+	// Sell pays the sold currency into its FX account, Buy pays the bought currency out of its FX account
+	Sell *Dealing
+	Buy  *Dealing
+	// Rate is the mid rate, the price of one major unit of the sold currency in the bought one
+	Rate string
+	// SpreadBps is the spread charged on the mid rate in basis points
+	SpreadBps int64
+}
+
+func (e *Exchange) IsValid() bool {
+	if e == nil || !e.Sell.IsValid() || !e.Buy.IsValid() {
+		return false
+	} else if e.Sell.ToAccountID != FXAccountID(e.Sell.Amount.Currency) || e.Buy.FromAccountID != FXAccountID(e.Buy.Amount.Currency) {
+		return false
+	} else if e.Sell.Amount.Currency == e.Buy.Amount.Currency {
+		return false
+	}
+	return true
+}
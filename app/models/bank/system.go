@@ -0,0 +1,66 @@
+package bank
+
+import "strings"
+
+// SystemAccountPrefix prefixes the accounts in the chart of system accounts, e.g. sys-funding-direct-USD
+const SystemAccountPrefix = "sys-"
+
+// Purpose is what a system account books, each purpose has one account per currency so balances stay meaningful
+type Purpose string
+
+const (
+	// Purpose_FUNDING is the money outside the bank on one rail, deposits are paid out of it and withdrawals into it,
+	// so its balance is minus the money the rail brought in
+	Purpose_FUNDING Purpose = "funding"
+	// Purpose_CLEARING is the money in flight on one rail, deposits accepted but not settled and withdrawals
+	// taken from wallets but not settled, so its balance never goes negative
+	Purpose_CLEARING Purpose = "clearing"
+	// Purpose_FEE is the revenue of fees charged to users
+	Purpose_FEE Purpose = "fee"
+	// Purpose_SUSPENSE books admin adjustments and returned deposits users already spent until they are reconciled
+	Purpose_SUSPENSE Purpose = "suspense"
+	// Purpose_WRITE_OFF books the losses the bank absorbs, goodwill credits included
+	Purpose_WRITE_OFF Purpose = "write-off"
+)
+
+// Rail is the way money enters and leaves the bank, each rail has a funding account per currency
+type Rail string
+
+const (
+	// Rail_DIRECT is the instant deposit and withdraw of the wallet API
+	Rail_DIRECT Rail = "direct"
+	// Rail_SIMULATOR settles deposits and withdrawals asynchronously through status callbacks, for running locally
+	Rail_SIMULATOR Rail = "simulator"
+)
+
+// SystemAccountID returns the system account of the purpose in the currency, accounts of rails are by FundingAccountID
+// and ClearingAccountID
+func SystemAccountID(purpose Purpose, currency string) string {
+	return SystemAccountPrefix + string(purpose) + "-" + currency
+}
+
+// FundingAccountID returns the funding account of the rail in the currency
+func FundingAccountID(rail Rail, currency string) string {
+	return SystemAccountID(Purpose_FUNDING, string(rail)+"-"+currency)
+}
+
+// ClearingAccountID returns the clearing account of the rail in the currency
+func ClearingAccountID(rail Rail, currency string) string {
+	return SystemAccountID(Purpose_CLEARING, string(rail)+"-"+currency)
+}
+
+// IsSystemAccount tells whether the account is in the chart of system accounts. Escrow, hold and FX accounts are
+// owned by the system too but they hold money of users or the exchange, they are not
+func IsSystemAccount(accountID string) bool {
+	return strings.HasPrefix(accountID, SystemAccountPrefix)
+}
+
+// IsFundingAccount tells whether money from or to the account enters or leaves the bank
+func IsFundingAccount(accountID string) bool {
+	return strings.HasPrefix(accountID, SystemAccountPrefix+string(Purpose_FUNDING)+"-")
+}
+
+// IsClearingAccount tells whether the account holds money in flight on a rail
+func IsClearingAccount(accountID string) bool {
+	return strings.HasPrefix(accountID, SystemAccountPrefix+string(Purpose_CLEARING)+"-")
+}
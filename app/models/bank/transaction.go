@@ -1,5 +1,11 @@
 package bank
 
+import (
+	"context"
+
+	"github.com/n3k0fi5t/wallet/app/money"
+)
+
 type Action int32
 
 const (
@@ -8,22 +14,30 @@ const (
 	Action_DECREASE       Action = 2
 )
 
-const (
-	PseudoAccount = "c1e395d9-8c00-4124-819a-85b0402900cf"
-)
-
 type Transaction struct {
-	AccountID   string `db:"accountID"`
-	Action      Action `db:"action"`
-	Amount      int64  `db:"amount"`
-	TimestampMs int64  `db:"timeMs"`
-	TradeID     string `db:"tradeID"`
+	AccountID   string      `db:"accountID"`
+	Action      Action      `db:"action"`
+	Amount      money.Money `db:"amount"`
+	TimestampMs int64       `db:"timeMs"`
+	TradeID     string      `db:"tradeID"`
+}
+
+// IsDebit reports whether the entry takes money out of the account
+func (t *Transaction) IsDebit() bool {
+	return t.Action == Action_DECREASE
 }
 
 type Dealing struct {
 	FromAccountID string
 	ToAccountID   string
-	Amount        int64
+	// Amount is in the currency of both accounts
+	Amount money.Money
+
+	// ReversalOf is the tradeID this dealing reverses, a trade can only be reversed once
+	ReversalOf string
+
+	// IdempotencyKey makes a retried dealing execute once, the same key returns the first tradeID
+	IdempotencyKey string
 }
 
 func (d *Dealing) IsValid() bool {
@@ -31,8 +45,21 @@ func (d *Dealing) IsValid() bool {
 		return false
 	} else if d.FromAccountID == d.ToAccountID {
 		return false
-	} else if d.Amount < 0 {
+	} else if d.Amount.IsNegative() || !money.IsValidCurrency(d.Amount.Currency) {
 		return false
 	}
 	return true
 }
+
+type idempotencyKey struct{}
+
+// WithIdempotencyKey returns a context carrying the client supplied idempotency key of the request
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKey{}, key)
+}
+
+// IdempotencyKeyFrom returns the idempotency key carried by context, or empty if absent
+func IdempotencyKeyFrom(ctx context.Context) string {
+	key, _ := ctx.Value(idempotencyKey{}).(string)
+	return key
+}
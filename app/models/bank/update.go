@@ -0,0 +1,29 @@
+package bank
+
+import "github.com/n3k0fi5t/wallet/app/money"
+
+// Update is a change of the account's balance by a trade, ID is the position of its entry in the trade log
+// and increases per account
+type Update struct {
+	ID             int64       `db:"id"`
+	AccountID      string      `db:"accountID"`
+	TradeID        string      `db:"tradeID"`
+	CounterpartyID string      `db:"counterpartyID"`
+	Action         Action      `db:"action"`
+	Amount         money.Money `db:"amount"`
+	BalanceAfter   money.Money `db:"-"`
+	TimestampMs    int64       `db:"timeMs"`
+}
+
+// IsDebit reports whether the update takes money out of the account
+func (u *Update) IsDebit() bool {
+	return u.Action == Action_DECREASE
+}
+
+// Delta is the signed change of the balance
+func (u *Update) Delta() (money.Money, error) {
+	if u.IsDebit() {
+		return u.Amount.Neg()
+	}
+	return u.Amount, nil
+}
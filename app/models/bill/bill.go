@@ -0,0 +1,121 @@
+package bill
+
+import "github.com/n3k0fi5t/wallet/app/money"
+
+// SplitType is how the total of a bill is divided among participants
+type SplitType string
+
+const (
+	// SplitType_EQUAL divides the total evenly
+	SplitType_EQUAL SplitType = "EQUAL"
+	// SplitType_EXACT takes the amount of each participant as given
+	SplitType_EXACT SplitType = "EXACT"
+	// SplitType_PERCENTAGE divides the total by basis points of each participant
+	SplitType_PERCENTAGE SplitType = "PERCENTAGE"
+)
+
+func (t SplitType) IsValid() bool {
+	switch t {
+	case SplitType_EQUAL, SplitType_EXACT, SplitType_PERCENTAGE:
+		return true
+	}
+	return false
+}
+
+type Status string
+
+const (
+	// Status_OPEN means some shares are not paid yet
+	Status_OPEN Status = "OPEN"
+	// Status_SETTLED means all shares are paid
+	Status_SETTLED Status = "SETTLED"
+	// Status_CANCELED means the organizer stops collecting unpaid shares
+	Status_CANCELED Status = "CANCELED"
+)
+
+func (s Status) IsValid() bool {
+	switch s {
+	case Status_OPEN, Status_SETTLED, Status_CANCELED:
+		return true
+	}
+	return false
+}
+
+type ShareStatus string
+
+const (
+	ShareStatus_PENDING  ShareStatus = "PENDING"
+	ShareStatus_PAID     ShareStatus = "PAID"
+	ShareStatus_CANCELED ShareStatus = "CANCELED"
+)
+
+// Role is the side of bills an account lists
+type Role string
+
+const (
+	Role_ORGANIZER   Role = "organizer"
+	Role_PARTICIPANT Role = "participant"
+)
+
+func (r Role) IsValid() bool {
+	return r == Role_ORGANIZER || r == Role_PARTICIPANT
+}
+
+// Participant is an account asked to pay part of a bill, Amount is for exact split and
+// BasisPoints (1/100 of a percent) is for percentage split
+type Participant struct {
+	AccountID   string
+	Amount      money.Money
+	BasisPoints int64
+}
+
+// Bill is a total the organizer paid and splits among participants
+type Bill struct {
+	ID          int         `db:"id"`
+	BillID      string      `db:"billID"`
+	OrganizerID string      `db:"organizerID"`
+	Total       money.Money `db:"total"`
+	Memo        string      `db:"memo"`
+	SplitType   SplitType   `db:"splitType"`
+	Status      Status      `db:"status"`
+	CreatedMs   int64       `db:"createdMs"`
+	UpdatedMs   int64       `db:"updatedMs"`
+
+	// Shares are in the order of participants on creation
+	Shares []*Share `db:"-"`
+}
+
+// Share is what a participant owes the organizer
+type Share struct {
+	ID            int         `db:"id"`
+	BillID        string      `db:"billID"`
+	ParticipantID string      `db:"participantID"`
+	Amount        money.Money `db:"amount"`
+	Status        ShareStatus `db:"status"`
+	// TradeID is the transfer paying the share, empty for the share of the organizer
+	TradeID    string `db:"tradeID"`
+	Reminders  int    `db:"reminders"`
+	RemindedMs int64  `db:"remindedMs"`
+	CreatedMs  int64  `db:"createdMs"`
+	UpdatedMs  int64  `db:"updatedMs"`
+}
+
+// Share returns the share of the participant, nil if the account does not take part in the bill
+func (b *Bill) Share(accountID string) *Share {
+	for _, share := range b.Shares {
+		if share.ParticipantID == accountID {
+			return share
+		}
+	}
+	return nil
+}
+
+// AllPaid tells whether no share is waiting for payment
+func (b *Bill) AllPaid() bool {
+	for _, share := range b.Shares {
+		if share.Status != ShareStatus_PAID {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,68 @@
+package escrow
+
+import "github.com/n3k0fi5t/wallet/app/money"
+
+type Status string
+
+const (
+	// Status_FUNDED means the money is parked in the escrow account
+	Status_FUNDED Status = "FUNDED"
+	// Status_DISPUTED means the escrow account is frozen until staff resolve the dispute
+	Status_DISPUTED Status = "DISPUTED"
+	// Status_RELEASED means the money went to the seller
+	Status_RELEASED Status = "RELEASED"
+	// Status_REFUNDED means the money went back to the buyer
+	Status_REFUNDED Status = "REFUNDED"
+)
+
+func (s Status) IsValid() bool {
+	switch s {
+	case Status_FUNDED, Status_DISPUTED, Status_RELEASED, Status_REFUNDED:
+		return true
+	}
+	return false
+}
+
+// Role is the side of escrows an account lists
+type Role string
+
+const (
+	Role_BUYER  Role = "buyer"
+	Role_SELLER Role = "seller"
+)
+
+func (r Role) IsValid() bool {
+	return r == Role_BUYER || r == Role_SELLER
+}
+
+// Outcome is how staff resolve a disputed escrow
+type Outcome string
+
+const (
+	Outcome_RELEASE Outcome = "RELEASE"
+	Outcome_REFUND  Outcome = "REFUND"
+)
+
+func (o Outcome) IsValid() bool {
+	return o == Outcome_RELEASE || o == Outcome_REFUND
+}
+
+// Escrow parks the money of a sale in a system owned account until the buyer confirms,
+// the seller cancels or it is released automatically at ReleaseAtMs
+type Escrow struct {
+	ID       int    `db:"id"`
+	EscrowID string `db:"escrowID"`
+	// AccountID is the escrow account holding the money
+	AccountID     string      `db:"accountID"`
+	BuyerID       string      `db:"buyerID"`
+	SellerID      string      `db:"sellerID"`
+	Amount        money.Money `db:"amount"`
+	Memo          string      `db:"memo"`
+	Status        Status      `db:"status"`
+	FundTradeID   string      `db:"fundTradeID"`
+	SettleTradeID string      `db:"settleTradeID"`
+	DisputedBy    string      `db:"disputedBy"`
+	ReleaseAtMs   int64       `db:"releaseAtMs"`
+	CreatedMs     int64       `db:"createdMs"`
+	UpdatedMs     int64       `db:"updatedMs"`
+}
@@ -0,0 +1,64 @@
+package event
+
+import "github.com/n3k0fi5t/wallet/app/money"
+
+type Type string
+
+const (
+	Type_TRADE_COMPLETED Type = "TradeCompleted"
+	Type_TRADE_FAILED    Type = "TradeFailed"
+	// Type_ACCOUNT_OVERDRAWN means a trade took the balance below zero, interest and fees accrue from then on
+	Type_ACCOUNT_OVERDRAWN Type = "AccountOverdrawn"
+	// Type_OVERDRAFT_REPAID means a trade took the balance of an overdrawn account back to zero or above
+	Type_OVERDRAFT_REPAID Type = "OverdraftRepaid"
+	// Type_BILL_REMINDER asks the event's account to pay its share of a bill
+	Type_BILL_REMINDER Type = "BillReminder"
+)
+
+type Direction string
+
+const (
+	Direction_DEBIT  Direction = "debit"
+	Direction_CREDIT Direction = "credit"
+)
+
+// Event is published to downstream consumers. Delivery is at-least-once,
+// consumers should dedupe by EventID, and events of the same account are delivered in Sequence order
+type Event struct {
+	// Sequence is the outbox position, increasing per account
+	Sequence    int64      `json:"sequence"`
+	EventID     string     `json:"eventID"`
+	Type        Type       `json:"type"`
+	AccountID   string     `json:"accountID"`
+	TimestampMs int64      `json:"timestampMs"`
+	Trade       *Trade     `json:"trade,omitempty"`
+	Overdraft   *Overdraft `json:"overdraft,omitempty"`
+	Bill        *Bill      `json:"bill,omitempty"`
+}
+
+// Trade is the trade from the point of view of the event's account
+type Trade struct {
+	TradeID        string       `json:"tradeID,omitempty"`
+	CounterpartyID string       `json:"counterpartyID"`
+	Direction      Direction    `json:"direction"`
+	Amount         money.Money  `json:"amount"`
+	BalanceAfter   *money.Money `json:"balanceAfter,omitempty"`
+	// Reason is the failure reason of TradeFailed
+	Reason string `json:"reason,omitempty"`
+}
+
+// Overdraft is the credit line usage of the account after the trade changing it
+type Overdraft struct {
+	TradeID     string      `json:"tradeID"`
+	Balance     money.Money `json:"balance"`
+	CreditLimit money.Money `json:"creditLimit"`
+}
+
+// Bill is the unpaid share of the event's account in a bill
+type Bill struct {
+	BillID      string      `json:"billID"`
+	OrganizerID string      `json:"organizerID"`
+	Amount      money.Money `json:"amount"`
+	Memo        string      `json:"memo,omitempty"`
+	Reminders   int         `json:"reminders"`
+}
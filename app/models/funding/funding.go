@@ -0,0 +1,117 @@
+package funding
+
+import (
+	mBank "github.com/n3k0fi5t/wallet/app/models/bank"
+	"github.com/n3k0fi5t/wallet/app/money"
+)
+
+// Direction is whether money enters or leaves the wallet over the rail
+type Direction string
+
+const (
+	Direction_DEPOSIT    Direction = "DEPOSIT"
+	Direction_WITHDRAWAL Direction = "WITHDRAWAL"
+)
+
+func (d Direction) IsValid() bool {
+	return d == Direction_DEPOSIT || d == Direction_WITHDRAWAL
+}
+
+type Status string
+
+const (
+	// Status_INITIATED means the transfer is recorded but not accepted by the rail yet
+	Status_INITIATED Status = "INITIATED"
+	// Status_PENDING means the rail accepted the transfer, the money is in the clearing account of the rail
+	Status_PENDING Status = "PENDING"
+	// Status_SETTLED means the rail moved the money, a deposit is credited to the wallet
+	Status_SETTLED Status = "SETTLED"
+	// Status_FAILED means the rail did not move the money, a withdrawal is refunded to the wallet
+	Status_FAILED Status = "FAILED"
+	// Status_RETURNED means the money came back after the rail accepted or settled it, e.g. the receiving bank
+	// rejected it or the payer charged it back
+	Status_RETURNED Status = "RETURNED"
+)
+
+func (s Status) IsValid() bool {
+	switch s {
+	case Status_INITIATED, Status_PENDING, Status_SETTLED, Status_FAILED, Status_RETURNED:
+		return true
+	}
+	return false
+}
+
+// CanBecome tells whether the rail may move a transfer of status s to next. Settled transfers can still be returned
+func (s Status) CanBecome(next Status) bool {
+	switch s {
+	case Status_INITIATED:
+		return next == Status_PENDING || next == Status_SETTLED || next == Status_FAILED || next == Status_RETURNED
+	case Status_PENDING:
+		return next == Status_SETTLED || next == Status_FAILED || next == Status_RETURNED
+	case Status_SETTLED:
+		return next == Status_RETURNED
+	}
+	return false
+}
+
+// Transfer moves money between the wallet and outside the bank over a rail. The money is booked through the
+// clearing account of the rail, so the clearing balance is the money in flight:
+//
+//	deposit:    funding -> clearing when pending, clearing -> wallet when settled
+//	withdrawal: wallet -> clearing when initiated, clearing -> funding when settled
+//
+// Failed and returned transfers refund the clearing account to where the money came from. A returned deposit the
+// wallet already spent is taken back as far as the wallet can pay, the shortfall comes from the suspense account
+type Transfer struct {
+	ID         int         `db:"id"`
+	TransferID string      `db:"transferID"`
+	WalletID   string      `db:"walletID"`
+	UserID     string      `db:"userID"`
+	Direction  Direction   `db:"direction"`
+	Rail       mBank.Rail  `db:"rail"`
+	Amount     money.Money `db:"amount"`
+	Status     Status      `db:"status"`
+	// Reference identifies the transfer on the rail, empty until the rail accepts it
+	Reference     string `db:"reference"`
+	FailureReason string `db:"failureReason"`
+	// ClearTradeID moves the money into the clearing account
+	ClearTradeID string `db:"clearTradeID"`
+	// SettleTradeID moves the money out of the clearing account to where it goes
+	SettleTradeID string `db:"settleTradeID"`
+	// ReturnTradeID moves the settled money back into the clearing account
+	ReturnTradeID string `db:"returnTradeID"`
+	// RefundTradeID moves the money out of the clearing account back to where it came from
+	RefundTradeID string `db:"refundTradeID"`
+	// ShortfallTradeID moves the part of a returned deposit the wallet could not pay from suspense into the clearing
+	// account, ShortfallAmount is owed by the user until it is reconciled
+	ShortfallTradeID string      `db:"shortfallTradeID"`
+	ShortfallAmount  money.Money `db:"shortfallAmount"`
+	CreatedMs        int64       `db:"createdMs"`
+	UpdatedMs        int64       `db:"updatedMs"`
+}
+
+// Currency is the currency of the wallet the transfer is in
+func (t *Transfer) Currency() string {
+	return t.Amount.Currency
+}
+
+// Source returns the account the money comes from, the funding account of the rail for deposits
+func (t *Transfer) Source() string {
+	if t.Direction == Direction_DEPOSIT {
+		return mBank.FundingAccountID(t.Rail, t.Currency())
+	}
+	return t.WalletID
+}
+
+// Destination returns the account the money goes to, the funding account of the rail for withdrawals
+func (t *Transfer) Destination() string {
+	if t.Direction == Direction_DEPOSIT {
+		return t.WalletID
+	}
+	return mBank.FundingAccountID(t.Rail, t.Currency())
+}
+
+// Clearing returns the clearing account of the rail in the currency
+func (t *Transfer) Clearing() string {
+	return mBank.ClearingAccountID(t.Rail, t.Currency())
+}
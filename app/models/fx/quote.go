@@ -0,0 +1,50 @@
+package fx
+
+import "github.com/n3k0fi5t/wallet/app/money"
+
+type Status string
+
+const (
+	// Status_OPEN means the quote can be executed until it expires
+	Status_OPEN Status = "OPEN"
+	// Status_EXECUTED means the exchange is booked
+	Status_EXECUTED Status = "EXECUTED"
+	// Status_EXPIRED is never saved, open quotes past their expiry read as expired
+	Status_EXPIRED Status = "EXPIRED"
+)
+
+// Quote prices selling SellAmount of the from wallet for BuyAmount into the to wallet, each in the currency of its wallet
+type Quote struct {
+	ID           int         `db:"id"`
+	QuoteID      string      `db:"quoteID"`
+	UserID       string      `db:"userID"`
+	FromWalletID string      `db:"fromWalletID"`
+	ToWalletID   string      `db:"toWalletID"`
+	SellAmount   money.Money `db:"sellAmount"`
+	BuyAmount    money.Money `db:"buyAmount"`
+	// Rate is the mid rate rounded to RateDecimals, the price of one major unit of the sold currency in the bought one
+	Rate string `db:"rate"`
+	// SpreadBps is charged on Rate in basis points
+	SpreadBps   int64  `db:"spreadBps"`
+	Status      Status `db:"status"`
+	TradeID     string `db:"tradeID"`
+	ExpiresAtMs int64  `db:"expiresAtMs"`
+	CreatedMs   int64  `db:"createdMs"`
+	UpdatedMs   int64  `db:"updatedMs"`
+}
+
+// RateDecimals is the number of decimal places rates are quoted at, rounded half away from zero
+const RateDecimals = 8
+
+// Expired tells whether the open quote can no longer be executed at nowMs
+func (q *Quote) Expired(nowMs int64) bool {
+	return q.Status == Status_OPEN && nowMs >= q.ExpiresAtMs
+}
+
+// StatusAt returns the status of the quote seen at nowMs
+func (q *Quote) StatusAt(nowMs int64) Status {
+	if q.Expired(nowMs) {
+		return Status_EXPIRED
+	}
+	return q.Status
+}
@@ -0,0 +1,22 @@
+package health
+
+type Status string
+
+const (
+	Status_UP   Status = "UP"
+	Status_DOWN Status = "DOWN"
+)
+
+// CheckResult is the result of checking one dependency
+type CheckResult struct {
+	Name       string
+	Status     Status
+	Error      string
+	DurationMs int64
+}
+
+// Report is the result of all checks, it is up only if every check is up
+type Report struct {
+	Status Status
+	Checks []*CheckResult
+}
@@ -0,0 +1,60 @@
+package payment
+
+import "github.com/n3k0fi5t/wallet/app/money"
+
+type Status string
+
+const (
+	Status_PENDING  Status = "PENDING"
+	Status_PAID     Status = "PAID"
+	Status_DECLINED Status = "DECLINED"
+	Status_CANCELED Status = "CANCELED"
+	// Status_EXPIRED is never stored, a pending request past its expiry is expired
+	Status_EXPIRED Status = "EXPIRED"
+)
+
+func (s Status) IsValid() bool {
+	switch s {
+	case Status_PENDING, Status_PAID, Status_DECLINED, Status_CANCELED, Status_EXPIRED:
+		return true
+	}
+	return false
+}
+
+// Direction is the side of requests an account lists
+type Direction string
+
+const (
+	// Direction_INCOMING are requests the account is asked to pay
+	Direction_INCOMING Direction = "incoming"
+	// Direction_OUTGOING are requests the account asks others to pay
+	Direction_OUTGOING Direction = "outgoing"
+)
+
+func (d Direction) IsValid() bool {
+	return d == Direction_INCOMING || d == Direction_OUTGOING
+}
+
+// Request asks the payer to pay the amount to the requester before it expires
+type Request struct {
+	ID          int         `db:"id"`
+	RequestID   string      `db:"requestID"`
+	RequesterID string      `db:"requesterID"`
+	PayerID     string      `db:"payerID"`
+	Amount      money.Money `db:"amount"`
+	Memo        string      `db:"memo"`
+	Status      Status      `db:"status"`
+	// TradeID is the transfer paying the request
+	TradeID   string `db:"tradeID"`
+	ExpiresMs int64  `db:"expiresMs"`
+	CreatedMs int64  `db:"createdMs"`
+	UpdatedMs int64  `db:"updatedMs"`
+}
+
+// StatusAt returns the status at nowMs, a pending request expires lazily
+func (r *Request) StatusAt(nowMs int64) Status {
+	if r.Status == Status_PENDING && nowMs >= r.ExpiresMs {
+		return Status_EXPIRED
+	}
+	return r.Status
+}
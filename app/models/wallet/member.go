@@ -0,0 +1,68 @@
+package wallet
+
+import "github.com/n3k0fi5t/wallet/app/money"
+
+// Role is what a member can do with a joint wallet
+type Role string
+
+const (
+	// Role_OWNER spends without cap and manages members, the user opening the wallet is always an owner
+	Role_OWNER Role = "OWNER"
+	// Role_SPENDER withdraws and transfers within the daily cap
+	Role_SPENDER Role = "SPENDER"
+	// Role_VIEWER sees balance and history, and deposits
+	Role_VIEWER Role = "VIEWER"
+)
+
+func (r Role) IsValid() bool {
+	switch r {
+	case Role_OWNER, Role_SPENDER, Role_VIEWER:
+		return true
+	}
+	return false
+}
+
+// CanSpend tells whether the role withdraws and transfers from the wallet
+func (r Role) CanSpend() bool {
+	return r == Role_OWNER || r == Role_SPENDER
+}
+
+// CanManage tells whether the role adds and removes members
+func (r Role) CanManage() bool {
+	return r == Role_OWNER
+}
+
+// DayMs is the length of the period spending caps apply to
+const DayMs = int64(24 * 60 * 60 * 1000)
+
+// Member grants a user access to a wallet of someone else
+type Member struct {
+	ID       int    `db:"id"`
+	WalletID string `db:"walletID"`
+	UserID   string `db:"userID"`
+	Role     Role   `db:"role"`
+	// DailyCap is the max amount a spender pays out per UTC day in the currency of the wallet, 0 for no cap
+	DailyCap money.Money `db:"dailyCap"`
+	// SpentToday is the amount paid out in the UTC day SpentDay, which is days since epoch
+	SpentToday money.Money `db:"spentToday"`
+	SpentDay   int64       `db:"spentDay"`
+	CreatedMs  int64       `db:"createdMs"`
+	UpdatedMs  int64       `db:"updatedMs"`
+}
+
+// Spent returns the amount paid out in the UTC day of nowMs
+func (m *Member) Spent(nowMs int64) money.Money {
+	if m.SpentDay != nowMs/DayMs {
+		return money.New(0, m.DailyCap.Currency)
+	}
+	return m.SpentToday
+}
+
+// Capped tells whether paying out amount at nowMs exceeds the daily cap
+func (m *Member) Capped(nowMs int64, amount money.Money) bool {
+	if m.Role != Role_SPENDER || m.DailyCap.IsZero() {
+		return false
+	}
+	spent, err := money.AddInt64(m.Spent(nowMs).Amount, amount.Amount)
+	return err != nil || spent > m.DailyCap.Amount
+}
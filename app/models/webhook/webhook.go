@@ -0,0 +1,105 @@
+package webhook
+
+import (
+	"strings"
+
+	"github.com/n3k0fi5t/wallet/app/money"
+)
+
+type EventType string
+
+const (
+	EventType_DEPOSIT           EventType = "deposit"
+	EventType_WITHDRAW          EventType = "withdraw"
+	EventType_TRANSFER_RECEIVED EventType = "transfer.received"
+	EventType_TRANSFER_SENT     EventType = "transfer.sent"
+	// EventType_BILL_REMINDER asks the account to pay its share of a bill
+	EventType_BILL_REMINDER EventType = "bill.reminder"
+)
+
+// EventTypes are all event types endpoints may subscribe to
+var EventTypes = []EventType{EventType_DEPOSIT, EventType_WITHDRAW, EventType_TRANSFER_RECEIVED, EventType_TRANSFER_SENT, EventType_BILL_REMINDER}
+
+func (t EventType) IsValid() bool {
+	switch t {
+	case EventType_DEPOSIT, EventType_WITHDRAW, EventType_TRANSFER_RECEIVED, EventType_TRANSFER_SENT, EventType_BILL_REMINDER:
+		return true
+	}
+	return false
+}
+
+type DeliveryStatus string
+
+const (
+	DeliveryStatus_PENDING   DeliveryStatus = "PENDING"
+	DeliveryStatus_SUCCEEDED DeliveryStatus = "SUCCEEDED"
+	// DeliveryStatus_DEAD means retries are exhausted, only redelivery sends it again
+	DeliveryStatus_DEAD DeliveryStatus = "DEAD"
+)
+
+// Endpoint is a URL registered by an account to receive callbacks
+type Endpoint struct {
+	ID         int    `db:"id"`
+	EndpointID string `db:"endpointID"`
+	AccountID  string `db:"accountID"`
+	URL        string `db:"url"`
+	Secret     string `db:"secret"`
+	// EventTypes is comma separated EventType
+	EventTypes string `db:"eventTypes"`
+	CreatedMs  int64  `db:"createdMs"`
+}
+
+// Subscribes reports whether the endpoint wants events of the type
+func (e *Endpoint) Subscribes(t EventType) bool {
+	for _, s := range strings.Split(e.EventTypes, ",") {
+		if EventType(s) == t {
+			return true
+		}
+	}
+	return false
+}
+
+// Delivery is one event to be sent to one endpoint
+type Delivery struct {
+	ID             int            `db:"id"`
+	DeliveryID     string         `db:"deliveryID"`
+	EndpointID     string         `db:"endpointID"`
+	AccountID      string         `db:"accountID"`
+	EventID        string         `db:"eventID"`
+	EventType      EventType      `db:"eventType"`
+	Payload        string         `db:"payload"`
+	Status         DeliveryStatus `db:"status"`
+	Attempts       int            `db:"attempts"`
+	NextAttemptMs  int64          `db:"nextAttemptMs"`
+	LastStatusCode int            `db:"lastStatusCode"`
+	LastError      string         `db:"lastError"`
+	CreatedMs      int64          `db:"createdMs"`
+	UpdatedMs      int64          `db:"updatedMs"`
+}
+
+// Payload is the JSON body posted to endpoints
+type Payload struct {
+	DeliveryID  string    `json:"deliveryID"`
+	EventID     string    `json:"eventID"`
+	Type        EventType `json:"type"`
+	AccountID   string    `json:"accountID"`
+	TimestampMs int64     `json:"timestampMs"`
+	// Data is the trade of trade events, Bill is the unpaid share of bill reminders
+	Data *Data     `json:"data,omitempty"`
+	Bill *BillData `json:"bill,omitempty"`
+}
+
+type Data struct {
+	TradeID        string       `json:"tradeID"`
+	CounterpartyID string       `json:"counterpartyID,omitempty"`
+	Amount         money.Money  `json:"amount"`
+	BalanceAfter   *money.Money `json:"balanceAfter"`
+}
+
+type BillData struct {
+	BillID      string      `json:"billID"`
+	OrganizerID string      `json:"organizerID"`
+	Amount      money.Money `json:"amount"`
+	Memo        string      `json:"memo,omitempty"`
+	Reminders   int         `json:"reminders"`
+}
@@ -0,0 +1,109 @@
+package money
+
+import (
+	"math/big"
+	"math/bits"
+	"sort"
+)
+
+// RoundingMode is how amounts finer than the minor unit are rounded
+type RoundingMode int
+
+const (
+	// RoundDown drops the digits beyond the minor unit, rounding towards zero
+	RoundDown RoundingMode = iota
+	// RoundHalfUp rounds to the nearest minor unit, halves away from zero
+	RoundHalfUp
+	// RoundHalfEven rounds to the nearest minor unit, halves to the even one
+	RoundHalfEven
+)
+
+// FromRat converts an amount in the major unit to money, rounded to the minor unit of the currency by mode
+func FromRat(major *big.Rat, currency string, mode RoundingMode) (Money, error) {
+	if !IsValidCurrency(currency) {
+		return Money{}, ErrInvalidCurrency
+	}
+
+	x := new(big.Rat).Mul(major, new(big.Rat).SetInt(pow10(Exponent(currency))))
+	q, r := new(big.Int).QuoRem(x.Num(), x.Denom(), new(big.Int))
+
+	// compare the dropped part with a half by doubling it
+	half := new(big.Int).Abs(r)
+	half.Lsh(half, 1).Sub(half, x.Denom())
+	switch {
+	case mode == RoundHalfUp && half.Sign() >= 0,
+		mode == RoundHalfEven && (half.Sign() > 0 || half.Sign() == 0 && q.Bit(0) == 1):
+		q.Add(q, big.NewInt(int64(x.Sign())))
+	}
+
+	if !q.IsInt64() {
+		return Money{}, ErrOverflow
+	}
+	return New(q.Int64(), currency), nil
+}
+
+// Allocate divides the money by ratios without losing a minor unit. Each part is rounded down, then the leftover
+// units go one each to the parts with the largest remainders, ties go to the earlier part.
+func (m Money) Allocate(ratios ...int64) ([]Money, error) {
+	if len(ratios) == 0 {
+		return nil, ErrInvalidRatios
+	}
+
+	sum := uint64(0)
+	for _, ratio := range ratios {
+		if ratio < 0 {
+			return nil, ErrInvalidRatios
+		}
+		var carry uint64
+		if sum, carry = bits.Add64(sum, uint64(ratio), 0); carry != 0 {
+			return nil, ErrInvalidRatios
+		}
+	}
+	if sum == 0 {
+		return nil, ErrInvalidRatios
+	}
+
+	// amount * ratio may overflow 64 bits, it always fits 128 bits and the part fits 64 bits as ratio <= sum
+	total := magnitude(m.Amount)
+	parts := make([]uint64, len(ratios))
+	remainders := make([]uint64, len(ratios))
+	allocated := uint64(0)
+	for i, ratio := range ratios {
+		hi, lo := bits.Mul64(total, uint64(ratio))
+		parts[i], remainders[i] = bits.Div64(hi, lo, sum)
+		allocated += parts[i]
+	}
+
+	order := make([]int, len(ratios))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return remainders[order[a]] > remainders[order[b]]
+	})
+	for _, i := range order[:total-allocated] {
+		parts[i]++
+	}
+
+	allocation := make([]Money, len(ratios))
+	for i, part := range parts {
+		if m.Amount < 0 {
+			part = -part
+		}
+		allocation[i] = New(int64(part), m.Currency)
+	}
+	return allocation, nil
+}
+
+// Split divides the money into n parts as equal as possible, the earlier parts take the leftover units
+func (m Money) Split(n int) ([]Money, error) {
+	if n <= 0 {
+		return nil, ErrInvalidParts
+	}
+
+	ratios := make([]int64, n)
+	for i := range ratios {
+		ratios[i] = 1
+	}
+	return m.Allocate(ratios...)
+}
@@ -0,0 +1,24 @@
+package money
+
+// DefaultCurrency is the currency of accounts opened without one, default wallets included
+const DefaultCurrency = "USD"
+
+// currencyExponents is the number of digits of the minor unit of supported currencies, amounts are in minor units
+var currencyExponents = map[string]int{
+	"USD": 2,
+	"EUR": 2,
+	"GBP": 2,
+	"TWD": 2,
+	"JPY": 0,
+}
+
+// IsValidCurrency tells whether the ISO 4217 code is supported
+func IsValidCurrency(currency string) bool {
+	_, ok := currencyExponents[currency]
+	return ok
+}
+
+// Exponent returns the number of digits of the minor unit, 100 cents make a dollar for 2
+func Exponent(currency string) int {
+	return currencyExponents[currency]
+}
@@ -0,0 +1,115 @@
+package money
+
+import (
+	"encoding/json"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Decimal formats the amount in the major unit with all digits of the minor unit, e.g. 12.30 for 1230 USD
+func (m Money) Decimal() string {
+	digits := strconv.FormatUint(magnitude(m.Amount), 10)
+	sign := ""
+	if m.Amount < 0 {
+		sign = "-"
+	}
+
+	exp := Exponent(m.Currency)
+	if exp == 0 {
+		return sign + digits
+	}
+	if len(digits) <= exp {
+		digits = strings.Repeat("0", exp-len(digits)+1) + digits
+	}
+	return sign + digits[:len(digits)-exp] + "." + digits[len(digits)-exp:]
+}
+
+// String formats the money for display, e.g. 12.30 USD
+func (m Money) String() string {
+	return m.Decimal() + " " + m.Currency
+}
+
+// Parse parses a decimal string in the major unit of the currency, it has at most the digits of the minor unit
+// so amounts are never rounded
+func Parse(s, currency string) (Money, error) {
+	if !IsValidCurrency(currency) {
+		return Money{}, ErrInvalidCurrency
+	}
+
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+
+	whole, frac := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		whole, frac = s[:i], s[i+1:]
+		if frac == "" {
+			return Money{}, ErrInvalidAmount
+		}
+	}
+
+	exp := Exponent(currency)
+	if whole == "" || !isDigits(whole) || !isDigits(frac) || len(frac) > exp {
+		return Money{}, ErrInvalidAmount
+	}
+
+	amount, err := strconv.ParseInt(sign+whole+frac+strings.Repeat("0", exp-len(frac)), 10, 64)
+	if e, ok := err.(*strconv.NumError); ok && e.Err == strconv.ErrRange {
+		return Money{}, ErrOverflow
+	} else if err != nil {
+		return Money{}, ErrInvalidAmount
+	}
+	return New(amount, currency), nil
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// magnitude returns |amount|, which fits uint64 even for MinInt64
+func magnitude(amount int64) uint64 {
+	if amount < 0 {
+		return uint64(-(amount + 1)) + 1
+	}
+	return uint64(amount)
+}
+
+// Rat returns the amount in the major unit, e.g. 1234/100 for 1234 USD
+func (m Money) Rat() *big.Rat {
+	return new(big.Rat).SetFrac(big.NewInt(m.Amount), pow10(Exponent(m.Currency)))
+}
+
+func pow10(exp int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exp)), nil)
+}
+
+// jsonMoney is the JSON encoding of Money, the amount is a decimal string so clients never parse it into floats
+type jsonMoney struct {
+	Amount   string `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonMoney{Amount: m.Decimal(), Currency: m.Currency})
+}
+
+func (m *Money) UnmarshalJSON(b []byte) error {
+	j := jsonMoney{}
+	if err := json.Unmarshal(b, &j); err != nil {
+		return err
+	}
+
+	parsed, err := Parse(j.Amount, j.Currency)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
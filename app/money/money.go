@@ -0,0 +1,118 @@
+// Package money does arithmetic, allocation and decimal formatting of amounts. Every amount of the wallet is a Money,
+// stored as int64 minor units next to its currency and encoded in JSON as a decimal string in the major unit.
+package money
+
+import (
+	"fmt"
+	"math"
+)
+
+var (
+	// ErrOverflow means the result does not fit in int64 minor units
+	ErrOverflow = fmt.Errorf("Amount overflow")
+
+	// ErrCurrencyMismatch means the operands are of different currencies
+	ErrCurrencyMismatch = fmt.Errorf("Currency mismatch")
+
+	// ErrInvalidCurrency means the currency is not supported
+	ErrInvalidCurrency = fmt.Errorf("Invalid currency")
+
+	// ErrInvalidAmount means the decimal string is malformed or has more digits than the minor unit
+	ErrInvalidAmount = fmt.Errorf("Invalid amount")
+
+	// ErrInvalidRatios means no ratio is given, a ratio is negative or they add up to 0
+	ErrInvalidRatios = fmt.Errorf("Invalid ratios")
+
+	// ErrInvalidParts means the money is split into no parts or a negative number of them
+	ErrInvalidParts = fmt.Errorf("Invalid parts")
+)
+
+// Money is an amount in the minor unit of its currency, e.g. 1234 USD is 12.34 dollars. It is a value type,
+// operations return new values and never wrap around.
+type Money struct {
+	Amount   int64
+	Currency string
+}
+
+// New returns the amount of minor units of the currency
+func New(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+func (m Money) IsPositive() bool {
+	return m.Amount > 0
+}
+
+func (m Money) IsNegative() bool {
+	return m.Amount < 0
+}
+
+// Add returns m + o
+func (m Money) Add(o Money) (Money, error) {
+	if m.Currency != o.Currency {
+		return Money{}, ErrCurrencyMismatch
+	}
+
+	amount, err := AddInt64(m.Amount, o.Amount)
+	if err != nil {
+		return Money{}, err
+	}
+	return New(amount, m.Currency), nil
+}
+
+// Sub returns m - o
+func (m Money) Sub(o Money) (Money, error) {
+	if m.Currency != o.Currency {
+		return Money{}, ErrCurrencyMismatch
+	}
+
+	amount, err := SubInt64(m.Amount, o.Amount)
+	if err != nil {
+		return Money{}, err
+	}
+	return New(amount, m.Currency), nil
+}
+
+// Neg returns -m
+func (m Money) Neg() (Money, error) {
+	amount, err := SubInt64(0, m.Amount)
+	if err != nil {
+		return Money{}, err
+	}
+	return New(amount, m.Currency), nil
+}
+
+// Cmp returns -1, 0 or 1 as m is less than, equal to or greater than o
+func (m Money) Cmp(o Money) (int, error) {
+	if m.Currency != o.Currency {
+		return 0, ErrCurrencyMismatch
+	}
+
+	switch {
+	case m.Amount < o.Amount:
+		return -1, nil
+	case m.Amount > o.Amount:
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// AddInt64 returns a + b, or ErrOverflow if it does not fit in int64
+func AddInt64(a, b int64) (int64, error) {
+	if (b > 0 && a > math.MaxInt64-b) || (b < 0 && a < math.MinInt64-b) {
+		return 0, ErrOverflow
+	}
+	return a + b, nil
+}
+
+// SubInt64 returns a - b, or ErrOverflow if it does not fit in int64
+func SubInt64(a, b int64) (int64, error) {
+	if (b < 0 && a > math.MaxInt64+b) || (b > 0 && a < math.MinInt64+b) {
+		return 0, ErrOverflow
+	}
+	return a - b, nil
+}
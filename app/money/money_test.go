@@ -0,0 +1,185 @@
+package money
+
+import (
+	"encoding/json"
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestArithmetic(t *testing.T) {
+	sum, err := New(150, "USD").Add(New(-50, "USD"))
+	require.NoError(t, err)
+	require.Equal(t, New(100, "USD"), sum)
+
+	diff, err := New(150, "USD").Sub(New(200, "USD"))
+	require.NoError(t, err)
+	require.Equal(t, New(-50, "USD"), diff)
+
+	cmp, err := New(150, "USD").Cmp(New(200, "USD"))
+	require.NoError(t, err)
+	require.Equal(t, -1, cmp)
+
+	_, err = New(150, "USD").Add(New(1, "EUR"))
+	require.Equal(t, ErrCurrencyMismatch, err)
+	_, err = New(150, "USD").Cmp(New(1, "EUR"))
+	require.Equal(t, ErrCurrencyMismatch, err)
+
+	_, err = New(math.MaxInt64, "USD").Add(New(1, "USD"))
+	require.Equal(t, ErrOverflow, err)
+	_, err = New(math.MinInt64, "USD").Sub(New(1, "USD"))
+	require.Equal(t, ErrOverflow, err)
+	_, err = New(-1, "USD").Sub(New(math.MaxInt64, "USD"))
+	require.NoError(t, err)
+	_, err = New(0, "USD").Sub(New(math.MinInt64, "USD"))
+	require.Equal(t, ErrOverflow, err)
+	_, err = New(math.MinInt64, "USD").Neg()
+	require.Equal(t, ErrOverflow, err)
+}
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		Money  Money
+		ExpStr string
+	}{
+		{New(1234, "USD"), "12.34 USD"},
+		{New(1230, "USD"), "12.30 USD"},
+		{New(5, "USD"), "0.05 USD"},
+		{New(-5, "EUR"), "-0.05 EUR"},
+		{New(0, "USD"), "0.00 USD"},
+		{New(1234, "JPY"), "1234 JPY"},
+		{New(math.MinInt64, "USD"), "-92233720368547758.08 USD"},
+	}
+
+	for _, tt := range tests {
+		require.Equal(t, tt.ExpStr, tt.Money.String())
+
+		parsed, err := Parse(tt.Money.Decimal(), tt.Money.Currency)
+		require.NoError(t, err, tt.ExpStr)
+		require.Equal(t, tt.Money, parsed, tt.ExpStr)
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		Desc     string
+		S        string
+		Currency string
+		Exp      Money
+		ExpError error
+	}{
+		{Desc: "whole", S: "12", Currency: "USD", Exp: New(1200, "USD")},
+		{Desc: "less digits than minor unit", S: "12.3", Currency: "USD", Exp: New(1230, "USD")},
+		{Desc: "more digits than minor unit", S: "12.345", Currency: "USD", ExpError: ErrInvalidAmount},
+		{Desc: "no minor unit", S: "12.0", Currency: "JPY", ExpError: ErrInvalidAmount},
+		{Desc: "dangling point", S: "12.", Currency: "USD", ExpError: ErrInvalidAmount},
+		{Desc: "no whole part", S: ".5", Currency: "USD", ExpError: ErrInvalidAmount},
+		{Desc: "exponent", S: "1e3", Currency: "USD", ExpError: ErrInvalidAmount},
+		{Desc: "plus sign", S: "+1", Currency: "USD", ExpError: ErrInvalidAmount},
+		{Desc: "empty", S: "", Currency: "USD", ExpError: ErrInvalidAmount},
+		{Desc: "overflow", S: "92233720368547758.08", Currency: "USD", ExpError: ErrOverflow},
+		{Desc: "unknown currency", S: "1", Currency: "XXX", ExpError: ErrInvalidCurrency},
+	}
+
+	for _, tt := range tests {
+		m, err := Parse(tt.S, tt.Currency)
+		require.Equal(t, tt.ExpError, err, tt.Desc)
+		if tt.ExpError == nil {
+			require.Equal(t, tt.Exp, m, tt.Desc)
+		}
+	}
+}
+
+func TestJSON(t *testing.T) {
+	b, err := json.Marshal(New(1234, "USD"))
+	require.NoError(t, err)
+	require.JSONEq(t, `{"amount":"12.34","currency":"USD"}`, string(b))
+
+	m := Money{}
+	require.NoError(t, json.Unmarshal(b, &m))
+	require.Equal(t, New(1234, "USD"), m)
+
+	require.Equal(t, ErrInvalidAmount, json.Unmarshal([]byte(`{"amount":"0.001","currency":"USD"}`), &m))
+	require.Error(t, json.Unmarshal([]byte(`{"amount":12.34,"currency":"USD"}`), &m))
+}
+
+func TestFromRat(t *testing.T) {
+	tests := []struct {
+		Major    *big.Rat
+		Currency string
+		Mode     RoundingMode
+		Exp      int64
+	}{
+		{big.NewRat(12345, 1000), "USD", RoundDown, 1234},
+		{big.NewRat(12345, 1000), "USD", RoundHalfUp, 1235},
+		{big.NewRat(12345, 1000), "USD", RoundHalfEven, 1234},
+		{big.NewRat(12355, 1000), "USD", RoundHalfEven, 1236},
+		{big.NewRat(12346, 1000), "USD", RoundHalfEven, 1235},
+		{big.NewRat(-12345, 1000), "USD", RoundDown, -1234},
+		{big.NewRat(-12345, 1000), "USD", RoundHalfUp, -1235},
+		{big.NewRat(3, 2), "JPY", RoundHalfEven, 2},
+		{big.NewRat(5, 2), "JPY", RoundHalfEven, 2},
+	}
+
+	for _, tt := range tests {
+		m, err := FromRat(tt.Major, tt.Currency, tt.Mode)
+		require.NoError(t, err, tt.Major.String())
+		require.Equal(t, New(tt.Exp, tt.Currency), m, tt.Major.String())
+	}
+
+	_, err := FromRat(new(big.Rat).SetInt64(math.MaxInt64), "USD", RoundDown)
+	require.Equal(t, ErrOverflow, err)
+	_, err = FromRat(big.NewRat(1, 1), "XXX", RoundDown)
+	require.Equal(t, ErrInvalidCurrency, err)
+
+	require.Equal(t, big.NewRat(1234, 100), New(1234, "USD").Rat())
+}
+
+func TestAllocate(t *testing.T) {
+	amounts := func(parts []Money) []int64 {
+		res := []int64{}
+		for _, p := range parts {
+			res = append(res, p.Amount)
+		}
+		return res
+	}
+
+	tests := []struct {
+		Desc       string
+		Amount     int64
+		Ratios     []int64
+		ExpAmounts []int64
+		ExpError   error
+	}{
+		{Desc: "even", Amount: 900, Ratios: []int64{1, 1, 1}, ExpAmounts: []int64{300, 300, 300}},
+		{Desc: "leftover to the earlier", Amount: 1000, Ratios: []int64{1, 1, 1}, ExpAmounts: []int64{334, 333, 333}},
+		{Desc: "leftover to the largest remainder", Amount: 100, Ratios: []int64{3333, 3334, 3333}, ExpAmounts: []int64{33, 34, 33}},
+		{Desc: "zero ratio", Amount: 100, Ratios: []int64{0, 1, 2}, ExpAmounts: []int64{0, 33, 67}},
+		{Desc: "negative amount", Amount: -1000, Ratios: []int64{1, 1, 1}, ExpAmounts: []int64{-334, -333, -333}},
+		{Desc: "no overflow of amount times ratio", Amount: math.MaxInt64, Ratios: []int64{math.MaxInt64, 1}, ExpAmounts: []int64{math.MaxInt64 - 1, 1}},
+		{Desc: "min amount", Amount: math.MinInt64, Ratios: []int64{1}, ExpAmounts: []int64{math.MinInt64}},
+		{Desc: "no ratio", Amount: 100, Ratios: []int64{}, ExpError: ErrInvalidRatios},
+		{Desc: "negative ratio", Amount: 100, Ratios: []int64{2, -1}, ExpError: ErrInvalidRatios},
+		{Desc: "zero ratios", Amount: 100, Ratios: []int64{0, 0}, ExpError: ErrInvalidRatios},
+	}
+
+	for _, tt := range tests {
+		parts, err := New(tt.Amount, "USD").Allocate(tt.Ratios...)
+		require.Equal(t, tt.ExpError, err, tt.Desc)
+		if tt.ExpError == nil {
+			require.Equal(t, tt.ExpAmounts, amounts(parts), tt.Desc)
+		}
+	}
+
+	parts, err := New(11, "JPY").Split(3)
+	require.NoError(t, err)
+	require.Equal(t, []Money{New(4, "JPY"), New(4, "JPY"), New(3, "JPY")}, parts)
+
+	for _, n := range []int{0, -1} {
+		parts, err = New(11, "JPY").Split(n)
+		require.Equal(t, ErrInvalidParts, err, n)
+		require.Nil(t, parts, n)
+	}
+}
@@ -0,0 +1,44 @@
+package publisher
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+
+	mEvent "github.com/n3k0fi5t/wallet/app/models/event"
+)
+
+// File appends events to a local file as JSON lines, a stand-in for a message broker
+type File struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFile ...
+func NewFile(path string) *File {
+	return &File{
+		path: path,
+	}
+}
+
+func (p *File) Publish(ctx context.Context, event *mEvent.Event) error {
+	b, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	f, err := os.OpenFile(p.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.Write(append(b, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
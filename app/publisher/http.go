@@ -0,0 +1,51 @@
+package publisher
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	mEvent "github.com/n3k0fi5t/wallet/app/models/event"
+)
+
+// HTTP posts events as JSON to an endpoint, a stand-in for a message broker
+type HTTP struct {
+	url    string
+	client *http.Client
+}
+
+// NewHTTP ...
+func NewHTTP(url string, client *http.Client) *HTTP {
+	return &HTTP{
+		url:    url,
+		client: client,
+	}
+}
+
+func (p *HTTP) Publish(ctx context.Context, event *mEvent.Event) error {
+	b, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, p.url, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Event-ID", event.EventID)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("publish event %s: unexpected status %d", event.EventID, resp.StatusCode)
+	}
+	return nil
+}
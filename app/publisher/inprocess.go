@@ -0,0 +1,43 @@
+package publisher
+
+import (
+	"context"
+	"sync"
+
+	mEvent "github.com/n3k0fi5t/wallet/app/models/event"
+)
+
+// Handler handles an event, an error makes the event delivered again later
+type Handler func(ctx context.Context, event *mEvent.Event) error
+
+// InProcess delivers events to handlers subscribed in the same process
+type InProcess struct {
+	mu       sync.RWMutex
+	handlers []Handler
+}
+
+// NewInProcess ...
+func NewInProcess() *InProcess {
+	return &InProcess{}
+}
+
+// Subscribe registers a handler receiving every published event
+func (p *InProcess) Subscribe(h Handler) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.handlers = append(p.handlers, h)
+}
+
+func (p *InProcess) Publish(ctx context.Context, event *mEvent.Event) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	// handlers must be idempotent, all of them see the event again if any fails
+	for _, h := range p.handlers {
+		if err := h(ctx, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package publisher
+
+import (
+	"context"
+
+	mEvent "github.com/n3k0fi5t/wallet/app/models/event"
+)
+
+// Multi fans out events to several publishers, all of them see the event again if any fails
+type Multi struct {
+	publishers []Publisher
+}
+
+// NewMulti ...
+func NewMulti(publishers ...Publisher) *Multi {
+	return &Multi{
+		publishers: publishers,
+	}
+}
+
+func (p *Multi) Publish(ctx context.Context, event *mEvent.Event) error {
+	for _, pub := range p.publishers {
+		if err := pub.Publish(ctx, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
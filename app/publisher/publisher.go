@@ -0,0 +1,12 @@
+package publisher
+
+import (
+	"context"
+
+	mEvent "github.com/n3k0fi5t/wallet/app/models/event"
+)
+
+// Publisher delivers events to downstream, an error means the event should be delivered again later
+type Publisher interface {
+	Publish(ctx context.Context, event *mEvent.Event) error
+}
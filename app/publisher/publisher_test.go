@@ -0,0 +1,112 @@
+package publisher
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	mdEvent "github.com/n3k0fi5t/wallet/app/models/event"
+	"github.com/n3k0fi5t/wallet/app/money"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	mockCtx          = context.Background()
+	mockBalanceAfter = money.New(300, "USD")
+	mockEvent        = &mdEvent.Event{
+		Sequence:  1,
+		EventID:   "935f871a-660f-4f19-801e-916c04bb0324",
+		Type:      mdEvent.Type_TRADE_COMPLETED,
+		AccountID: "n3k0fi5t",
+		Trade: &mdEvent.Trade{
+			TradeID:        "a89b7b78-b9c1-4129-8cff-380bf53f3a49",
+			CounterpartyID: "deadbeef",
+			Direction:      mdEvent.Direction_CREDIT,
+			Amount:         money.New(100, "USD"),
+			BalanceAfter:   &mockBalanceAfter,
+		},
+	}
+)
+
+func TestInProcess(t *testing.T) {
+	p := NewInProcess()
+
+	received := []*mdEvent.Event{}
+	p.Subscribe(func(ctx context.Context, e *mdEvent.Event) error {
+		received = append(received, e)
+		return nil
+	})
+	require.NoError(t, p.Publish(mockCtx, mockEvent))
+	require.Equal(t, []*mdEvent.Event{mockEvent}, received)
+
+	// a failed handler makes the event delivered again
+	p.Subscribe(func(ctx context.Context, e *mdEvent.Event) error {
+		return fmt.Errorf("not ready")
+	})
+	require.Error(t, p.Publish(mockCtx, mockEvent))
+}
+
+func TestFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "publisher")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "events.jsonl")
+	p := NewFile(path)
+	require.NoError(t, p.Publish(mockCtx, mockEvent))
+	require.NoError(t, p.Publish(mockCtx, mockEvent))
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	lines := 0
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		e := &mdEvent.Event{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), e))
+		require.Equal(t, mockEvent, e)
+		lines++
+	}
+	require.Equal(t, 2, lines)
+}
+
+func TestHTTP(t *testing.T) {
+	status := http.StatusOK
+	received := []*mdEvent.Event{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		e := &mdEvent.Event{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(e))
+		require.Equal(t, e.EventID, r.Header.Get("X-Event-ID"))
+		received = append(received, e)
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	p := NewHTTP(srv.URL, srv.Client())
+	require.NoError(t, p.Publish(mockCtx, mockEvent))
+	require.Equal(t, []*mdEvent.Event{mockEvent}, received)
+
+	status = http.StatusServiceUnavailable
+	require.Error(t, p.Publish(mockCtx, mockEvent))
+}
+
+func TestMulti(t *testing.T) {
+	first, second := NewInProcess(), NewInProcess()
+	count := 0
+	second.Subscribe(func(ctx context.Context, e *mdEvent.Event) error {
+		count++
+		return nil
+	})
+
+	p := NewMulti(first, second)
+	require.NoError(t, p.Publish(mockCtx, mockEvent))
+	require.Equal(t, 1, count)
+}
@@ -0,0 +1,79 @@
+package rail
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	mFunding "github.com/n3k0fi5t/wallet/app/models/funding"
+)
+
+const (
+	// HeaderSignature carries "sha256=" and hex HMAC-SHA256 of "{timestamp}.{body}" keyed by the secret of the rail
+	HeaderSignature = "X-Rail-Signature"
+	// HeaderTimestamp carries unix seconds when the callback is signed
+	HeaderTimestamp = "X-Rail-Timestamp"
+
+	// SignatureTolerance is how old a signed callback may be, older ones are rejected as replays
+	SignatureTolerance = 5 * time.Minute
+)
+
+var (
+	// ErrInvalidSignature means the callback is not signed by the rail, or signed too long ago
+	ErrInvalidSignature = fmt.Errorf("Invalid signature")
+
+	// ErrInvalidCallback means the callback body is malformed
+	ErrInvalidCallback = fmt.Errorf("Invalid callback")
+
+	timeNow = time.Now
+)
+
+// Adapter connects a payment rail, e.g. a bank transfer network or a card acquirer. Rails move money
+// asynchronously, they tell how submitted transfers end by status callbacks
+type Adapter interface {
+	// Submit sends the transfer to the rail, it returns the reference of the transfer on the rail once accepted
+	Submit(ctx context.Context, transfer *mFunding.Transfer) (string, error)
+
+	// ParseCallback authenticates a status callback by its headers and parses its body
+	ParseCallback(header http.Header, body []byte) (*Callback, error)
+}
+
+// Callback tells the status a transfer moved to on the rail
+type Callback struct {
+	TransferID string          `json:"transferID"`
+	Reference  string          `json:"reference"`
+	Status     mFunding.Status `json:"status"`
+	Reason     string          `json:"reason,omitempty"`
+}
+
+// Sign returns the signature header value of the body
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks the signature and timestamp headers of the body in constant time
+func Verify(secret string, header http.Header, body []byte) error {
+	timestamp, err := strconv.ParseInt(header.Get(HeaderTimestamp), 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	age := timeNow().Sub(time.Unix(timestamp, 0))
+	if age > SignatureTolerance || age < -SignatureTolerance {
+		return ErrInvalidSignature
+	}
+
+	if !hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(header.Get(HeaderSignature))) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package rail
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	mFunding "github.com/n3k0fi5t/wallet/app/models/funding"
+	"github.com/n3k0fi5t/wallet/app/money"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	mockCtx        = context.Background()
+	mockSecret     = "rail-secret"
+	mockTransferID = "7d3c1a52-9b0e-4f6a-8c21-5e4b3a2f1d09"
+)
+
+func signedHeader(secret string, timestamp int64, body []byte) http.Header {
+	header := http.Header{}
+	header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	header.Set(HeaderSignature, Sign(secret, timestamp, body))
+	return header
+}
+
+func TestVerify(t *testing.T) {
+	body := []byte(`{"transferID":"t1","status":"SETTLED"}`)
+	now := time.Now().Unix()
+
+	require.NoError(t, Verify(mockSecret, signedHeader(mockSecret, now, body), body))
+	require.Equal(t, ErrInvalidSignature, Verify("other", signedHeader(mockSecret, now, body), body))
+	require.Equal(t, ErrInvalidSignature, Verify(mockSecret, signedHeader(mockSecret, now, body), []byte(`{}`)))
+	require.Equal(t, ErrInvalidSignature, Verify(mockSecret, http.Header{}, body))
+
+	// stale callbacks are replays
+	stale := now - int64(SignatureTolerance/time.Second) - 1
+	require.Equal(t, ErrInvalidSignature, Verify(mockSecret, signedHeader(mockSecret, stale, body), body))
+}
+
+func TestOutcome(t *testing.T) {
+	require.Equal(t, []mFunding.Status{mFunding.Status_SETTLED}, Outcome(10000))
+	require.Equal(t, []mFunding.Status{mFunding.Status_FAILED}, Outcome(10001))
+	require.Equal(t, []mFunding.Status{mFunding.Status_SETTLED, mFunding.Status_RETURNED}, Outcome(10002))
+}
+
+func TestSimulator(t *testing.T) {
+	callbacks := make(chan *Callback, 2)
+	failed := false
+	var s *Simulator
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		// the first post is not acknowledged and posted again
+		if !failed {
+			failed = true
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		cb, err := s.ParseCallback(r.Header, body)
+		require.NoError(t, err)
+		callbacks <- cb
+	}))
+	defer srv.Close()
+
+	s = NewSimulator(srv.URL, mockSecret, 0, srv.Client())
+	reference, err := s.Submit(mockCtx, &mFunding.Transfer{TransferID: mockTransferID, Amount: money.New(10002, "USD")})
+	require.NoError(t, err)
+	require.Contains(t, reference, ReferencePrefix)
+
+	for _, status := range []mFunding.Status{mFunding.Status_SETTLED, mFunding.Status_RETURNED} {
+		select {
+		case cb := <-callbacks:
+			require.Equal(t, mockTransferID, cb.TransferID)
+			require.Equal(t, reference, cb.Reference)
+			require.Equal(t, status, cb.Status)
+		case <-time.After(5 * time.Second):
+			require.FailNow(t, "callback not posted", status)
+		}
+	}
+
+	_, err = s.ParseCallback(signedHeader(mockSecret, time.Now().Unix(), []byte(`{}`)), []byte(`{}`))
+	require.Equal(t, ErrInvalidCallback, err)
+}
@@ -0,0 +1,147 @@
+package rail
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	mFunding "github.com/n3k0fi5t/wallet/app/models/funding"
+	"github.com/n3k0fi5t/wallet/app/util"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// simulatorAttempts is the number of times a callback is posted before the simulator gives up on it
+	simulatorAttempts = 5
+
+	// ReferencePrefix prefixes references of the simulator
+	ReferencePrefix = "sim_"
+)
+
+var (
+	getUUID = util.GetUUIDv4
+)
+
+// Outcome returns the statuses the simulator moves a transfer of the amount through, picked by the last two digits
+// of the amount in minor units: 01 fails, 02 settles and is returned, others settle
+func Outcome(amount int64) []mFunding.Status {
+	switch amount % 100 {
+	case 1:
+		return []mFunding.Status{mFunding.Status_FAILED}
+	case 2:
+		return []mFunding.Status{mFunding.Status_SETTLED, mFunding.Status_RETURNED}
+	}
+	return []mFunding.Status{mFunding.Status_SETTLED}
+}
+
+// simulatedReasons are the reasons of failed and returned transfers
+var simulatedReasons = map[mFunding.Status]string{
+	mFunding.Status_FAILED:   "declined by the simulator",
+	mFunding.Status_RETURNED: "returned by the simulator",
+}
+
+// Simulator accepts every transfer and posts signed status callbacks to the callback URL after delay, so the
+// whole lifecycle runs locally. Callbacks not acknowledged with 2xx are posted again after delay
+type Simulator struct {
+	callbackURL string
+	secret      string
+	delay       time.Duration
+	client      *http.Client
+}
+
+// NewSimulator ...
+func NewSimulator(callbackURL, secret string, delay time.Duration, client *http.Client) *Simulator {
+	return &Simulator{
+		callbackURL: callbackURL,
+		secret:      secret,
+		delay:       delay,
+		client:      client,
+	}
+}
+
+func (s *Simulator) Submit(ctx context.Context, t *mFunding.Transfer) (string, error) {
+	id, err := getUUID()
+	if err != nil {
+		return "", err
+	}
+	reference := ReferencePrefix + id
+
+	// the callbacks outlive the request submitting the transfer
+	go s.run(t.TransferID, reference, Outcome(t.Amount.Amount))
+	return reference, nil
+}
+
+func (s *Simulator) ParseCallback(header http.Header, body []byte) (*Callback, error) {
+	if err := Verify(s.secret, header, body); err != nil {
+		return nil, err
+	}
+
+	cb := &Callback{}
+	if err := json.Unmarshal(body, cb); err != nil || cb.TransferID == "" || !cb.Status.IsValid() {
+		return nil, ErrInvalidCallback
+	}
+	return cb, nil
+}
+
+// run posts the callbacks of the statuses in order, a status is not posted until the one before is acknowledged
+func (s *Simulator) run(transferID, reference string, statuses []mFunding.Status) {
+	for _, status := range statuses {
+		cb := &Callback{
+			TransferID: transferID,
+			Reference:  reference,
+			Status:     status,
+			Reason:     simulatedReasons[status],
+		}
+
+		var err error
+		for attempt := 0; attempt < simulatorAttempts; attempt++ {
+			time.Sleep(s.delay)
+			if err = s.post(cb); err == nil {
+				break
+			}
+		}
+		if err != nil {
+			logrus.WithFields(logrus.Fields{
+				"err":        err,
+				"transferID": transferID,
+				"status":     status,
+			}).Error("post callback failed in Simulator")
+			return
+		}
+	}
+}
+
+func (s *Simulator) post(cb *Callback) error {
+	body, err := json.Marshal(cb)
+	if err != nil {
+		return err
+	}
+	timestamp := timeNow().Unix()
+
+	req, err := http.NewRequest(http.MethodPost, s.callbackURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(s.secret, timestamp, body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	// drain body to reuse the connection
+	io.Copy(ioutil.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
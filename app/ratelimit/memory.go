@@ -0,0 +1,80 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+const (
+	// sweepInterval is how often full buckets are dropped, a full bucket is the same as a missing one
+	sweepInterval = time.Minute
+)
+
+var (
+	timeNow = time.Now
+)
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	fullAt time.Time
+}
+
+// Memory keeps buckets in this process, limits are per replica
+type Memory struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+// NewMemory ...
+func NewMemory() *Memory {
+	return &Memory{
+		buckets:   map[string]*bucket{},
+		lastSweep: timeNow(),
+	}
+}
+
+func (m *Memory) Allow(ctx context.Context, key string, limit Limit) (*Result, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := timeNow()
+	m.sweep(now)
+
+	b, ok := m.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Burst), last: now}
+		m.buckets[key] = b
+	}
+
+	tokens, res := take(b.tokens, now.Sub(b.last), limit)
+	b.tokens, b.last, b.fullAt = tokens, now, now.Add(res.ResetAfter)
+	return res, nil
+}
+
+func (m *Memory) Refund(ctx context.Context, key string, limit Limit) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// a swept bucket is full already
+	if b, ok := m.buckets[key]; ok {
+		b.tokens = math.Min(float64(limit.Burst), b.tokens+1)
+		b.fullAt = b.fullAt.Add(-seconds(1 / limit.Rate))
+	}
+	return nil
+}
+
+func (m *Memory) sweep(now time.Time) {
+	if now.Sub(m.lastSweep) < sweepInterval {
+		return
+	}
+	for key, b := range m.buckets {
+		if !now.Before(b.fullAt) {
+			delete(m.buckets, key)
+		}
+	}
+	m.lastSweep = now
+}
@@ -0,0 +1,74 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// Limit is a token bucket, it refills Rate tokens per second up to Burst, and each request takes one token
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// Enabled reports whether the limit applies, a zero limit means unlimited
+func (l Limit) Enabled() bool {
+	return l.Rate > 0 && l.Burst > 0
+}
+
+// Result is the state of the bucket after taking a token
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// RetryAfter is how long until a token is available, zero if allowed
+	RetryAfter time.Duration
+	// ResetAfter is how long until the bucket is full again
+	ResetAfter time.Duration
+}
+
+// Limiter takes tokens from buckets by key, implementations sharing state across replicas give consistent limits
+type Limiter interface {
+	Allow(ctx context.Context, key string, limit Limit) (*Result, error)
+
+	// Refund gives back the token taken by Allow, for requests rejected by another bucket
+	Refund(ctx context.Context, key string, limit Limit) error
+}
+
+// take refills the bucket for the elapsed time and takes one token, it returns the tokens left and the result
+func take(tokens float64, elapsed time.Duration, limit Limit) (float64, *Result) {
+	tokens = math.Min(float64(limit.Burst), tokens+elapsed.Seconds()*limit.Rate)
+
+	allowed := tokens >= 1
+	if allowed {
+		tokens--
+	}
+	return tokens, result(allowed, tokens, limit)
+}
+
+// result describes the bucket holding the tokens after the request
+func result(allowed bool, tokens float64, limit Limit) *Result {
+	res := &Result{
+		Allowed:    allowed,
+		Limit:      limit.Burst,
+		Remaining:  int(tokens),
+		ResetAfter: seconds((float64(limit.Burst) - tokens) / limit.Rate),
+	}
+	if !allowed {
+		res.RetryAfter = seconds((1 - tokens) / limit.Rate)
+	}
+	return res
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
+
+// Policy is the budgets of requests, reads and writes of an account or an IP are limited separately
+type Policy struct {
+	AccountRead  Limit
+	AccountWrite Limit
+	IPRead       Limit
+	IPWrite      Limit
+}
@@ -0,0 +1,147 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/suite"
+)
+
+var (
+	mockCtx   = context.Background()
+	mockKey   = "account:935f871a-660f-4f19-801e-916c04bb0324:write"
+	mockLimit = Limit{Rate: 2, Burst: 3}
+	mockNow   = time.Date(2022, 4, 15, 5, 20, 0, 0, time.UTC)
+)
+
+type testSuite struct {
+	suite.Suite
+
+	now   time.Time
+	redis *miniredis.Miniredis
+}
+
+func (s *testSuite) SetupTest() {
+	s.now = mockNow
+	timeNow = func() time.Time { return s.now }
+
+	s.redis = miniredis.NewMiniRedis()
+	s.Require().NoError(s.redis.Start())
+	s.redis.SetTime(mockNow)
+}
+
+func (s *testSuite) TearDownTest() {
+	timeNow = time.Now
+	s.redis.Close()
+}
+
+func TestSuite(t *testing.T) {
+	suite.Run(t, new(testSuite))
+}
+
+// advance moves the clock of both backends
+func (s *testSuite) advance(d time.Duration) {
+	s.now = s.now.Add(d)
+	s.redis.SetTime(s.now)
+}
+
+func (s *testSuite) testBucket(l Limiter) {
+	tests := []struct {
+		Desc    string
+		Advance time.Duration
+		ExpRes  Result
+	}{
+		{
+			Desc:   "full bucket",
+			ExpRes: Result{Allowed: true, Limit: 3, Remaining: 2, ResetAfter: 500 * time.Millisecond},
+		},
+		{
+			Desc:   "second token",
+			ExpRes: Result{Allowed: true, Limit: 3, Remaining: 1, ResetAfter: time.Second},
+		},
+		{
+			Desc:   "last token",
+			ExpRes: Result{Allowed: true, Limit: 3, Remaining: 0, ResetAfter: 1500 * time.Millisecond},
+		},
+		{
+			Desc:   "empty bucket",
+			ExpRes: Result{Allowed: false, Limit: 3, Remaining: 0, RetryAfter: 500 * time.Millisecond, ResetAfter: 1500 * time.Millisecond},
+		},
+		{
+			Desc:    "refilled",
+			Advance: 500 * time.Millisecond,
+			ExpRes:  Result{Allowed: true, Limit: 3, Remaining: 0, ResetAfter: 1500 * time.Millisecond},
+		},
+		{
+			Desc:    "refill stops at burst",
+			Advance: time.Hour,
+			ExpRes:  Result{Allowed: true, Limit: 3, Remaining: 2, ResetAfter: 500 * time.Millisecond},
+		},
+	}
+
+	for _, t := range tests {
+		s.advance(t.Advance)
+		res, err := l.Allow(mockCtx, mockKey, mockLimit)
+		s.Require().NoError(err, t.Desc)
+		s.Require().Equal(t.ExpRes, *res, t.Desc)
+	}
+
+	// buckets are independent
+	res, err := l.Allow(mockCtx, "ip:127.0.0.1:write", mockLimit)
+	s.Require().NoError(err)
+	s.Require().Equal(2, res.Remaining)
+}
+
+func (s *testSuite) testRefund(l Limiter) {
+	for i := 0; i < mockLimit.Burst; i++ {
+		_, err := l.Allow(mockCtx, mockKey, mockLimit)
+		s.Require().NoError(err)
+	}
+
+	// the refunded token is taken again, and refunds never fill the bucket above burst
+	s.Require().NoError(l.Refund(mockCtx, mockKey, mockLimit))
+	res, err := l.Allow(mockCtx, mockKey, mockLimit)
+	s.Require().NoError(err)
+	s.Require().True(res.Allowed)
+	s.Require().Equal(0, res.Remaining)
+
+	s.advance(time.Hour)
+	s.Require().NoError(l.Refund(mockCtx, mockKey, mockLimit))
+	res, err = l.Allow(mockCtx, mockKey, mockLimit)
+	s.Require().NoError(err)
+	s.Require().Equal(2, res.Remaining)
+
+	// refunding a missing bucket is a no-op
+	s.Require().NoError(l.Refund(mockCtx, "ip:192.0.2.1:read", mockLimit))
+}
+
+func (s *testSuite) TestMemoryRefund() {
+	s.testRefund(NewMemory())
+}
+
+func (s *testSuite) TestRedisRefund() {
+	s.testRefund(NewRedis(redis.NewClient(&redis.Options{Addr: s.redis.Addr()})))
+}
+
+func (s *testSuite) TestMemory() {
+	m := NewMemory()
+	s.testBucket(m)
+
+	// full buckets are swept
+	s.advance(sweepInterval)
+	_, err := m.Allow(mockCtx, mockKey, mockLimit)
+	s.Require().NoError(err)
+	s.Require().Len(m.buckets, 1)
+}
+
+func (s *testSuite) TestRedis() {
+	s.testBucket(NewRedis(redis.NewClient(&redis.Options{Addr: s.redis.Addr()})))
+
+	// buckets expire once full
+	s.Require().True(s.redis.Exists(keyPrefix + mockKey))
+	s.redis.FastForward(2 * time.Second)
+	s.Require().False(s.redis.Exists(keyPrefix + mockKey))
+}
@@ -0,0 +1,82 @@
+package ratelimit
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	keyPrefix = "ratelimit:"
+)
+
+// takeScript refills and takes a token atomically with the clock of Redis, so replicas share buckets without clock skew.
+// The bucket expires once it would be full again
+var takeScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local t = redis.call("TIME")
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+
+local b = redis.call("HMGET", KEYS[1], "tokens", "ts")
+local tokens = tonumber(b[1]) or burst
+local ts = tonumber(b[2]) or now
+tokens = math.min(burst, tokens + math.max(0, now - ts) / 1000 * rate)
+
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+
+redis.call("HSET", KEYS[1], "tokens", tostring(tokens), "ts", tostring(now))
+redis.call("PEXPIRE", KEYS[1], math.ceil((burst - tokens) / rate * 1000) + 1000)
+return {allowed, tostring(tokens)}
+`)
+
+// refundScript gives a token back without refilling, an expired bucket is full already
+var refundScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local tokens = tonumber(redis.call("HGET", KEYS[1], "tokens"))
+if not tokens then
+	return 0
+end
+
+tokens = math.min(burst, tokens + 1)
+redis.call("HSET", KEYS[1], "tokens", tostring(tokens))
+redis.call("PEXPIRE", KEYS[1], math.ceil((burst - tokens) / rate * 1000) + 1000)
+return 1
+`)
+
+// Redis keeps buckets in Redis, limits are shared by replicas
+type Redis struct {
+	client redis.Scripter
+}
+
+// NewRedis ...
+func NewRedis(client redis.Scripter) *Redis {
+	return &Redis{
+		client: client,
+	}
+}
+
+func (r *Redis) Allow(ctx context.Context, key string, limit Limit) (*Result, error) {
+	res, err := takeScript.Run(ctx, r.client, []string{keyPrefix + key}, limit.Rate, limit.Burst).Slice()
+	if err != nil {
+		return nil, err
+	}
+
+	allowed, _ := res[0].(int64)
+	s, _ := res[1].(string)
+	tokens, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil, err
+	}
+	return result(allowed == 1, tokens, limit), nil
+}
+
+func (r *Redis) Refund(ctx context.Context, key string, limit Limit) error {
+	return refundScript.Run(ctx, r.client, []string{keyPrefix + key}, limit.Rate, limit.Burst).Err()
+}
@@ -0,0 +1,51 @@
+package admin
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+	mAdmin "github.com/n3k0fi5t/wallet/app/models/admin"
+	mAudit "github.com/n3k0fi5t/wallet/app/models/audit"
+	"github.com/n3k0fi5t/wallet/app/money"
+	"github.com/n3k0fi5t/wallet/app/repository/audit"
+	"github.com/n3k0fi5t/wallet/common/logger"
+	"github.com/n3k0fi5t/wallet/common/sql"
+)
+
+const (
+	insertAdminAction = "INSERT INTO AdminActionLog (operatorID, role, action, accountID, tradeID, amount, currency, reasonCode, note, timestampMs) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"
+)
+
+func NewAdmin(db *sqlx.DB, a audit.Audit) Admin {
+	return &impl{
+		db:    db,
+		audit: a,
+	}
+}
+
+type impl struct {
+	db    *sqlx.DB
+	audit audit.Audit
+}
+
+func (im *impl) RecordAction(ctx context.Context, action *mAdmin.Action) error {
+	// actions moving no money have no amount
+	amount := money.Money{}
+	if action.Amount != nil {
+		amount = *action.Amount
+	}
+
+	return sql.TransactCtx(ctx, im.db, func(ctx context.Context, tx *sqlx.Tx) error {
+		if _, err := tx.ExecContext(ctx, insertAdminAction, action.OperatorID, action.Role, action.Action, action.AccountID,
+			action.TradeID, amount.Amount, amount.Currency, action.ReasonCode, action.Note, action.TimestampMs); err != nil {
+			logger.FromContext(ctx).WithField("err", err).Error("ExecContext failed in Admin.RecordAction")
+			return err
+		}
+
+		if err := im.audit.Record(ctx, mAudit.Action_ADMIN_ACTION, "account:"+action.AccountID, nil, action); err != nil {
+			logger.FromContext(ctx).WithField("err", err).Error("audit.Record failed in Admin.RecordAction")
+			return err
+		}
+		return nil
+	})
+}
@@ -0,0 +1,12 @@
+package admin
+
+import (
+	"context"
+
+	mAdmin "github.com/n3k0fi5t/wallet/app/models/admin"
+)
+
+type Admin interface {
+	// RecordAction persists an admin action, it joins the transaction carried by context
+	RecordAction(ctx context.Context, action *mAdmin.Action) error
+}
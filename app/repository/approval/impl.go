@@ -0,0 +1,228 @@
+package approval
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+	mApproval "github.com/n3k0fi5t/wallet/app/models/approval"
+	mAudit "github.com/n3k0fi5t/wallet/app/models/audit"
+	"github.com/n3k0fi5t/wallet/app/repository/audit"
+	"github.com/n3k0fi5t/wallet/common/logger"
+	"github.com/n3k0fi5t/wallet/common/sql"
+)
+
+const (
+	policyColumns   = "id, walletID, threshold AS `threshold.amount`, currency AS `threshold.currency`, required, approvers, updatedBy, createdMs, updatedMs"
+	intentColumns   = "id, intentID, walletID, requestedBy, toAccountID, amount AS `amount.amount`, currency AS `amount.currency`, holdAccountID, status, required, approvers, holdTradeID, settleTradeID, idempotencyKey, expiresAtMs, createdMs, updatedMs"
+	approvalColumns = "id, intentID, approverID, decision, comment, createdMs"
+
+	queryPolicy  = "SELECT " + policyColumns + " FROM ApprovalPolicy WHERE walletID = ?"
+	lockPolicy   = queryPolicy + " FOR UPDATE"
+	upsertPolicy = "INSERT INTO ApprovalPolicy (walletID, threshold, currency, required, approvers, updatedBy, createdMs, updatedMs) VALUES (?, ?, ?, ?, ?, ?, ?, ?) ON DUPLICATE KEY UPDATE threshold = VALUES(threshold), required = VALUES(required), approvers = VALUES(approvers), updatedBy = VALUES(updatedBy), updatedMs = VALUES(updatedMs)"
+	deletePolicy = "DELETE FROM ApprovalPolicy WHERE walletID = ?"
+
+	insertIntent   = "INSERT INTO TradeIntent (intentID, walletID, requestedBy, toAccountID, amount, currency, holdAccountID, status, required, approvers, holdTradeID, settleTradeID, idempotencyKey, expiresAtMs, createdMs, updatedMs) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"
+	queryIntent    = "SELECT " + intentColumns + " FROM TradeIntent WHERE intentID = ?"
+	lockIntent     = queryIntent + " FOR UPDATE"
+	queryKeyIntent = "SELECT " + intentColumns + " FROM TradeIntent WHERE idempotencyKey = ?"
+	queryIntents   = "SELECT " + intentColumns + " FROM TradeIntent WHERE walletID = ?"
+	queryExpired   = "SELECT " + intentColumns + " FROM TradeIntent WHERE status = 'PENDING' AND expiresAtMs <= ? ORDER BY expiresAtMs LIMIT ?"
+	updateIntent   = "UPDATE TradeIntent SET status = ?, settleTradeID = ?, updatedMs = ? WHERE intentID = ?"
+	condStatus     = " AND status = ?"
+	orderPage      = " ORDER BY id DESC LIMIT ? OFFSET ?"
+	insertApproval = "INSERT INTO TradeApproval (intentID, approverID, decision, comment, createdMs) VALUES (?, ?, ?, ?, ?)"
+	queryApprovals = "SELECT " + approvalColumns + " FROM TradeApproval WHERE intentID = ? ORDER BY id"
+)
+
+func NewApproval(db *sqlx.DB, a audit.Audit) Approval {
+	return &impl{
+		db:    db,
+		audit: a,
+	}
+}
+
+type impl struct {
+	db    *sqlx.DB
+	audit audit.Audit
+}
+
+// lockPolicy returns the locked policy of the wallet, nil if the wallet has none
+func (im *impl) lockPolicy(ctx context.Context, tx *sqlx.Tx, walletID string) (interface{}, error) {
+	policies := []*mApproval.Policy{}
+	if err := tx.SelectContext(ctx, &policies, lockPolicy, walletID); err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("SelectContext failed in Approval.lockPolicy")
+		return nil, err
+	}
+
+	if len(policies) == 0 {
+		return nil, nil
+	}
+	return policies[0], nil
+}
+
+func (im *impl) GetPolicy(ctx context.Context, walletID string) (*mApproval.Policy, error) {
+	policies := []*mApproval.Policy{}
+	if err := sql.Transactx(ctx, im.db, func(tx *sqlx.Tx) error {
+		return tx.SelectContext(ctx, &policies, queryPolicy, walletID)
+	}); err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("SelectContext failed in Approval.GetPolicy")
+		return nil, err
+	}
+
+	if len(policies) == 0 {
+		return nil, ErrPolicyNotExist
+	}
+	return policies[0], nil
+}
+
+func (im *impl) SavePolicy(ctx context.Context, p *mApproval.Policy) error {
+	return sql.TransactCtx(ctx, im.db, func(ctx context.Context, tx *sqlx.Tx) error {
+		before, err := im.lockPolicy(ctx, tx, p.WalletID)
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, upsertPolicy, p.WalletID, p.Threshold.Amount, p.Threshold.Currency, p.Required, p.Approvers, p.UpdatedBy, p.CreatedMs, p.UpdatedMs); err != nil {
+			logger.FromContext(ctx).WithField("err", err).Error("ExecContext failed in Approval.SavePolicy")
+			return err
+		}
+
+		if err := im.audit.Record(ctx, mAudit.Action_CONFIG_CHANGE, "wallet:"+p.WalletID+":policy", before, p); err != nil {
+			logger.FromContext(ctx).WithField("err", err).Error("audit.Record failed in Approval.SavePolicy")
+			return err
+		}
+		return nil
+	})
+}
+
+func (im *impl) DeletePolicy(ctx context.Context, walletID string) error {
+	return sql.TransactCtx(ctx, im.db, func(ctx context.Context, tx *sqlx.Tx) error {
+		before, err := im.lockPolicy(ctx, tx, walletID)
+		if err != nil {
+			return err
+		} else if before == nil {
+			return nil
+		}
+
+		if _, err := tx.ExecContext(ctx, deletePolicy, walletID); err != nil {
+			logger.FromContext(ctx).WithField("err", err).Error("ExecContext failed in Approval.DeletePolicy")
+			return err
+		}
+
+		if err := im.audit.Record(ctx, mAudit.Action_CONFIG_CHANGE, "wallet:"+walletID+":policy", before, nil); err != nil {
+			logger.FromContext(ctx).WithField("err", err).Error("audit.Record failed in Approval.DeletePolicy")
+			return err
+		}
+		return nil
+	})
+}
+
+func (im *impl) CreateIntent(ctx context.Context, i *mApproval.Intent) error {
+	return sql.Transactx(ctx, im.db, func(tx *sqlx.Tx) error {
+		if _, err := tx.ExecContext(ctx, insertIntent, i.IntentID, i.WalletID, i.RequestedBy, i.ToAccountID, i.Amount.Amount, i.Amount.Currency, i.HoldAccountID, i.Status, i.Required, i.Approvers, i.HoldTradeID, i.SettleTradeID, i.IdempotencyKey, i.ExpiresAtMs, i.CreatedMs, i.UpdatedMs); err != nil {
+			logger.FromContext(ctx).WithField("err", err).Error("ExecContext failed in Approval.CreateIntent")
+			return err
+		}
+		return nil
+	})
+}
+
+func (im *impl) GetIntent(ctx context.Context, intentID string) (*mApproval.Intent, error) {
+	intents := []*mApproval.Intent{}
+	if err := im.db.SelectContext(ctx, &intents, queryIntent, intentID); err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("SelectContext failed in Approval.GetIntent")
+		return nil, err
+	}
+
+	if len(intents) == 0 {
+		return nil, ErrIntentNotExist
+	}
+	return intents[0], nil
+}
+
+func (im *impl) GetIdempotentIntent(ctx context.Context, idempotencyKey string) (*mApproval.Intent, error) {
+	intents := []*mApproval.Intent{}
+	if err := sql.Transactx(ctx, im.db, func(tx *sqlx.Tx) error {
+		return tx.SelectContext(ctx, &intents, queryKeyIntent, idempotencyKey)
+	}); err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("SelectContext failed in Approval.GetIdempotentIntent")
+		return nil, err
+	}
+
+	if len(intents) == 0 {
+		return nil, ErrIntentNotExist
+	}
+	return intents[0], nil
+}
+
+func (im *impl) LockIntent(ctx context.Context, intentID string) (*mApproval.Intent, error) {
+	intents := []*mApproval.Intent{}
+	if err := sql.Transactx(ctx, im.db, func(tx *sqlx.Tx) error {
+		return tx.SelectContext(ctx, &intents, lockIntent, intentID)
+	}); err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("SelectContext failed in Approval.LockIntent")
+		return nil, err
+	}
+
+	if len(intents) == 0 {
+		return nil, ErrIntentNotExist
+	}
+	return intents[0], nil
+}
+
+func (im *impl) ListIntents(ctx context.Context, walletID string, status mApproval.Status, offset, limit int) ([]*mApproval.Intent, error) {
+	query := queryIntents
+	args := []interface{}{walletID}
+	if status != "" {
+		query += condStatus
+		args = append(args, status)
+	}
+	args = append(args, limit, offset)
+
+	intents := []*mApproval.Intent{}
+	if err := im.db.SelectContext(ctx, &intents, query+orderPage, args...); err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("SelectContext failed in Approval.ListIntents")
+		return nil, err
+	}
+	return intents, nil
+}
+
+func (im *impl) ListExpired(ctx context.Context, nowMs int64, limit int) ([]*mApproval.Intent, error) {
+	intents := []*mApproval.Intent{}
+	if err := im.db.SelectContext(ctx, &intents, queryExpired, nowMs, limit); err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("SelectContext failed in Approval.ListExpired")
+		return nil, err
+	}
+	return intents, nil
+}
+
+func (im *impl) UpdateIntent(ctx context.Context, i *mApproval.Intent) error {
+	return sql.Transactx(ctx, im.db, func(tx *sqlx.Tx) error {
+		if _, err := tx.ExecContext(ctx, updateIntent, i.Status, i.SettleTradeID, i.UpdatedMs, i.IntentID); err != nil {
+			logger.FromContext(ctx).WithField("err", err).Error("ExecContext failed in Approval.UpdateIntent")
+			return err
+		}
+		return nil
+	})
+}
+
+func (im *impl) CreateApproval(ctx context.Context, a *mApproval.Approval) error {
+	return sql.Transactx(ctx, im.db, func(tx *sqlx.Tx) error {
+		if _, err := tx.ExecContext(ctx, insertApproval, a.IntentID, a.ApproverID, a.Decision, a.Comment, a.CreatedMs); err != nil {
+			logger.FromContext(ctx).WithField("err", err).Error("ExecContext failed in Approval.CreateApproval")
+			return err
+		}
+		return nil
+	})
+}
+
+func (im *impl) ListApprovals(ctx context.Context, intentID string) ([]*mApproval.Approval, error) {
+	approvals := []*mApproval.Approval{}
+	if err := sql.Transactx(ctx, im.db, func(tx *sqlx.Tx) error {
+		return tx.SelectContext(ctx, &approvals, queryApprovals, intentID)
+	}); err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("SelectContext failed in Approval.ListApprovals")
+		return nil, err
+	}
+	return approvals, nil
+}
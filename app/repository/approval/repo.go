@@ -0,0 +1,54 @@
+package approval
+
+import (
+	"context"
+	"fmt"
+
+	mApproval "github.com/n3k0fi5t/wallet/app/models/approval"
+)
+
+var (
+	// ErrPolicyNotExist means the wallet has no approval policy
+	ErrPolicyNotExist = fmt.Errorf("Policy not exist")
+
+	// ErrIntentNotExist means query intent not exist
+	ErrIntentNotExist = fmt.Errorf("Intent not exist")
+)
+
+type Approval interface {
+	// GetPolicy get the approval policy of the wallet
+	GetPolicy(ctx context.Context, walletID string) (*mApproval.Policy, error)
+
+	// SavePolicy sets the approval policy of the wallet, replacing the existing one
+	SavePolicy(ctx context.Context, policy *mApproval.Policy) error
+
+	// DeletePolicy removes the approval policy of the wallet
+	DeletePolicy(ctx context.Context, walletID string) error
+
+	// CreateIntent creates an intent
+	CreateIntent(ctx context.Context, intent *mApproval.Intent) error
+
+	// GetIntent get an intent by ID
+	GetIntent(ctx context.Context, intentID string) (*mApproval.Intent, error)
+
+	// GetIdempotentIntent get the intent held with the idempotency key, ErrIntentNotExist if the key is unused
+	GetIdempotentIntent(ctx context.Context, idempotencyKey string) (*mApproval.Intent, error)
+
+	// LockIntent get an intent and locks it until the transaction carried by context ends
+	LockIntent(ctx context.Context, intentID string) (*mApproval.Intent, error)
+
+	// ListIntents list intents paying out of the wallet, newest first. Empty status for all
+	ListIntents(ctx context.Context, walletID string, status mApproval.Status, offset, limit int) ([]*mApproval.Intent, error)
+
+	// ListExpired list pending intents expired at nowMs, the earliest first
+	ListExpired(ctx context.Context, nowMs int64, limit int) ([]*mApproval.Intent, error)
+
+	// UpdateIntent saves status, settlement and update time of the intent
+	UpdateIntent(ctx context.Context, intent *mApproval.Intent) error
+
+	// CreateApproval records a decision on an intent
+	CreateApproval(ctx context.Context, approval *mApproval.Approval) error
+
+	// ListApprovals list decisions on the intent, the oldest first
+	ListApprovals(ctx context.Context, intentID string) ([]*mApproval.Approval, error)
+}
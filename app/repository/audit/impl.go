@@ -0,0 +1,131 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	mAudit "github.com/n3k0fi5t/wallet/app/models/audit"
+	"github.com/n3k0fi5t/wallet/app/util"
+	"github.com/n3k0fi5t/wallet/common/logger"
+	"github.com/n3k0fi5t/wallet/common/sql"
+)
+
+const (
+	insertAuditLog = "INSERT INTO AuditLog (actorType, actorID, principal, requestID, clientIP, userAgent, action, resource, `before`, `after`, timestampMs) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"
+	queryAuditLog  = "SELECT id, actorType, actorID, principal, requestID, clientIP, userAgent, action, resource, `before`, `after`, timestampMs FROM AuditLog"
+)
+
+var (
+	timeNowMs = util.TimeNowMs
+)
+
+func NewAudit(db *sqlx.DB) Audit {
+	return &impl{
+		db: db,
+	}
+}
+
+type impl struct {
+	db *sqlx.DB
+}
+
+func encode(v interface{}) (string, error) {
+	if v == nil {
+		return "", nil
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+func newEntry(ctx context.Context, action mAudit.Action, resource string, before, after interface{}) (*mAudit.Entry, error) {
+	entry := &mAudit.Entry{
+		ActorType:   mAudit.ActorType_SYSTEM,
+		Action:      action,
+		Resource:    resource,
+		TimestampMs: timeNowMs(),
+	}
+
+	if md := mAudit.MetadataFrom(ctx); md != nil {
+		if md.ActorType != "" {
+			entry.ActorType = md.ActorType
+		}
+		entry.ActorID = md.ActorID
+		entry.Principal = md.Principal
+		entry.RequestID = md.RequestID
+		entry.ClientIP = md.ClientIP
+		entry.UserAgent = md.UserAgent
+	}
+
+	var err error
+	if entry.Before, err = encode(before); err != nil {
+		return nil, err
+	}
+	if entry.After, err = encode(after); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+func (im *impl) Record(ctx context.Context, action mAudit.Action, resource string, before, after interface{}) error {
+	entry, err := newEntry(ctx, action, resource, before, after)
+	if err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("newEntry failed in Audit.Record")
+		return err
+	}
+
+	return sql.Transactx(ctx, im.db, func(tx *sqlx.Tx) error {
+		if _, err := tx.ExecContext(ctx, insertAuditLog, entry.ActorType, entry.ActorID, entry.Principal, entry.RequestID, entry.ClientIP,
+			entry.UserAgent, entry.Action, entry.Resource, entry.Before, entry.After, entry.TimestampMs); err != nil {
+			logger.FromContext(ctx).WithField("err", err).Error("ExecContext failed in Audit.Record")
+			return err
+		}
+		return nil
+	})
+}
+
+func (im *impl) List(ctx context.Context, filter *mAudit.Filter, offset, limit int) ([]*mAudit.Entry, error) {
+	conds := []string{}
+	args := []interface{}{}
+	if filter != nil {
+		if filter.ActorID != "" {
+			conds = append(conds, "actorID = ?")
+			args = append(args, filter.ActorID)
+		}
+		if filter.Action != "" {
+			conds = append(conds, "action = ?")
+			args = append(args, filter.Action)
+		}
+		if filter.Resource != "" {
+			conds = append(conds, "resource = ?")
+			args = append(args, filter.Resource)
+		}
+		if filter.FromMs > 0 {
+			conds = append(conds, "timestampMs >= ?")
+			args = append(args, filter.FromMs)
+		}
+		if filter.ToMs > 0 {
+			conds = append(conds, "timestampMs < ?")
+			args = append(args, filter.ToMs)
+		}
+	}
+
+	query := queryAuditLog
+	if len(conds) > 0 {
+		query += " WHERE " + strings.Join(conds, " AND ")
+	}
+	query += " ORDER BY id DESC LIMIT ? OFFSET ?"
+	args = append(args, limit, offset)
+
+	entries := []*mAudit.Entry{}
+	if err := im.db.SelectContext(ctx, &entries, query, args...); err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("SelectContext failed in Audit.List")
+		return nil, err
+	}
+	return entries, nil
+}
@@ -0,0 +1,16 @@
+package audit
+
+import (
+	"context"
+
+	mAudit "github.com/n3k0fi5t/wallet/app/models/audit"
+)
+
+type Audit interface {
+	// Record appends an entry with the metadata carried by context, before and after are encoded as JSON.
+	// It joins the transaction carried by context so that the entry commits or rollbacks with the change
+	Record(ctx context.Context, action mAudit.Action, resource string, before, after interface{}) error
+
+	// List query entries matching the filter, newest first
+	List(ctx context.Context, filter *mAudit.Filter, offset, limit int) ([]*mAudit.Entry, error)
+}
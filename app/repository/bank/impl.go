@@ -2,34 +2,84 @@ package bank
 
 import (
 	"context"
+	"fmt"
 
+	"github.com/go-sql-driver/mysql"
 	"github.com/jmoiron/sqlx"
+	"github.com/n3k0fi5t/wallet/app/broker"
+	mAudit "github.com/n3k0fi5t/wallet/app/models/audit"
 	mBank "github.com/n3k0fi5t/wallet/app/models/bank"
+	mEvent "github.com/n3k0fi5t/wallet/app/models/event"
+	"github.com/n3k0fi5t/wallet/app/money"
+	"github.com/n3k0fi5t/wallet/app/repository/audit"
+	"github.com/n3k0fi5t/wallet/app/repository/outbox"
 	"github.com/n3k0fi5t/wallet/app/util"
+	"github.com/n3k0fi5t/wallet/common/logger"
 	"github.com/n3k0fi5t/wallet/common/sql"
-	"github.com/sirupsen/logrus"
 	"golang.org/x/sync/singleflight"
 )
 
 const (
-	queryAccount         = "SELECT id, accountID, balance FROM account WHERE accountID = ?"
-	queryBalance         = "SELECT balance FROM account WHERE accountID = ?"
+	accountColumns       = "id, accountID, status, type, COALESCE(ownerID, '') AS ownerID, name, allowNegative, " + moneyColumns
+	moneyColumns         = "balance AS `balance.amount`, currency AS `balance.currency`, creditLimit AS `creditLimit.amount`, currency AS `creditLimit.currency`"
+	queryAccount         = "SELECT " + accountColumns + " FROM account WHERE accountID = ?"
+	queryOwnedAccounts   = "SELECT " + accountColumns + " FROM account WHERE ownerID = ? ORDER BY id"
+	insertAccount        = "INSERT INTO account (accountID, balance, status, type, ownerID, name, currency) VALUES (?, ?, ?, ?, NULLIF(?, ''), ?, ?)"
+	updateAccountName    = "UPDATE account SET name = ? WHERE accountID = ?"
+	queryBalance         = "SELECT balance AS `balance.amount`, currency AS `balance.currency` FROM account WHERE accountID = ?"
+	queryFunds           = "SELECT allowNegative, " + moneyColumns + " FROM account WHERE accountID = ? FOR UPDATE"
+	queryAccountStatus   = "SELECT status FROM account WHERE accountID = ?"
 	updateBalance        = "UPDATE account SET balance = balance + ? WHERE accountID = ?"
-	insertTransactionLog = "INSERT INTO TransactionLog (accountID, action, amount, timestampMs, tradeID) VALUES (?, ?, ?, ?, ?)"
+	debitBalance         = "UPDATE account SET balance = balance - ? WHERE accountID = ? AND (allowNegative OR balance - ? >= -creditLimit)"
+	updateCreditLimit    = "UPDATE account SET creditLimit = ? WHERE accountID = ? AND balance >= -?"
+	queryOverdrawn       = "SELECT " + accountColumns + " FROM account WHERE balance < 0 AND NOT allowNegative ORDER BY balance, id LIMIT ? OFFSET ?"
+	querySystemAccounts  = "SELECT " + accountColumns + " FROM account WHERE type = ? ORDER BY currency, accountID"
+	updateAccountStatus  = "UPDATE account SET status = ? WHERE accountID = ?"
+	insertTransactionLog = "INSERT INTO TransactionLog (accountID, action, amount, currency, timestampMs, tradeID) VALUES (?, ?, ?, ?, ?, ?)"
+	insertTradeReversal  = "INSERT INTO TradeReversal (tradeID, reversalTradeID, timestampMs) VALUES (?, ?, ?)"
+	insertIdempotency    = "INSERT INTO TradeIdempotency (idempotencyKey, tradeID, fromAccountID, toAccountID, amount, timestampMs) VALUES (?, ?, ?, ?, ?, ?)"
+	queryIdempotency     = "SELECT tradeID, fromAccountID, toAccountID, amount FROM TradeIdempotency WHERE idempotencyKey = ?"
+	lockIdempotency      = "SELECT tradeID FROM TradeIdempotency WHERE idempotencyKey = ? LOCK IN SHARE MODE"
+	entryColumns         = "accountID, action, amount AS `amount.amount`, currency AS `amount.currency`, timestampMs AS timeMs, tradeID"
+	queryTransactions    = "SELECT " + entryColumns + " FROM TransactionLog WHERE accountID = ? ORDER BY id DESC LIMIT ? OFFSET ?"
+	queryTradeLogs       = "SELECT " + entryColumns + " FROM TransactionLog WHERE tradeID = ? ORDER BY id"
+	queryLiabilities     = "SELECT currency, SUM(GREATEST(balance, 0)) AS amount FROM account WHERE type <> ? AND type <> ? GROUP BY currency"
+	queryOverdrafts      = "SELECT currency, -SUM(balance) AS amount FROM account WHERE balance < 0 AND NOT allowNegative GROUP BY currency"
+	insertTradeExchange  = "INSERT INTO TradeExchange (tradeID, quoteID, fromCurrency, toCurrency, sellAmount, buyAmount, rate, spreadBps, timestampMs) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)"
+	// queryUpdates takes the newest entries after the given one
+	queryUpdates = "SELECT id, " + entryColumns + " FROM TransactionLog WHERE accountID = ? AND id > ? ORDER BY id DESC LIMIT ?"
+	// queryTradeEntries takes every entry of the trades in booking order, their counterparties are among them
+	queryTradeEntries = "SELECT id, " + entryColumns + " FROM TransactionLog WHERE tradeID IN (?) ORDER BY id"
+)
+
+const (
+	// mysql error number of duplicate entry for key
+	errDuplicateEntry = 1062
+	// mysql error number of SIGNAL, raised by the trigger keeping balances within credit lines
+	errSignalException = 1644
 )
 
 var (
 	timeNowMs = util.TimeNowMs
+
+	// errIdempotentReplay means the dealing was executed with the same idempotency key before
+	errIdempotentReplay = fmt.Errorf("idempotent replay")
 )
 
-func NewBank(db *sqlx.DB) Bank {
+func NewBank(db *sqlx.DB, a audit.Audit, o outbox.Outbox, br broker.Broker) Bank {
 	return &impl{
-		db: db,
+		db:     db,
+		audit:  a,
+		outbox: o,
+		broker: br,
 	}
 }
 
 type impl struct {
 	db           *sqlx.DB
+	audit        audit.Audit
+	outbox       outbox.Outbox
+	broker       broker.Broker
 	singleflight singleflight.Group
 }
 
@@ -52,85 +102,397 @@ func createTradingLog(dealing *mBank.Dealing, tradeID string, timestamp int64) (
 	return debit, credit
 }
 
-func (im *impl) checkAccountBalance(ctx context.Context, tx *sqlx.Tx, accountID string, amount int64) (bool, error) {
-	var balance int64
-	if err := tx.Get(&balance, queryBalance, accountID); err != nil {
-		return false, err
+func (im *impl) getBalance(ctx context.Context, tx *sqlx.Tx, accountID string) (money.Money, error) {
+	account := mBank.Account{}
+	if err := tx.GetContext(ctx, &account, queryBalance, accountID); err != nil {
+		return money.Money{}, err
+	}
+	return account.Balance, nil
+}
+
+// getFunds locks the account for the trade and returns its balance and credit limit
+func (im *impl) getFunds(ctx context.Context, tx *sqlx.Tx, accountID string) (*mBank.Account, error) {
+	account := &mBank.Account{AccountID: accountID}
+	if err := tx.GetContext(ctx, account, queryFunds, accountID); err != nil {
+		return nil, err
+	}
+	return account, nil
+}
+
+func (im *impl) checkAccountStatus(ctx context.Context, tx *sqlx.Tx, accountID string) error {
+	var status mBank.AccountStatus
+	if err := tx.GetContext(ctx, &status, queryAccountStatus, accountID); err != nil {
+		return err
 	}
 
-	if balance < amount {
-		return false, nil
+	if status == mBank.AccountStatus_FROZEN {
+		return ErrAccountFrozen
 	}
-	return true, nil
+	return nil
+}
+
+// checkCurrency makes sure money stays in one currency, system accounts included as they are per currency
+func checkCurrency(dealing *mBank.Dealing, from, to *mBank.Account) error {
+	if from.Currency() != dealing.Amount.Currency || to.Currency() != dealing.Amount.Currency {
+		return ErrCurrencyMismatch
+	}
+	return nil
 }
 
 func (im *impl) updateBalance(ctx context.Context, tx *sqlx.Tx, accountID string, amount int64) error {
-	res, err := tx.ExecContext(ctx, updateBalance, amount, accountID)
-	if err != nil {
-		logrus.WithField("err", err).Error("ExecContext failed")
+	// debits never take the balance below the credit line whatever was read before, unless the account may go negative
+	query, args := updateBalance, []interface{}{amount, accountID}
+	if amount < 0 {
+		query, args = debitBalance, []interface{}{-amount, accountID, -amount}
+	}
+
+	res, err := tx.ExecContext(ctx, query, args...)
+	if e, ok := err.(*mysql.MySQLError); ok && e.Number == errSignalException {
+		return ErrBalanceNotEnough
+	} else if err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("ExecContext failed")
 		return err
 	}
 
 	affected, err := res.RowsAffected()
 	if err != nil {
-		logrus.WithField("err", err).Error("RowsAffected failed")
+		logger.FromContext(ctx).WithField("err", err).Error("RowsAffected failed")
 		return err
 	}
 
-	if affected != 1 {
-		logrus.WithField("affected", affected).Error("unexpected affected rows")
+	if affected != 1 && amount < 0 {
+		return ErrBalanceNotEnough
+	} else if affected != 1 {
+		logger.FromContext(ctx).WithField("affected", affected).Error("unexpected affected rows")
 		return ErrUpdateBalance
 	}
 	return nil
 }
 
-func (im *impl) logTrading(ctx context.Context, tx *sqlx.Tx, dealing *mBank.Dealing, tradeID string, timestampMs int64) error {
+// logTrading writes the double entries and returns their IDs in the trade log
+func (im *impl) logTrading(ctx context.Context, tx *sqlx.Tx, dealing *mBank.Dealing, tradeID string, timestampMs int64) (debitID, creditID int64, err error) {
 	debit, credit := createTradingLog(dealing, tradeID, timestampMs)
-	if _, err := tx.ExecContext(ctx, insertTransactionLog, debit.AccountID, debit.Action, debit.Amount, debit.TimestampMs, debit.TradeID); err != nil {
-		logrus.WithField("err", err).Error("ExecContext failed")
-		return err
+	ids := []int64{}
+	for _, entry := range []*mBank.Transaction{debit, credit} {
+		res, err := tx.ExecContext(ctx, insertTransactionLog, entry.AccountID, entry.Action, entry.Amount.Amount, entry.Amount.Currency, entry.TimestampMs, entry.TradeID)
+		if err != nil {
+			logger.FromContext(ctx).WithField("err", err).Error("ExecContext failed")
+			return 0, 0, err
+		}
+
+		id, err := res.LastInsertId()
+		if err != nil {
+			logger.FromContext(ctx).WithField("err", err).Error("LastInsertId failed")
+			return 0, 0, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids[0], ids[1], nil
+}
+
+// notifyUpdates hands balance changes of the trade to subscribers once the transaction commits
+func (im *impl) notifyUpdates(ctx context.Context, dealing *mBank.Dealing, tradeID string, entryIDs map[string]int64, balancesAfter map[string]money.Money, timestampMs int64) {
+	updates := []*mBank.Update{}
+	for _, accountID := range []string{dealing.FromAccountID, dealing.ToAccountID} {
+		if mBank.IsSystemAccount(accountID) {
+			continue
+		}
+
+		u := &mBank.Update{
+			ID:             entryIDs[accountID],
+			AccountID:      accountID,
+			TradeID:        tradeID,
+			CounterpartyID: dealing.ToAccountID,
+			Action:         mBank.Action_DECREASE,
+			Amount:         dealing.Amount,
+			BalanceAfter:   balancesAfter[accountID],
+			TimestampMs:    timestampMs,
+		}
+		if accountID == dealing.ToAccountID {
+			u.CounterpartyID = dealing.FromAccountID
+			u.Action = mBank.Action_INCREASE
+		}
+		updates = append(updates, u)
 	}
 
-	if _, err := tx.ExecContext(ctx, insertTransactionLog, credit.AccountID, credit.Action, credit.Amount, credit.TimestampMs, credit.TradeID); err != nil {
-		logrus.WithField("err", err).Error("ExecContext failed")
+	sql.AfterCommit(ctx, func() {
+		im.broker.Publish(updates...)
+	})
+}
+
+func (im *impl) logReversal(ctx context.Context, tx *sqlx.Tx, reversedTradeID, tradeID string, timestampMs int64) error {
+	if _, err := tx.ExecContext(ctx, insertTradeReversal, reversedTradeID, tradeID, timestampMs); err != nil {
+		if e, ok := err.(*mysql.MySQLError); ok && e.Number == errDuplicateEntry {
+			return ErrTradeAlreadyReversed
+		}
+		logger.FromContext(ctx).WithField("err", err).Error("ExecContext failed")
 		return err
 	}
+	return nil
+}
 
+func (im *impl) logIdempotency(ctx context.Context, tx *sqlx.Tx, dealing *mBank.Dealing, tradeID string, timestampMs int64) error {
+	if _, err := tx.ExecContext(ctx, insertIdempotency, dealing.IdempotencyKey, tradeID, dealing.FromAccountID, dealing.ToAccountID, dealing.Amount.Amount, timestampMs); err != nil {
+		if e, ok := err.(*mysql.MySQLError); ok && e.Number == errDuplicateEntry {
+			return errIdempotentReplay
+		}
+		logger.FromContext(ctx).WithField("err", err).Error("ExecContext failed")
+		return err
+	}
 	return nil
 }
 
+// replayedTrade returns the tradeID executed with the same idempotency key, the dealing must be the same
+func (im *impl) replayedTrade(ctx context.Context, dealing *mBank.Dealing) (string, error) {
+	record := struct {
+		TradeID       string `db:"tradeID"`
+		FromAccountID string `db:"fromAccountID"`
+		ToAccountID   string `db:"toAccountID"`
+		Amount        int64  `db:"amount"`
+	}{}
+	if err := im.db.GetContext(ctx, &record, queryIdempotency, dealing.IdempotencyKey); err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("GetContext failed in Bank.replayedTrade")
+		return "", err
+	}
+
+	// the accounts fix the currency of the amount
+	if record.FromAccountID != dealing.FromAccountID || record.ToAccountID != dealing.ToAccountID || record.Amount != dealing.Amount.Amount {
+		return "", ErrIdempotencyKeyReused
+	}
+	return record.TradeID, nil
+}
+
+// tradeEvent builds the event of the trade from the point of view of accountID, nil for system accounts
+func tradeEvent(eventType mEvent.Type, dealing *mBank.Dealing, accountID string, timestampMs int64) (*mEvent.Event, error) {
+	if mBank.IsSystemAccount(accountID) {
+		return nil, nil
+	}
+
+	eventID, err := util.GetUUIDv4()
+	if err != nil {
+		return nil, err
+	}
+
+	trade := &mEvent.Trade{
+		CounterpartyID: dealing.ToAccountID,
+		Direction:      mEvent.Direction_DEBIT,
+		Amount:         dealing.Amount,
+	}
+	if accountID == dealing.ToAccountID {
+		trade.CounterpartyID = dealing.FromAccountID
+		trade.Direction = mEvent.Direction_CREDIT
+	}
+
+	return &mEvent.Event{
+		EventID:     eventID,
+		Type:        eventType,
+		AccountID:   accountID,
+		TimestampMs: timestampMs,
+		Trade:       trade,
+	}, nil
+}
+
+func (im *impl) publishCompleted(ctx context.Context, dealing *mBank.Dealing, tradeID string, balancesAfter map[string]money.Money, timestampMs int64) error {
+	events := []*mEvent.Event{}
+	for _, accountID := range []string{dealing.FromAccountID, dealing.ToAccountID} {
+		e, err := tradeEvent(mEvent.Type_TRADE_COMPLETED, dealing, accountID, timestampMs)
+		if err != nil {
+			return err
+		} else if e == nil {
+			continue
+		}
+
+		e.Trade.TradeID = tradeID
+		balanceAfter := balancesAfter[accountID]
+		e.Trade.BalanceAfter = &balanceAfter
+		events = append(events, e)
+	}
+
+	return im.outbox.Add(ctx, events...)
+}
+
+// overdraftEvent builds the event of the account crossing zero by the trade, nil if it does not
+func overdraftEvent(account *mBank.Account, tradeID string, balanceAfter money.Money, timestampMs int64) (*mEvent.Event, error) {
+	eventType := mEvent.Type_ACCOUNT_OVERDRAWN
+	switch {
+	case account.AllowNegative:
+		return nil, nil
+	case !account.Balance.IsNegative() && balanceAfter.IsNegative():
+	case account.Balance.IsNegative() && !balanceAfter.IsNegative():
+		eventType = mEvent.Type_OVERDRAFT_REPAID
+	default:
+		return nil, nil
+	}
+
+	eventID, err := util.GetUUIDv4()
+	if err != nil {
+		return nil, err
+	}
+
+	return &mEvent.Event{
+		EventID:     eventID,
+		Type:        eventType,
+		AccountID:   account.AccountID,
+		TimestampMs: timestampMs,
+		Overdraft: &mEvent.Overdraft{
+			TradeID:     tradeID,
+			Balance:     balanceAfter,
+			CreditLimit: account.CreditLimit,
+		},
+	}, nil
+}
+
+// publishOverdrafts reports accounts the trade takes below zero or back, interest and fees are charged by their consumers
+func (im *impl) publishOverdrafts(ctx context.Context, accounts []*mBank.Account, tradeID string, balancesAfter map[string]money.Money, timestampMs int64) error {
+	events := []*mEvent.Event{}
+	for _, account := range accounts {
+		e, err := overdraftEvent(account, tradeID, balancesAfter[account.AccountID], timestampMs)
+		if err != nil {
+			return err
+		} else if e != nil {
+			events = append(events, e)
+		}
+	}
+
+	if len(events) == 0 {
+		return nil
+	}
+	return im.outbox.Add(ctx, events...)
+}
+
+// publishFailed writes TradeFailed of the payer outside the failed transaction
+func (im *impl) publishFailed(ctx context.Context, dealing *mBank.Dealing, reason error) {
+	e, err := tradeEvent(mEvent.Type_TRADE_FAILED, dealing, dealing.FromAccountID, timeNowMs())
+	if err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("tradeEvent failed in Bank.publishFailed")
+		return
+	} else if e == nil {
+		return
+	}
+
+	e.Trade.Reason = reason.Error()
+	if err := im.outbox.Add(sql.WithoutTx(ctx), e); err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("outbox.Add failed in Bank.publishFailed")
+	}
+}
+
 func (im *impl) trade(ctx context.Context, tx *sqlx.Tx, dealing *mBank.Dealing) (string, error) {
 	nowMs := timeNowMs()
 	tradeID, err := util.GetUUIDv4()
 	if err != nil {
-		logrus.WithField("err", err).Error("GetUUIDv4 failed in Bank.trade")
+		logger.FromContext(ctx).WithField("err", err).Error("GetUUIDv4 failed in Bank.trade")
 		return "", err
 	}
 
-	if ok, err := im.checkAccountBalance(ctx, tx, dealing.FromAccountID, dealing.Amount); err != nil {
-		logrus.WithField("err", err).Error("checkAccountBalance failed in Bank.trade")
+	// claim the idempotency key first, a concurrent retry waits here until the first one finishes
+	if dealing.IdempotencyKey != "" {
+		if err := im.logIdempotency(ctx, tx, dealing, tradeID, nowMs); err != nil {
+			return "", err
+		}
+	}
+
+	if err := im.book(ctx, tx, dealing, tradeID, nowMs); err != nil {
 		return "", err
-	} else if !ok {
-		return "", ErrBalanceNotEnough
+	}
+
+	if dealing.ReversalOf != "" {
+		if err := im.logReversal(ctx, tx, dealing.ReversalOf, tradeID, nowMs); err != nil {
+			return "", err
+		}
+	}
+
+	return tradeID, nil
+}
+
+// book moves the money of one leg of the trade, writes its double entries and publishes the balance changes
+func (im *impl) book(ctx context.Context, tx *sqlx.Tx, dealing *mBank.Dealing, tradeID string, nowMs int64) error {
+	// frozen account can not pay out
+	if err := im.checkAccountStatus(ctx, tx, dealing.FromAccountID); err != nil {
+		return err
+	}
+
+	from, err := im.getFunds(ctx, tx, dealing.FromAccountID)
+	if err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("getFunds failed in Bank.book")
+		return err
+	}
+	to, err := im.getFunds(ctx, tx, dealing.ToAccountID)
+	if err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("getFunds failed in Bank.book")
+		return err
+	}
+
+	if err := checkCurrency(dealing, from, to); err != nil {
+		return err
+	}
+
+	// the payer may go below zero within its credit line
+	if from.Available().Amount < dealing.Amount.Amount {
+		return ErrBalanceNotEnough
+	}
+	fromBalance, toBalance := from.Balance, to.Balance
+
+	// balances never wrap around, the column would reject them anyway
+	fromAfter, err := fromBalance.Sub(dealing.Amount)
+	if err != nil {
+		return ErrBalanceOverflow
+	}
+	toAfter, err := toBalance.Add(dealing.Amount)
+	if err != nil {
+		return ErrBalanceOverflow
 	}
 
 	// doing transfer
-	if err = im.updateBalance(ctx, tx, dealing.FromAccountID, -1*dealing.Amount); err != nil {
-		logrus.WithField("err", err).Error("updateBalance failed in Bank.trade")
-		return "", err
+	if err = im.updateBalance(ctx, tx, dealing.FromAccountID, -1*dealing.Amount.Amount); err == ErrBalanceNotEnough {
+		return err
+	} else if err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("updateBalance failed in Bank.book")
+		return err
 	}
-	if err = im.updateBalance(ctx, tx, dealing.ToAccountID, dealing.Amount); err != nil {
-		logrus.WithField("err", err).Error("updateBalance failed in Bank.trade")
-		return "", err
+	if err = im.updateBalance(ctx, tx, dealing.ToAccountID, dealing.Amount.Amount); err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("updateBalance failed in Bank.book")
+		return err
 	}
 
 	// write transaction log (double entries)
-	if err := im.logTrading(ctx, tx, dealing, tradeID, nowMs); err != nil {
-		logrus.WithField("err", err).Error("logTransaction failed in Bank.trade")
-		return "", err
+	debitID, creditID, err := im.logTrading(ctx, tx, dealing, tradeID, nowMs)
+	if err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("logTransaction failed in Bank.book")
+		return err
 	}
 
-	return tradeID, nil
+	// audit balance changes within the same transaction
+	before := map[string]money.Money{
+		dealing.FromAccountID: fromBalance,
+		dealing.ToAccountID:   toBalance,
+	}
+	after := map[string]money.Money{
+		dealing.FromAccountID: fromAfter,
+		dealing.ToAccountID:   toAfter,
+	}
+	if err := im.audit.Record(ctx, mAudit.Action_TRADE, "trade:"+tradeID, before, after); err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("audit.Record failed in Bank.book")
+		return err
+	}
+
+	// events are relayed to downstream after commit
+	if err := im.publishCompleted(ctx, dealing, tradeID, after, nowMs); err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("publishCompleted failed in Bank.book")
+		return err
+	}
+	if err := im.publishOverdrafts(ctx, []*mBank.Account{from, to}, tradeID, after, nowMs); err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("publishOverdrafts failed in Bank.book")
+		return err
+	}
+
+	entryIDs := map[string]int64{
+		dealing.FromAccountID: debitID,
+		dealing.ToAccountID:   creditID,
+	}
+	im.notifyUpdates(ctx, dealing, tradeID, entryIDs, after, nowMs)
+
+	return nil
 }
 
 func (im *impl) Trade(ctx context.Context, dealing *mBank.Dealing) (string, error) {
@@ -140,11 +502,51 @@ func (im *impl) Trade(ctx context.Context, dealing *mBank.Dealing) (string, erro
 	}
 
 	tradeID := ""
-	if err := sql.Transactx(ctx, im.db, func(tx *sqlx.Tx) error {
+	if err := sql.TransactCtx(ctx, im.db, func(ctx context.Context, tx *sqlx.Tx) error {
 		tID, err := im.trade(ctx, tx, dealing)
 		tradeID = tID
 		return err
+	}); err == errIdempotentReplay {
+		return im.replayedTrade(ctx, dealing)
+	} else if err != nil {
+		im.publishFailed(ctx, dealing, err)
+		return "", err
+	}
+
+	return tradeID, nil
+}
+
+func (im *impl) Exchange(ctx context.Context, exchange *mBank.Exchange) (string, error) {
+	if !exchange.IsValid() {
+		return "", ErrInvalidDealing
+	}
+
+	tradeID := ""
+	if err := sql.TransactCtx(ctx, im.db, func(ctx context.Context, tx *sqlx.Tx) error {
+		nowMs := timeNowMs()
+		tID, err := util.GetUUIDv4()
+		if err != nil {
+			logger.FromContext(ctx).WithField("err", err).Error("GetUUIDv4 failed in Bank.Exchange")
+			return err
+		}
+
+		// both legs share the tradeID, so the trade shows the two currencies it converts between
+		for _, leg := range []*mBank.Dealing{exchange.Sell, exchange.Buy} {
+			if err := im.book(ctx, tx, leg, tID, nowMs); err != nil {
+				return err
+			}
+		}
+
+		if _, err := tx.ExecContext(ctx, insertTradeExchange, tID, exchange.QuoteID, exchange.Sell.Amount.Currency, exchange.Buy.Amount.Currency,
+			exchange.Sell.Amount.Amount, exchange.Buy.Amount.Amount, exchange.Rate, exchange.SpreadBps, nowMs); err != nil {
+			logger.FromContext(ctx).WithField("err", err).Error("ExecContext failed in Bank.Exchange")
+			return err
+		}
+
+		tradeID = tID
+		return nil
 	}); err != nil {
+		im.publishFailed(ctx, exchange.Sell, err)
 		return "", err
 	}
 
@@ -165,8 +567,7 @@ func (im *impl) getAccount(ctx context.Context, tx *sqlx.Tx, accountID string) (
 }
 
 func (im *impl) GetAccount(ctx context.Context, accountID string) (*mBank.Account, error) {
-	// use singleflight to avoid spike query for the same accountID
-	val, err, _ := im.singleflight.Do(accountID, func() (interface{}, error) {
+	read := func() (interface{}, error) {
 		var acc *mBank.Account
 		if err := sql.Transactx(ctx, im.db, func(tx *sqlx.Tx) error {
 			res, e := im.getAccount(ctx, tx, accountID)
@@ -180,7 +581,19 @@ func (im *impl) GetAccount(ctx context.Context, accountID string) (*mBank.Accoun
 			return nil, err
 		}
 		return acc, nil
-	})
+	}
+
+	// a read in a transaction must see the transaction's own view, it is never shared with other callers
+	if sql.InTx(ctx) {
+		acc, err := read()
+		if err != nil {
+			return nil, err
+		}
+		return acc.(*mBank.Account), nil
+	}
+
+	// use singleflight to avoid spike query for the same accountID
+	val, err, _ := im.singleflight.Do(accountID, read)
 
 	// singleflight.Do only return function error. In this case, type conversion failure happends while function return nil
 	res, ok := val.(*mBank.Account)
@@ -190,3 +603,283 @@ func (im *impl) GetAccount(ctx context.Context, accountID string) (*mBank.Accoun
 
 	return res, nil
 }
+
+func (im *impl) CreateAccount(ctx context.Context, account *mBank.Account) error {
+	if account.Balance.Currency == "" {
+		account.Balance.Currency = money.DefaultCurrency
+	}
+	account.CreditLimit.Currency = account.Balance.Currency
+
+	return sql.TransactCtx(ctx, im.db, func(ctx context.Context, tx *sqlx.Tx) error {
+		if _, err := tx.ExecContext(ctx, insertAccount, account.AccountID, account.Balance.Amount, account.Status, account.Type, account.OwnerID, account.Name, account.Currency()); err != nil {
+			logger.FromContext(ctx).WithField("err", err).Error("ExecContext failed in Bank.CreateAccount")
+			return err
+		}
+
+		// opening a wallet is a config change of its owner, escrow, hold and other system owned accounts are not
+		if account.OwnerID == "" {
+			return nil
+		}
+		if err := im.audit.Record(ctx, mAudit.Action_CONFIG_CHANGE, "account:"+account.AccountID, nil, account); err != nil {
+			logger.FromContext(ctx).WithField("err", err).Error("audit.Record failed in Bank.CreateAccount")
+			return err
+		}
+		return nil
+	})
+}
+
+func (im *impl) ListAccounts(ctx context.Context, ownerID string) ([]*mBank.Account, error) {
+	accounts := []*mBank.Account{}
+	if err := im.db.SelectContext(ctx, &accounts, queryOwnedAccounts, ownerID); err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("SelectContext failed in Bank.ListAccounts")
+		return nil, err
+	}
+	return accounts, nil
+}
+
+func (im *impl) RenameAccount(ctx context.Context, accountID, name string) error {
+	return sql.TransactCtx(ctx, im.db, func(ctx context.Context, tx *sqlx.Tx) error {
+		account, err := im.getAccount(ctx, tx, accountID)
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, updateAccountName, name, accountID); err != nil {
+			logger.FromContext(ctx).WithField("err", err).Error("ExecContext failed in Bank.RenameAccount")
+			return err
+		}
+
+		if err := im.audit.Record(ctx, mAudit.Action_CONFIG_CHANGE, "account:"+accountID, account.Name, name); err != nil {
+			logger.FromContext(ctx).WithField("err", err).Error("audit.Record failed in Bank.RenameAccount")
+			return err
+		}
+		return nil
+	})
+}
+
+func (im *impl) ListTransactions(ctx context.Context, accountID string, offset, limit int) ([]*mBank.Transaction, error) {
+	txs := []*mBank.Transaction{}
+	if err := im.db.SelectContext(ctx, &txs, queryTransactions, accountID, limit, offset); err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("SelectContext failed in Bank.ListTransactions")
+		return nil, err
+	}
+
+	return txs, nil
+}
+
+func (im *impl) GetTrade(ctx context.Context, tradeID string) ([]*mBank.Transaction, error) {
+	txs := []*mBank.Transaction{}
+	if err := im.db.SelectContext(ctx, &txs, queryTradeLogs, tradeID); err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("SelectContext failed in Bank.GetTrade")
+		return nil, err
+	}
+
+	if len(txs) == 0 {
+		return nil, ErrTradeNotExist
+	}
+
+	return txs, nil
+}
+
+func (im *impl) GetIdempotentTrade(ctx context.Context, idempotencyKey string) (string, error) {
+	tradeIDs := []string{}
+	if err := sql.TransactCtx(ctx, im.db, func(ctx context.Context, tx *sqlx.Tx) error {
+		// a locking read sees the key committed by a concurrent request, unlike the snapshot of the transaction
+		if err := tx.SelectContext(ctx, &tradeIDs, lockIdempotency, idempotencyKey); err != nil {
+			logger.FromContext(ctx).WithField("err", err).Error("SelectContext failed in Bank.GetIdempotentTrade")
+			return err
+		}
+		return nil
+	}); err != nil {
+		return "", err
+	}
+
+	if len(tradeIDs) == 0 {
+		return "", ErrTradeNotExist
+	}
+	return tradeIDs[0], nil
+}
+
+func (im *impl) SetAccountStatus(ctx context.Context, accountID string, status mBank.AccountStatus) error {
+	return sql.TransactCtx(ctx, im.db, func(ctx context.Context, tx *sqlx.Tx) error {
+		// make sure the account exists, since rows affected is 0 while status does not change
+		account, err := im.getAccount(ctx, tx, accountID)
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, updateAccountStatus, status, accountID); err != nil {
+			logger.FromContext(ctx).WithField("err", err).Error("ExecContext failed in Bank.SetAccountStatus")
+			return err
+		}
+
+		if err := im.audit.Record(ctx, mAudit.Action_ACCOUNT_STATUS, "account:"+accountID, account.Status, status); err != nil {
+			logger.FromContext(ctx).WithField("err", err).Error("audit.Record failed in Bank.SetAccountStatus")
+			return err
+		}
+		return nil
+	})
+}
+
+func (im *impl) SetCreditLimit(ctx context.Context, accountID string, limit money.Money) error {
+	return sql.TransactCtx(ctx, im.db, func(ctx context.Context, tx *sqlx.Tx) error {
+		account, err := im.getAccount(ctx, tx, accountID)
+		if err != nil {
+			return err
+		} else if account.Currency() != limit.Currency {
+			return ErrCurrencyMismatch
+		} else if account.CreditLimit == limit {
+			return nil
+		}
+
+		// the update checks the balance again, a concurrent trade may overdraw the account after it is read
+		res, err := tx.ExecContext(ctx, updateCreditLimit, limit.Amount, accountID, limit.Amount)
+		if e, ok := err.(*mysql.MySQLError); ok && e.Number == errSignalException {
+			return ErrCreditLimitInUse
+		} else if err != nil {
+			logger.FromContext(ctx).WithField("err", err).Error("ExecContext failed in Bank.SetCreditLimit")
+			return err
+		}
+
+		affected, err := res.RowsAffected()
+		if err != nil {
+			logger.FromContext(ctx).WithField("err", err).Error("RowsAffected failed in Bank.SetCreditLimit")
+			return err
+		} else if affected != 1 {
+			return ErrCreditLimitInUse
+		}
+
+		if err := im.audit.Record(ctx, mAudit.Action_CREDIT_LIMIT, "account:"+accountID, account.CreditLimit, limit); err != nil {
+			logger.FromContext(ctx).WithField("err", err).Error("audit.Record failed in Bank.SetCreditLimit")
+			return err
+		}
+		return nil
+	})
+}
+
+func (im *impl) ListOverdrawn(ctx context.Context, offset, limit int) ([]*mBank.Account, error) {
+	accounts := []*mBank.Account{}
+	if err := im.db.SelectContext(ctx, &accounts, queryOverdrawn, limit, offset); err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("SelectContext failed in Bank.ListOverdrawn")
+		return nil, err
+	}
+	return accounts, nil
+}
+
+func (im *impl) GetOverdrafts(ctx context.Context) ([]money.Money, error) {
+	totals := []money.Money{}
+	if err := im.db.SelectContext(ctx, &totals, queryOverdrafts); err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("SelectContext failed in Bank.GetOverdrafts")
+		return nil, err
+	}
+
+	return totals, nil
+}
+
+func (im *impl) ListSystemAccounts(ctx context.Context) ([]*mBank.Account, error) {
+	accounts := []*mBank.Account{}
+	if err := im.db.SelectContext(ctx, &accounts, querySystemAccounts, mBank.AccountType_SYSTEM); err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("SelectContext failed in Bank.ListSystemAccounts")
+		return nil, err
+	}
+	return accounts, nil
+}
+
+func (im *impl) GetLiabilities(ctx context.Context) ([]money.Money, error) {
+	// system and FX accounts are the money of the bank, their balances are not owed to anyone.
+	// Overdrawn accounts owe the bank instead, they are in GetOverdrafts
+	totals := []money.Money{}
+	if err := im.db.SelectContext(ctx, &totals, queryLiabilities, mBank.AccountType_SYSTEM, mBank.AccountType_FX); err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("SelectContext failed in Bank.GetLiabilities")
+		return nil, err
+	}
+
+	return totals, nil
+}
+
+// attachCounterparties sets the counterparty of each update to the other entry of its dealing
+func attachCounterparties(ctx context.Context, tx *sqlx.Tx, updates []*mBank.Update) error {
+	if len(updates) == 0 {
+		return nil
+	}
+
+	tradeIDs := make([]string, 0, len(updates))
+	for _, u := range updates {
+		tradeIDs = append(tradeIDs, u.TradeID)
+	}
+
+	query, args, err := sqlx.In(queryTradeEntries, tradeIDs)
+	if err != nil {
+		return err
+	}
+	entries := []*mBank.Update{}
+	if err := tx.SelectContext(ctx, &entries, tx.Rebind(query), args...); err != nil {
+		return err
+	}
+	pairCounterparties(updates, entries)
+	return nil
+}
+
+// pairCounterparties pairs updates with entries of their trades in booking order. book logs the debit and then the
+// credit of a dealing, so the entries of a trade pair up per dealing; exchanges book one pair per leg and the
+// counterparty is the other side of the same leg
+func pairCounterparties(updates, entries []*mBank.Update) {
+	byTrade := map[string][]*mBank.Update{}
+	for _, e := range entries {
+		byTrade[e.TradeID] = append(byTrade[e.TradeID], e)
+	}
+
+	for _, u := range updates {
+		trade := byTrade[u.TradeID]
+		for i, e := range trade {
+			if e.ID != u.ID {
+				continue
+			}
+			// debits are at even positions, their credits follow
+			if pair := i ^ 1; pair < len(trade) {
+				u.CounterpartyID = trade[pair].AccountID
+			}
+			break
+		}
+	}
+}
+
+func (im *impl) ListUpdates(ctx context.Context, accountID string, afterID int64, limit int) ([]*mBank.Update, error) {
+	updates := []*mBank.Update{}
+	if err := sql.Transactx(ctx, im.db, func(tx *sqlx.Tx) error {
+		// the balance and entries are read from the same snapshot, so balances after each entry add up
+		balance, err := im.getBalance(ctx, tx, accountID)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.SelectContext(ctx, &updates, queryUpdates, accountID, afterID, limit); err != nil {
+			return err
+		}
+		if err := attachCounterparties(ctx, tx, updates); err != nil {
+			return err
+		}
+
+		// entries are newest first, walk back from the current balance
+		for _, u := range updates {
+			u.BalanceAfter = balance
+			delta, err := u.Delta()
+			if err != nil {
+				return err
+			}
+			if balance, err = balance.Sub(delta); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("Transactx failed in Bank.ListUpdates")
+		return nil, err
+	}
+
+	// oldest first
+	for i, j := 0, len(updates)-1; i < j; i, j = i+1, j-1 {
+		updates[i], updates[j] = updates[j], updates[i]
+	}
+	return updates, nil
+}
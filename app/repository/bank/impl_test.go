@@ -0,0 +1,51 @@
+package bank
+
+import (
+	"testing"
+
+	mBank "github.com/n3k0fi5t/wallet/app/models/bank"
+	"github.com/n3k0fi5t/wallet/app/money"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	mockUSDWallet = "935f871a-660f-4f19-801e-916c04bb0324"
+	mockEURWallet = "a89b7b78-b9c1-4129-8cff-380bf53f3a49"
+	mockPayeeID   = "5d2c9a4e-3f61-4a8e-9b0c-7e1f2d3c4b5a"
+	mockExchange  = "7e1f2d3c-4b5a-4a8e-9b0c-5d2c9a4e3f61"
+	mockTransfer  = "c1b2a3d4-5e6f-4a8e-9b0c-7e1f2d3c4b5a"
+)
+
+func entry(id int64, accountID, tradeID string, action mBank.Action, amount money.Money) *mBank.Update {
+	return &mBank.Update{ID: id, AccountID: accountID, TradeID: tradeID, Action: action, Amount: amount}
+}
+
+func TestPairCounterparties(t *testing.T) {
+	// an exchange books the sell leg and then the buy leg, a transfer books one dealing
+	entries := []*mBank.Update{
+		entry(11, mockUSDWallet, mockExchange, mBank.Action_DECREASE, money.New(1000, "USD")),
+		entry(12, mBank.FXAccountID("USD"), mockExchange, mBank.Action_INCREASE, money.New(1000, "USD")),
+		entry(13, mBank.FXAccountID("EUR"), mockExchange, mBank.Action_DECREASE, money.New(1000, "EUR")),
+		entry(14, mockEURWallet, mockExchange, mBank.Action_INCREASE, money.New(1000, "EUR")),
+		entry(15, mockEURWallet, mockTransfer, mBank.Action_DECREASE, money.New(300, "EUR")),
+		entry(16, mockPayeeID, mockTransfer, mBank.Action_INCREASE, money.New(300, "EUR")),
+	}
+
+	// replaying the EUR wallet gets one update per entry, even though both legs have the same amount
+	updates := []*mBank.Update{
+		entry(15, mockEURWallet, mockTransfer, mBank.Action_DECREASE, money.New(300, "EUR")),
+		entry(14, mockEURWallet, mockExchange, mBank.Action_INCREASE, money.New(1000, "EUR")),
+	}
+	pairCounterparties(updates, entries)
+	require.Equal(t, mockPayeeID, updates[0].CounterpartyID)
+	require.Equal(t, mBank.FXAccountID("EUR"), updates[1].CounterpartyID)
+
+	updates = []*mBank.Update{entry(11, mockUSDWallet, mockExchange, mBank.Action_DECREASE, money.New(1000, "USD"))}
+	pairCounterparties(updates, entries)
+	require.Equal(t, mBank.FXAccountID("USD"), updates[0].CounterpartyID)
+
+	// entries of the trade not found leave the counterparty empty
+	updates = []*mBank.Update{entry(17, mockPayeeID, "unknown", mBank.Action_INCREASE, money.New(300, "EUR"))}
+	pairCounterparties(updates, entries)
+	require.Empty(t, updates[0].CounterpartyID)
+}
@@ -0,0 +1,188 @@
+package bank
+
+import (
+	"context"
+	"time"
+
+	mBank "github.com/n3k0fi5t/wallet/app/models/bank"
+	"github.com/n3k0fi5t/wallet/common/logger"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const (
+	TradeType_DEPOSIT    = "deposit"
+	TradeType_WITHDRAW   = "withdraw"
+	TradeType_TRANSFER   = "transfer"
+	TradeType_REVERSAL   = "reversal"
+	TradeType_EXCHANGE   = "exchange"
+	TradeType_ADJUSTMENT = "adjustment"
+	TradeType_CLEARING   = "clearing"
+	TradeType_UNKNOWN    = "unknown"
+
+	OutcomeOK    = "ok"
+	OutcomeError = "error"
+)
+
+var (
+	outcomes = map[error]string{
+		ErrAccountNotExist:      "account_not_exist",
+		ErrBalanceNotEnough:     "balance_not_enough",
+		ErrSelfTransfer:         "self_transfer",
+		ErrUpdateBalance:        "update_balance_failed",
+		ErrInvalidDealing:       "invalid_dealing",
+		ErrAccountFrozen:        "account_frozen",
+		ErrTradeNotExist:        "trade_not_exist",
+		ErrTradeAlreadyReversed: "trade_already_reversed",
+		ErrIdempotencyKeyReused: "idempotency_key_reused",
+		ErrCurrencyMismatch:     "currency_mismatch",
+		ErrBalanceOverflow:      "balance_overflow",
+		ErrCreditLimitInUse:     "credit_limit_in_use",
+	}
+
+	tradesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "bank_trades_total",
+		Help: "Trades executed by the bank, by type and outcome",
+	}, []string{"type", "outcome"})
+
+	tradeDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "bank_trade_duration_seconds",
+		Help:    "Duration of trades executed by the bank, by type",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"type"})
+
+	liabilitiesDesc = prometheus.NewDesc(
+		"bank_customer_liabilities",
+		"Total balance of customer accounts, by currency",
+		[]string{"currency"}, nil,
+	)
+
+	overdraftsDesc = prometheus.NewDesc(
+		"bank_customer_overdrafts",
+		"Total overdrawn amount of customer accounts, by currency",
+		[]string{"currency"}, nil,
+	)
+
+	systemBalancesDesc = prometheus.NewDesc(
+		"bank_system_account_balance",
+		"Balance of each system account, funding accounts are minus the money their rail brought in",
+		[]string{"account", "currency"}, nil,
+	)
+)
+
+// Outcome returns the metric label of a bank error, unknown errors are OutcomeError
+func Outcome(err error) string {
+	if err == nil {
+		return OutcomeOK
+	} else if outcome, ok := outcomes[err]; ok {
+		return outcome
+	}
+	return OutcomeError
+}
+
+// TradeType returns the metric label of the dealing, money comes from or goes to a funding or clearing account in
+// deposits and withdrawals, and to other system accounts in adjustments. Money between the funding and the clearing
+// account of a rail is clearing, so each deposit and withdrawal over a rail counts once
+func TradeType(dealing *mBank.Dealing) string {
+	switch {
+	case dealing == nil:
+		return TradeType_UNKNOWN
+	case dealing.ReversalOf != "":
+		return TradeType_REVERSAL
+	case mBank.IsFundingAccount(dealing.FromAccountID) && mBank.IsClearingAccount(dealing.ToAccountID),
+		mBank.IsClearingAccount(dealing.FromAccountID) && mBank.IsFundingAccount(dealing.ToAccountID):
+		return TradeType_CLEARING
+	case mBank.IsFundingAccount(dealing.FromAccountID), mBank.IsClearingAccount(dealing.FromAccountID):
+		return TradeType_DEPOSIT
+	case mBank.IsFundingAccount(dealing.ToAccountID), mBank.IsClearingAccount(dealing.ToAccountID):
+		return TradeType_WITHDRAW
+	case mBank.IsSystemAccount(dealing.FromAccountID), mBank.IsSystemAccount(dealing.ToAccountID):
+		return TradeType_ADJUSTMENT
+	}
+	return TradeType_TRANSFER
+}
+
+// NewInstrumentedBank decorates b with trade metrics
+func NewInstrumentedBank(b Bank) Bank {
+	return &instrumented{
+		Bank: b,
+	}
+}
+
+type instrumented struct {
+	Bank
+}
+
+func (in *instrumented) Trade(ctx context.Context, dealing *mBank.Dealing) (string, error) {
+	start := time.Now()
+	tradeID, err := in.Bank.Trade(ctx, dealing)
+
+	tradeType := TradeType(dealing)
+	tradesTotal.WithLabelValues(tradeType, Outcome(err)).Inc()
+	tradeDuration.WithLabelValues(tradeType).Observe(time.Since(start).Seconds())
+	return tradeID, err
+}
+
+func (in *instrumented) Exchange(ctx context.Context, exchange *mBank.Exchange) (string, error) {
+	start := time.Now()
+	tradeID, err := in.Bank.Exchange(ctx, exchange)
+
+	tradesTotal.WithLabelValues(TradeType_EXCHANGE, Outcome(err)).Inc()
+	tradeDuration.WithLabelValues(TradeType_EXCHANGE).Observe(time.Since(start).Seconds())
+	return tradeID, err
+}
+
+// NewLiabilitiesCollector exports the total balance and overdrafts of customer accounts and the balances of system
+// accounts, they are queried on every scrape
+func NewLiabilitiesCollector(b Bank, timeout time.Duration) prometheus.Collector {
+	return &liabilitiesCollector{
+		bank:    b,
+		timeout: timeout,
+	}
+}
+
+type liabilitiesCollector struct {
+	bank    Bank
+	timeout time.Duration
+}
+
+func (lc *liabilitiesCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- liabilitiesDesc
+	ch <- overdraftsDesc
+	ch <- systemBalancesDesc
+}
+
+func (lc *liabilitiesCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), lc.timeout)
+	defer cancel()
+
+	totals, err := lc.bank.GetLiabilities(ctx)
+	if err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("bank.GetLiabilities failed in liabilitiesCollector.Collect")
+		ch <- prometheus.NewInvalidMetric(liabilitiesDesc, err)
+		return
+	}
+	for _, total := range totals {
+		ch <- prometheus.MustNewConstMetric(liabilitiesDesc, prometheus.GaugeValue, float64(total.Amount), total.Currency)
+	}
+
+	overdrafts, err := lc.bank.GetOverdrafts(ctx)
+	if err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("bank.GetOverdrafts failed in liabilitiesCollector.Collect")
+		ch <- prometheus.NewInvalidMetric(overdraftsDesc, err)
+		return
+	}
+	for _, total := range overdrafts {
+		ch <- prometheus.MustNewConstMetric(overdraftsDesc, prometheus.GaugeValue, float64(total.Amount), total.Currency)
+	}
+
+	accounts, err := lc.bank.ListSystemAccounts(ctx)
+	if err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("bank.ListSystemAccounts failed in liabilitiesCollector.Collect")
+		ch <- prometheus.NewInvalidMetric(systemBalancesDesc, err)
+		return
+	}
+	for _, account := range accounts {
+		ch <- prometheus.MustNewConstMetric(systemBalancesDesc, prometheus.GaugeValue, float64(account.Balance.Amount), account.AccountID, account.Currency())
+	}
+}
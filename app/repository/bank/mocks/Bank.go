@@ -5,12 +5,48 @@ package mocks
 import bank "github.com/n3k0fi5t/wallet/app/models/bank"
 import context "context"
 import mock "github.com/stretchr/testify/mock"
+import money "github.com/n3k0fi5t/wallet/app/money"
 
 // Bank is an autogenerated mock type for the Bank type
 type Bank struct {
 	mock.Mock
 }
 
+// CreateAccount provides a mock function with given fields: ctx, account
+func (_m *Bank) CreateAccount(ctx context.Context, account *bank.Account) error {
+	ret := _m.Called(ctx, account)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *bank.Account) error); ok {
+		r0 = rf(ctx, account)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Exchange provides a mock function with given fields: ctx, exchange
+func (_m *Bank) Exchange(ctx context.Context, exchange *bank.Exchange) (string, error) {
+	ret := _m.Called(ctx, exchange)
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(context.Context, *bank.Exchange) string); ok {
+		r0 = rf(ctx, exchange)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *bank.Exchange) error); ok {
+		r1 = rf(ctx, exchange)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetAccount provides a mock function with given fields: ctx, accountID
 func (_m *Bank) GetAccount(ctx context.Context, accountID string) (*bank.Account, error) {
 	ret := _m.Called(ctx, accountID)
@@ -34,6 +70,253 @@ func (_m *Bank) GetAccount(ctx context.Context, accountID string) (*bank.Account
 	return r0, r1
 }
 
+// GetIdempotentTrade provides a mock function with given fields: ctx, idempotencyKey
+func (_m *Bank) GetIdempotentTrade(ctx context.Context, idempotencyKey string) (string, error) {
+	ret := _m.Called(ctx, idempotencyKey)
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(context.Context, string) string); ok {
+		r0 = rf(ctx, idempotencyKey)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, idempotencyKey)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetLiabilities provides a mock function with given fields: ctx
+func (_m *Bank) GetLiabilities(ctx context.Context) ([]money.Money, error) {
+	ret := _m.Called(ctx)
+
+	var r0 []money.Money
+	if rf, ok := ret.Get(0).(func(context.Context) []money.Money); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]money.Money)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetOverdrafts provides a mock function with given fields: ctx
+func (_m *Bank) GetOverdrafts(ctx context.Context) ([]money.Money, error) {
+	ret := _m.Called(ctx)
+
+	var r0 []money.Money
+	if rf, ok := ret.Get(0).(func(context.Context) []money.Money); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]money.Money)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetTrade provides a mock function with given fields: ctx, tradeID
+func (_m *Bank) GetTrade(ctx context.Context, tradeID string) ([]*bank.Transaction, error) {
+	ret := _m.Called(ctx, tradeID)
+
+	var r0 []*bank.Transaction
+	if rf, ok := ret.Get(0).(func(context.Context, string) []*bank.Transaction); ok {
+		r0 = rf(ctx, tradeID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*bank.Transaction)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, tradeID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListAccounts provides a mock function with given fields: ctx, ownerID
+func (_m *Bank) ListAccounts(ctx context.Context, ownerID string) ([]*bank.Account, error) {
+	ret := _m.Called(ctx, ownerID)
+
+	var r0 []*bank.Account
+	if rf, ok := ret.Get(0).(func(context.Context, string) []*bank.Account); ok {
+		r0 = rf(ctx, ownerID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*bank.Account)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, ownerID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListOverdrawn provides a mock function with given fields: ctx, offset, limit
+func (_m *Bank) ListOverdrawn(ctx context.Context, offset int, limit int) ([]*bank.Account, error) {
+	ret := _m.Called(ctx, offset, limit)
+
+	var r0 []*bank.Account
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) []*bank.Account); ok {
+		r0 = rf(ctx, offset, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*bank.Account)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int, int) error); ok {
+		r1 = rf(ctx, offset, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListSystemAccounts provides a mock function with given fields: ctx
+func (_m *Bank) ListSystemAccounts(ctx context.Context) ([]*bank.Account, error) {
+	ret := _m.Called(ctx)
+
+	var r0 []*bank.Account
+	if rf, ok := ret.Get(0).(func(context.Context) []*bank.Account); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*bank.Account)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListTransactions provides a mock function with given fields: ctx, accountID, offset, limit
+func (_m *Bank) ListTransactions(ctx context.Context, accountID string, offset int, limit int) ([]*bank.Transaction, error) {
+	ret := _m.Called(ctx, accountID, offset, limit)
+
+	var r0 []*bank.Transaction
+	if rf, ok := ret.Get(0).(func(context.Context, string, int, int) []*bank.Transaction); ok {
+		r0 = rf(ctx, accountID, offset, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*bank.Transaction)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, int, int) error); ok {
+		r1 = rf(ctx, accountID, offset, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListUpdates provides a mock function with given fields: ctx, accountID, afterID, limit
+func (_m *Bank) ListUpdates(ctx context.Context, accountID string, afterID int64, limit int) ([]*bank.Update, error) {
+	ret := _m.Called(ctx, accountID, afterID, limit)
+
+	var r0 []*bank.Update
+	if rf, ok := ret.Get(0).(func(context.Context, string, int64, int) []*bank.Update); ok {
+		r0 = rf(ctx, accountID, afterID, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*bank.Update)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, int64, int) error); ok {
+		r1 = rf(ctx, accountID, afterID, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RenameAccount provides a mock function with given fields: ctx, accountID, name
+func (_m *Bank) RenameAccount(ctx context.Context, accountID string, name string) error {
+	ret := _m.Called(ctx, accountID, name)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, accountID, name)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SetAccountStatus provides a mock function with given fields: ctx, accountID, status
+func (_m *Bank) SetAccountStatus(ctx context.Context, accountID string, status bank.AccountStatus) error {
+	ret := _m.Called(ctx, accountID, status)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, bank.AccountStatus) error); ok {
+		r0 = rf(ctx, accountID, status)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SetCreditLimit provides a mock function with given fields: ctx, accountID, limit
+func (_m *Bank) SetCreditLimit(ctx context.Context, accountID string, limit money.Money) error {
+	ret := _m.Called(ctx, accountID, limit)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, money.Money) error); ok {
+		r0 = rf(ctx, accountID, limit)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Trade provides a mock function with given fields: ctx, dealing
 func (_m *Bank) Trade(ctx context.Context, dealing *bank.Dealing) (string, error) {
 	ret := _m.Called(ctx, dealing)
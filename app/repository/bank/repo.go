@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	mBank "github.com/n3k0fi5t/wallet/app/models/bank"
+	"github.com/n3k0fi5t/wallet/app/money"
 )
 
 var (
@@ -22,12 +23,76 @@ var (
 
 	// ErrInvalidDealing
 	ErrInvalidDealing = fmt.Errorf("Invalid dealing")
+
+	// ErrAccountFrozen means the account is frozen and can not pay out
+	ErrAccountFrozen = fmt.Errorf("Account frozen")
+
+	// ErrTradeNotExist means query trade not exist
+	ErrTradeNotExist = fmt.Errorf("Trade not exist")
+
+	// ErrTradeAlreadyReversed means the trade has been reversed before
+	ErrTradeAlreadyReversed = fmt.Errorf("Trade already reversed")
+
+	// ErrIdempotencyKeyReused means the idempotency key was used by a different dealing
+	ErrIdempotencyKeyReused = fmt.Errorf("Idempotency key reused")
+
+	// ErrCurrencyMismatch means the accounts of the dealing hold different currencies, convert through Exchange instead
+	ErrCurrencyMismatch = money.ErrCurrencyMismatch
+
+	// ErrBalanceOverflow means the balance after the dealing does not fit in int64 minor units
+	ErrBalanceOverflow = fmt.Errorf("Balance overflow")
+
+	// ErrCreditLimitInUse means the account is overdrawn by more than the new credit limit
+	ErrCreditLimitInUse = fmt.Errorf("Credit limit in use")
 )
 
 type Bank interface {
 	// Trade executes dealings
 	Trade(ctx context.Context, dealing *mBank.Dealing) (string, error)
 
-	// GetAccount get account Information
+	// Exchange books both legs of a currency exchange as one trade, the rate and spread are recorded with it
+	Exchange(ctx context.Context, exchange *mBank.Exchange) (string, error)
+
+	// GetAccount get account Information, a context carrying a transaction reads within it
 	GetAccount(ctx context.Context, accountID string) (*mBank.Account, error)
+
+	// CreateAccount opens an account, system accounts are opened by the services owning them
+	CreateAccount(ctx context.Context, account *mBank.Account) error
+
+	// ListAccounts list accounts owned by the user, the oldest first
+	ListAccounts(ctx context.Context, ownerID string) ([]*mBank.Account, error)
+
+	// RenameAccount changes the name of the account
+	RenameAccount(ctx context.Context, accountID, name string) error
+
+	// ListTransactions list transaction logs of the account, newest first
+	ListTransactions(ctx context.Context, accountID string, offset, limit int) ([]*mBank.Transaction, error)
+
+	// GetTrade get the double entries of the trade
+	GetTrade(ctx context.Context, tradeID string) ([]*mBank.Transaction, error)
+
+	// GetIdempotentTrade get the trade executed with the idempotency key, ErrTradeNotExist if the key is unused
+	GetIdempotentTrade(ctx context.Context, idempotencyKey string) (string, error)
+
+	// SetAccountStatus freeze or unfreeze the account
+	SetAccountStatus(ctx context.Context, accountID string, status mBank.AccountStatus) error
+
+	// SetCreditLimit sets how far the balance of the account may go below zero, 0 revokes the credit line.
+	// The limit is in the currency of the account
+	SetCreditLimit(ctx context.Context, accountID string, limit money.Money) error
+
+	// ListOverdrawn list accounts below zero, the most overdrawn first
+	ListOverdrawn(ctx context.Context, offset, limit int) ([]*mBank.Account, error)
+
+	// GetLiabilities get the total positive balance of customer accounts per currency, which the bank owes to customers
+	GetLiabilities(ctx context.Context) ([]money.Money, error)
+
+	// GetOverdrafts get the total overdrawn amount of accounts per currency, which customers owe to the bank
+	GetOverdrafts(ctx context.Context) ([]money.Money, error)
+
+	// ListSystemAccounts list the chart of system accounts with their balances, by currency
+	ListSystemAccounts(ctx context.Context) ([]*mBank.Account, error)
+
+	// ListUpdates list the newest limit updates of the account after the entry afterID of the trade log, oldest first
+	ListUpdates(ctx context.Context, accountID string, afterID int64, limit int) ([]*mBank.Update, error)
 }
@@ -0,0 +1,154 @@
+package bill
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+	mBill "github.com/n3k0fi5t/wallet/app/models/bill"
+	"github.com/n3k0fi5t/wallet/common/logger"
+	"github.com/n3k0fi5t/wallet/common/sql"
+)
+
+const (
+	billColumns  = "b.id, b.billID, b.organizerID, b.total AS `total.amount`, b.currency AS `total.currency`, b.memo, b.splitType, b.status, b.createdMs, b.updatedMs"
+	shareColumns = "id, billID, participantID, amount AS `amount.amount`, currency AS `amount.currency`, status, tradeID, reminders, remindedMs, createdMs, updatedMs"
+
+	insertBill  = "INSERT INTO Bill (billID, organizerID, total, currency, memo, splitType, status, createdMs, updatedMs) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)"
+	insertShare = "INSERT INTO BillShare (billID, participantID, amount, currency, status, tradeID, reminders, remindedMs, createdMs, updatedMs) VALUES (:billID, :participantID, :amount.amount, :amount.currency, :status, :tradeID, :reminders, :remindedMs, :createdMs, :updatedMs)"
+	queryBill   = "SELECT " + billColumns + " FROM Bill b WHERE b.billID = ?"
+	lockBill    = queryBill + " FOR UPDATE"
+	queryShares = "SELECT " + shareColumns + " FROM BillShare WHERE billID IN (?) ORDER BY id"
+	updateBill  = "UPDATE Bill SET status = ?, updatedMs = ? WHERE billID = ?"
+	updateShare = "UPDATE BillShare SET status = ?, tradeID = ?, reminders = ?, remindedMs = ?, updatedMs = ? WHERE billID = ? AND participantID = ?"
+
+	queryOrganizedBills = "SELECT " + billColumns + " FROM Bill b WHERE b.organizerID = ?"
+	queryJoinedBills    = "SELECT " + billColumns + " FROM Bill b JOIN BillShare s ON s.billID = b.billID WHERE s.participantID = ? AND b.organizerID != s.participantID"
+	condStatus          = " AND b.status = ?"
+	orderPage           = " ORDER BY b.id DESC LIMIT ? OFFSET ?"
+)
+
+func NewBill(db *sqlx.DB) Bill {
+	return &impl{
+		db: db,
+	}
+}
+
+type impl struct {
+	db *sqlx.DB
+}
+
+func (im *impl) CreateBill(ctx context.Context, b *mBill.Bill) error {
+	return sql.Transactx(ctx, im.db, func(tx *sqlx.Tx) error {
+		if _, err := tx.ExecContext(ctx, insertBill, b.BillID, b.OrganizerID, b.Total.Amount, b.Total.Currency, b.Memo, b.SplitType, b.Status, b.CreatedMs, b.UpdatedMs); err != nil {
+			logger.FromContext(ctx).WithField("err", err).Error("ExecContext failed in Bill.CreateBill")
+			return err
+		}
+		if _, err := tx.NamedExecContext(ctx, insertShare, b.Shares); err != nil {
+			logger.FromContext(ctx).WithField("err", err).Error("NamedExecContext failed in Bill.CreateBill")
+			return err
+		}
+		return nil
+	})
+}
+
+// attachShares fills shares of the bills in order of creation
+func attachShares(ctx context.Context, tx *sqlx.Tx, bills []*mBill.Bill) error {
+	if len(bills) == 0 {
+		return nil
+	}
+
+	billIDs := make([]string, 0, len(bills))
+	byID := map[string]*mBill.Bill{}
+	for _, b := range bills {
+		billIDs = append(billIDs, b.BillID)
+		byID[b.BillID] = b
+	}
+
+	query, args, err := sqlx.In(queryShares, billIDs)
+	if err != nil {
+		return err
+	}
+	shares := []*mBill.Share{}
+	if err := tx.SelectContext(ctx, &shares, tx.Rebind(query), args...); err != nil {
+		return err
+	}
+	for _, share := range shares {
+		b := byID[share.BillID]
+		b.Shares = append(b.Shares, share)
+	}
+	return nil
+}
+
+func (im *impl) getBill(ctx context.Context, query, billID string) (*mBill.Bill, error) {
+	bills := []*mBill.Bill{}
+	if err := sql.Transactx(ctx, im.db, func(tx *sqlx.Tx) error {
+		if err := tx.SelectContext(ctx, &bills, query, billID); err != nil {
+			return err
+		}
+		return attachShares(ctx, tx, bills)
+	}); err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("SelectContext failed in Bill.GetBill")
+		return nil, err
+	}
+
+	if len(bills) == 0 {
+		return nil, ErrBillNotExist
+	}
+	return bills[0], nil
+}
+
+func (im *impl) GetBill(ctx context.Context, billID string) (*mBill.Bill, error) {
+	return im.getBill(ctx, queryBill, billID)
+}
+
+func (im *impl) LockBill(ctx context.Context, billID string) (*mBill.Bill, error) {
+	return im.getBill(ctx, lockBill, billID)
+}
+
+func (im *impl) ListBills(ctx context.Context, accountID string, role mBill.Role, status mBill.Status, offset, limit int) ([]*mBill.Bill, error) {
+	query := queryOrganizedBills
+	if role == mBill.Role_PARTICIPANT {
+		query = queryJoinedBills
+	}
+
+	args := []interface{}{accountID}
+	if status != "" {
+		query += condStatus
+		args = append(args, status)
+	}
+	args = append(args, limit, offset)
+
+	bills := []*mBill.Bill{}
+	if err := sql.Transactx(ctx, im.db, func(tx *sqlx.Tx) error {
+		if err := tx.SelectContext(ctx, &bills, query+orderPage, args...); err != nil {
+			return err
+		}
+		return attachShares(ctx, tx, bills)
+	}); err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("SelectContext failed in Bill.ListBills")
+		return nil, err
+	}
+	return bills, nil
+}
+
+func (im *impl) UpdateBill(ctx context.Context, b *mBill.Bill) error {
+	return sql.Transactx(ctx, im.db, func(tx *sqlx.Tx) error {
+		if _, err := tx.ExecContext(ctx, updateBill, b.Status, b.UpdatedMs, b.BillID); err != nil {
+			logger.FromContext(ctx).WithField("err", err).Error("ExecContext failed in Bill.UpdateBill")
+			return err
+		}
+		return nil
+	})
+}
+
+func (im *impl) UpdateShares(ctx context.Context, shares ...*mBill.Share) error {
+	return sql.Transactx(ctx, im.db, func(tx *sqlx.Tx) error {
+		for _, s := range shares {
+			if _, err := tx.ExecContext(ctx, updateShare, s.Status, s.TradeID, s.Reminders, s.RemindedMs, s.UpdatedMs, s.BillID, s.ParticipantID); err != nil {
+				logger.FromContext(ctx).WithField("err", err).Error("ExecContext failed in Bill.UpdateShares")
+				return err
+			}
+		}
+		return nil
+	})
+}
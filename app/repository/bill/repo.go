@@ -0,0 +1,35 @@
+package bill
+
+import (
+	"context"
+	"fmt"
+
+	mBill "github.com/n3k0fi5t/wallet/app/models/bill"
+)
+
+var (
+	// ErrBillNotExist means query bill not exist
+	ErrBillNotExist = fmt.Errorf("Bill not exist")
+)
+
+type Bill interface {
+	// CreateBill creates a bill with its shares
+	CreateBill(ctx context.Context, bill *mBill.Bill) error
+
+	// GetBill get a bill with its shares by ID
+	GetBill(ctx context.Context, billID string) (*mBill.Bill, error)
+
+	// LockBill get a bill with its shares and locks the bill until the transaction carried by context ends,
+	// changes of the bill and its shares take turns by the lock
+	LockBill(ctx context.Context, billID string) (*mBill.Bill, error)
+
+	// ListBills list bills with their shares the account organizes or takes part in, newest first.
+	// Empty status for all
+	ListBills(ctx context.Context, accountID string, role mBill.Role, status mBill.Status, offset, limit int) ([]*mBill.Bill, error)
+
+	// UpdateBill saves status and update time of the bill
+	UpdateBill(ctx context.Context, bill *mBill.Bill) error
+
+	// UpdateShares saves status, trade, reminders and update time of the shares
+	UpdateShares(ctx context.Context, shares ...*mBill.Share) error
+}
@@ -0,0 +1,111 @@
+package escrow
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+	mEscrow "github.com/n3k0fi5t/wallet/app/models/escrow"
+	"github.com/n3k0fi5t/wallet/common/logger"
+	"github.com/n3k0fi5t/wallet/common/sql"
+)
+
+const (
+	escrowColumns = "id, escrowID, accountID, buyerID, sellerID, amount AS `amount.amount`, currency AS `amount.currency`, memo, status, fundTradeID, settleTradeID, disputedBy, releaseAtMs, createdMs, updatedMs"
+
+	insertEscrow     = "INSERT INTO Escrow (escrowID, accountID, buyerID, sellerID, amount, currency, memo, status, fundTradeID, settleTradeID, disputedBy, releaseAtMs, createdMs, updatedMs) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"
+	queryEscrow      = "SELECT " + escrowColumns + " FROM Escrow WHERE escrowID = ?"
+	lockEscrow       = queryEscrow + " FOR UPDATE"
+	queryDueEscrows  = "SELECT " + escrowColumns + " FROM Escrow WHERE status = 'FUNDED' AND releaseAtMs <= ? ORDER BY releaseAtMs LIMIT ?"
+	updateEscrow     = "UPDATE Escrow SET status = ?, settleTradeID = ?, disputedBy = ?, updatedMs = ? WHERE escrowID = ?"
+	queryEscrowsFrom = "SELECT " + escrowColumns + " FROM Escrow WHERE "
+	condStatus       = " AND status = ?"
+	orderPage        = " ORDER BY id DESC LIMIT ? OFFSET ?"
+)
+
+func NewEscrow(db *sqlx.DB) Escrow {
+	return &impl{
+		db: db,
+	}
+}
+
+type impl struct {
+	db *sqlx.DB
+}
+
+func (im *impl) CreateEscrow(ctx context.Context, e *mEscrow.Escrow) error {
+	return sql.Transactx(ctx, im.db, func(tx *sqlx.Tx) error {
+		if _, err := tx.ExecContext(ctx, insertEscrow, e.EscrowID, e.AccountID, e.BuyerID, e.SellerID, e.Amount.Amount, e.Amount.Currency, e.Memo, e.Status, e.FundTradeID, e.SettleTradeID, e.DisputedBy, e.ReleaseAtMs, e.CreatedMs, e.UpdatedMs); err != nil {
+			logger.FromContext(ctx).WithField("err", err).Error("ExecContext failed in Escrow.CreateEscrow")
+			return err
+		}
+		return nil
+	})
+}
+
+func (im *impl) GetEscrow(ctx context.Context, escrowID string) (*mEscrow.Escrow, error) {
+	escrows := []*mEscrow.Escrow{}
+	if err := im.db.SelectContext(ctx, &escrows, queryEscrow, escrowID); err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("SelectContext failed in Escrow.GetEscrow")
+		return nil, err
+	}
+
+	if len(escrows) == 0 {
+		return nil, ErrEscrowNotExist
+	}
+	return escrows[0], nil
+}
+
+func (im *impl) LockEscrow(ctx context.Context, escrowID string) (*mEscrow.Escrow, error) {
+	escrows := []*mEscrow.Escrow{}
+	if err := sql.Transactx(ctx, im.db, func(tx *sqlx.Tx) error {
+		return tx.SelectContext(ctx, &escrows, lockEscrow, escrowID)
+	}); err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("SelectContext failed in Escrow.LockEscrow")
+		return nil, err
+	}
+
+	if len(escrows) == 0 {
+		return nil, ErrEscrowNotExist
+	}
+	return escrows[0], nil
+}
+
+func (im *impl) ListEscrows(ctx context.Context, accountID string, role mEscrow.Role, status mEscrow.Status, offset, limit int) ([]*mEscrow.Escrow, error) {
+	query := queryEscrowsFrom + "buyerID = ?"
+	if role == mEscrow.Role_SELLER {
+		query = queryEscrowsFrom + "sellerID = ?"
+	}
+
+	args := []interface{}{accountID}
+	if status != "" {
+		query += condStatus
+		args = append(args, status)
+	}
+	args = append(args, limit, offset)
+
+	escrows := []*mEscrow.Escrow{}
+	if err := im.db.SelectContext(ctx, &escrows, query+orderPage, args...); err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("SelectContext failed in Escrow.ListEscrows")
+		return nil, err
+	}
+	return escrows, nil
+}
+
+func (im *impl) ListDue(ctx context.Context, nowMs int64, limit int) ([]*mEscrow.Escrow, error) {
+	escrows := []*mEscrow.Escrow{}
+	if err := im.db.SelectContext(ctx, &escrows, queryDueEscrows, nowMs, limit); err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("SelectContext failed in Escrow.ListDue")
+		return nil, err
+	}
+	return escrows, nil
+}
+
+func (im *impl) UpdateEscrow(ctx context.Context, e *mEscrow.Escrow) error {
+	return sql.Transactx(ctx, im.db, func(tx *sqlx.Tx) error {
+		if _, err := tx.ExecContext(ctx, updateEscrow, e.Status, e.SettleTradeID, e.DisputedBy, e.UpdatedMs, e.EscrowID); err != nil {
+			logger.FromContext(ctx).WithField("err", err).Error("ExecContext failed in Escrow.UpdateEscrow")
+			return err
+		}
+		return nil
+	})
+}
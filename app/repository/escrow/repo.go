@@ -0,0 +1,33 @@
+package escrow
+
+import (
+	"context"
+	"fmt"
+
+	mEscrow "github.com/n3k0fi5t/wallet/app/models/escrow"
+)
+
+var (
+	// ErrEscrowNotExist means query escrow not exist
+	ErrEscrowNotExist = fmt.Errorf("Escrow not exist")
+)
+
+type Escrow interface {
+	// CreateEscrow creates an escrow
+	CreateEscrow(ctx context.Context, escrow *mEscrow.Escrow) error
+
+	// GetEscrow get an escrow by ID
+	GetEscrow(ctx context.Context, escrowID string) (*mEscrow.Escrow, error)
+
+	// LockEscrow get an escrow and locks it until the transaction carried by context ends
+	LockEscrow(ctx context.Context, escrowID string) (*mEscrow.Escrow, error)
+
+	// ListEscrows list escrows the account buys or sells in, newest first. Empty status for all
+	ListEscrows(ctx context.Context, accountID string, role mEscrow.Role, status mEscrow.Status, offset, limit int) ([]*mEscrow.Escrow, error)
+
+	// ListDue list funded escrows to be released automatically at nowMs, the earliest first
+	ListDue(ctx context.Context, nowMs int64, limit int) ([]*mEscrow.Escrow, error)
+
+	// UpdateEscrow saves status, settlement, dispute and update time of the escrow
+	UpdateEscrow(ctx context.Context, escrow *mEscrow.Escrow) error
+}
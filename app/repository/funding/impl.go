@@ -0,0 +1,87 @@
+package funding
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+	mFunding "github.com/n3k0fi5t/wallet/app/models/funding"
+	"github.com/n3k0fi5t/wallet/common/logger"
+	"github.com/n3k0fi5t/wallet/common/sql"
+)
+
+const (
+	transferColumns = "id, transferID, walletID, userID, direction, rail, amount AS `amount.amount`, currency AS `amount.currency`, status, reference, failureReason, clearTradeID, settleTradeID, returnTradeID, refundTradeID, shortfallTradeID, shortfallAmount AS `shortfallAmount.amount`, currency AS `shortfallAmount.currency`, createdMs, updatedMs"
+
+	insertTransfer = "INSERT INTO FundingTransfer (transferID, walletID, userID, direction, rail, amount, currency, status, reference, failureReason, clearTradeID, settleTradeID, returnTradeID, refundTradeID, shortfallTradeID, shortfallAmount, createdMs, updatedMs) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"
+	queryTransfer  = "SELECT " + transferColumns + " FROM FundingTransfer WHERE transferID = ?"
+	lockTransfer   = queryTransfer + " FOR UPDATE"
+	queryTransfers = "SELECT " + transferColumns + " FROM FundingTransfer WHERE walletID = ? ORDER BY id DESC LIMIT ? OFFSET ?"
+	updateTransfer = "UPDATE FundingTransfer SET status = ?, reference = ?, failureReason = ?, clearTradeID = ?, settleTradeID = ?, returnTradeID = ?, refundTradeID = ?, shortfallTradeID = ?, shortfallAmount = ?, updatedMs = ? WHERE transferID = ?"
+)
+
+func NewFunding(db *sqlx.DB) Funding {
+	return &impl{
+		db: db,
+	}
+}
+
+type impl struct {
+	db *sqlx.DB
+}
+
+func (im *impl) CreateTransfer(ctx context.Context, t *mFunding.Transfer) error {
+	return sql.Transactx(ctx, im.db, func(tx *sqlx.Tx) error {
+		if _, err := tx.ExecContext(ctx, insertTransfer, t.TransferID, t.WalletID, t.UserID, t.Direction, t.Rail, t.Amount.Amount, t.Currency(), t.Status, t.Reference, t.FailureReason, t.ClearTradeID, t.SettleTradeID, t.ReturnTradeID, t.RefundTradeID, t.ShortfallTradeID, t.ShortfallAmount.Amount, t.CreatedMs, t.UpdatedMs); err != nil {
+			logger.FromContext(ctx).WithField("err", err).Error("ExecContext failed in Funding.CreateTransfer")
+			return err
+		}
+		return nil
+	})
+}
+
+func (im *impl) GetTransfer(ctx context.Context, transferID string) (*mFunding.Transfer, error) {
+	transfers := []*mFunding.Transfer{}
+	if err := im.db.SelectContext(ctx, &transfers, queryTransfer, transferID); err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("SelectContext failed in Funding.GetTransfer")
+		return nil, err
+	}
+
+	if len(transfers) == 0 {
+		return nil, ErrTransferNotExist
+	}
+	return transfers[0], nil
+}
+
+func (im *impl) LockTransfer(ctx context.Context, transferID string) (*mFunding.Transfer, error) {
+	transfers := []*mFunding.Transfer{}
+	if err := sql.Transactx(ctx, im.db, func(tx *sqlx.Tx) error {
+		return tx.SelectContext(ctx, &transfers, lockTransfer, transferID)
+	}); err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("SelectContext failed in Funding.LockTransfer")
+		return nil, err
+	}
+
+	if len(transfers) == 0 {
+		return nil, ErrTransferNotExist
+	}
+	return transfers[0], nil
+}
+
+func (im *impl) ListTransfers(ctx context.Context, walletID string, offset, limit int) ([]*mFunding.Transfer, error) {
+	transfers := []*mFunding.Transfer{}
+	if err := im.db.SelectContext(ctx, &transfers, queryTransfers, walletID, limit, offset); err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("SelectContext failed in Funding.ListTransfers")
+		return nil, err
+	}
+	return transfers, nil
+}
+
+func (im *impl) UpdateTransfer(ctx context.Context, t *mFunding.Transfer) error {
+	return sql.Transactx(ctx, im.db, func(tx *sqlx.Tx) error {
+		if _, err := tx.ExecContext(ctx, updateTransfer, t.Status, t.Reference, t.FailureReason, t.ClearTradeID, t.SettleTradeID, t.ReturnTradeID, t.RefundTradeID, t.ShortfallTradeID, t.ShortfallAmount.Amount, t.UpdatedMs, t.TransferID); err != nil {
+			logger.FromContext(ctx).WithField("err", err).Error("ExecContext failed in Funding.UpdateTransfer")
+			return err
+		}
+		return nil
+	})
+}
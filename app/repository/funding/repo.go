@@ -0,0 +1,30 @@
+package funding
+
+import (
+	"context"
+	"fmt"
+
+	mFunding "github.com/n3k0fi5t/wallet/app/models/funding"
+)
+
+var (
+	// ErrTransferNotExist means query transfer not exist
+	ErrTransferNotExist = fmt.Errorf("Transfer not exist")
+)
+
+type Funding interface {
+	// CreateTransfer creates a transfer
+	CreateTransfer(ctx context.Context, transfer *mFunding.Transfer) error
+
+	// GetTransfer get a transfer by ID
+	GetTransfer(ctx context.Context, transferID string) (*mFunding.Transfer, error)
+
+	// LockTransfer get a transfer and locks it until the transaction carried by context ends
+	LockTransfer(ctx context.Context, transferID string) (*mFunding.Transfer, error)
+
+	// ListTransfers list transfers of the wallet, newest first
+	ListTransfers(ctx context.Context, walletID string, offset, limit int) ([]*mFunding.Transfer, error)
+
+	// UpdateTransfer saves status, reference, failure reason, trades and update time of the transfer
+	UpdateTransfer(ctx context.Context, transfer *mFunding.Transfer) error
+}
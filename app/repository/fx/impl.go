@@ -0,0 +1,78 @@
+package fx
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+	mFx "github.com/n3k0fi5t/wallet/app/models/fx"
+	"github.com/n3k0fi5t/wallet/common/logger"
+	"github.com/n3k0fi5t/wallet/common/sql"
+)
+
+const (
+	quoteColumns = "id, quoteID, userID, fromWalletID, toWalletID, sellAmount AS `sellAmount.amount`, fromCurrency AS `sellAmount.currency`, " +
+		"buyAmount AS `buyAmount.amount`, toCurrency AS `buyAmount.currency`, rate, spreadBps, status, tradeID, expiresAtMs, createdMs, updatedMs"
+
+	insertQuote = "INSERT INTO FXQuote (quoteID, userID, fromWalletID, toWalletID, fromCurrency, toCurrency, sellAmount, buyAmount, rate, spreadBps, status, tradeID, expiresAtMs, createdMs, updatedMs) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"
+	queryQuote  = "SELECT " + quoteColumns + " FROM FXQuote WHERE quoteID = ?"
+	lockQuote   = queryQuote + " FOR UPDATE"
+	updateQuote = "UPDATE FXQuote SET status = ?, tradeID = ?, updatedMs = ? WHERE quoteID = ?"
+)
+
+func NewFX(db *sqlx.DB) FX {
+	return &impl{
+		db: db,
+	}
+}
+
+type impl struct {
+	db *sqlx.DB
+}
+
+func (im *impl) CreateQuote(ctx context.Context, q *mFx.Quote) error {
+	return sql.Transactx(ctx, im.db, func(tx *sqlx.Tx) error {
+		if _, err := tx.ExecContext(ctx, insertQuote, q.QuoteID, q.UserID, q.FromWalletID, q.ToWalletID, q.SellAmount.Currency, q.BuyAmount.Currency, q.SellAmount.Amount, q.BuyAmount.Amount, q.Rate, q.SpreadBps, q.Status, q.TradeID, q.ExpiresAtMs, q.CreatedMs, q.UpdatedMs); err != nil {
+			logger.FromContext(ctx).WithField("err", err).Error("ExecContext failed in FX.CreateQuote")
+			return err
+		}
+		return nil
+	})
+}
+
+func (im *impl) GetQuote(ctx context.Context, quoteID string) (*mFx.Quote, error) {
+	quotes := []*mFx.Quote{}
+	if err := im.db.SelectContext(ctx, &quotes, queryQuote, quoteID); err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("SelectContext failed in FX.GetQuote")
+		return nil, err
+	}
+
+	if len(quotes) == 0 {
+		return nil, ErrQuoteNotExist
+	}
+	return quotes[0], nil
+}
+
+func (im *impl) LockQuote(ctx context.Context, quoteID string) (*mFx.Quote, error) {
+	quotes := []*mFx.Quote{}
+	if err := sql.Transactx(ctx, im.db, func(tx *sqlx.Tx) error {
+		return tx.SelectContext(ctx, &quotes, lockQuote, quoteID)
+	}); err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("SelectContext failed in FX.LockQuote")
+		return nil, err
+	}
+
+	if len(quotes) == 0 {
+		return nil, ErrQuoteNotExist
+	}
+	return quotes[0], nil
+}
+
+func (im *impl) UpdateQuote(ctx context.Context, q *mFx.Quote) error {
+	return sql.Transactx(ctx, im.db, func(tx *sqlx.Tx) error {
+		if _, err := tx.ExecContext(ctx, updateQuote, q.Status, q.TradeID, q.UpdatedMs, q.QuoteID); err != nil {
+			logger.FromContext(ctx).WithField("err", err).Error("ExecContext failed in FX.UpdateQuote")
+			return err
+		}
+		return nil
+	})
+}
@@ -0,0 +1,27 @@
+package fx
+
+import (
+	"context"
+	"fmt"
+
+	mFx "github.com/n3k0fi5t/wallet/app/models/fx"
+)
+
+var (
+	// ErrQuoteNotExist means query quote not exist
+	ErrQuoteNotExist = fmt.Errorf("Quote not exist")
+)
+
+type FX interface {
+	// CreateQuote creates a quote
+	CreateQuote(ctx context.Context, quote *mFx.Quote) error
+
+	// GetQuote get a quote by ID
+	GetQuote(ctx context.Context, quoteID string) (*mFx.Quote, error)
+
+	// LockQuote get a quote and locks it until the transaction carried by context ends
+	LockQuote(ctx context.Context, quoteID string) (*mFx.Quote, error)
+
+	// UpdateQuote saves status, trade and update time of the quote
+	UpdateQuote(ctx context.Context, quote *mFx.Quote) error
+}
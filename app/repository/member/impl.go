@@ -0,0 +1,154 @@
+package member
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+	mAudit "github.com/n3k0fi5t/wallet/app/models/audit"
+	mWallet "github.com/n3k0fi5t/wallet/app/models/wallet"
+	"github.com/n3k0fi5t/wallet/app/repository/audit"
+	"github.com/n3k0fi5t/wallet/common/logger"
+	"github.com/n3k0fi5t/wallet/common/sql"
+)
+
+const (
+	memberColumns = "id, walletID, userID, role, dailyCap AS `dailyCap.amount`, currency AS `dailyCap.currency`, " +
+		"spentToday AS `spentToday.amount`, currency AS `spentToday.currency`, spentDay, createdMs, updatedMs"
+
+	queryMember      = "SELECT " + memberColumns + " FROM WalletMember WHERE walletID = ? AND userID = ?"
+	lockMember       = queryMember + " FOR UPDATE"
+	queryMembers     = "SELECT " + memberColumns + " FROM WalletMember WHERE walletID = ? ORDER BY id"
+	queryMemberships = "SELECT " + memberColumns + " FROM WalletMember WHERE userID = ? ORDER BY id"
+	upsertMember     = "INSERT INTO WalletMember (walletID, userID, role, dailyCap, currency, spentToday, spentDay, createdMs, updatedMs) VALUES (?, ?, ?, ?, ?, 0, 0, ?, ?) ON DUPLICATE KEY UPDATE role = VALUES(role), dailyCap = VALUES(dailyCap), updatedMs = VALUES(updatedMs)"
+	updateSpent      = "UPDATE WalletMember SET spentToday = ?, spentDay = ?, updatedMs = ? WHERE walletID = ? AND userID = ?"
+	deleteMember     = "DELETE FROM WalletMember WHERE walletID = ? AND userID = ?"
+)
+
+func NewMember(db *sqlx.DB, a audit.Audit) Member {
+	return &impl{
+		db:    db,
+		audit: a,
+	}
+}
+
+type impl struct {
+	db    *sqlx.DB
+	audit audit.Audit
+}
+
+// resource names the membership in the audit trail
+func resource(walletID, userID string) string {
+	return "wallet:" + walletID + ":member:" + userID
+}
+
+// lockMember returns the locked member of the wallet, nil if the user is not a member
+func (im *impl) lockMember(ctx context.Context, tx *sqlx.Tx, walletID, userID string) (interface{}, error) {
+	members := []*mWallet.Member{}
+	if err := tx.SelectContext(ctx, &members, lockMember, walletID, userID); err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("SelectContext failed in Member.lockMember")
+		return nil, err
+	}
+
+	if len(members) == 0 {
+		return nil, nil
+	}
+	return members[0], nil
+}
+
+func (im *impl) GetMember(ctx context.Context, walletID, userID string) (*mWallet.Member, error) {
+	members := []*mWallet.Member{}
+	if err := im.db.SelectContext(ctx, &members, queryMember, walletID, userID); err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("SelectContext failed in Member.GetMember")
+		return nil, err
+	}
+
+	if len(members) == 0 {
+		return nil, ErrMemberNotExist
+	}
+	return members[0], nil
+}
+
+func (im *impl) LockMember(ctx context.Context, walletID, userID string) (*mWallet.Member, error) {
+	members := []*mWallet.Member{}
+	if err := sql.Transactx(ctx, im.db, func(tx *sqlx.Tx) error {
+		return tx.SelectContext(ctx, &members, lockMember, walletID, userID)
+	}); err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("SelectContext failed in Member.LockMember")
+		return nil, err
+	}
+
+	if len(members) == 0 {
+		return nil, ErrMemberNotExist
+	}
+	return members[0], nil
+}
+
+func (im *impl) ListMembers(ctx context.Context, walletID string) ([]*mWallet.Member, error) {
+	members := []*mWallet.Member{}
+	if err := im.db.SelectContext(ctx, &members, queryMembers, walletID); err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("SelectContext failed in Member.ListMembers")
+		return nil, err
+	}
+	return members, nil
+}
+
+func (im *impl) ListMemberships(ctx context.Context, userID string) ([]*mWallet.Member, error) {
+	members := []*mWallet.Member{}
+	if err := im.db.SelectContext(ctx, &members, queryMemberships, userID); err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("SelectContext failed in Member.ListMemberships")
+		return nil, err
+	}
+	return members, nil
+}
+
+func (im *impl) SaveMember(ctx context.Context, m *mWallet.Member) error {
+	return sql.TransactCtx(ctx, im.db, func(ctx context.Context, tx *sqlx.Tx) error {
+		before, err := im.lockMember(ctx, tx, m.WalletID, m.UserID)
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, upsertMember, m.WalletID, m.UserID, m.Role, m.DailyCap.Amount, m.DailyCap.Currency, m.CreatedMs, m.UpdatedMs); err != nil {
+			logger.FromContext(ctx).WithField("err", err).Error("ExecContext failed in Member.SaveMember")
+			return err
+		}
+
+		if err := im.audit.Record(ctx, mAudit.Action_CONFIG_CHANGE, resource(m.WalletID, m.UserID), before, m); err != nil {
+			logger.FromContext(ctx).WithField("err", err).Error("audit.Record failed in Member.SaveMember")
+			return err
+		}
+		return nil
+	})
+}
+
+func (im *impl) UpdateSpent(ctx context.Context, m *mWallet.Member) error {
+	return sql.Transactx(ctx, im.db, func(tx *sqlx.Tx) error {
+		if _, err := tx.ExecContext(ctx, updateSpent, m.SpentToday.Amount, m.SpentDay, m.UpdatedMs, m.WalletID, m.UserID); err != nil {
+			logger.FromContext(ctx).WithField("err", err).Error("ExecContext failed in Member.UpdateSpent")
+			return err
+		}
+		return nil
+	})
+}
+
+func (im *impl) RemoveMember(ctx context.Context, walletID, userID string) error {
+	return sql.TransactCtx(ctx, im.db, func(ctx context.Context, tx *sqlx.Tx) error {
+		before, err := im.lockMember(ctx, tx, walletID, userID)
+		if err != nil {
+			return err
+		} else if before == nil {
+			return nil
+		}
+
+		if _, err := tx.ExecContext(ctx, deleteMember, walletID, userID); err != nil {
+			logger.FromContext(ctx).WithField("err", err).Error("ExecContext failed in Member.RemoveMember")
+			return err
+		}
+
+		if err := im.audit.Record(ctx, mAudit.Action_CONFIG_CHANGE, resource(walletID, userID), before, nil); err != nil {
+			logger.FromContext(ctx).WithField("err", err).Error("audit.Record failed in Member.RemoveMember")
+			return err
+		}
+		return nil
+	})
+}
@@ -0,0 +1,36 @@
+package member
+
+import (
+	"context"
+	"fmt"
+
+	mWallet "github.com/n3k0fi5t/wallet/app/models/wallet"
+)
+
+var (
+	// ErrMemberNotExist means query member not exist
+	ErrMemberNotExist = fmt.Errorf("Member not exist")
+)
+
+type Member interface {
+	// GetMember get the membership of the user on the wallet
+	GetMember(ctx context.Context, walletID, userID string) (*mWallet.Member, error)
+
+	// LockMember get the membership and locks it until the transaction carried by context ends
+	LockMember(ctx context.Context, walletID, userID string) (*mWallet.Member, error)
+
+	// ListMembers list members of the wallet, the oldest first
+	ListMembers(ctx context.Context, walletID string) ([]*mWallet.Member, error)
+
+	// ListMemberships list memberships of the user on wallets of others, the oldest first
+	ListMemberships(ctx context.Context, userID string) ([]*mWallet.Member, error)
+
+	// SaveMember adds the member or updates role and daily cap of the existing one
+	SaveMember(ctx context.Context, member *mWallet.Member) error
+
+	// UpdateSpent saves the amount the member paid out in the day
+	UpdateSpent(ctx context.Context, member *mWallet.Member) error
+
+	// RemoveMember removes the user from the wallet
+	RemoveMember(ctx context.Context, walletID, userID string) error
+}
@@ -0,0 +1,178 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	mEvent "github.com/n3k0fi5t/wallet/app/models/event"
+	"github.com/n3k0fi5t/wallet/app/util"
+	"github.com/n3k0fi5t/wallet/common/logger"
+	"github.com/n3k0fi5t/wallet/common/sql"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	insertOutbox     = "INSERT INTO Outbox (accountID, payload, createdMs) VALUES (?, ?, ?)"
+	queryPending     = "SELECT id, accountID, payload, leaseUntilMs FROM Outbox WHERE publishedMs IS NULL AND deadLetterMs IS NULL ORDER BY id LIMIT ? FOR UPDATE"
+	updateLease      = "UPDATE Outbox SET leaseUntilMs = ? WHERE id IN (?)"
+	updatePublished  = "UPDATE Outbox SET publishedMs = ? WHERE id IN (?)"
+	updateDeadLetter = "UPDATE Outbox SET deadLetterMs = ? WHERE id IN (?)"
+)
+
+var (
+	timeNowMs = util.TimeNowMs
+)
+
+func NewOutbox(db *sqlx.DB) Outbox {
+	return &impl{
+		db: db,
+	}
+}
+
+type impl struct {
+	db *sqlx.DB
+}
+
+type record struct {
+	ID           int64  `db:"id"`
+	AccountID    string `db:"accountID"`
+	Payload      []byte `db:"payload"`
+	LeaseUntilMs int64  `db:"leaseUntilMs"`
+}
+
+func (im *impl) Add(ctx context.Context, events ...*mEvent.Event) error {
+	nowMs := timeNowMs()
+	return sql.Transactx(ctx, im.db, func(tx *sqlx.Tx) error {
+		for _, e := range events {
+			payload, err := json.Marshal(e)
+			if err != nil {
+				return err
+			}
+
+			if _, err := tx.ExecContext(ctx, insertOutbox, e.AccountID, payload, nowMs); err != nil {
+				logger.FromContext(ctx).WithField("err", err).Error("ExecContext failed in Outbox.Add")
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (im *impl) Claim(ctx context.Context, limit int, lease time.Duration) ([]*mEvent.Event, error) {
+	nowMs := timeNowMs()
+	var events []*mEvent.Event
+	// the claim is a short transaction of its own, claims of relays take turns and never wait on publishing
+	err := sql.Transactx(ctx, im.db, func(tx *sqlx.Tx) error {
+		records := []*record{}
+		if err := tx.SelectContext(ctx, &records, queryPending, limit); err != nil {
+			logger.FromContext(ctx).WithField("err", err).Error("SelectContext failed in Outbox.Claim")
+			return err
+		}
+
+		var sequences, corrupt []int64
+		events, sequences, corrupt = claimable(ctx, records, nowMs)
+		// a payload that can not be decoded never will be, it is dead lettered so it does not stop the claims
+		if err := im.markDeadLetter(ctx, tx, corrupt, nowMs); err != nil {
+			return err
+		}
+
+		return im.setLease(ctx, tx, sequences, nowMs+lease.Milliseconds())
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+// claimable returns the events to claim among pending records and their sequences, and the sequences of records whose
+// payload can not be decoded. An account with an event leased by another relay waits for it, to keep events of the
+// account in order
+func claimable(ctx context.Context, records []*record, nowMs int64) ([]*mEvent.Event, []int64, []int64) {
+	events := []*mEvent.Event{}
+	sequences, corrupt := []int64{}, []int64{}
+	blocked := map[string]bool{}
+	for _, r := range records {
+		if r.LeaseUntilMs > nowMs {
+			blocked[r.AccountID] = true
+		}
+		if blocked[r.AccountID] {
+			continue
+		}
+
+		e := &mEvent.Event{}
+		if err := json.Unmarshal(r.Payload, e); err != nil {
+			logger.FromContext(ctx).WithFields(logrus.Fields{
+				"err": err,
+				"id":  r.ID,
+			}).Error("json.Unmarshal failed in Outbox.Claim, dead letter the event")
+			corrupt = append(corrupt, r.ID)
+			continue
+		}
+		e.Sequence = r.ID
+		events = append(events, e)
+		sequences = append(sequences, r.ID)
+	}
+	return events, sequences, corrupt
+}
+
+func (im *impl) markDeadLetter(ctx context.Context, tx *sqlx.Tx, sequences []int64, nowMs int64) error {
+	if len(sequences) == 0 {
+		return nil
+	}
+
+	query, args, err := sqlx.In(updateDeadLetter, nowMs, sequences)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, tx.Rebind(query), args...); err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("ExecContext failed in Outbox.markDeadLetter")
+		return err
+	}
+	return nil
+}
+
+func (im *impl) setLease(ctx context.Context, tx *sqlx.Tx, sequences []int64, leaseUntilMs int64) error {
+	if len(sequences) == 0 {
+		return nil
+	}
+
+	query, args, err := sqlx.In(updateLease, leaseUntilMs, sequences)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, tx.Rebind(query), args...); err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("ExecContext failed in Outbox.setLease")
+		return err
+	}
+	return nil
+}
+
+func (im *impl) Release(ctx context.Context, sequences []int64) error {
+	return sql.Transactx(ctx, im.db, func(tx *sqlx.Tx) error {
+		return im.setLease(ctx, tx, sequences, 0)
+	})
+}
+
+func (im *impl) MarkPublished(ctx context.Context, sequences []int64) error {
+	if len(sequences) == 0 {
+		return nil
+	}
+
+	query, args, err := sqlx.In(updatePublished, timeNowMs(), sequences)
+	if err != nil {
+		return err
+	}
+
+	return sql.Transactx(ctx, im.db, func(tx *sqlx.Tx) error {
+		if _, err := tx.ExecContext(ctx, tx.Rebind(query), args...); err != nil {
+			logger.FromContext(ctx).WithField("err", err).Error("ExecContext failed in Outbox.MarkPublished")
+			return err
+		}
+		return nil
+	})
+}
@@ -0,0 +1,42 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	mEvent "github.com/n3k0fi5t/wallet/app/models/event"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	mockAccountID1 = "935f871a-660f-4f19-801e-916c04bb0324"
+	mockAccountID2 = "a89b7b78-b9c1-4129-8cff-380bf53f3a49"
+)
+
+func payload(t *testing.T, eventID, accountID string) []byte {
+	b, err := json.Marshal(&mEvent.Event{EventID: eventID, AccountID: accountID})
+	require.NoError(t, err)
+	return b
+}
+
+func TestClaimable(t *testing.T) {
+	nowMs := int64(1650000000000)
+	records := []*record{
+		{ID: 1, AccountID: mockAccountID1, Payload: []byte("{corrupt")},
+		{ID: 2, AccountID: mockAccountID1, Payload: payload(t, "e2", mockAccountID1)},
+		{ID: 3, AccountID: mockAccountID2, Payload: payload(t, "e3", mockAccountID2), LeaseUntilMs: nowMs + 1000},
+		{ID: 4, AccountID: mockAccountID2, Payload: []byte("{corrupt")},
+		{ID: 5, AccountID: mockAccountID1, Payload: payload(t, "e5", mockAccountID1)},
+	}
+
+	// the corrupt payload is dead lettered and the claim goes on with later events, of its account as well
+	events, sequences, corrupt := claimable(context.Background(), records, nowMs)
+	require.Equal(t, []int64{2, 5}, sequences)
+	require.Len(t, events, 2)
+	require.Equal(t, "e2", events[0].EventID)
+	require.Equal(t, int64(2), events[0].Sequence)
+	require.Equal(t, "e5", events[1].EventID)
+	// events of an account waiting for a lease are left alone, corrupt or not
+	require.Equal(t, []int64{1}, corrupt)
+}
@@ -0,0 +1,24 @@
+package outbox
+
+import (
+	"context"
+	"time"
+
+	mEvent "github.com/n3k0fi5t/wallet/app/models/event"
+)
+
+type Outbox interface {
+	// Add appends events, it joins the transaction carried by context so events commit with the change
+	Add(ctx context.Context, events ...*mEvent.Event) error
+
+	// Claim leases unpublished events in sequence order and commits, so rows are not locked while they are
+	// published. Events leased by another relay, and later events of their accounts, are skipped until the lease ends
+	// Events whose payload can not be decoded are dead lettered and never claimed again
+	Claim(ctx context.Context, limit int, lease time.Duration) ([]*mEvent.Event, error)
+
+	// MarkPublished marks events of the sequences as published
+	MarkPublished(ctx context.Context, sequences []int64) error
+
+	// Release ends leases of events of the sequences, so the next claim takes them again
+	Release(ctx context.Context, sequences []int64) error
+}
@@ -0,0 +1,113 @@
+package payment
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+	mPayment "github.com/n3k0fi5t/wallet/app/models/payment"
+	"github.com/n3k0fi5t/wallet/common/logger"
+	"github.com/n3k0fi5t/wallet/common/sql"
+)
+
+const (
+	requestColumns = "id, requestID, requesterID, payerID, amount AS `amount.amount`, currency AS `amount.currency`, memo, status, tradeID, expiresMs, createdMs, updatedMs"
+
+	insertRequest     = "INSERT INTO PaymentRequest (requestID, requesterID, payerID, amount, currency, memo, status, tradeID, expiresMs, createdMs, updatedMs) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"
+	queryRequest      = "SELECT " + requestColumns + " FROM PaymentRequest WHERE requestID = ?"
+	lockRequest       = queryRequest + " FOR UPDATE"
+	updateRequest     = "UPDATE PaymentRequest SET status = ?, tradeID = ?, updatedMs = ? WHERE requestID = ?"
+	queryRequestsFrom = "SELECT " + requestColumns + " FROM PaymentRequest WHERE "
+
+	// conditions of listed requests, the column is requesterID or payerID
+	condAll     = " = ?"
+	condStatus  = " = ? AND status = ?"
+	condPending = " = ? AND status = 'PENDING' AND expiresMs > ?"
+	condExpired = " = ? AND status = 'PENDING' AND expiresMs <= ?"
+	orderPage   = " ORDER BY id DESC LIMIT ? OFFSET ?"
+)
+
+func NewPayment(db *sqlx.DB) Payment {
+	return &impl{
+		db: db,
+	}
+}
+
+type impl struct {
+	db *sqlx.DB
+}
+
+func (im *impl) CreateRequest(ctx context.Context, r *mPayment.Request) error {
+	if _, err := im.db.ExecContext(ctx, insertRequest, r.RequestID, r.RequesterID, r.PayerID, r.Amount.Amount, r.Amount.Currency, r.Memo, r.Status, r.TradeID, r.ExpiresMs, r.CreatedMs, r.UpdatedMs); err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("ExecContext failed in Payment.CreateRequest")
+		return err
+	}
+	return nil
+}
+
+func (im *impl) GetRequest(ctx context.Context, requestID string) (*mPayment.Request, error) {
+	requests := []*mPayment.Request{}
+	if err := im.db.SelectContext(ctx, &requests, queryRequest, requestID); err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("SelectContext failed in Payment.GetRequest")
+		return nil, err
+	}
+
+	if len(requests) == 0 {
+		return nil, ErrRequestNotExist
+	}
+	return requests[0], nil
+}
+
+func (im *impl) LockRequest(ctx context.Context, requestID string) (*mPayment.Request, error) {
+	requests := []*mPayment.Request{}
+	if err := sql.Transactx(ctx, im.db, func(tx *sqlx.Tx) error {
+		return tx.SelectContext(ctx, &requests, lockRequest, requestID)
+	}); err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("SelectContext failed in Payment.LockRequest")
+		return nil, err
+	}
+
+	if len(requests) == 0 {
+		return nil, ErrRequestNotExist
+	}
+	return requests[0], nil
+}
+
+func (im *impl) ListRequests(ctx context.Context, accountID string, direction mPayment.Direction, status mPayment.Status, nowMs int64, offset, limit int) ([]*mPayment.Request, error) {
+	query := queryRequestsFrom + "payerID"
+	if direction == mPayment.Direction_OUTGOING {
+		query = queryRequestsFrom + "requesterID"
+	}
+
+	args := []interface{}{accountID}
+	switch status {
+	case "":
+		query += condAll
+	case mPayment.Status_PENDING:
+		query += condPending
+		args = append(args, nowMs)
+	case mPayment.Status_EXPIRED:
+		query += condExpired
+		args = append(args, nowMs)
+	default:
+		query += condStatus
+		args = append(args, status)
+	}
+	args = append(args, limit, offset)
+
+	requests := []*mPayment.Request{}
+	if err := im.db.SelectContext(ctx, &requests, query+orderPage, args...); err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("SelectContext failed in Payment.ListRequests")
+		return nil, err
+	}
+	return requests, nil
+}
+
+func (im *impl) UpdateRequest(ctx context.Context, r *mPayment.Request) error {
+	return sql.Transactx(ctx, im.db, func(tx *sqlx.Tx) error {
+		if _, err := tx.ExecContext(ctx, updateRequest, r.Status, r.TradeID, r.UpdatedMs, r.RequestID); err != nil {
+			logger.FromContext(ctx).WithField("err", err).Error("ExecContext failed in Payment.UpdateRequest")
+			return err
+		}
+		return nil
+	})
+}
@@ -0,0 +1,32 @@
+package payment
+
+import (
+	"context"
+	"fmt"
+
+	mPayment "github.com/n3k0fi5t/wallet/app/models/payment"
+)
+
+var (
+	// ErrRequestNotExist means query payment request not exist
+	ErrRequestNotExist = fmt.Errorf("Payment request not exist")
+)
+
+type Payment interface {
+	// CreateRequest creates a payment request
+	CreateRequest(ctx context.Context, request *mPayment.Request) error
+
+	// GetRequest get a payment request by ID
+	GetRequest(ctx context.Context, requestID string) (*mPayment.Request, error)
+
+	// LockRequest get a payment request and locks it until the transaction carried by context ends,
+	// so that concurrent transitions of the request take turns
+	LockRequest(ctx context.Context, requestID string) (*mPayment.Request, error)
+
+	// ListRequests list requests of the account on the direction, newest first.
+	// Empty status for all, pending and expired are told apart by nowMs
+	ListRequests(ctx context.Context, accountID string, direction mPayment.Direction, status mPayment.Status, nowMs int64, offset, limit int) ([]*mPayment.Request, error)
+
+	// UpdateRequest saves status, trade and update time of the request
+	UpdateRequest(ctx context.Context, request *mPayment.Request) error
+}
@@ -0,0 +1,190 @@
+package webhook
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+	mAudit "github.com/n3k0fi5t/wallet/app/models/audit"
+	mWebhook "github.com/n3k0fi5t/wallet/app/models/webhook"
+	"github.com/n3k0fi5t/wallet/app/repository/audit"
+	"github.com/n3k0fi5t/wallet/common/logger"
+	"github.com/n3k0fi5t/wallet/common/sql"
+)
+
+const (
+	endpointColumns = "id, endpointID, accountID, url, secret, eventTypes, createdMs"
+	deliveryColumns = "id, deliveryID, endpointID, accountID, eventID, eventType, payload, status, attempts, nextAttemptMs, lastStatusCode, lastError, createdMs, updatedMs"
+
+	insertEndpoint      = "INSERT INTO WebhookEndpoint (endpointID, accountID, url, secret, eventTypes, createdMs) VALUES (?, ?, ?, ?, ?, ?)"
+	queryEndpoints      = "SELECT " + endpointColumns + " FROM WebhookEndpoint WHERE accountID = ? ORDER BY id"
+	queryEndpoint       = "SELECT " + endpointColumns + " FROM WebhookEndpoint WHERE endpointID = ?"
+	lockOwnEndpoint     = "SELECT " + endpointColumns + " FROM WebhookEndpoint WHERE accountID = ? AND endpointID = ? FOR UPDATE"
+	deleteEndpoint      = "DELETE FROM WebhookEndpoint WHERE accountID = ? AND endpointID = ?"
+	insertDelivery      = "INSERT IGNORE INTO WebhookDelivery (deliveryID, endpointID, accountID, eventID, eventType, payload, status, attempts, nextAttemptMs, lastStatusCode, lastError, createdMs, updatedMs) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"
+	queryDueDeliveries  = "SELECT " + deliveryColumns + " FROM WebhookDelivery WHERE status = ? AND nextAttemptMs <= ? ORDER BY nextAttemptMs LIMIT ? FOR UPDATE"
+	updateLease         = "UPDATE WebhookDelivery SET nextAttemptMs = ? WHERE id IN (?)"
+	updateDelivery      = "UPDATE WebhookDelivery SET status = ?, attempts = ?, nextAttemptMs = ?, lastStatusCode = ?, lastError = ?, updatedMs = ? WHERE deliveryID = ?"
+	queryDeliveries     = "SELECT " + deliveryColumns + " FROM WebhookDelivery WHERE accountID = ? AND endpointID = ? ORDER BY id DESC LIMIT ? OFFSET ?"
+	queryStatDeliveries = "SELECT " + deliveryColumns + " FROM WebhookDelivery WHERE accountID = ? AND endpointID = ? AND status = ? ORDER BY id DESC LIMIT ? OFFSET ?"
+	queryDelivery       = "SELECT " + deliveryColumns + " FROM WebhookDelivery WHERE accountID = ? AND deliveryID = ?"
+)
+
+func NewWebhook(db *sqlx.DB, a audit.Audit) Webhook {
+	return &impl{
+		db:    db,
+		audit: a,
+	}
+}
+
+type impl struct {
+	db    *sqlx.DB
+	audit audit.Audit
+}
+
+// audited is the endpoint as recorded in the audit trail, signing secrets are credentials and never reach it
+func audited(e *mWebhook.Endpoint) *mWebhook.Endpoint {
+	a := *e
+	a.Secret = ""
+	return &a
+}
+
+func (im *impl) CreateEndpoint(ctx context.Context, e *mWebhook.Endpoint) error {
+	return sql.TransactCtx(ctx, im.db, func(ctx context.Context, tx *sqlx.Tx) error {
+		if _, err := tx.ExecContext(ctx, insertEndpoint, e.EndpointID, e.AccountID, e.URL, e.Secret, e.EventTypes, e.CreatedMs); err != nil {
+			logger.FromContext(ctx).WithField("err", err).Error("ExecContext failed in Webhook.CreateEndpoint")
+			return err
+		}
+
+		if err := im.audit.Record(ctx, mAudit.Action_CONFIG_CHANGE, "webhook:"+e.EndpointID, nil, audited(e)); err != nil {
+			logger.FromContext(ctx).WithField("err", err).Error("audit.Record failed in Webhook.CreateEndpoint")
+			return err
+		}
+		return nil
+	})
+}
+
+func (im *impl) ListEndpoints(ctx context.Context, accountID string) ([]*mWebhook.Endpoint, error) {
+	endpoints := []*mWebhook.Endpoint{}
+	if err := im.db.SelectContext(ctx, &endpoints, queryEndpoints, accountID); err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("SelectContext failed in Webhook.ListEndpoints")
+		return nil, err
+	}
+	return endpoints, nil
+}
+
+func (im *impl) DeleteEndpoint(ctx context.Context, accountID, endpointID string) error {
+	return sql.TransactCtx(ctx, im.db, func(ctx context.Context, tx *sqlx.Tx) error {
+		endpoints := []*mWebhook.Endpoint{}
+		if err := tx.SelectContext(ctx, &endpoints, lockOwnEndpoint, accountID, endpointID); err != nil {
+			logger.FromContext(ctx).WithField("err", err).Error("SelectContext failed in Webhook.DeleteEndpoint")
+			return err
+		} else if len(endpoints) == 0 {
+			return ErrEndpointNotExist
+		}
+
+		if _, err := tx.ExecContext(ctx, deleteEndpoint, accountID, endpointID); err != nil {
+			logger.FromContext(ctx).WithField("err", err).Error("ExecContext failed in Webhook.DeleteEndpoint")
+			return err
+		}
+
+		if err := im.audit.Record(ctx, mAudit.Action_CONFIG_CHANGE, "webhook:"+endpointID, audited(endpoints[0]), nil); err != nil {
+			logger.FromContext(ctx).WithField("err", err).Error("audit.Record failed in Webhook.DeleteEndpoint")
+			return err
+		}
+		return nil
+	})
+}
+
+func (im *impl) GetEndpoint(ctx context.Context, endpointID string) (*mWebhook.Endpoint, error) {
+	endpoints := []*mWebhook.Endpoint{}
+	if err := im.db.SelectContext(ctx, &endpoints, queryEndpoint, endpointID); err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("SelectContext failed in Webhook.GetEndpoint")
+		return nil, err
+	}
+
+	if len(endpoints) == 0 {
+		return nil, ErrEndpointNotExist
+	}
+	return endpoints[0], nil
+}
+
+func (im *impl) CreateDeliveries(ctx context.Context, deliveries ...*mWebhook.Delivery) error {
+	return sql.Transactx(ctx, im.db, func(tx *sqlx.Tx) error {
+		for _, d := range deliveries {
+			if _, err := tx.ExecContext(ctx, insertDelivery, d.DeliveryID, d.EndpointID, d.AccountID, d.EventID, d.EventType, d.Payload,
+				d.Status, d.Attempts, d.NextAttemptMs, d.LastStatusCode, d.LastError, d.CreatedMs, d.UpdatedMs); err != nil {
+				logger.FromContext(ctx).WithField("err", err).Error("ExecContext failed in Webhook.CreateDeliveries")
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (im *impl) ClaimDue(ctx context.Context, nowMs, leaseUntilMs int64, limit int) ([]*mWebhook.Delivery, error) {
+	deliveries := []*mWebhook.Delivery{}
+	err := sql.Transactx(ctx, im.db, func(tx *sqlx.Tx) error {
+		if err := tx.SelectContext(ctx, &deliveries, queryDueDeliveries, mWebhook.DeliveryStatus_PENDING, nowMs, limit); err != nil {
+			logger.FromContext(ctx).WithField("err", err).Error("SelectContext failed in Webhook.ClaimDue")
+			return err
+		}
+
+		if len(deliveries) == 0 {
+			return nil
+		}
+
+		ids := make([]int, 0, len(deliveries))
+		for _, d := range deliveries {
+			ids = append(ids, d.ID)
+		}
+		query, args, err := sqlx.In(updateLease, leaseUntilMs, ids)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, tx.Rebind(query), args...); err != nil {
+			logger.FromContext(ctx).WithField("err", err).Error("ExecContext failed in Webhook.ClaimDue")
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+func (im *impl) UpdateDelivery(ctx context.Context, d *mWebhook.Delivery) error {
+	if _, err := im.db.ExecContext(ctx, updateDelivery, d.Status, d.Attempts, d.NextAttemptMs, d.LastStatusCode, d.LastError, d.UpdatedMs, d.DeliveryID); err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("ExecContext failed in Webhook.UpdateDelivery")
+		return err
+	}
+	return nil
+}
+
+func (im *impl) ListDeliveries(ctx context.Context, accountID, endpointID string, status mWebhook.DeliveryStatus, offset, limit int) ([]*mWebhook.Delivery, error) {
+	deliveries := []*mWebhook.Delivery{}
+	var err error
+	if status == "" {
+		err = im.db.SelectContext(ctx, &deliveries, queryDeliveries, accountID, endpointID, limit, offset)
+	} else {
+		err = im.db.SelectContext(ctx, &deliveries, queryStatDeliveries, accountID, endpointID, status, limit, offset)
+	}
+	if err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("SelectContext failed in Webhook.ListDeliveries")
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+func (im *impl) GetDelivery(ctx context.Context, accountID, deliveryID string) (*mWebhook.Delivery, error) {
+	deliveries := []*mWebhook.Delivery{}
+	if err := im.db.SelectContext(ctx, &deliveries, queryDelivery, accountID, deliveryID); err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("SelectContext failed in Webhook.GetDelivery")
+		return nil, err
+	}
+
+	if len(deliveries) == 0 {
+		return nil, ErrDeliveryNotExist
+	}
+	return deliveries[0], nil
+}
@@ -0,0 +1,46 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+
+	mWebhook "github.com/n3k0fi5t/wallet/app/models/webhook"
+)
+
+var (
+	// ErrEndpointNotExist means query endpoint not exist or not owned by the account
+	ErrEndpointNotExist = fmt.Errorf("Endpoint not exist")
+
+	// ErrDeliveryNotExist means query delivery not exist or not owned by the account
+	ErrDeliveryNotExist = fmt.Errorf("Delivery not exist")
+)
+
+type Webhook interface {
+	// CreateEndpoint registers an endpoint
+	CreateEndpoint(ctx context.Context, endpoint *mWebhook.Endpoint) error
+
+	// ListEndpoints list endpoints of the account
+	ListEndpoints(ctx context.Context, accountID string) ([]*mWebhook.Endpoint, error)
+
+	// DeleteEndpoint deletes an endpoint of the account
+	DeleteEndpoint(ctx context.Context, accountID, endpointID string) error
+
+	// GetEndpoint get an endpoint by ID
+	GetEndpoint(ctx context.Context, endpointID string) (*mWebhook.Endpoint, error)
+
+	// CreateDeliveries creates deliveries, the ones of an already delivered (endpointID, eventID) are ignored
+	CreateDeliveries(ctx context.Context, deliveries ...*mWebhook.Delivery) error
+
+	// ClaimDue picks pending deliveries due at nowMs and postpones them to leaseUntilMs,
+	// so that other dispatchers do not send them while they are being sent
+	ClaimDue(ctx context.Context, nowMs, leaseUntilMs int64, limit int) ([]*mWebhook.Delivery, error)
+
+	// UpdateDelivery saves status, attempts, next attempt and last result of the delivery
+	UpdateDelivery(ctx context.Context, delivery *mWebhook.Delivery) error
+
+	// ListDeliveries list deliveries of the account's endpoint, newest first, empty status for all
+	ListDeliveries(ctx context.Context, accountID, endpointID string, status mWebhook.DeliveryStatus, offset, limit int) ([]*mWebhook.Delivery, error)
+
+	// GetDelivery get a delivery of the account
+	GetDelivery(ctx context.Context, accountID, deliveryID string) (*mWebhook.Delivery, error)
+}
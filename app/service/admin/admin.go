@@ -0,0 +1,67 @@
+package admin
+
+import (
+	"context"
+	"fmt"
+
+	mAdmin "github.com/n3k0fi5t/wallet/app/models/admin"
+	mAudit "github.com/n3k0fi5t/wallet/app/models/audit"
+	mBank "github.com/n3k0fi5t/wallet/app/models/bank"
+	"github.com/n3k0fi5t/wallet/app/money"
+)
+
+var (
+	// ErrPermissionDenied means the operator's role is not granted to do the action
+	ErrPermissionDenied = fmt.Errorf("Permission denied")
+
+	// ErrInvalidReasonCode means the reason code is not one of the known codes
+	ErrInvalidReasonCode = fmt.Errorf("Invalid reason code")
+
+	// ErrInvalidAmount means the adjustment amount is zero
+	ErrInvalidAmount = fmt.Errorf("Invalid amount")
+
+	// ErrTradeNotRefundable means the trade has more than one leg, e.g. a currency exchange, or is not between wallets of users
+	ErrTradeNotRefundable = fmt.Errorf("Trade not refundable")
+
+	// ErrInvalidCreditLimit means the credit limit is negative
+	ErrInvalidCreditLimit = fmt.Errorf("Invalid credit limit")
+
+	// ErrCreditNotAllowed means the account is a system account, only wallets of users have credit lines
+	ErrCreditNotAllowed = fmt.Errorf("Credit not allowed")
+)
+
+type Service interface {
+	// GetAccount get account information of any account
+	GetAccount(ctx context.Context, operator *mAdmin.Operator, accountID string) (*mBank.Account, error)
+
+	// ListTransactions list transaction logs of any account, newest first
+	ListTransactions(ctx context.Context, operator *mAdmin.Operator, accountID string, offset, limit int) ([]*mBank.Transaction, error)
+
+	// AdjustBalance credits (positive amount) or debits (negative amount) the account in its currency
+	AdjustBalance(ctx context.Context, operator *mAdmin.Operator, accountID string, amount money.Money, reason mAdmin.ReasonCode, note string) (string, error)
+
+	// FreezeAccount stops the account from paying out
+	FreezeAccount(ctx context.Context, operator *mAdmin.Operator, accountID string, reason mAdmin.ReasonCode, note string) error
+
+	// UnfreezeAccount lifts the freeze of the account
+	UnfreezeAccount(ctx context.Context, operator *mAdmin.Operator, accountID string, reason mAdmin.ReasonCode, note string) error
+
+	// SetCreditLimit grants or changes the credit line of a wallet in its currency, limit 0 revokes it. The limit can not
+	// be less than the overdrawn amount of the wallet
+	SetCreditLimit(ctx context.Context, operator *mAdmin.Operator, accountID string, limit money.Money, reason mAdmin.ReasonCode, note string) (*mBank.Account, error)
+
+	// RevokeCreditLimit sets the credit line of a wallet to 0, it fails while the wallet is overdrawn
+	RevokeCreditLimit(ctx context.Context, operator *mAdmin.Operator, accountID string, reason mAdmin.ReasonCode, note string) (*mBank.Account, error)
+
+	// ListOverdrawn list accounts below zero, the most overdrawn first
+	ListOverdrawn(ctx context.Context, operator *mAdmin.Operator, offset, limit int) ([]*mBank.Account, error)
+
+	// ListSystemAccounts list the chart of system accounts with their balances
+	ListSystemAccounts(ctx context.Context, operator *mAdmin.Operator) ([]*mBank.Account, error)
+
+	// Refund reverses a trade, money goes back from the receiver to the payer
+	Refund(ctx context.Context, operator *mAdmin.Operator, tradeID string, reason mAdmin.ReasonCode, note string) (string, error)
+
+	// ListAuditEntries query the audit trail, newest first
+	ListAuditEntries(ctx context.Context, operator *mAdmin.Operator, filter *mAudit.Filter, offset, limit int) ([]*mAudit.Entry, error)
+}
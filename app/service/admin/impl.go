@@ -0,0 +1,369 @@
+package admin
+
+import (
+	"context"
+
+	mAdmin "github.com/n3k0fi5t/wallet/app/models/admin"
+	mAudit "github.com/n3k0fi5t/wallet/app/models/audit"
+	mBank "github.com/n3k0fi5t/wallet/app/models/bank"
+	"github.com/n3k0fi5t/wallet/app/money"
+	"github.com/n3k0fi5t/wallet/app/repository/admin"
+	"github.com/n3k0fi5t/wallet/app/repository/audit"
+	"github.com/n3k0fi5t/wallet/app/repository/bank"
+	"github.com/n3k0fi5t/wallet/app/util"
+	"github.com/n3k0fi5t/wallet/common/logger"
+	"github.com/n3k0fi5t/wallet/common/sql"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	timeNowMs = util.TimeNowMs
+)
+
+func NewAdmin(t sql.Transactor, b bank.Bank, a admin.Admin, au audit.Audit) Service {
+	return &impl{
+		transactor: t,
+		bank:       b,
+		admin:      a,
+		audit:      au,
+	}
+}
+
+type impl struct {
+	transactor sql.Transactor
+	bank       bank.Bank
+	admin      admin.Admin
+	audit      audit.Audit
+}
+
+func authorize(operator *mAdmin.Operator, perm mAdmin.Permission) error {
+	if operator == nil || !operator.Role.Can(perm) {
+		return ErrPermissionDenied
+	}
+	return nil
+}
+
+func (im *impl) record(ctx context.Context, operator *mAdmin.Operator, action *mAdmin.Action) error {
+	action.OperatorID = operator.OperatorID
+	action.Role = operator.Role
+	action.TimestampMs = timeNowMs()
+	if err := im.admin.RecordAction(ctx, action); err != nil {
+		logger.FromContext(ctx).WithFields(logrus.Fields{
+			"err":    err,
+			"action": action,
+		}).Error("admin.RecordAction failed")
+		return err
+	}
+	return nil
+}
+
+func (im *impl) GetAccount(ctx context.Context, operator *mAdmin.Operator, accountID string) (*mBank.Account, error) {
+	if err := authorize(operator, mAdmin.Permission_VIEW_ACCOUNT); err != nil {
+		return nil, err
+	}
+
+	// reads of customer data leave a trace too, accounts not exist included as they are probes
+	if err := im.record(ctx, operator, &mAdmin.Action{
+		Action:    mAdmin.ActionType_VIEW_ACCOUNT,
+		AccountID: accountID,
+	}); err != nil {
+		return nil, err
+	}
+
+	account, err := im.bank.GetAccount(ctx, accountID)
+	if err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("bank.GetAccount failed in GetAccount")
+		return nil, err
+	}
+
+	return account, nil
+}
+
+func (im *impl) ListTransactions(ctx context.Context, operator *mAdmin.Operator, accountID string, offset, limit int) ([]*mBank.Transaction, error) {
+	if err := authorize(operator, mAdmin.Permission_VIEW_ACCOUNT); err != nil {
+		return nil, err
+	}
+
+	if err := im.record(ctx, operator, &mAdmin.Action{
+		Action:    mAdmin.ActionType_VIEW_TRANSACTIONS,
+		AccountID: accountID,
+	}); err != nil {
+		return nil, err
+	}
+
+	txs, err := im.bank.ListTransactions(ctx, accountID, offset, limit)
+	if err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("bank.ListTransactions failed in ListTransactions")
+		return nil, err
+	}
+
+	return txs, nil
+}
+
+// adjustmentAccount returns the system account an adjustment for the reason is booked against, goodwill is a loss the
+// bank absorbs and the others wait in suspense until they are reconciled
+func adjustmentAccount(reason mAdmin.ReasonCode, currency string) string {
+	if reason == mAdmin.ReasonCode_GOODWILL {
+		return mBank.SystemAccountID(mBank.Purpose_WRITE_OFF, currency)
+	}
+	return mBank.SystemAccountID(mBank.Purpose_SUSPENSE, currency)
+}
+
+func (im *impl) AdjustBalance(ctx context.Context, operator *mAdmin.Operator, accountID string, amount money.Money, reason mAdmin.ReasonCode, note string) (string, error) {
+	if err := authorize(operator, mAdmin.Permission_ADJUST_BALANCE); err != nil {
+		return "", err
+	} else if !reason.IsValid() {
+		return "", ErrInvalidReasonCode
+	} else if amount.IsZero() {
+		return "", ErrInvalidAmount
+	}
+
+	account, err := im.bank.GetAccount(ctx, accountID)
+	if err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("bank.GetAccount failed in AdjustBalance")
+		return "", err
+	} else if amount.Currency != account.Currency() {
+		return "", bank.ErrCurrencyMismatch
+	}
+
+	counterAccount := adjustmentAccount(reason, account.Currency())
+	deal := &mBank.Dealing{
+		FromAccountID: counterAccount,
+		ToAccountID:   accountID,
+		Amount:        amount,
+	}
+	if amount.IsNegative() {
+		debit, err := amount.Neg()
+		if err != nil {
+			return "", err
+		}
+		deal = &mBank.Dealing{
+			FromAccountID: accountID,
+			ToAccountID:   counterAccount,
+			Amount:        debit,
+		}
+	}
+
+	// the trade and its admin record commit together
+	tradeID := ""
+	if err := im.transactor.Transact(ctx, func(ctx context.Context) error {
+		tID, err := im.bank.Trade(ctx, deal)
+		if err != nil {
+			logger.FromContext(ctx).WithField("err", err).Error("bank.Trade failed in AdjustBalance")
+			return err
+		}
+		tradeID = tID
+
+		return im.record(ctx, operator, &mAdmin.Action{
+			Action:     mAdmin.ActionType_ADJUST_BALANCE,
+			AccountID:  accountID,
+			TradeID:    tradeID,
+			Amount:     &amount,
+			ReasonCode: reason,
+			Note:       note,
+		})
+	}); err != nil {
+		return "", err
+	}
+
+	return tradeID, nil
+}
+
+func (im *impl) setAccountStatus(ctx context.Context, operator *mAdmin.Operator, accountID string, status mBank.AccountStatus, reason mAdmin.ReasonCode, note string) error {
+	if err := authorize(operator, mAdmin.Permission_FREEZE_ACCOUNT); err != nil {
+		return err
+	} else if !reason.IsValid() {
+		return ErrInvalidReasonCode
+	}
+
+	actionType := mAdmin.ActionType_FREEZE
+	if status == mBank.AccountStatus_ACTIVE {
+		actionType = mAdmin.ActionType_UNFREEZE
+	}
+
+	return im.transactor.Transact(ctx, func(ctx context.Context) error {
+		if err := im.bank.SetAccountStatus(ctx, accountID, status); err != nil {
+			logger.FromContext(ctx).WithField("err", err).Error("bank.SetAccountStatus failed")
+			return err
+		}
+
+		return im.record(ctx, operator, &mAdmin.Action{
+			Action:     actionType,
+			AccountID:  accountID,
+			ReasonCode: reason,
+			Note:       note,
+		})
+	})
+}
+
+func (im *impl) FreezeAccount(ctx context.Context, operator *mAdmin.Operator, accountID string, reason mAdmin.ReasonCode, note string) error {
+	return im.setAccountStatus(ctx, operator, accountID, mBank.AccountStatus_FROZEN, reason, note)
+}
+
+func (im *impl) UnfreezeAccount(ctx context.Context, operator *mAdmin.Operator, accountID string, reason mAdmin.ReasonCode, note string) error {
+	return im.setAccountStatus(ctx, operator, accountID, mBank.AccountStatus_ACTIVE, reason, note)
+}
+
+func (im *impl) SetCreditLimit(ctx context.Context, operator *mAdmin.Operator, accountID string, limit money.Money, reason mAdmin.ReasonCode, note string) (*mBank.Account, error) {
+	if limit.IsNegative() {
+		return nil, ErrInvalidCreditLimit
+	}
+	return im.setCreditLimit(ctx, operator, accountID, &limit, reason, note)
+}
+
+func (im *impl) RevokeCreditLimit(ctx context.Context, operator *mAdmin.Operator, accountID string, reason mAdmin.ReasonCode, note string) (*mBank.Account, error) {
+	return im.setCreditLimit(ctx, operator, accountID, nil, reason, note)
+}
+
+// setCreditLimit sets the credit line of the wallet, nil limit revokes it in the currency of the wallet
+func (im *impl) setCreditLimit(ctx context.Context, operator *mAdmin.Operator, accountID string, limitPtr *money.Money, reason mAdmin.ReasonCode, note string) (*mBank.Account, error) {
+	if err := authorize(operator, mAdmin.Permission_GRANT_CREDIT); err != nil {
+		return nil, err
+	} else if !reason.IsValid() {
+		return nil, ErrInvalidReasonCode
+	}
+
+	account, err := im.bank.GetAccount(ctx, accountID)
+	if err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("bank.GetAccount failed in SetCreditLimit")
+		return nil, err
+	} else if account.Type != mBank.AccountType_USER {
+		return nil, ErrCreditNotAllowed
+	}
+
+	limit := money.New(0, account.Currency())
+	if limitPtr != nil {
+		limit = *limitPtr
+	}
+	if limit.Currency != account.Currency() {
+		return nil, bank.ErrCurrencyMismatch
+	}
+
+	// the credit line and its admin record commit together
+	if err := im.transactor.Transact(ctx, func(ctx context.Context) error {
+		if err := im.bank.SetCreditLimit(ctx, accountID, limit); err != nil {
+			logger.FromContext(ctx).WithField("err", err).Error("bank.SetCreditLimit failed in SetCreditLimit")
+			return err
+		}
+
+		return im.record(ctx, operator, &mAdmin.Action{
+			Action:     mAdmin.ActionType_CREDIT_LIMIT,
+			AccountID:  accountID,
+			Amount:     &limit,
+			ReasonCode: reason,
+			Note:       note,
+		})
+	}); err != nil {
+		return nil, err
+	}
+
+	// the account may be shared with concurrent readers, so it is copied
+	updated := *account
+	updated.CreditLimit = limit
+	return &updated, nil
+}
+
+func (im *impl) ListOverdrawn(ctx context.Context, operator *mAdmin.Operator, offset, limit int) ([]*mBank.Account, error) {
+	if err := authorize(operator, mAdmin.Permission_VIEW_ACCOUNT); err != nil {
+		return nil, err
+	}
+
+	accounts, err := im.bank.ListOverdrawn(ctx, offset, limit)
+	if err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("bank.ListOverdrawn failed in ListOverdrawn")
+		return nil, err
+	}
+
+	return accounts, nil
+}
+
+func (im *impl) ListSystemAccounts(ctx context.Context, operator *mAdmin.Operator) ([]*mBank.Account, error) {
+	if err := authorize(operator, mAdmin.Permission_VIEW_ACCOUNT); err != nil {
+		return nil, err
+	}
+
+	accounts, err := im.bank.ListSystemAccounts(ctx)
+	if err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("bank.ListSystemAccounts failed in ListSystemAccounts")
+		return nil, err
+	}
+
+	return accounts, nil
+}
+
+func (im *impl) Refund(ctx context.Context, operator *mAdmin.Operator, tradeID string, reason mAdmin.ReasonCode, note string) (string, error) {
+	if err := authorize(operator, mAdmin.Permission_REFUND); err != nil {
+		return "", err
+	} else if !reason.IsValid() {
+		return "", ErrInvalidReasonCode
+	}
+
+	entries, err := im.bank.GetTrade(ctx, tradeID)
+	if err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("bank.GetTrade failed in Refund")
+		return "", err
+	} else if len(entries) != 2 {
+		return "", ErrTradeNotRefundable
+	}
+
+	// money of escrows and holds moves by their own state machines, only trades between wallets are refunded here
+	for _, entry := range entries {
+		account, err := im.bank.GetAccount(ctx, entry.AccountID)
+		if err != nil {
+			logger.FromContext(ctx).WithField("err", err).Error("bank.GetAccount failed in Refund")
+			return "", err
+		} else if account.Type != mBank.AccountType_USER {
+			return "", ErrTradeNotRefundable
+		}
+	}
+
+	// reverse the double entries, the one credited pays back to the one debited
+	deal := &mBank.Dealing{
+		ReversalOf: tradeID,
+	}
+	for _, entry := range entries {
+		if entry.IsDebit() {
+			deal.ToAccountID = entry.AccountID
+		} else {
+			deal.FromAccountID = entry.AccountID
+		}
+		deal.Amount = entry.Amount
+	}
+
+	refundID := ""
+	if err := im.transactor.Transact(ctx, func(ctx context.Context) error {
+		tID, err := im.bank.Trade(ctx, deal)
+		if err != nil {
+			logger.FromContext(ctx).WithField("err", err).Error("bank.Trade failed in Refund")
+			return err
+		}
+		refundID = tID
+
+		return im.record(ctx, operator, &mAdmin.Action{
+			Action:     mAdmin.ActionType_REFUND,
+			AccountID:  deal.ToAccountID,
+			TradeID:    refundID,
+			Amount:     &deal.Amount,
+			ReasonCode: reason,
+			Note:       note,
+		})
+	}); err != nil {
+		return "", err
+	}
+
+	return refundID, nil
+}
+
+func (im *impl) ListAuditEntries(ctx context.Context, operator *mAdmin.Operator, filter *mAudit.Filter, offset, limit int) ([]*mAudit.Entry, error) {
+	if err := authorize(operator, mAdmin.Permission_VIEW_AUDIT); err != nil {
+		return nil, err
+	}
+
+	entries, err := im.audit.List(ctx, filter, offset, limit)
+	if err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("audit.List failed in ListAuditEntries")
+		return nil, err
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,641 @@
+package admin
+
+import (
+	"context"
+	"testing"
+
+	mdAdmin "github.com/n3k0fi5t/wallet/app/models/admin"
+	mdAudit "github.com/n3k0fi5t/wallet/app/models/audit"
+	mdBank "github.com/n3k0fi5t/wallet/app/models/bank"
+	"github.com/n3k0fi5t/wallet/app/money"
+	mockAdmin "github.com/n3k0fi5t/wallet/app/repository/admin/mocks"
+	mockAudit "github.com/n3k0fi5t/wallet/app/repository/audit/mocks"
+	"github.com/n3k0fi5t/wallet/app/repository/bank"
+	mockBank "github.com/n3k0fi5t/wallet/app/repository/bank/mocks"
+	"github.com/n3k0fi5t/wallet/common/sql/sqltest"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+// usd returns amount minor units of the currency of the mock account
+func usd(amount int64) money.Money {
+	return money.New(amount, "USD")
+}
+
+var (
+	mockCtx        = context.Background()
+	mockAccountID1 = "n3k0fi5t"
+	mockAccountID2 = "deadbeef"
+	mockTradeID    = "935f871a-660f-4f19-801e-916c04bb0324"
+	mockRefundID   = "a89b7b78-b9c1-4129-8cff-380bf53f3a49"
+	mockTimeMs     = int64(1650000000000)
+	mockAccount    = &mdBank.Account{
+		AccountID:   mockAccountID1,
+		Balance:     usd(3345678),
+		CreditLimit: usd(0),
+	}
+
+	mockSupport  = &mdAdmin.Operator{OperatorID: "Mori", Role: mdAdmin.Role_SUPPORT}
+	mockOperator = &mdAdmin.Operator{OperatorID: "Kronii", Role: mdAdmin.Role_OPERATOR}
+	mockFinance  = &mdAdmin.Operator{OperatorID: "Fauna", Role: mdAdmin.Role_FINANCE}
+	mockAuditor  = &mdAdmin.Operator{OperatorID: "Bae", Role: mdAdmin.Role_AUDITOR}
+
+	anyAction = mock.AnythingOfType("*admin.Action")
+)
+
+type testSuite struct {
+	suite.Suite
+	srv    Service
+	mBank  *mockBank.Bank
+	mAdmin *mockAdmin.Admin
+	mAudit *mockAudit.Audit
+}
+
+func (s *testSuite) SetupSuite() {
+	timeNowMs = func() int64 { return mockTimeMs }
+}
+
+func (s *testSuite) TearDownSuite() {
+}
+
+func (s *testSuite) SetupTest() {
+	s.mBank = &mockBank.Bank{}
+	s.mAdmin = &mockAdmin.Admin{}
+	s.mAudit = &mockAudit.Audit{}
+	s.srv = NewAdmin(sqltest.Transactor{}, s.mBank, s.mAdmin, s.mAudit)
+}
+
+func (s *testSuite) TearDownTest() {
+	s.mBank.AssertExpectations(s.T())
+	s.mAdmin.AssertExpectations(s.T())
+	s.mAudit.AssertExpectations(s.T())
+}
+
+func (s *testSuite) TestGetAccount() {
+	tests := []struct {
+		Desc       string
+		Operator   *mdAdmin.Operator
+		expAccount *mdBank.Account
+		ExpError   error
+		setup      func()
+	}{
+		{
+			Desc:       "normal Path",
+			Operator:   mockSupport,
+			expAccount: mockAccount,
+			ExpError:   nil,
+			setup: func() {
+				s.mAdmin.On("RecordAction", mockCtx, &mdAdmin.Action{
+					OperatorID:  mockSupport.OperatorID,
+					Role:        mockSupport.Role,
+					Action:      mdAdmin.ActionType_VIEW_ACCOUNT,
+					AccountID:   mockAccountID1,
+					TimestampMs: mockTimeMs,
+				}).Return(nil).Once()
+				s.mBank.On("GetAccount", mockCtx, mockAccountID1).Return(mockAccount, nil).Once()
+			},
+		},
+		{
+			Desc:       "bad Path, no operator",
+			Operator:   nil,
+			expAccount: nil,
+			ExpError:   ErrPermissionDenied,
+		},
+		{
+			Desc:       "bad Path, account not exist",
+			Operator:   mockSupport,
+			expAccount: nil,
+			ExpError:   bank.ErrAccountNotExist,
+			setup: func() {
+				s.mAdmin.On("RecordAction", mockCtx, anyAction).Return(nil).Once()
+				s.mBank.On("GetAccount", mockCtx, mockAccountID1).Return((*mdBank.Account)(nil), bank.ErrAccountNotExist).Once()
+			},
+		},
+		{
+			Desc:       "bad Path, the view can not be recorded",
+			Operator:   mockSupport,
+			expAccount: nil,
+			ExpError:   bank.ErrUpdateBalance,
+			setup: func() {
+				s.mAdmin.On("RecordAction", mockCtx, anyAction).Return(bank.ErrUpdateBalance).Once()
+			},
+		},
+	}
+
+	for _, test := range tests {
+		s.SetupTest()
+		if test.setup != nil {
+			test.setup()
+		}
+
+		account, err := s.srv.GetAccount(mockCtx, test.Operator, mockAccountID1)
+		s.Require().Equal(test.expAccount, account, test.Desc)
+		s.Require().Equal(test.ExpError, err, test.Desc)
+
+		s.TearDownTest()
+	}
+}
+
+func (s *testSuite) TestListTransactions() {
+	mockTxs := []*mdBank.Transaction{
+		{AccountID: mockAccountID1, Action: mdBank.Action_INCREASE, Amount: usd(100), TradeID: mockTradeID},
+	}
+
+	tests := []struct {
+		Desc     string
+		Operator *mdAdmin.Operator
+		ExpTxs   []*mdBank.Transaction
+		ExpError error
+		setup    func()
+	}{
+		{
+			Desc:     "normal Path",
+			Operator: mockOperator,
+			ExpTxs:   mockTxs,
+			setup: func() {
+				s.mAdmin.On("RecordAction", mockCtx, &mdAdmin.Action{
+					OperatorID:  mockOperator.OperatorID,
+					Role:        mockOperator.Role,
+					Action:      mdAdmin.ActionType_VIEW_TRANSACTIONS,
+					AccountID:   mockAccountID1,
+					TimestampMs: mockTimeMs,
+				}).Return(nil).Once()
+				s.mBank.On("ListTransactions", mockCtx, mockAccountID1, 0, 10).Return(mockTxs, nil).Once()
+			},
+		},
+		{
+			Desc:     "bad Path, auditor can not view accounts",
+			Operator: mockAuditor,
+			ExpError: ErrPermissionDenied,
+		},
+		{
+			Desc:     "bad Path, the view can not be recorded",
+			Operator: mockSupport,
+			ExpError: bank.ErrUpdateBalance,
+			setup: func() {
+				s.mAdmin.On("RecordAction", mockCtx, anyAction).Return(bank.ErrUpdateBalance).Once()
+			},
+		},
+	}
+
+	for _, test := range tests {
+		s.SetupTest()
+		if test.setup != nil {
+			test.setup()
+		}
+
+		txs, err := s.srv.ListTransactions(mockCtx, test.Operator, mockAccountID1, 0, 10)
+		s.Require().Equal(test.ExpTxs, txs, test.Desc)
+		s.Require().Equal(test.ExpError, err, test.Desc)
+
+		s.TearDownTest()
+	}
+}
+
+func (s *testSuite) TestAdjustBalance() {
+	credit := usd(100)
+	tests := []struct {
+		Desc       string
+		Operator   *mdAdmin.Operator
+		Amount     money.Money
+		Reason     mdAdmin.ReasonCode
+		ExpTradeID string
+		ExpError   error
+		setup      func()
+	}{
+		{
+			Desc:       "normal Path, credit",
+			Operator:   mockFinance,
+			Amount:     usd(100),
+			Reason:     mdAdmin.ReasonCode_GOODWILL,
+			ExpTradeID: mockTradeID,
+			setup: func() {
+				s.mBank.On("GetAccount", mockCtx, mockAccountID1).Return(mockAccount, nil).Once()
+				s.mBank.On("Trade", mockCtx, &mdBank.Dealing{FromAccountID: "sys-write-off-USD", ToAccountID: mockAccountID1, Amount: usd(100)}).Return(mockTradeID, nil).Once()
+				s.mAdmin.On("RecordAction", mockCtx, &mdAdmin.Action{
+					OperatorID:  mockFinance.OperatorID,
+					Role:        mockFinance.Role,
+					Action:      mdAdmin.ActionType_ADJUST_BALANCE,
+					AccountID:   mockAccountID1,
+					TradeID:     mockTradeID,
+					Amount:      &credit,
+					ReasonCode:  mdAdmin.ReasonCode_GOODWILL,
+					TimestampMs: mockTimeMs,
+				}).Return(nil).Once()
+			},
+		},
+		{
+			Desc:       "normal Path, debit",
+			Operator:   mockFinance,
+			Amount:     usd(-100),
+			Reason:     mdAdmin.ReasonCode_CORRECTION,
+			ExpTradeID: mockTradeID,
+			setup: func() {
+				s.mBank.On("GetAccount", mockCtx, mockAccountID1).Return(mockAccount, nil).Once()
+				s.mBank.On("Trade", mockCtx, &mdBank.Dealing{FromAccountID: mockAccountID1, ToAccountID: "sys-suspense-USD", Amount: usd(100)}).Return(mockTradeID, nil).Once()
+				s.mAdmin.On("RecordAction", mockCtx, anyAction).Return(nil).Once()
+			},
+		},
+		{
+			Desc:     "bad Path, operator can not adjust",
+			Operator: mockOperator,
+			Amount:   usd(100),
+			Reason:   mdAdmin.ReasonCode_GOODWILL,
+			ExpError: ErrPermissionDenied,
+		},
+		{
+			Desc:     "bad Path, invalid reason",
+			Operator: mockFinance,
+			Amount:   usd(100),
+			Reason:   "BORED",
+			ExpError: ErrInvalidReasonCode,
+		},
+		{
+			Desc:     "bad Path, zero amount",
+			Operator: mockFinance,
+			Amount:   usd(0),
+			Reason:   mdAdmin.ReasonCode_GOODWILL,
+			ExpError: ErrInvalidAmount,
+		},
+		{
+			Desc:     "bad Path, account not exist",
+			Operator: mockFinance,
+			Amount:   usd(100),
+			Reason:   mdAdmin.ReasonCode_CORRECTION,
+			ExpError: bank.ErrAccountNotExist,
+			setup: func() {
+				s.mBank.On("GetAccount", mockCtx, mockAccountID1).Return(nil, bank.ErrAccountNotExist).Once()
+			},
+		},
+		{
+			Desc:     "bad Path, amount in another currency",
+			Operator: mockFinance,
+			Amount:   money.New(100, "EUR"),
+			Reason:   mdAdmin.ReasonCode_GOODWILL,
+			ExpError: bank.ErrCurrencyMismatch,
+			setup: func() {
+				s.mBank.On("GetAccount", mockCtx, mockAccountID1).Return(mockAccount, nil).Once()
+			},
+		},
+		{
+			Desc:     "bad Path, balance not enough",
+			Operator: mockFinance,
+			Amount:   usd(-100),
+			Reason:   mdAdmin.ReasonCode_CORRECTION,
+			ExpError: bank.ErrBalanceNotEnough,
+			setup: func() {
+				s.mBank.On("GetAccount", mockCtx, mockAccountID1).Return(mockAccount, nil).Once()
+				s.mBank.On("Trade", mockCtx, mock.AnythingOfType("*bank.Dealing")).Return("", bank.ErrBalanceNotEnough).Once()
+			},
+		},
+	}
+
+	for _, test := range tests {
+		s.SetupTest()
+		if test.setup != nil {
+			test.setup()
+		}
+
+		tradeID, err := s.srv.AdjustBalance(mockCtx, test.Operator, mockAccountID1, test.Amount, test.Reason, "")
+		s.Require().Equal(test.ExpTradeID, tradeID, test.Desc)
+		s.Require().Equal(test.ExpError, err, test.Desc)
+
+		s.TearDownTest()
+	}
+}
+
+func (s *testSuite) TestFreezeAccount() {
+	tests := []struct {
+		Desc     string
+		Operator *mdAdmin.Operator
+		ExpError error
+		setup    func()
+	}{
+		{
+			Desc:     "normal Path",
+			Operator: mockOperator,
+			setup: func() {
+				s.mBank.On("SetAccountStatus", mockCtx, mockAccountID1, mdBank.AccountStatus_FROZEN).Return(nil).Once()
+				s.mAdmin.On("RecordAction", mockCtx, anyAction).Return(nil).Once()
+			},
+		},
+		{
+			Desc:     "bad Path, support is read-only",
+			Operator: mockSupport,
+			ExpError: ErrPermissionDenied,
+		},
+		{
+			Desc:     "bad Path, account not exist",
+			Operator: mockOperator,
+			ExpError: bank.ErrAccountNotExist,
+			setup: func() {
+				s.mBank.On("SetAccountStatus", mockCtx, mockAccountID1, mdBank.AccountStatus_FROZEN).Return(bank.ErrAccountNotExist).Once()
+			},
+		},
+	}
+
+	for _, test := range tests {
+		s.SetupTest()
+		if test.setup != nil {
+			test.setup()
+		}
+
+		err := s.srv.FreezeAccount(mockCtx, test.Operator, mockAccountID1, mdAdmin.ReasonCode_FRAUD, "")
+		s.Require().Equal(test.ExpError, err, test.Desc)
+
+		s.TearDownTest()
+	}
+}
+
+func (s *testSuite) TestSetCreditLimit() {
+	tests := []struct {
+		Desc       string
+		Operator   *mdAdmin.Operator
+		Limit      money.Money
+		ExpAccount *mdBank.Account
+		ExpError   error
+		setup      func()
+	}{
+		{
+			Desc:       "normal Path",
+			Operator:   mockFinance,
+			Limit:      usd(5000),
+			ExpAccount: &mdBank.Account{AccountID: mockAccountID1, Balance: usd(3345678), CreditLimit: usd(5000)},
+			setup: func() {
+				s.mBank.On("GetAccount", mockCtx, mockAccountID1).Return(mockAccount, nil).Once()
+				s.mBank.On("SetCreditLimit", mockCtx, mockAccountID1, usd(5000)).Return(nil).Once()
+				s.mAdmin.On("RecordAction", mockCtx, mock.MatchedBy(func(a *mdAdmin.Action) bool {
+					return a.Action == mdAdmin.ActionType_CREDIT_LIMIT && *a.Amount == usd(5000)
+				})).Return(nil).Once()
+			},
+		},
+		{
+			Desc:     "bad Path, operator can not grant credit",
+			Operator: mockOperator,
+			Limit:    usd(5000),
+			ExpError: ErrPermissionDenied,
+		},
+		{
+			Desc:     "bad Path, negative limit",
+			Operator: mockFinance,
+			Limit:    usd(-1),
+			ExpError: ErrInvalidCreditLimit,
+		},
+		{
+			Desc:     "bad Path, system account",
+			Operator: mockFinance,
+			Limit:    usd(5000),
+			ExpError: ErrCreditNotAllowed,
+			setup: func() {
+				s.mBank.On("GetAccount", mockCtx, mockAccountID1).Return(&mdBank.Account{AccountID: mockAccountID1, Type: mdBank.AccountType_ESCROW}, nil).Once()
+			},
+		},
+		{
+			Desc:     "bad Path, revoke while overdrawn",
+			Operator: mockFinance,
+			Limit:    usd(0),
+			ExpError: bank.ErrCreditLimitInUse,
+			setup: func() {
+				s.mBank.On("GetAccount", mockCtx, mockAccountID1).Return(&mdBank.Account{AccountID: mockAccountID1, Balance: usd(-100), CreditLimit: usd(500)}, nil).Once()
+				s.mBank.On("SetCreditLimit", mockCtx, mockAccountID1, usd(0)).Return(bank.ErrCreditLimitInUse).Once()
+			},
+		},
+	}
+
+	for _, test := range tests {
+		s.SetupTest()
+		if test.setup != nil {
+			test.setup()
+		}
+
+		account, err := s.srv.SetCreditLimit(mockCtx, test.Operator, mockAccountID1, test.Limit, mdAdmin.ReasonCode_CORRECTION, "")
+		s.Require().Equal(test.ExpError, err, test.Desc)
+		s.Require().Equal(test.ExpAccount, account, test.Desc)
+
+		s.TearDownTest()
+	}
+
+	// the account returned by the bank is left as it is
+	s.Require().Equal(usd(0), mockAccount.CreditLimit)
+}
+
+func (s *testSuite) TestRevokeCreditLimit() {
+	tests := []struct {
+		Desc       string
+		Operator   *mdAdmin.Operator
+		ExpAccount *mdBank.Account
+		ExpError   error
+		setup      func()
+	}{
+		{
+			Desc:       "normal Path, revoked in the currency of the wallet",
+			Operator:   mockFinance,
+			ExpAccount: &mdBank.Account{AccountID: mockAccountID1, Balance: money.New(800, "EUR"), CreditLimit: money.New(0, "EUR")},
+			setup: func() {
+				s.mBank.On("GetAccount", mockCtx, mockAccountID1).Return(&mdBank.Account{AccountID: mockAccountID1, Balance: money.New(800, "EUR"), CreditLimit: money.New(500, "EUR")}, nil).Once()
+				s.mBank.On("SetCreditLimit", mockCtx, mockAccountID1, money.New(0, "EUR")).Return(nil).Once()
+				s.mAdmin.On("RecordAction", mockCtx, mock.MatchedBy(func(a *mdAdmin.Action) bool {
+					return a.Action == mdAdmin.ActionType_CREDIT_LIMIT && *a.Amount == money.New(0, "EUR")
+				})).Return(nil).Once()
+			},
+		},
+		{
+			Desc:     "bad Path, operator can not grant credit",
+			Operator: mockOperator,
+			ExpError: ErrPermissionDenied,
+		},
+		{
+			Desc:     "bad Path, revoke while overdrawn",
+			Operator: mockFinance,
+			ExpError: bank.ErrCreditLimitInUse,
+			setup: func() {
+				s.mBank.On("GetAccount", mockCtx, mockAccountID1).Return(&mdBank.Account{AccountID: mockAccountID1, Balance: usd(-100), CreditLimit: usd(500)}, nil).Once()
+				s.mBank.On("SetCreditLimit", mockCtx, mockAccountID1, usd(0)).Return(bank.ErrCreditLimitInUse).Once()
+			},
+		},
+	}
+
+	for _, test := range tests {
+		s.SetupTest()
+		if test.setup != nil {
+			test.setup()
+		}
+
+		account, err := s.srv.RevokeCreditLimit(mockCtx, test.Operator, mockAccountID1, mdAdmin.ReasonCode_CORRECTION, "")
+		s.Require().Equal(test.ExpError, err, test.Desc)
+		s.Require().Equal(test.ExpAccount, account, test.Desc)
+
+		s.TearDownTest()
+	}
+}
+
+func (s *testSuite) TestRefund() {
+	mockEntries := []*mdBank.Transaction{
+		{AccountID: mockAccountID1, Action: mdBank.Action_DECREASE, Amount: usd(100), TradeID: mockTradeID},
+		{AccountID: mockAccountID2, Action: mdBank.Action_INCREASE, Amount: usd(100), TradeID: mockTradeID},
+	}
+	mockWallets := func() {
+		s.mBank.On("GetAccount", mockCtx, mockAccountID1).Return(&mdBank.Account{AccountID: mockAccountID1}, nil).Once()
+		s.mBank.On("GetAccount", mockCtx, mockAccountID2).Return(&mdBank.Account{AccountID: mockAccountID2}, nil).Once()
+	}
+
+	tests := []struct {
+		Desc        string
+		Operator    *mdAdmin.Operator
+		ExpRefundID string
+		ExpError    error
+		setup       func()
+	}{
+		{
+			Desc:        "normal Path",
+			Operator:    mockOperator,
+			ExpRefundID: mockRefundID,
+			setup: func() {
+				s.mBank.On("GetTrade", mockCtx, mockTradeID).Return(mockEntries, nil).Once()
+				mockWallets()
+				s.mBank.On("Trade", mockCtx, &mdBank.Dealing{FromAccountID: mockAccountID2, ToAccountID: mockAccountID1, Amount: usd(100), ReversalOf: mockTradeID}).Return(mockRefundID, nil).Once()
+				s.mAdmin.On("RecordAction", mockCtx, anyAction).Return(nil).Once()
+			},
+		},
+		{
+			Desc:     "bad Path, support is read-only",
+			Operator: mockSupport,
+			ExpError: ErrPermissionDenied,
+		},
+		{
+			Desc:     "bad Path, trade not exist",
+			Operator: mockFinance,
+			ExpError: bank.ErrTradeNotExist,
+			setup: func() {
+				s.mBank.On("GetTrade", mockCtx, mockTradeID).Return(nil, bank.ErrTradeNotExist).Once()
+			},
+		},
+		{
+			Desc:     "bad Path, exchange has more legs",
+			Operator: mockFinance,
+			ExpError: ErrTradeNotRefundable,
+			setup: func() {
+				legs := append(mockEntries, mockEntries...)
+				s.mBank.On("GetTrade", mockCtx, mockTradeID).Return(legs, nil).Once()
+			},
+		},
+		{
+			Desc:     "bad Path, already refunded",
+			Operator: mockFinance,
+			ExpError: bank.ErrTradeAlreadyReversed,
+			setup: func() {
+				s.mBank.On("GetTrade", mockCtx, mockTradeID).Return(mockEntries, nil).Once()
+				mockWallets()
+				s.mBank.On("Trade", mockCtx, mock.AnythingOfType("*bank.Dealing")).Return("", bank.ErrTradeAlreadyReversed).Once()
+			},
+		},
+		{
+			Desc:     "bad Path, escrow funding moves by the escrow",
+			Operator: mockFinance,
+			ExpError: ErrTradeNotRefundable,
+			setup: func() {
+				s.mBank.On("GetTrade", mockCtx, mockTradeID).Return(mockEntries, nil).Once()
+				s.mBank.On("GetAccount", mockCtx, mockAccountID1).Return(&mdBank.Account{AccountID: mockAccountID1}, nil).Once()
+				s.mBank.On("GetAccount", mockCtx, mockAccountID2).Return(&mdBank.Account{AccountID: mockAccountID2, Type: mdBank.AccountType_ESCROW}, nil).Once()
+			},
+		},
+	}
+
+	for _, test := range tests {
+		s.SetupTest()
+		if test.setup != nil {
+			test.setup()
+		}
+
+		refundID, err := s.srv.Refund(mockCtx, test.Operator, mockTradeID, mdAdmin.ReasonCode_CHARGEBACK, "")
+		s.Require().Equal(test.ExpRefundID, refundID, test.Desc)
+		s.Require().Equal(test.ExpError, err, test.Desc)
+
+		s.TearDownTest()
+	}
+}
+
+func (s *testSuite) TestAdjustBalanceRecordFailed() {
+	s.SetupTest()
+	s.mBank.On("GetAccount", mockCtx, mockAccountID1).Return(mockAccount, nil).Once()
+	s.mBank.On("Trade", mockCtx, mock.AnythingOfType("*bank.Dealing")).Return(mockTradeID, nil).Once()
+	s.mAdmin.On("RecordAction", mockCtx, anyAction).Return(bank.ErrUpdateBalance).Once()
+
+	// the record error must fail the whole transaction, so the trade is not returned
+	tradeID, err := s.srv.AdjustBalance(mockCtx, mockFinance, mockAccountID1, usd(100), mdAdmin.ReasonCode_GOODWILL, "")
+	s.Require().Equal("", tradeID)
+	s.Require().Equal(bank.ErrUpdateBalance, err)
+
+	s.TearDownTest()
+}
+
+func (s *testSuite) TestListAuditEntries() {
+	mockFilter := &mdAudit.Filter{ActorID: mockFinance.OperatorID}
+	mockEntries := []*mdAudit.Entry{
+		{ActorType: mdAudit.ActorType_STAFF, ActorID: mockFinance.OperatorID, Action: mdAudit.Action_TRADE},
+	}
+
+	tests := []struct {
+		Desc       string
+		Operator   *mdAdmin.Operator
+		ExpEntries []*mdAudit.Entry
+		ExpError   error
+		setup      func()
+	}{
+		{
+			Desc:       "normal Path",
+			Operator:   mockAuditor,
+			ExpEntries: mockEntries,
+			setup: func() {
+				s.mAudit.On("List", mockCtx, mockFilter, 0, 10).Return(mockEntries, nil).Once()
+			},
+		},
+		{
+			Desc:     "bad Path, finance is not auditor",
+			Operator: mockFinance,
+			ExpError: ErrPermissionDenied,
+		},
+	}
+
+	for _, test := range tests {
+		s.SetupTest()
+		if test.setup != nil {
+			test.setup()
+		}
+
+		entries, err := s.srv.ListAuditEntries(mockCtx, test.Operator, mockFilter, 0, 10)
+		s.Require().Equal(test.ExpEntries, entries, test.Desc)
+		s.Require().Equal(test.ExpError, err, test.Desc)
+
+		s.TearDownTest()
+	}
+}
+
+func (s *testSuite) TestListOverdrawn() {
+	mockAccounts := []*mdBank.Account{
+		{AccountID: mockAccountID1, Balance: usd(-300), CreditLimit: usd(500)},
+	}
+
+	s.mBank.On("ListOverdrawn", mockCtx, 0, 10).Return(mockAccounts, nil).Once()
+	accounts, err := s.srv.ListOverdrawn(mockCtx, mockOperator, 0, 10)
+	s.Require().NoError(err)
+	s.Require().Equal(mockAccounts, accounts)
+}
+
+func (s *testSuite) TestListSystemAccounts() {
+	mockAccounts := []*mdBank.Account{
+		{AccountID: "sys-funding-direct-USD", Balance: usd(-5000), Type: mdBank.AccountType_SYSTEM, AllowNegative: true},
+	}
+
+	s.mBank.On("ListSystemAccounts", mockCtx).Return(mockAccounts, nil).Once()
+	accounts, err := s.srv.ListSystemAccounts(mockCtx, mockSupport)
+	s.Require().NoError(err)
+	s.Require().Equal(mockAccounts, accounts)
+
+	_, err = s.srv.ListSystemAccounts(mockCtx, mockAuditor)
+	s.Require().Equal(ErrPermissionDenied, err)
+}
+
+func TestSuite(t *testing.T) {
+	suite.Run(t, new(testSuite))
+}
@@ -0,0 +1,90 @@
+package approval
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	mApproval "github.com/n3k0fi5t/wallet/app/models/approval"
+	"github.com/n3k0fi5t/wallet/app/money"
+	"github.com/n3k0fi5t/wallet/app/service/wallet"
+)
+
+var (
+	// ErrInvalidPolicy means the threshold is negative, approvers repeat, or required is not between 1 and the number of approvers
+	ErrInvalidPolicy = fmt.Errorf("Invalid policy")
+
+	// ErrInvalidApprover means an approver is not a member of the wallet
+	ErrInvalidApprover = fmt.Errorf("Invalid approver")
+
+	// ErrInvalidAmount means the amount is not positive
+	ErrInvalidAmount = fmt.Errorf("Invalid amount")
+
+	// ErrApprovalNotRequired means the wallet has no policy or the amount is within the threshold, pay directly instead
+	ErrApprovalNotRequired = wallet.ErrApprovalNotRequired
+
+	// ErrNotEnoughApprovers means the approvers other than the requester are fewer than required
+	ErrNotEnoughApprovers = wallet.ErrNotEnoughApprovers
+
+	// ErrIntentNotPending means the intent is executed, rejected or expired already
+	ErrIntentNotPending = fmt.Errorf("Intent not pending")
+
+	// ErrSelfApproval means the requester approves its own intent
+	ErrSelfApproval = fmt.Errorf("Self approval")
+
+	// ErrAlreadyDecided means the approver approved or rejected the intent already
+	ErrAlreadyDecided = fmt.Errorf("Already decided")
+
+	// ErrCommentTooLong means the comment exceeds MaxCommentLength
+	ErrCommentTooLong = fmt.Errorf("Comment too long")
+)
+
+const (
+	// MaxCommentLength is the max length of comments in bytes
+	MaxCommentLength = 255
+)
+
+// Config controls how often intents past wallet.IntentTTL expire
+type Config struct {
+	PollInterval time.Duration
+	BatchSize    int
+}
+
+// DefaultConfig expires due intents every minute
+var DefaultConfig = Config{
+	PollInterval: time.Minute,
+	BatchSize:    50,
+}
+
+type Service interface {
+	// SetPolicy requires required of the approvers to approve paying more than threshold out of the wallet, only managers set it.
+	// The threshold is in the currency of the wallet
+	SetPolicy(ctx context.Context, userID, walletID string, threshold money.Money, required int, approvers []string) (*mApproval.Policy, error)
+
+	// GetPolicy get the approval policy of a wallet the user is a member of
+	GetPolicy(ctx context.Context, userID, walletID string) (*mApproval.Policy, error)
+
+	// DeletePolicy removes the approval policy of the wallet, only managers delete it. Pending intents keep waiting
+	DeletePolicy(ctx context.Context, userID, walletID string) error
+
+	// RequestApproval holds the amount of the wallet until approved, empty toAccountID for a withdrawal
+	RequestApproval(ctx context.Context, userID, walletID, toAccountID string, amount money.Money) (*mApproval.Intent, error)
+
+	// GetIntent get an intent of a wallet the user is a member of, with the decisions on it
+	GetIntent(ctx context.Context, userID, intentID string) (*mApproval.Intent, []*mApproval.Approval, error)
+
+	// ListIntents list intents of a wallet the user is a member of, newest first
+	ListIntents(ctx context.Context, userID, walletID string, status mApproval.Status, offset, limit int) ([]*mApproval.Intent, error)
+
+	// Approve records the approval of an approver, the intent executes once approved by the required number of approvers
+	Approve(ctx context.Context, userID, intentID, comment string) (*mApproval.Intent, error)
+
+	// Reject returns the money to the wallet, approvers and the requester can reject
+	Reject(ctx context.Context, userID, intentID, comment string) (*mApproval.Intent, error)
+
+	// ExpireDue expires at most BatchSize pending intents past their expiry, returns the number of intents expired
+	ExpireDue(ctx context.Context) (int, error)
+
+	// Run expires due intents every PollInterval until ctx is done
+	Run(ctx context.Context)
+}
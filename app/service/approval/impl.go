@@ -0,0 +1,390 @@
+package approval
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	mApproval "github.com/n3k0fi5t/wallet/app/models/approval"
+	mBank "github.com/n3k0fi5t/wallet/app/models/bank"
+	mWallet "github.com/n3k0fi5t/wallet/app/models/wallet"
+	"github.com/n3k0fi5t/wallet/app/money"
+	"github.com/n3k0fi5t/wallet/app/repository/approval"
+	"github.com/n3k0fi5t/wallet/app/repository/bank"
+	"github.com/n3k0fi5t/wallet/app/service/wallet"
+	"github.com/n3k0fi5t/wallet/app/util"
+	"github.com/n3k0fi5t/wallet/common/logger"
+	"github.com/n3k0fi5t/wallet/common/sql"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	timeNowMs = util.TimeNowMs
+)
+
+func NewApproval(t sql.Transactor, b bank.Bank, a approval.Approval, w wallet.Service, cfg Config) Service {
+	return &impl{
+		transactor: t,
+		bank:       b,
+		approval:   a,
+		walletSrv:  w,
+		cfg:        cfg,
+	}
+}
+
+type impl struct {
+	transactor sql.Transactor
+	bank       bank.Bank
+	approval   approval.Approval
+	walletSrv  wallet.Service
+	cfg        Config
+}
+
+// memberRoles maps members of the wallet to their roles, only members see the members
+func (im *impl) memberRoles(ctx context.Context, userID, walletID string) (map[string]mWallet.Role, error) {
+	members, err := im.walletSrv.ListMembers(ctx, userID, walletID)
+	if err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("walletSrv.ListMembers failed in memberRoles")
+		return nil, err
+	}
+
+	roles := make(map[string]mWallet.Role, len(members))
+	for _, m := range members {
+		roles[m.UserID] = m.Role
+	}
+	return roles, nil
+}
+
+func validPolicy(threshold money.Money, required int, approvers []string) bool {
+	if threshold.IsNegative() || required < 1 || required > len(approvers) {
+		return false
+	}
+
+	seen := map[string]bool{}
+	for _, id := range approvers {
+		if id == "" || strings.Contains(id, ",") || seen[id] {
+			return false
+		}
+		seen[id] = true
+	}
+	return true
+}
+
+func (im *impl) SetPolicy(ctx context.Context, userID, walletID string, threshold money.Money, required int, approvers []string) (*mApproval.Policy, error) {
+	if !validPolicy(threshold, required, approvers) {
+		return nil, ErrInvalidPolicy
+	}
+
+	roles, err := im.memberRoles(ctx, userID, walletID)
+	if err != nil {
+		return nil, err
+	} else if !roles[userID].CanManage() {
+		return nil, wallet.ErrPermissionDenied
+	}
+	for _, id := range approvers {
+		if _, ok := roles[id]; !ok {
+			return nil, ErrInvalidApprover
+		}
+	}
+
+	// the threshold is compared with amounts paid out of the wallet, in its currency
+	account, err := im.bank.GetAccount(ctx, walletID)
+	if err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("bank.GetAccount failed in SetPolicy")
+		return nil, err
+	} else if account.Currency() != threshold.Currency {
+		return nil, bank.ErrCurrencyMismatch
+	}
+
+	nowMs := timeNowMs()
+	p := &mApproval.Policy{
+		WalletID:  walletID,
+		Threshold: threshold,
+		Required:  required,
+		Approvers: strings.Join(approvers, ","),
+		UpdatedBy: userID,
+		CreatedMs: nowMs,
+		UpdatedMs: nowMs,
+	}
+	if err := im.approval.SavePolicy(ctx, p); err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("approval.SavePolicy failed in SetPolicy")
+		return nil, err
+	}
+	return p, nil
+}
+
+func (im *impl) GetPolicy(ctx context.Context, userID, walletID string) (*mApproval.Policy, error) {
+	if _, err := im.walletSrv.Authorize(ctx, userID, walletID); err != nil {
+		return nil, err
+	}
+
+	p, err := im.approval.GetPolicy(ctx, walletID)
+	if err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("approval.GetPolicy failed in GetPolicy")
+		return nil, err
+	}
+	return p, nil
+}
+
+func (im *impl) DeletePolicy(ctx context.Context, userID, walletID string) error {
+	m, err := im.walletSrv.Authorize(ctx, userID, walletID)
+	if err != nil {
+		return err
+	} else if !m.Role.CanManage() {
+		return wallet.ErrPermissionDenied
+	}
+
+	if err := im.approval.DeletePolicy(ctx, walletID); err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("approval.DeletePolicy failed in DeletePolicy")
+		return err
+	}
+	return nil
+}
+
+func (im *impl) RequestApproval(ctx context.Context, userID, walletID, toAccountID string, amount money.Money) (*mApproval.Intent, error) {
+	if !amount.IsPositive() {
+		return nil, ErrInvalidAmount
+	} else if toAccountID == walletID {
+		return nil, bank.ErrSelfTransfer
+	}
+
+	i, err := im.walletSrv.Hold(ctx, userID, walletID, toAccountID, amount)
+	if err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("walletSrv.Hold failed in RequestApproval")
+		return nil, err
+	}
+	return i, nil
+}
+
+// authorize checks the user is a member of the wallet of the intent, intents of other wallets look not exist
+func (im *impl) authorize(ctx context.Context, userID string, i *mApproval.Intent) error {
+	_, err := im.walletSrv.Authorize(ctx, userID, i.WalletID)
+	if err == bank.ErrAccountNotExist {
+		return approval.ErrIntentNotExist
+	}
+	return err
+}
+
+func (im *impl) GetIntent(ctx context.Context, userID, intentID string) (*mApproval.Intent, []*mApproval.Approval, error) {
+	i, err := im.approval.GetIntent(ctx, intentID)
+	if err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("approval.GetIntent failed in GetIntent")
+		return nil, nil, err
+	}
+	if err := im.authorize(ctx, userID, i); err != nil {
+		return nil, nil, err
+	}
+
+	approvals, err := im.approval.ListApprovals(ctx, intentID)
+	if err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("approval.ListApprovals failed in GetIntent")
+		return nil, nil, err
+	}
+	return i, approvals, nil
+}
+
+func (im *impl) ListIntents(ctx context.Context, userID, walletID string, status mApproval.Status, offset, limit int) ([]*mApproval.Intent, error) {
+	if _, err := im.walletSrv.Authorize(ctx, userID, walletID); err != nil {
+		return nil, err
+	}
+
+	intents, err := im.approval.ListIntents(ctx, walletID, status, offset, limit)
+	if err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("approval.ListIntents failed in ListIntents")
+		return nil, err
+	}
+	return intents, nil
+}
+
+// change locks the intent and applies f to it, decisions on the same intent take turns
+func (im *impl) change(ctx context.Context, intentID string, f func(context.Context, *mApproval.Intent) error) (*mApproval.Intent, error) {
+	var i *mApproval.Intent
+	if err := im.transactor.Transact(ctx, func(ctx context.Context) error {
+		locked, err := im.approval.LockIntent(ctx, intentID)
+		if err != nil {
+			logger.FromContext(ctx).WithField("err", err).Error("approval.LockIntent failed")
+			return err
+		}
+
+		if err := f(ctx, locked); err != nil {
+			return err
+		}
+		i = locked
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return i, nil
+}
+
+// settle pays the hold account out to the account and closes the intent with status
+func (im *impl) settle(ctx context.Context, i *mApproval.Intent, toAccountID string, status mApproval.Status) error {
+	// users can only pay to users, the payee may be closed or turned into another type since requested
+	if !i.IsWithdrawal() && toAccountID == i.ToAccountID {
+		if _, err := im.walletSrv.GetAccount(ctx, toAccountID); err != nil {
+			logger.FromContext(ctx).WithField("err", err).Error("walletSrv.GetAccount failed in settle")
+			return err
+		}
+	}
+
+	tradeID, err := im.bank.Trade(ctx, &mBank.Dealing{
+		FromAccountID: i.HoldAccountID,
+		ToAccountID:   toAccountID,
+		Amount:        i.Amount,
+	})
+	if err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("bank.Trade failed in settle")
+		return err
+	}
+
+	i.Status = status
+	i.SettleTradeID = tradeID
+	i.UpdatedMs = timeNowMs()
+	if err := im.approval.UpdateIntent(ctx, i); err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("approval.UpdateIntent failed in settle")
+		return err
+	}
+	return nil
+}
+
+// decide records the decision of the user on the pending intent, returns the number of approvals so far
+func (im *impl) decide(ctx context.Context, userID string, i *mApproval.Intent, decision mApproval.Decision, comment string) (int, error) {
+	if i.Status != mApproval.Status_PENDING || i.ExpiresAtMs <= timeNowMs() {
+		return 0, ErrIntentNotPending
+	}
+
+	approvals, err := im.approval.ListApprovals(ctx, i.IntentID)
+	if err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("approval.ListApprovals failed in decide")
+		return 0, err
+	}
+	approved := 0
+	for _, a := range approvals {
+		if a.ApproverID == userID {
+			return 0, ErrAlreadyDecided
+		} else if a.Decision == mApproval.Decision_APPROVE {
+			approved++
+		}
+	}
+
+	a := &mApproval.Approval{
+		IntentID:   i.IntentID,
+		ApproverID: userID,
+		Decision:   decision,
+		Comment:    comment,
+		CreatedMs:  timeNowMs(),
+	}
+	if err := im.approval.CreateApproval(ctx, a); err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("approval.CreateApproval failed in decide")
+		return 0, err
+	}
+	if decision == mApproval.Decision_APPROVE {
+		approved++
+	}
+	return approved, nil
+}
+
+func (im *impl) Approve(ctx context.Context, userID, intentID, comment string) (*mApproval.Intent, error) {
+	if len(comment) > MaxCommentLength {
+		return nil, ErrCommentTooLong
+	}
+
+	return im.change(ctx, intentID, func(ctx context.Context, i *mApproval.Intent) error {
+		// requesters can not approve their own intents even if they are approvers
+		if err := im.authorize(ctx, userID, i); err != nil {
+			return err
+		} else if i.RequestedBy == userID {
+			return ErrSelfApproval
+		} else if !i.IsApprover(userID) {
+			return wallet.ErrPermissionDenied
+		}
+
+		approved, err := im.decide(ctx, userID, i, mApproval.Decision_APPROVE, comment)
+		if err != nil {
+			return err
+		} else if approved < i.Required {
+			return nil
+		}
+
+		toAccountID := i.ToAccountID
+		if i.IsWithdrawal() {
+			// the hold account is in the currency of the wallet
+			hold, err := im.bank.GetAccount(ctx, i.HoldAccountID)
+			if err != nil {
+				logger.FromContext(ctx).WithField("err", err).Error("bank.GetAccount failed in Approve")
+				return err
+			}
+			toAccountID = mBank.FundingAccountID(mBank.Rail_DIRECT, hold.Currency())
+		}
+		return im.settle(ctx, i, toAccountID, mApproval.Status_EXECUTED)
+	})
+}
+
+func (im *impl) Reject(ctx context.Context, userID, intentID, comment string) (*mApproval.Intent, error) {
+	if len(comment) > MaxCommentLength {
+		return nil, ErrCommentTooLong
+	}
+
+	return im.change(ctx, intentID, func(ctx context.Context, i *mApproval.Intent) error {
+		if err := im.authorize(ctx, userID, i); err != nil {
+			return err
+		} else if i.RequestedBy != userID && !i.IsApprover(userID) {
+			return wallet.ErrPermissionDenied
+		}
+
+		if _, err := im.decide(ctx, userID, i, mApproval.Decision_REJECT, comment); err != nil {
+			return err
+		}
+		return im.settle(ctx, i, i.WalletID, mApproval.Status_REJECTED)
+	})
+}
+
+func (im *impl) ExpireDue(ctx context.Context) (int, error) {
+	nowMs := timeNowMs()
+	intents, err := im.approval.ListExpired(ctx, nowMs, im.cfg.BatchSize)
+	if err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("approval.ListExpired failed in ExpireDue")
+		return 0, err
+	}
+
+	expired := 0
+	for _, due := range intents {
+		// the intent may be executed or rejected since listed
+		if _, err := im.change(ctx, due.IntentID, func(ctx context.Context, i *mApproval.Intent) error {
+			if i.Status != mApproval.Status_PENDING || i.ExpiresAtMs > nowMs {
+				return nil
+			}
+			if err := im.settle(ctx, i, i.WalletID, mApproval.Status_EXPIRED); err != nil {
+				return err
+			}
+			expired++
+			return nil
+		}); err != nil {
+			logger.FromContext(ctx).WithFields(logrus.Fields{
+				"err":      err,
+				"intentID": due.IntentID,
+			}).Error("expire failed in ExpireDue")
+		}
+	}
+	return expired, nil
+}
+
+func (im *impl) Run(ctx context.Context) {
+	ticker := time.NewTicker(im.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		n, err := im.ExpireDue(ctx)
+		// keep draining without waiting while there is a backlog
+		if err == nil && n == im.cfg.BatchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,502 @@
+package approval
+
+import (
+	"context"
+	"testing"
+
+	mdApproval "github.com/n3k0fi5t/wallet/app/models/approval"
+	mdBank "github.com/n3k0fi5t/wallet/app/models/bank"
+	mdWallet "github.com/n3k0fi5t/wallet/app/models/wallet"
+	"github.com/n3k0fi5t/wallet/app/money"
+	"github.com/n3k0fi5t/wallet/app/repository/approval"
+	mockApproval "github.com/n3k0fi5t/wallet/app/repository/approval/mocks"
+	"github.com/n3k0fi5t/wallet/app/repository/bank"
+	mockBank "github.com/n3k0fi5t/wallet/app/repository/bank/mocks"
+	"github.com/n3k0fi5t/wallet/app/service/wallet"
+	mockWallet "github.com/n3k0fi5t/wallet/app/service/wallet/mocks"
+	"github.com/n3k0fi5t/wallet/common/sql/sqltest"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+var (
+	mockCtx         = context.Background()
+	mockWalletID    = "a89b7b78-b9c1-4129-8cff-380bf53f3a49"
+	mockOwnerID     = "n3k0fi5t"
+	mockApprover1   = "deadbeef"
+	mockApprover2   = "badc0ffee"
+	mockSpenderID   = "f00dcafe"
+	mockPayeeID     = "c0ffee00"
+	mockIntentID    = "935f871a-660f-4f19-801e-916c04bb0324"
+	mockHoldAccount = "hold-935f871a-660f-4f19-801e-916c04bb0324"
+	mockHoldID      = "5d2c9a4e-3f61-4a8e-9b0c-7e1f2d3c4b5a"
+	mockTradeID     = "7e1f2d3c-4b5a-4a8e-9b0c-5d2c9a4e3f61"
+	mockTimeMs      = int64(1650000000000)
+
+	anyApproval = mock.AnythingOfType("*approval.Approval")
+	anyIntent   = mock.AnythingOfType("*approval.Intent")
+)
+
+// eur returns amount minor units of the currency of the mock wallet
+func eur(amount int64) money.Money {
+	return money.New(amount, "EUR")
+}
+
+// twoOfTwo requires both approvers above 1000
+func twoOfTwo() *mdApproval.Policy {
+	return &mdApproval.Policy{
+		WalletID:  mockWalletID,
+		Threshold: eur(1000),
+		Required:  2,
+		Approvers: mockApprover1 + "," + mockApprover2,
+		UpdatedBy: mockOwnerID,
+		CreatedMs: mockTimeMs,
+		UpdatedMs: mockTimeMs,
+	}
+}
+
+// pendingIntent is a transfer of 5000 to the payee requested by the spender
+func pendingIntent() *mdApproval.Intent {
+	return &mdApproval.Intent{
+		IntentID:      mockIntentID,
+		WalletID:      mockWalletID,
+		RequestedBy:   mockSpenderID,
+		ToAccountID:   mockPayeeID,
+		Amount:        eur(5000),
+		HoldAccountID: mockHoldAccount,
+		Status:        mdApproval.Status_PENDING,
+		Required:      2,
+		Approvers:     mockApprover1 + "," + mockApprover2,
+		HoldTradeID:   mockHoldID,
+		ExpiresAtMs:   mockTimeMs + wallet.IntentTTL.Milliseconds(),
+		CreatedMs:     mockTimeMs,
+		UpdatedMs:     mockTimeMs,
+	}
+}
+
+func member(userID string, role mdWallet.Role) *mdWallet.Member {
+	return &mdWallet.Member{WalletID: mockWalletID, UserID: userID, Role: role}
+}
+
+// payout is the dealing paying the hold account out to the account
+func payout(toAccountID string) *mdBank.Dealing {
+	return &mdBank.Dealing{
+		FromAccountID: mockHoldAccount,
+		ToAccountID:   toAccountID,
+		Amount:        eur(5000),
+	}
+}
+
+type testSuite struct {
+	suite.Suite
+	srv       Service
+	mBank     *mockBank.Bank
+	mApproval *mockApproval.Approval
+	mWallet   *mockWallet.Service
+}
+
+func (s *testSuite) SetupSuite() {
+	timeNowMs = func() int64 { return mockTimeMs }
+}
+
+func (s *testSuite) TearDownSuite() {
+}
+
+func (s *testSuite) SetupTest() {
+	s.mBank = &mockBank.Bank{}
+	s.mApproval = &mockApproval.Approval{}
+	s.mWallet = &mockWallet.Service{}
+	s.srv = NewApproval(sqltest.Transactor{}, s.mBank, s.mApproval, s.mWallet, DefaultConfig)
+}
+
+func (s *testSuite) TearDownTest() {
+	s.mBank.AssertExpectations(s.T())
+	s.mApproval.AssertExpectations(s.T())
+	s.mWallet.AssertExpectations(s.T())
+}
+
+func (s *testSuite) TestSetPolicy() {
+	members := []*mdWallet.Member{
+		member(mockOwnerID, mdWallet.Role_OWNER),
+		member(mockApprover1, mdWallet.Role_VIEWER),
+		member(mockApprover2, mdWallet.Role_SPENDER),
+	}
+
+	tests := []struct {
+		Desc      string
+		UserID    string
+		Threshold money.Money
+		Required  int
+		Approvers []string
+		ExpPolicy *mdApproval.Policy
+		ExpError  error
+		setup     func()
+	}{
+		{
+			Desc:      "normal Path",
+			UserID:    mockOwnerID,
+			Threshold: eur(1000),
+			Required:  2,
+			Approvers: []string{mockApprover1, mockApprover2},
+			ExpPolicy: twoOfTwo(),
+			setup: func() {
+				s.mWallet.On("ListMembers", mockCtx, mockOwnerID, mockWalletID).Return(members, nil).Once()
+				s.mBank.On("GetAccount", mockCtx, mockWalletID).Return(&mdBank.Account{AccountID: mockWalletID, Balance: eur(0)}, nil).Once()
+				s.mApproval.On("SavePolicy", mockCtx, twoOfTwo()).Return(nil).Once()
+			},
+		},
+		{
+			Desc:      "bad Path, threshold in another currency",
+			UserID:    mockOwnerID,
+			Threshold: money.New(1000, "USD"),
+			Required:  2,
+			Approvers: []string{mockApprover1, mockApprover2},
+			ExpError:  bank.ErrCurrencyMismatch,
+			setup: func() {
+				s.mWallet.On("ListMembers", mockCtx, mockOwnerID, mockWalletID).Return(members, nil).Once()
+				s.mBank.On("GetAccount", mockCtx, mockWalletID).Return(&mdBank.Account{AccountID: mockWalletID, Balance: eur(0)}, nil).Once()
+			},
+		},
+		{
+			Desc:      "bad Path, more required than approvers",
+			UserID:    mockOwnerID,
+			Threshold: eur(1000),
+			Required:  3,
+			Approvers: []string{mockApprover1, mockApprover2},
+			ExpError:  ErrInvalidPolicy,
+		},
+		{
+			Desc:      "bad Path, approver repeats",
+			UserID:    mockOwnerID,
+			Threshold: eur(1000),
+			Required:  2,
+			Approvers: []string{mockApprover1, mockApprover1},
+			ExpError:  ErrInvalidPolicy,
+		},
+		{
+			Desc:      "bad Path, approver not member",
+			UserID:    mockOwnerID,
+			Threshold: eur(1000),
+			Required:  1,
+			Approvers: []string{mockApprover1, mockPayeeID},
+			ExpError:  ErrInvalidApprover,
+			setup: func() {
+				s.mWallet.On("ListMembers", mockCtx, mockOwnerID, mockWalletID).Return(members, nil).Once()
+			},
+		},
+		{
+			Desc:      "bad Path, spender sets policy",
+			UserID:    mockApprover2,
+			Threshold: eur(1000),
+			Required:  1,
+			Approvers: []string{mockApprover2},
+			ExpError:  wallet.ErrPermissionDenied,
+			setup: func() {
+				s.mWallet.On("ListMembers", mockCtx, mockApprover2, mockWalletID).Return(members, nil).Once()
+			},
+		},
+	}
+
+	for _, t := range tests {
+		s.SetupTest()
+		if t.setup != nil {
+			t.setup()
+		}
+
+		p, err := s.srv.SetPolicy(mockCtx, t.UserID, mockWalletID, t.Threshold, t.Required, t.Approvers)
+		s.Require().Equal(t.ExpError, err, t.Desc)
+		s.Require().Equal(t.ExpPolicy, p, t.Desc)
+		s.TearDownTest()
+	}
+}
+
+func (s *testSuite) TestDeletePolicy() {
+	s.mWallet.On("Authorize", mockCtx, mockApprover1, mockWalletID).Return(member(mockApprover1, mdWallet.Role_VIEWER), nil).Once()
+	s.Require().Equal(wallet.ErrPermissionDenied, s.srv.DeletePolicy(mockCtx, mockApprover1, mockWalletID))
+
+	s.mWallet.On("Authorize", mockCtx, mockOwnerID, mockWalletID).Return(member(mockOwnerID, mdWallet.Role_OWNER), nil).Once()
+	s.mApproval.On("DeletePolicy", mockCtx, mockWalletID).Return(nil).Once()
+	s.Require().NoError(s.srv.DeletePolicy(mockCtx, mockOwnerID, mockWalletID))
+	s.TearDownTest()
+}
+
+func (s *testSuite) TestRequestApproval() {
+	tests := []struct {
+		Desc      string
+		UserID    string
+		ToAccount string
+		Amount    money.Money
+		ExpIntent *mdApproval.Intent
+		ExpError  error
+		setup     func()
+	}{
+		{
+			Desc:      "normal Path",
+			UserID:    mockSpenderID,
+			ToAccount: mockPayeeID,
+			Amount:    eur(5000),
+			ExpIntent: pendingIntent(),
+			setup: func() {
+				s.mWallet.On("Hold", mockCtx, mockSpenderID, mockWalletID, mockPayeeID, eur(5000)).Return(pendingIntent(), nil).Once()
+			},
+		},
+		{
+			Desc:      "bad Path, within threshold",
+			UserID:    mockSpenderID,
+			ToAccount: mockPayeeID,
+			Amount:    eur(1000),
+			ExpError:  ErrApprovalNotRequired,
+			setup: func() {
+				s.mWallet.On("Hold", mockCtx, mockSpenderID, mockWalletID, mockPayeeID, eur(1000)).Return(nil, ErrApprovalNotRequired).Once()
+			},
+		},
+		{
+			Desc:      "bad Path, requester is one of two required approvers",
+			UserID:    mockApprover2,
+			ToAccount: mockPayeeID,
+			Amount:    eur(5000),
+			ExpError:  ErrNotEnoughApprovers,
+			setup: func() {
+				s.mWallet.On("Hold", mockCtx, mockApprover2, mockWalletID, mockPayeeID, eur(5000)).Return(nil, ErrNotEnoughApprovers).Once()
+			},
+		},
+		{
+			Desc:      "bad Path, pay to the wallet itself",
+			UserID:    mockSpenderID,
+			ToAccount: mockWalletID,
+			Amount:    eur(5000),
+			ExpError:  bank.ErrSelfTransfer,
+		},
+		{
+			Desc:      "bad Path, zero amount",
+			UserID:    mockSpenderID,
+			ToAccount: mockPayeeID,
+			ExpError:  ErrInvalidAmount,
+		},
+	}
+
+	for _, t := range tests {
+		s.SetupTest()
+		if t.setup != nil {
+			t.setup()
+		}
+
+		i, err := s.srv.RequestApproval(mockCtx, t.UserID, mockWalletID, t.ToAccount, t.Amount)
+		s.Require().Equal(t.ExpError, err, t.Desc)
+		s.Require().Equal(t.ExpIntent, i, t.Desc)
+		s.TearDownTest()
+	}
+}
+
+func (s *testSuite) TestGetIntent() {
+	history := []*mdApproval.Approval{{IntentID: mockIntentID, ApproverID: mockApprover1, Decision: mdApproval.Decision_APPROVE}}
+	s.mApproval.On("GetIntent", mockCtx, mockIntentID).Return(pendingIntent(), nil).Twice()
+	s.mWallet.On("Authorize", mockCtx, mockOwnerID, mockWalletID).Return(member(mockOwnerID, mdWallet.Role_OWNER), nil).Once()
+	s.mApproval.On("ListApprovals", mockCtx, mockIntentID).Return(history, nil).Once()
+
+	i, approvals, err := s.srv.GetIntent(mockCtx, mockOwnerID, mockIntentID)
+	s.Require().NoError(err)
+	s.Require().Equal(pendingIntent(), i)
+	s.Require().Equal(history, approvals)
+
+	// intents of other wallets look not exist
+	s.mWallet.On("Authorize", mockCtx, mockPayeeID, mockWalletID).Return(nil, bank.ErrAccountNotExist).Once()
+	_, _, err = s.srv.GetIntent(mockCtx, mockPayeeID, mockIntentID)
+	s.Require().Equal(approval.ErrIntentNotExist, err)
+	s.TearDownTest()
+}
+
+func (s *testSuite) TestApprove() {
+	approvedBy1 := []*mdApproval.Approval{{IntentID: mockIntentID, ApproverID: mockApprover1, Decision: mdApproval.Decision_APPROVE}}
+
+	tests := []struct {
+		Desc      string
+		UserID    string
+		ExpStatus mdApproval.Status
+		ExpError  error
+		setup     func()
+	}{
+		{
+			Desc:      "normal Path, first approval",
+			UserID:    mockApprover1,
+			ExpStatus: mdApproval.Status_PENDING,
+			setup: func() {
+				s.mApproval.On("LockIntent", mockCtx, mockIntentID).Return(pendingIntent(), nil).Once()
+				s.mWallet.On("Authorize", mockCtx, mockApprover1, mockWalletID).Return(member(mockApprover1, mdWallet.Role_VIEWER), nil).Once()
+				s.mApproval.On("ListApprovals", mockCtx, mockIntentID).Return(nil, nil).Once()
+				s.mApproval.On("CreateApproval", mockCtx, &mdApproval.Approval{
+					IntentID:   mockIntentID,
+					ApproverID: mockApprover1,
+					Decision:   mdApproval.Decision_APPROVE,
+					Comment:    "ok",
+					CreatedMs:  mockTimeMs,
+				}).Return(nil).Once()
+			},
+		},
+		{
+			Desc:      "normal Path, last approval executes",
+			UserID:    mockApprover2,
+			ExpStatus: mdApproval.Status_EXECUTED,
+			setup: func() {
+				s.mApproval.On("LockIntent", mockCtx, mockIntentID).Return(pendingIntent(), nil).Once()
+				s.mWallet.On("Authorize", mockCtx, mockApprover2, mockWalletID).Return(member(mockApprover2, mdWallet.Role_SPENDER), nil).Once()
+				s.mApproval.On("ListApprovals", mockCtx, mockIntentID).Return(approvedBy1, nil).Once()
+				s.mApproval.On("CreateApproval", mockCtx, anyApproval).Return(nil).Once()
+				s.mWallet.On("GetAccount", mockCtx, mockPayeeID).Return(&mdBank.Account{AccountID: mockPayeeID, Balance: eur(0)}, nil).Once()
+				s.mBank.On("Trade", mockCtx, payout(mockPayeeID)).Return(mockTradeID, nil).Once()
+				s.mApproval.On("UpdateIntent", mockCtx, anyIntent).Return(nil).Once()
+			},
+		},
+		{
+			Desc:     "bad Path, payee no longer a user",
+			UserID:   mockApprover2,
+			ExpError: bank.ErrAccountNotExist,
+			setup: func() {
+				s.mApproval.On("LockIntent", mockCtx, mockIntentID).Return(pendingIntent(), nil).Once()
+				s.mWallet.On("Authorize", mockCtx, mockApprover2, mockWalletID).Return(member(mockApprover2, mdWallet.Role_SPENDER), nil).Once()
+				s.mApproval.On("ListApprovals", mockCtx, mockIntentID).Return(approvedBy1, nil).Once()
+				s.mApproval.On("CreateApproval", mockCtx, anyApproval).Return(nil).Once()
+				s.mWallet.On("GetAccount", mockCtx, mockPayeeID).Return(nil, bank.ErrAccountNotExist).Once()
+			},
+		},
+		{
+			Desc:     "bad Path, approved already",
+			UserID:   mockApprover1,
+			ExpError: ErrAlreadyDecided,
+			setup: func() {
+				s.mApproval.On("LockIntent", mockCtx, mockIntentID).Return(pendingIntent(), nil).Once()
+				s.mWallet.On("Authorize", mockCtx, mockApprover1, mockWalletID).Return(member(mockApprover1, mdWallet.Role_VIEWER), nil).Once()
+				s.mApproval.On("ListApprovals", mockCtx, mockIntentID).Return(approvedBy1, nil).Once()
+			},
+		},
+		{
+			Desc:     "bad Path, self approval",
+			UserID:   mockSpenderID,
+			ExpError: ErrSelfApproval,
+			setup: func() {
+				s.mApproval.On("LockIntent", mockCtx, mockIntentID).Return(pendingIntent(), nil).Once()
+				s.mWallet.On("Authorize", mockCtx, mockSpenderID, mockWalletID).Return(member(mockSpenderID, mdWallet.Role_SPENDER), nil).Once()
+			},
+		},
+		{
+			Desc:     "bad Path, not approver",
+			UserID:   mockOwnerID,
+			ExpError: wallet.ErrPermissionDenied,
+			setup: func() {
+				s.mApproval.On("LockIntent", mockCtx, mockIntentID).Return(pendingIntent(), nil).Once()
+				s.mWallet.On("Authorize", mockCtx, mockOwnerID, mockWalletID).Return(member(mockOwnerID, mdWallet.Role_OWNER), nil).Once()
+			},
+		},
+		{
+			Desc:     "bad Path, expired",
+			UserID:   mockApprover1,
+			ExpError: ErrIntentNotPending,
+			setup: func() {
+				expired := pendingIntent()
+				expired.ExpiresAtMs = mockTimeMs
+				s.mApproval.On("LockIntent", mockCtx, mockIntentID).Return(expired, nil).Once()
+				s.mWallet.On("Authorize", mockCtx, mockApprover1, mockWalletID).Return(member(mockApprover1, mdWallet.Role_VIEWER), nil).Once()
+			},
+		},
+	}
+
+	for _, t := range tests {
+		s.SetupTest()
+		t.setup()
+
+		i, err := s.srv.Approve(mockCtx, t.UserID, mockIntentID, "ok")
+		s.Require().Equal(t.ExpError, err, t.Desc)
+		if t.ExpError == nil {
+			s.Require().Equal(t.ExpStatus, i.Status, t.Desc)
+		}
+		s.TearDownTest()
+	}
+}
+
+func (s *testSuite) TestApproveWithdrawal() {
+	withdrawal := pendingIntent()
+	withdrawal.ToAccountID = ""
+	withdrawal.Required = 1
+	s.mApproval.On("LockIntent", mockCtx, mockIntentID).Return(withdrawal, nil).Once()
+	s.mWallet.On("Authorize", mockCtx, mockApprover1, mockWalletID).Return(member(mockApprover1, mdWallet.Role_VIEWER), nil).Once()
+	s.mApproval.On("ListApprovals", mockCtx, mockIntentID).Return(nil, nil).Once()
+	s.mApproval.On("CreateApproval", mockCtx, anyApproval).Return(nil).Once()
+	s.mBank.On("GetAccount", mockCtx, mockHoldAccount).Return(&mdBank.Account{AccountID: mockHoldAccount, Balance: eur(0)}, nil).Once()
+	s.mBank.On("Trade", mockCtx, payout("sys-funding-direct-EUR")).Return(mockTradeID, nil).Once()
+	s.mApproval.On("UpdateIntent", mockCtx, anyIntent).Return(nil).Once()
+
+	i, err := s.srv.Approve(mockCtx, mockApprover1, mockIntentID, "")
+	s.Require().NoError(err)
+	s.Require().Equal(mdApproval.Status_EXECUTED, i.Status)
+	s.Require().Equal(mockTradeID, i.SettleTradeID)
+	s.TearDownTest()
+}
+
+func (s *testSuite) TestReject() {
+	tests := []struct {
+		Desc     string
+		UserID   string
+		ExpError error
+		setup    func()
+	}{
+		{
+			Desc:   "normal Path, approver rejects",
+			UserID: mockApprover2,
+			setup: func() {
+				s.mWallet.On("Authorize", mockCtx, mockApprover2, mockWalletID).Return(member(mockApprover2, mdWallet.Role_SPENDER), nil).Once()
+			},
+		},
+		{
+			Desc:   "normal Path, requester cancels",
+			UserID: mockSpenderID,
+			setup: func() {
+				s.mWallet.On("Authorize", mockCtx, mockSpenderID, mockWalletID).Return(member(mockSpenderID, mdWallet.Role_SPENDER), nil).Once()
+			},
+		},
+	}
+
+	for _, t := range tests {
+		s.SetupTest()
+		t.setup()
+		s.mApproval.On("LockIntent", mockCtx, mockIntentID).Return(pendingIntent(), nil).Once()
+		s.mApproval.On("ListApprovals", mockCtx, mockIntentID).Return(nil, nil).Once()
+		s.mApproval.On("CreateApproval", mockCtx, &mdApproval.Approval{
+			IntentID:   mockIntentID,
+			ApproverID: t.UserID,
+			Decision:   mdApproval.Decision_REJECT,
+			Comment:    "wrong payee",
+			CreatedMs:  mockTimeMs,
+		}).Return(nil).Once()
+		s.mBank.On("Trade", mockCtx, payout(mockWalletID)).Return(mockTradeID, nil).Once()
+		s.mApproval.On("UpdateIntent", mockCtx, anyIntent).Return(nil).Once()
+
+		i, err := s.srv.Reject(mockCtx, t.UserID, mockIntentID, "wrong payee")
+		s.Require().Equal(t.ExpError, err, t.Desc)
+		s.Require().Equal(mdApproval.Status_REJECTED, i.Status, t.Desc)
+		s.TearDownTest()
+	}
+}
+
+func (s *testSuite) TestExpireDue() {
+	due := pendingIntent()
+	due.ExpiresAtMs = mockTimeMs
+	executed := pendingIntent()
+	executed.Status = mdApproval.Status_EXECUTED
+
+	s.mApproval.On("ListExpired", mockCtx, mockTimeMs, DefaultConfig.BatchSize).Return([]*mdApproval.Intent{due, due}, nil).Once()
+	s.mApproval.On("LockIntent", mockCtx, mockIntentID).Return(due, nil).Once()
+	s.mBank.On("Trade", mockCtx, payout(mockWalletID)).Return(mockTradeID, nil).Once()
+	s.mApproval.On("UpdateIntent", mockCtx, anyIntent).Return(nil).Once()
+	// approved by the time it is locked
+	s.mApproval.On("LockIntent", mockCtx, mockIntentID).Return(executed, nil).Once()
+
+	n, err := s.srv.ExpireDue(mockCtx)
+	s.Require().NoError(err)
+	s.Require().Equal(1, n)
+	s.Require().Equal(mdApproval.Status_EXPIRED, due.Status)
+	s.TearDownTest()
+}
+
+func TestSuite(t *testing.T) {
+	suite.Run(t, new(testSuite))
+}
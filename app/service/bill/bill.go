@@ -0,0 +1,61 @@
+package bill
+
+import (
+	"context"
+	"fmt"
+
+	mBill "github.com/n3k0fi5t/wallet/app/models/bill"
+	"github.com/n3k0fi5t/wallet/app/money"
+)
+
+var (
+	// ErrInvalidTotal means the total is not positive
+	ErrInvalidTotal = fmt.Errorf("Invalid total")
+
+	// ErrInvalidSplitType means the split type is not one of the known types
+	ErrInvalidSplitType = fmt.Errorf("Invalid split type")
+
+	// ErrInvalidShares means the shares do not add up to the total or some share is not positive
+	ErrInvalidShares = fmt.Errorf("Invalid shares")
+
+	// ErrInvalidParticipants means there is no participant other than the organizer, too many or duplicated participants
+	ErrInvalidParticipants = fmt.Errorf("Invalid participants")
+
+	// ErrMemoTooLong means the memo exceeds MaxMemoLength
+	ErrMemoTooLong = fmt.Errorf("Memo too long")
+
+	// ErrBillNotOpen means the bill is settled or canceled already
+	ErrBillNotOpen = fmt.Errorf("Bill not open")
+
+	// ErrShareNotPending means the share of the participant is paid already
+	ErrShareNotPending = fmt.Errorf("Share not pending")
+)
+
+const (
+	// MaxMemoLength is the max length of memo in bytes
+	MaxMemoLength = 255
+
+	// MaxParticipants is the max number of participants of a bill, the organizer included
+	MaxParticipants = 50
+)
+
+type Service interface {
+	// CreateBill splits the total among participants, the organizer may take part and the share is paid already.
+	// The organizer and participants hold wallets in the currency of the total
+	CreateBill(ctx context.Context, organizerID string, total money.Money, memo string, splitType mBill.SplitType, participants []*mBill.Participant) (*mBill.Bill, error)
+
+	// GetBill get a bill the account organizes or takes part in
+	GetBill(ctx context.Context, accountID, billID string) (*mBill.Bill, error)
+
+	// ListBills list bills the account organizes or takes part in, newest first
+	ListBills(ctx context.Context, accountID string, role mBill.Role, status mBill.Status, offset, limit int) ([]*mBill.Bill, error)
+
+	// PayShare transfers the share of the participant to the organizer, the bill is settled with the last share
+	PayShare(ctx context.Context, accountID, billID string) (*mBill.Bill, error)
+
+	// Remind reminds participants with pending shares, a share is reminded at most once per RemindInterval
+	Remind(ctx context.Context, accountID, billID string) (*mBill.Bill, error)
+
+	// CancelBill stops collecting pending shares, only the organizer can cancel, paid shares are kept
+	CancelBill(ctx context.Context, accountID, billID string) (*mBill.Bill, error)
+}
@@ -0,0 +1,317 @@
+package bill
+
+import (
+	"context"
+	"time"
+
+	mBill "github.com/n3k0fi5t/wallet/app/models/bill"
+	mEvent "github.com/n3k0fi5t/wallet/app/models/event"
+	"github.com/n3k0fi5t/wallet/app/money"
+	"github.com/n3k0fi5t/wallet/app/repository/bank"
+	"github.com/n3k0fi5t/wallet/app/repository/bill"
+	"github.com/n3k0fi5t/wallet/app/repository/outbox"
+	"github.com/n3k0fi5t/wallet/app/service/wallet"
+	"github.com/n3k0fi5t/wallet/app/util"
+	"github.com/n3k0fi5t/wallet/common/logger"
+	"github.com/n3k0fi5t/wallet/common/sql"
+)
+
+const (
+	// RemindInterval is the least time between reminders of a share
+	RemindInterval = 24 * time.Hour
+)
+
+var (
+	timeNowMs = util.TimeNowMs
+	getUUID   = util.GetUUIDv4
+)
+
+func NewBill(t sql.Transactor, b bill.Bill, o outbox.Outbox, w wallet.Service) Service {
+	return &impl{
+		transactor: t,
+		bill:       b,
+		outbox:     o,
+		walletSrv:  w,
+	}
+}
+
+type impl struct {
+	transactor sql.Transactor
+	bill       bill.Bill
+	outbox     outbox.Outbox
+	walletSrv  wallet.Service
+}
+
+func (im *impl) CreateBill(ctx context.Context, organizerID string, total money.Money, memo string, splitType mBill.SplitType, participants []*mBill.Participant) (*mBill.Bill, error) {
+	if !total.IsPositive() {
+		return nil, ErrInvalidTotal
+	} else if !splitType.IsValid() {
+		return nil, ErrInvalidSplitType
+	} else if len(memo) > MaxMemoLength {
+		return nil, ErrMemoTooLong
+	} else if len(participants) > MaxParticipants {
+		return nil, ErrInvalidParticipants
+	}
+
+	// someone other than the organizer has to pay, and nobody takes two shares
+	others := 0
+	seen := map[string]bool{}
+	for _, p := range participants {
+		if seen[p.AccountID] {
+			return nil, ErrInvalidParticipants
+		}
+		seen[p.AccountID] = true
+		if p.AccountID != organizerID {
+			others++
+		}
+	}
+	if others == 0 {
+		return nil, ErrInvalidParticipants
+	}
+
+	amounts, err := split(total, splitType, participants)
+	if err != nil {
+		return nil, err
+	}
+
+	// the organizer and participants have to exist and hold the currency of the total, or shares could never be paid
+	accountIDs := []string{organizerID}
+	for _, p := range participants {
+		if p.AccountID != organizerID {
+			accountIDs = append(accountIDs, p.AccountID)
+		}
+	}
+	for _, accountID := range accountIDs {
+		account, err := im.walletSrv.GetAccount(ctx, accountID)
+		if err != nil {
+			logger.FromContext(ctx).WithField("err", err).Error("walletSrv.GetAccount failed in CreateBill")
+			return nil, err
+		}
+		if account.Currency() != total.Currency {
+			return nil, bank.ErrCurrencyMismatch
+		}
+	}
+
+	billID, err := getUUID()
+	if err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("GetUUIDv4 failed in CreateBill")
+		return nil, err
+	}
+
+	nowMs := timeNowMs()
+	b := &mBill.Bill{
+		BillID:      billID,
+		OrganizerID: organizerID,
+		Total:       total,
+		Memo:        memo,
+		SplitType:   splitType,
+		Status:      mBill.Status_OPEN,
+		CreatedMs:   nowMs,
+		UpdatedMs:   nowMs,
+	}
+	for i, p := range participants {
+		share := &mBill.Share{
+			BillID:        billID,
+			ParticipantID: p.AccountID,
+			Amount:        amounts[i],
+			Status:        mBill.ShareStatus_PENDING,
+			CreatedMs:     nowMs,
+			UpdatedMs:     nowMs,
+		}
+		// the organizer paid the whole bill already
+		if p.AccountID == organizerID {
+			share.Status = mBill.ShareStatus_PAID
+		}
+		b.Shares = append(b.Shares, share)
+	}
+
+	if err := im.bill.CreateBill(ctx, b); err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("bill.CreateBill failed")
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// participates tells whether the account organizes or takes part in the bill
+func participates(b *mBill.Bill, accountID string) bool {
+	return b.OrganizerID == accountID || b.Share(accountID) != nil
+}
+
+func (im *impl) GetBill(ctx context.Context, accountID, billID string) (*mBill.Bill, error) {
+	b, err := im.bill.GetBill(ctx, billID)
+	if err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("bill.GetBill failed")
+		return nil, err
+	}
+
+	// bills of others look not exist, so bill IDs can not be probed
+	if !participates(b, accountID) {
+		return nil, bill.ErrBillNotExist
+	}
+	return b, nil
+}
+
+func (im *impl) ListBills(ctx context.Context, accountID string, role mBill.Role, status mBill.Status, offset, limit int) ([]*mBill.Bill, error) {
+	bills, err := im.bill.ListBills(ctx, accountID, role, status, offset, limit)
+	if err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("bill.ListBills failed")
+		return nil, err
+	}
+	return bills, nil
+}
+
+// change locks the open bill and applies f to it, changes of the same bill take turns
+func (im *impl) change(ctx context.Context, accountID, billID string, f func(context.Context, *mBill.Bill) error) (*mBill.Bill, error) {
+	var b *mBill.Bill
+	if err := im.transactor.Transact(ctx, func(ctx context.Context) error {
+		locked, err := im.bill.LockBill(ctx, billID)
+		if err != nil {
+			logger.FromContext(ctx).WithField("err", err).Error("bill.LockBill failed")
+			return err
+		}
+
+		if !participates(locked, accountID) {
+			return bill.ErrBillNotExist
+		} else if locked.Status != mBill.Status_OPEN {
+			return ErrBillNotOpen
+		}
+
+		if err := f(ctx, locked); err != nil {
+			return err
+		}
+		b = locked
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+func (im *impl) PayShare(ctx context.Context, accountID, billID string) (*mBill.Bill, error) {
+	return im.change(ctx, accountID, billID, func(ctx context.Context, b *mBill.Bill) error {
+		share := b.Share(accountID)
+		if share == nil {
+			return bill.ErrBillNotExist
+		} else if share.Status != mBill.ShareStatus_PENDING {
+			return ErrShareNotPending
+		}
+
+		// shares are paid at once, a held transfer is rolled back with the bill
+		tradeID, intent, err := im.walletSrv.Transfer(ctx, accountID, b.OrganizerID, share.Amount)
+		if err != nil {
+			logger.FromContext(ctx).WithField("err", err).Error("walletSrv.Transfer failed in PayShare")
+			return err
+		} else if intent != nil {
+			return wallet.ErrApprovalRequired
+		}
+
+		nowMs := timeNowMs()
+		share.Status = mBill.ShareStatus_PAID
+		share.TradeID = tradeID
+		share.UpdatedMs = nowMs
+		if err := im.bill.UpdateShares(ctx, share); err != nil {
+			logger.FromContext(ctx).WithField("err", err).Error("bill.UpdateShares failed in PayShare")
+			return err
+		}
+
+		if !b.AllPaid() {
+			return nil
+		}
+		b.Status = mBill.Status_SETTLED
+		b.UpdatedMs = nowMs
+		if err := im.bill.UpdateBill(ctx, b); err != nil {
+			logger.FromContext(ctx).WithField("err", err).Error("bill.UpdateBill failed in PayShare")
+			return err
+		}
+		return nil
+	})
+}
+
+func (im *impl) Remind(ctx context.Context, accountID, billID string) (*mBill.Bill, error) {
+	return im.change(ctx, accountID, billID, func(ctx context.Context, b *mBill.Bill) error {
+		if b.OrganizerID != accountID {
+			return bill.ErrBillNotExist
+		}
+
+		nowMs := timeNowMs()
+		reminded := []*mBill.Share{}
+		for _, share := range b.Shares {
+			if share.Status != mBill.ShareStatus_PENDING || nowMs-share.RemindedMs < RemindInterval.Milliseconds() {
+				continue
+			}
+			share.Reminders++
+			share.RemindedMs = nowMs
+			share.UpdatedMs = nowMs
+			reminded = append(reminded, share)
+		}
+
+		if len(reminded) == 0 {
+			return nil
+		}
+		if err := im.bill.UpdateShares(ctx, reminded...); err != nil {
+			logger.FromContext(ctx).WithField("err", err).Error("bill.UpdateShares failed in Remind")
+			return err
+		}
+
+		// reminders reach the participants through the relay, e.g. their webhooks, and only if the counters commit
+		events := make([]*mEvent.Event, 0, len(reminded))
+		for _, share := range reminded {
+			eventID, err := getUUID()
+			if err != nil {
+				logger.FromContext(ctx).WithField("err", err).Error("GetUUIDv4 failed in Remind")
+				return err
+			}
+			events = append(events, &mEvent.Event{
+				EventID:     eventID,
+				Type:        mEvent.Type_BILL_REMINDER,
+				AccountID:   share.ParticipantID,
+				TimestampMs: nowMs,
+				Bill: &mEvent.Bill{
+					BillID:      b.BillID,
+					OrganizerID: b.OrganizerID,
+					Amount:      share.Amount,
+					Memo:        b.Memo,
+					Reminders:   share.Reminders,
+				},
+			})
+		}
+		if err := im.outbox.Add(ctx, events...); err != nil {
+			logger.FromContext(ctx).WithField("err", err).Error("outbox.Add failed in Remind")
+			return err
+		}
+		return nil
+	})
+}
+
+func (im *impl) CancelBill(ctx context.Context, accountID, billID string) (*mBill.Bill, error) {
+	return im.change(ctx, accountID, billID, func(ctx context.Context, b *mBill.Bill) error {
+		if b.OrganizerID != accountID {
+			return bill.ErrBillNotExist
+		}
+
+		nowMs := timeNowMs()
+		canceled := []*mBill.Share{}
+		for _, share := range b.Shares {
+			if share.Status != mBill.ShareStatus_PENDING {
+				continue
+			}
+			share.Status = mBill.ShareStatus_CANCELED
+			share.UpdatedMs = nowMs
+			canceled = append(canceled, share)
+		}
+		if err := im.bill.UpdateShares(ctx, canceled...); err != nil {
+			logger.FromContext(ctx).WithField("err", err).Error("bill.UpdateShares failed in CancelBill")
+			return err
+		}
+
+		b.Status = mBill.Status_CANCELED
+		b.UpdatedMs = nowMs
+		if err := im.bill.UpdateBill(ctx, b); err != nil {
+			logger.FromContext(ctx).WithField("err", err).Error("bill.UpdateBill failed in CancelBill")
+			return err
+		}
+		return nil
+	})
+}
@@ -0,0 +1,385 @@
+package bill
+
+import (
+	"context"
+	"testing"
+
+	mdBank "github.com/n3k0fi5t/wallet/app/models/bank"
+	mdBill "github.com/n3k0fi5t/wallet/app/models/bill"
+	mdEvent "github.com/n3k0fi5t/wallet/app/models/event"
+	"github.com/n3k0fi5t/wallet/app/money"
+	"github.com/n3k0fi5t/wallet/app/repository/bank"
+	"github.com/n3k0fi5t/wallet/app/repository/bill"
+	mockBill "github.com/n3k0fi5t/wallet/app/repository/bill/mocks"
+	mockOutbox "github.com/n3k0fi5t/wallet/app/repository/outbox/mocks"
+	mockWallet "github.com/n3k0fi5t/wallet/app/service/wallet/mocks"
+	"github.com/n3k0fi5t/wallet/common/sql/sqltest"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+var (
+	mockCtx         = context.Background()
+	mockOrganizerID = "n3k0fi5t"
+	mockPayerID1    = "deadbeef"
+	mockPayerID2    = "cafebabe"
+	mockStrangerID  = "badc0ffee"
+	mockBillID      = "935f871a-660f-4f19-801e-916c04bb0324"
+	mockTradeID     = "a89b7b78-b9c1-4129-8cff-380bf53f3a49"
+	mockTimeMs      = int64(1650000000000)
+
+	anyShare = mock.AnythingOfType("*bill.Share")
+	anyEvent = mock.AnythingOfType("*event.Event")
+)
+
+// usdWallet is a wallet in the currency of the bills
+func usdWallet(accountID string) *mdBank.Account {
+	return &mdBank.Account{AccountID: accountID, Balance: money.New(0, "USD")}
+}
+
+// openBill is a dinner of 900 split by the organizer and two payers, the organizer's share is paid
+func openBill() *mdBill.Bill {
+	b := &mdBill.Bill{
+		BillID:      mockBillID,
+		OrganizerID: mockOrganizerID,
+		Total:       money.New(900, "USD"),
+		Memo:        "dinner",
+		SplitType:   mdBill.SplitType_EQUAL,
+		Status:      mdBill.Status_OPEN,
+		CreatedMs:   mockTimeMs,
+		UpdatedMs:   mockTimeMs,
+	}
+	for _, id := range []string{mockOrganizerID, mockPayerID1, mockPayerID2} {
+		b.Shares = append(b.Shares, &mdBill.Share{
+			BillID:        mockBillID,
+			ParticipantID: id,
+			Amount:        money.New(300, "USD"),
+			Status:        mdBill.ShareStatus_PENDING,
+			CreatedMs:     mockTimeMs,
+			UpdatedMs:     mockTimeMs,
+		})
+	}
+	b.Shares[0].Status = mdBill.ShareStatus_PAID
+	return b
+}
+
+type testSuite struct {
+	suite.Suite
+	srv     Service
+	mBill   *mockBill.Bill
+	mOutbox *mockOutbox.Outbox
+	mWallet *mockWallet.Service
+}
+
+func (s *testSuite) SetupSuite() {
+	timeNowMs = func() int64 { return mockTimeMs }
+	getUUID = func() (string, error) { return mockBillID, nil }
+}
+
+func (s *testSuite) TearDownSuite() {
+}
+
+func (s *testSuite) SetupTest() {
+	s.mBill = &mockBill.Bill{}
+	s.mOutbox = &mockOutbox.Outbox{}
+	s.mWallet = &mockWallet.Service{}
+	s.srv = NewBill(sqltest.Transactor{}, s.mBill, s.mOutbox, s.mWallet)
+}
+
+func (s *testSuite) TearDownTest() {
+	s.mBill.AssertExpectations(s.T())
+	s.mOutbox.AssertExpectations(s.T())
+	s.mWallet.AssertExpectations(s.T())
+}
+
+func (s *testSuite) TestCreateBill() {
+	participants := []*mdBill.Participant{{AccountID: mockOrganizerID}, {AccountID: mockPayerID1}, {AccountID: mockPayerID2}}
+
+	tests := []struct {
+		Desc         string
+		Total        money.Money
+		Participants []*mdBill.Participant
+		ExpBill      *mdBill.Bill
+		ExpError     error
+		setup        func()
+	}{
+		{
+			Desc:         "normal Path, organizer takes part",
+			Total:        money.New(900, "USD"),
+			Participants: participants,
+			ExpBill:      openBill(),
+			setup: func() {
+				s.mWallet.On("GetAccount", mockCtx, mockOrganizerID).Return(usdWallet(mockOrganizerID), nil).Once()
+				s.mWallet.On("GetAccount", mockCtx, mockPayerID1).Return(usdWallet(mockPayerID1), nil).Once()
+				s.mWallet.On("GetAccount", mockCtx, mockPayerID2).Return(usdWallet(mockPayerID2), nil).Once()
+				s.mBill.On("CreateBill", mockCtx, openBill()).Return(nil).Once()
+			},
+		},
+		{
+			Desc:         "bad Path, zero total",
+			Total:        money.New(0, "USD"),
+			Participants: participants,
+			ExpError:     ErrInvalidTotal,
+		},
+		{
+			Desc:         "bad Path, only the organizer",
+			Total:        money.New(900, "USD"),
+			Participants: participants[:1],
+			ExpError:     ErrInvalidParticipants,
+		},
+		{
+			Desc:         "bad Path, duplicated participant",
+			Total:        money.New(900, "USD"),
+			Participants: []*mdBill.Participant{{AccountID: mockPayerID1}, {AccountID: mockPayerID1}},
+			ExpError:     ErrInvalidParticipants,
+		},
+		{
+			Desc:         "bad Path, participant not exist",
+			Total:        money.New(900, "USD"),
+			Participants: participants,
+			ExpError:     bank.ErrAccountNotExist,
+			setup: func() {
+				s.mWallet.On("GetAccount", mockCtx, mockOrganizerID).Return(usdWallet(mockOrganizerID), nil).Once()
+				s.mWallet.On("GetAccount", mockCtx, mockPayerID1).Return((*mdBank.Account)(nil), bank.ErrAccountNotExist).Once()
+			},
+		},
+		{
+			Desc:         "bad Path, participant in another currency",
+			Total:        money.New(900, "USD"),
+			Participants: participants,
+			ExpError:     bank.ErrCurrencyMismatch,
+			setup: func() {
+				s.mWallet.On("GetAccount", mockCtx, mockOrganizerID).Return(usdWallet(mockOrganizerID), nil).Once()
+				s.mWallet.On("GetAccount", mockCtx, mockPayerID1).Return(&mdBank.Account{AccountID: mockPayerID1, Balance: money.New(0, "EUR")}, nil).Once()
+			},
+		},
+	}
+
+	for _, test := range tests {
+		s.SetupTest()
+		if test.setup != nil {
+			test.setup()
+		}
+
+		b, err := s.srv.CreateBill(mockCtx, mockOrganizerID, test.Total, "dinner", mdBill.SplitType_EQUAL, test.Participants)
+		s.Require().Equal(test.ExpBill, b, test.Desc)
+		s.Require().Equal(test.ExpError, err, test.Desc)
+
+		s.TearDownTest()
+	}
+}
+
+func (s *testSuite) TestGetBill() {
+	tests := []struct {
+		Desc      string
+		AccountID string
+		ExpError  error
+	}{
+		{Desc: "normal Path, organizer", AccountID: mockOrganizerID},
+		{Desc: "normal Path, participant", AccountID: mockPayerID2},
+		{Desc: "bad Path, stranger", AccountID: mockStrangerID, ExpError: bill.ErrBillNotExist},
+	}
+
+	for _, test := range tests {
+		s.SetupTest()
+		s.mBill.On("GetBill", mockCtx, mockBillID).Return(openBill(), nil).Once()
+
+		_, err := s.srv.GetBill(mockCtx, test.AccountID, mockBillID)
+		s.Require().Equal(test.ExpError, err, test.Desc)
+
+		s.TearDownTest()
+	}
+}
+
+func (s *testSuite) TestPayShare() {
+	lastUnpaid := openBill()
+	lastUnpaid.Shares[2].Status = mdBill.ShareStatus_PAID
+	canceled := openBill()
+	canceled.Status = mdBill.Status_CANCELED
+
+	tests := []struct {
+		Desc      string
+		AccountID string
+		Locked    *mdBill.Bill
+		ExpStatus mdBill.Status
+		ExpError  error
+		setup     func()
+	}{
+		{
+			Desc:      "normal Path, bill stays open",
+			AccountID: mockPayerID1,
+			Locked:    openBill(),
+			ExpStatus: mdBill.Status_OPEN,
+			setup: func() {
+				s.mWallet.On("Transfer", mockCtx, mockPayerID1, mockOrganizerID, money.New(300, "USD")).Return(mockTradeID, nil, nil).Once()
+				s.mBill.On("UpdateShares", mockCtx, &mdBill.Share{
+					BillID:        mockBillID,
+					ParticipantID: mockPayerID1,
+					Amount:        money.New(300, "USD"),
+					Status:        mdBill.ShareStatus_PAID,
+					TradeID:       mockTradeID,
+					CreatedMs:     mockTimeMs,
+					UpdatedMs:     mockTimeMs,
+				}).Return(nil).Once()
+			},
+		},
+		{
+			Desc:      "normal Path, last share settles the bill",
+			AccountID: mockPayerID1,
+			Locked:    lastUnpaid,
+			ExpStatus: mdBill.Status_SETTLED,
+			setup: func() {
+				s.mWallet.On("Transfer", mockCtx, mockPayerID1, mockOrganizerID, money.New(300, "USD")).Return(mockTradeID, nil, nil).Once()
+				s.mBill.On("UpdateShares", mockCtx, anyShare).Return(nil).Once()
+				s.mBill.On("UpdateBill", mockCtx, mock.MatchedBy(func(b *mdBill.Bill) bool { return b.Status == mdBill.Status_SETTLED })).Return(nil).Once()
+			},
+		},
+		{
+			Desc:      "bad Path, paid already",
+			AccountID: mockOrganizerID,
+			Locked:    openBill(),
+			ExpError:  ErrShareNotPending,
+		},
+		{
+			Desc:      "bad Path, stranger",
+			AccountID: mockStrangerID,
+			Locked:    openBill(),
+			ExpError:  bill.ErrBillNotExist,
+		},
+		{
+			Desc:      "bad Path, bill canceled",
+			AccountID: mockPayerID1,
+			Locked:    canceled,
+			ExpError:  ErrBillNotOpen,
+		},
+		{
+			Desc:      "bad Path, balance not enough",
+			AccountID: mockPayerID1,
+			Locked:    openBill(),
+			ExpError:  bank.ErrBalanceNotEnough,
+			setup: func() {
+				s.mWallet.On("Transfer", mockCtx, mockPayerID1, mockOrganizerID, money.New(300, "USD")).Return("", nil, bank.ErrBalanceNotEnough).Once()
+			},
+		},
+	}
+
+	for _, test := range tests {
+		s.SetupTest()
+		s.mBill.On("LockBill", mockCtx, mockBillID).Return(test.Locked, nil).Once()
+		if test.setup != nil {
+			test.setup()
+		}
+
+		b, err := s.srv.PayShare(mockCtx, test.AccountID, mockBillID)
+		s.Require().Equal(test.ExpError, err, test.Desc)
+		if err == nil {
+			s.Require().Equal(test.ExpStatus, b.Status, test.Desc)
+		}
+
+		s.TearDownTest()
+	}
+}
+
+func (s *testSuite) TestRemind() {
+	recentlyReminded := openBill()
+	recentlyReminded.Shares[1].Reminders = 1
+	recentlyReminded.Shares[1].RemindedMs = mockTimeMs - RemindInterval.Milliseconds() + 1
+
+	tests := []struct {
+		Desc         string
+		AccountID    string
+		Locked       *mdBill.Bill
+		ExpReminded  []string
+		ExpReminders []int
+		ExpError     error
+	}{
+		{
+			Desc:         "normal Path, pending shares are reminded",
+			AccountID:    mockOrganizerID,
+			Locked:       openBill(),
+			ExpReminded:  []string{mockPayerID1, mockPayerID2},
+			ExpReminders: []int{0, 1, 1},
+		},
+		{
+			Desc:         "normal Path, recently reminded share is skipped",
+			AccountID:    mockOrganizerID,
+			Locked:       recentlyReminded,
+			ExpReminded:  []string{mockPayerID2},
+			ExpReminders: []int{0, 1, 1},
+		},
+		{
+			Desc:      "bad Path, participant can not remind",
+			AccountID: mockPayerID1,
+			Locked:    openBill(),
+			ExpError:  bill.ErrBillNotExist,
+		},
+	}
+
+	for _, test := range tests {
+		s.SetupTest()
+		s.mBill.On("LockBill", mockCtx, mockBillID).Return(test.Locked, nil).Once()
+		if len(test.ExpReminded) > 0 {
+			args := []interface{}{mockCtx}
+			for range test.ExpReminded {
+				args = append(args, anyShare)
+			}
+			s.mBill.On("UpdateShares", args...).Return(nil).Once().Run(func(args mock.Arguments) {
+				reminded := []string{}
+				for _, arg := range args[1:] {
+					reminded = append(reminded, arg.(*mdBill.Share).ParticipantID)
+				}
+				s.Require().Equal(test.ExpReminded, reminded, test.Desc)
+			})
+
+			// each reminded participant gets a reminder event with the bill transaction
+			events := []interface{}{mockCtx}
+			for range test.ExpReminded {
+				events = append(events, anyEvent)
+			}
+			s.mOutbox.On("Add", events...).Return(nil).Once().Run(func(args mock.Arguments) {
+				notified := []string{}
+				for _, arg := range args[1:] {
+					e := arg.(*mdEvent.Event)
+					s.Require().Equal(mdEvent.Type_BILL_REMINDER, e.Type, test.Desc)
+					s.Require().Equal(&mdEvent.Bill{BillID: mockBillID, OrganizerID: mockOrganizerID, Amount: money.New(300, "USD"), Memo: "dinner", Reminders: 1}, e.Bill, test.Desc)
+					notified = append(notified, e.AccountID)
+				}
+				s.Require().Equal(test.ExpReminded, notified, test.Desc)
+			})
+		}
+
+		b, err := s.srv.Remind(mockCtx, test.AccountID, mockBillID)
+		s.Require().Equal(test.ExpError, err, test.Desc)
+		if err == nil {
+			reminders := []int{}
+			for _, share := range b.Shares {
+				reminders = append(reminders, share.Reminders)
+			}
+			s.Require().Equal(test.ExpReminders, reminders, test.Desc)
+		}
+
+		s.TearDownTest()
+	}
+}
+
+func (s *testSuite) TestCancelBill() {
+	s.mBill.On("LockBill", mockCtx, mockBillID).Return(openBill(), nil).Once()
+	s.mBill.On("UpdateShares", mockCtx, anyShare, anyShare).Return(nil).Once()
+	s.mBill.On("UpdateBill", mockCtx, mock.AnythingOfType("*bill.Bill")).Return(nil).Once()
+
+	b, err := s.srv.CancelBill(mockCtx, mockOrganizerID, mockBillID)
+	s.Require().NoError(err)
+	s.Require().Equal(mdBill.Status_CANCELED, b.Status)
+	s.Require().Equal(mdBill.ShareStatus_PAID, b.Shares[0].Status)
+	s.Require().Equal(mdBill.ShareStatus_CANCELED, b.Shares[1].Status)
+	s.Require().Equal(mdBill.ShareStatus_CANCELED, b.Shares[2].Status)
+
+	s.SetupTest()
+	s.mBill.On("LockBill", mockCtx, mockBillID).Return(openBill(), nil).Once()
+	_, err = s.srv.CancelBill(mockCtx, mockPayerID1, mockBillID)
+	s.Require().Equal(bill.ErrBillNotExist, err)
+}
+
+func TestSuite(t *testing.T) {
+	suite.Run(t, new(testSuite))
+}
@@ -0,0 +1,71 @@
+package bill
+
+import (
+	mBill "github.com/n3k0fi5t/wallet/app/models/bill"
+	"github.com/n3k0fi5t/wallet/app/money"
+)
+
+const (
+	// totalBasisPoints is 100 percent
+	totalBasisPoints = 10000
+)
+
+// split allocates the total to participants in order. Leftover minor units go one each to
+// participants with the largest remainders, ties go to the earlier participant.
+func split(total money.Money, splitType mBill.SplitType, participants []*mBill.Participant) ([]money.Money, error) {
+	var amounts []money.Money
+
+	switch splitType {
+	case mBill.SplitType_EQUAL:
+		parts, err := total.Split(len(participants))
+		if err != nil {
+			return nil, ErrInvalidShares
+		}
+		amounts = parts
+
+	case mBill.SplitType_EXACT:
+		sum := money.New(0, total.Currency)
+		for _, p := range participants {
+			// Add fails on amounts in another currency than the total
+			next, err := sum.Add(p.Amount)
+			if err != nil || !p.Amount.IsPositive() || next.Amount > total.Amount {
+				return nil, ErrInvalidShares
+			}
+			amounts = append(amounts, p.Amount)
+			sum = next
+		}
+		if sum.Amount != total.Amount {
+			return nil, ErrInvalidShares
+		}
+
+	case mBill.SplitType_PERCENTAGE:
+		sum := int64(0)
+		bps := make([]int64, len(participants))
+		for i, p := range participants {
+			if p.BasisPoints <= 0 || p.BasisPoints > totalBasisPoints-sum {
+				return nil, ErrInvalidShares
+			}
+			sum += p.BasisPoints
+			bps[i] = p.BasisPoints
+		}
+		if sum != totalBasisPoints {
+			return nil, ErrInvalidShares
+		}
+
+		parts, err := total.Allocate(bps...)
+		if err != nil {
+			return nil, ErrInvalidShares
+		}
+		amounts = parts
+
+	default:
+		return nil, ErrInvalidSplitType
+	}
+
+	for _, amount := range amounts {
+		if !amount.IsPositive() {
+			return nil, ErrInvalidShares
+		}
+	}
+	return amounts, nil
+}
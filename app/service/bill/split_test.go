@@ -0,0 +1,160 @@
+package bill
+
+import (
+	"math"
+
+	mdBill "github.com/n3k0fi5t/wallet/app/models/bill"
+	"github.com/n3k0fi5t/wallet/app/money"
+)
+
+func (s *testSuite) TestSplit() {
+	exact := func(amounts ...int64) []*mdBill.Participant {
+		ps := []*mdBill.Participant{}
+		for _, amount := range amounts {
+			ps = append(ps, &mdBill.Participant{Amount: money.New(amount, "USD")})
+		}
+		return ps
+	}
+	percentage := func(bps ...int64) []*mdBill.Participant {
+		ps := []*mdBill.Participant{}
+		for _, bp := range bps {
+			ps = append(ps, &mdBill.Participant{BasisPoints: bp})
+		}
+		return ps
+	}
+
+	tests := []struct {
+		Desc         string
+		Total        int64
+		SplitType    mdBill.SplitType
+		Participants []*mdBill.Participant
+		ExpAmounts   []int64
+		ExpError     error
+	}{
+		{
+			Desc:         "equal, leftover goes to the first participants",
+			Total:        1000,
+			SplitType:    mdBill.SplitType_EQUAL,
+			Participants: exact(0, 0, 0),
+			ExpAmounts:   []int64{334, 333, 333},
+		},
+		{
+			Desc:         "equal, leftover of two units",
+			Total:        11,
+			SplitType:    mdBill.SplitType_EQUAL,
+			Participants: exact(0, 0, 0),
+			ExpAmounts:   []int64{4, 4, 3},
+		},
+		{
+			Desc:         "equal, less units than participants",
+			Total:        2,
+			SplitType:    mdBill.SplitType_EQUAL,
+			Participants: exact(0, 0, 0),
+			ExpError:     ErrInvalidShares,
+		},
+		{
+			Desc:         "exact",
+			Total:        1000,
+			SplitType:    mdBill.SplitType_EXACT,
+			Participants: exact(600, 300, 100),
+			ExpAmounts:   []int64{600, 300, 100},
+		},
+		{
+			Desc:         "exact, not adding up",
+			Total:        1000,
+			SplitType:    mdBill.SplitType_EXACT,
+			Participants: exact(600, 300),
+			ExpError:     ErrInvalidShares,
+		},
+		{
+			Desc:         "exact, over the total",
+			Total:        1000,
+			SplitType:    mdBill.SplitType_EXACT,
+			Participants: exact(600, 500),
+			ExpError:     ErrInvalidShares,
+		},
+		{
+			Desc:         "exact, zero share",
+			Total:        1000,
+			SplitType:    mdBill.SplitType_EXACT,
+			Participants: exact(1000, 0),
+			ExpError:     ErrInvalidShares,
+		},
+		{
+			Desc:         "exact, in another currency",
+			Total:        1000,
+			SplitType:    mdBill.SplitType_EXACT,
+			Participants: []*mdBill.Participant{{Amount: money.New(600, "USD")}, {Amount: money.New(400, "EUR")}},
+			ExpError:     ErrInvalidShares,
+		},
+		{
+			Desc:         "percentage, leftover goes to the largest remainder",
+			Total:        100,
+			SplitType:    mdBill.SplitType_PERCENTAGE,
+			Participants: percentage(3333, 3333, 3334),
+			ExpAmounts:   []int64{33, 33, 34},
+		},
+		{
+			Desc:         "percentage, ties go to the earlier participant",
+			Total:        1000,
+			SplitType:    mdBill.SplitType_PERCENTAGE,
+			Participants: percentage(2500, 2500, 2500, 2500),
+			ExpAmounts:   []int64{250, 250, 250, 250},
+		},
+		{
+			Desc:         "percentage, equal remainders",
+			Total:        10,
+			SplitType:    mdBill.SplitType_PERCENTAGE,
+			Participants: percentage(3333, 3333, 3334),
+			ExpAmounts:   []int64{3, 3, 4},
+		},
+		{
+			Desc:         "percentage, remainders tie",
+			Total:        5,
+			SplitType:    mdBill.SplitType_PERCENTAGE,
+			Participants: percentage(5000, 5000),
+			ExpAmounts:   []int64{3, 2},
+		},
+		{
+			Desc:         "percentage, huge total does not overflow",
+			Total:        math.MaxInt64,
+			SplitType:    mdBill.SplitType_PERCENTAGE,
+			Participants: percentage(5000, 5000),
+			ExpAmounts:   []int64{math.MaxInt64/2 + 1, math.MaxInt64 / 2},
+		},
+		{
+			Desc:         "percentage, not adding up",
+			Total:        1000,
+			SplitType:    mdBill.SplitType_PERCENTAGE,
+			Participants: percentage(5000, 4000),
+			ExpError:     ErrInvalidShares,
+		},
+		{
+			Desc:         "unknown split type",
+			Total:        1000,
+			SplitType:    "RANDOM",
+			Participants: exact(0),
+			ExpError:     ErrInvalidSplitType,
+		},
+	}
+
+	for _, test := range tests {
+		shares, err := split(money.New(test.Total, "USD"), test.SplitType, test.Participants)
+		s.Require().Equal(test.ExpError, err, test.Desc)
+
+		var amounts []int64
+		for _, share := range shares {
+			s.Require().Equal("USD", share.Currency, test.Desc)
+			amounts = append(amounts, share.Amount)
+		}
+		s.Require().Equal(test.ExpAmounts, amounts, test.Desc)
+
+		if err == nil && test.Total < math.MaxInt64 {
+			sum := int64(0)
+			for _, amount := range amounts {
+				sum += amount
+			}
+			s.Require().Equal(test.Total, sum, test.Desc)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package escrow
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	mAdmin "github.com/n3k0fi5t/wallet/app/models/admin"
+	mEscrow "github.com/n3k0fi5t/wallet/app/models/escrow"
+	"github.com/n3k0fi5t/wallet/app/money"
+)
+
+var (
+	// ErrInvalidAmount means the amount is not positive
+	ErrInvalidAmount = fmt.Errorf("Invalid amount")
+
+	// ErrInvalidReleaseTime means the release time is past or later than MaxHoldPeriod
+	ErrInvalidReleaseTime = fmt.Errorf("Invalid release time")
+
+	// ErrMemoTooLong means the memo exceeds MaxMemoLength
+	ErrMemoTooLong = fmt.Errorf("Memo too long")
+
+	// ErrSelfEscrow means the buyer and the seller are the same account
+	ErrSelfEscrow = fmt.Errorf("Self escrow")
+
+	// ErrEscrowNotFunded means the escrow is disputed or settled already
+	ErrEscrowNotFunded = fmt.Errorf("Escrow not funded")
+
+	// ErrEscrowNotDisputed means the escrow is not waiting for staff to resolve
+	ErrEscrowNotDisputed = fmt.Errorf("Escrow not disputed")
+
+	// ErrInvalidOutcome means the outcome is neither RELEASE nor REFUND
+	ErrInvalidOutcome = fmt.Errorf("Invalid outcome")
+)
+
+const (
+	// MaxMemoLength is the max length of memo in bytes
+	MaxMemoLength = 255
+
+	// escrowAccountPrefix prefixes IDs of escrow accounts, so they never clash with user accounts
+	escrowAccountPrefix = "escrow-"
+)
+
+// Config controls hold periods and the auto-release of escrows
+type Config struct {
+	// DefaultHoldPeriod is used when the buyer does not set the release time
+	DefaultHoldPeriod time.Duration
+	MaxHoldPeriod     time.Duration
+	PollInterval      time.Duration
+	BatchSize         int
+}
+
+// DefaultConfig releases escrows two weeks after funding unless set otherwise
+var DefaultConfig = Config{
+	DefaultHoldPeriod: 14 * 24 * time.Hour,
+	MaxHoldPeriod:     90 * 24 * time.Hour,
+	PollInterval:      time.Minute,
+	BatchSize:         50,
+}
+
+type Service interface {
+	// CreateEscrow moves the amount from the buyer to a new escrow account, zero releaseAtMs for DefaultHoldPeriod.
+	// The amount is in the currency of the buyer and the seller
+	CreateEscrow(ctx context.Context, buyerID, sellerID string, amount money.Money, memo string, releaseAtMs int64) (*mEscrow.Escrow, error)
+
+	// GetEscrow get an escrow the account buys or sells in
+	GetEscrow(ctx context.Context, accountID, escrowID string) (*mEscrow.Escrow, error)
+
+	// ListEscrows list escrows the account buys or sells in, newest first
+	ListEscrows(ctx context.Context, accountID string, role mEscrow.Role, status mEscrow.Status, offset, limit int) ([]*mEscrow.Escrow, error)
+
+	// Confirm releases the money to the seller, only the buyer can confirm
+	Confirm(ctx context.Context, accountID, escrowID string) (*mEscrow.Escrow, error)
+
+	// Cancel refunds the money to the buyer, only the seller can cancel
+	Cancel(ctx context.Context, accountID, escrowID string) (*mEscrow.Escrow, error)
+
+	// Dispute freezes the escrow account until staff resolve it, either party can dispute
+	Dispute(ctx context.Context, accountID, escrowID string) (*mEscrow.Escrow, error)
+
+	// Resolve releases or refunds a disputed escrow by staff
+	Resolve(ctx context.Context, operator *mAdmin.Operator, escrowID string, outcome mEscrow.Outcome, reason mAdmin.ReasonCode, note string) (*mEscrow.Escrow, error)
+
+	// ReleaseDue releases at most BatchSize funded escrows past their release time, returns the number of escrows released
+	ReleaseDue(ctx context.Context) (int, error)
+
+	// Run releases due escrows every PollInterval until ctx is done
+	Run(ctx context.Context)
+}
@@ -0,0 +1,356 @@
+package escrow
+
+import (
+	"context"
+	"time"
+
+	mAdmin "github.com/n3k0fi5t/wallet/app/models/admin"
+	mBank "github.com/n3k0fi5t/wallet/app/models/bank"
+	mEscrow "github.com/n3k0fi5t/wallet/app/models/escrow"
+	"github.com/n3k0fi5t/wallet/app/money"
+	"github.com/n3k0fi5t/wallet/app/repository/admin"
+	"github.com/n3k0fi5t/wallet/app/repository/bank"
+	"github.com/n3k0fi5t/wallet/app/repository/escrow"
+	aSrv "github.com/n3k0fi5t/wallet/app/service/admin"
+	"github.com/n3k0fi5t/wallet/app/service/wallet"
+	"github.com/n3k0fi5t/wallet/app/util"
+	"github.com/n3k0fi5t/wallet/common/logger"
+	"github.com/n3k0fi5t/wallet/common/sql"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	timeNowMs = util.TimeNowMs
+	getUUID   = util.GetUUIDv4
+)
+
+func NewEscrow(t sql.Transactor, b bank.Bank, e escrow.Escrow, a admin.Admin, w wallet.Service, cfg Config) Service {
+	return &impl{
+		transactor: t,
+		bank:       b,
+		escrow:     e,
+		admin:      a,
+		walletSrv:  w,
+		cfg:        cfg,
+	}
+}
+
+type impl struct {
+	transactor sql.Transactor
+	bank       bank.Bank
+	escrow     escrow.Escrow
+	admin      admin.Admin
+	walletSrv  wallet.Service
+	cfg        Config
+}
+
+func (im *impl) CreateEscrow(ctx context.Context, buyerID, sellerID string, amount money.Money, memo string, releaseAtMs int64) (*mEscrow.Escrow, error) {
+	nowMs := timeNowMs()
+	if releaseAtMs == 0 {
+		releaseAtMs = nowMs + im.cfg.DefaultHoldPeriod.Milliseconds()
+	}
+
+	if !amount.IsPositive() {
+		return nil, ErrInvalidAmount
+	} else if releaseAtMs <= nowMs || releaseAtMs > nowMs+im.cfg.MaxHoldPeriod.Milliseconds() {
+		return nil, ErrInvalidReleaseTime
+	} else if len(memo) > MaxMemoLength {
+		return nil, ErrMemoTooLong
+	} else if buyerID == sellerID {
+		return nil, ErrSelfEscrow
+	}
+
+	// the escrow account holds the currency of the buyer
+	buyer, err := im.walletSrv.GetAccount(ctx, buyerID)
+	if err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("walletSrv.GetAccount failed in CreateEscrow")
+		return nil, err
+	} else if amount.Currency != buyer.Currency() {
+		return nil, bank.ErrCurrencyMismatch
+	}
+
+	// only users sell, the money of an escrow never goes to another system account, and it is paid out as it is
+	seller, err := im.walletSrv.GetAccount(ctx, sellerID)
+	if err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("walletSrv.GetAccount failed in CreateEscrow")
+		return nil, err
+	} else if seller.Currency() != buyer.Currency() {
+		return nil, bank.ErrCurrencyMismatch
+	}
+
+	escrowID, err := getUUID()
+	if err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("GetUUIDv4 failed in CreateEscrow")
+		return nil, err
+	}
+
+	e := &mEscrow.Escrow{
+		EscrowID:    escrowID,
+		AccountID:   escrowAccountPrefix + escrowID,
+		BuyerID:     buyerID,
+		SellerID:    sellerID,
+		Amount:      amount,
+		Memo:        memo,
+		Status:      mEscrow.Status_FUNDED,
+		ReleaseAtMs: releaseAtMs,
+		CreatedMs:   nowMs,
+		UpdatedMs:   nowMs,
+	}
+
+	// the escrow account, its funding and the escrow commit together
+	if err := im.transactor.Transact(ctx, func(ctx context.Context) error {
+		if err := im.bank.CreateAccount(ctx, &mBank.Account{
+			AccountID: e.AccountID,
+			Status:    mBank.AccountStatus_ACTIVE,
+			Type:      mBank.AccountType_ESCROW,
+			Balance:   money.New(0, buyer.Currency()),
+		}); err != nil {
+			logger.FromContext(ctx).WithField("err", err).Error("bank.CreateAccount failed in CreateEscrow")
+			return err
+		}
+
+		// funding the escrow pays out of the buyer, which the approval policy of the buyer may require approvals for.
+		// It is checked in the transaction of the funding, so concurrent payments can not both pass it
+		if err := im.walletSrv.CheckPolicy(ctx, buyerID, amount); err != nil {
+			return err
+		}
+
+		tradeID, err := im.bank.Trade(ctx, &mBank.Dealing{
+			FromAccountID: buyerID,
+			ToAccountID:   e.AccountID,
+			Amount:        amount,
+		})
+		if err != nil {
+			logger.FromContext(ctx).WithField("err", err).Error("bank.Trade failed in CreateEscrow")
+			return err
+		}
+		e.FundTradeID = tradeID
+
+		if err := im.escrow.CreateEscrow(ctx, e); err != nil {
+			logger.FromContext(ctx).WithField("err", err).Error("escrow.CreateEscrow failed")
+			return err
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
+// participates tells whether the account buys or sells in the escrow
+func participates(e *mEscrow.Escrow, accountID string) bool {
+	return e.BuyerID == accountID || e.SellerID == accountID
+}
+
+func (im *impl) GetEscrow(ctx context.Context, accountID, escrowID string) (*mEscrow.Escrow, error) {
+	e, err := im.escrow.GetEscrow(ctx, escrowID)
+	if err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("escrow.GetEscrow failed")
+		return nil, err
+	}
+
+	// escrows of others look not exist, so escrow IDs can not be probed
+	if !participates(e, accountID) {
+		return nil, escrow.ErrEscrowNotExist
+	}
+	return e, nil
+}
+
+func (im *impl) ListEscrows(ctx context.Context, accountID string, role mEscrow.Role, status mEscrow.Status, offset, limit int) ([]*mEscrow.Escrow, error) {
+	escrows, err := im.escrow.ListEscrows(ctx, accountID, role, status, offset, limit)
+	if err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("escrow.ListEscrows failed")
+		return nil, err
+	}
+	return escrows, nil
+}
+
+// change locks the escrow and applies f to it, changes of the same escrow take turns
+func (im *impl) change(ctx context.Context, escrowID string, f func(context.Context, *mEscrow.Escrow) error) (*mEscrow.Escrow, error) {
+	var e *mEscrow.Escrow
+	if err := im.transactor.Transact(ctx, func(ctx context.Context) error {
+		locked, err := im.escrow.LockEscrow(ctx, escrowID)
+		if err != nil {
+			logger.FromContext(ctx).WithField("err", err).Error("escrow.LockEscrow failed")
+			return err
+		}
+
+		if err := f(ctx, locked); err != nil {
+			return err
+		}
+		e = locked
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
+// settle pays the escrow account out to the account and closes the escrow with status
+func (im *impl) settle(ctx context.Context, e *mEscrow.Escrow, toAccountID string, status mEscrow.Status) error {
+	tradeID, err := im.bank.Trade(ctx, &mBank.Dealing{
+		FromAccountID: e.AccountID,
+		ToAccountID:   toAccountID,
+		Amount:        e.Amount,
+	})
+	if err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("bank.Trade failed in settle")
+		return err
+	}
+
+	e.Status = status
+	e.SettleTradeID = tradeID
+	e.UpdatedMs = timeNowMs()
+	if err := im.escrow.UpdateEscrow(ctx, e); err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("escrow.UpdateEscrow failed in settle")
+		return err
+	}
+	return nil
+}
+
+func (im *impl) Confirm(ctx context.Context, accountID, escrowID string) (*mEscrow.Escrow, error) {
+	return im.change(ctx, escrowID, func(ctx context.Context, e *mEscrow.Escrow) error {
+		if e.BuyerID != accountID {
+			return escrow.ErrEscrowNotExist
+		} else if e.Status != mEscrow.Status_FUNDED {
+			return ErrEscrowNotFunded
+		}
+		return im.settle(ctx, e, e.SellerID, mEscrow.Status_RELEASED)
+	})
+}
+
+func (im *impl) Cancel(ctx context.Context, accountID, escrowID string) (*mEscrow.Escrow, error) {
+	return im.change(ctx, escrowID, func(ctx context.Context, e *mEscrow.Escrow) error {
+		if e.SellerID != accountID {
+			return escrow.ErrEscrowNotExist
+		} else if e.Status != mEscrow.Status_FUNDED {
+			return ErrEscrowNotFunded
+		}
+		return im.settle(ctx, e, e.BuyerID, mEscrow.Status_REFUNDED)
+	})
+}
+
+func (im *impl) Dispute(ctx context.Context, accountID, escrowID string) (*mEscrow.Escrow, error) {
+	return im.change(ctx, escrowID, func(ctx context.Context, e *mEscrow.Escrow) error {
+		if !participates(e, accountID) {
+			return escrow.ErrEscrowNotExist
+		} else if e.Status != mEscrow.Status_FUNDED {
+			return ErrEscrowNotFunded
+		}
+
+		// nothing pays out of a frozen account, until staff resolve the dispute
+		if err := im.bank.SetAccountStatus(ctx, e.AccountID, mBank.AccountStatus_FROZEN); err != nil {
+			logger.FromContext(ctx).WithField("err", err).Error("bank.SetAccountStatus failed in Dispute")
+			return err
+		}
+
+		e.Status = mEscrow.Status_DISPUTED
+		e.DisputedBy = accountID
+		e.UpdatedMs = timeNowMs()
+		if err := im.escrow.UpdateEscrow(ctx, e); err != nil {
+			logger.FromContext(ctx).WithField("err", err).Error("escrow.UpdateEscrow failed in Dispute")
+			return err
+		}
+		return nil
+	})
+}
+
+func (im *impl) Resolve(ctx context.Context, operator *mAdmin.Operator, escrowID string, outcome mEscrow.Outcome, reason mAdmin.ReasonCode, note string) (*mEscrow.Escrow, error) {
+	if operator == nil || !operator.Role.Can(mAdmin.Permission_RESOLVE_DISPUTE) {
+		return nil, aSrv.ErrPermissionDenied
+	} else if !reason.IsValid() {
+		return nil, aSrv.ErrInvalidReasonCode
+	} else if !outcome.IsValid() {
+		return nil, ErrInvalidOutcome
+	}
+
+	return im.change(ctx, escrowID, func(ctx context.Context, e *mEscrow.Escrow) error {
+		if e.Status != mEscrow.Status_DISPUTED {
+			return ErrEscrowNotDisputed
+		}
+
+		if err := im.bank.SetAccountStatus(ctx, e.AccountID, mBank.AccountStatus_ACTIVE); err != nil {
+			logger.FromContext(ctx).WithField("err", err).Error("bank.SetAccountStatus failed in Resolve")
+			return err
+		}
+
+		toAccountID, status := e.SellerID, mEscrow.Status_RELEASED
+		if outcome == mEscrow.Outcome_REFUND {
+			toAccountID, status = e.BuyerID, mEscrow.Status_REFUNDED
+		}
+		if err := im.settle(ctx, e, toAccountID, status); err != nil {
+			return err
+		}
+
+		action := &mAdmin.Action{
+			OperatorID:  operator.OperatorID,
+			Role:        operator.Role,
+			Action:      mAdmin.ActionType_RESOLVE_ESCROW,
+			AccountID:   e.AccountID,
+			TradeID:     e.SettleTradeID,
+			Amount:      &e.Amount,
+			ReasonCode:  reason,
+			Note:        note,
+			TimestampMs: e.UpdatedMs,
+		}
+		if err := im.admin.RecordAction(ctx, action); err != nil {
+			logger.FromContext(ctx).WithFields(logrus.Fields{
+				"err":    err,
+				"action": action,
+			}).Error("admin.RecordAction failed in Resolve")
+			return err
+		}
+		return nil
+	})
+}
+
+func (im *impl) ReleaseDue(ctx context.Context) (int, error) {
+	nowMs := timeNowMs()
+	escrows, err := im.escrow.ListDue(ctx, nowMs, im.cfg.BatchSize)
+	if err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("escrow.ListDue failed in ReleaseDue")
+		return 0, err
+	}
+
+	released := 0
+	for _, due := range escrows {
+		// the escrow may be confirmed, canceled or disputed since listed
+		if _, err := im.change(ctx, due.EscrowID, func(ctx context.Context, e *mEscrow.Escrow) error {
+			if e.Status != mEscrow.Status_FUNDED || e.ReleaseAtMs > nowMs {
+				return nil
+			}
+			if err := im.settle(ctx, e, e.SellerID, mEscrow.Status_RELEASED); err != nil {
+				return err
+			}
+			released++
+			return nil
+		}); err != nil {
+			logger.FromContext(ctx).WithFields(logrus.Fields{
+				"err":      err,
+				"escrowID": due.EscrowID,
+			}).Error("release failed in ReleaseDue")
+		}
+	}
+	return released, nil
+}
+
+func (im *impl) Run(ctx context.Context) {
+	ticker := time.NewTicker(im.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		n, err := im.ReleaseDue(ctx)
+		// keep draining without waiting while there is a backlog
+		if err == nil && n == im.cfg.BatchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,468 @@
+package escrow
+
+import (
+	"context"
+	"testing"
+
+	mdAdmin "github.com/n3k0fi5t/wallet/app/models/admin"
+	mdBank "github.com/n3k0fi5t/wallet/app/models/bank"
+	mdEscrow "github.com/n3k0fi5t/wallet/app/models/escrow"
+	"github.com/n3k0fi5t/wallet/app/money"
+	mockAdmin "github.com/n3k0fi5t/wallet/app/repository/admin/mocks"
+	"github.com/n3k0fi5t/wallet/app/repository/bank"
+	mockBank "github.com/n3k0fi5t/wallet/app/repository/bank/mocks"
+	"github.com/n3k0fi5t/wallet/app/repository/escrow"
+	mockEscrow "github.com/n3k0fi5t/wallet/app/repository/escrow/mocks"
+	aSrv "github.com/n3k0fi5t/wallet/app/service/admin"
+	"github.com/n3k0fi5t/wallet/app/service/wallet"
+	mockWallet "github.com/n3k0fi5t/wallet/app/service/wallet/mocks"
+	"github.com/n3k0fi5t/wallet/common/sql/sqltest"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+var (
+	mockCtx        = context.Background()
+	mockBuyerID    = "n3k0fi5t"
+	mockSellerID   = "deadbeef"
+	mockStrangerID = "badc0ffee"
+	mockEscrowID   = "935f871a-660f-4f19-801e-916c04bb0324"
+	mockAccountID  = "escrow-935f871a-660f-4f19-801e-916c04bb0324"
+	mockFundID     = "a89b7b78-b9c1-4129-8cff-380bf53f3a49"
+	mockTradeID    = "5d2c9a4e-3f61-4a8e-9b0c-7e1f2d3c4b5a"
+	mockTimeMs     = int64(1650000000000)
+	mockOperator   = &mdAdmin.Operator{OperatorID: "kronii", Role: mdAdmin.Role_OPERATOR}
+	escrowAmount   = money.New(500, "EUR")
+
+	anyEscrow = mock.AnythingOfType("*escrow.Escrow")
+)
+
+// eurWallet is a wallet in the currency of the escrows
+func eurWallet(accountID string) *mdBank.Account {
+	return &mdBank.Account{AccountID: accountID, Balance: money.New(0, "EUR")}
+}
+
+// fundedEscrow parks 500 of the buyer until the default release time
+func fundedEscrow() *mdEscrow.Escrow {
+	return &mdEscrow.Escrow{
+		EscrowID:    mockEscrowID,
+		AccountID:   mockAccountID,
+		BuyerID:     mockBuyerID,
+		SellerID:    mockSellerID,
+		Amount:      money.New(500, "EUR"),
+		Memo:        "vintage camera",
+		Status:      mdEscrow.Status_FUNDED,
+		FundTradeID: mockFundID,
+		ReleaseAtMs: mockTimeMs + DefaultConfig.DefaultHoldPeriod.Milliseconds(),
+		CreatedMs:   mockTimeMs,
+		UpdatedMs:   mockTimeMs,
+	}
+}
+
+func disputedEscrow() *mdEscrow.Escrow {
+	e := fundedEscrow()
+	e.Status = mdEscrow.Status_DISPUTED
+	e.DisputedBy = mockBuyerID
+	return e
+}
+
+// payout is the dealing paying the escrow account out to the account
+func payout(toAccountID string) *mdBank.Dealing {
+	return &mdBank.Dealing{
+		FromAccountID: mockAccountID,
+		ToAccountID:   toAccountID,
+		Amount:        money.New(500, "EUR"),
+	}
+}
+
+type testSuite struct {
+	suite.Suite
+	srv     Service
+	mBank   *mockBank.Bank
+	mEscrow *mockEscrow.Escrow
+	mAdmin  *mockAdmin.Admin
+	mWallet *mockWallet.Service
+}
+
+func (s *testSuite) SetupSuite() {
+	timeNowMs = func() int64 { return mockTimeMs }
+	getUUID = func() (string, error) { return mockEscrowID, nil }
+}
+
+func (s *testSuite) TearDownSuite() {
+}
+
+func (s *testSuite) SetupTest() {
+	s.mBank = &mockBank.Bank{}
+	s.mEscrow = &mockEscrow.Escrow{}
+	s.mAdmin = &mockAdmin.Admin{}
+	s.mWallet = &mockWallet.Service{}
+	s.srv = NewEscrow(sqltest.Transactor{}, s.mBank, s.mEscrow, s.mAdmin, s.mWallet, DefaultConfig)
+}
+
+func (s *testSuite) TearDownTest() {
+	s.mBank.AssertExpectations(s.T())
+	s.mEscrow.AssertExpectations(s.T())
+	s.mAdmin.AssertExpectations(s.T())
+	s.mWallet.AssertExpectations(s.T())
+}
+
+func (s *testSuite) TestCreateEscrow() {
+	tests := []struct {
+		Desc        string
+		SellerID    string
+		Amount      money.Money
+		ReleaseAtMs int64
+		ExpEscrow   *mdEscrow.Escrow
+		ExpError    error
+		setup       func()
+	}{
+		{
+			Desc:      "normal Path, default release time",
+			SellerID:  mockSellerID,
+			Amount:    money.New(500, "EUR"),
+			ExpEscrow: fundedEscrow(),
+			setup: func() {
+				s.mWallet.On("GetAccount", mockCtx, mockBuyerID).Return(eurWallet(mockBuyerID), nil).Once()
+				s.mWallet.On("GetAccount", mockCtx, mockSellerID).Return(eurWallet(mockSellerID), nil).Once()
+				s.mWallet.On("CheckPolicy", mockCtx, mockBuyerID, money.New(500, "EUR")).Return(nil).Once()
+				s.mBank.On("CreateAccount", mockCtx, &mdBank.Account{AccountID: mockAccountID, Type: mdBank.AccountType_ESCROW, Balance: money.New(0, "EUR")}).Return(nil).Once()
+				s.mBank.On("Trade", mockCtx, &mdBank.Dealing{FromAccountID: mockBuyerID, ToAccountID: mockAccountID, Amount: money.New(500, "EUR")}).Return(mockFundID, nil).Once()
+				s.mEscrow.On("CreateEscrow", mockCtx, fundedEscrow()).Return(nil).Once()
+			},
+		},
+		{
+			Desc:     "bad Path, zero amount",
+			SellerID: mockSellerID,
+			ExpError: ErrInvalidAmount,
+		},
+		{
+			Desc:        "bad Path, release time past",
+			SellerID:    mockSellerID,
+			Amount:      money.New(500, "EUR"),
+			ReleaseAtMs: mockTimeMs,
+			ExpError:    ErrInvalidReleaseTime,
+		},
+		{
+			Desc:        "bad Path, release time too late",
+			SellerID:    mockSellerID,
+			Amount:      money.New(500, "EUR"),
+			ReleaseAtMs: mockTimeMs + DefaultConfig.MaxHoldPeriod.Milliseconds() + 1,
+			ExpError:    ErrInvalidReleaseTime,
+		},
+		{
+			Desc:     "bad Path, buy from self",
+			SellerID: mockBuyerID,
+			Amount:   money.New(500, "EUR"),
+			ExpError: ErrSelfEscrow,
+		},
+		{
+			Desc:     "bad Path, amount of another currency",
+			SellerID: mockSellerID,
+			Amount:   money.New(500, "USD"),
+			ExpError: bank.ErrCurrencyMismatch,
+			setup: func() {
+				s.mWallet.On("GetAccount", mockCtx, mockBuyerID).Return(eurWallet(mockBuyerID), nil).Once()
+			},
+		},
+		{
+			Desc:     "bad Path, seller not exist",
+			SellerID: mockStrangerID,
+			Amount:   money.New(500, "EUR"),
+			ExpError: bank.ErrAccountNotExist,
+			setup: func() {
+				s.mWallet.On("GetAccount", mockCtx, mockBuyerID).Return(eurWallet(mockBuyerID), nil).Once()
+				s.mWallet.On("GetAccount", mockCtx, mockStrangerID).Return((*mdBank.Account)(nil), bank.ErrAccountNotExist).Once()
+			},
+		},
+		{
+			Desc:     "bad Path, seller of another currency",
+			SellerID: mockSellerID,
+			Amount:   money.New(500, "EUR"),
+			ExpError: bank.ErrCurrencyMismatch,
+			setup: func() {
+				s.mWallet.On("GetAccount", mockCtx, mockBuyerID).Return(eurWallet(mockBuyerID), nil).Once()
+				s.mWallet.On("GetAccount", mockCtx, mockSellerID).Return(&mdBank.Account{AccountID: mockSellerID, Balance: money.New(0, "USD")}, nil).Once()
+			},
+		},
+		{
+			Desc:     "bad Path, approval required",
+			SellerID: mockSellerID,
+			Amount:   money.New(500, "EUR"),
+			ExpError: wallet.ErrApprovalRequired,
+			setup: func() {
+				s.mWallet.On("GetAccount", mockCtx, mockBuyerID).Return(eurWallet(mockBuyerID), nil).Once()
+				s.mWallet.On("GetAccount", mockCtx, mockSellerID).Return(eurWallet(mockSellerID), nil).Once()
+				s.mBank.On("CreateAccount", mockCtx, mock.AnythingOfType("*bank.Account")).Return(nil).Once()
+				s.mWallet.On("CheckPolicy", mockCtx, mockBuyerID, money.New(500, "EUR")).Return(wallet.ErrApprovalRequired).Once()
+			},
+		},
+		{
+			Desc:     "bad Path, balance not enough",
+			SellerID: mockSellerID,
+			Amount:   money.New(500, "EUR"),
+			ExpError: bank.ErrBalanceNotEnough,
+			setup: func() {
+				s.mWallet.On("GetAccount", mockCtx, mockBuyerID).Return(eurWallet(mockBuyerID), nil).Once()
+				s.mWallet.On("GetAccount", mockCtx, mockSellerID).Return(eurWallet(mockSellerID), nil).Once()
+				s.mWallet.On("CheckPolicy", mockCtx, mockBuyerID, money.New(500, "EUR")).Return(nil).Once()
+				s.mBank.On("CreateAccount", mockCtx, mock.AnythingOfType("*bank.Account")).Return(nil).Once()
+				s.mBank.On("Trade", mockCtx, mock.AnythingOfType("*bank.Dealing")).Return("", bank.ErrBalanceNotEnough).Once()
+			},
+		},
+	}
+
+	for _, test := range tests {
+		s.SetupTest()
+		if test.setup != nil {
+			test.setup()
+		}
+
+		e, err := s.srv.CreateEscrow(mockCtx, mockBuyerID, test.SellerID, test.Amount, "vintage camera", test.ReleaseAtMs)
+		s.Require().Equal(test.ExpEscrow, e, test.Desc)
+		s.Require().Equal(test.ExpError, err, test.Desc)
+
+		s.TearDownTest()
+	}
+}
+
+func (s *testSuite) TestGetEscrow() {
+	tests := []struct {
+		Desc      string
+		AccountID string
+		ExpError  error
+	}{
+		{Desc: "normal Path, buyer", AccountID: mockBuyerID},
+		{Desc: "normal Path, seller", AccountID: mockSellerID},
+		{Desc: "bad Path, stranger", AccountID: mockStrangerID, ExpError: escrow.ErrEscrowNotExist},
+	}
+
+	for _, test := range tests {
+		s.SetupTest()
+		s.mEscrow.On("GetEscrow", mockCtx, mockEscrowID).Return(fundedEscrow(), nil).Once()
+
+		_, err := s.srv.GetEscrow(mockCtx, test.AccountID, mockEscrowID)
+		s.Require().Equal(test.ExpError, err, test.Desc)
+
+		s.TearDownTest()
+	}
+}
+
+func (s *testSuite) TestConfirmAndCancel() {
+	tests := []struct {
+		Desc      string
+		call      func(ctx context.Context, accountID, escrowID string) (*mdEscrow.Escrow, error)
+		AccountID string
+		Locked    *mdEscrow.Escrow
+		ExpStatus mdEscrow.Status
+		ExpError  error
+		setup     func()
+	}{
+		{
+			Desc:      "normal Path, buyer confirms",
+			call:      s.confirm,
+			AccountID: mockBuyerID,
+			Locked:    fundedEscrow(),
+			ExpStatus: mdEscrow.Status_RELEASED,
+			setup: func() {
+				s.mBank.On("Trade", mockCtx, payout(mockSellerID)).Return(mockTradeID, nil).Once()
+				s.mEscrow.On("UpdateEscrow", mockCtx, anyEscrow).Return(nil).Once()
+			},
+		},
+		{
+			Desc:      "normal Path, seller cancels",
+			call:      s.cancel,
+			AccountID: mockSellerID,
+			Locked:    fundedEscrow(),
+			ExpStatus: mdEscrow.Status_REFUNDED,
+			setup: func() {
+				s.mBank.On("Trade", mockCtx, payout(mockBuyerID)).Return(mockTradeID, nil).Once()
+				s.mEscrow.On("UpdateEscrow", mockCtx, anyEscrow).Return(nil).Once()
+			},
+		},
+		{
+			Desc:      "bad Path, seller can not confirm",
+			call:      s.confirm,
+			AccountID: mockSellerID,
+			Locked:    fundedEscrow(),
+			ExpError:  escrow.ErrEscrowNotExist,
+		},
+		{
+			Desc:      "bad Path, buyer can not cancel",
+			call:      s.cancel,
+			AccountID: mockBuyerID,
+			Locked:    fundedEscrow(),
+			ExpError:  escrow.ErrEscrowNotExist,
+		},
+		{
+			Desc:      "bad Path, disputed",
+			call:      s.confirm,
+			AccountID: mockBuyerID,
+			Locked:    disputedEscrow(),
+			ExpError:  ErrEscrowNotFunded,
+		},
+	}
+
+	for _, test := range tests {
+		s.SetupTest()
+		s.mEscrow.On("LockEscrow", mockCtx, mockEscrowID).Return(test.Locked, nil).Once()
+		if test.setup != nil {
+			test.setup()
+		}
+
+		e, err := test.call(mockCtx, test.AccountID, mockEscrowID)
+		s.Require().Equal(test.ExpError, err, test.Desc)
+		if err == nil {
+			s.Require().Equal(test.ExpStatus, e.Status, test.Desc)
+			s.Require().Equal(mockTradeID, e.SettleTradeID, test.Desc)
+		}
+
+		s.TearDownTest()
+	}
+}
+
+// confirm and cancel call the service of the current test, SetupTest replaces it for each test
+func (s *testSuite) confirm(ctx context.Context, accountID, escrowID string) (*mdEscrow.Escrow, error) {
+	return s.srv.Confirm(ctx, accountID, escrowID)
+}
+
+func (s *testSuite) cancel(ctx context.Context, accountID, escrowID string) (*mdEscrow.Escrow, error) {
+	return s.srv.Cancel(ctx, accountID, escrowID)
+}
+
+func (s *testSuite) TestDispute() {
+	s.mEscrow.On("LockEscrow", mockCtx, mockEscrowID).Return(fundedEscrow(), nil).Once()
+	s.mBank.On("SetAccountStatus", mockCtx, mockAccountID, mdBank.AccountStatus_FROZEN).Return(nil).Once()
+	s.mEscrow.On("UpdateEscrow", mockCtx, anyEscrow).Return(nil).Once()
+
+	e, err := s.srv.Dispute(mockCtx, mockSellerID, mockEscrowID)
+	s.Require().NoError(err)
+	s.Require().Equal(mdEscrow.Status_DISPUTED, e.Status)
+	s.Require().Equal(mockSellerID, e.DisputedBy)
+	s.TearDownTest()
+
+	s.SetupTest()
+	s.mEscrow.On("LockEscrow", mockCtx, mockEscrowID).Return(fundedEscrow(), nil).Once()
+	_, err = s.srv.Dispute(mockCtx, mockStrangerID, mockEscrowID)
+	s.Require().Equal(escrow.ErrEscrowNotExist, err)
+	s.TearDownTest()
+
+	s.SetupTest()
+	s.mEscrow.On("LockEscrow", mockCtx, mockEscrowID).Return(disputedEscrow(), nil).Once()
+	_, err = s.srv.Dispute(mockCtx, mockBuyerID, mockEscrowID)
+	s.Require().Equal(ErrEscrowNotFunded, err)
+}
+
+func (s *testSuite) TestResolve() {
+	tests := []struct {
+		Desc      string
+		Operator  *mdAdmin.Operator
+		Outcome   mdEscrow.Outcome
+		Reason    mdAdmin.ReasonCode
+		Locked    *mdEscrow.Escrow
+		ExpStatus mdEscrow.Status
+		ExpError  error
+		setup     func()
+	}{
+		{
+			Desc:      "normal Path, refund to buyer",
+			Operator:  mockOperator,
+			Outcome:   mdEscrow.Outcome_REFUND,
+			Reason:    mdAdmin.ReasonCode_FRAUD,
+			Locked:    disputedEscrow(),
+			ExpStatus: mdEscrow.Status_REFUNDED,
+			setup: func() {
+				s.mBank.On("SetAccountStatus", mockCtx, mockAccountID, mdBank.AccountStatus_ACTIVE).Return(nil).Once()
+				s.mBank.On("Trade", mockCtx, payout(mockBuyerID)).Return(mockTradeID, nil).Once()
+				s.mEscrow.On("UpdateEscrow", mockCtx, anyEscrow).Return(nil).Once()
+				s.mAdmin.On("RecordAction", mockCtx, &mdAdmin.Action{
+					OperatorID:  mockOperator.OperatorID,
+					Role:        mockOperator.Role,
+					Action:      mdAdmin.ActionType_RESOLVE_ESCROW,
+					AccountID:   mockAccountID,
+					TradeID:     mockTradeID,
+					Amount:      &escrowAmount,
+					ReasonCode:  mdAdmin.ReasonCode_FRAUD,
+					Note:        "item never shipped",
+					TimestampMs: mockTimeMs,
+				}).Return(nil).Once()
+			},
+		},
+		{
+			Desc:      "normal Path, release to seller",
+			Operator:  mockOperator,
+			Outcome:   mdEscrow.Outcome_RELEASE,
+			Reason:    mdAdmin.ReasonCode_CORRECTION,
+			Locked:    disputedEscrow(),
+			ExpStatus: mdEscrow.Status_RELEASED,
+			setup: func() {
+				s.mBank.On("SetAccountStatus", mockCtx, mockAccountID, mdBank.AccountStatus_ACTIVE).Return(nil).Once()
+				s.mBank.On("Trade", mockCtx, payout(mockSellerID)).Return(mockTradeID, nil).Once()
+				s.mEscrow.On("UpdateEscrow", mockCtx, anyEscrow).Return(nil).Once()
+				s.mAdmin.On("RecordAction", mockCtx, mock.AnythingOfType("*admin.Action")).Return(nil).Once()
+			},
+		},
+		{
+			Desc:     "bad Path, support can not resolve",
+			Operator: &mdAdmin.Operator{OperatorID: "mori", Role: mdAdmin.Role_SUPPORT},
+			Outcome:  mdEscrow.Outcome_REFUND,
+			Reason:   mdAdmin.ReasonCode_FRAUD,
+			ExpError: aSrv.ErrPermissionDenied,
+		},
+		{
+			Desc:     "bad Path, invalid outcome",
+			Operator: mockOperator,
+			Outcome:  "SPLIT",
+			Reason:   mdAdmin.ReasonCode_FRAUD,
+			ExpError: ErrInvalidOutcome,
+		},
+		{
+			Desc:     "bad Path, not disputed",
+			Operator: mockOperator,
+			Outcome:  mdEscrow.Outcome_REFUND,
+			Reason:   mdAdmin.ReasonCode_FRAUD,
+			Locked:   fundedEscrow(),
+			ExpError: ErrEscrowNotDisputed,
+		},
+	}
+
+	for _, test := range tests {
+		s.SetupTest()
+		if test.Locked != nil {
+			s.mEscrow.On("LockEscrow", mockCtx, mockEscrowID).Return(test.Locked, nil).Once()
+		}
+		if test.setup != nil {
+			test.setup()
+		}
+
+		e, err := s.srv.Resolve(mockCtx, test.Operator, mockEscrowID, test.Outcome, test.Reason, "item never shipped")
+		s.Require().Equal(test.ExpError, err, test.Desc)
+		if err == nil {
+			s.Require().Equal(test.ExpStatus, e.Status, test.Desc)
+		}
+
+		s.TearDownTest()
+	}
+}
+
+func (s *testSuite) TestReleaseDue() {
+	due := fundedEscrow()
+	due.ReleaseAtMs = mockTimeMs
+
+	s.mEscrow.On("ListDue", mockCtx, mockTimeMs, DefaultConfig.BatchSize).Return([]*mdEscrow.Escrow{due, fundedEscrow()}, nil).Once()
+	// the first is still due when locked, the second was disputed since listed
+	dueLocked := fundedEscrow()
+	dueLocked.ReleaseAtMs = mockTimeMs
+	s.mEscrow.On("LockEscrow", mockCtx, mockEscrowID).Return(dueLocked, nil).Once()
+	s.mEscrow.On("LockEscrow", mockCtx, mockEscrowID).Return(disputedEscrow(), nil).Once()
+	s.mBank.On("Trade", mockCtx, payout(mockSellerID)).Return(mockTradeID, nil).Once()
+	s.mEscrow.On("UpdateEscrow", mockCtx, mock.MatchedBy(func(e *mdEscrow.Escrow) bool { return e.Status == mdEscrow.Status_RELEASED })).Return(nil).Once()
+
+	n, err := s.srv.ReleaseDue(mockCtx)
+	s.Require().NoError(err)
+	s.Require().Equal(1, n)
+}
+
+func TestSuite(t *testing.T) {
+	suite.Run(t, new(testSuite))
+}
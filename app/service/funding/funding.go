@@ -0,0 +1,45 @@
+package funding
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	mBank "github.com/n3k0fi5t/wallet/app/models/bank"
+	mFunding "github.com/n3k0fi5t/wallet/app/models/funding"
+	"github.com/n3k0fi5t/wallet/app/money"
+)
+
+var (
+	// ErrInvalidAmount means the amount is not positive
+	ErrInvalidAmount = fmt.Errorf("Invalid amount")
+
+	// ErrUnknownRail means no adapter of the rail is configured, the direct rail is the instant deposit and withdraw
+	ErrUnknownRail = fmt.Errorf("Unknown rail")
+
+	// ErrRailUnavailable means the rail did not accept the transfer, the transfer failed and a withdrawal is refunded
+	ErrRailUnavailable = fmt.Errorf("Rail unavailable")
+
+	// ErrInvalidTransition means the callback moves the transfer to a status it can not reach from its current one
+	ErrInvalidTransition = fmt.Errorf("Invalid transition")
+)
+
+type Service interface {
+	// Deposit starts a deposit to the wallet over the rail, the wallet is credited when the rail settles it.
+	// Members of any role deposit, in the currency of the wallet
+	Deposit(ctx context.Context, userID, walletID string, rail mBank.Rail, amount money.Money) (*mFunding.Transfer, error)
+
+	// Withdraw takes the amount from the wallet into the clearing account of the rail and starts a withdrawal over the
+	// rail, the amount is refunded if the rail fails or returns it. Only owners withdraw over rails
+	Withdraw(ctx context.Context, userID, walletID string, rail mBank.Rail, amount money.Money) (*mFunding.Transfer, error)
+
+	// GetTransfer get a transfer of a wallet the user is a member of
+	GetTransfer(ctx context.Context, userID, transferID string) (*mFunding.Transfer, error)
+
+	// ListTransfers list transfers of a wallet the user is a member of, newest first
+	ListTransfers(ctx context.Context, userID, walletID string, offset, limit int) ([]*mFunding.Transfer, error)
+
+	// HandleCallback authenticates a status callback of the rail and moves the transfer to the status, booking the
+	// money through the clearing account of the rail. Repeated callbacks of the current status change nothing
+	HandleCallback(ctx context.Context, rail mBank.Rail, header http.Header, body []byte) (*mFunding.Transfer, error)
+}
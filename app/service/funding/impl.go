@@ -0,0 +1,383 @@
+package funding
+
+import (
+	"context"
+	"net/http"
+
+	mBank "github.com/n3k0fi5t/wallet/app/models/bank"
+	mFunding "github.com/n3k0fi5t/wallet/app/models/funding"
+	mWallet "github.com/n3k0fi5t/wallet/app/models/wallet"
+	"github.com/n3k0fi5t/wallet/app/money"
+	"github.com/n3k0fi5t/wallet/app/rail"
+	"github.com/n3k0fi5t/wallet/app/repository/bank"
+	"github.com/n3k0fi5t/wallet/app/repository/funding"
+	"github.com/n3k0fi5t/wallet/app/service/wallet"
+	"github.com/n3k0fi5t/wallet/app/util"
+	"github.com/n3k0fi5t/wallet/common/logger"
+	"github.com/n3k0fi5t/wallet/common/sql"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// submitFailedReason is the failure reason of transfers the rail did not accept
+	submitFailedReason = "not accepted by the rail"
+)
+
+var (
+	timeNowMs = util.TimeNowMs
+	getUUID   = util.GetUUIDv4
+)
+
+func NewFunding(t sql.Transactor, b bank.Bank, f funding.Funding, w wallet.Service, rails map[mBank.Rail]rail.Adapter) Service {
+	return &impl{
+		transactor: t,
+		bank:       b,
+		funding:    f,
+		walletSrv:  w,
+		rails:      rails,
+	}
+}
+
+type impl struct {
+	transactor sql.Transactor
+	bank       bank.Bank
+	funding    funding.Funding
+	walletSrv  wallet.Service
+	rails      map[mBank.Rail]rail.Adapter
+}
+
+func (im *impl) adapter(r mBank.Rail) (rail.Adapter, error) {
+	adapter, ok := im.rails[r]
+	if !ok {
+		return nil, ErrUnknownRail
+	}
+	return adapter, nil
+}
+
+// newTransfer returns an initiated transfer of the wallet in its currency
+func (im *impl) newTransfer(ctx context.Context, userID, walletID string, direction mFunding.Direction, r mBank.Rail, amount money.Money) (*mFunding.Transfer, error) {
+	account, err := im.walletSrv.GetAccount(ctx, walletID)
+	if err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("walletSrv.GetAccount failed in newTransfer")
+		return nil, err
+	} else if amount.Currency != account.Currency() {
+		return nil, bank.ErrCurrencyMismatch
+	}
+
+	transferID, err := getUUID()
+	if err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("GetUUIDv4 failed in newTransfer")
+		return nil, err
+	}
+
+	nowMs := timeNowMs()
+	return &mFunding.Transfer{
+		TransferID: transferID,
+		WalletID:   walletID,
+		UserID:     userID,
+		Direction:  direction,
+		Rail:       r,
+		Amount:     amount,
+		Status:     mFunding.Status_INITIATED,
+		CreatedMs:  nowMs,
+		UpdatedMs:  nowMs,
+	}, nil
+}
+
+func (im *impl) Deposit(ctx context.Context, userID, walletID string, r mBank.Rail, amount money.Money) (*mFunding.Transfer, error) {
+	if !amount.IsPositive() {
+		return nil, ErrInvalidAmount
+	}
+	adapter, err := im.adapter(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := im.walletSrv.Authorize(ctx, userID, walletID); err != nil {
+		return nil, err
+	}
+
+	t, err := im.newTransfer(ctx, userID, walletID, mFunding.Direction_DEPOSIT, r, amount)
+	if err != nil {
+		return nil, err
+	}
+
+	// nothing is booked until the rail accepts the deposit
+	if err := im.funding.CreateTransfer(ctx, t); err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("funding.CreateTransfer failed in Deposit")
+		return nil, err
+	}
+	return im.submit(ctx, adapter, t)
+}
+
+func (im *impl) Withdraw(ctx context.Context, userID, walletID string, r mBank.Rail, amount money.Money) (*mFunding.Transfer, error) {
+	if !amount.IsPositive() {
+		return nil, ErrInvalidAmount
+	}
+	adapter, err := im.adapter(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// the money leaves to the bank account of the owner, spenders pay out within their daily caps instead
+	m, err := im.walletSrv.Authorize(ctx, userID, walletID)
+	if err != nil {
+		return nil, err
+	} else if m.Role != mWallet.Role_OWNER {
+		return nil, wallet.ErrPermissionDenied
+	}
+	if err := im.walletSrv.CheckPolicy(ctx, walletID, amount); err != nil {
+		return nil, err
+	}
+
+	t, err := im.newTransfer(ctx, userID, walletID, mFunding.Direction_WITHDRAWAL, r, amount)
+	if err != nil {
+		return nil, err
+	}
+
+	// the money is held in the clearing account before the rail sees the withdrawal, so it is never spent twice
+	if err := im.transactor.Transact(ctx, func(ctx context.Context) error {
+		tradeID, err := im.book(ctx, t.Source(), t.Clearing(), t.Amount)
+		if err != nil {
+			return err
+		}
+		t.ClearTradeID = tradeID
+
+		if err := im.funding.CreateTransfer(ctx, t); err != nil {
+			logger.FromContext(ctx).WithField("err", err).Error("funding.CreateTransfer failed in Withdraw")
+			return err
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return im.submit(ctx, adapter, t)
+}
+
+// submit sends the initiated transfer to the rail and marks it pending, a transfer the rail does not accept fails
+func (im *impl) submit(ctx context.Context, adapter rail.Adapter, t *mFunding.Transfer) (*mFunding.Transfer, error) {
+	reference, err := adapter.Submit(ctx, t)
+	if err != nil {
+		logger.FromContext(ctx).WithFields(logrus.Fields{
+			"err":        err,
+			"transferID": t.TransferID,
+		}).Error("adapter.Submit failed in submit")
+
+		if _, err := im.change(ctx, t.TransferID, func(ctx context.Context, t *mFunding.Transfer) error {
+			return im.advance(ctx, t, mFunding.Status_FAILED, submitFailedReason)
+		}); err != nil {
+			return nil, err
+		}
+		return nil, ErrRailUnavailable
+	}
+
+	return im.change(ctx, t.TransferID, func(ctx context.Context, t *mFunding.Transfer) error {
+		// the rail may have called back before it returned
+		if t.Status != mFunding.Status_INITIATED {
+			return nil
+		}
+		t.Reference = reference
+		return im.advance(ctx, t, mFunding.Status_PENDING, "")
+	})
+}
+
+// change locks the transfer and applies f to it, changes of the same transfer take turns
+func (im *impl) change(ctx context.Context, transferID string, f func(context.Context, *mFunding.Transfer) error) (*mFunding.Transfer, error) {
+	var t *mFunding.Transfer
+	if err := im.transactor.Transact(ctx, func(ctx context.Context) error {
+		locked, err := im.funding.LockTransfer(ctx, transferID)
+		if err != nil {
+			logger.FromContext(ctx).WithField("err", err).Error("funding.LockTransfer failed")
+			return err
+		}
+
+		if err := f(ctx, locked); err != nil {
+			return err
+		}
+		t = locked
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// book moves amount between accounts of the transfer
+func (im *impl) book(ctx context.Context, from, to string, amount money.Money) (string, error) {
+	tradeID, err := im.bank.Trade(ctx, &mBank.Dealing{
+		FromAccountID: from,
+		ToAccountID:   to,
+		Amount:        amount,
+	})
+	if err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("bank.Trade failed in book")
+		return "", err
+	}
+	return tradeID, nil
+}
+
+// takeBack books the settled money back into the clearing account. The rail took the money of a returned deposit
+// back already, so the wallet pays what it holds and the shortfall comes out of suspense
+func (im *impl) takeBack(ctx context.Context, t *mFunding.Transfer) error {
+	var err error
+	if t.Direction != mFunding.Direction_DEPOSIT {
+		t.ReturnTradeID, err = im.book(ctx, t.Destination(), t.Clearing(), t.Amount)
+		return err
+	}
+
+	paid, err := im.payable(ctx, t)
+	if err != nil {
+		return err
+	}
+	if paid.IsPositive() {
+		if t.ReturnTradeID, err = im.book(ctx, t.Destination(), t.Clearing(), paid); err != nil {
+			return err
+		}
+	}
+	if paid == t.Amount {
+		return nil
+	}
+
+	if t.ShortfallAmount, err = t.Amount.Sub(paid); err != nil {
+		return err
+	}
+	if t.ShortfallTradeID, err = im.book(ctx, mBank.SystemAccountID(mBank.Purpose_SUSPENSE, t.Currency()), t.Clearing(), t.ShortfallAmount); err != nil {
+		return err
+	}
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"transferID": t.TransferID,
+		"walletID":   t.WalletID,
+		"shortfall":  t.ShortfallAmount.String(),
+	}).Warn("returned deposit was spent, the shortfall is in suspense")
+	return nil
+}
+
+// payable is how much of a returned deposit the wallet pays back. It pays from its balance only, the credit line
+// is not drawn to cover a return, and a frozen wallet pays nothing so the return still settles
+func (im *impl) payable(ctx context.Context, t *mFunding.Transfer) (money.Money, error) {
+	nothing := money.New(0, t.Currency())
+	account, err := im.bank.GetAccount(ctx, t.Destination())
+	if err == bank.ErrAccountNotExist {
+		return nothing, nil
+	} else if err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("bank.GetAccount failed in payable")
+		return nothing, err
+	}
+
+	if account.Status != mBank.AccountStatus_ACTIVE || !account.Balance.IsPositive() {
+		return nothing, nil
+	} else if account.Balance.Amount < t.Amount.Amount {
+		return account.Balance, nil
+	}
+	return t.Amount, nil
+}
+
+// advance moves the locked transfer to status and books the money of the step, the clearing account of the rail
+// nets to zero once the transfer ends
+func (im *impl) advance(ctx context.Context, t *mFunding.Transfer, status mFunding.Status, reason string) error {
+	if t.Status == status {
+		return nil
+	} else if !t.Status.CanBecome(status) {
+		return ErrInvalidTransition
+	}
+
+	var err error
+	// the money of a deposit enters the clearing account once the rail accepts it
+	if t.Direction == mFunding.Direction_DEPOSIT && t.Status == mFunding.Status_INITIATED && status != mFunding.Status_FAILED {
+		if t.ClearTradeID, err = im.book(ctx, t.Source(), t.Clearing(), t.Amount); err != nil {
+			return err
+		}
+	}
+
+	switch status {
+	case mFunding.Status_SETTLED:
+		if t.SettleTradeID, err = im.book(ctx, t.Clearing(), t.Destination(), t.Amount); err != nil {
+			return err
+		}
+	case mFunding.Status_FAILED, mFunding.Status_RETURNED:
+		// settled money comes back into the clearing account first, a returned deposit is taken from the wallet
+		if t.Status == mFunding.Status_SETTLED {
+			if err = im.takeBack(ctx, t); err != nil {
+				return err
+			}
+		}
+		if t.ClearTradeID != "" {
+			if t.RefundTradeID, err = im.book(ctx, t.Clearing(), t.Source(), t.Amount); err != nil {
+				return err
+			}
+		}
+		t.FailureReason = reason
+	}
+
+	t.Status = status
+	t.UpdatedMs = timeNowMs()
+	if err := im.funding.UpdateTransfer(ctx, t); err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("funding.UpdateTransfer failed in advance")
+		return err
+	}
+	return nil
+}
+
+// member checks the user is a member of the wallet, wallets of others look not exist
+func (im *impl) member(ctx context.Context, userID, walletID string) error {
+	_, err := im.walletSrv.Authorize(ctx, userID, walletID)
+	return err
+}
+
+func (im *impl) GetTransfer(ctx context.Context, userID, transferID string) (*mFunding.Transfer, error) {
+	t, err := im.funding.GetTransfer(ctx, transferID)
+	if err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("funding.GetTransfer failed")
+		return nil, err
+	}
+
+	// transfers of wallets of others look not exist, so transfer IDs can not be probed
+	if err := im.member(ctx, userID, t.WalletID); err == bank.ErrAccountNotExist {
+		return nil, funding.ErrTransferNotExist
+	} else if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (im *impl) ListTransfers(ctx context.Context, userID, walletID string, offset, limit int) ([]*mFunding.Transfer, error) {
+	if err := im.member(ctx, userID, walletID); err != nil {
+		return nil, err
+	}
+
+	transfers, err := im.funding.ListTransfers(ctx, walletID, offset, limit)
+	if err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("funding.ListTransfers failed")
+		return nil, err
+	}
+	return transfers, nil
+}
+
+func (im *impl) HandleCallback(ctx context.Context, r mBank.Rail, header http.Header, body []byte) (*mFunding.Transfer, error) {
+	adapter, err := im.adapter(r)
+	if err != nil {
+		return nil, err
+	}
+
+	cb, err := adapter.ParseCallback(header, body)
+	if err != nil {
+		logger.FromContext(ctx).WithFields(logrus.Fields{
+			"err":  err,
+			"rail": r,
+		}).Warn("adapter.ParseCallback failed in HandleCallback")
+		return nil, err
+	}
+
+	return im.change(ctx, cb.TransferID, func(ctx context.Context, t *mFunding.Transfer) error {
+		// a rail only moves its own transfers, others look not exist
+		if t.Rail != r || (t.Reference != "" && cb.Reference != "" && t.Reference != cb.Reference) {
+			return funding.ErrTransferNotExist
+		}
+		if t.Reference == "" {
+			t.Reference = cb.Reference
+		}
+		return im.advance(ctx, t, cb.Status, cb.Reason)
+	})
+}
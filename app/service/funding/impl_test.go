@@ -0,0 +1,508 @@
+package funding
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	mdBank "github.com/n3k0fi5t/wallet/app/models/bank"
+	mdFunding "github.com/n3k0fi5t/wallet/app/models/funding"
+	mdWallet "github.com/n3k0fi5t/wallet/app/models/wallet"
+	"github.com/n3k0fi5t/wallet/app/money"
+	"github.com/n3k0fi5t/wallet/app/rail"
+	"github.com/n3k0fi5t/wallet/app/repository/bank"
+	mockBank "github.com/n3k0fi5t/wallet/app/repository/bank/mocks"
+	"github.com/n3k0fi5t/wallet/app/repository/funding"
+	mockFunding "github.com/n3k0fi5t/wallet/app/repository/funding/mocks"
+	"github.com/n3k0fi5t/wallet/app/service/wallet"
+	mockWallet "github.com/n3k0fi5t/wallet/app/service/wallet/mocks"
+	"github.com/n3k0fi5t/wallet/common/sql/sqltest"
+	"github.com/stretchr/testify/suite"
+)
+
+var (
+	mockCtx              = context.Background()
+	mockUserID           = "n3k0fi5t"
+	mockOtherID          = "deadbeef"
+	mockWalletID         = "a89b7b78-b9c1-4129-8cff-380bf53f3a49"
+	mockTransferID       = "935f871a-660f-4f19-801e-916c04bb0324"
+	mockReference        = "sim_5e0e8b3c-1f6a-4c39-9a52-6b4a8f1b0d7e"
+	mockClearTradeID     = "c1b2d7a4-3f2e-4a8b-9d55-0e6f7a8b9c01"
+	mockSettleTradeID    = "7e1f2d3c-4b5a-4a8e-9b0c-5d2c9a4e3f61"
+	mockReturnTradeID    = "0d9c8b7a-6f5e-4d3c-2b1a-0f9e8d7c6b5a"
+	mockRefundTradeID    = "f00dcafe-b9c1-4129-8cff-380bf53f3a49"
+	mockShortfallTradeID = "5a4b3c2d-1e0f-4a9b-8c7d-6e5f4a3b2c1d"
+	mockTimeMs           = int64(1650000000000)
+	mockFundingID        = "sys-funding-simulator-USD"
+	mockClearingID       = "sys-clearing-simulator-USD"
+	mockSuspenseID       = "sys-suspense-USD"
+	mockHeader           = http.Header{}
+	mockBody             = []byte(`{}`)
+)
+
+// fakeAdapter accepts transfers as reference unless err, and parses every callback as callback
+type fakeAdapter struct {
+	reference string
+	err       error
+	callback  *rail.Callback
+}
+
+func (f *fakeAdapter) Submit(ctx context.Context, t *mdFunding.Transfer) (string, error) {
+	return f.reference, f.err
+}
+
+func (f *fakeAdapter) ParseCallback(header http.Header, body []byte) (*rail.Callback, error) {
+	if f.callback == nil {
+		return nil, rail.ErrInvalidSignature
+	}
+	return f.callback, nil
+}
+
+func member(role mdWallet.Role) *mdWallet.Member {
+	return &mdWallet.Member{WalletID: mockWalletID, UserID: mockUserID, Role: role}
+}
+
+// transfer returns a transfer of 100.00 USD over the simulator, with the trades booked before status
+func transfer(direction mdFunding.Direction, status mdFunding.Status) *mdFunding.Transfer {
+	t := &mdFunding.Transfer{
+		TransferID: mockTransferID,
+		WalletID:   mockWalletID,
+		UserID:     mockUserID,
+		Direction:  direction,
+		Rail:       mdBank.Rail_SIMULATOR,
+		Amount:     money.New(10000, "USD"),
+		Status:     status,
+		CreatedMs:  mockTimeMs,
+		UpdatedMs:  mockTimeMs,
+	}
+	if status != mdFunding.Status_INITIATED {
+		t.Reference = mockReference
+	}
+	if direction == mdFunding.Direction_WITHDRAWAL || status != mdFunding.Status_INITIATED {
+		t.ClearTradeID = mockClearTradeID
+	}
+	if status == mdFunding.Status_SETTLED {
+		t.SettleTradeID = mockSettleTradeID
+	}
+	return t
+}
+
+type testSuite struct {
+	suite.Suite
+	srv      Service
+	adapter  *fakeAdapter
+	mBank    *mockBank.Bank
+	mFunding *mockFunding.Funding
+	mWallet  *mockWallet.Service
+}
+
+func (s *testSuite) SetupSuite() {
+	timeNowMs = func() int64 { return mockTimeMs }
+	getUUID = func() (string, error) { return mockTransferID, nil }
+}
+
+func (s *testSuite) TearDownSuite() {
+}
+
+func (s *testSuite) SetupTest() {
+	s.adapter = &fakeAdapter{reference: mockReference}
+	s.mBank = &mockBank.Bank{}
+	s.mFunding = &mockFunding.Funding{}
+	s.mWallet = &mockWallet.Service{}
+	s.srv = NewFunding(sqltest.Transactor{}, s.mBank, s.mFunding, s.mWallet, map[mdBank.Rail]rail.Adapter{
+		mdBank.Rail_SIMULATOR: s.adapter,
+	})
+}
+
+func (s *testSuite) TearDownTest() {
+	s.mBank.AssertExpectations(s.T())
+	s.mFunding.AssertExpectations(s.T())
+	s.mWallet.AssertExpectations(s.T())
+}
+
+func (s *testSuite) expectTrade(from, to, tradeID string) {
+	s.mBank.On("Trade", mockCtx, &mdBank.Dealing{FromAccountID: from, ToAccountID: to, Amount: money.New(10000, "USD")}).Return(tradeID, nil).Once()
+}
+
+func (s *testSuite) expectWallet(role mdWallet.Role) {
+	s.mWallet.On("Authorize", mockCtx, mockUserID, mockWalletID).Return(member(role), nil).Once()
+	s.mWallet.On("GetAccount", mockCtx, mockWalletID).Return(&mdBank.Account{AccountID: mockWalletID, Balance: money.New(0, "USD")}, nil).Once()
+}
+
+func (s *testSuite) TestDeposit() {
+	pending := transfer(mdFunding.Direction_DEPOSIT, mdFunding.Status_PENDING)
+	failed := transfer(mdFunding.Direction_DEPOSIT, mdFunding.Status_INITIATED)
+	failed.Status = mdFunding.Status_FAILED
+	failed.FailureReason = submitFailedReason
+
+	tests := []struct {
+		Desc        string
+		Rail        mdBank.Rail
+		Amount      money.Money
+		ExpTransfer *mdFunding.Transfer
+		ExpError    error
+		setup       func()
+	}{
+		{
+			Desc:        "normal Path, funding to clearing once accepted",
+			Rail:        mdBank.Rail_SIMULATOR,
+			Amount:      money.New(10000, "USD"),
+			ExpTransfer: pending,
+			setup: func() {
+				s.expectWallet(mdWallet.Role_VIEWER)
+				s.mFunding.On("CreateTransfer", mockCtx, transfer(mdFunding.Direction_DEPOSIT, mdFunding.Status_INITIATED)).Return(nil).Once()
+				s.mFunding.On("LockTransfer", mockCtx, mockTransferID).Return(transfer(mdFunding.Direction_DEPOSIT, mdFunding.Status_INITIATED), nil).Once()
+				s.expectTrade(mockFundingID, mockClearingID, mockClearTradeID)
+				s.mFunding.On("UpdateTransfer", mockCtx, pending).Return(nil).Once()
+			},
+		},
+		{
+			Desc:     "bad Path, rail unavailable books nothing",
+			Rail:     mdBank.Rail_SIMULATOR,
+			Amount:   money.New(10000, "USD"),
+			ExpError: ErrRailUnavailable,
+			setup: func() {
+				s.adapter.err = fmt.Errorf("connection refused")
+				s.expectWallet(mdWallet.Role_VIEWER)
+				s.mFunding.On("CreateTransfer", mockCtx, transfer(mdFunding.Direction_DEPOSIT, mdFunding.Status_INITIATED)).Return(nil).Once()
+				s.mFunding.On("LockTransfer", mockCtx, mockTransferID).Return(transfer(mdFunding.Direction_DEPOSIT, mdFunding.Status_INITIATED), nil).Once()
+				s.mFunding.On("UpdateTransfer", mockCtx, failed).Return(nil).Once()
+			},
+		},
+		{
+			Desc:     "bad Path, not a member",
+			Rail:     mdBank.Rail_SIMULATOR,
+			Amount:   money.New(10000, "USD"),
+			ExpError: bank.ErrAccountNotExist,
+			setup: func() {
+				s.mWallet.On("Authorize", mockCtx, mockUserID, mockWalletID).Return(nil, bank.ErrAccountNotExist).Once()
+			},
+		},
+		{
+			Desc:     "bad Path, amount in another currency",
+			Rail:     mdBank.Rail_SIMULATOR,
+			Amount:   money.New(10000, "EUR"),
+			ExpError: bank.ErrCurrencyMismatch,
+			setup: func() {
+				s.expectWallet(mdWallet.Role_VIEWER)
+			},
+		},
+		{
+			Desc:     "bad Path, direct rail is instant",
+			Rail:     mdBank.Rail_DIRECT,
+			Amount:   money.New(10000, "USD"),
+			ExpError: ErrUnknownRail,
+		},
+		{
+			Desc:     "bad Path, zero amount",
+			Rail:     mdBank.Rail_SIMULATOR,
+			ExpError: ErrInvalidAmount,
+		},
+	}
+
+	for _, t := range tests {
+		s.SetupTest()
+		if t.setup != nil {
+			t.setup()
+		}
+
+		transfer, err := s.srv.Deposit(mockCtx, mockUserID, mockWalletID, t.Rail, t.Amount)
+		s.Require().Equal(t.ExpError, err, t.Desc)
+		s.Require().Equal(t.ExpTransfer, transfer, t.Desc)
+		s.TearDownTest()
+	}
+}
+
+func (s *testSuite) TestWithdraw() {
+	initiated := transfer(mdFunding.Direction_WITHDRAWAL, mdFunding.Status_INITIATED)
+	pending := transfer(mdFunding.Direction_WITHDRAWAL, mdFunding.Status_PENDING)
+	failed := transfer(mdFunding.Direction_WITHDRAWAL, mdFunding.Status_INITIATED)
+	failed.Status = mdFunding.Status_FAILED
+	failed.FailureReason = submitFailedReason
+	failed.RefundTradeID = mockRefundTradeID
+
+	tests := []struct {
+		Desc        string
+		ExpTransfer *mdFunding.Transfer
+		ExpError    error
+		setup       func()
+	}{
+		{
+			Desc:        "normal Path, wallet to clearing before submitted",
+			ExpTransfer: pending,
+			setup: func() {
+				s.expectWallet(mdWallet.Role_OWNER)
+				s.mWallet.On("CheckPolicy", mockCtx, mockWalletID, money.New(10000, "USD")).Return(nil).Once()
+				s.expectTrade(mockWalletID, mockClearingID, mockClearTradeID)
+				s.mFunding.On("CreateTransfer", mockCtx, initiated).Return(nil).Once()
+				s.mFunding.On("LockTransfer", mockCtx, mockTransferID).Return(transfer(mdFunding.Direction_WITHDRAWAL, mdFunding.Status_INITIATED), nil).Once()
+				s.mFunding.On("UpdateTransfer", mockCtx, pending).Return(nil).Once()
+			},
+		},
+		{
+			Desc:     "bad Path, rail unavailable refunds the wallet",
+			ExpError: ErrRailUnavailable,
+			setup: func() {
+				s.adapter.err = fmt.Errorf("connection refused")
+				s.expectWallet(mdWallet.Role_OWNER)
+				s.mWallet.On("CheckPolicy", mockCtx, mockWalletID, money.New(10000, "USD")).Return(nil).Once()
+				s.expectTrade(mockWalletID, mockClearingID, mockClearTradeID)
+				s.mFunding.On("CreateTransfer", mockCtx, initiated).Return(nil).Once()
+				s.mFunding.On("LockTransfer", mockCtx, mockTransferID).Return(transfer(mdFunding.Direction_WITHDRAWAL, mdFunding.Status_INITIATED), nil).Once()
+				s.expectTrade(mockClearingID, mockWalletID, mockRefundTradeID)
+				s.mFunding.On("UpdateTransfer", mockCtx, failed).Return(nil).Once()
+			},
+		},
+		{
+			Desc:     "bad Path, balance not enough",
+			ExpError: bank.ErrBalanceNotEnough,
+			setup: func() {
+				s.expectWallet(mdWallet.Role_OWNER)
+				s.mWallet.On("CheckPolicy", mockCtx, mockWalletID, money.New(10000, "USD")).Return(nil).Once()
+				s.mBank.On("Trade", mockCtx, &mdBank.Dealing{FromAccountID: mockWalletID, ToAccountID: mockClearingID, Amount: money.New(10000, "USD")}).Return("", bank.ErrBalanceNotEnough).Once()
+			},
+		},
+		{
+			Desc:     "bad Path, approval required",
+			ExpError: wallet.ErrApprovalRequired,
+			setup: func() {
+				s.mWallet.On("Authorize", mockCtx, mockUserID, mockWalletID).Return(member(mdWallet.Role_OWNER), nil).Once()
+				s.mWallet.On("CheckPolicy", mockCtx, mockWalletID, money.New(10000, "USD")).Return(wallet.ErrApprovalRequired).Once()
+			},
+		},
+		{
+			Desc:     "bad Path, spender",
+			ExpError: wallet.ErrPermissionDenied,
+			setup: func() {
+				s.mWallet.On("Authorize", mockCtx, mockUserID, mockWalletID).Return(member(mdWallet.Role_SPENDER), nil).Once()
+			},
+		},
+	}
+
+	for _, t := range tests {
+		s.SetupTest()
+		if t.setup != nil {
+			t.setup()
+		}
+
+		transfer, err := s.srv.Withdraw(mockCtx, mockUserID, mockWalletID, mdBank.Rail_SIMULATOR, money.New(10000, "USD"))
+		s.Require().Equal(t.ExpError, err, t.Desc)
+		s.Require().Equal(t.ExpTransfer, transfer, t.Desc)
+		s.TearDownTest()
+	}
+}
+
+func (s *testSuite) TestHandleCallback() {
+	tests := []struct {
+		Desc      string
+		Direction mdFunding.Direction
+		Status    mdFunding.Status
+		Callback  *rail.Callback
+		ExpStatus mdFunding.Status
+		ExpError  error
+		setup     func(*mdFunding.Transfer)
+	}{
+		{
+			Desc:      "normal Path, settled deposit credits the wallet",
+			Direction: mdFunding.Direction_DEPOSIT,
+			Status:    mdFunding.Status_PENDING,
+			Callback:  &rail.Callback{TransferID: mockTransferID, Reference: mockReference, Status: mdFunding.Status_SETTLED},
+			ExpStatus: mdFunding.Status_SETTLED,
+			setup: func(exp *mdFunding.Transfer) {
+				s.expectTrade(mockClearingID, mockWalletID, mockSettleTradeID)
+				exp.SettleTradeID = mockSettleTradeID
+			},
+		},
+		{
+			Desc:      "normal Path, failed deposit goes back to funding",
+			Direction: mdFunding.Direction_DEPOSIT,
+			Status:    mdFunding.Status_PENDING,
+			Callback:  &rail.Callback{TransferID: mockTransferID, Reference: mockReference, Status: mdFunding.Status_FAILED, Reason: "declined"},
+			ExpStatus: mdFunding.Status_FAILED,
+			setup: func(exp *mdFunding.Transfer) {
+				s.expectTrade(mockClearingID, mockFundingID, mockRefundTradeID)
+				exp.RefundTradeID = mockRefundTradeID
+				exp.FailureReason = "declined"
+			},
+		},
+		{
+			Desc:      "normal Path, deposit settled before accepted",
+			Direction: mdFunding.Direction_DEPOSIT,
+			Status:    mdFunding.Status_INITIATED,
+			Callback:  &rail.Callback{TransferID: mockTransferID, Reference: mockReference, Status: mdFunding.Status_SETTLED},
+			ExpStatus: mdFunding.Status_SETTLED,
+			setup: func(exp *mdFunding.Transfer) {
+				s.expectTrade(mockFundingID, mockClearingID, mockClearTradeID)
+				s.expectTrade(mockClearingID, mockWalletID, mockSettleTradeID)
+				exp.Reference = mockReference
+				exp.ClearTradeID = mockClearTradeID
+				exp.SettleTradeID = mockSettleTradeID
+			},
+		},
+		{
+			Desc:      "normal Path, returned deposit is taken from the wallet",
+			Direction: mdFunding.Direction_DEPOSIT,
+			Status:    mdFunding.Status_SETTLED,
+			Callback:  &rail.Callback{TransferID: mockTransferID, Reference: mockReference, Status: mdFunding.Status_RETURNED, Reason: "charged back"},
+			ExpStatus: mdFunding.Status_RETURNED,
+			setup: func(exp *mdFunding.Transfer) {
+				s.mBank.On("GetAccount", mockCtx, mockWalletID).Return(&mdBank.Account{AccountID: mockWalletID, Balance: money.New(12000, "USD")}, nil).Once()
+				s.expectTrade(mockWalletID, mockClearingID, mockReturnTradeID)
+				s.expectTrade(mockClearingID, mockFundingID, mockRefundTradeID)
+				exp.ReturnTradeID = mockReturnTradeID
+				exp.RefundTradeID = mockRefundTradeID
+				exp.FailureReason = "charged back"
+			},
+		},
+		{
+			Desc:      "normal Path, returned deposit spent already takes the shortfall from suspense",
+			Direction: mdFunding.Direction_DEPOSIT,
+			Status:    mdFunding.Status_SETTLED,
+			Callback:  &rail.Callback{TransferID: mockTransferID, Reference: mockReference, Status: mdFunding.Status_RETURNED, Reason: "charged back"},
+			ExpStatus: mdFunding.Status_RETURNED,
+			setup: func(exp *mdFunding.Transfer) {
+				// the credit line is not drawn to cover the return
+				s.mBank.On("GetAccount", mockCtx, mockWalletID).Return(&mdBank.Account{AccountID: mockWalletID, Balance: money.New(2500, "USD"), CreditLimit: money.New(1500, "USD")}, nil).Once()
+				s.mBank.On("Trade", mockCtx, &mdBank.Dealing{FromAccountID: mockWalletID, ToAccountID: mockClearingID, Amount: money.New(2500, "USD")}).Return(mockReturnTradeID, nil).Once()
+				s.mBank.On("Trade", mockCtx, &mdBank.Dealing{FromAccountID: mockSuspenseID, ToAccountID: mockClearingID, Amount: money.New(7500, "USD")}).Return(mockShortfallTradeID, nil).Once()
+				s.expectTrade(mockClearingID, mockFundingID, mockRefundTradeID)
+				exp.ReturnTradeID = mockReturnTradeID
+				exp.ShortfallTradeID = mockShortfallTradeID
+				exp.ShortfallAmount = money.New(7500, "USD")
+				exp.RefundTradeID = mockRefundTradeID
+				exp.FailureReason = "charged back"
+			},
+		},
+		{
+			Desc:      "normal Path, returned deposit of an overdrawn wallet is all shortfall",
+			Direction: mdFunding.Direction_DEPOSIT,
+			Status:    mdFunding.Status_SETTLED,
+			Callback:  &rail.Callback{TransferID: mockTransferID, Reference: mockReference, Status: mdFunding.Status_RETURNED},
+			ExpStatus: mdFunding.Status_RETURNED,
+			setup: func(exp *mdFunding.Transfer) {
+				s.mBank.On("GetAccount", mockCtx, mockWalletID).Return(&mdBank.Account{AccountID: mockWalletID, Balance: money.New(-500, "USD"), CreditLimit: money.New(2000, "USD")}, nil).Once()
+				s.expectTrade(mockSuspenseID, mockClearingID, mockShortfallTradeID)
+				s.expectTrade(mockClearingID, mockFundingID, mockRefundTradeID)
+				exp.ShortfallTradeID = mockShortfallTradeID
+				exp.ShortfallAmount = money.New(10000, "USD")
+				exp.RefundTradeID = mockRefundTradeID
+			},
+		},
+		{
+			Desc:      "normal Path, returned deposit of a frozen wallet is all shortfall",
+			Direction: mdFunding.Direction_DEPOSIT,
+			Status:    mdFunding.Status_SETTLED,
+			Callback:  &rail.Callback{TransferID: mockTransferID, Reference: mockReference, Status: mdFunding.Status_RETURNED},
+			ExpStatus: mdFunding.Status_RETURNED,
+			setup: func(exp *mdFunding.Transfer) {
+				s.mBank.On("GetAccount", mockCtx, mockWalletID).Return(&mdBank.Account{AccountID: mockWalletID, Balance: money.New(12000, "USD"), Status: mdBank.AccountStatus_FROZEN}, nil).Once()
+				s.expectTrade(mockSuspenseID, mockClearingID, mockShortfallTradeID)
+				s.expectTrade(mockClearingID, mockFundingID, mockRefundTradeID)
+				exp.ShortfallTradeID = mockShortfallTradeID
+				exp.ShortfallAmount = money.New(10000, "USD")
+				exp.RefundTradeID = mockRefundTradeID
+			},
+		},
+		{
+			Desc:      "normal Path, settled withdrawal leaves to funding",
+			Direction: mdFunding.Direction_WITHDRAWAL,
+			Status:    mdFunding.Status_PENDING,
+			Callback:  &rail.Callback{TransferID: mockTransferID, Reference: mockReference, Status: mdFunding.Status_SETTLED},
+			ExpStatus: mdFunding.Status_SETTLED,
+			setup: func(exp *mdFunding.Transfer) {
+				s.expectTrade(mockClearingID, mockFundingID, mockSettleTradeID)
+				exp.SettleTradeID = mockSettleTradeID
+			},
+		},
+		{
+			Desc:      "normal Path, returned withdrawal refunds the wallet",
+			Direction: mdFunding.Direction_WITHDRAWAL,
+			Status:    mdFunding.Status_SETTLED,
+			Callback:  &rail.Callback{TransferID: mockTransferID, Reference: mockReference, Status: mdFunding.Status_RETURNED},
+			ExpStatus: mdFunding.Status_RETURNED,
+			setup: func(exp *mdFunding.Transfer) {
+				s.expectTrade(mockFundingID, mockClearingID, mockReturnTradeID)
+				s.expectTrade(mockClearingID, mockWalletID, mockRefundTradeID)
+				exp.ReturnTradeID = mockReturnTradeID
+				exp.RefundTradeID = mockRefundTradeID
+			},
+		},
+		{
+			Desc:      "normal Path, repeated callback",
+			Direction: mdFunding.Direction_WITHDRAWAL,
+			Status:    mdFunding.Status_SETTLED,
+			Callback:  &rail.Callback{TransferID: mockTransferID, Reference: mockReference, Status: mdFunding.Status_SETTLED},
+			ExpStatus: mdFunding.Status_SETTLED,
+		},
+		{
+			Desc:      "bad Path, failed after settled",
+			Direction: mdFunding.Direction_DEPOSIT,
+			Status:    mdFunding.Status_SETTLED,
+			Callback:  &rail.Callback{TransferID: mockTransferID, Reference: mockReference, Status: mdFunding.Status_FAILED},
+			ExpError:  ErrInvalidTransition,
+		},
+		{
+			Desc:      "bad Path, reference of another transfer",
+			Direction: mdFunding.Direction_DEPOSIT,
+			Status:    mdFunding.Status_PENDING,
+			Callback:  &rail.Callback{TransferID: mockTransferID, Reference: "sim_other", Status: mdFunding.Status_SETTLED},
+			ExpError:  funding.ErrTransferNotExist,
+		},
+	}
+
+	for _, t := range tests {
+		s.SetupTest()
+		s.adapter.callback = t.Callback
+		s.mFunding.On("LockTransfer", mockCtx, mockTransferID).Return(transfer(t.Direction, t.Status), nil).Once()
+
+		exp := transfer(t.Direction, t.Status)
+		exp.Status = t.ExpStatus
+		if t.setup != nil {
+			t.setup(exp)
+		}
+		if t.ExpError != nil {
+			exp = nil
+		} else if t.ExpStatus != t.Status {
+			s.mFunding.On("UpdateTransfer", mockCtx, exp).Return(nil).Once()
+		}
+
+		transfer, err := s.srv.HandleCallback(mockCtx, mdBank.Rail_SIMULATOR, mockHeader, mockBody)
+		s.Require().Equal(t.ExpError, err, t.Desc)
+		s.Require().Equal(exp, transfer, t.Desc)
+		s.TearDownTest()
+	}
+
+	s.SetupTest()
+	_, err := s.srv.HandleCallback(mockCtx, mdBank.Rail_SIMULATOR, mockHeader, mockBody)
+	s.Require().Equal(rail.ErrInvalidSignature, err)
+	_, err = s.srv.HandleCallback(mockCtx, mdBank.Rail("ach"), mockHeader, mockBody)
+	s.Require().Equal(ErrUnknownRail, err)
+}
+
+func (s *testSuite) TestGetTransfer() {
+	s.mFunding.On("GetTransfer", mockCtx, mockTransferID).Return(transfer(mdFunding.Direction_DEPOSIT, mdFunding.Status_PENDING), nil).Twice()
+	s.mWallet.On("Authorize", mockCtx, mockUserID, mockWalletID).Return(member(mdWallet.Role_VIEWER), nil).Once()
+	s.mWallet.On("Authorize", mockCtx, mockOtherID, mockWalletID).Return(nil, bank.ErrAccountNotExist).Once()
+
+	t, err := s.srv.GetTransfer(mockCtx, mockUserID, mockTransferID)
+	s.Require().NoError(err)
+	s.Require().Equal(transfer(mdFunding.Direction_DEPOSIT, mdFunding.Status_PENDING), t)
+
+	_, err = s.srv.GetTransfer(mockCtx, mockOtherID, mockTransferID)
+	s.Require().Equal(funding.ErrTransferNotExist, err)
+}
+
+func (s *testSuite) TestListTransfers() {
+	transfers := []*mdFunding.Transfer{transfer(mdFunding.Direction_DEPOSIT, mdFunding.Status_PENDING)}
+	s.mWallet.On("Authorize", mockCtx, mockUserID, mockWalletID).Return(member(mdWallet.Role_VIEWER), nil).Once()
+	s.mFunding.On("ListTransfers", mockCtx, mockWalletID, 0, 10).Return(transfers, nil).Once()
+
+	res, err := s.srv.ListTransfers(mockCtx, mockUserID, mockWalletID, 0, 10)
+	s.Require().NoError(err)
+	s.Require().Equal(transfers, res)
+}
+
+func TestSuite(t *testing.T) {
+	suite.Run(t, new(testSuite))
+}
@@ -0,0 +1,59 @@
+package fx
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	mFx "github.com/n3k0fi5t/wallet/app/models/fx"
+	"github.com/n3k0fi5t/wallet/app/money"
+)
+
+var (
+	// ErrInvalidAmount means the amount is not positive or converts beyond int64
+	ErrInvalidAmount = fmt.Errorf("Invalid amount")
+
+	// ErrSameCurrency means both wallets hold the same currency, move money instead
+	ErrSameCurrency = fmt.Errorf("Same currency")
+
+	// ErrDifferentOwners means the wallets are owned by different users, exchanges stay within the wallets of one owner
+	ErrDifferentOwners = fmt.Errorf("Different owners")
+
+	// ErrAmountTooSmall means the amount buys nothing after the spread and rounding
+	ErrAmountTooSmall = fmt.Errorf("Amount too small")
+
+	// ErrQuoteExpired means the quote is past its expiry, request a new one
+	ErrQuoteExpired = fmt.Errorf("Quote expired")
+
+	// ErrQuoteNotOpen means the quote is executed already
+	ErrQuoteNotOpen = fmt.Errorf("Quote not open")
+
+	// ErrInsufficientLiquidity means the FX account of the bought currency can not pay the amount out
+	ErrInsufficientLiquidity = fmt.Errorf("Insufficient liquidity")
+)
+
+// Config controls the price and lifetime of quotes
+type Config struct {
+	// SpreadBps is charged on the mid rate in basis points, between 0 and 9999
+	SpreadBps int64
+	// QuoteTTL is the time a quote can be executed at its rate
+	QuoteTTL time.Duration
+}
+
+// DefaultConfig charges 0.5% and holds quotes for 30 seconds
+var DefaultConfig = Config{
+	SpreadBps: 50,
+	QuoteTTL:  30 * time.Second,
+}
+
+type Service interface {
+	// CreateQuote prices selling amount of the from wallet into the to wallet, both wallets must be owned by the same user.
+	// The amount is in the currency of the from wallet
+	CreateQuote(ctx context.Context, userID, fromWalletID, toWalletID string, amount money.Money) (*mFx.Quote, error)
+
+	// GetQuote get a quote requested by the user
+	GetQuote(ctx context.Context, userID, quoteID string) (*mFx.Quote, error)
+
+	// ExecuteQuote books the exchange of an open quote of the user at its rate
+	ExecuteQuote(ctx context.Context, userID, quoteID string) (*mFx.Quote, error)
+}
@@ -0,0 +1,240 @@
+package fx
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/n3k0fi5t/wallet/app/fxrate"
+	mBank "github.com/n3k0fi5t/wallet/app/models/bank"
+	mFx "github.com/n3k0fi5t/wallet/app/models/fx"
+	"github.com/n3k0fi5t/wallet/app/money"
+	"github.com/n3k0fi5t/wallet/app/repository/bank"
+	"github.com/n3k0fi5t/wallet/app/repository/fx"
+	"github.com/n3k0fi5t/wallet/app/service/wallet"
+	"github.com/n3k0fi5t/wallet/app/util"
+	"github.com/n3k0fi5t/wallet/common/logger"
+	"github.com/n3k0fi5t/wallet/common/sql"
+)
+
+const (
+	// bpsDenominator is one in basis points
+	bpsDenominator = 10000
+)
+
+var (
+	timeNowMs = util.TimeNowMs
+	getUUID   = util.GetUUIDv4
+)
+
+func NewFX(t sql.Transactor, b bank.Bank, f fx.FX, w wallet.Service, p fxrate.Provider, cfg Config) Service {
+	return &impl{
+		transactor: t,
+		bank:       b,
+		fx:         f,
+		walletSrv:  w,
+		rates:      p,
+		cfg:        cfg,
+	}
+}
+
+type impl struct {
+	transactor sql.Transactor
+	bank       bank.Bank
+	fx         fx.FX
+	walletSrv  wallet.Service
+	rates      fxrate.Provider
+	cfg        Config
+}
+
+// quoteRate rounds the mid rate to RateDecimals half away from zero, amounts are priced at the rounded rate so the quote reproduces them
+func quoteRate(mid *big.Rat) (string, *big.Rat) {
+	s := mid.FloatString(mFx.RateDecimals)
+	rate, _ := new(big.Rat).SetString(s)
+	return s, rate
+}
+
+// convert prices the sold money in the bought currency at rate less the spread.
+// The result is rounded down, so the bank never pays out more than the rate
+func convert(sold money.Money, rate *big.Rat, spreadBps int64, toCurrency string) (money.Money, error) {
+	x := sold.Rat()
+	x.Mul(x, rate)
+	x.Mul(x, big.NewRat(bpsDenominator-spreadBps, bpsDenominator))
+
+	bought, err := money.FromRat(x, toCurrency, money.RoundDown)
+	if err != nil {
+		return money.Money{}, ErrInvalidAmount
+	}
+	return bought, nil
+}
+
+// spendable checks the user spends from the wallet
+func (im *impl) spendable(ctx context.Context, userID, walletID string) error {
+	m, err := im.walletSrv.Authorize(ctx, userID, walletID)
+	if err != nil {
+		return err
+	} else if !m.Role.CanSpend() {
+		return wallet.ErrPermissionDenied
+	}
+	return nil
+}
+
+func (im *impl) CreateQuote(ctx context.Context, userID, fromWalletID, toWalletID string, amount money.Money) (*mFx.Quote, error) {
+	if !amount.IsPositive() {
+		return nil, ErrInvalidAmount
+	}
+
+	// the user spends from one wallet and sees the other, converting never moves money to another owner
+	if err := im.spendable(ctx, userID, fromWalletID); err != nil {
+		return nil, err
+	}
+	if _, err := im.walletSrv.Authorize(ctx, userID, toWalletID); err != nil {
+		return nil, err
+	}
+
+	from, err := im.walletSrv.GetAccount(ctx, fromWalletID)
+	if err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("walletSrv.GetAccount failed in CreateQuote")
+		return nil, err
+	}
+	to, err := im.walletSrv.GetAccount(ctx, toWalletID)
+	if err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("walletSrv.GetAccount failed in CreateQuote")
+		return nil, err
+	}
+
+	if from.OwnerID != to.OwnerID {
+		return nil, ErrDifferentOwners
+	} else if from.Currency() == to.Currency() {
+		return nil, ErrSameCurrency
+	} else if amount.Currency != from.Currency() {
+		return nil, bank.ErrCurrencyMismatch
+	}
+
+	mid, err := im.rates.Rate(ctx, from.Currency(), to.Currency())
+	if err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("rates.Rate failed in CreateQuote")
+		return nil, err
+	}
+
+	rateString, rate := quoteRate(mid)
+	bought, err := convert(amount, rate, im.cfg.SpreadBps, to.Currency())
+	if err != nil {
+		return nil, err
+	} else if !bought.IsPositive() {
+		return nil, ErrAmountTooSmall
+	}
+
+	quoteID, err := getUUID()
+	if err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("GetUUIDv4 failed in CreateQuote")
+		return nil, err
+	}
+
+	nowMs := timeNowMs()
+	q := &mFx.Quote{
+		QuoteID:      quoteID,
+		UserID:       userID,
+		FromWalletID: fromWalletID,
+		ToWalletID:   toWalletID,
+		SellAmount:   amount,
+		BuyAmount:    bought,
+		Rate:         rateString,
+		SpreadBps:    im.cfg.SpreadBps,
+		Status:       mFx.Status_OPEN,
+		ExpiresAtMs:  nowMs + im.cfg.QuoteTTL.Milliseconds(),
+		CreatedMs:    nowMs,
+		UpdatedMs:    nowMs,
+	}
+	if err := im.fx.CreateQuote(ctx, q); err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("fx.CreateQuote failed in CreateQuote")
+		return nil, err
+	}
+	return q, nil
+}
+
+func (im *impl) GetQuote(ctx context.Context, userID, quoteID string) (*mFx.Quote, error) {
+	q, err := im.fx.GetQuote(ctx, quoteID)
+	if err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("fx.GetQuote failed")
+		return nil, err
+	}
+
+	// quotes of others look not exist
+	if q.UserID != userID {
+		return nil, fx.ErrQuoteNotExist
+	}
+	return q, nil
+}
+
+func (im *impl) ExecuteQuote(ctx context.Context, userID, quoteID string) (*mFx.Quote, error) {
+	var q *mFx.Quote
+	if err := im.transactor.Transact(ctx, func(ctx context.Context) error {
+		quote, err := im.fx.LockQuote(ctx, quoteID)
+		if err != nil {
+			logger.FromContext(ctx).WithField("err", err).Error("fx.LockQuote failed in ExecuteQuote")
+			return err
+		}
+		q = quote
+
+		nowMs := timeNowMs()
+		if q.UserID != userID {
+			return fx.ErrQuoteNotExist
+		} else if q.Status != mFx.Status_OPEN {
+			return ErrQuoteNotOpen
+		} else if q.Expired(nowMs) {
+			return ErrQuoteExpired
+		}
+
+		// the membership may have changed since quoted
+		if err := im.spendable(ctx, userID, q.FromWalletID); err != nil {
+			return err
+		}
+
+		// the sell leg pays out of the wallet, it can not wait for approvals as the rate expires
+		if err := im.walletSrv.CheckPolicy(ctx, q.FromWalletID, q.SellAmount); err != nil {
+			return err
+		}
+
+		// a short FX account would fail the buy leg, tell it apart from the user not having enough money
+		liquidity, err := im.bank.GetAccount(ctx, mBank.FXAccountID(q.BuyAmount.Currency))
+		if err != nil {
+			logger.FromContext(ctx).WithField("err", err).Error("bank.GetAccount failed in ExecuteQuote")
+			return err
+		} else if liquidity.Balance.Amount < q.BuyAmount.Amount {
+			return ErrInsufficientLiquidity
+		}
+
+		tradeID, err := im.bank.Exchange(ctx, &mBank.Exchange{
+			QuoteID: q.QuoteID,
+			Sell: &mBank.Dealing{
+				FromAccountID: q.FromWalletID,
+				ToAccountID:   mBank.FXAccountID(q.SellAmount.Currency),
+				Amount:        q.SellAmount,
+			},
+			Buy: &mBank.Dealing{
+				FromAccountID: mBank.FXAccountID(q.BuyAmount.Currency),
+				ToAccountID:   q.ToWalletID,
+				Amount:        q.BuyAmount,
+			},
+			Rate:      q.Rate,
+			SpreadBps: q.SpreadBps,
+		})
+		if err != nil {
+			logger.FromContext(ctx).WithField("err", err).Error("bank.Exchange failed in ExecuteQuote")
+			return err
+		}
+
+		q.Status = mFx.Status_EXECUTED
+		q.TradeID = tradeID
+		q.UpdatedMs = nowMs
+		if err := im.fx.UpdateQuote(ctx, q); err != nil {
+			logger.FromContext(ctx).WithField("err", err).Error("fx.UpdateQuote failed in ExecuteQuote")
+			return err
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return q, nil
+}
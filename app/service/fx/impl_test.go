@@ -0,0 +1,405 @@
+package fx
+
+import (
+	"context"
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/n3k0fi5t/wallet/app/fxrate"
+	mdBank "github.com/n3k0fi5t/wallet/app/models/bank"
+	mdFx "github.com/n3k0fi5t/wallet/app/models/fx"
+	mdWallet "github.com/n3k0fi5t/wallet/app/models/wallet"
+	"github.com/n3k0fi5t/wallet/app/money"
+	"github.com/n3k0fi5t/wallet/app/repository/bank"
+	mockBank "github.com/n3k0fi5t/wallet/app/repository/bank/mocks"
+	"github.com/n3k0fi5t/wallet/app/repository/fx"
+	mockFx "github.com/n3k0fi5t/wallet/app/repository/fx/mocks"
+	"github.com/n3k0fi5t/wallet/app/service/wallet"
+	mockWallet "github.com/n3k0fi5t/wallet/app/service/wallet/mocks"
+	"github.com/n3k0fi5t/wallet/common/sql/sqltest"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+var (
+	mockCtx       = context.Background()
+	mockUserID    = "n3k0fi5t"
+	mockOtherID   = "deadbeef"
+	mockUSDWallet = "a89b7b78-b9c1-4129-8cff-380bf53f3a49"
+	mockEURWallet = "f00dcafe-b9c1-4129-8cff-380bf53f3a49"
+	mockJPYWallet = "c0ffee00-b9c1-4129-8cff-380bf53f3a49"
+	mockQuoteID   = "935f871a-660f-4f19-801e-916c04bb0324"
+	mockTradeID   = "7e1f2d3c-4b5a-4a8e-9b0c-5d2c9a4e3f61"
+	mockTimeMs    = int64(1650000000000)
+
+	mockRates = map[string]*big.Rat{
+		"USD/EUR": big.NewRat(92, 100),
+		"USD/JPY": big.NewRat(15125, 100),
+	}
+)
+
+func account(walletID, ownerID, currency string) *mdBank.Account {
+	return &mdBank.Account{AccountID: walletID, OwnerID: ownerID, Balance: money.New(0, currency)}
+}
+
+func owner(walletID string) *mdWallet.Member {
+	return &mdWallet.Member{WalletID: walletID, UserID: mockUserID, Role: mdWallet.Role_OWNER}
+}
+
+// openQuote sells 100.00 USD for 91.54 EUR, 0.92 less 0.5%
+func openQuote() *mdFx.Quote {
+	return &mdFx.Quote{
+		QuoteID:      mockQuoteID,
+		UserID:       mockUserID,
+		FromWalletID: mockUSDWallet,
+		ToWalletID:   mockEURWallet,
+		SellAmount:   money.New(10000, "USD"),
+		BuyAmount:    money.New(9154, "EUR"),
+		Rate:         "0.92000000",
+		SpreadBps:    50,
+		Status:       mdFx.Status_OPEN,
+		ExpiresAtMs:  mockTimeMs + DefaultConfig.QuoteTTL.Milliseconds(),
+		CreatedMs:    mockTimeMs,
+		UpdatedMs:    mockTimeMs,
+	}
+}
+
+type testSuite struct {
+	suite.Suite
+	srv     Service
+	mBank   *mockBank.Bank
+	mFx     *mockFx.FX
+	mWallet *mockWallet.Service
+}
+
+func (s *testSuite) SetupSuite() {
+	timeNowMs = func() int64 { return mockTimeMs }
+	getUUID = func() (string, error) { return mockQuoteID, nil }
+}
+
+func (s *testSuite) TearDownSuite() {
+}
+
+func (s *testSuite) SetupTest() {
+	s.mBank = &mockBank.Bank{}
+	s.mFx = &mockFx.FX{}
+	s.mWallet = &mockWallet.Service{}
+	s.srv = NewFX(sqltest.Transactor{}, s.mBank, s.mFx, s.mWallet, fxrate.NewStatic(mockRates), DefaultConfig)
+}
+
+func (s *testSuite) TearDownTest() {
+	s.mBank.AssertExpectations(s.T())
+	s.mFx.AssertExpectations(s.T())
+	s.mWallet.AssertExpectations(s.T())
+}
+
+// expectWallets expects the owner quoting between the two wallets
+func (s *testSuite) expectWallets(from, to *mdBank.Account) {
+	s.mWallet.On("Authorize", mockCtx, mockUserID, from.AccountID).Return(owner(from.AccountID), nil).Once()
+	s.mWallet.On("Authorize", mockCtx, mockUserID, to.AccountID).Return(owner(to.AccountID), nil).Once()
+	s.mWallet.On("GetAccount", mockCtx, from.AccountID).Return(from, nil).Once()
+	s.mWallet.On("GetAccount", mockCtx, to.AccountID).Return(to, nil).Once()
+}
+
+func (s *testSuite) TestConvert() {
+	tests := []struct {
+		Desc      string
+		Amount    int64
+		Rate      *big.Rat
+		SpreadBps int64
+		From      string
+		To        string
+		ExpAmount int64
+		ExpError  error
+	}{
+		{
+			Desc:      "no spread",
+			Amount:    10000,
+			Rate:      big.NewRat(92, 100),
+			From:      "USD",
+			To:        "EUR",
+			ExpAmount: 9200,
+		},
+		{
+			Desc:      "rounds down",
+			Amount:    1000,
+			Rate:      big.NewRat(15125, 100),
+			SpreadBps: 50,
+			From:      "USD",
+			To:        "JPY",
+			ExpAmount: 1504,
+		},
+		{
+			Desc:      "to more minor digits",
+			Amount:    1000,
+			Rate:      big.NewRat(100, 15125),
+			From:      "JPY",
+			To:        "USD",
+			ExpAmount: 661,
+		},
+		{
+			Desc:     "overflow",
+			Amount:   math.MaxInt64,
+			Rate:     big.NewRat(15125, 100),
+			From:     "USD",
+			To:       "JPY",
+			ExpError: ErrInvalidAmount,
+		},
+	}
+
+	for _, t := range tests {
+		bought, err := convert(money.New(t.Amount, t.From), t.Rate, t.SpreadBps, t.To)
+		s.Require().Equal(t.ExpError, err, t.Desc)
+		s.Require().Equal(t.ExpAmount, bought.Amount, t.Desc)
+	}
+}
+
+func (s *testSuite) TestCreateQuote() {
+	usd := account(mockUSDWallet, mockUserID, "USD")
+	eur := account(mockEURWallet, mockUserID, "EUR")
+
+	tests := []struct {
+		Desc     string
+		From     string
+		To       string
+		Amount   money.Money
+		ExpQuote *mdFx.Quote
+		ExpError error
+		setup    func()
+	}{
+		{
+			Desc:     "normal Path",
+			From:     mockUSDWallet,
+			To:       mockEURWallet,
+			Amount:   money.New(10000, "USD"),
+			ExpQuote: openQuote(),
+			setup: func() {
+				s.expectWallets(usd, eur)
+				s.mFx.On("CreateQuote", mockCtx, openQuote()).Return(nil).Once()
+			},
+		},
+		{
+			Desc:   "normal Path, inverse rate rounded",
+			From:   mockEURWallet,
+			To:     mockUSDWallet,
+			Amount: money.New(100, "EUR"),
+			ExpQuote: &mdFx.Quote{
+				QuoteID:      mockQuoteID,
+				UserID:       mockUserID,
+				FromWalletID: mockEURWallet,
+				ToWalletID:   mockUSDWallet,
+				SellAmount:   money.New(100, "EUR"),
+				BuyAmount:    money.New(108, "USD"),
+				Rate:         "1.08695652",
+				SpreadBps:    50,
+				Status:       mdFx.Status_OPEN,
+				ExpiresAtMs:  mockTimeMs + DefaultConfig.QuoteTTL.Milliseconds(),
+				CreatedMs:    mockTimeMs,
+				UpdatedMs:    mockTimeMs,
+			},
+			setup: func() {
+				s.expectWallets(eur, usd)
+				s.mFx.On("CreateQuote", mockCtx, mock.AnythingOfType("*fx.Quote")).Return(nil).Once()
+			},
+		},
+		{
+			Desc:     "bad Path, buys nothing",
+			From:     mockUSDWallet,
+			To:       mockEURWallet,
+			Amount:   money.New(1, "USD"),
+			ExpError: ErrAmountTooSmall,
+			setup: func() {
+				s.expectWallets(usd, eur)
+			},
+		},
+		{
+			Desc:     "bad Path, same currency",
+			From:     mockUSDWallet,
+			To:       mockEURWallet,
+			Amount:   money.New(10000, "USD"),
+			ExpError: ErrSameCurrency,
+			setup: func() {
+				s.expectWallets(usd, account(mockEURWallet, mockUserID, "USD"))
+			},
+		},
+		{
+			Desc:     "bad Path, amount in the currency of the to wallet",
+			From:     mockUSDWallet,
+			To:       mockEURWallet,
+			Amount:   money.New(10000, "EUR"),
+			ExpError: bank.ErrCurrencyMismatch,
+			setup: func() {
+				s.expectWallets(usd, eur)
+			},
+		},
+		{
+			Desc:     "bad Path, wallet of another owner",
+			From:     mockUSDWallet,
+			To:       mockEURWallet,
+			Amount:   money.New(10000, "USD"),
+			ExpError: ErrDifferentOwners,
+			setup: func() {
+				s.expectWallets(usd, account(mockEURWallet, mockOtherID, "EUR"))
+			},
+		},
+		{
+			Desc:     "bad Path, no rate",
+			From:     mockUSDWallet,
+			To:       mockEURWallet,
+			Amount:   money.New(10000, "USD"),
+			ExpError: fxrate.ErrRateNotFound,
+			setup: func() {
+				s.expectWallets(usd, account(mockEURWallet, mockUserID, "GBP"))
+			},
+		},
+		{
+			Desc:     "bad Path, viewer",
+			From:     mockUSDWallet,
+			To:       mockEURWallet,
+			Amount:   money.New(10000, "USD"),
+			ExpError: wallet.ErrPermissionDenied,
+			setup: func() {
+				viewer := &mdWallet.Member{WalletID: mockUSDWallet, UserID: mockUserID, Role: mdWallet.Role_VIEWER}
+				s.mWallet.On("Authorize", mockCtx, mockUserID, mockUSDWallet).Return(viewer, nil).Once()
+			},
+		},
+		{
+			Desc:     "bad Path, zero amount",
+			From:     mockUSDWallet,
+			To:       mockEURWallet,
+			ExpError: ErrInvalidAmount,
+		},
+	}
+
+	for _, t := range tests {
+		s.SetupTest()
+		if t.setup != nil {
+			t.setup()
+		}
+
+		q, err := s.srv.CreateQuote(mockCtx, mockUserID, t.From, t.To, t.Amount)
+		s.Require().Equal(t.ExpError, err, t.Desc)
+		s.Require().Equal(t.ExpQuote, q, t.Desc)
+		s.TearDownTest()
+	}
+}
+
+func (s *testSuite) TestGetQuote() {
+	s.mFx.On("GetQuote", mockCtx, mockQuoteID).Return(openQuote(), nil).Twice()
+
+	q, err := s.srv.GetQuote(mockCtx, mockUserID, mockQuoteID)
+	s.Require().NoError(err)
+	s.Require().Equal(openQuote(), q)
+
+	_, err = s.srv.GetQuote(mockCtx, mockOtherID, mockQuoteID)
+	s.Require().Equal(fx.ErrQuoteNotExist, err)
+}
+
+func (s *testSuite) TestExecuteQuote() {
+	exchange := &mdBank.Exchange{
+		QuoteID:   mockQuoteID,
+		Sell:      &mdBank.Dealing{FromAccountID: mockUSDWallet, ToAccountID: "fx-USD", Amount: money.New(10000, "USD")},
+		Buy:       &mdBank.Dealing{FromAccountID: "fx-EUR", ToAccountID: mockEURWallet, Amount: money.New(9154, "EUR")},
+		Rate:      "0.92000000",
+		SpreadBps: 50,
+	}
+	executed := openQuote()
+	executed.Status = mdFx.Status_EXECUTED
+	executed.TradeID = mockTradeID
+
+	tests := []struct {
+		Desc     string
+		UserID   string
+		ExpQuote *mdFx.Quote
+		ExpError error
+		setup    func()
+	}{
+		{
+			Desc:     "normal Path",
+			UserID:   mockUserID,
+			ExpQuote: executed,
+			setup: func() {
+				s.mFx.On("LockQuote", mockCtx, mockQuoteID).Return(openQuote(), nil).Once()
+				s.mWallet.On("Authorize", mockCtx, mockUserID, mockUSDWallet).Return(owner(mockUSDWallet), nil).Once()
+				s.mWallet.On("CheckPolicy", mockCtx, mockUSDWallet, money.New(10000, "USD")).Return(nil).Once()
+				s.mBank.On("GetAccount", mockCtx, "fx-EUR").Return(&mdBank.Account{AccountID: "fx-EUR", Balance: money.New(100000, "EUR")}, nil).Once()
+				s.mBank.On("Exchange", mockCtx, exchange).Return(mockTradeID, nil).Once()
+				s.mFx.On("UpdateQuote", mockCtx, executed).Return(nil).Once()
+			},
+		},
+		{
+			Desc:     "bad Path, short of liquidity",
+			UserID:   mockUserID,
+			ExpError: ErrInsufficientLiquidity,
+			setup: func() {
+				s.mFx.On("LockQuote", mockCtx, mockQuoteID).Return(openQuote(), nil).Once()
+				s.mWallet.On("Authorize", mockCtx, mockUserID, mockUSDWallet).Return(owner(mockUSDWallet), nil).Once()
+				s.mWallet.On("CheckPolicy", mockCtx, mockUSDWallet, money.New(10000, "USD")).Return(nil).Once()
+				s.mBank.On("GetAccount", mockCtx, "fx-EUR").Return(&mdBank.Account{AccountID: "fx-EUR", Balance: money.New(9000, "EUR")}, nil).Once()
+			},
+		},
+		{
+			Desc:     "bad Path, balance not enough",
+			UserID:   mockUserID,
+			ExpError: bank.ErrBalanceNotEnough,
+			setup: func() {
+				s.mFx.On("LockQuote", mockCtx, mockQuoteID).Return(openQuote(), nil).Once()
+				s.mWallet.On("Authorize", mockCtx, mockUserID, mockUSDWallet).Return(owner(mockUSDWallet), nil).Once()
+				s.mWallet.On("CheckPolicy", mockCtx, mockUSDWallet, money.New(10000, "USD")).Return(nil).Once()
+				s.mBank.On("GetAccount", mockCtx, "fx-EUR").Return(&mdBank.Account{AccountID: "fx-EUR", Balance: money.New(100000, "EUR")}, nil).Once()
+				s.mBank.On("Exchange", mockCtx, exchange).Return("", bank.ErrBalanceNotEnough).Once()
+			},
+		},
+		{
+			Desc:     "bad Path, approval required",
+			UserID:   mockUserID,
+			ExpError: wallet.ErrApprovalRequired,
+			setup: func() {
+				s.mFx.On("LockQuote", mockCtx, mockQuoteID).Return(openQuote(), nil).Once()
+				s.mWallet.On("Authorize", mockCtx, mockUserID, mockUSDWallet).Return(owner(mockUSDWallet), nil).Once()
+				s.mWallet.On("CheckPolicy", mockCtx, mockUSDWallet, money.New(10000, "USD")).Return(wallet.ErrApprovalRequired).Once()
+			},
+		},
+		{
+			Desc:     "bad Path, expired",
+			UserID:   mockUserID,
+			ExpError: ErrQuoteExpired,
+			setup: func() {
+				q := openQuote()
+				q.ExpiresAtMs = mockTimeMs
+				s.mFx.On("LockQuote", mockCtx, mockQuoteID).Return(q, nil).Once()
+			},
+		},
+		{
+			Desc:     "bad Path, executed already",
+			UserID:   mockUserID,
+			ExpError: ErrQuoteNotOpen,
+			setup: func() {
+				s.mFx.On("LockQuote", mockCtx, mockQuoteID).Return(executed, nil).Once()
+			},
+		},
+		{
+			Desc:     "bad Path, quote of another user",
+			UserID:   mockOtherID,
+			ExpError: fx.ErrQuoteNotExist,
+			setup: func() {
+				s.mFx.On("LockQuote", mockCtx, mockQuoteID).Return(openQuote(), nil).Once()
+			},
+		},
+	}
+
+	for _, t := range tests {
+		s.SetupTest()
+		t.setup()
+
+		q, err := s.srv.ExecuteQuote(mockCtx, t.UserID, mockQuoteID)
+		s.Require().Equal(t.ExpError, err, t.Desc)
+		s.Require().Equal(t.ExpQuote, q, t.Desc)
+		s.TearDownTest()
+	}
+}
+
+func TestFX(t *testing.T) {
+	suite.Run(t, new(testSuite))
+}
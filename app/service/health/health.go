@@ -0,0 +1,24 @@
+package health
+
+import (
+	"context"
+
+	mHealth "github.com/n3k0fi5t/wallet/app/models/health"
+)
+
+// Check checks one dependency, it should return before ctx is done
+type Check struct {
+	Name  string
+	Check func(ctx context.Context) error
+}
+
+type Service interface {
+	// Live reports the process is up, it does not check dependencies so a broken DB does not restart the process
+	Live(ctx context.Context) *mHealth.Report
+
+	// Ready reports whether the service can take traffic, it runs every check with a timeout
+	Ready(ctx context.Context) *mHealth.Report
+
+	// SetShuttingDown fails readiness so load balancers drain traffic before the server shuts down
+	SetShuttingDown()
+}
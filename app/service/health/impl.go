@@ -0,0 +1,93 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	mHealth "github.com/n3k0fi5t/wallet/app/models/health"
+)
+
+const (
+	checkShutdown = "shutdown"
+)
+
+var (
+	errShuttingDown = fmt.Errorf("shutting down")
+)
+
+// NewHealth ...
+func NewHealth(timeout time.Duration, checks ...Check) Service {
+	return &impl{
+		timeout: timeout,
+		checks:  checks,
+	}
+}
+
+type impl struct {
+	timeout      time.Duration
+	checks       []Check
+	shuttingDown int32
+}
+
+func (im *impl) Live(ctx context.Context) *mHealth.Report {
+	return &mHealth.Report{
+		Status: mHealth.Status_UP,
+		Checks: []*mHealth.CheckResult{},
+	}
+}
+
+func (im *impl) run(ctx context.Context, check Check) *mHealth.CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, im.timeout)
+	defer cancel()
+
+	start := time.Now()
+	res := &mHealth.CheckResult{
+		Name:   check.Name,
+		Status: mHealth.Status_UP,
+	}
+	if err := check.Check(ctx); err != nil {
+		res.Status = mHealth.Status_DOWN
+		res.Error = err.Error()
+	}
+	res.DurationMs = time.Since(start).Milliseconds()
+	return res
+}
+
+func (im *impl) Ready(ctx context.Context) *mHealth.Report {
+	report := &mHealth.Report{
+		Status: mHealth.Status_UP,
+		Checks: make([]*mHealth.CheckResult, len(im.checks)+1),
+	}
+
+	// checks run concurrently, the slowest one bounds the response time
+	wg := sync.WaitGroup{}
+	for i, check := range im.checks {
+		wg.Add(1)
+		go func(i int, check Check) {
+			defer wg.Done()
+			report.Checks[i] = im.run(ctx, check)
+		}(i, check)
+	}
+	wg.Wait()
+
+	shutdown := &mHealth.CheckResult{Name: checkShutdown, Status: mHealth.Status_UP}
+	if atomic.LoadInt32(&im.shuttingDown) == 1 {
+		shutdown.Status = mHealth.Status_DOWN
+		shutdown.Error = errShuttingDown.Error()
+	}
+	report.Checks[len(im.checks)] = shutdown
+
+	for _, res := range report.Checks {
+		if res.Status != mHealth.Status_UP {
+			report.Status = mHealth.Status_DOWN
+		}
+	}
+	return report
+}
+
+func (im *impl) SetShuttingDown() {
+	atomic.StoreInt32(&im.shuttingDown, 1)
+}
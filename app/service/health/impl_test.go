@@ -0,0 +1,98 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	mdHealth "github.com/n3k0fi5t/wallet/app/models/health"
+)
+
+var (
+	mockCtx     = context.Background()
+	mockTimeout = 20 * time.Millisecond
+
+	upCheck = Check{Name: "up", Check: func(ctx context.Context) error {
+		return nil
+	}}
+	downCheck = Check{Name: "down", Check: func(ctx context.Context) error {
+		return fmt.Errorf("connection refused")
+	}}
+	hangCheck = Check{Name: "hang", Check: func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}}
+)
+
+type testSuite struct {
+	suite.Suite
+}
+
+func TestSuite(t *testing.T) {
+	suite.Run(t, new(testSuite))
+}
+
+// statuses returns status or error of each check by name
+func statuses(report *mdHealth.Report) map[string]string {
+	res := map[string]string{}
+	for _, c := range report.Checks {
+		res[c.Name] = string(c.Status)
+		if c.Error != "" {
+			res[c.Name] = c.Error
+		}
+	}
+	return res
+}
+
+func (s *testSuite) TestReady() {
+	tests := []struct {
+		Desc        string
+		Checks      []Check
+		Shutdown    bool
+		ExpStatus   mdHealth.Status
+		ExpStatuses map[string]string
+	}{
+		{
+			Desc:        "all up",
+			Checks:      []Check{upCheck},
+			ExpStatus:   mdHealth.Status_UP,
+			ExpStatuses: map[string]string{"up": "UP", "shutdown": "UP"},
+		},
+		{
+			Desc:        "dependency down",
+			Checks:      []Check{upCheck, downCheck},
+			ExpStatus:   mdHealth.Status_DOWN,
+			ExpStatuses: map[string]string{"up": "UP", "down": "connection refused", "shutdown": "UP"},
+		},
+		{
+			Desc:        "check times out",
+			Checks:      []Check{hangCheck},
+			ExpStatus:   mdHealth.Status_DOWN,
+			ExpStatuses: map[string]string{"hang": context.DeadlineExceeded.Error(), "shutdown": "UP"},
+		},
+		{
+			Desc:        "shutting down",
+			Checks:      []Check{upCheck},
+			Shutdown:    true,
+			ExpStatus:   mdHealth.Status_DOWN,
+			ExpStatuses: map[string]string{"up": "UP", "shutdown": errShuttingDown.Error()},
+		},
+	}
+
+	for _, t := range tests {
+		srv := NewHealth(mockTimeout, t.Checks...)
+		if t.Shutdown {
+			srv.SetShuttingDown()
+		}
+
+		report := srv.Ready(mockCtx)
+		s.Require().Equal(t.ExpStatus, report.Status, t.Desc)
+		s.Require().Equal(t.ExpStatuses, statuses(report), t.Desc)
+
+		// liveness does not depend on checks
+		s.Require().Equal(mdHealth.Status_UP, srv.Live(mockCtx).Status, t.Desc)
+	}
+}
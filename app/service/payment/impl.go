@@ -0,0 +1,187 @@
+package payment
+
+import (
+	"context"
+	"time"
+
+	mPayment "github.com/n3k0fi5t/wallet/app/models/payment"
+	"github.com/n3k0fi5t/wallet/app/money"
+	"github.com/n3k0fi5t/wallet/app/repository/bank"
+	"github.com/n3k0fi5t/wallet/app/repository/payment"
+	"github.com/n3k0fi5t/wallet/app/service/wallet"
+	"github.com/n3k0fi5t/wallet/app/util"
+	"github.com/n3k0fi5t/wallet/common/logger"
+	"github.com/n3k0fi5t/wallet/common/sql"
+)
+
+const (
+	defaultExpiry = 7 * 24 * time.Hour
+	maxExpiry     = 30 * 24 * time.Hour
+)
+
+var (
+	timeNowMs = util.TimeNowMs
+	getUUID   = util.GetUUIDv4
+)
+
+func NewPayment(t sql.Transactor, p payment.Payment, w wallet.Service) Service {
+	return &impl{
+		transactor: t,
+		payment:    p,
+		walletSrv:  w,
+	}
+}
+
+type impl struct {
+	transactor sql.Transactor
+	payment    payment.Payment
+	walletSrv  wallet.Service
+}
+
+func (im *impl) CreateRequest(ctx context.Context, requesterID, payerID string, amount money.Money, memo string, expiresMs int64) (*mPayment.Request, error) {
+	nowMs := timeNowMs()
+	if expiresMs == 0 {
+		expiresMs = nowMs + defaultExpiry.Milliseconds()
+	}
+
+	if !amount.IsPositive() {
+		return nil, ErrInvalidAmount
+	} else if expiresMs <= nowMs || expiresMs > nowMs+maxExpiry.Milliseconds() {
+		return nil, ErrInvalidExpiry
+	} else if len(memo) > MaxMemoLength {
+		return nil, ErrMemoTooLong
+	} else if requesterID == payerID {
+		return nil, ErrSelfRequest
+	}
+
+	// both sides have to exist and hold the currency of the amount, or the request could never be paid
+	for _, accountID := range []string{requesterID, payerID} {
+		account, err := im.walletSrv.GetAccount(ctx, accountID)
+		if err != nil {
+			logger.FromContext(ctx).WithField("err", err).Error("walletSrv.GetAccount failed in CreateRequest")
+			return nil, err
+		}
+		if account.Currency() != amount.Currency {
+			return nil, bank.ErrCurrencyMismatch
+		}
+	}
+
+	requestID, err := getUUID()
+	if err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("GetUUIDv4 failed in CreateRequest")
+		return nil, err
+	}
+
+	request := &mPayment.Request{
+		RequestID:   requestID,
+		RequesterID: requesterID,
+		PayerID:     payerID,
+		Amount:      amount,
+		Memo:        memo,
+		Status:      mPayment.Status_PENDING,
+		ExpiresMs:   expiresMs,
+		CreatedMs:   nowMs,
+		UpdatedMs:   nowMs,
+	}
+	if err := im.payment.CreateRequest(ctx, request); err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("payment.CreateRequest failed")
+		return nil, err
+	}
+
+	return request, nil
+}
+
+// participates tells whether the account is the requester or payer of the request
+func participates(request *mPayment.Request, accountID string) bool {
+	return request.RequesterID == accountID || request.PayerID == accountID
+}
+
+func (im *impl) GetRequest(ctx context.Context, accountID, requestID string) (*mPayment.Request, error) {
+	request, err := im.payment.GetRequest(ctx, requestID)
+	if err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("payment.GetRequest failed")
+		return nil, err
+	}
+
+	// requests of others look not exist, so request IDs can not be probed
+	if !participates(request, accountID) {
+		return nil, payment.ErrRequestNotExist
+	}
+	return request, nil
+}
+
+func (im *impl) ListRequests(ctx context.Context, accountID string, direction mPayment.Direction, status mPayment.Status, offset, limit int) ([]*mPayment.Request, error) {
+	requests, err := im.payment.ListRequests(ctx, accountID, direction, status, timeNowMs(), offset, limit)
+	if err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("payment.ListRequests failed")
+		return nil, err
+	}
+	return requests, nil
+}
+
+// transit locks the request and moves it from pending to status, pay runs in the same transaction.
+// Concurrent transitions wait for the lock and then see the request is not pending any more.
+func (im *impl) transit(ctx context.Context, requestID string, allowed func(*mPayment.Request) bool, status mPayment.Status, pay func(context.Context, *mPayment.Request) (string, error)) (*mPayment.Request, error) {
+	var request *mPayment.Request
+	if err := im.transactor.Transact(ctx, func(ctx context.Context) error {
+		r, err := im.payment.LockRequest(ctx, requestID)
+		if err != nil {
+			logger.FromContext(ctx).WithField("err", err).Error("payment.LockRequest failed")
+			return err
+		}
+
+		nowMs := timeNowMs()
+		if !allowed(r) {
+			return payment.ErrRequestNotExist
+		} else if r.StatusAt(nowMs) != mPayment.Status_PENDING {
+			return ErrRequestNotPending
+		}
+
+		if pay != nil {
+			tradeID, err := pay(ctx, r)
+			if err != nil {
+				return err
+			}
+			r.TradeID = tradeID
+		}
+
+		r.Status = status
+		r.UpdatedMs = nowMs
+		if err := im.payment.UpdateRequest(ctx, r); err != nil {
+			logger.FromContext(ctx).WithField("err", err).Error("payment.UpdateRequest failed")
+			return err
+		}
+
+		request = r
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return request, nil
+}
+
+func (im *impl) Accept(ctx context.Context, accountID, requestID string) (*mPayment.Request, error) {
+	isPayer := func(r *mPayment.Request) bool { return r.PayerID == accountID }
+	return im.transit(ctx, requestID, isPayer, mPayment.Status_PAID, func(ctx context.Context, r *mPayment.Request) (string, error) {
+		// requests are paid at once, a held transfer is rolled back with the request
+		tradeID, intent, err := im.walletSrv.Transfer(ctx, r.PayerID, r.RequesterID, r.Amount)
+		if err != nil {
+			logger.FromContext(ctx).WithField("err", err).Error("walletSrv.Transfer failed in Accept")
+			return "", err
+		} else if intent != nil {
+			return "", wallet.ErrApprovalRequired
+		}
+		return tradeID, nil
+	})
+}
+
+func (im *impl) Decline(ctx context.Context, accountID, requestID string) (*mPayment.Request, error) {
+	isPayer := func(r *mPayment.Request) bool { return r.PayerID == accountID }
+	return im.transit(ctx, requestID, isPayer, mPayment.Status_DECLINED, nil)
+}
+
+func (im *impl) Cancel(ctx context.Context, accountID, requestID string) (*mPayment.Request, error) {
+	isRequester := func(r *mPayment.Request) bool { return r.RequesterID == accountID }
+	return im.transit(ctx, requestID, isRequester, mPayment.Status_CANCELED, nil)
+}
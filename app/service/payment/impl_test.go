@@ -0,0 +1,386 @@
+package payment
+
+import (
+	"context"
+	"testing"
+
+	mdBank "github.com/n3k0fi5t/wallet/app/models/bank"
+	mdPayment "github.com/n3k0fi5t/wallet/app/models/payment"
+	"github.com/n3k0fi5t/wallet/app/money"
+	"github.com/n3k0fi5t/wallet/app/repository/bank"
+	"github.com/n3k0fi5t/wallet/app/repository/payment"
+	mockPayment "github.com/n3k0fi5t/wallet/app/repository/payment/mocks"
+	mockWallet "github.com/n3k0fi5t/wallet/app/service/wallet/mocks"
+	"github.com/n3k0fi5t/wallet/common/sql/sqltest"
+	"github.com/stretchr/testify/suite"
+)
+
+var (
+	mockCtx         = context.Background()
+	mockRequesterID = "n3k0fi5t"
+	mockPayerID     = "deadbeef"
+	mockStrangerID  = "cafebabe"
+	mockRequestID   = "935f871a-660f-4f19-801e-916c04bb0324"
+	mockTradeID     = "a89b7b78-b9c1-4129-8cff-380bf53f3a49"
+	mockTimeMs      = int64(1650000000000)
+	mockExpiresMs   = mockTimeMs + defaultExpiry.Milliseconds()
+)
+
+// usdWallet is a wallet in the currency of the requests
+func usdWallet(accountID string) *mdBank.Account {
+	return &mdBank.Account{AccountID: accountID, Balance: money.New(0, "USD")}
+}
+
+func pendingRequest() *mdPayment.Request {
+	return &mdPayment.Request{
+		RequestID:   mockRequestID,
+		RequesterID: mockRequesterID,
+		PayerID:     mockPayerID,
+		Amount:      money.New(100, "USD"),
+		Memo:        "lunch",
+		Status:      mdPayment.Status_PENDING,
+		ExpiresMs:   mockExpiresMs,
+		CreatedMs:   mockTimeMs - 1000,
+		UpdatedMs:   mockTimeMs - 1000,
+	}
+}
+
+func withStatus(r *mdPayment.Request, status mdPayment.Status, tradeID string) *mdPayment.Request {
+	r.Status = status
+	r.TradeID = tradeID
+	r.UpdatedMs = mockTimeMs
+	return r
+}
+
+type testSuite struct {
+	suite.Suite
+	srv      Service
+	mPayment *mockPayment.Payment
+	mWallet  *mockWallet.Service
+}
+
+func (s *testSuite) SetupSuite() {
+	timeNowMs = func() int64 { return mockTimeMs }
+	getUUID = func() (string, error) { return mockRequestID, nil }
+}
+
+func (s *testSuite) TearDownSuite() {
+}
+
+func (s *testSuite) SetupTest() {
+	s.mPayment = &mockPayment.Payment{}
+	s.mWallet = &mockWallet.Service{}
+	s.srv = NewPayment(sqltest.Transactor{}, s.mPayment, s.mWallet)
+}
+
+func (s *testSuite) TearDownTest() {
+	s.mPayment.AssertExpectations(s.T())
+	s.mWallet.AssertExpectations(s.T())
+}
+
+func (s *testSuite) TestCreateRequest() {
+	created := pendingRequest()
+	created.CreatedMs = mockTimeMs
+	created.UpdatedMs = mockTimeMs
+
+	tests := []struct {
+		Desc       string
+		PayerID    string
+		Amount     money.Money
+		Memo       string
+		ExpiresMs  int64
+		ExpRequest *mdPayment.Request
+		ExpError   error
+		setup      func()
+	}{
+		{
+			Desc:       "normal Path, default expiry",
+			PayerID:    mockPayerID,
+			Amount:     money.New(100, "USD"),
+			Memo:       "lunch",
+			ExpRequest: created,
+			setup: func() {
+				s.mWallet.On("GetAccount", mockCtx, mockRequesterID).Return(usdWallet(mockRequesterID), nil).Once()
+				s.mWallet.On("GetAccount", mockCtx, mockPayerID).Return(usdWallet(mockPayerID), nil).Once()
+				s.mPayment.On("CreateRequest", mockCtx, created).Return(nil).Once()
+			},
+		},
+		{
+			Desc:     "bad Path, zero amount",
+			PayerID:  mockPayerID,
+			Amount:   money.New(0, "USD"),
+			ExpError: ErrInvalidAmount,
+		},
+		{
+			Desc:      "bad Path, expired already",
+			PayerID:   mockPayerID,
+			Amount:    money.New(100, "USD"),
+			ExpiresMs: mockTimeMs,
+			ExpError:  ErrInvalidExpiry,
+		},
+		{
+			Desc:      "bad Path, expiry too far",
+			PayerID:   mockPayerID,
+			Amount:    money.New(100, "USD"),
+			ExpiresMs: mockTimeMs + maxExpiry.Milliseconds() + 1,
+			ExpError:  ErrInvalidExpiry,
+		},
+		{
+			Desc:     "bad Path, memo too long",
+			PayerID:  mockPayerID,
+			Amount:   money.New(100, "USD"),
+			Memo:     string(make([]byte, MaxMemoLength+1)),
+			ExpError: ErrMemoTooLong,
+		},
+		{
+			Desc:     "bad Path, self request",
+			PayerID:  mockRequesterID,
+			Amount:   money.New(100, "USD"),
+			ExpError: ErrSelfRequest,
+		},
+		{
+			Desc:     "bad Path, payer not exist",
+			PayerID:  mockPayerID,
+			Amount:   money.New(100, "USD"),
+			ExpError: bank.ErrAccountNotExist,
+			setup: func() {
+				s.mWallet.On("GetAccount", mockCtx, mockRequesterID).Return(usdWallet(mockRequesterID), nil).Once()
+				s.mWallet.On("GetAccount", mockCtx, mockPayerID).Return((*mdBank.Account)(nil), bank.ErrAccountNotExist).Once()
+			},
+		},
+		{
+			Desc:     "bad Path, payer in another currency",
+			PayerID:  mockPayerID,
+			Amount:   money.New(100, "USD"),
+			ExpError: bank.ErrCurrencyMismatch,
+			setup: func() {
+				s.mWallet.On("GetAccount", mockCtx, mockRequesterID).Return(usdWallet(mockRequesterID), nil).Once()
+				s.mWallet.On("GetAccount", mockCtx, mockPayerID).Return(&mdBank.Account{AccountID: mockPayerID, Balance: money.New(0, "EUR")}, nil).Once()
+			},
+		},
+	}
+
+	for _, test := range tests {
+		s.SetupTest()
+		if test.setup != nil {
+			test.setup()
+		}
+
+		request, err := s.srv.CreateRequest(mockCtx, mockRequesterID, test.PayerID, test.Amount, test.Memo, test.ExpiresMs)
+		s.Require().Equal(test.ExpRequest, request, test.Desc)
+		s.Require().Equal(test.ExpError, err, test.Desc)
+
+		s.TearDownTest()
+	}
+}
+
+func (s *testSuite) TestGetRequest() {
+	tests := []struct {
+		Desc       string
+		AccountID  string
+		ExpRequest *mdPayment.Request
+		ExpError   error
+		setup      func()
+	}{
+		{
+			Desc:       "normal Path, payer",
+			AccountID:  mockPayerID,
+			ExpRequest: pendingRequest(),
+			setup: func() {
+				s.mPayment.On("GetRequest", mockCtx, mockRequestID).Return(pendingRequest(), nil).Once()
+			},
+		},
+		{
+			Desc:      "bad Path, not a participant",
+			AccountID: mockStrangerID,
+			ExpError:  payment.ErrRequestNotExist,
+			setup: func() {
+				s.mPayment.On("GetRequest", mockCtx, mockRequestID).Return(pendingRequest(), nil).Once()
+			},
+		},
+		{
+			Desc:      "bad Path, not exist",
+			AccountID: mockPayerID,
+			ExpError:  payment.ErrRequestNotExist,
+			setup: func() {
+				s.mPayment.On("GetRequest", mockCtx, mockRequestID).Return((*mdPayment.Request)(nil), payment.ErrRequestNotExist).Once()
+			},
+		},
+	}
+
+	for _, test := range tests {
+		s.SetupTest()
+		if test.setup != nil {
+			test.setup()
+		}
+
+		request, err := s.srv.GetRequest(mockCtx, test.AccountID, mockRequestID)
+		s.Require().Equal(test.ExpRequest, request, test.Desc)
+		s.Require().Equal(test.ExpError, err, test.Desc)
+
+		s.TearDownTest()
+	}
+}
+
+func (s *testSuite) TestListRequests() {
+	s.mPayment.On("ListRequests", mockCtx, mockPayerID, mdPayment.Direction_INCOMING, mdPayment.Status_EXPIRED, mockTimeMs, 0, 50).Return([]*mdPayment.Request{pendingRequest()}, nil).Once()
+
+	requests, err := s.srv.ListRequests(mockCtx, mockPayerID, mdPayment.Direction_INCOMING, mdPayment.Status_EXPIRED, 0, 50)
+	s.Require().NoError(err)
+	s.Require().Equal([]*mdPayment.Request{pendingRequest()}, requests)
+}
+
+func (s *testSuite) TestAccept() {
+	expired := pendingRequest()
+	expired.ExpiresMs = mockTimeMs
+
+	tests := []struct {
+		Desc       string
+		AccountID  string
+		ExpRequest *mdPayment.Request
+		ExpError   error
+		setup      func()
+	}{
+		{
+			Desc:       "normal Path",
+			AccountID:  mockPayerID,
+			ExpRequest: withStatus(pendingRequest(), mdPayment.Status_PAID, mockTradeID),
+			setup: func() {
+				s.mPayment.On("LockRequest", mockCtx, mockRequestID).Return(pendingRequest(), nil).Once()
+				s.mWallet.On("Transfer", mockCtx, mockPayerID, mockRequesterID, money.New(100, "USD")).Return(mockTradeID, nil, nil).Once()
+				s.mPayment.On("UpdateRequest", mockCtx, withStatus(pendingRequest(), mdPayment.Status_PAID, mockTradeID)).Return(nil).Once()
+			},
+		},
+		{
+			Desc:      "bad Path, requester can not accept",
+			AccountID: mockRequesterID,
+			ExpError:  payment.ErrRequestNotExist,
+			setup: func() {
+				s.mPayment.On("LockRequest", mockCtx, mockRequestID).Return(pendingRequest(), nil).Once()
+			},
+		},
+		{
+			Desc:      "bad Path, paid already",
+			AccountID: mockPayerID,
+			ExpError:  ErrRequestNotPending,
+			setup: func() {
+				s.mPayment.On("LockRequest", mockCtx, mockRequestID).Return(withStatus(pendingRequest(), mdPayment.Status_PAID, mockTradeID), nil).Once()
+			},
+		},
+		{
+			Desc:      "bad Path, expired",
+			AccountID: mockPayerID,
+			ExpError:  ErrRequestNotPending,
+			setup: func() {
+				s.mPayment.On("LockRequest", mockCtx, mockRequestID).Return(expired, nil).Once()
+			},
+		},
+		{
+			Desc:      "bad Path, balance not enough",
+			AccountID: mockPayerID,
+			ExpError:  bank.ErrBalanceNotEnough,
+			setup: func() {
+				s.mPayment.On("LockRequest", mockCtx, mockRequestID).Return(pendingRequest(), nil).Once()
+				s.mWallet.On("Transfer", mockCtx, mockPayerID, mockRequesterID, money.New(100, "USD")).Return("", nil, bank.ErrBalanceNotEnough).Once()
+			},
+		},
+	}
+
+	for _, test := range tests {
+		s.SetupTest()
+		if test.setup != nil {
+			test.setup()
+		}
+
+		request, err := s.srv.Accept(mockCtx, test.AccountID, mockRequestID)
+		s.Require().Equal(test.ExpRequest, request, test.Desc)
+		s.Require().Equal(test.ExpError, err, test.Desc)
+
+		s.TearDownTest()
+	}
+}
+
+func (s *testSuite) TestDeclineAndCancel() {
+	tests := []struct {
+		Desc       string
+		Action     func(ctx context.Context, accountID, requestID string) (*mdPayment.Request, error)
+		AccountID  string
+		ExpRequest *mdPayment.Request
+		ExpError   error
+		setup      func()
+	}{
+		{
+			Desc:       "normal Path, payer declines",
+			Action:     s.decline,
+			AccountID:  mockPayerID,
+			ExpRequest: withStatus(pendingRequest(), mdPayment.Status_DECLINED, ""),
+			setup: func() {
+				s.mPayment.On("LockRequest", mockCtx, mockRequestID).Return(pendingRequest(), nil).Once()
+				s.mPayment.On("UpdateRequest", mockCtx, withStatus(pendingRequest(), mdPayment.Status_DECLINED, "")).Return(nil).Once()
+			},
+		},
+		{
+			Desc:      "bad Path, requester can not decline",
+			Action:    s.decline,
+			AccountID: mockRequesterID,
+			ExpError:  payment.ErrRequestNotExist,
+			setup: func() {
+				s.mPayment.On("LockRequest", mockCtx, mockRequestID).Return(pendingRequest(), nil).Once()
+			},
+		},
+		{
+			Desc:       "normal Path, requester cancels",
+			Action:     s.cancel,
+			AccountID:  mockRequesterID,
+			ExpRequest: withStatus(pendingRequest(), mdPayment.Status_CANCELED, ""),
+			setup: func() {
+				s.mPayment.On("LockRequest", mockCtx, mockRequestID).Return(pendingRequest(), nil).Once()
+				s.mPayment.On("UpdateRequest", mockCtx, withStatus(pendingRequest(), mdPayment.Status_CANCELED, "")).Return(nil).Once()
+			},
+		},
+		{
+			Desc:      "bad Path, payer can not cancel",
+			Action:    s.cancel,
+			AccountID: mockPayerID,
+			ExpError:  payment.ErrRequestNotExist,
+			setup: func() {
+				s.mPayment.On("LockRequest", mockCtx, mockRequestID).Return(pendingRequest(), nil).Once()
+			},
+		},
+		{
+			Desc:      "bad Path, declined already",
+			Action:    s.cancel,
+			AccountID: mockRequesterID,
+			ExpError:  ErrRequestNotPending,
+			setup: func() {
+				s.mPayment.On("LockRequest", mockCtx, mockRequestID).Return(withStatus(pendingRequest(), mdPayment.Status_DECLINED, ""), nil).Once()
+			},
+		},
+	}
+
+	for _, test := range tests {
+		s.SetupTest()
+		if test.setup != nil {
+			test.setup()
+		}
+
+		request, err := test.Action(mockCtx, test.AccountID, mockRequestID)
+		s.Require().Equal(test.ExpRequest, request, test.Desc)
+		s.Require().Equal(test.ExpError, err, test.Desc)
+
+		s.TearDownTest()
+	}
+}
+
+// decline and cancel call the service of the current test
+func (s *testSuite) decline(ctx context.Context, accountID, requestID string) (*mdPayment.Request, error) {
+	return s.srv.Decline(ctx, accountID, requestID)
+}
+
+func (s *testSuite) cancel(ctx context.Context, accountID, requestID string) (*mdPayment.Request, error) {
+	return s.srv.Cancel(ctx, accountID, requestID)
+}
+
+func TestSuite(t *testing.T) {
+	suite.Run(t, new(testSuite))
+}
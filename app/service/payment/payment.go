@@ -0,0 +1,52 @@
+package payment
+
+import (
+	"context"
+	"fmt"
+
+	mPayment "github.com/n3k0fi5t/wallet/app/models/payment"
+	"github.com/n3k0fi5t/wallet/app/money"
+)
+
+var (
+	// ErrInvalidAmount means the requested amount is not positive
+	ErrInvalidAmount = fmt.Errorf("Invalid amount")
+
+	// ErrInvalidExpiry means the expiry is in the past or too far away
+	ErrInvalidExpiry = fmt.Errorf("Invalid expiry")
+
+	// ErrMemoTooLong means the memo exceeds MaxMemoLength
+	ErrMemoTooLong = fmt.Errorf("Memo too long")
+
+	// ErrSelfRequest means the requester asks money from itself
+	ErrSelfRequest = fmt.Errorf("Self request")
+
+	// ErrRequestNotPending means the request is paid, declined, canceled or expired already
+	ErrRequestNotPending = fmt.Errorf("Payment request not pending")
+)
+
+const (
+	// MaxMemoLength is the max length of memo in bytes
+	MaxMemoLength = 255
+)
+
+type Service interface {
+	// CreateRequest asks the payer to pay amount to the requester, expiresMs 0 means the default expiry.
+	// Both accounts hold the currency of the amount
+	CreateRequest(ctx context.Context, requesterID, payerID string, amount money.Money, memo string, expiresMs int64) (*mPayment.Request, error)
+
+	// GetRequest get a request the account takes part in
+	GetRequest(ctx context.Context, accountID, requestID string) (*mPayment.Request, error)
+
+	// ListRequests list incoming or outgoing requests of the account, newest first
+	ListRequests(ctx context.Context, accountID string, direction mPayment.Direction, status mPayment.Status, offset, limit int) ([]*mPayment.Request, error)
+
+	// Accept pays a pending request, only the payer can accept it
+	Accept(ctx context.Context, accountID, requestID string) (*mPayment.Request, error)
+
+	// Decline refuses a pending request, only the payer can decline it
+	Decline(ctx context.Context, accountID, requestID string) (*mPayment.Request, error)
+
+	// Cancel withdraws a pending request, only the requester can cancel it
+	Cancel(ctx context.Context, accountID, requestID string) (*mPayment.Request, error)
+}
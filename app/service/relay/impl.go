@@ -0,0 +1,112 @@
+package relay
+
+import (
+	"context"
+	"time"
+
+	mEvent "github.com/n3k0fi5t/wallet/app/models/event"
+	"github.com/n3k0fi5t/wallet/app/publisher"
+	"github.com/n3k0fi5t/wallet/app/repository/outbox"
+	"github.com/n3k0fi5t/wallet/common/logger"
+	"github.com/n3k0fi5t/wallet/common/sql"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// claimLease is how long claimed events are reserved for the relay, events not published by then are
+	// left to the next claim
+	claimLease = 30 * time.Second
+)
+
+func NewRelay(t sql.Transactor, o outbox.Outbox, p publisher.Publisher, interval time.Duration, batchSize int) Relay {
+	return &impl{
+		transactor: t,
+		outbox:     o,
+		publisher:  p,
+		interval:   interval,
+		batchSize:  batchSize,
+	}
+}
+
+type impl struct {
+	transactor sql.Transactor
+	outbox     outbox.Outbox
+	publisher  publisher.Publisher
+	interval   time.Duration
+	batchSize  int
+}
+
+func (im *impl) RelayOnce(ctx context.Context) (int, error) {
+	// claimed events are leased instead of locked, so bookings adding events never wait on the publisher
+	events, err := im.outbox.Claim(ctx, im.batchSize, claimLease)
+	if err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("outbox.Claim failed in RelayOnce")
+		return 0, err
+	}
+
+	published, unpublished := im.publish(ctx, events)
+
+	// unpublished events are released so the next round retries them in order
+	if err := im.transactor.Transact(ctx, func(ctx context.Context) error {
+		if err := im.outbox.MarkPublished(ctx, published); err != nil {
+			return err
+		}
+		return im.outbox.Release(ctx, unpublished)
+	}); err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("outbox.MarkPublished failed in RelayOnce")
+		return 0, err
+	}
+
+	if len(unpublished) > 0 {
+		logger.FromContext(ctx).WithField("failed", len(unpublished)).Warn("some events are not published in RelayOnce")
+	}
+	return len(published), nil
+}
+
+// publish publishes events before their lease ends, and returns sequences of published and unpublished events
+func (im *impl) publish(ctx context.Context, events []*mEvent.Event) ([]int64, []int64) {
+	leaseCtx, cancel := context.WithTimeout(ctx, claimLease)
+	defer cancel()
+
+	published, unpublished := []int64{}, []int64{}
+	// once an event of an account fails, later events of the account wait for the next round to keep ordering
+	blocked := map[string]bool{}
+	for _, e := range events {
+		if blocked[e.AccountID] || leaseCtx.Err() != nil {
+			unpublished = append(unpublished, e.Sequence)
+			continue
+		}
+
+		if err := im.publisher.Publish(leaseCtx, e); err != nil {
+			logger.FromContext(ctx).WithFields(logrus.Fields{
+				"err":      err,
+				"eventID":  e.EventID,
+				"sequence": e.Sequence,
+			}).Warn("publisher.Publish failed in RelayOnce")
+			blocked[e.AccountID] = true
+			unpublished = append(unpublished, e.Sequence)
+			continue
+		}
+		published = append(published, e.Sequence)
+	}
+	return published, unpublished
+}
+
+func (im *impl) Run(ctx context.Context) {
+	ticker := time.NewTicker(im.interval)
+	defer ticker.Stop()
+
+	for {
+		n, err := im.RelayOnce(ctx)
+		// keep draining without waiting while there is a backlog
+		if err == nil && n == im.batchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
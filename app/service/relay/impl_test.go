@@ -0,0 +1,144 @@
+package relay
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	mdEvent "github.com/n3k0fi5t/wallet/app/models/event"
+	mockPublisher "github.com/n3k0fi5t/wallet/app/publisher/mocks"
+	mockOutbox "github.com/n3k0fi5t/wallet/app/repository/outbox/mocks"
+	"github.com/n3k0fi5t/wallet/common/sql/sqltest"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+var (
+	mockCtx        = context.Background()
+	mockAccountID1 = "n3k0fi5t"
+	mockAccountID2 = "deadbeef"
+	mockBatchSize  = 10
+
+	anyCtx = mock.Anything
+
+	mockEventA1 = &mdEvent.Event{Sequence: 1, EventID: "a1", Type: mdEvent.Type_TRADE_COMPLETED, AccountID: mockAccountID1}
+	mockEventB1 = &mdEvent.Event{Sequence: 2, EventID: "b1", Type: mdEvent.Type_TRADE_COMPLETED, AccountID: mockAccountID2}
+	mockEventA2 = &mdEvent.Event{Sequence: 3, EventID: "a2", Type: mdEvent.Type_TRADE_FAILED, AccountID: mockAccountID1}
+	mockEventB2 = &mdEvent.Event{Sequence: 4, EventID: "b2", Type: mdEvent.Type_TRADE_COMPLETED, AccountID: mockAccountID2}
+)
+
+type testSuite struct {
+	suite.Suite
+	relay      Relay
+	mOutbox    *mockOutbox.Outbox
+	mPublisher *mockPublisher.Publisher
+}
+
+func (s *testSuite) SetupTest() {
+	s.mOutbox = &mockOutbox.Outbox{}
+	s.mPublisher = &mockPublisher.Publisher{}
+	s.relay = NewRelay(sqltest.Transactor{}, s.mOutbox, s.mPublisher, time.Millisecond, mockBatchSize)
+}
+
+func (s *testSuite) TearDownTest() {
+	s.mOutbox.AssertExpectations(s.T())
+	s.mPublisher.AssertExpectations(s.T())
+}
+
+func (s *testSuite) TestRelayOnce() {
+	tests := []struct {
+		Desc     string
+		ExpCount int
+		ExpError error
+		setup    func()
+	}{
+		{
+			Desc:     "normal Path",
+			ExpCount: 2,
+			setup: func() {
+				s.mOutbox.On("Claim", mockCtx, mockBatchSize, claimLease).Return([]*mdEvent.Event{mockEventA1, mockEventB1}, nil).Once()
+				s.mPublisher.On("Publish", anyCtx, mockEventA1).Return(nil).Once()
+				s.mPublisher.On("Publish", anyCtx, mockEventB1).Return(nil).Once()
+				s.mOutbox.On("MarkPublished", mockCtx, []int64{1, 2}).Return(nil).Once()
+				s.mOutbox.On("Release", mockCtx, []int64{}).Return(nil).Once()
+			},
+		},
+		{
+			Desc:     "failed event blocks later events of the same account only",
+			ExpCount: 2,
+			setup: func() {
+				s.mOutbox.On("Claim", mockCtx, mockBatchSize, claimLease).Return([]*mdEvent.Event{mockEventA1, mockEventB1, mockEventA2, mockEventB2}, nil).Once()
+				s.mPublisher.On("Publish", anyCtx, mockEventA1).Return(fmt.Errorf("broker down")).Once()
+				s.mPublisher.On("Publish", anyCtx, mockEventB1).Return(nil).Once()
+				s.mPublisher.On("Publish", anyCtx, mockEventB2).Return(nil).Once()
+				s.mOutbox.On("MarkPublished", mockCtx, []int64{2, 4}).Return(nil).Once()
+				s.mOutbox.On("Release", mockCtx, []int64{1, 3}).Return(nil).Once()
+			},
+		},
+		{
+			Desc:     "nothing pending",
+			ExpCount: 0,
+			setup: func() {
+				s.mOutbox.On("Claim", mockCtx, mockBatchSize, claimLease).Return([]*mdEvent.Event{}, nil).Once()
+				s.mOutbox.On("MarkPublished", mockCtx, []int64{}).Return(nil).Once()
+				s.mOutbox.On("Release", mockCtx, []int64{}).Return(nil).Once()
+			},
+		},
+		{
+			Desc:     "bad Path, claim failed",
+			ExpCount: 0,
+			ExpError: fmt.Errorf("db down"),
+			setup: func() {
+				s.mOutbox.On("Claim", mockCtx, mockBatchSize, claimLease).Return(nil, fmt.Errorf("db down")).Once()
+			},
+		},
+		{
+			Desc:     "bad Path, mark failed",
+			ExpCount: 0,
+			ExpError: fmt.Errorf("db down"),
+			setup: func() {
+				s.mOutbox.On("Claim", mockCtx, mockBatchSize, claimLease).Return([]*mdEvent.Event{mockEventA1}, nil).Once()
+				s.mPublisher.On("Publish", anyCtx, mockEventA1).Return(nil).Once()
+				s.mOutbox.On("MarkPublished", mockCtx, []int64{1}).Return(fmt.Errorf("db down")).Once()
+			},
+		},
+	}
+
+	for _, test := range tests {
+		s.SetupTest()
+		if test.setup != nil {
+			test.setup()
+		}
+
+		n, err := s.relay.RelayOnce(mockCtx)
+		s.Require().Equal(test.ExpCount, n, test.Desc)
+		s.Require().Equal(test.ExpError, err, test.Desc)
+
+		s.TearDownTest()
+	}
+}
+
+func (s *testSuite) TestRunStopsOnCancel() {
+	ctx, cancel := context.WithCancel(mockCtx)
+	s.mOutbox.On("Claim", ctx, mockBatchSize, claimLease).Return([]*mdEvent.Event{}, nil)
+	s.mOutbox.On("MarkPublished", ctx, []int64{}).Return(nil)
+	s.mOutbox.On("Release", ctx, []int64{}).Return(nil)
+
+	done := make(chan struct{})
+	go func() {
+		s.relay.Run(ctx)
+		close(done)
+	}()
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		s.Fail("Run does not return after cancel")
+	}
+}
+
+func TestSuite(t *testing.T) {
+	suite.Run(t, new(testSuite))
+}
@@ -0,0 +1,13 @@
+package relay
+
+import (
+	"context"
+)
+
+type Relay interface {
+	// RelayOnce publishes one batch of pending outbox events and returns the number of published events
+	RelayOnce(ctx context.Context) (int, error)
+
+	// Run keeps relaying until context is done
+	Run(ctx context.Context)
+}
@@ -0,0 +1,156 @@
+package wallet
+
+import (
+	"context"
+
+	mApproval "github.com/n3k0fi5t/wallet/app/models/approval"
+	mBank "github.com/n3k0fi5t/wallet/app/models/bank"
+	mWallet "github.com/n3k0fi5t/wallet/app/models/wallet"
+	"github.com/n3k0fi5t/wallet/app/money"
+	"github.com/n3k0fi5t/wallet/app/repository/approval"
+	"github.com/n3k0fi5t/wallet/app/repository/bank"
+	"github.com/n3k0fi5t/wallet/common/logger"
+)
+
+// eligible counts the approvers other than the requester
+func eligible(approvers []string, requestedBy string) int {
+	n := 0
+	for _, id := range approvers {
+		if id != requestedBy {
+			n++
+		}
+	}
+	return n
+}
+
+// hold moves amount of the wallet to a new hold account until the intent is approved under the policy, the payee is
+// nil for a withdrawal
+func (im *impl) hold(ctx context.Context, account *mBank.Account, m *mWallet.Member, payee *mBank.Account, amount money.Money, policy *mApproval.Policy) (*mApproval.Intent, error) {
+	key := idempotencyKey(ctx, account.AccountID)
+	if i, err := im.idempotentIntent(ctx, key, payee, amount); err != approval.ErrIntentNotExist {
+		return i, err
+	}
+
+	if eligible(mApproval.ApproverIDs(policy.Approvers), m.UserID) < policy.Required {
+		return nil, ErrNotEnoughApprovers
+	}
+
+	// the hold account holds the currency of the wallet, which is paid out to the payee
+	toAccountID := ""
+	if payee != nil {
+		if payee.Currency() != account.Currency() {
+			return nil, bank.ErrCurrencyMismatch
+		}
+		toAccountID = payee.AccountID
+	}
+
+	intentID, err := getUUID()
+	if err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("GetUUIDv4 failed in hold")
+		return nil, err
+	}
+
+	nowMs := timeNowMs()
+	i := &mApproval.Intent{
+		IntentID:       intentID,
+		WalletID:       account.AccountID,
+		RequestedBy:    m.UserID,
+		ToAccountID:    toAccountID,
+		Amount:         amount,
+		HoldAccountID:  holdAccountPrefix + intentID,
+		Status:         mApproval.Status_PENDING,
+		Required:       policy.Required,
+		Approvers:      policy.Approvers,
+		IdempotencyKey: key,
+		ExpiresAtMs:    nowMs + IntentTTL.Milliseconds(),
+		CreatedMs:      nowMs,
+		UpdatedMs:      nowMs,
+	}
+
+	// the hold account, the hold and the intent commit together
+	if err := im.transactor.Transact(ctx, func(ctx context.Context) error {
+		if err := im.bank.CreateAccount(ctx, &mBank.Account{
+			AccountID: i.HoldAccountID,
+			Status:    mBank.AccountStatus_ACTIVE,
+			Type:      mBank.AccountType_HOLD,
+			Balance:   money.New(0, account.Currency()),
+		}); err != nil {
+			logger.FromContext(ctx).WithField("err", err).Error("bank.CreateAccount failed in hold")
+			return err
+		}
+
+		// concurrent retries with the same idempotency key pay to another hold account, they fail instead of holding twice
+		deal := makeDeal(account.AccountID, i.HoldAccountID, amount, dealTransfer)
+		deal.IdempotencyKey = key
+		tradeID, err := im.bank.Trade(ctx, deal)
+		if err != nil {
+			logger.FromContext(ctx).WithField("err", err).Error("bank.Trade failed in hold")
+			return err
+		}
+		i.HoldTradeID = tradeID
+
+		if err := im.approvals.CreateIntent(ctx, i); err != nil {
+			logger.FromContext(ctx).WithField("err", err).Error("approvals.CreateIntent failed in hold")
+			return err
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return i, nil
+}
+
+// idempotentIntent returns the intent held with the idempotency key, a retry gets the intent of the original request.
+// ErrIntentNotExist if the key is unused
+func (im *impl) idempotentIntent(ctx context.Context, key string, payee *mBank.Account, amount money.Money) (*mApproval.Intent, error) {
+	if key == "" {
+		return nil, approval.ErrIntentNotExist
+	}
+
+	i, err := im.approvals.GetIdempotentIntent(ctx, key)
+	if err == approval.ErrIntentNotExist {
+		return nil, err
+	} else if err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("approvals.GetIdempotentIntent failed in idempotentIntent")
+		return nil, err
+	}
+
+	toAccountID := ""
+	if payee != nil {
+		toAccountID = payee.AccountID
+	}
+	if i.ToAccountID != toAccountID || i.Amount != amount {
+		return nil, bank.ErrIdempotencyKeyReused
+	}
+	return i, nil
+}
+
+func (im *impl) Hold(ctx context.Context, userID, walletID, toAccountID string, amount money.Money) (*mApproval.Intent, error) {
+	var i *mApproval.Intent
+	err := im.transactor.Transact(ctx, func(ctx context.Context) error {
+		account, m, err := im.spender(ctx, userID, walletID)
+		if err != nil {
+			return err
+		}
+
+		// users can only pay to users, the payee is checked again when the intent executes
+		var payee *mBank.Account
+		if toAccountID != "" {
+			if payee, err = im.GetAccount(ctx, toAccountID); err != nil {
+				return err
+			}
+		}
+
+		policy, err := im.requiredPolicy(ctx, walletID, amount)
+		if err != nil {
+			return err
+		} else if policy == nil {
+			return ErrApprovalNotRequired
+		}
+
+		i, err = im.hold(ctx, account, m, payee, amount, policy)
+		return err
+	})
+	return i, err
+}
@@ -0,0 +1,216 @@
+package wallet
+
+import (
+	mdApproval "github.com/n3k0fi5t/wallet/app/models/approval"
+	mdBank "github.com/n3k0fi5t/wallet/app/models/bank"
+	mdWallet "github.com/n3k0fi5t/wallet/app/models/wallet"
+	"github.com/n3k0fi5t/wallet/app/money"
+	"github.com/n3k0fi5t/wallet/app/repository/approval"
+	"github.com/n3k0fi5t/wallet/app/repository/bank"
+)
+
+var (
+	mockIntentID    = "0b7e4d8a-3f6c-4d1e-9a2b-5c8d7e6f1a2b"
+	mockHoldAccount = holdAccountPrefix + mockIntentID
+)
+
+// policyOver100 requires one of the owner and the stranger to approve paying more than 100 out of the joint wallet
+func policyOver100() *mdApproval.Policy {
+	return &mdApproval.Policy{WalletID: mockWalletID, Threshold: usd(100), Required: 1, Approvers: mockAccountID1 + "," + mockStranger}
+}
+
+// heldIntent is the intent holding 200 of the joint wallet, requested by the spender
+func heldIntent(toAccountID string) *mdApproval.Intent {
+	return &mdApproval.Intent{
+		IntentID:      mockIntentID,
+		WalletID:      mockWalletID,
+		RequestedBy:   mockAccountID2,
+		ToAccountID:   toAccountID,
+		Amount:        usd(200),
+		HoldAccountID: mockHoldAccount,
+		Status:        mdApproval.Status_PENDING,
+		Required:      1,
+		Approvers:     mockAccountID1 + "," + mockStranger,
+		HoldTradeID:   mockTradeID,
+		ExpiresAtMs:   mockTimeMs + IntentTTL.Milliseconds(),
+		CreatedMs:     mockTimeMs,
+		UpdatedMs:     mockTimeMs,
+	}
+}
+
+// expectHold expects the hold account, the hold and the intent of heldIntent
+func (s *testSuite) expectHold(toAccountID string) {
+	s.mBank.On("CreateAccount", mockCtx, &mdBank.Account{
+		AccountID: mockHoldAccount,
+		Status:    mdBank.AccountStatus_ACTIVE,
+		Type:      mdBank.AccountType_HOLD,
+		Balance:   usd(0),
+	}).Return(nil).Once()
+	s.mBank.On("Trade", mockCtx, &mdBank.Dealing{FromAccountID: mockWalletID, ToAccountID: mockHoldAccount, Amount: usd(200)}).Return(mockTradeID, nil).Once()
+	s.mApproval.On("CreateIntent", mockCtx, heldIntent(toAccountID)).Return(nil).Once()
+}
+
+func (s *testSuite) TestWithdrawAsHeld() {
+	timeNowMs = func() int64 { return mockTimeMs }
+	getUUID = func() (string, error) { return mockIntentID, nil }
+
+	// held amounts are not charged to the daily cap, UpdateSpent is not expected
+	s.mBank.On("GetAccount", mockCtx, mockWalletID).Return(jointWallet(), nil).Once()
+	s.mMember.On("LockMember", mockCtx, mockWalletID, mockAccountID2).Return(spender(), nil).Once()
+	s.mApproval.On("GetPolicy", mockCtx, mockWalletID).Return(policyOver100(), nil).Once()
+	s.expectHold("")
+
+	tradeID, intent, err := s.srv.WithdrawAs(mockCtx, mockAccountID2, mockWalletID, usd(200))
+	s.Require().NoError(err)
+	s.Require().Empty(tradeID)
+	s.Require().Equal(heldIntent(""), intent)
+	s.TearDownTest()
+}
+
+func (s *testSuite) TestWithdrawAsHeldIdempotent() {
+	timeNowMs = func() int64 { return mockTimeMs }
+	getUUID = func() (string, error) { return mockIntentID, nil }
+	ctx := mdBank.WithIdempotencyKey(mockCtx, "retry-1")
+	key := mockWalletID + ":retry-1"
+	held := heldIntent("")
+	held.IdempotencyKey = key
+
+	tests := []struct {
+		Desc      string
+		Amount    money.Money
+		ExpIntent *mdApproval.Intent
+		ExpError  error
+		setup     func()
+	}{
+		{
+			Desc:      "first request, held",
+			Amount:    usd(200),
+			ExpIntent: held,
+			setup: func() {
+				s.mApproval.On("GetIdempotentIntent", ctx, key).Return(nil, approval.ErrIntentNotExist).Once()
+				s.mBank.On("CreateAccount", ctx, &mdBank.Account{
+					AccountID: mockHoldAccount,
+					Status:    mdBank.AccountStatus_ACTIVE,
+					Type:      mdBank.AccountType_HOLD,
+					Balance:   usd(0),
+				}).Return(nil).Once()
+				s.mBank.On("Trade", ctx, &mdBank.Dealing{FromAccountID: mockWalletID, ToAccountID: mockHoldAccount, Amount: usd(200), IdempotencyKey: key}).Return(mockTradeID, nil).Once()
+				s.mApproval.On("CreateIntent", ctx, held).Return(nil).Once()
+			},
+		},
+		{
+			Desc:      "retry, the held intent returned without holding again",
+			Amount:    usd(200),
+			ExpIntent: held,
+			setup: func() {
+				s.mApproval.On("GetIdempotentIntent", ctx, key).Return(held, nil).Once()
+			},
+		},
+		{
+			Desc:     "bad Path, key reused for another amount",
+			Amount:   usd(300),
+			ExpError: bank.ErrIdempotencyKeyReused,
+			setup: func() {
+				s.mApproval.On("GetIdempotentIntent", ctx, key).Return(held, nil).Once()
+			},
+		},
+	}
+
+	for _, t := range tests {
+		s.mBank.On("GetAccount", ctx, mockWalletID).Return(jointWallet(), nil).Once()
+		s.mMember.On("LockMember", ctx, mockWalletID, mockAccountID2).Return(spender(), nil).Once()
+		s.mApproval.On("GetPolicy", ctx, mockWalletID).Return(policyOver100(), nil).Once()
+		t.setup()
+		tradeID, intent, err := s.srv.WithdrawAs(ctx, mockAccountID2, mockWalletID, t.Amount)
+		s.Require().Equal(t.ExpError, err, t.Desc)
+		s.Require().Empty(tradeID, t.Desc)
+		s.Require().Equal(t.ExpIntent, intent, t.Desc)
+		s.TearDownTest()
+	}
+}
+
+func (s *testSuite) TestHold() {
+	timeNowMs = func() int64 { return mockTimeMs }
+	getUUID = func() (string, error) { return mockIntentID, nil }
+
+	tests := []struct {
+		Desc      string
+		UserID    string
+		ToAccount string
+		ExpIntent *mdApproval.Intent
+		ExpError  error
+		setup     func()
+	}{
+		{
+			Desc:      "normal Path, transfer held",
+			UserID:    mockAccountID2,
+			ToAccount: mockStranger,
+			ExpIntent: heldIntent(mockStranger),
+			setup: func() {
+				s.mMember.On("LockMember", mockCtx, mockWalletID, mockAccountID2).Return(spender(), nil).Once()
+				s.mBank.On("GetAccount", mockCtx, mockStranger).Return(&mdBank.Account{AccountID: mockStranger, Balance: usd(0)}, nil).Once()
+				s.mApproval.On("GetPolicy", mockCtx, mockWalletID).Return(policyOver100(), nil).Once()
+				s.expectHold(mockStranger)
+			},
+		},
+		{
+			Desc:     "bad Path, viewer",
+			UserID:   mockAccountID2,
+			ExpError: ErrPermissionDenied,
+			setup: func() {
+				s.mMember.On("LockMember", mockCtx, mockWalletID, mockAccountID2).Return(withRole(spender(), mdWallet.Role_VIEWER), nil).Once()
+			},
+		},
+		{
+			Desc:      "bad Path, pay to escrow account",
+			UserID:    mockAccountID2,
+			ToAccount: mockStranger,
+			ExpError:  bank.ErrAccountNotExist,
+			setup: func() {
+				s.mMember.On("LockMember", mockCtx, mockWalletID, mockAccountID2).Return(spender(), nil).Once()
+				s.mBank.On("GetAccount", mockCtx, mockStranger).Return(&mdBank.Account{AccountID: mockStranger, Type: mdBank.AccountType_ESCROW, Balance: usd(0)}, nil).Once()
+			},
+		},
+		{
+			Desc:      "bad Path, payee of another currency",
+			UserID:    mockAccountID2,
+			ToAccount: mockStranger,
+			ExpError:  bank.ErrCurrencyMismatch,
+			setup: func() {
+				s.mMember.On("LockMember", mockCtx, mockWalletID, mockAccountID2).Return(spender(), nil).Once()
+				s.mBank.On("GetAccount", mockCtx, mockStranger).Return(&mdBank.Account{AccountID: mockStranger, Balance: money.New(0, "EUR")}, nil).Once()
+				s.mApproval.On("GetPolicy", mockCtx, mockWalletID).Return(policyOver100(), nil).Once()
+			},
+		},
+		{
+			Desc:     "bad Path, within threshold",
+			UserID:   mockAccountID2,
+			ExpError: ErrApprovalNotRequired,
+			setup: func() {
+				s.mMember.On("LockMember", mockCtx, mockWalletID, mockAccountID2).Return(spender(), nil).Once()
+				policy := policyOver100()
+				policy.Threshold = usd(200)
+				s.mApproval.On("GetPolicy", mockCtx, mockWalletID).Return(policy, nil).Once()
+			},
+		},
+		{
+			Desc:     "bad Path, the requester is the only approver",
+			UserID:   mockAccountID1,
+			ExpError: ErrNotEnoughApprovers,
+			setup: func() {
+				policy := policyOver100()
+				policy.Approvers = mockAccountID1
+				s.mApproval.On("GetPolicy", mockCtx, mockWalletID).Return(policy, nil).Once()
+			},
+		},
+	}
+
+	for _, t := range tests {
+		s.mBank.On("GetAccount", mockCtx, mockWalletID).Return(jointWallet(), nil).Once()
+		t.setup()
+		intent, err := s.srv.Hold(mockCtx, t.UserID, mockWalletID, t.ToAccount, usd(200))
+		s.Require().Equal(t.ExpError, err, t.Desc)
+		s.Require().Equal(t.ExpIntent, intent, t.Desc)
+		s.TearDownTest()
+	}
+}
@@ -3,9 +3,22 @@ package wallet
 import (
 	"context"
 
+	"github.com/n3k0fi5t/wallet/app/broker"
+	mApproval "github.com/n3k0fi5t/wallet/app/models/approval"
 	mBank "github.com/n3k0fi5t/wallet/app/models/bank"
+	mWallet "github.com/n3k0fi5t/wallet/app/models/wallet"
+	"github.com/n3k0fi5t/wallet/app/money"
+	"github.com/n3k0fi5t/wallet/app/repository/approval"
 	"github.com/n3k0fi5t/wallet/app/repository/bank"
-	"github.com/sirupsen/logrus"
+	"github.com/n3k0fi5t/wallet/app/repository/member"
+	"github.com/n3k0fi5t/wallet/app/util"
+	"github.com/n3k0fi5t/wallet/common/logger"
+	"github.com/n3k0fi5t/wallet/common/sql"
+)
+
+var (
+	timeNowMs = util.TimeNowMs
+	getUUID   = util.GetUUIDv4
 )
 
 type dealCategory int
@@ -17,32 +30,36 @@ const (
 	dealTransfer
 )
 
-const (
-	PseudoAccount = "c1e395d9-8c00-4124-819a-85b0402900cf"
-)
-
-func NewWallet(b bank.Bank) Service {
+func NewWallet(t sql.Transactor, b bank.Bank, m member.Member, a approval.Approval, br broker.Broker) Service {
 	return &impl{
-		bank: b,
+		transactor: t,
+		bank:       b,
+		members:    m,
+		approvals:  a,
+		broker:     br,
 	}
 }
 
 type impl struct {
-	bank bank.Bank
+	transactor sql.Transactor
+	bank       bank.Bank
+	members    member.Member
+	approvals  approval.Approval
+	broker     broker.Broker
 }
 
-func makeDeal(acc1, acc2 string, amount int64, category dealCategory) *mBank.Dealing {
+func makeDeal(acc1, acc2 string, amount money.Money, category dealCategory) *mBank.Dealing {
 	switch category {
 	case dealDeposit:
 		return &mBank.Dealing{
-			FromAccountID: PseudoAccount,
+			FromAccountID: acc2,
 			ToAccountID:   acc1,
 			Amount:        amount,
 		}
 	case dealWithdraw:
 		return &mBank.Dealing{
 			FromAccountID: acc1,
-			ToAccountID:   PseudoAccount,
+			ToAccountID:   acc2,
 			Amount:        amount,
 		}
 	case dealTransfer:
@@ -56,46 +73,342 @@ func makeDeal(acc1, acc2 string, amount int64, category dealCategory) *mBank.Dea
 	}
 }
 
-func (im *impl) Deposit(ctx context.Context, accountID string, amount int64) (string, error) {
-	deal := makeDeal(accountID, "", amount, dealDeposit)
+// idempotencyKey scopes the client supplied key to the requesting account
+func idempotencyKey(ctx context.Context, accountID string) string {
+	key := mBank.IdempotencyKeyFrom(ctx)
+	if key == "" {
+		return ""
+	}
+	return accountID + ":" + key
+}
+
+// fundingAccount returns the system account deposits of amount to the wallet come from, in the currency of the wallet
+func (im *impl) fundingAccount(ctx context.Context, walletID string, amount money.Money) (string, error) {
+	account, err := im.bank.GetAccount(ctx, walletID)
+	if err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("bank.GetAccount failed in fundingAccount")
+		return "", err
+	} else if amount.Currency != account.Currency() {
+		return "", bank.ErrCurrencyMismatch
+	}
+	return mBank.FundingAccountID(mBank.Rail_DIRECT, account.Currency()), nil
+}
+
+func (im *impl) Deposit(ctx context.Context, accountID string, amount money.Money) (string, error) {
+	funding, err := im.fundingAccount(ctx, accountID, amount)
+	if err != nil {
+		return "", err
+	}
+
+	deal := makeDeal(accountID, funding, amount, dealDeposit)
+	deal.IdempotencyKey = idempotencyKey(ctx, accountID)
 	tradeID, err := im.bank.Trade(ctx, deal)
 	if err != nil {
-		logrus.WithField("err", err).Error("bank.Trade failed in Deposit")
+		logger.FromContext(ctx).WithField("err", err).Error("bank.Trade failed in Deposit")
 		return "", err
 	}
 
 	return tradeID, nil
 }
 
-func (im *impl) Withdraw(ctx context.Context, accountID string, amount int64) (string, error) {
-	deal := makeDeal(accountID, "", amount, dealWithdraw)
+// requiredPolicy returns the approval policy of the wallet if paying amount out of the wallet requires approvals, nil otherwise
+func (im *impl) requiredPolicy(ctx context.Context, walletID string, amount money.Money) (*mApproval.Policy, error) {
+	policy, err := im.approvals.GetPolicy(ctx, walletID)
+	if err == approval.ErrPolicyNotExist {
+		return nil, nil
+	} else if err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("approvals.GetPolicy failed in requiredPolicy")
+		return nil, err
+	}
+
+	if !policy.Requires(amount) {
+		return nil, nil
+	}
+	return policy, nil
+}
+
+func (im *impl) CheckPolicy(ctx context.Context, walletID string, amount money.Money) error {
+	policy, err := im.requiredPolicy(ctx, walletID, amount)
+	if err != nil {
+		return err
+	} else if policy != nil {
+		return ErrApprovalRequired
+	}
+	return nil
+}
+
+func (im *impl) Withdraw(ctx context.Context, accountID string, amount money.Money) (string, *mApproval.Intent, error) {
+	account, err := im.bank.GetAccount(ctx, accountID)
+	if err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("bank.GetAccount failed in Withdraw")
+		return "", nil, err
+	}
+
+	return im.withdraw(ctx, account, ownerMember(account), amount)
+}
+
+// withdraw pays amount out of the wallet to the funding account on behalf of the member, or holds it until approved
+func (im *impl) withdraw(ctx context.Context, account *mBank.Account, m *mWallet.Member, amount money.Money) (string, *mApproval.Intent, error) {
+	// the daily cap and the policy are in the currency of the wallet
+	if amount.Currency != account.Currency() {
+		return "", nil, bank.ErrCurrencyMismatch
+	}
+
+	policy, err := im.requiredPolicy(ctx, account.AccountID, amount)
+	if err != nil {
+		return "", nil, err
+	} else if policy != nil {
+		i, err := im.hold(ctx, account, m, nil, amount, policy)
+		return "", i, err
+	}
+
+	if err := im.spend(ctx, m, amount); err != nil {
+		return "", nil, err
+	}
+
+	deal := makeDeal(account.AccountID, mBank.FundingAccountID(mBank.Rail_DIRECT, account.Currency()), amount, dealWithdraw)
+	deal.IdempotencyKey = idempotencyKey(ctx, account.AccountID)
 	tradeID, err := im.bank.Trade(ctx, deal)
 	if err != nil {
-		logrus.WithField("err", err).Error("bank.Trade failed in Withdraw")
-		return "", err
+		logger.FromContext(ctx).WithField("err", err).Error("bank.Trade failed in Withdraw")
+		return "", nil, err
 	}
 
-	return tradeID, nil
+	return tradeID, nil, nil
 }
 
-func (im *impl) Transfer(ctx context.Context, from, to string, amount int64) (string, error) {
-	deal := makeDeal(from, to, amount, dealTransfer)
+func (im *impl) Transfer(ctx context.Context, from, to string, amount money.Money) (string, *mApproval.Intent, error) {
+	account, err := im.bank.GetAccount(ctx, from)
+	if err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("bank.GetAccount failed in Transfer")
+		return "", nil, err
+	}
+
+	return im.transfer(ctx, account, ownerMember(account), to, amount)
+}
+
+// transfer pays amount out of the wallet to the payee on behalf of the member, or holds it until approved
+func (im *impl) transfer(ctx context.Context, account *mBank.Account, m *mWallet.Member, to string, amount money.Money) (string, *mApproval.Intent, error) {
+	if amount.Currency != account.Currency() {
+		return "", nil, bank.ErrCurrencyMismatch
+	}
+
+	// users can only pay to users, system accounts are funded by the services owning them
+	payee, err := im.GetAccount(ctx, to)
+	if err != nil {
+		return "", nil, err
+	}
+
+	policy, err := im.requiredPolicy(ctx, account.AccountID, amount)
+	if err != nil {
+		return "", nil, err
+	} else if policy != nil {
+		i, err := im.hold(ctx, account, m, payee, amount, policy)
+		return "", i, err
+	}
+
+	if err := im.spend(ctx, m, amount); err != nil {
+		return "", nil, err
+	}
+
+	deal := makeDeal(account.AccountID, to, amount, dealTransfer)
+	deal.IdempotencyKey = idempotencyKey(ctx, account.AccountID)
 	tradeID, err := im.bank.Trade(ctx, deal)
 	if err != nil {
-		logrus.WithField("err", err).Error("bank.Trade failed in Transfer")
-		return "", err
+		logger.FromContext(ctx).WithField("err", err).Error("bank.Trade failed in Transfer")
+		return "", nil, err
 	}
 
-	return tradeID, nil
+	return tradeID, nil, nil
 }
 
 func (im *impl) GetAccount(ctx context.Context, accountID string) (*mBank.Account, error) {
 	var account *mBank.Account
 	account, err := im.bank.GetAccount(ctx, accountID)
 	if err != nil {
-		logrus.WithField("err", err).Error("bank.GetAccount failed in GetAccount")
+		logger.FromContext(ctx).WithField("err", err).Error("bank.GetAccount failed in GetAccount")
+		return nil, err
+	}
+
+	// system accounts are not wallets of users
+	if account.Type != mBank.AccountType_USER {
+		return nil, bank.ErrAccountNotExist
+	}
+	return account, nil
+}
+
+func (im *impl) ListTransactions(ctx context.Context, accountID string, offset, limit int) ([]*mBank.Transaction, error) {
+	txs, err := im.bank.ListTransactions(ctx, accountID, offset, limit)
+	if err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("bank.ListTransactions failed in ListTransactions")
+		return nil, err
+	}
+
+	return txs, nil
+}
+
+func (im *impl) GetTrade(ctx context.Context, accountID, tradeID string) ([]*mBank.Transaction, error) {
+	txs, err := im.bank.GetTrade(ctx, tradeID)
+	if err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("bank.GetTrade failed in GetTrade")
+		return nil, err
+	}
+
+	// users can only see trades they take part in
+	for _, tx := range txs {
+		if tx.AccountID == accountID {
+			return txs, nil
+		}
+	}
+	return nil, bank.ErrTradeNotExist
+}
+
+func (im *impl) Subscribe(ctx context.Context, accountID string) *broker.Subscription {
+	return im.broker.Subscribe(accountID)
+}
+
+func (im *impl) ListUpdates(ctx context.Context, accountID string, afterID int64, limit int) ([]*mBank.Update, error) {
+	updates, err := im.bank.ListUpdates(ctx, accountID, afterID, limit)
+	if err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("bank.ListUpdates failed in ListUpdates")
+		return nil, err
+	}
+
+	return updates, nil
+}
+
+func (im *impl) ListWallets(ctx context.Context, userID string) ([]*mBank.Account, error) {
+	accounts, err := im.bank.ListAccounts(ctx, userID)
+	if err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("bank.ListAccounts failed in ListWallets")
+		return nil, err
+	}
+
+	// the default wallet was opened with the user, so it is the oldest
+	wallets := make([]*mBank.Account, 0, len(accounts))
+	for _, account := range accounts {
+		if account.IsDefault() {
+			wallets = append([]*mBank.Account{account}, wallets...)
+		} else {
+			wallets = append(wallets, account)
+		}
+	}
+	return wallets, nil
+}
+
+func validWalletName(name string) bool {
+	return name != "" && len(name) <= MaxWalletNameLength
+}
+
+// nameTaken tells whether any wallet other than walletID has the name
+func nameTaken(wallets []*mBank.Account, walletID, name string) bool {
+	for _, w := range wallets {
+		if w.AccountID != walletID && w.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (im *impl) CreateWallet(ctx context.Context, userID, name, currency string) (*mBank.Account, error) {
+	if currency == "" {
+		currency = money.DefaultCurrency
+	}
+
+	if !validWalletName(name) {
+		return nil, ErrInvalidWalletName
+	} else if !money.IsValidCurrency(currency) {
+		return nil, ErrInvalidCurrency
+	}
+
+	wallets, err := im.ListWallets(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(wallets) >= MaxWallets {
+		return nil, ErrTooManyWallets
+	} else if nameTaken(wallets, "", name) {
+		return nil, ErrWalletNameTaken
+	}
+
+	walletID, err := getUUID()
+	if err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("GetUUIDv4 failed in CreateWallet")
 		return nil, err
 	}
 
+	account := &mBank.Account{
+		AccountID: walletID,
+		Status:    mBank.AccountStatus_ACTIVE,
+		Type:      mBank.AccountType_USER,
+		OwnerID:   userID,
+		Name:      name,
+		Balance:   money.New(0, currency),
+	}
+	if err := im.bank.CreateAccount(ctx, account); err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("bank.CreateAccount failed in CreateWallet")
+		return nil, err
+	}
 	return account, nil
 }
+
+// ownedWallet returns the wallet if the user owns it, wallets of others look not exist
+func ownedWallet(wallets []*mBank.Account, walletID string) (*mBank.Account, error) {
+	for _, w := range wallets {
+		if w.AccountID == walletID {
+			return w, nil
+		}
+	}
+	return nil, bank.ErrAccountNotExist
+}
+
+func (im *impl) RenameWallet(ctx context.Context, userID, walletID, name string) (*mBank.Account, error) {
+	if !validWalletName(name) {
+		return nil, ErrInvalidWalletName
+	}
+
+	wallets, err := im.ListWallets(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	wallet, err := ownedWallet(wallets, walletID)
+	if err != nil {
+		return nil, err
+	} else if nameTaken(wallets, walletID, name) {
+		return nil, ErrWalletNameTaken
+	}
+
+	if err := im.bank.RenameAccount(ctx, walletID, name); err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("bank.RenameAccount failed in RenameWallet")
+		return nil, err
+	}
+	wallet.Name = name
+	return wallet, nil
+}
+
+func (im *impl) Move(ctx context.Context, userID, from, to string, amount money.Money) (string, error) {
+	wallets, err := im.ListWallets(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	for _, walletID := range []string{from, to} {
+		if _, err := ownedWallet(wallets, walletID); err != nil {
+			return "", err
+		}
+	}
+
+	// moves are paid out of the wallet as transfers are, request approvals to move more
+	if err := im.CheckPolicy(ctx, from, amount); err != nil {
+		return "", err
+	}
+
+	deal := makeDeal(from, to, amount, dealTransfer)
+	deal.IdempotencyKey = idempotencyKey(ctx, from)
+	tradeID, err := im.bank.Trade(ctx, deal)
+	if err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("bank.Trade failed in Move")
+		return "", err
+	}
+
+	return tradeID, nil
+}
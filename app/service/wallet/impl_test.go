@@ -4,22 +4,35 @@ import (
 	"context"
 	"testing"
 
+	"github.com/n3k0fi5t/wallet/app/broker"
+	mdApproval "github.com/n3k0fi5t/wallet/app/models/approval"
 	mdBank "github.com/n3k0fi5t/wallet/app/models/bank"
+	"github.com/n3k0fi5t/wallet/app/money"
+	"github.com/n3k0fi5t/wallet/app/repository/approval"
+	mockApproval "github.com/n3k0fi5t/wallet/app/repository/approval/mocks"
 	"github.com/n3k0fi5t/wallet/app/repository/bank"
 	mockBank "github.com/n3k0fi5t/wallet/app/repository/bank/mocks"
+	mockMember "github.com/n3k0fi5t/wallet/app/repository/member/mocks"
+	"github.com/n3k0fi5t/wallet/common/sql/sqltest"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 )
 
+// usd returns amount minor units of the currency of the mock wallets
+func usd(amount int64) money.Money {
+	return money.New(amount, "USD")
+}
+
 var (
 	mockCtx        = context.Background()
 	mockAccountID1 = "n3k0fi5t"
 	mockAccountID2 = "deadbeef"
+	mockWalletID   = "a89b7b78-b9c1-4129-8cff-380bf53f3a49"
 	mockTradeID    = "935f871a-660f-4f19-801e-916c04bb0324"
 	mockDealing    = mdBank.Dealing{}
 	mockAccount    = &mdBank.Account{
 		AccountID: mockAccountID1,
-		Balance:   3345678,
+		Balance:   usd(3345678),
 	}
 
 	anyDealing = mock.AnythingOfType("*bank.Dealing")
@@ -27,13 +40,19 @@ var (
 
 type testSuite struct {
 	suite.Suite
-	srv   Service
-	mBank *mockBank.Bank
+	srv       Service
+	mBank     *mockBank.Bank
+	mMember   *mockMember.Member
+	mApproval *mockApproval.Approval
+	broker    broker.Broker
 }
 
 func (s *testSuite) SetupSuite() {
 	s.mBank = &mockBank.Bank{}
-	s.srv = NewWallet(s.mBank)
+	s.mMember = &mockMember.Member{}
+	s.mApproval = &mockApproval.Approval{}
+	s.broker = broker.NewBroker(1)
+	s.srv = NewWallet(sqltest.Transactor{}, s.mBank, s.mMember, s.mApproval, s.broker)
 }
 
 func (s *testSuite) TearDownSuite() {
@@ -44,6 +63,8 @@ func (s *testSuite) SetupTest() {
 
 func (s *testSuite) TearDownTest() {
 	s.mBank.AssertExpectations(s.T())
+	s.mMember.AssertExpectations(s.T())
+	s.mApproval.AssertExpectations(s.T())
 }
 
 func (s *testSuite) TestTransfer() {
@@ -51,7 +72,7 @@ func (s *testSuite) TestTransfer() {
 		Desc       string
 		From       string
 		To         string
-		Amount     int64
+		Amount     money.Money
 		ExpTradeID string
 		ExpError   error
 		setup      func()
@@ -60,10 +81,12 @@ func (s *testSuite) TestTransfer() {
 			Desc:       "normal Path",
 			From:       mockAccountID1,
 			To:         mockAccountID2,
-			Amount:     100,
+			Amount:     usd(100),
 			ExpTradeID: mockTradeID,
 			ExpError:   nil,
 			setup: func() {
+				s.expectPayee(mockCtx)
+				s.expectNoPolicy(mockCtx, mockAccountID1)
 				s.mBank.On("Trade", mockCtx, anyDealing).Return(mockTradeID, nil).Once()
 			},
 		},
@@ -71,21 +94,59 @@ func (s *testSuite) TestTransfer() {
 			Desc:       "bad Path account Not Exist",
 			From:       mockAccountID1,
 			To:         mockAccountID2,
-			Amount:     100,
+			Amount:     usd(100),
 			ExpTradeID: "",
 			ExpError:   bank.ErrAccountNotExist,
 			setup: func() {
+				s.expectPayee(mockCtx)
+				s.expectNoPolicy(mockCtx, mockAccountID1)
 				s.mBank.On("Trade", mockCtx, anyDealing).Return("", bank.ErrAccountNotExist).Once()
 			},
 		},
+		{
+			Desc:       "bad Path, payee Not Exist",
+			From:       mockAccountID1,
+			To:         mockAccountID2,
+			Amount:     usd(100),
+			ExpTradeID: "",
+			ExpError:   bank.ErrAccountNotExist,
+			setup: func() {
+				s.mBank.On("GetAccount", mockCtx, mockAccountID2).Return((*mdBank.Account)(nil), bank.ErrAccountNotExist).Once()
+			},
+		},
+		{
+			Desc:       "bad Path, pay to escrow account",
+			From:       mockAccountID1,
+			To:         mockAccountID2,
+			Amount:     usd(100),
+			ExpTradeID: "",
+			ExpError:   bank.ErrAccountNotExist,
+			setup: func() {
+				s.mBank.On("GetAccount", mockCtx, mockAccountID2).Return(&mdBank.Account{AccountID: mockAccountID2, Type: mdBank.AccountType_ESCROW}, nil).Once()
+			},
+		},
+		{
+			Desc:       "bad Path, approval required without approvers other than the owner",
+			From:       mockAccountID1,
+			To:         mockAccountID2,
+			Amount:     usd(100),
+			ExpTradeID: "",
+			ExpError:   ErrNotEnoughApprovers,
+			setup: func() {
+				s.expectPayee(mockCtx)
+				s.mApproval.On("GetPolicy", mockCtx, mockAccountID1).Return(&mdApproval.Policy{Threshold: usd(50), Required: 1, Approvers: mockAccountID1}, nil).Once()
+			},
+		},
 		{
 			Desc:       "bad Path, Balance Not Enough",
 			From:       mockAccountID1,
 			To:         mockAccountID2,
-			Amount:     100,
+			Amount:     usd(100),
 			ExpTradeID: "",
 			ExpError:   bank.ErrBalanceNotEnough,
 			setup: func() {
+				s.expectPayee(mockCtx)
+				s.expectNoPolicy(mockCtx, mockAccountID1)
 				s.mBank.On("Trade", mockCtx, anyDealing).Return("", bank.ErrBalanceNotEnough).Once()
 			},
 		},
@@ -93,10 +154,12 @@ func (s *testSuite) TestTransfer() {
 			Desc:       "bad Path, self transfer",
 			From:       mockAccountID1,
 			To:         mockAccountID2,
-			Amount:     100,
+			Amount:     usd(100),
 			ExpTradeID: "",
 			ExpError:   bank.ErrSelfTransfer,
 			setup: func() {
+				s.expectPayee(mockCtx)
+				s.expectNoPolicy(mockCtx, mockAccountID1)
 				s.mBank.On("Trade", mockCtx, anyDealing).Return("", bank.ErrSelfTransfer).Once()
 			},
 		},
@@ -104,10 +167,12 @@ func (s *testSuite) TestTransfer() {
 			Desc:       "bad Path,update Balance fail",
 			From:       mockAccountID1,
 			To:         mockAccountID2,
-			Amount:     100,
+			Amount:     usd(100),
 			ExpTradeID: "",
 			ExpError:   bank.ErrUpdateBalance,
 			setup: func() {
+				s.expectPayee(mockCtx)
+				s.expectNoPolicy(mockCtx, mockAccountID1)
 				s.mBank.On("Trade", mockCtx, anyDealing).Return("", bank.ErrUpdateBalance).Once()
 			},
 		},
@@ -115,10 +180,12 @@ func (s *testSuite) TestTransfer() {
 			Desc:       "bad Path, invalid dealing",
 			From:       mockAccountID1,
 			To:         mockAccountID2,
-			Amount:     100,
+			Amount:     usd(100),
 			ExpTradeID: "",
 			ExpError:   bank.ErrInvalidDealing,
 			setup: func() {
+				s.expectPayee(mockCtx)
+				s.expectNoPolicy(mockCtx, mockAccountID1)
 				s.mBank.On("Trade", mockCtx, anyDealing).Return("", bank.ErrInvalidDealing).Once()
 			},
 		},
@@ -130,7 +197,9 @@ func (s *testSuite) TestTransfer() {
 			test.setup()
 		}
 
-		tradeID, err := s.srv.Transfer(mockCtx, test.From, test.To, test.Amount)
+		s.mBank.On("GetAccount", mockCtx, test.From).Return(&mdBank.Account{AccountID: test.From, OwnerID: test.From, Balance: usd(0)}, nil).Once()
+		tradeID, intent, err := s.srv.Transfer(mockCtx, test.From, test.To, test.Amount)
+		s.Require().Nil(intent, test.Desc)
 		s.Require().Equal(test.ExpTradeID, tradeID, test.Desc)
 		s.Require().Equal(test.ExpError, err, test.Desc)
 
@@ -142,7 +211,7 @@ func (s *testSuite) TestWithdraw() {
 	tests := []struct {
 		Desc       string
 		Account    string
-		Amount     int64
+		Amount     money.Money
 		ExpTradeID string
 		ExpError   error
 		setup      func()
@@ -150,21 +219,46 @@ func (s *testSuite) TestWithdraw() {
 		{
 			Desc:       "normal Path",
 			Account:    mockAccountID1,
-			Amount:     100,
+			Amount:     usd(100),
 			ExpTradeID: mockTradeID,
 			ExpError:   nil,
 			setup: func() {
-				s.mBank.On("Trade", mockCtx, anyDealing).Return(mockTradeID, nil).Once()
+				s.expectNoPolicy(mockCtx, mockAccountID1)
+				s.expectWallet(mockCtx, mockAccountID1)
+				s.mBank.On("Trade", mockCtx, &mdBank.Dealing{FromAccountID: mockAccountID1, ToAccountID: "sys-funding-direct-USD", Amount: usd(100)}).Return(mockTradeID, nil).Once()
 			},
 		},
 		{
 			Desc:       "bad Path",
 			Account:    mockAccountID1,
-			Amount:     100,
+			Amount:     usd(100),
 			ExpTradeID: "",
 			ExpError:   bank.ErrAccountNotExist,
 			setup: func() {
-				s.mBank.On("Trade", mockCtx, anyDealing).Return("", bank.ErrAccountNotExist).Once()
+				s.mBank.On("GetAccount", mockCtx, mockAccountID1).Return(nil, bank.ErrAccountNotExist).Once()
+			},
+		},
+		{
+			Desc:       "normal Path, within threshold",
+			Account:    mockAccountID1,
+			Amount:     usd(100),
+			ExpTradeID: mockTradeID,
+			ExpError:   nil,
+			setup: func() {
+				s.mApproval.On("GetPolicy", mockCtx, mockAccountID1).Return(&mdApproval.Policy{Threshold: usd(100)}, nil).Once()
+				s.expectWallet(mockCtx, mockAccountID1)
+				s.mBank.On("Trade", mockCtx, anyDealing).Return(mockTradeID, nil).Once()
+			},
+		},
+		{
+			Desc:       "bad Path, approval required with fewer approvers than required",
+			Account:    mockAccountID1,
+			Amount:     usd(100),
+			ExpTradeID: "",
+			ExpError:   ErrNotEnoughApprovers,
+			setup: func() {
+				s.mApproval.On("GetPolicy", mockCtx, mockAccountID1).Return(&mdApproval.Policy{Threshold: usd(99), Required: 2, Approvers: mockAccountID2}, nil).Once()
+				s.expectWallet(mockCtx, mockAccountID1)
 			},
 		},
 	}
@@ -175,7 +269,8 @@ func (s *testSuite) TestWithdraw() {
 			test.setup()
 		}
 
-		tradeID, err := s.srv.Withdraw(mockCtx, test.Account, test.Amount)
+		tradeID, intent, err := s.srv.Withdraw(mockCtx, test.Account, test.Amount)
+		s.Require().Nil(intent, test.Desc)
 		s.Require().Equal(test.ExpTradeID, tradeID, test.Desc)
 		s.Require().Equal(test.ExpError, err, test.Desc)
 
@@ -225,11 +320,108 @@ func (s *testSuite) TestGetAccount() {
 	}
 }
 
+func (s *testSuite) TestListTransactions() {
+	mockTxs := []*mdBank.Transaction{
+		{AccountID: mockAccountID1, Action: mdBank.Action_INCREASE, Amount: usd(100), TradeID: mockTradeID},
+	}
+
+	tests := []struct {
+		Desc     string
+		Account  string
+		ExpTxs   []*mdBank.Transaction
+		ExpError error
+		setup    func()
+	}{
+		{
+			Desc:    "normal Path",
+			Account: mockAccountID1,
+			ExpTxs:  mockTxs,
+			setup: func() {
+				s.mBank.On("ListTransactions", mockCtx, mockAccountID1, 10, 5).Return(mockTxs, nil).Once()
+			},
+		},
+		{
+			Desc:     "bad Path",
+			Account:  mockAccountID2,
+			ExpError: bank.ErrAccountNotExist,
+			setup: func() {
+				s.mBank.On("ListTransactions", mockCtx, mockAccountID2, 10, 5).Return(nil, bank.ErrAccountNotExist).Once()
+			},
+		},
+	}
+
+	for _, test := range tests {
+		s.SetupTest()
+		if test.setup != nil {
+			test.setup()
+		}
+
+		txs, err := s.srv.ListTransactions(mockCtx, test.Account, 10, 5)
+		s.Require().Equal(test.ExpTxs, txs, test.Desc)
+		s.Require().Equal(test.ExpError, err, test.Desc)
+
+		s.TearDownTest()
+	}
+}
+
+func (s *testSuite) TestListUpdates() {
+	mockUpdates := []*mdBank.Update{
+		{ID: 7, AccountID: mockAccountID1, Action: mdBank.Action_INCREASE, Amount: usd(100), BalanceAfter: usd(300), TradeID: mockTradeID},
+	}
+
+	tests := []struct {
+		Desc       string
+		Account    string
+		ExpUpdates []*mdBank.Update
+		ExpError   error
+		setup      func()
+	}{
+		{
+			Desc:       "normal Path",
+			Account:    mockAccountID1,
+			ExpUpdates: mockUpdates,
+			setup: func() {
+				s.mBank.On("ListUpdates", mockCtx, mockAccountID1, int64(6), 10).Return(mockUpdates, nil).Once()
+			},
+		},
+		{
+			Desc:     "bad Path",
+			Account:  mockAccountID2,
+			ExpError: bank.ErrAccountNotExist,
+			setup: func() {
+				s.mBank.On("ListUpdates", mockCtx, mockAccountID2, int64(6), 10).Return(nil, bank.ErrAccountNotExist).Once()
+			},
+		},
+	}
+
+	for _, test := range tests {
+		s.SetupTest()
+		if test.setup != nil {
+			test.setup()
+		}
+
+		updates, err := s.srv.ListUpdates(mockCtx, test.Account, 6, 10)
+		s.Require().Equal(test.ExpUpdates, updates, test.Desc)
+		s.Require().Equal(test.ExpError, err, test.Desc)
+
+		s.TearDownTest()
+	}
+}
+
+func (s *testSuite) TestSubscribe() {
+	sub := s.srv.Subscribe(mockCtx, mockAccountID1)
+	defer sub.Close()
+
+	u := &mdBank.Update{ID: 8, AccountID: mockAccountID1}
+	s.broker.Publish(u, &mdBank.Update{ID: 9, AccountID: mockAccountID2})
+	s.Require().Equal(u, <-sub.Updates())
+}
+
 func (s *testSuite) TestDeposit() {
 	tests := []struct {
 		Desc       string
 		Account    string
-		Amount     int64
+		Amount     money.Money
 		ExpTradeID string
 		ExpError   error
 		setup      func()
@@ -237,21 +429,32 @@ func (s *testSuite) TestDeposit() {
 		{
 			Desc:       "normal Path",
 			Account:    mockAccountID1,
-			Amount:     100,
+			Amount:     usd(100),
 			ExpTradeID: mockTradeID,
 			ExpError:   nil,
 			setup: func() {
-				s.mBank.On("Trade", mockCtx, anyDealing).Return(mockTradeID, nil).Once()
+				s.expectWallet(mockCtx, mockAccountID1)
+				s.mBank.On("Trade", mockCtx, &mdBank.Dealing{FromAccountID: "sys-funding-direct-USD", ToAccountID: mockAccountID1, Amount: usd(100)}).Return(mockTradeID, nil).Once()
 			},
 		},
 		{
 			Desc:       "bad Path",
 			Account:    mockAccountID1,
-			Amount:     100,
+			Amount:     usd(100),
 			ExpTradeID: "",
 			ExpError:   bank.ErrAccountNotExist,
 			setup: func() {
-				s.mBank.On("Trade", mockCtx, anyDealing).Return("", bank.ErrAccountNotExist).Once()
+				s.mBank.On("GetAccount", mockCtx, mockAccountID1).Return(nil, bank.ErrAccountNotExist).Once()
+			},
+		},
+		{
+			Desc:       "bad Path, amount in another currency",
+			Account:    mockAccountID1,
+			Amount:     money.New(100, "EUR"),
+			ExpTradeID: "",
+			ExpError:   bank.ErrCurrencyMismatch,
+			setup: func() {
+				s.expectWallet(mockCtx, mockAccountID1)
 			},
 		},
 	}
@@ -270,6 +473,296 @@ func (s *testSuite) TestDeposit() {
 	}
 }
 
+// expectPayee expects the lookup of the user receiving a transfer
+func (s *testSuite) expectPayee(ctx interface{}) {
+	s.mBank.On("GetAccount", ctx, mockAccountID2).Return(&mdBank.Account{AccountID: mockAccountID2}, nil).Once()
+}
+
+// expectWallet expects the lookup of the currency of a wallet depositing or withdrawing
+func (s *testSuite) expectWallet(ctx interface{}, walletID string) {
+	s.mBank.On("GetAccount", ctx, walletID).Return(&mdBank.Account{AccountID: walletID, Balance: usd(0)}, nil).Once()
+}
+
+// expectNoPolicy expects the lookup of the approval policy of a wallet without one
+func (s *testSuite) expectNoPolicy(ctx interface{}, walletID string) {
+	s.mApproval.On("GetPolicy", ctx, walletID).Return(nil, approval.ErrPolicyNotExist).Once()
+}
+
 func TestSuite(t *testing.T) {
 	suite.Run(t, new(testSuite))
 }
+
+func (s *testSuite) TestIdempotencyKey() {
+	s.SetupTest()
+
+	ctx := mdBank.WithIdempotencyKey(mockCtx, "retry-1")
+	s.expectPayee(ctx)
+	s.expectNoPolicy(ctx, mockAccountID1)
+	s.mBank.On("Trade", ctx, &mdBank.Dealing{
+		FromAccountID:  mockAccountID1,
+		ToAccountID:    mockAccountID2,
+		Amount:         usd(100),
+		IdempotencyKey: mockAccountID1 + ":retry-1",
+	}).Return(mockTradeID, nil).Once()
+
+	s.expectWallet(ctx, mockAccountID1)
+	tradeID, _, err := s.srv.Transfer(ctx, mockAccountID1, mockAccountID2, usd(100))
+	s.Require().NoError(err)
+	s.Require().Equal(mockTradeID, tradeID)
+
+	s.TearDownTest()
+}
+
+func (s *testSuite) TestGetTrade() {
+	mockTxs := []*mdBank.Transaction{
+		{AccountID: mockAccountID1, Action: mdBank.Action_DECREASE, Amount: usd(100), TradeID: mockTradeID},
+		{AccountID: mockAccountID2, Action: mdBank.Action_INCREASE, Amount: usd(100), TradeID: mockTradeID},
+	}
+
+	tests := []struct {
+		Desc     string
+		Account  string
+		ExpTxs   []*mdBank.Transaction
+		ExpError error
+		setup    func()
+	}{
+		{
+			Desc:    "normal Path",
+			Account: mockAccountID2,
+			ExpTxs:  mockTxs,
+			setup: func() {
+				s.mBank.On("GetTrade", mockCtx, mockTradeID).Return(mockTxs, nil).Once()
+			},
+		},
+		{
+			Desc:     "bad Path, trade of others",
+			Account:  "others",
+			ExpError: bank.ErrTradeNotExist,
+			setup: func() {
+				s.mBank.On("GetTrade", mockCtx, mockTradeID).Return(mockTxs, nil).Once()
+			},
+		},
+		{
+			Desc:     "bad Path, trade not exist",
+			Account:  mockAccountID1,
+			ExpError: bank.ErrTradeNotExist,
+			setup: func() {
+				s.mBank.On("GetTrade", mockCtx, mockTradeID).Return(nil, bank.ErrTradeNotExist).Once()
+			},
+		},
+	}
+
+	for _, test := range tests {
+		s.SetupTest()
+		if test.setup != nil {
+			test.setup()
+		}
+
+		txs, err := s.srv.GetTrade(mockCtx, test.Account, mockTradeID)
+		s.Require().Equal(test.ExpTxs, txs, test.Desc)
+		s.Require().Equal(test.ExpError, err, test.Desc)
+
+		s.TearDownTest()
+	}
+}
+
+// ownedWallets are the default wallet and a savings wallet of mockAccountID1, listed oldest first
+func ownedWallets() []*mdBank.Account {
+	return []*mdBank.Account{
+		{AccountID: mockAccountID1, OwnerID: mockAccountID1, Name: "Main", Balance: usd(100)},
+		{AccountID: mockWalletID, OwnerID: mockAccountID1, Name: "Savings"},
+	}
+}
+
+func (s *testSuite) TestListWallets() {
+	// the default wallet comes first whatever the order of listing
+	wallets := ownedWallets()
+	s.mBank.On("ListAccounts", mockCtx, mockAccountID1).Return([]*mdBank.Account{wallets[1], wallets[0]}, nil).Once()
+
+	res, err := s.srv.ListWallets(mockCtx, mockAccountID1)
+	s.Require().NoError(err)
+	s.Require().Equal(wallets, res)
+}
+
+func (s *testSuite) TestCreateWallet() {
+	getUUID = func() (string, error) { return mockTradeID, nil }
+	full := []*mdBank.Account{}
+	for i := 0; i < MaxWallets; i++ {
+		full = append(full, &mdBank.Account{AccountID: string(rune('a' + i)), OwnerID: mockAccountID1})
+	}
+
+	tests := []struct {
+		Desc      string
+		Name      string
+		Currency  string
+		ExpWallet *mdBank.Account
+		ExpError  error
+		setup     func()
+	}{
+		{
+			Desc:      "normal Path",
+			Name:      "Travel",
+			ExpWallet: &mdBank.Account{AccountID: mockTradeID, OwnerID: mockAccountID1, Name: "Travel", Balance: usd(0)},
+			setup: func() {
+				s.mBank.On("ListAccounts", mockCtx, mockAccountID1).Return(ownedWallets(), nil).Once()
+				s.mBank.On("CreateAccount", mockCtx, &mdBank.Account{AccountID: mockTradeID, OwnerID: mockAccountID1, Name: "Travel", Balance: usd(0)}).Return(nil).Once()
+			},
+		},
+		{
+			Desc:      "normal Path, other currency",
+			Name:      "Travel",
+			Currency:  "JPY",
+			ExpWallet: &mdBank.Account{AccountID: mockTradeID, OwnerID: mockAccountID1, Name: "Travel", Balance: money.New(0, "JPY")},
+			setup: func() {
+				s.mBank.On("ListAccounts", mockCtx, mockAccountID1).Return(ownedWallets(), nil).Once()
+				s.mBank.On("CreateAccount", mockCtx, &mdBank.Account{AccountID: mockTradeID, OwnerID: mockAccountID1, Name: "Travel", Balance: money.New(0, "JPY")}).Return(nil).Once()
+			},
+		},
+		{
+			Desc:     "bad Path, unknown currency",
+			Name:     "Travel",
+			Currency: "XYZ",
+			ExpError: ErrInvalidCurrency,
+			setup:    func() {},
+		},
+		{
+			Desc:     "bad Path, empty name",
+			Name:     "",
+			ExpError: ErrInvalidWalletName,
+			setup:    func() {},
+		},
+		{
+			Desc:     "bad Path, name taken",
+			Name:     "Savings",
+			ExpError: ErrWalletNameTaken,
+			setup: func() {
+				s.mBank.On("ListAccounts", mockCtx, mockAccountID1).Return(ownedWallets(), nil).Once()
+			},
+		},
+		{
+			Desc:     "bad Path, too many wallets",
+			Name:     "Travel",
+			ExpError: ErrTooManyWallets,
+			setup: func() {
+				s.mBank.On("ListAccounts", mockCtx, mockAccountID1).Return(full, nil).Once()
+			},
+		},
+	}
+
+	for _, t := range tests {
+		t.setup()
+		wallet, err := s.srv.CreateWallet(mockCtx, mockAccountID1, t.Name, t.Currency)
+		s.Require().Equal(t.ExpError, err, t.Desc)
+		s.Require().Equal(t.ExpWallet, wallet, t.Desc)
+		s.TearDownTest()
+	}
+}
+
+func (s *testSuite) TestRenameWallet() {
+	tests := []struct {
+		Desc     string
+		WalletID string
+		Name     string
+		ExpError error
+		setup    func()
+	}{
+		{
+			Desc:     "normal Path",
+			WalletID: mockWalletID,
+			Name:     "Rainy day",
+			setup: func() {
+				s.mBank.On("ListAccounts", mockCtx, mockAccountID1).Return(ownedWallets(), nil).Once()
+				s.mBank.On("RenameAccount", mockCtx, mockWalletID, "Rainy day").Return(nil).Once()
+			},
+		},
+		{
+			Desc:     "normal Path, same name",
+			WalletID: mockWalletID,
+			Name:     "Savings",
+			setup: func() {
+				s.mBank.On("ListAccounts", mockCtx, mockAccountID1).Return(ownedWallets(), nil).Once()
+				s.mBank.On("RenameAccount", mockCtx, mockWalletID, "Savings").Return(nil).Once()
+			},
+		},
+		{
+			Desc:     "bad Path, name taken",
+			WalletID: mockWalletID,
+			Name:     "Main",
+			ExpError: ErrWalletNameTaken,
+			setup: func() {
+				s.mBank.On("ListAccounts", mockCtx, mockAccountID1).Return(ownedWallets(), nil).Once()
+			},
+		},
+		{
+			Desc:     "bad Path, wallet of others",
+			WalletID: mockAccountID2,
+			Name:     "Mine",
+			ExpError: bank.ErrAccountNotExist,
+			setup: func() {
+				s.mBank.On("ListAccounts", mockCtx, mockAccountID1).Return(ownedWallets(), nil).Once()
+			},
+		},
+	}
+
+	for _, t := range tests {
+		t.setup()
+		wallet, err := s.srv.RenameWallet(mockCtx, mockAccountID1, t.WalletID, t.Name)
+		s.Require().Equal(t.ExpError, err, t.Desc)
+		if err == nil {
+			s.Require().Equal(t.Name, wallet.Name, t.Desc)
+		}
+		s.TearDownTest()
+	}
+}
+
+func (s *testSuite) TestMove() {
+	tests := []struct {
+		Desc       string
+		To         string
+		ExpTradeID string
+		ExpError   error
+		setup      func()
+	}{
+		{
+			Desc:       "normal Path",
+			To:         mockWalletID,
+			ExpTradeID: mockTradeID,
+			setup: func() {
+				s.expectNoPolicy(mockCtx, mockAccountID1)
+				s.mBank.On("Trade", mockCtx, &mdBank.Dealing{FromAccountID: mockAccountID1, ToAccountID: mockWalletID, Amount: usd(100)}).Return(mockTradeID, nil).Once()
+			},
+		},
+		{
+			Desc:     "bad Path, move to others",
+			To:       mockAccountID2,
+			ExpError: bank.ErrAccountNotExist,
+			setup:    func() {},
+		},
+		{
+			Desc:     "bad Path, approval required",
+			To:       mockWalletID,
+			ExpError: ErrApprovalRequired,
+			setup: func() {
+				s.mApproval.On("GetPolicy", mockCtx, mockAccountID1).Return(&mdApproval.Policy{Threshold: usd(50)}, nil).Once()
+			},
+		},
+		{
+			Desc:     "bad Path, balance not enough",
+			To:       mockWalletID,
+			ExpError: bank.ErrBalanceNotEnough,
+			setup: func() {
+				s.expectNoPolicy(mockCtx, mockAccountID1)
+				s.mBank.On("Trade", mockCtx, anyDealing).Return("", bank.ErrBalanceNotEnough).Once()
+			},
+		},
+	}
+
+	for _, t := range tests {
+		s.mBank.On("ListAccounts", mockCtx, mockAccountID1).Return(ownedWallets(), nil).Once()
+		t.setup()
+		tradeID, err := s.srv.Move(mockCtx, mockAccountID1, mockAccountID1, t.To, usd(100))
+		s.Require().Equal(t.ExpError, err, t.Desc)
+		s.Require().Equal(t.ExpTradeID, tradeID, t.Desc)
+		s.TearDownTest()
+	}
+}
@@ -0,0 +1,223 @@
+package wallet
+
+import (
+	"context"
+
+	mApproval "github.com/n3k0fi5t/wallet/app/models/approval"
+	mBank "github.com/n3k0fi5t/wallet/app/models/bank"
+	mWallet "github.com/n3k0fi5t/wallet/app/models/wallet"
+	"github.com/n3k0fi5t/wallet/app/money"
+	"github.com/n3k0fi5t/wallet/app/repository/bank"
+	"github.com/n3k0fi5t/wallet/app/repository/member"
+	"github.com/n3k0fi5t/wallet/common/logger"
+)
+
+// ownerMember is the implicit membership of the user opening the wallet
+func ownerMember(account *mBank.Account) *mWallet.Member {
+	return &mWallet.Member{
+		WalletID:   account.AccountID,
+		UserID:     account.OwnerID,
+		Role:       mWallet.Role_OWNER,
+		DailyCap:   money.New(0, account.Currency()),
+		SpentToday: money.New(0, account.Currency()),
+	}
+}
+
+// membership returns the wallet and the membership of the user on it, lock holds the membership until
+// the transaction carried by context ends. Wallets the user is not a member of look not exist
+func (im *impl) membership(ctx context.Context, userID, walletID string, lock bool) (*mBank.Account, *mWallet.Member, error) {
+	account, err := im.GetAccount(ctx, walletID)
+	if err != nil {
+		return nil, nil, err
+	} else if account.OwnerID == userID {
+		return account, ownerMember(account), nil
+	}
+
+	get := im.members.GetMember
+	if lock {
+		get = im.members.LockMember
+	}
+	m, err := get(ctx, walletID, userID)
+	if err == member.ErrMemberNotExist {
+		return nil, nil, bank.ErrAccountNotExist
+	} else if err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("member.GetMember failed in membership")
+		return nil, nil, err
+	}
+	return account, m, nil
+}
+
+func (im *impl) Authorize(ctx context.Context, userID, walletID string) (*mWallet.Member, error) {
+	_, m, err := im.membership(ctx, userID, walletID, false)
+	return m, err
+}
+
+// spender locks the membership of the user on the wallet until the transaction carried by context ends, the member
+// must be allowed to spend
+func (im *impl) spender(ctx context.Context, userID, walletID string) (*mBank.Account, *mWallet.Member, error) {
+	account, m, err := im.membership(ctx, userID, walletID, true)
+	if err != nil {
+		return nil, nil, err
+	} else if !m.Role.CanSpend() {
+		return nil, nil, ErrPermissionDenied
+	}
+	return account, m, nil
+}
+
+// spend charges amount to the daily cap of the member locked by spender, the caller pays out in the same transaction.
+// Replays of idempotent requests were charged the first time, they are not charged again
+func (im *impl) spend(ctx context.Context, m *mWallet.Member, amount money.Money) error {
+	if m.Role != mWallet.Role_SPENDER {
+		return nil
+	}
+
+	// the membership lock orders retries with the same key, the first one has committed its trade by now
+	if key := idempotencyKey(ctx, m.WalletID); key != "" {
+		if _, err := im.bank.GetIdempotentTrade(ctx, key); err == nil {
+			return nil
+		} else if err != bank.ErrTradeNotExist {
+			logger.FromContext(ctx).WithField("err", err).Error("bank.GetIdempotentTrade failed in spend")
+			return err
+		}
+	}
+
+	nowMs := timeNowMs()
+	if m.Capped(nowMs, amount) {
+		return ErrDailyCapExceeded
+	}
+	spent, err := m.Spent(nowMs).Add(amount)
+	if err != nil {
+		return err
+	}
+	m.SpentToday = spent
+	m.SpentDay = nowMs / mWallet.DayMs
+	m.UpdatedMs = nowMs
+	if err := im.members.UpdateSpent(ctx, m); err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("member.UpdateSpent failed in spend")
+		return err
+	}
+	return nil
+}
+
+func (im *impl) WithdrawAs(ctx context.Context, userID, walletID string, amount money.Money) (string, *mApproval.Intent, error) {
+	tradeID, intent := "", (*mApproval.Intent)(nil)
+	err := im.transactor.Transact(ctx, func(ctx context.Context) error {
+		account, m, err := im.spender(ctx, userID, walletID)
+		if err != nil {
+			return err
+		}
+
+		tradeID, intent, err = im.withdraw(ctx, account, m, amount)
+		return err
+	})
+	return tradeID, intent, err
+}
+
+func (im *impl) TransferAs(ctx context.Context, userID, from, to string, amount money.Money) (string, *mApproval.Intent, error) {
+	tradeID, intent := "", (*mApproval.Intent)(nil)
+	err := im.transactor.Transact(ctx, func(ctx context.Context) error {
+		account, m, err := im.spender(ctx, userID, from)
+		if err != nil {
+			return err
+		}
+
+		tradeID, intent, err = im.transfer(ctx, account, m, to, amount)
+		return err
+	})
+	return tradeID, intent, err
+}
+
+func (im *impl) ListMembers(ctx context.Context, userID, walletID string) ([]*mWallet.Member, error) {
+	account, _, err := im.membership(ctx, userID, walletID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := im.members.ListMembers(ctx, walletID)
+	if err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("member.ListMembers failed in ListMembers")
+		return nil, err
+	}
+	return append([]*mWallet.Member{ownerMember(account)}, members...), nil
+}
+
+func (im *impl) SetMember(ctx context.Context, userID, walletID, memberID string, role mWallet.Role, dailyCap money.Money) (*mWallet.Member, error) {
+	if !role.IsValid() || dailyCap.IsNegative() {
+		return nil, ErrInvalidMember
+	}
+
+	account, m, err := im.membership(ctx, userID, walletID, false)
+	if err != nil {
+		return nil, err
+	} else if dailyCap.Currency != account.Currency() {
+		return nil, bank.ErrCurrencyMismatch
+	} else if !m.Role.CanManage() {
+		return nil, ErrPermissionDenied
+	} else if memberID == account.OwnerID {
+		return nil, ErrWalletOwner
+	}
+
+	// members are users, who have their default wallets
+	if _, err := im.GetAccount(ctx, mBank.DefaultWalletID(memberID)); err != nil {
+		return nil, err
+	}
+
+	members, err := im.members.ListMembers(ctx, walletID)
+	if err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("member.ListMembers failed in SetMember")
+		return nil, err
+	}
+	nowMs := timeNowMs()
+	saved := &mWallet.Member{
+		WalletID:   walletID,
+		UserID:     memberID,
+		SpentToday: money.New(0, account.Currency()),
+		CreatedMs:  nowMs,
+	}
+	for _, existing := range members {
+		if existing.UserID == memberID {
+			saved = existing
+		}
+	}
+	if saved.ID == 0 && len(members) >= MaxMembers {
+		return nil, ErrTooManyMembers
+	}
+
+	saved.Role = role
+	saved.DailyCap = dailyCap
+	saved.UpdatedMs = nowMs
+	if err := im.members.SaveMember(ctx, saved); err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("member.SaveMember failed in SetMember")
+		return nil, err
+	}
+	return saved, nil
+}
+
+func (im *impl) RemoveMember(ctx context.Context, userID, walletID, memberID string) error {
+	account, m, err := im.membership(ctx, userID, walletID, false)
+	if err != nil {
+		return err
+	} else if memberID == account.OwnerID {
+		return ErrWalletOwner
+	} else if memberID != userID && !m.Role.CanManage() {
+		return ErrPermissionDenied
+	}
+
+	if _, err := im.members.GetMember(ctx, walletID, memberID); err != nil {
+		return err
+	}
+	if err := im.members.RemoveMember(ctx, walletID, memberID); err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("member.RemoveMember failed in RemoveMember")
+		return err
+	}
+	return nil
+}
+
+func (im *impl) ListMemberships(ctx context.Context, userID string) ([]*mWallet.Member, error) {
+	members, err := im.members.ListMemberships(ctx, userID)
+	if err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("member.ListMemberships failed in ListMemberships")
+		return nil, err
+	}
+	return members, nil
+}
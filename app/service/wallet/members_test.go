@@ -0,0 +1,410 @@
+package wallet
+
+import (
+	mdBank "github.com/n3k0fi5t/wallet/app/models/bank"
+	mdWallet "github.com/n3k0fi5t/wallet/app/models/wallet"
+	"github.com/n3k0fi5t/wallet/app/money"
+	"github.com/n3k0fi5t/wallet/app/repository/bank"
+	"github.com/n3k0fi5t/wallet/app/repository/member"
+)
+
+var (
+	mockTimeMs   = int64(1650000000000)
+	mockToday    = mockTimeMs / mdWallet.DayMs
+	mockStranger = "badc0ffee"
+)
+
+// jointWallet is opened by account 1 and shared with account 2
+func jointWallet() *mdBank.Account {
+	return &mdBank.Account{AccountID: mockWalletID, OwnerID: mockAccountID1, Name: "Household", Balance: usd(1000)}
+}
+
+// spender can pay out 300 a day, 100 of which is spent today
+func spender() *mdWallet.Member {
+	return &mdWallet.Member{
+		ID:         1,
+		WalletID:   mockWalletID,
+		UserID:     mockAccountID2,
+		Role:       mdWallet.Role_SPENDER,
+		DailyCap:   usd(300),
+		SpentToday: usd(100),
+		SpentDay:   mockToday,
+	}
+}
+
+func withRole(m *mdWallet.Member, role mdWallet.Role) *mdWallet.Member {
+	m.Role = role
+	return m
+}
+
+func (s *testSuite) TestAuthorize() {
+	tests := []struct {
+		Desc     string
+		UserID   string
+		ExpRole  mdWallet.Role
+		ExpError error
+		setup    func()
+	}{
+		{
+			Desc:    "normal Path, owner",
+			UserID:  mockAccountID1,
+			ExpRole: mdWallet.Role_OWNER,
+			setup:   func() {},
+		},
+		{
+			Desc:    "normal Path, member",
+			UserID:  mockAccountID2,
+			ExpRole: mdWallet.Role_SPENDER,
+			setup: func() {
+				s.mMember.On("GetMember", mockCtx, mockWalletID, mockAccountID2).Return(spender(), nil).Once()
+			},
+		},
+		{
+			Desc:     "bad Path, not member",
+			UserID:   mockStranger,
+			ExpError: bank.ErrAccountNotExist,
+			setup: func() {
+				s.mMember.On("GetMember", mockCtx, mockWalletID, mockStranger).Return(nil, member.ErrMemberNotExist).Once()
+			},
+		},
+	}
+
+	for _, t := range tests {
+		s.mBank.On("GetAccount", mockCtx, mockWalletID).Return(jointWallet(), nil).Once()
+		t.setup()
+		m, err := s.srv.Authorize(mockCtx, t.UserID, mockWalletID)
+		s.Require().Equal(t.ExpError, err, t.Desc)
+		if err == nil {
+			s.Require().Equal(t.ExpRole, m.Role, t.Desc)
+		}
+		s.TearDownTest()
+	}
+}
+
+func (s *testSuite) TestWithdrawAs() {
+	timeNowMs = func() int64 { return mockTimeMs }
+	withdrawal := &mdBank.Dealing{FromAccountID: mockWalletID, ToAccountID: "sys-funding-direct-USD", Amount: usd(200)}
+
+	tests := []struct {
+		Desc       string
+		UserID     string
+		ExpTradeID string
+		ExpError   error
+		setup      func()
+	}{
+		{
+			Desc:       "normal Path, owner without cap",
+			UserID:     mockAccountID1,
+			ExpTradeID: mockTradeID,
+			setup: func() {
+				s.expectNoPolicy(mockCtx, mockWalletID)
+				s.mBank.On("Trade", mockCtx, withdrawal).Return(mockTradeID, nil).Once()
+			},
+		},
+		{
+			Desc:       "normal Path, spender within cap",
+			UserID:     mockAccountID2,
+			ExpTradeID: mockTradeID,
+			setup: func() {
+				charged := spender()
+				charged.SpentToday, charged.UpdatedMs = usd(300), mockTimeMs
+				s.mMember.On("LockMember", mockCtx, mockWalletID, mockAccountID2).Return(spender(), nil).Once()
+				s.mMember.On("UpdateSpent", mockCtx, charged).Return(nil).Once()
+				s.expectNoPolicy(mockCtx, mockWalletID)
+				s.mBank.On("Trade", mockCtx, withdrawal).Return(mockTradeID, nil).Once()
+			},
+		},
+		{
+			Desc:       "normal Path, cap of yesterday",
+			UserID:     mockAccountID2,
+			ExpTradeID: mockTradeID,
+			setup: func() {
+				yesterday := spender()
+				yesterday.SpentToday, yesterday.SpentDay = usd(300), mockToday-1
+				charged := spender()
+				charged.SpentToday, charged.UpdatedMs = usd(200), mockTimeMs
+				s.mMember.On("LockMember", mockCtx, mockWalletID, mockAccountID2).Return(yesterday, nil).Once()
+				s.mMember.On("UpdateSpent", mockCtx, charged).Return(nil).Once()
+				s.expectNoPolicy(mockCtx, mockWalletID)
+				s.mBank.On("Trade", mockCtx, withdrawal).Return(mockTradeID, nil).Once()
+			},
+		},
+		{
+			Desc:     "bad Path, cap exceeded",
+			UserID:   mockAccountID2,
+			ExpError: ErrDailyCapExceeded,
+			setup: func() {
+				spent := spender()
+				spent.SpentToday = usd(150)
+				s.mMember.On("LockMember", mockCtx, mockWalletID, mockAccountID2).Return(spent, nil).Once()
+				s.expectNoPolicy(mockCtx, mockWalletID)
+			},
+		},
+		{
+			Desc:     "bad Path, viewer",
+			UserID:   mockAccountID2,
+			ExpError: ErrPermissionDenied,
+			setup: func() {
+				s.mMember.On("LockMember", mockCtx, mockWalletID, mockAccountID2).Return(withRole(spender(), mdWallet.Role_VIEWER), nil).Once()
+			},
+		},
+		{
+			Desc:     "bad Path, not member",
+			UserID:   mockStranger,
+			ExpError: bank.ErrAccountNotExist,
+			setup: func() {
+				s.mMember.On("LockMember", mockCtx, mockWalletID, mockStranger).Return(nil, member.ErrMemberNotExist).Once()
+			},
+		},
+	}
+
+	for _, t := range tests {
+		s.mBank.On("GetAccount", mockCtx, mockWalletID).Return(jointWallet(), nil).Once()
+		t.setup()
+		tradeID, _, err := s.srv.WithdrawAs(mockCtx, t.UserID, mockWalletID, usd(200))
+		s.Require().Equal(t.ExpError, err, t.Desc)
+		s.Require().Equal(t.ExpTradeID, tradeID, t.Desc)
+		s.TearDownTest()
+	}
+}
+
+func (s *testSuite) TestWithdrawAsIdempotent() {
+	timeNowMs = func() int64 { return mockTimeMs }
+	ctx := mdBank.WithIdempotencyKey(mockCtx, "retry-1")
+	key := mockWalletID + ":retry-1"
+	withdrawal := &mdBank.Dealing{FromAccountID: mockWalletID, ToAccountID: "sys-funding-direct-USD", Amount: usd(200), IdempotencyKey: key}
+
+	tests := []struct {
+		Desc  string
+		setup func()
+	}{
+		{
+			Desc: "first request, cap charged",
+			setup: func() {
+				charged := spender()
+				charged.SpentToday, charged.UpdatedMs = usd(300), mockTimeMs
+				s.mBank.On("GetIdempotentTrade", ctx, key).Return("", bank.ErrTradeNotExist).Once()
+				s.mMember.On("UpdateSpent", ctx, charged).Return(nil).Once()
+			},
+		},
+		{
+			Desc: "replay, cap not charged again",
+			setup: func() {
+				s.mBank.On("GetIdempotentTrade", ctx, key).Return(mockTradeID, nil).Once()
+			},
+		},
+	}
+
+	for _, t := range tests {
+		s.mBank.On("GetAccount", ctx, mockWalletID).Return(jointWallet(), nil).Once()
+		s.mMember.On("LockMember", ctx, mockWalletID, mockAccountID2).Return(spender(), nil).Once()
+		t.setup()
+		s.expectNoPolicy(ctx, mockWalletID)
+		s.mBank.On("Trade", ctx, withdrawal).Return(mockTradeID, nil).Once()
+
+		tradeID, _, err := s.srv.WithdrawAs(ctx, mockAccountID2, mockWalletID, usd(200))
+		s.Require().NoError(err, t.Desc)
+		s.Require().Equal(mockTradeID, tradeID, t.Desc)
+		s.TearDownTest()
+	}
+}
+
+func (s *testSuite) TestTransferAs() {
+	timeNowMs = func() int64 { return mockTimeMs }
+
+	// the payee is checked once the member is charged
+	charged := spender()
+	charged.SpentToday, charged.UpdatedMs = usd(150), mockTimeMs
+	s.mBank.On("GetAccount", mockCtx, mockWalletID).Return(jointWallet(), nil).Once()
+	s.mMember.On("LockMember", mockCtx, mockWalletID, mockAccountID2).Return(spender(), nil).Once()
+	s.mMember.On("UpdateSpent", mockCtx, charged).Return(nil).Once()
+	s.mBank.On("GetAccount", mockCtx, mockStranger).Return(&mdBank.Account{AccountID: mockStranger}, nil).Once()
+	s.expectNoPolicy(mockCtx, mockWalletID)
+	s.mBank.On("Trade", mockCtx, &mdBank.Dealing{FromAccountID: mockWalletID, ToAccountID: mockStranger, Amount: usd(50)}).Return(mockTradeID, nil).Once()
+
+	tradeID, _, err := s.srv.TransferAs(mockCtx, mockAccountID2, mockWalletID, mockStranger, usd(50))
+	s.Require().NoError(err)
+	s.Require().Equal(mockTradeID, tradeID)
+	s.TearDownTest()
+}
+
+func (s *testSuite) TestListMembers() {
+	s.mBank.On("GetAccount", mockCtx, mockWalletID).Return(jointWallet(), nil).Once()
+	s.mMember.On("GetMember", mockCtx, mockWalletID, mockAccountID2).Return(spender(), nil).Once()
+	s.mMember.On("ListMembers", mockCtx, mockWalletID).Return([]*mdWallet.Member{spender()}, nil).Once()
+
+	members, err := s.srv.ListMembers(mockCtx, mockAccountID2, mockWalletID)
+	s.Require().NoError(err)
+	s.Require().Equal([]*mdWallet.Member{
+		{WalletID: mockWalletID, UserID: mockAccountID1, Role: mdWallet.Role_OWNER, DailyCap: usd(0), SpentToday: usd(0)},
+		spender(),
+	}, members)
+	s.TearDownTest()
+}
+
+func (s *testSuite) TestSetMember() {
+	timeNowMs = func() int64 { return mockTimeMs }
+	full := []*mdWallet.Member{}
+	for i := 0; i < MaxMembers; i++ {
+		full = append(full, &mdWallet.Member{ID: i + 1, UserID: string(rune('a' + i))})
+	}
+
+	tests := []struct {
+		Desc      string
+		UserID    string
+		MemberID  string
+		Role      mdWallet.Role
+		ExpMember *mdWallet.Member
+		ExpError  error
+		setup     func()
+	}{
+		{
+			Desc:      "normal Path, add member",
+			UserID:    mockAccountID1,
+			MemberID:  mockStranger,
+			Role:      mdWallet.Role_VIEWER,
+			ExpMember: &mdWallet.Member{WalletID: mockWalletID, UserID: mockStranger, Role: mdWallet.Role_VIEWER, DailyCap: usd(0), SpentToday: usd(0), CreatedMs: mockTimeMs, UpdatedMs: mockTimeMs},
+			setup: func() {
+				s.mBank.On("GetAccount", mockCtx, mockStranger).Return(&mdBank.Account{AccountID: mockStranger}, nil).Once()
+				s.mMember.On("ListMembers", mockCtx, mockWalletID).Return([]*mdWallet.Member{spender()}, nil).Once()
+				s.mMember.On("SaveMember", mockCtx, &mdWallet.Member{WalletID: mockWalletID, UserID: mockStranger, Role: mdWallet.Role_VIEWER, DailyCap: usd(0), SpentToday: usd(0), CreatedMs: mockTimeMs, UpdatedMs: mockTimeMs}).Return(nil).Once()
+			},
+		},
+		{
+			Desc:      "normal Path, update member keeps spending",
+			UserID:    mockAccountID1,
+			MemberID:  mockAccountID2,
+			Role:      mdWallet.Role_OWNER,
+			ExpMember: &mdWallet.Member{ID: 1, WalletID: mockWalletID, UserID: mockAccountID2, Role: mdWallet.Role_OWNER, DailyCap: usd(0), SpentToday: usd(100), SpentDay: mockToday, UpdatedMs: mockTimeMs},
+			setup: func() {
+				s.mBank.On("GetAccount", mockCtx, mockAccountID2).Return(&mdBank.Account{AccountID: mockAccountID2}, nil).Once()
+				s.mMember.On("ListMembers", mockCtx, mockWalletID).Return([]*mdWallet.Member{spender()}, nil).Once()
+				s.mMember.On("SaveMember", mockCtx, &mdWallet.Member{ID: 1, WalletID: mockWalletID, UserID: mockAccountID2, Role: mdWallet.Role_OWNER, DailyCap: usd(0), SpentToday: usd(100), SpentDay: mockToday, UpdatedMs: mockTimeMs}).Return(nil).Once()
+			},
+		},
+		{
+			Desc:     "bad Path, too many members",
+			UserID:   mockAccountID1,
+			MemberID: mockStranger,
+			Role:     mdWallet.Role_VIEWER,
+			ExpError: ErrTooManyMembers,
+			setup: func() {
+				s.mBank.On("GetAccount", mockCtx, mockStranger).Return(&mdBank.Account{AccountID: mockStranger}, nil).Once()
+				s.mMember.On("ListMembers", mockCtx, mockWalletID).Return(full, nil).Once()
+			},
+		},
+		{
+			Desc:     "bad Path, user not exist",
+			UserID:   mockAccountID1,
+			MemberID: mockStranger,
+			Role:     mdWallet.Role_VIEWER,
+			ExpError: bank.ErrAccountNotExist,
+			setup: func() {
+				s.mBank.On("GetAccount", mockCtx, mockStranger).Return(nil, bank.ErrAccountNotExist).Once()
+			},
+		},
+		{
+			Desc:     "bad Path, spender manages",
+			UserID:   mockAccountID2,
+			MemberID: mockStranger,
+			Role:     mdWallet.Role_VIEWER,
+			ExpError: ErrPermissionDenied,
+			setup: func() {
+				s.mMember.On("GetMember", mockCtx, mockWalletID, mockAccountID2).Return(spender(), nil).Once()
+			},
+		},
+		{
+			Desc:     "bad Path, wallet owner",
+			UserID:   mockAccountID1,
+			MemberID: mockAccountID1,
+			Role:     mdWallet.Role_VIEWER,
+			ExpError: ErrWalletOwner,
+			setup:    func() {},
+		},
+	}
+
+	for _, t := range tests {
+		s.mBank.On("GetAccount", mockCtx, mockWalletID).Return(jointWallet(), nil).Once()
+		t.setup()
+		m, err := s.srv.SetMember(mockCtx, t.UserID, mockWalletID, t.MemberID, t.Role, usd(0))
+		s.Require().Equal(t.ExpError, err, t.Desc)
+		s.Require().Equal(t.ExpMember, m, t.Desc)
+		s.TearDownTest()
+	}
+
+	// role and cap are checked before anything else
+	_, err := s.srv.SetMember(mockCtx, mockAccountID1, mockWalletID, mockStranger, "ADMIN", usd(0))
+	s.Require().Equal(ErrInvalidMember, err)
+	_, err = s.srv.SetMember(mockCtx, mockAccountID1, mockWalletID, mockStranger, mdWallet.Role_SPENDER, usd(-1))
+	s.Require().Equal(ErrInvalidMember, err)
+
+	// the cap is in the currency of the wallet
+	s.mBank.On("GetAccount", mockCtx, mockWalletID).Return(jointWallet(), nil).Once()
+	_, err = s.srv.SetMember(mockCtx, mockAccountID1, mockWalletID, mockStranger, mdWallet.Role_SPENDER, money.New(100, "EUR"))
+	s.Require().Equal(bank.ErrCurrencyMismatch, err)
+	s.TearDownTest()
+}
+
+func (s *testSuite) TestRemoveMember() {
+	tests := []struct {
+		Desc     string
+		UserID   string
+		MemberID string
+		ExpError error
+		setup    func()
+	}{
+		{
+			Desc:     "normal Path, owner removes",
+			UserID:   mockAccountID1,
+			MemberID: mockAccountID2,
+			setup: func() {
+				s.mMember.On("GetMember", mockCtx, mockWalletID, mockAccountID2).Return(spender(), nil).Once()
+				s.mMember.On("RemoveMember", mockCtx, mockWalletID, mockAccountID2).Return(nil).Once()
+			},
+		},
+		{
+			Desc:     "normal Path, member leaves",
+			UserID:   mockAccountID2,
+			MemberID: mockAccountID2,
+			setup: func() {
+				s.mMember.On("GetMember", mockCtx, mockWalletID, mockAccountID2).Return(spender(), nil).Twice()
+				s.mMember.On("RemoveMember", mockCtx, mockWalletID, mockAccountID2).Return(nil).Once()
+			},
+		},
+		{
+			Desc:     "bad Path, spender removes others",
+			UserID:   mockAccountID2,
+			MemberID: mockStranger,
+			ExpError: ErrPermissionDenied,
+			setup: func() {
+				s.mMember.On("GetMember", mockCtx, mockWalletID, mockAccountID2).Return(spender(), nil).Once()
+			},
+		},
+		{
+			Desc:     "bad Path, wallet owner",
+			UserID:   mockAccountID2,
+			MemberID: mockAccountID1,
+			ExpError: ErrWalletOwner,
+			setup: func() {
+				s.mMember.On("GetMember", mockCtx, mockWalletID, mockAccountID2).Return(withRole(spender(), mdWallet.Role_OWNER), nil).Once()
+			},
+		},
+		{
+			Desc:     "bad Path, member not exist",
+			UserID:   mockAccountID1,
+			MemberID: mockStranger,
+			ExpError: member.ErrMemberNotExist,
+			setup: func() {
+				s.mMember.On("GetMember", mockCtx, mockWalletID, mockStranger).Return(nil, member.ErrMemberNotExist).Once()
+			},
+		},
+	}
+
+	for _, t := range tests {
+		s.mBank.On("GetAccount", mockCtx, mockWalletID).Return(jointWallet(), nil).Once()
+		t.setup()
+		err := s.srv.RemoveMember(mockCtx, t.UserID, mockWalletID, t.MemberID)
+		s.Require().Equal(t.ExpError, err, t.Desc)
+		s.TearDownTest()
+	}
+}
@@ -0,0 +1,154 @@
+package wallet
+
+import (
+	"context"
+	"time"
+
+	"github.com/n3k0fi5t/wallet/app/broker"
+	mApproval "github.com/n3k0fi5t/wallet/app/models/approval"
+	mBank "github.com/n3k0fi5t/wallet/app/models/bank"
+	mWallet "github.com/n3k0fi5t/wallet/app/models/wallet"
+	"github.com/n3k0fi5t/wallet/app/money"
+	"github.com/n3k0fi5t/wallet/app/repository/bank"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	callsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "wallet_service_calls_total",
+		Help: "Calls of the wallet service, by method and outcome",
+	}, []string{"method", "outcome"})
+
+	callDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "wallet_service_call_duration_seconds",
+		Help:    "Duration of wallet service calls, by method",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method"})
+)
+
+// NewInstrumentedWallet decorates s with call metrics
+func NewInstrumentedWallet(s Service) Service {
+	return &instrumented{
+		srv: s,
+	}
+}
+
+type instrumented struct {
+	srv Service
+}
+
+// observe records the call, the returned function is deferred with the named error of the method
+func observe(method string) func(err *error) {
+	start := time.Now()
+	return func(err *error) {
+		callsTotal.WithLabelValues(method, bank.Outcome(*err)).Inc()
+		callDuration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+	}
+}
+
+func (in *instrumented) Deposit(ctx context.Context, accountID string, amount money.Money) (tradeID string, err error) {
+	defer observe("Deposit")(&err)
+	return in.srv.Deposit(ctx, accountID, amount)
+}
+
+func (in *instrumented) Withdraw(ctx context.Context, accountID string, amount money.Money) (tradeID string, intent *mApproval.Intent, err error) {
+	defer observe("Withdraw")(&err)
+	return in.srv.Withdraw(ctx, accountID, amount)
+}
+
+func (in *instrumented) Transfer(ctx context.Context, from, to string, amount money.Money) (tradeID string, intent *mApproval.Intent, err error) {
+	defer observe("Transfer")(&err)
+	return in.srv.Transfer(ctx, from, to, amount)
+}
+
+func (in *instrumented) GetAccount(ctx context.Context, accountID string) (account *mBank.Account, err error) {
+	defer observe("GetAccount")(&err)
+	return in.srv.GetAccount(ctx, accountID)
+}
+
+func (in *instrumented) ListTransactions(ctx context.Context, accountID string, offset, limit int) (txs []*mBank.Transaction, err error) {
+	defer observe("ListTransactions")(&err)
+	return in.srv.ListTransactions(ctx, accountID, offset, limit)
+}
+
+func (in *instrumented) GetTrade(ctx context.Context, accountID, tradeID string) (txs []*mBank.Transaction, err error) {
+	defer observe("GetTrade")(&err)
+	return in.srv.GetTrade(ctx, accountID, tradeID)
+}
+
+func (in *instrumented) Subscribe(ctx context.Context, accountID string) *broker.Subscription {
+	var err error
+	defer observe("Subscribe")(&err)
+	return in.srv.Subscribe(ctx, accountID)
+}
+
+func (in *instrumented) ListUpdates(ctx context.Context, accountID string, afterID int64, limit int) (updates []*mBank.Update, err error) {
+	defer observe("ListUpdates")(&err)
+	return in.srv.ListUpdates(ctx, accountID, afterID, limit)
+}
+
+func (in *instrumented) ListWallets(ctx context.Context, userID string) (wallets []*mBank.Account, err error) {
+	defer observe("ListWallets")(&err)
+	return in.srv.ListWallets(ctx, userID)
+}
+
+func (in *instrumented) CreateWallet(ctx context.Context, userID, name, currency string) (wallet *mBank.Account, err error) {
+	defer observe("CreateWallet")(&err)
+	return in.srv.CreateWallet(ctx, userID, name, currency)
+}
+
+func (in *instrumented) RenameWallet(ctx context.Context, userID, walletID, name string) (wallet *mBank.Account, err error) {
+	defer observe("RenameWallet")(&err)
+	return in.srv.RenameWallet(ctx, userID, walletID, name)
+}
+
+func (in *instrumented) CheckPolicy(ctx context.Context, walletID string, amount money.Money) (err error) {
+	defer observe("CheckPolicy")(&err)
+	return in.srv.CheckPolicy(ctx, walletID, amount)
+}
+
+func (in *instrumented) Hold(ctx context.Context, userID, walletID, toAccountID string, amount money.Money) (intent *mApproval.Intent, err error) {
+	defer observe("Hold")(&err)
+	return in.srv.Hold(ctx, userID, walletID, toAccountID, amount)
+}
+
+func (in *instrumented) Move(ctx context.Context, userID, from, to string, amount money.Money) (tradeID string, err error) {
+	defer observe("Move")(&err)
+	return in.srv.Move(ctx, userID, from, to, amount)
+}
+
+func (in *instrumented) Authorize(ctx context.Context, userID, walletID string) (m *mWallet.Member, err error) {
+	defer observe("Authorize")(&err)
+	return in.srv.Authorize(ctx, userID, walletID)
+}
+
+func (in *instrumented) WithdrawAs(ctx context.Context, userID, walletID string, amount money.Money) (tradeID string, intent *mApproval.Intent, err error) {
+	defer observe("WithdrawAs")(&err)
+	return in.srv.WithdrawAs(ctx, userID, walletID, amount)
+}
+
+func (in *instrumented) TransferAs(ctx context.Context, userID, from, to string, amount money.Money) (tradeID string, intent *mApproval.Intent, err error) {
+	defer observe("TransferAs")(&err)
+	return in.srv.TransferAs(ctx, userID, from, to, amount)
+}
+
+func (in *instrumented) ListMembers(ctx context.Context, userID, walletID string) (members []*mWallet.Member, err error) {
+	defer observe("ListMembers")(&err)
+	return in.srv.ListMembers(ctx, userID, walletID)
+}
+
+func (in *instrumented) SetMember(ctx context.Context, userID, walletID, memberID string, role mWallet.Role, dailyCap money.Money) (m *mWallet.Member, err error) {
+	defer observe("SetMember")(&err)
+	return in.srv.SetMember(ctx, userID, walletID, memberID, role, dailyCap)
+}
+
+func (in *instrumented) RemoveMember(ctx context.Context, userID, walletID, memberID string) (err error) {
+	defer observe("RemoveMember")(&err)
+	return in.srv.RemoveMember(ctx, userID, walletID, memberID)
+}
+
+func (in *instrumented) ListMemberships(ctx context.Context, userID string) (members []*mWallet.Member, err error) {
+	defer observe("ListMemberships")(&err)
+	return in.srv.ListMemberships(ctx, userID)
+}
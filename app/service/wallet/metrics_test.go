@@ -0,0 +1,102 @@
+package wallet
+
+import (
+	"fmt"
+
+	"github.com/n3k0fi5t/wallet/app/repository/bank"
+	"github.com/n3k0fi5t/wallet/common/sql/sqltest"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// counterValue returns the counter of the default registry which has all the labels
+func (s *testSuite) counterValue(name string, labels map[string]string) float64 {
+	families, err := prometheus.DefaultGatherer.Gather()
+	s.Require().NoError(err)
+
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+	metrics:
+		for _, m := range family.GetMetric() {
+			for _, l := range m.GetLabel() {
+				if v, ok := labels[l.GetName()]; ok && v != l.GetValue() {
+					continue metrics
+				}
+			}
+			return m.GetCounter().GetValue()
+		}
+	}
+	return 0
+}
+
+func (s *testSuite) TestInstrumented() {
+	srv := NewInstrumentedWallet(NewWallet(sqltest.Transactor{}, bank.NewInstrumentedBank(s.mBank), s.mMember, s.mApproval, s.broker))
+
+	tests := []struct {
+		Desc       string
+		call       func() error
+		Method     string
+		TradeType  string
+		ExpOutcome string
+		setup      func()
+	}{
+		{
+			Desc: "deposit ok",
+			call: func() error {
+				_, err := srv.Deposit(mockCtx, mockAccountID1, usd(100))
+				return err
+			},
+			Method:     "Deposit",
+			TradeType:  bank.TradeType_DEPOSIT,
+			ExpOutcome: bank.OutcomeOK,
+			setup: func() {
+				s.expectWallet(mockCtx, mockAccountID1)
+				s.mBank.On("Trade", mockCtx, anyDealing).Return(mockTradeID, nil).Once()
+			},
+		},
+		{
+			Desc: "withdraw balance not enough",
+			call: func() error {
+				_, _, err := srv.Withdraw(mockCtx, mockAccountID1, usd(100))
+				return err
+			},
+			Method:     "Withdraw",
+			TradeType:  bank.TradeType_WITHDRAW,
+			ExpOutcome: "balance_not_enough",
+			setup: func() {
+				s.expectNoPolicy(mockCtx, mockAccountID1)
+				s.expectWallet(mockCtx, mockAccountID1)
+				s.mBank.On("Trade", mockCtx, anyDealing).Return("", bank.ErrBalanceNotEnough).Once()
+			},
+		},
+		{
+			Desc: "transfer unknown error",
+			call: func() error {
+				_, _, err := srv.Transfer(mockCtx, mockAccountID1, mockAccountID2, usd(100))
+				return err
+			},
+			Method:     "Transfer",
+			TradeType:  bank.TradeType_TRANSFER,
+			ExpOutcome: bank.OutcomeError,
+			setup: func() {
+				s.expectWallet(mockCtx, mockAccountID1)
+				s.expectPayee(mockCtx)
+				s.expectNoPolicy(mockCtx, mockAccountID1)
+				s.mBank.On("Trade", mockCtx, anyDealing).Return("", fmt.Errorf("connection reset")).Once()
+			},
+		},
+	}
+
+	for _, t := range tests {
+		t.setup()
+		calls := map[string]string{"method": t.Method, "outcome": t.ExpOutcome}
+		trades := map[string]string{"type": t.TradeType, "outcome": t.ExpOutcome}
+		callsBefore := s.counterValue("wallet_service_calls_total", calls)
+		tradesBefore := s.counterValue("bank_trades_total", trades)
+
+		t.call()
+		s.Require().Equal(callsBefore+1, s.counterValue("wallet_service_calls_total", calls), t.Desc)
+		s.Require().Equal(tradesBefore+1, s.counterValue("bank_trades_total", trades), t.Desc)
+	}
+}
@@ -2,28 +2,92 @@
 
 package mocks
 
+import approval "github.com/n3k0fi5t/wallet/app/models/approval"
 import bank "github.com/n3k0fi5t/wallet/app/models/bank"
+import broker "github.com/n3k0fi5t/wallet/app/broker"
 import context "context"
 import mock "github.com/stretchr/testify/mock"
+import money "github.com/n3k0fi5t/wallet/app/money"
+import wallet "github.com/n3k0fi5t/wallet/app/models/wallet"
 
 // Service is an autogenerated mock type for the Service type
 type Service struct {
 	mock.Mock
 }
 
+// Authorize provides a mock function with given fields: ctx, userID, walletID
+func (_m *Service) Authorize(ctx context.Context, userID string, walletID string) (*wallet.Member, error) {
+	ret := _m.Called(ctx, userID, walletID)
+
+	var r0 *wallet.Member
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *wallet.Member); ok {
+		r0 = rf(ctx, userID, walletID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*wallet.Member)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, userID, walletID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CheckPolicy provides a mock function with given fields: ctx, walletID, amount
+func (_m *Service) CheckPolicy(ctx context.Context, walletID string, amount money.Money) error {
+	ret := _m.Called(ctx, walletID, amount)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, money.Money) error); ok {
+		r0 = rf(ctx, walletID, amount)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CreateWallet provides a mock function with given fields: ctx, userID, name, currency
+func (_m *Service) CreateWallet(ctx context.Context, userID string, name string, currency string) (*bank.Account, error) {
+	ret := _m.Called(ctx, userID, name, currency)
+
+	var r0 *bank.Account
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) *bank.Account); ok {
+		r0 = rf(ctx, userID, name, currency)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*bank.Account)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string) error); ok {
+		r1 = rf(ctx, userID, name, currency)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Deposit provides a mock function with given fields: ctx, accountID, amount
-func (_m *Service) Deposit(ctx context.Context, accountID string, amount int64) (string, error) {
+func (_m *Service) Deposit(ctx context.Context, accountID string, amount money.Money) (string, error) {
 	ret := _m.Called(ctx, accountID, amount)
 
 	var r0 string
-	if rf, ok := ret.Get(0).(func(context.Context, string, int64) string); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, string, money.Money) string); ok {
 		r0 = rf(ctx, accountID, amount)
 	} else {
 		r0 = ret.Get(0).(string)
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, string, int64) error); ok {
+	if rf, ok := ret.Get(1).(func(context.Context, string, money.Money) error); ok {
 		r1 = rf(ctx, accountID, amount)
 	} else {
 		r1 = ret.Error(1)
@@ -55,44 +119,380 @@ func (_m *Service) GetAccount(ctx context.Context, accountID string) (*bank.Acco
 	return r0, r1
 }
 
+// GetTrade provides a mock function with given fields: ctx, accountID, tradeID
+func (_m *Service) GetTrade(ctx context.Context, accountID string, tradeID string) ([]*bank.Transaction, error) {
+	ret := _m.Called(ctx, accountID, tradeID)
+
+	var r0 []*bank.Transaction
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) []*bank.Transaction); ok {
+		r0 = rf(ctx, accountID, tradeID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*bank.Transaction)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, accountID, tradeID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Hold provides a mock function with given fields: ctx, userID, walletID, toAccountID, amount
+func (_m *Service) Hold(ctx context.Context, userID string, walletID string, toAccountID string, amount money.Money) (*approval.Intent, error) {
+	ret := _m.Called(ctx, userID, walletID, toAccountID, amount)
+
+	var r0 *approval.Intent
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, money.Money) *approval.Intent); ok {
+		r0 = rf(ctx, userID, walletID, toAccountID, amount)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*approval.Intent)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string, money.Money) error); ok {
+		r1 = rf(ctx, userID, walletID, toAccountID, amount)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListMembers provides a mock function with given fields: ctx, userID, walletID
+func (_m *Service) ListMembers(ctx context.Context, userID string, walletID string) ([]*wallet.Member, error) {
+	ret := _m.Called(ctx, userID, walletID)
+
+	var r0 []*wallet.Member
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) []*wallet.Member); ok {
+		r0 = rf(ctx, userID, walletID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*wallet.Member)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, userID, walletID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListMemberships provides a mock function with given fields: ctx, userID
+func (_m *Service) ListMemberships(ctx context.Context, userID string) ([]*wallet.Member, error) {
+	ret := _m.Called(ctx, userID)
+
+	var r0 []*wallet.Member
+	if rf, ok := ret.Get(0).(func(context.Context, string) []*wallet.Member); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*wallet.Member)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListTransactions provides a mock function with given fields: ctx, accountID, offset, limit
+func (_m *Service) ListTransactions(ctx context.Context, accountID string, offset int, limit int) ([]*bank.Transaction, error) {
+	ret := _m.Called(ctx, accountID, offset, limit)
+
+	var r0 []*bank.Transaction
+	if rf, ok := ret.Get(0).(func(context.Context, string, int, int) []*bank.Transaction); ok {
+		r0 = rf(ctx, accountID, offset, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*bank.Transaction)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, int, int) error); ok {
+		r1 = rf(ctx, accountID, offset, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListUpdates provides a mock function with given fields: ctx, accountID, afterID, limit
+func (_m *Service) ListUpdates(ctx context.Context, accountID string, afterID int64, limit int) ([]*bank.Update, error) {
+	ret := _m.Called(ctx, accountID, afterID, limit)
+
+	var r0 []*bank.Update
+	if rf, ok := ret.Get(0).(func(context.Context, string, int64, int) []*bank.Update); ok {
+		r0 = rf(ctx, accountID, afterID, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*bank.Update)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, int64, int) error); ok {
+		r1 = rf(ctx, accountID, afterID, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListWallets provides a mock function with given fields: ctx, userID
+func (_m *Service) ListWallets(ctx context.Context, userID string) ([]*bank.Account, error) {
+	ret := _m.Called(ctx, userID)
+
+	var r0 []*bank.Account
+	if rf, ok := ret.Get(0).(func(context.Context, string) []*bank.Account); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*bank.Account)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Move provides a mock function with given fields: ctx, userID, from, to, amount
+func (_m *Service) Move(ctx context.Context, userID string, from string, to string, amount money.Money) (string, error) {
+	ret := _m.Called(ctx, userID, from, to, amount)
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, money.Money) string); ok {
+		r0 = rf(ctx, userID, from, to, amount)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string, money.Money) error); ok {
+		r1 = rf(ctx, userID, from, to, amount)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RemoveMember provides a mock function with given fields: ctx, userID, walletID, memberID
+func (_m *Service) RemoveMember(ctx context.Context, userID string, walletID string, memberID string) error {
+	ret := _m.Called(ctx, userID, walletID, memberID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) error); ok {
+		r0 = rf(ctx, userID, walletID, memberID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RenameWallet provides a mock function with given fields: ctx, userID, walletID, name
+func (_m *Service) RenameWallet(ctx context.Context, userID string, walletID string, name string) (*bank.Account, error) {
+	ret := _m.Called(ctx, userID, walletID, name)
+
+	var r0 *bank.Account
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) *bank.Account); ok {
+		r0 = rf(ctx, userID, walletID, name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*bank.Account)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string) error); ok {
+		r1 = rf(ctx, userID, walletID, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetMember provides a mock function with given fields: ctx, userID, walletID, memberID, role, dailyCap
+func (_m *Service) SetMember(ctx context.Context, userID string, walletID string, memberID string, role wallet.Role, dailyCap money.Money) (*wallet.Member, error) {
+	ret := _m.Called(ctx, userID, walletID, memberID, role, dailyCap)
+
+	var r0 *wallet.Member
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, wallet.Role, money.Money) *wallet.Member); ok {
+		r0 = rf(ctx, userID, walletID, memberID, role, dailyCap)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*wallet.Member)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string, wallet.Role, money.Money) error); ok {
+		r1 = rf(ctx, userID, walletID, memberID, role, dailyCap)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Subscribe provides a mock function with given fields: ctx, accountID
+func (_m *Service) Subscribe(ctx context.Context, accountID string) *broker.Subscription {
+	ret := _m.Called(ctx, accountID)
+
+	var r0 *broker.Subscription
+	if rf, ok := ret.Get(0).(func(context.Context, string) *broker.Subscription); ok {
+		r0 = rf(ctx, accountID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*broker.Subscription)
+		}
+	}
+
+	return r0
+}
+
 // Transfer provides a mock function with given fields: ctx, from, to, amount
-func (_m *Service) Transfer(ctx context.Context, from string, to string, amount int64) (string, error) {
+func (_m *Service) Transfer(ctx context.Context, from string, to string, amount money.Money) (string, *approval.Intent, error) {
 	ret := _m.Called(ctx, from, to, amount)
 
 	var r0 string
-	if rf, ok := ret.Get(0).(func(context.Context, string, string, int64) string); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, money.Money) string); ok {
 		r0 = rf(ctx, from, to, amount)
 	} else {
 		r0 = ret.Get(0).(string)
 	}
 
-	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, string, string, int64) error); ok {
+	var r1 *approval.Intent
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, money.Money) *approval.Intent); ok {
 		r1 = rf(ctx, from, to, amount)
 	} else {
-		r1 = ret.Error(1)
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*approval.Intent)
+		}
 	}
 
-	return r0, r1
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, string, string, money.Money) error); ok {
+		r2 = rf(ctx, from, to, amount)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// TransferAs provides a mock function with given fields: ctx, userID, from, to, amount
+func (_m *Service) TransferAs(ctx context.Context, userID string, from string, to string, amount money.Money) (string, *approval.Intent, error) {
+	ret := _m.Called(ctx, userID, from, to, amount)
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, money.Money) string); ok {
+		r0 = rf(ctx, userID, from, to, amount)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	var r1 *approval.Intent
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string, money.Money) *approval.Intent); ok {
+		r1 = rf(ctx, userID, from, to, amount)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*approval.Intent)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, string, string, string, money.Money) error); ok {
+		r2 = rf(ctx, userID, from, to, amount)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
 }
 
 // Withdraw provides a mock function with given fields: ctx, accountID, amount
-func (_m *Service) Withdraw(ctx context.Context, accountID string, amount int64) (string, error) {
+func (_m *Service) Withdraw(ctx context.Context, accountID string, amount money.Money) (string, *approval.Intent, error) {
 	ret := _m.Called(ctx, accountID, amount)
 
 	var r0 string
-	if rf, ok := ret.Get(0).(func(context.Context, string, int64) string); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, string, money.Money) string); ok {
 		r0 = rf(ctx, accountID, amount)
 	} else {
 		r0 = ret.Get(0).(string)
 	}
 
-	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, string, int64) error); ok {
+	var r1 *approval.Intent
+	if rf, ok := ret.Get(1).(func(context.Context, string, money.Money) *approval.Intent); ok {
 		r1 = rf(ctx, accountID, amount)
 	} else {
-		r1 = ret.Error(1)
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*approval.Intent)
+		}
 	}
 
-	return r0, r1
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, string, money.Money) error); ok {
+		r2 = rf(ctx, accountID, amount)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// WithdrawAs provides a mock function with given fields: ctx, userID, walletID, amount
+func (_m *Service) WithdrawAs(ctx context.Context, userID string, walletID string, amount money.Money) (string, *approval.Intent, error) {
+	ret := _m.Called(ctx, userID, walletID, amount)
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, money.Money) string); ok {
+		r0 = rf(ctx, userID, walletID, amount)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	var r1 *approval.Intent
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, money.Money) *approval.Intent); ok {
+		r1 = rf(ctx, userID, walletID, amount)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*approval.Intent)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, string, string, money.Money) error); ok {
+		r2 = rf(ctx, userID, walletID, amount)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
 }
@@ -0,0 +1,214 @@
+package wallet
+
+import (
+	"context"
+
+	"github.com/n3k0fi5t/wallet/app/broker"
+	mApproval "github.com/n3k0fi5t/wallet/app/models/approval"
+	mBank "github.com/n3k0fi5t/wallet/app/models/bank"
+	mWallet "github.com/n3k0fi5t/wallet/app/models/wallet"
+	"github.com/n3k0fi5t/wallet/app/money"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	tracerName = "github.com/n3k0fi5t/wallet/app/service/wallet"
+
+	AttrUserID      = attribute.Key("wallet.user_id")
+	AttrAccountID   = attribute.Key("wallet.account_id")
+	AttrToAccountID = attribute.Key("wallet.to_account_id")
+	AttrTradeID     = attribute.Key("wallet.trade_id")
+	AttrIntentID    = attribute.Key("wallet.intent_id")
+	AttrAmount      = attribute.Key("wallet.amount")
+	AttrMemberID    = attribute.Key("wallet.member_id")
+)
+
+// NewTracedWallet decorates s with a span per call
+func NewTracedWallet(s Service) Service {
+	return &traced{
+		srv:    s,
+		tracer: otel.Tracer(tracerName),
+	}
+}
+
+type traced struct {
+	srv    Service
+	tracer trace.Tracer
+}
+
+// start starts the span of the call, the returned function is deferred with the named error of the method
+func (t *traced) start(ctx context.Context, method string, attrs ...attribute.KeyValue) (context.Context, trace.Span, func(err *error)) {
+	ctx, span := t.tracer.Start(ctx, "wallet.Service/"+method, trace.WithAttributes(attrs...))
+	return ctx, span, func(err *error) {
+		if *err != nil {
+			span.RecordError(*err)
+			span.SetStatus(codes.Error, (*err).Error())
+		}
+		span.End()
+	}
+}
+
+func (t *traced) Deposit(ctx context.Context, accountID string, amount money.Money) (tradeID string, err error) {
+	ctx, span, end := t.start(ctx, "Deposit", AttrAccountID.String(accountID), AttrAmount.String(amount.String()))
+	defer end(&err)
+
+	tradeID, err = t.srv.Deposit(ctx, accountID, amount)
+	span.SetAttributes(AttrTradeID.String(tradeID))
+	return tradeID, err
+}
+
+func (t *traced) Withdraw(ctx context.Context, accountID string, amount money.Money) (tradeID string, intent *mApproval.Intent, err error) {
+	ctx, span, end := t.start(ctx, "Withdraw", AttrAccountID.String(accountID), AttrAmount.String(amount.String()))
+	defer end(&err)
+
+	tradeID, intent, err = t.srv.Withdraw(ctx, accountID, amount)
+	span.SetAttributes(AttrTradeID.String(tradeID))
+	if intent != nil {
+		span.SetAttributes(AttrIntentID.String(intent.IntentID))
+	}
+	return tradeID, intent, err
+}
+
+func (t *traced) Transfer(ctx context.Context, from, to string, amount money.Money) (tradeID string, intent *mApproval.Intent, err error) {
+	ctx, span, end := t.start(ctx, "Transfer", AttrAccountID.String(from), AttrToAccountID.String(to), AttrAmount.String(amount.String()))
+	defer end(&err)
+
+	tradeID, intent, err = t.srv.Transfer(ctx, from, to, amount)
+	span.SetAttributes(AttrTradeID.String(tradeID))
+	if intent != nil {
+		span.SetAttributes(AttrIntentID.String(intent.IntentID))
+	}
+	return tradeID, intent, err
+}
+
+func (t *traced) GetAccount(ctx context.Context, accountID string) (account *mBank.Account, err error) {
+	ctx, _, end := t.start(ctx, "GetAccount", AttrAccountID.String(accountID))
+	defer end(&err)
+	return t.srv.GetAccount(ctx, accountID)
+}
+
+func (t *traced) ListTransactions(ctx context.Context, accountID string, offset, limit int) (txs []*mBank.Transaction, err error) {
+	ctx, _, end := t.start(ctx, "ListTransactions", AttrAccountID.String(accountID))
+	defer end(&err)
+	return t.srv.ListTransactions(ctx, accountID, offset, limit)
+}
+
+func (t *traced) GetTrade(ctx context.Context, accountID, tradeID string) (txs []*mBank.Transaction, err error) {
+	ctx, _, end := t.start(ctx, "GetTrade", AttrAccountID.String(accountID), AttrTradeID.String(tradeID))
+	defer end(&err)
+	return t.srv.GetTrade(ctx, accountID, tradeID)
+}
+
+func (t *traced) Subscribe(ctx context.Context, accountID string) *broker.Subscription {
+	var err error
+	ctx, _, end := t.start(ctx, "Subscribe", AttrAccountID.String(accountID))
+	defer end(&err)
+	return t.srv.Subscribe(ctx, accountID)
+}
+
+func (t *traced) ListUpdates(ctx context.Context, accountID string, afterID int64, limit int) (updates []*mBank.Update, err error) {
+	ctx, _, end := t.start(ctx, "ListUpdates", AttrAccountID.String(accountID))
+	defer end(&err)
+	return t.srv.ListUpdates(ctx, accountID, afterID, limit)
+}
+
+func (t *traced) ListWallets(ctx context.Context, userID string) (wallets []*mBank.Account, err error) {
+	ctx, _, end := t.start(ctx, "ListWallets", AttrUserID.String(userID))
+	defer end(&err)
+	return t.srv.ListWallets(ctx, userID)
+}
+
+func (t *traced) CreateWallet(ctx context.Context, userID, name, currency string) (wallet *mBank.Account, err error) {
+	ctx, _, end := t.start(ctx, "CreateWallet", AttrUserID.String(userID))
+	defer end(&err)
+	return t.srv.CreateWallet(ctx, userID, name, currency)
+}
+
+func (t *traced) RenameWallet(ctx context.Context, userID, walletID, name string) (wallet *mBank.Account, err error) {
+	ctx, _, end := t.start(ctx, "RenameWallet", AttrUserID.String(userID), AttrAccountID.String(walletID))
+	defer end(&err)
+	return t.srv.RenameWallet(ctx, userID, walletID, name)
+}
+
+func (t *traced) CheckPolicy(ctx context.Context, walletID string, amount money.Money) (err error) {
+	ctx, _, end := t.start(ctx, "CheckPolicy", AttrAccountID.String(walletID), AttrAmount.String(amount.String()))
+	defer end(&err)
+	return t.srv.CheckPolicy(ctx, walletID, amount)
+}
+
+func (t *traced) Hold(ctx context.Context, userID, walletID, toAccountID string, amount money.Money) (intent *mApproval.Intent, err error) {
+	ctx, span, end := t.start(ctx, "Hold", AttrUserID.String(userID), AttrAccountID.String(walletID), AttrToAccountID.String(toAccountID), AttrAmount.String(amount.String()))
+	defer end(&err)
+
+	intent, err = t.srv.Hold(ctx, userID, walletID, toAccountID, amount)
+	if intent != nil {
+		span.SetAttributes(AttrIntentID.String(intent.IntentID))
+	}
+	return intent, err
+}
+
+func (t *traced) Move(ctx context.Context, userID, from, to string, amount money.Money) (tradeID string, err error) {
+	ctx, span, end := t.start(ctx, "Move", AttrUserID.String(userID), AttrAccountID.String(from), AttrToAccountID.String(to), AttrAmount.String(amount.String()))
+	defer end(&err)
+
+	tradeID, err = t.srv.Move(ctx, userID, from, to, amount)
+	span.SetAttributes(AttrTradeID.String(tradeID))
+	return tradeID, err
+}
+
+func (t *traced) Authorize(ctx context.Context, userID, walletID string) (m *mWallet.Member, err error) {
+	ctx, _, end := t.start(ctx, "Authorize", AttrUserID.String(userID), AttrAccountID.String(walletID))
+	defer end(&err)
+	return t.srv.Authorize(ctx, userID, walletID)
+}
+
+func (t *traced) WithdrawAs(ctx context.Context, userID, walletID string, amount money.Money) (tradeID string, intent *mApproval.Intent, err error) {
+	ctx, span, end := t.start(ctx, "WithdrawAs", AttrUserID.String(userID), AttrAccountID.String(walletID), AttrAmount.String(amount.String()))
+	defer end(&err)
+
+	tradeID, intent, err = t.srv.WithdrawAs(ctx, userID, walletID, amount)
+	span.SetAttributes(AttrTradeID.String(tradeID))
+	if intent != nil {
+		span.SetAttributes(AttrIntentID.String(intent.IntentID))
+	}
+	return tradeID, intent, err
+}
+
+func (t *traced) TransferAs(ctx context.Context, userID, from, to string, amount money.Money) (tradeID string, intent *mApproval.Intent, err error) {
+	ctx, span, end := t.start(ctx, "TransferAs", AttrUserID.String(userID), AttrAccountID.String(from), AttrToAccountID.String(to), AttrAmount.String(amount.String()))
+	defer end(&err)
+
+	tradeID, intent, err = t.srv.TransferAs(ctx, userID, from, to, amount)
+	span.SetAttributes(AttrTradeID.String(tradeID))
+	if intent != nil {
+		span.SetAttributes(AttrIntentID.String(intent.IntentID))
+	}
+	return tradeID, intent, err
+}
+
+func (t *traced) ListMembers(ctx context.Context, userID, walletID string) (members []*mWallet.Member, err error) {
+	ctx, _, end := t.start(ctx, "ListMembers", AttrUserID.String(userID), AttrAccountID.String(walletID))
+	defer end(&err)
+	return t.srv.ListMembers(ctx, userID, walletID)
+}
+
+func (t *traced) SetMember(ctx context.Context, userID, walletID, memberID string, role mWallet.Role, dailyCap money.Money) (m *mWallet.Member, err error) {
+	ctx, _, end := t.start(ctx, "SetMember", AttrUserID.String(userID), AttrAccountID.String(walletID), AttrMemberID.String(memberID))
+	defer end(&err)
+	return t.srv.SetMember(ctx, userID, walletID, memberID, role, dailyCap)
+}
+
+func (t *traced) RemoveMember(ctx context.Context, userID, walletID, memberID string) (err error) {
+	ctx, _, end := t.start(ctx, "RemoveMember", AttrUserID.String(userID), AttrAccountID.String(walletID), AttrMemberID.String(memberID))
+	defer end(&err)
+	return t.srv.RemoveMember(ctx, userID, walletID, memberID)
+}
+
+func (t *traced) ListMemberships(ctx context.Context, userID string) (members []*mWallet.Member, err error) {
+	ctx, _, end := t.start(ctx, "ListMemberships", AttrUserID.String(userID))
+	defer end(&err)
+	return t.srv.ListMemberships(ctx, userID)
+}
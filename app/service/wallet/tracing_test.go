@@ -0,0 +1,90 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/n3k0fi5t/wallet/common/sql/sqltest"
+	"github.com/stretchr/testify/mock"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// spanCtx matches the context carrying the span of the call, repositories trace under it
+var spanCtx = mock.MatchedBy(func(ctx context.Context) bool {
+	return trace.SpanContextFromContext(ctx).IsValid()
+})
+
+func (s *testSuite) TestTraced() {
+	exporter := tracetest.NewInMemoryExporter()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	srv := NewTracedWallet(NewWallet(sqltest.Transactor{}, s.mBank, s.mMember, s.mApproval, s.broker))
+
+	tests := []struct {
+		Desc     string
+		call     func() error
+		ExpName  string
+		ExpAttrs map[string]string
+		ExpCode  codes.Code
+		setup    func()
+	}{
+		{
+			Desc: "transfer",
+			call: func() error {
+				_, _, err := srv.Transfer(mockCtx, mockAccountID1, mockAccountID2, usd(100))
+				return err
+			},
+			ExpName: "wallet.Service/Transfer",
+			ExpAttrs: map[string]string{
+				string(AttrAccountID):   mockAccountID1,
+				string(AttrToAccountID): mockAccountID2,
+				string(AttrTradeID):     mockTradeID,
+			},
+			ExpCode: codes.Unset,
+			setup: func() {
+				s.expectWallet(spanCtx, mockAccountID1)
+				s.expectPayee(spanCtx)
+				s.expectNoPolicy(spanCtx, mockAccountID1)
+				s.mBank.On("Trade", spanCtx, anyDealing).Return(mockTradeID, nil).Once()
+			},
+		},
+		{
+			Desc: "get trade fails",
+			call: func() error {
+				_, err := srv.GetTrade(mockCtx, mockAccountID1, mockTradeID)
+				return err
+			},
+			ExpName: "wallet.Service/GetTrade",
+			ExpAttrs: map[string]string{
+				string(AttrAccountID): mockAccountID1,
+				string(AttrTradeID):   mockTradeID,
+			},
+			ExpCode: codes.Error,
+			setup: func() {
+				s.mBank.On("GetTrade", spanCtx, mockTradeID).Return(nil, fmt.Errorf("connection reset")).Once()
+			},
+		},
+	}
+
+	for _, t := range tests {
+		exporter.Reset()
+		t.setup()
+		t.call()
+
+		spans := exporter.GetSpans()
+		s.Require().Len(spans, 1, t.Desc)
+		s.Require().Equal(t.ExpName, spans[0].Name, t.Desc)
+		s.Require().Equal(t.ExpCode, spans[0].Status.Code, t.Desc)
+
+		attrs := map[string]string{}
+		for _, kv := range spans[0].Attributes {
+			attrs[string(kv.Key)] = kv.Value.Emit()
+		}
+		for k, v := range t.ExpAttrs {
+			s.Require().Equal(v, attrs[k], t.Desc)
+		}
+	}
+}
@@ -2,20 +2,138 @@ package wallet
 
 import (
 	"context"
+	"fmt"
+	"time"
 
+	"github.com/n3k0fi5t/wallet/app/broker"
+	mApproval "github.com/n3k0fi5t/wallet/app/models/approval"
 	mBank "github.com/n3k0fi5t/wallet/app/models/bank"
+	mWallet "github.com/n3k0fi5t/wallet/app/models/wallet"
+	"github.com/n3k0fi5t/wallet/app/money"
+)
+
+var (
+	// ErrInvalidWalletName means the name is empty or exceeds MaxWalletNameLength
+	ErrInvalidWalletName = fmt.Errorf("Invalid wallet name")
+
+	// ErrWalletNameTaken means the user owns another wallet of the name
+	ErrWalletNameTaken = fmt.Errorf("Wallet name taken")
+
+	// ErrTooManyWallets means the user reaches MaxWallets
+	ErrTooManyWallets = fmt.Errorf("Too many wallets")
+
+	// ErrPermissionDenied means the role of the member does not allow the action
+	ErrPermissionDenied = fmt.Errorf("Permission denied")
+
+	// ErrDailyCapExceeded means paying out exceeds the daily spending cap of the member
+	ErrDailyCapExceeded = fmt.Errorf("Daily cap exceeded")
+
+	// ErrInvalidMember means the role is unknown or the daily cap is negative
+	ErrInvalidMember = fmt.Errorf("Invalid member")
+
+	// ErrWalletOwner means the membership of the user opening the wallet can not be changed
+	ErrWalletOwner = fmt.Errorf("Wallet owner")
+
+	// ErrTooManyMembers means the wallet reaches MaxMembers
+	ErrTooManyMembers = fmt.Errorf("Too many members")
+
+	// ErrApprovalRequired means the amount exceeds the threshold of the approval policy of the wallet, request approvals instead
+	ErrApprovalRequired = fmt.Errorf("Approval required")
+
+	// ErrApprovalNotRequired means the wallet has no policy or the amount is within the threshold, pay directly instead
+	ErrApprovalNotRequired = fmt.Errorf("Approval not required")
+
+	// ErrNotEnoughApprovers means the approvers other than the requester are fewer than required
+	ErrNotEnoughApprovers = fmt.Errorf("Not enough approvers")
+
+	// ErrInvalidCurrency means the currency is not supported
+	ErrInvalidCurrency = fmt.Errorf("Invalid currency")
+)
+
+const (
+	// MaxWallets is the max number of wallets of a user, the default wallet included
+	MaxWallets = 10
+
+	// MaxWalletNameLength is the max length of wallet names in bytes
+	MaxWalletNameLength = 50
+
+	// MaxMembers is the max number of members added to a wallet, the owner excluded
+	MaxMembers = 10
+
+	// IntentTTL is the time approvers have to approve an intent, the money goes back to the wallet after
+	IntentTTL = 48 * time.Hour
+
+	// holdAccountPrefix prefixes IDs of hold accounts, so they never clash with user accounts
+	holdAccountPrefix = "hold-"
 )
 
 type Service interface {
 	// Deposit deposit money to specific user's account
-	Deposit(ctx context.Context, accountID string, amount int64) (string, error)
+	Deposit(ctx context.Context, accountID string, amount money.Money) (string, error)
+
+	// Deposit withdraw money from specific user's account. Amounts over the threshold of the approval policy of
+	// the account are held until approved instead, the intent is returned without a tradeID
+	Withdraw(ctx context.Context, accountID string, amount money.Money) (string, *mApproval.Intent, error)
+
+	// Transfer transfer money from one account to another. Amounts over the threshold of the approval policy of
+	// the account are held until approved instead, the intent is returned without a tradeID
+	Transfer(ctx context.Context, from, to string, amount money.Money) (string, *mApproval.Intent, error)
 
-	// Deposit withdraw money from specific user's account
-	Withdraw(ctx context.Context, accountID string, amount int64) (string, error)
+	// CheckPolicy fails paying amount out of the wallet with ErrApprovalRequired if the approval policy of the wallet
+	// requires approvals, for debits which can not wait for them
+	CheckPolicy(ctx context.Context, walletID string, amount money.Money) error
 
-	// Transfer transfer money from one account to another
-	Transfer(ctx context.Context, from, to string, amount int64) (string, error)
+	// Hold holds amount of the wallet on behalf of a member allowed to spend until approved under the approval policy
+	// of the wallet, empty toAccountID for a withdrawal
+	Hold(ctx context.Context, userID, walletID, toAccountID string, amount money.Money) (*mApproval.Intent, error)
 
 	// GetAccount get account information of specific users's account
 	GetAccount(ctx context.Context, accountID string) (*mBank.Account, error)
+
+	// ListTransactions list transaction logs of specific user's account, newest first
+	ListTransactions(ctx context.Context, accountID string, offset, limit int) ([]*mBank.Transaction, error)
+
+	// GetTrade get the double entries of a trade specific user's account takes part in
+	GetTrade(ctx context.Context, accountID, tradeID string) ([]*mBank.Transaction, error)
+
+	// Subscribe subscribes balance updates of specific user's account committed from now on, the caller closes it
+	Subscribe(ctx context.Context, accountID string) *broker.Subscription
+
+	// ListUpdates list the newest limit balance updates of specific user's account after update afterID, oldest first
+	ListUpdates(ctx context.Context, accountID string, afterID int64, limit int) ([]*mBank.Update, error)
+
+	// ListWallets list wallets of the user, the default wallet first
+	ListWallets(ctx context.Context, userID string) ([]*mBank.Account, error)
+
+	// CreateWallet opens a named wallet of the currency for the user, empty currency for the default one
+	CreateWallet(ctx context.Context, userID, name, currency string) (*mBank.Account, error)
+
+	// RenameWallet renames a wallet of the user
+	RenameWallet(ctx context.Context, userID, walletID, name string) (*mBank.Account, error)
+
+	// Move moves money between wallets of the user
+	Move(ctx context.Context, userID, from, to string, amount money.Money) (string, error)
+
+	// Authorize returns the membership of the user on the wallet, members of any role see the wallet and deposit to it
+	Authorize(ctx context.Context, userID, walletID string) (*mWallet.Member, error)
+
+	// WithdrawAs withdraws money from the wallet on behalf of a member allowed to spend, within the daily cap of the member.
+	// Amounts over the threshold of the approval policy are held as Withdraw does, they are not charged to the cap
+	WithdrawAs(ctx context.Context, userID, walletID string, amount money.Money) (string, *mApproval.Intent, error)
+
+	// TransferAs transfers money from the wallet on behalf of a member allowed to spend, within the daily cap of the member.
+	// Amounts over the threshold of the approval policy are held as Transfer does, they are not charged to the cap
+	TransferAs(ctx context.Context, userID, from, to string, amount money.Money) (string, *mApproval.Intent, error)
+
+	// ListMembers list members of the wallet the user is a member of, the owner first
+	ListMembers(ctx context.Context, userID, walletID string) ([]*mWallet.Member, error)
+
+	// SetMember adds a member to the wallet the user manages, or updates role and daily cap of the member
+	SetMember(ctx context.Context, userID, walletID, memberID string, role mWallet.Role, dailyCap money.Money) (*mWallet.Member, error)
+
+	// RemoveMember removes a member from the wallet the user manages, members leave by removing themselves
+	RemoveMember(ctx context.Context, userID, walletID, memberID string) error
+
+	// ListMemberships list memberships of the user on wallets of others
+	ListMemberships(ctx context.Context, userID string) ([]*mWallet.Member, error)
 }
@@ -0,0 +1,382 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	mBank "github.com/n3k0fi5t/wallet/app/models/bank"
+	mEvent "github.com/n3k0fi5t/wallet/app/models/event"
+	mWebhook "github.com/n3k0fi5t/wallet/app/models/webhook"
+	"github.com/n3k0fi5t/wallet/app/repository/webhook"
+	"github.com/n3k0fi5t/wallet/app/util"
+	"github.com/n3k0fi5t/wallet/common/logger"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	secretPrefix = "whsec_"
+	secretBytes  = 32
+	// maxErrorLen bounds the error text kept on a delivery
+	maxErrorLen = 255
+)
+
+var (
+	timeNow   = time.Now
+	timeNowMs = util.TimeNowMs
+)
+
+func NewWebhook(w webhook.Webhook, client *http.Client, cfg Config) Service {
+	return &impl{
+		webhook: w,
+		client:  client,
+		cfg:     cfg,
+	}
+}
+
+type impl struct {
+	webhook webhook.Webhook
+	client  *http.Client
+	cfg     Config
+}
+
+func newSecret() (string, error) {
+	b := make([]byte, secretBytes)
+	if _, err := io.ReadFull(rand.Reader, b); err != nil {
+		return "", err
+	}
+	return secretPrefix + hex.EncodeToString(b), nil
+}
+
+// eventType maps a trade event to the webhook event type from the point of view of the event's account, money from
+// or to system accounts is a deposit or withdrawal, admin adjustments included
+func eventType(e *mEvent.Event) (mWebhook.EventType, bool) {
+	if e.Type == mEvent.Type_BILL_REMINDER && e.Bill != nil {
+		return mWebhook.EventType_BILL_REMINDER, true
+	} else if e.Type != mEvent.Type_TRADE_COMPLETED || e.Trade == nil {
+		return "", false
+	}
+
+	credit := e.Trade.Direction == mEvent.Direction_CREDIT
+	switch {
+	case mBank.IsSystemAccount(e.Trade.CounterpartyID) && credit:
+		return mWebhook.EventType_DEPOSIT, true
+	case mBank.IsSystemAccount(e.Trade.CounterpartyID):
+		return mWebhook.EventType_WITHDRAW, true
+	case credit:
+		return mWebhook.EventType_TRANSFER_RECEIVED, true
+	default:
+		return mWebhook.EventType_TRANSFER_SENT, true
+	}
+}
+
+// backoff returns the wait after the n-th failed attempt
+func (im *impl) backoff(attempts int) time.Duration {
+	d := im.cfg.BaseBackoff
+	for i := 1; i < attempts && d < im.cfg.MaxBackoff; i++ {
+		d *= 2
+	}
+	if d > im.cfg.MaxBackoff {
+		d = im.cfg.MaxBackoff
+	}
+	return d
+}
+
+func (im *impl) RegisterEndpoint(ctx context.Context, accountID, rawURL string, eventTypes []mWebhook.EventType) (*mWebhook.Endpoint, error) {
+	if !validURL(ctx, rawURL) {
+		return nil, ErrInvalidURL
+	}
+
+	if len(eventTypes) == 0 {
+		return nil, ErrInvalidEventType
+	}
+	types := make([]string, 0, len(eventTypes))
+	for _, t := range eventTypes {
+		if !t.IsValid() {
+			return nil, ErrInvalidEventType
+		}
+		types = append(types, string(t))
+	}
+
+	endpoints, err := im.webhook.ListEndpoints(ctx, accountID)
+	if err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("webhook.ListEndpoints failed in RegisterEndpoint")
+		return nil, err
+	} else if len(endpoints) >= im.cfg.MaxEndpoints {
+		return nil, ErrTooManyEndpoints
+	}
+
+	endpointID, err := util.GetUUIDv4()
+	if err != nil {
+		return nil, err
+	}
+	secret, err := newSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := &mWebhook.Endpoint{
+		EndpointID: endpointID,
+		AccountID:  accountID,
+		URL:        rawURL,
+		Secret:     secret,
+		EventTypes: strings.Join(types, ","),
+		CreatedMs:  timeNowMs(),
+	}
+	if err := im.webhook.CreateEndpoint(ctx, endpoint); err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("webhook.CreateEndpoint failed in RegisterEndpoint")
+		return nil, err
+	}
+
+	return endpoint, nil
+}
+
+func (im *impl) ListEndpoints(ctx context.Context, accountID string) ([]*mWebhook.Endpoint, error) {
+	endpoints, err := im.webhook.ListEndpoints(ctx, accountID)
+	if err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("webhook.ListEndpoints failed in ListEndpoints")
+		return nil, err
+	}
+	return endpoints, nil
+}
+
+func (im *impl) DeleteEndpoint(ctx context.Context, accountID, endpointID string) error {
+	if err := im.webhook.DeleteEndpoint(ctx, accountID, endpointID); err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("webhook.DeleteEndpoint failed in DeleteEndpoint")
+		return err
+	}
+	return nil
+}
+
+func (im *impl) ListDeliveries(ctx context.Context, accountID, endpointID string, status mWebhook.DeliveryStatus, offset, limit int) ([]*mWebhook.Delivery, error) {
+	deliveries, err := im.webhook.ListDeliveries(ctx, accountID, endpointID, status, offset, limit)
+	if err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("webhook.ListDeliveries failed in ListDeliveries")
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+func (im *impl) Redeliver(ctx context.Context, accountID, endpointID, deliveryID string) error {
+	delivery, err := im.webhook.GetDelivery(ctx, accountID, deliveryID)
+	if err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("webhook.GetDelivery failed in Redeliver")
+		return err
+	} else if delivery.EndpointID != endpointID {
+		return webhook.ErrDeliveryNotExist
+	}
+
+	nowMs := timeNowMs()
+	delivery.Status = mWebhook.DeliveryStatus_PENDING
+	delivery.Attempts = 0
+	delivery.NextAttemptMs = nowMs
+	delivery.UpdatedMs = nowMs
+	if err := im.webhook.UpdateDelivery(ctx, delivery); err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("webhook.UpdateDelivery failed in Redeliver")
+		return err
+	}
+	return nil
+}
+
+func (im *impl) HandleEvent(ctx context.Context, event *mEvent.Event) error {
+	t, ok := eventType(event)
+	if !ok {
+		return nil
+	}
+
+	endpoints, err := im.webhook.ListEndpoints(ctx, event.AccountID)
+	if err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("webhook.ListEndpoints failed in HandleEvent")
+		return err
+	}
+
+	nowMs := timeNowMs()
+	deliveries := []*mWebhook.Delivery{}
+	for _, endpoint := range endpoints {
+		if !endpoint.Subscribes(t) {
+			continue
+		}
+
+		deliveryID, err := util.GetUUIDv4()
+		if err != nil {
+			return err
+		}
+
+		payload, err := json.Marshal(newPayload(deliveryID, t, event))
+		if err != nil {
+			return err
+		}
+
+		deliveries = append(deliveries, &mWebhook.Delivery{
+			DeliveryID:    deliveryID,
+			EndpointID:    endpoint.EndpointID,
+			AccountID:     event.AccountID,
+			EventID:       event.EventID,
+			EventType:     t,
+			Payload:       string(payload),
+			Status:        mWebhook.DeliveryStatus_PENDING,
+			NextAttemptMs: nowMs,
+			CreatedMs:     nowMs,
+			UpdatedMs:     nowMs,
+		})
+	}
+
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	// the event may be relayed more than once, deliveries of the same event and endpoint are ignored
+	if err := im.webhook.CreateDeliveries(ctx, deliveries...); err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("webhook.CreateDeliveries failed in HandleEvent")
+		return err
+	}
+	return nil
+}
+
+// newPayload builds the body of the delivery of the event
+func newPayload(deliveryID string, t mWebhook.EventType, event *mEvent.Event) *mWebhook.Payload {
+	payload := &mWebhook.Payload{
+		DeliveryID:  deliveryID,
+		EventID:     event.EventID,
+		Type:        t,
+		AccountID:   event.AccountID,
+		TimestampMs: event.TimestampMs,
+	}
+
+	if event.Bill != nil {
+		payload.Bill = &mWebhook.BillData{
+			BillID:      event.Bill.BillID,
+			OrganizerID: event.Bill.OrganizerID,
+			Amount:      event.Bill.Amount,
+			Memo:        event.Bill.Memo,
+			Reminders:   event.Bill.Reminders,
+		}
+		return payload
+	}
+
+	payload.Data = &mWebhook.Data{
+		TradeID:        event.Trade.TradeID,
+		CounterpartyID: counterparty(event),
+		Amount:         event.Trade.Amount,
+		BalanceAfter:   event.Trade.BalanceAfter,
+	}
+	return payload
+}
+
+// counterparty hides system accounts from partners
+func counterparty(e *mEvent.Event) string {
+	if mBank.IsSystemAccount(e.Trade.CounterpartyID) {
+		return ""
+	}
+	return e.Trade.CounterpartyID
+}
+
+// send posts the delivery and returns the response status code
+func (im *impl) send(ctx context.Context, endpoint *mWebhook.Endpoint, delivery *mWebhook.Delivery) (int, error) {
+	body := []byte(delivery.Payload)
+	timestamp := timeNow().Unix()
+
+	req, err := http.NewRequest(http.MethodPost, endpoint.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderDeliveryID, delivery.DeliveryID)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(endpoint.Secret, timestamp, body))
+
+	resp, err := im.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	// drain body to reuse the connection
+	io.Copy(ioutil.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// deliver sends the delivery and moves it to the next state
+func (im *impl) deliver(ctx context.Context, delivery *mWebhook.Delivery) error {
+	endpoint, err := im.webhook.GetEndpoint(ctx, delivery.EndpointID)
+	if err == webhook.ErrEndpointNotExist {
+		// the endpoint is deleted, nowhere to send
+		delivery.Status = mWebhook.DeliveryStatus_DEAD
+		delivery.LastError = err.Error()
+	} else if err != nil {
+		return err
+	} else {
+		code, err := im.send(ctx, endpoint, delivery)
+		delivery.Attempts++
+		delivery.LastStatusCode = code
+		delivery.LastError = ""
+		switch {
+		case err == nil:
+			delivery.Status = mWebhook.DeliveryStatus_SUCCEEDED
+		case delivery.Attempts >= im.cfg.MaxAttempts:
+			delivery.Status = mWebhook.DeliveryStatus_DEAD
+			delivery.LastError = err.Error()
+		default:
+			delivery.NextAttemptMs = timeNowMs() + im.backoff(delivery.Attempts).Milliseconds()
+			delivery.LastError = err.Error()
+		}
+	}
+
+	if len(delivery.LastError) > maxErrorLen {
+		delivery.LastError = delivery.LastError[:maxErrorLen]
+	}
+	delivery.UpdatedMs = timeNowMs()
+	return im.webhook.UpdateDelivery(ctx, delivery)
+}
+
+func (im *impl) DispatchOnce(ctx context.Context) (int, error) {
+	nowMs := timeNowMs()
+	deliveries, err := im.webhook.ClaimDue(ctx, nowMs, nowMs+im.cfg.Lease.Milliseconds(), im.cfg.BatchSize)
+	if err != nil {
+		logger.FromContext(ctx).WithField("err", err).Error("webhook.ClaimDue failed in DispatchOnce")
+		return 0, err
+	}
+
+	for _, d := range deliveries {
+		// a delivery failed to save is sent again after its lease expires
+		if err := im.deliver(ctx, d); err != nil {
+			logger.FromContext(ctx).WithFields(logrus.Fields{
+				"err":        err,
+				"deliveryID": d.DeliveryID,
+			}).Error("deliver failed in DispatchOnce")
+		}
+	}
+	return len(deliveries), nil
+}
+
+func (im *impl) Run(ctx context.Context) {
+	ticker := time.NewTicker(im.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		n, err := im.DispatchOnce(ctx)
+		// keep draining without waiting while there is a backlog
+		if err == nil && n == im.cfg.BatchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
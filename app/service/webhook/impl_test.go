@@ -0,0 +1,487 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	mdBank "github.com/n3k0fi5t/wallet/app/models/bank"
+	mdEvent "github.com/n3k0fi5t/wallet/app/models/event"
+	mdWebhook "github.com/n3k0fi5t/wallet/app/models/webhook"
+	"github.com/n3k0fi5t/wallet/app/money"
+	"github.com/n3k0fi5t/wallet/app/repository/webhook"
+	mockWebhook "github.com/n3k0fi5t/wallet/app/repository/webhook/mocks"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+var (
+	mockCtx        = context.Background()
+	mockAccountID1 = "n3k0fi5t"
+	mockAccountID2 = "deadbeef"
+	mockWalletID   = "4b0f5b8e-8f0c-4d3e-9a7d-2f6c1e0b9a55"
+	mockEndpointID = "935f871a-660f-4f19-801e-916c04bb0324"
+	mockDeliveryID = "a89b7b78-b9c1-4129-8cff-380bf53f3a49"
+	mockSecret     = "whsec_test"
+	mockTime       = time.Unix(1650000000, 0)
+	mockTimeMs     = int64(1650000000000)
+	mockConfig     = Config{
+		MaxEndpoints: 2,
+		MaxAttempts:  3,
+		BaseBackoff:  time.Second,
+		MaxBackoff:   3 * time.Second,
+		Lease:        time.Minute,
+		PollInterval: time.Millisecond,
+		BatchSize:    10,
+	}
+
+	anyDelivery = mock.AnythingOfType("*webhook.Delivery")
+)
+
+type testSuite struct {
+	suite.Suite
+	srv      Service
+	mWebhook *mockWebhook.Webhook
+
+	receiver     *httptest.Server
+	receiverCode int
+	received     []*http.Request
+	receivedBody [][]byte
+}
+
+func (s *testSuite) SetupSuite() {
+	timeNow = func() time.Time { return mockTime }
+	timeNowMs = func() int64 { return mockTimeMs }
+	lookupIPAddr = func(ctx context.Context, host string) ([]net.IPAddr, error) {
+		if ip := net.ParseIP(host); ip != nil {
+			return []net.IPAddr{{IP: ip}}, nil
+		}
+		hosts := map[string][]net.IPAddr{
+			"partner.example.com":  {{IP: net.ParseIP("93.184.216.34")}},
+			"internal.example.com": {{IP: net.ParseIP("93.184.216.34")}, {IP: net.ParseIP("10.0.0.5")}},
+		}
+		if addrs, ok := hosts[host]; ok {
+			return addrs, nil
+		}
+		return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+
+	s.receiver = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		s.Require().NoError(err)
+		s.received = append(s.received, r)
+		s.receivedBody = append(s.receivedBody, body)
+		w.WriteHeader(s.receiverCode)
+	}))
+}
+
+func (s *testSuite) TearDownSuite() {
+	s.receiver.Close()
+}
+
+func (s *testSuite) SetupTest() {
+	s.mWebhook = &mockWebhook.Webhook{}
+	s.srv = NewWebhook(s.mWebhook, s.receiver.Client(), mockConfig)
+	s.receiverCode = http.StatusOK
+	s.received = nil
+	s.receivedBody = nil
+}
+
+func (s *testSuite) TearDownTest() {
+	s.mWebhook.AssertExpectations(s.T())
+}
+
+func (s *testSuite) endpoint(types string) *mdWebhook.Endpoint {
+	return &mdWebhook.Endpoint{
+		EndpointID: mockEndpointID,
+		AccountID:  mockAccountID1,
+		URL:        s.receiver.URL + "/hook",
+		Secret:     mockSecret,
+		EventTypes: types,
+	}
+}
+
+func (s *testSuite) TestRegisterEndpoint() {
+	tests := []struct {
+		Desc     string
+		URL      string
+		Types    []mdWebhook.EventType
+		ExpError error
+		setup    func()
+	}{
+		{
+			Desc:  "normal Path",
+			URL:   "https://partner.example.com/hook",
+			Types: []mdWebhook.EventType{mdWebhook.EventType_DEPOSIT, mdWebhook.EventType_TRANSFER_RECEIVED},
+			setup: func() {
+				s.mWebhook.On("ListEndpoints", mockCtx, mockAccountID1).Return([]*mdWebhook.Endpoint{}, nil).Once()
+				s.mWebhook.On("CreateEndpoint", mockCtx, mock.MatchedBy(func(e *mdWebhook.Endpoint) bool {
+					return e.AccountID == mockAccountID1 && e.EventTypes == "deposit,transfer.received" && len(e.Secret) > len(secretPrefix)
+				})).Return(nil).Once()
+			},
+		},
+		{
+			Desc:     "bad Path, relative URL",
+			URL:      "/hook",
+			Types:    []mdWebhook.EventType{mdWebhook.EventType_DEPOSIT},
+			ExpError: ErrInvalidURL,
+		},
+		{
+			Desc:     "bad Path, unknown scheme",
+			URL:      "ftp://partner.example.com/hook",
+			Types:    []mdWebhook.EventType{mdWebhook.EventType_DEPOSIT},
+			ExpError: ErrInvalidURL,
+		},
+		{
+			Desc:     "bad Path, loopback",
+			URL:      "http://127.0.0.1:8080/hook",
+			Types:    []mdWebhook.EventType{mdWebhook.EventType_DEPOSIT},
+			ExpError: ErrInvalidURL,
+		},
+		{
+			Desc:     "bad Path, cloud metadata",
+			URL:      "http://169.254.169.254/latest/meta-data",
+			Types:    []mdWebhook.EventType{mdWebhook.EventType_DEPOSIT},
+			ExpError: ErrInvalidURL,
+		},
+		{
+			Desc:     "bad Path, IPv4 mapped private address",
+			URL:      "http://[::ffff:192.168.1.1]/hook",
+			Types:    []mdWebhook.EventType{mdWebhook.EventType_DEPOSIT},
+			ExpError: ErrInvalidURL,
+		},
+		{
+			Desc:     "bad Path, host resolves to a private address",
+			URL:      "https://internal.example.com/hook",
+			Types:    []mdWebhook.EventType{mdWebhook.EventType_DEPOSIT},
+			ExpError: ErrInvalidURL,
+		},
+		{
+			Desc:     "bad Path, host not resolved",
+			URL:      "https://unknown.example.com/hook",
+			Types:    []mdWebhook.EventType{mdWebhook.EventType_DEPOSIT},
+			ExpError: ErrInvalidURL,
+		},
+		{
+			Desc:     "bad Path, unknown event type",
+			URL:      "https://partner.example.com/hook",
+			Types:    []mdWebhook.EventType{"refund"},
+			ExpError: ErrInvalidEventType,
+		},
+		{
+			Desc:     "bad Path, no event type",
+			URL:      "https://partner.example.com/hook",
+			ExpError: ErrInvalidEventType,
+		},
+		{
+			Desc:     "bad Path, too many endpoints",
+			URL:      "https://partner.example.com/hook",
+			Types:    []mdWebhook.EventType{mdWebhook.EventType_DEPOSIT},
+			ExpError: ErrTooManyEndpoints,
+			setup: func() {
+				s.mWebhook.On("ListEndpoints", mockCtx, mockAccountID1).Return([]*mdWebhook.Endpoint{{}, {}}, nil).Once()
+			},
+		},
+	}
+
+	for _, test := range tests {
+		s.SetupTest()
+		if test.setup != nil {
+			test.setup()
+		}
+
+		endpoint, err := s.srv.RegisterEndpoint(mockCtx, mockAccountID1, test.URL, test.Types)
+		s.Require().Equal(test.ExpError, err, test.Desc)
+		if test.ExpError == nil {
+			s.Require().Equal(test.URL, endpoint.URL, test.Desc)
+		}
+
+		s.TearDownTest()
+	}
+}
+
+func (s *testSuite) TestHandleEvent() {
+	tests := []struct {
+		Desc    string
+		Event   *mdEvent.Event
+		ExpType mdWebhook.EventType
+		setup   func()
+	}{
+		{
+			Desc: "transfer received",
+			Event: &mdEvent.Event{EventID: "e1", Type: mdEvent.Type_TRADE_COMPLETED, AccountID: mockAccountID1,
+				Trade: &mdEvent.Trade{TradeID: "t1", CounterpartyID: mockAccountID2, Direction: mdEvent.Direction_CREDIT, Amount: money.New(100, "USD")}},
+			ExpType: mdWebhook.EventType_TRANSFER_RECEIVED,
+		},
+		{
+			Desc: "transfer sent",
+			Event: &mdEvent.Event{EventID: "e2", Type: mdEvent.Type_TRADE_COMPLETED, AccountID: mockAccountID1,
+				Trade: &mdEvent.Trade{TradeID: "t2", CounterpartyID: mockAccountID2, Direction: mdEvent.Direction_DEBIT, Amount: money.New(100, "USD")}},
+			ExpType: mdWebhook.EventType_TRANSFER_SENT,
+		},
+		{
+			Desc: "deposit",
+			Event: &mdEvent.Event{EventID: "e3", Type: mdEvent.Type_TRADE_COMPLETED, AccountID: mockAccountID1,
+				Trade: &mdEvent.Trade{TradeID: "t3", CounterpartyID: mdBank.FundingAccountID(mdBank.Rail_DIRECT, "USD"), Direction: mdEvent.Direction_CREDIT, Amount: money.New(100, "USD")}},
+			ExpType: mdWebhook.EventType_DEPOSIT,
+		},
+		{
+			Desc: "withdraw",
+			Event: &mdEvent.Event{EventID: "e4", Type: mdEvent.Type_TRADE_COMPLETED, AccountID: mockAccountID1,
+				Trade: &mdEvent.Trade{TradeID: "t4", CounterpartyID: mdBank.FundingAccountID(mdBank.Rail_DIRECT, "USD"), Direction: mdEvent.Direction_DEBIT, Amount: money.New(100, "USD")}},
+			ExpType: mdWebhook.EventType_WITHDRAW,
+		},
+		{
+			Desc: "bill reminder",
+			Event: &mdEvent.Event{EventID: "e5", Type: mdEvent.Type_BILL_REMINDER, AccountID: mockAccountID1,
+				Bill: &mdEvent.Bill{BillID: "b1", OrganizerID: mockAccountID2, Amount: money.New(300, "USD"), Memo: "dinner", Reminders: 1}},
+			ExpType: mdWebhook.EventType_BILL_REMINDER,
+		},
+	}
+
+	for _, test := range tests {
+		s.SetupTest()
+		// the other endpoint subscribes nothing matching
+		other := &mdWebhook.Endpoint{EndpointID: "other", EventTypes: "none"}
+		s.mWebhook.On("ListEndpoints", mockCtx, mockAccountID1).Return([]*mdWebhook.Endpoint{s.endpoint("deposit,withdraw,transfer.received,transfer.sent,bill.reminder"), other}, nil).Once()
+
+		var created *mdWebhook.Delivery
+		s.mWebhook.On("CreateDeliveries", mockCtx, anyDelivery).Run(func(args mock.Arguments) {
+			created = args.Get(1).(*mdWebhook.Delivery)
+		}).Return(nil).Once()
+
+		err := s.srv.HandleEvent(mockCtx, test.Event)
+		s.Require().NoError(err, test.Desc)
+		s.Require().Equal(test.ExpType, created.EventType, test.Desc)
+		s.Require().Equal(mockEndpointID, created.EndpointID, test.Desc)
+		s.Require().Equal(mdWebhook.DeliveryStatus_PENDING, created.Status, test.Desc)
+
+		payload := &mdWebhook.Payload{}
+		s.Require().NoError(json.Unmarshal([]byte(created.Payload), payload), test.Desc)
+		s.Require().Equal(test.Event.EventID, payload.EventID, test.Desc)
+		s.Require().Equal(created.DeliveryID, payload.DeliveryID, test.Desc)
+		if test.ExpType == mdWebhook.EventType_DEPOSIT || test.ExpType == mdWebhook.EventType_WITHDRAW {
+			s.Require().Empty(payload.Data.CounterpartyID, test.Desc)
+		}
+		if test.ExpType == mdWebhook.EventType_BILL_REMINDER {
+			s.Require().Nil(payload.Data, test.Desc)
+			s.Require().Equal(&mdWebhook.BillData{BillID: "b1", OrganizerID: mockAccountID2, Amount: money.New(300, "USD"), Memo: "dinner", Reminders: 1}, payload.Bill, test.Desc)
+		} else {
+			s.Require().Nil(payload.Bill, test.Desc)
+			s.Require().Equal(money.New(100, "USD"), payload.Data.Amount, test.Desc)
+		}
+
+		s.TearDownTest()
+	}
+}
+
+func (s *testSuite) TestHandleEventOfWallet() {
+	s.SetupTest()
+
+	// endpoints registered on a joint or other non-default wallet receive its trades
+	endpoint := s.endpoint("transfer.received")
+	endpoint.AccountID = mockWalletID
+	s.mWebhook.On("ListEndpoints", mockCtx, mockWalletID).Return([]*mdWebhook.Endpoint{endpoint}, nil).Once()
+
+	var created *mdWebhook.Delivery
+	s.mWebhook.On("CreateDeliveries", mockCtx, anyDelivery).Run(func(args mock.Arguments) {
+		created = args.Get(1).(*mdWebhook.Delivery)
+	}).Return(nil).Once()
+
+	err := s.srv.HandleEvent(mockCtx, &mdEvent.Event{EventID: "e1", Type: mdEvent.Type_TRADE_COMPLETED, AccountID: mockWalletID,
+		Trade: &mdEvent.Trade{TradeID: "t1", CounterpartyID: mockAccountID2, Direction: mdEvent.Direction_CREDIT, Amount: money.New(100, "USD")}})
+	s.Require().NoError(err)
+	s.Require().Equal(mockEndpointID, created.EndpointID)
+	s.Require().Equal(mockWalletID, created.AccountID)
+
+	payload := &mdWebhook.Payload{}
+	s.Require().NoError(json.Unmarshal([]byte(created.Payload), payload))
+	s.Require().Equal(mockWalletID, payload.AccountID)
+
+	s.TearDownTest()
+}
+
+func (s *testSuite) TestHandleEventIgnored() {
+	s.SetupTest()
+
+	// failed trades are not sent and no endpoint is queried
+	err := s.srv.HandleEvent(mockCtx, &mdEvent.Event{Type: mdEvent.Type_TRADE_FAILED, AccountID: mockAccountID1, Trade: &mdEvent.Trade{}})
+	s.Require().NoError(err)
+
+	// no subscribed endpoint, no delivery
+	s.mWebhook.On("ListEndpoints", mockCtx, mockAccountID1).Return([]*mdWebhook.Endpoint{s.endpoint("withdraw")}, nil).Once()
+	err = s.srv.HandleEvent(mockCtx, &mdEvent.Event{Type: mdEvent.Type_TRADE_COMPLETED, AccountID: mockAccountID1,
+		Trade: &mdEvent.Trade{CounterpartyID: mockAccountID2, Direction: mdEvent.Direction_CREDIT}})
+	s.Require().NoError(err)
+
+	s.TearDownTest()
+}
+
+func (s *testSuite) TestDispatchOnce() {
+	tests := []struct {
+		Desc        string
+		Code        int
+		Attempts    int
+		ExpStatus   mdWebhook.DeliveryStatus
+		ExpAttempts int
+		ExpNextMs   int64
+	}{
+		{
+			Desc:        "delivered",
+			Code:        http.StatusNoContent,
+			ExpStatus:   mdWebhook.DeliveryStatus_SUCCEEDED,
+			ExpAttempts: 1,
+			ExpNextMs:   mockTimeMs,
+		},
+		{
+			Desc:        "first failure backs off base",
+			Code:        http.StatusInternalServerError,
+			ExpStatus:   mdWebhook.DeliveryStatus_PENDING,
+			ExpAttempts: 1,
+			ExpNextMs:   mockTimeMs + 1000,
+		},
+		{
+			Desc:        "second failure doubles",
+			Code:        http.StatusBadGateway,
+			Attempts:    1,
+			ExpStatus:   mdWebhook.DeliveryStatus_PENDING,
+			ExpAttempts: 2,
+			ExpNextMs:   mockTimeMs + 2000,
+		},
+		{
+			Desc:        "retries exhausted goes dead",
+			Code:        http.StatusBadGateway,
+			Attempts:    2,
+			ExpStatus:   mdWebhook.DeliveryStatus_DEAD,
+			ExpAttempts: 3,
+			ExpNextMs:   mockTimeMs,
+		},
+	}
+
+	for _, test := range tests {
+		s.SetupTest()
+		s.receiverCode = test.Code
+
+		payload := `{"type":"deposit"}`
+		delivery := &mdWebhook.Delivery{
+			DeliveryID:    mockDeliveryID,
+			EndpointID:    mockEndpointID,
+			AccountID:     mockAccountID1,
+			Payload:       payload,
+			Status:        mdWebhook.DeliveryStatus_PENDING,
+			Attempts:      test.Attempts,
+			NextAttemptMs: mockTimeMs,
+		}
+		s.mWebhook.On("ClaimDue", mockCtx, mockTimeMs, mockTimeMs+mockConfig.Lease.Milliseconds(), mockConfig.BatchSize).Return([]*mdWebhook.Delivery{delivery}, nil).Once()
+		s.mWebhook.On("GetEndpoint", mockCtx, mockEndpointID).Return(s.endpoint("deposit"), nil).Once()
+		s.mWebhook.On("UpdateDelivery", mockCtx, delivery).Return(nil).Once()
+
+		n, err := s.srv.DispatchOnce(mockCtx)
+		s.Require().NoError(err, test.Desc)
+		s.Require().Equal(1, n, test.Desc)
+		s.Require().Equal(test.ExpStatus, delivery.Status, test.Desc)
+		s.Require().Equal(test.ExpAttempts, delivery.Attempts, test.Desc)
+		s.Require().Equal(test.ExpNextMs, delivery.NextAttemptMs, test.Desc)
+		s.Require().Equal(test.Code, delivery.LastStatusCode, test.Desc)
+
+		// the receiver verifies the signature with its secret
+		s.Require().Len(s.received, 1, test.Desc)
+		req := s.received[0]
+		timestamp, err := strconv.ParseInt(req.Header.Get(HeaderTimestamp), 10, 64)
+		s.Require().NoError(err, test.Desc)
+		s.Require().Equal(mockTime.Unix(), timestamp, test.Desc)
+		s.Require().Equal(mockDeliveryID, req.Header.Get(HeaderDeliveryID), test.Desc)
+		s.Require().Equal(payload, string(s.receivedBody[0]), test.Desc)
+		s.Require().True(Verify(mockSecret, timestamp, s.receivedBody[0], req.Header.Get(HeaderSignature)), test.Desc)
+		s.Require().False(Verify("whsec_other", timestamp, s.receivedBody[0], req.Header.Get(HeaderSignature)), test.Desc)
+
+		s.TearDownTest()
+	}
+}
+
+func (s *testSuite) TestDispatchDeletedEndpoint() {
+	s.SetupTest()
+
+	delivery := &mdWebhook.Delivery{DeliveryID: mockDeliveryID, EndpointID: mockEndpointID, Status: mdWebhook.DeliveryStatus_PENDING}
+	s.mWebhook.On("ClaimDue", mockCtx, mockTimeMs, mock.Anything, mockConfig.BatchSize).Return([]*mdWebhook.Delivery{delivery}, nil).Once()
+	s.mWebhook.On("GetEndpoint", mockCtx, mockEndpointID).Return(nil, webhook.ErrEndpointNotExist).Once()
+	s.mWebhook.On("UpdateDelivery", mockCtx, delivery).Return(nil).Once()
+
+	_, err := s.srv.DispatchOnce(mockCtx)
+	s.Require().NoError(err)
+	s.Require().Equal(mdWebhook.DeliveryStatus_DEAD, delivery.Status)
+	s.Require().Empty(s.received)
+
+	s.TearDownTest()
+}
+
+func (s *testSuite) TestRedeliver() {
+	tests := []struct {
+		Desc       string
+		EndpointID string
+		ExpError   error
+		setup      func()
+	}{
+		{
+			Desc:       "normal Path",
+			EndpointID: mockEndpointID,
+			setup: func() {
+				dead := &mdWebhook.Delivery{DeliveryID: mockDeliveryID, EndpointID: mockEndpointID, Status: mdWebhook.DeliveryStatus_DEAD, Attempts: 3}
+				s.mWebhook.On("GetDelivery", mockCtx, mockAccountID1, mockDeliveryID).Return(dead, nil).Once()
+				s.mWebhook.On("UpdateDelivery", mockCtx, &mdWebhook.Delivery{
+					DeliveryID:    mockDeliveryID,
+					EndpointID:    mockEndpointID,
+					Status:        mdWebhook.DeliveryStatus_PENDING,
+					NextAttemptMs: mockTimeMs,
+					UpdatedMs:     mockTimeMs,
+				}).Return(nil).Once()
+			},
+		},
+		{
+			Desc:       "bad Path, delivery of another endpoint",
+			EndpointID: "other",
+			ExpError:   webhook.ErrDeliveryNotExist,
+			setup: func() {
+				s.mWebhook.On("GetDelivery", mockCtx, mockAccountID1, mockDeliveryID).Return(&mdWebhook.Delivery{EndpointID: mockEndpointID}, nil).Once()
+			},
+		},
+		{
+			Desc:       "bad Path, delivery not exist",
+			EndpointID: mockEndpointID,
+			ExpError:   webhook.ErrDeliveryNotExist,
+			setup: func() {
+				s.mWebhook.On("GetDelivery", mockCtx, mockAccountID1, mockDeliveryID).Return(nil, webhook.ErrDeliveryNotExist).Once()
+			},
+		},
+	}
+
+	for _, test := range tests {
+		s.SetupTest()
+		if test.setup != nil {
+			test.setup()
+		}
+
+		err := s.srv.Redeliver(mockCtx, mockAccountID1, test.EndpointID, mockDeliveryID)
+		s.Require().Equal(test.ExpError, err, test.Desc)
+
+		s.TearDownTest()
+	}
+}
+
+func (s *testSuite) TestNewClient() {
+	// the receiver listens on loopback, which deliveries must not reach
+	_, err := NewClient(time.Second).Get(s.receiver.URL + "/hook")
+	s.Require().Error(err)
+	s.Require().Contains(err.Error(), errForbiddenAddress.Error())
+	s.Require().Empty(s.received)
+}
+
+func TestSuite(t *testing.T) {
+	suite.Run(t, new(testSuite))
+}
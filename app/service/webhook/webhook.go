@@ -0,0 +1,100 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"time"
+
+	mEvent "github.com/n3k0fi5t/wallet/app/models/event"
+	mWebhook "github.com/n3k0fi5t/wallet/app/models/webhook"
+)
+
+const (
+	// HeaderSignature carries "sha256=" and hex HMAC-SHA256 of "{timestamp}.{body}" keyed by the endpoint secret
+	HeaderSignature = "X-Wallet-Signature"
+	// HeaderTimestamp carries unix seconds when the delivery is signed, receivers should reject stale ones
+	HeaderTimestamp = "X-Wallet-Timestamp"
+	// HeaderDeliveryID carries the delivery ID, receivers should dedupe by it
+	HeaderDeliveryID = "X-Wallet-Delivery"
+)
+
+var (
+	// ErrInvalidURL means the endpoint URL is not an absolute http(s) URL of a public host
+	ErrInvalidURL = fmt.Errorf("Invalid URL")
+
+	// ErrInvalidEventType means the event type is unknown
+	ErrInvalidEventType = fmt.Errorf("Invalid event type")
+
+	// ErrTooManyEndpoints means the account reaches the max number of endpoints
+	ErrTooManyEndpoints = fmt.Errorf("Too many endpoints")
+)
+
+// Config controls retries and polling of deliveries
+type Config struct {
+	// MaxEndpoints is the max number of endpoints per account
+	MaxEndpoints int
+	// MaxAttempts is the number of attempts before a delivery goes dead
+	MaxAttempts int
+	// BaseBackoff is the wait after the first failed attempt, it doubles after each failure up to MaxBackoff
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+	// Lease is how long a claimed delivery is hidden from other dispatchers
+	Lease        time.Duration
+	PollInterval time.Duration
+	BatchSize    int
+}
+
+// DefaultConfig retries for about 4 hours
+var DefaultConfig = Config{
+	MaxEndpoints: 10,
+	MaxAttempts:  8,
+	BaseBackoff:  30 * time.Second,
+	MaxBackoff:   time.Hour,
+	Lease:        time.Minute,
+	PollInterval: time.Second,
+	BatchSize:    50,
+}
+
+type Service interface {
+	// RegisterEndpoint registers an endpoint of the account, the returned endpoint carries the signing secret
+	RegisterEndpoint(ctx context.Context, accountID, url string, eventTypes []mWebhook.EventType) (*mWebhook.Endpoint, error)
+
+	// ListEndpoints list endpoints of the account
+	ListEndpoints(ctx context.Context, accountID string) ([]*mWebhook.Endpoint, error)
+
+	// DeleteEndpoint deletes an endpoint of the account
+	DeleteEndpoint(ctx context.Context, accountID, endpointID string) error
+
+	// ListDeliveries list deliveries of the account's endpoint, newest first, empty status for all
+	ListDeliveries(ctx context.Context, accountID, endpointID string, status mWebhook.DeliveryStatus, offset, limit int) ([]*mWebhook.Delivery, error)
+
+	// Redeliver sends the delivery again from the first attempt, e.g. a dead one after the endpoint is fixed
+	Redeliver(ctx context.Context, accountID, endpointID, deliveryID string) error
+
+	// HandleEvent creates deliveries of a trade event for subscribed endpoints
+	HandleEvent(ctx context.Context, event *mEvent.Event) error
+
+	// DispatchOnce sends one batch of due deliveries and returns the number of sent deliveries
+	DispatchOnce(ctx context.Context) (int, error)
+
+	// Run keeps dispatching until context is done
+	Run(ctx context.Context)
+}
+
+// Sign returns the signature header value of the body
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks the signature header value in constant time
+func Verify(secret string, timestamp int64, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
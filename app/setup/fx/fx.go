@@ -0,0 +1,76 @@
+package fx
+
+import (
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	_ "github.com/joho/godotenv/autoload"
+	"github.com/n3k0fi5t/wallet/app/fxrate"
+	"github.com/n3k0fi5t/wallet/app/service/fx"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	httpTimeout = 5 * time.Second
+)
+
+var (
+	// fxRateProvider is where rates come from, one of "" (static FX_RATES), "file" and "http"
+	fxRateProvider = os.Getenv("FX_RATE_PROVIDER")
+	// fxRates are static rates in "USD/EUR=0.92,USD/JPY=151.25" format
+	fxRates    = os.Getenv("FX_RATES")
+	fxRateFile = os.Getenv("FX_RATE_FILE")
+	fxRateURL  = os.Getenv("FX_RATE_URL")
+
+	fxSpreadBps = os.Getenv("FX_SPREAD_BPS")
+	fxQuoteTTL  = os.Getenv("FX_QUOTE_TTL")
+)
+
+// GetProvider returns the rate provider configured by FX_RATE_PROVIDER
+func GetProvider() fxrate.Provider {
+	switch fxRateProvider {
+	case "file":
+		return fxrate.NewFile(fxRateFile)
+	case "http":
+		return fxrate.NewHTTP(fxRateURL, &http.Client{Timeout: httpTimeout})
+	default:
+		rates, err := parseRates(fxRates)
+		if err != nil {
+			logrus.WithField("err", err).Error("invalid FX_RATES, no pair is priced")
+			rates = map[string]*big.Rat{}
+		}
+		return fxrate.NewStatic(rates)
+	}
+}
+
+func parseRates(v string) (map[string]*big.Rat, error) {
+	raw := map[string]string{}
+	for _, entry := range strings.Split(v, ",") {
+		if entry = strings.TrimSpace(entry); entry == "" {
+			continue
+		}
+		parts := strings.Split(entry, "=")
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("%q is not pair=rate", entry)
+		}
+		raw[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+	}
+	return fxrate.ParseRates(raw)
+}
+
+// GetConfig returns the default config overridden by FX_SPREAD_BPS and FX_QUOTE_TTL
+func GetConfig() fx.Config {
+	cfg := fx.DefaultConfig
+	if n, err := strconv.ParseInt(fxSpreadBps, 10, 64); err == nil && n >= 0 && n < 10000 {
+		cfg.SpreadBps = n
+	}
+	if d, err := time.ParseDuration(fxQuoteTTL); err == nil && d > 0 {
+		cfg.QuoteTTL = d
+	}
+	return cfg
+}
@@ -0,0 +1,36 @@
+package logger
+
+import (
+	"os"
+
+	_ "github.com/joho/godotenv/autoload"
+	"github.com/n3k0fi5t/wallet/common/logger"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	// logLevel is one of logrus levels, e.g. debug, info, warn and error
+	logLevel = os.Getenv("LOG_LEVEL")
+	// logFormat is one of "" (json) and "text"
+	logFormat = os.Getenv("LOG_FORMAT")
+)
+
+// Setup configures the standard logger, main calls it before anything logs
+func Setup() {
+	var formatter logrus.Formatter = &logrus.JSONFormatter{}
+	if logFormat == "text" {
+		formatter = &logrus.TextFormatter{FullTimestamp: true}
+	}
+	logrus.SetFormatter(&logger.RedactFormatter{Formatter: formatter})
+
+	level := logrus.InfoLevel
+	if logLevel != "" {
+		l, err := logrus.ParseLevel(logLevel)
+		if err != nil {
+			logrus.WithField("err", err).Error("invalid LOG_LEVEL, use info")
+		} else {
+			level = l
+		}
+	}
+	logrus.SetLevel(level)
+}
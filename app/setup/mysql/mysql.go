@@ -1,19 +1,28 @@
 package mysql
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"sync"
 	"time"
 
+	"github.com/XSAM/otelsql"
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/jmoiron/sqlx"
 	_ "github.com/joho/godotenv/autoload"
+	"github.com/sirupsen/logrus"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
 )
 
 const (
 	maxIdleConns = 10
 	maxOpenConns = 128
 	maxLifeTime  = 5 * time.Minute
+
+	// SchemaVersion is the version of migrations this build expects, bump it with migrations/init.sql
+	SchemaVersion      = 16
+	querySchemaVersion = "SELECT COALESCE(MAX(version), 0) FROM SchemaVersion"
 )
 
 var (
@@ -24,15 +33,21 @@ var (
 	dbPassword = os.Getenv("DB_PASSWORD")
 )
 
-var dbClient *sqlx.DB
+var (
+	dbClient     *sqlx.DB
+	dbClientOnce sync.Once
+)
 
-func init() {
-	db, err := sqlx.Open("mysql", getDSN())
+// connect opens the pool on first use, after main has set up the logger
+func connect() {
+	// each statement is traced as a child span of the caller
+	sqlDB, err := otelsql.Open("mysql", getDSN(), otelsql.WithAttributes(semconv.DBSystemMySQL))
 	if err != nil {
 		panic(err)
 	}
+	db := sqlx.NewDb(sqlDB, "mysql")
 
-	fmt.Printf("connect to %v\n", getDSN())
+	logrus.WithField("addr", fmt.Sprintf("%v:%v/%v", dbHost, dbPort, dbName)).Info("connect to MySQL")
 
 	// SetMaxOpenConns sets the maximum number of open connections to the database.
 	db.SetMaxOpenConns(maxOpenConns)
@@ -52,5 +67,17 @@ func getDSN() string {
 }
 
 func GetMySQL() *sqlx.DB {
+	dbClientOnce.Do(connect)
 	return dbClient
 }
+
+// CheckSchemaVersion fails if the migrations applied to DB are not the version this build expects
+func CheckSchemaVersion(ctx context.Context) error {
+	version := 0
+	if err := GetMySQL().GetContext(ctx, &version, querySchemaVersion); err != nil {
+		return err
+	} else if version != SchemaVersion {
+		return fmt.Errorf("schema version %d, expect %d", version, SchemaVersion)
+	}
+	return nil
+}
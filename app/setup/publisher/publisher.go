@@ -0,0 +1,62 @@
+package publisher
+
+import (
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	_ "github.com/joho/godotenv/autoload"
+	"github.com/n3k0fi5t/wallet/app/publisher"
+)
+
+const (
+	defaultPollInterval = time.Second
+	defaultBatchSize    = 100
+	httpTimeout         = 5 * time.Second
+)
+
+var (
+	// eventPublisher is the external publisher besides in-process subscribers, one of "", "file" and "http"
+	eventPublisher = os.Getenv("EVENT_PUBLISHER")
+	eventFilePath  = os.Getenv("EVENT_FILE_PATH")
+	eventHTTPURL   = os.Getenv("EVENT_HTTP_URL")
+
+	outboxPollInterval = os.Getenv("OUTBOX_POLL_INTERVAL")
+	outboxBatchSize    = os.Getenv("OUTBOX_BATCH_SIZE")
+)
+
+var inProcess = publisher.NewInProcess()
+
+// GetInProcess returns the in-process publisher for subscribers living in this service
+func GetInProcess() *publisher.InProcess {
+	return inProcess
+}
+
+// GetPublisher returns the publisher used by the outbox relay
+func GetPublisher() publisher.Publisher {
+	switch eventPublisher {
+	case "file":
+		return publisher.NewMulti(inProcess, publisher.NewFile(eventFilePath))
+	case "http":
+		return publisher.NewMulti(inProcess, publisher.NewHTTP(eventHTTPURL, &http.Client{Timeout: httpTimeout}))
+	default:
+		return inProcess
+	}
+}
+
+// GetPollInterval returns how often the outbox relay polls pending events
+func GetPollInterval() time.Duration {
+	if d, err := time.ParseDuration(outboxPollInterval); err == nil && d > 0 {
+		return d
+	}
+	return defaultPollInterval
+}
+
+// GetBatchSize returns the max number of events relayed in one round
+func GetBatchSize() int {
+	if n, err := strconv.Atoi(outboxBatchSize); err == nil && n > 0 {
+		return n
+	}
+	return defaultBatchSize
+}
@@ -0,0 +1,79 @@
+package rail
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	_ "github.com/joho/godotenv/autoload"
+	mBank "github.com/n3k0fi5t/wallet/app/models/bank"
+	"github.com/n3k0fi5t/wallet/app/rail"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	httpTimeout = 5 * time.Second
+
+	defaultSimulatorDelay = 5 * time.Second
+)
+
+var (
+	// fundingRails are the rails deposits and withdrawals go over asynchronously, comma separated, e.g. "simulator".
+	// None by default, the simulator credits money it never receives
+	fundingRails = os.Getenv("FUNDING_RAILS")
+
+	apiPort = os.Getenv("API_PORT")
+
+	// railSimulatorCallbackURL is where the simulator posts callbacks, the callback API of this service by default
+	railSimulatorCallbackURL = os.Getenv("RAIL_SIMULATOR_CALLBACK_URL")
+	// railSimulatorSecret signs callbacks of the simulator, a random one by default
+	railSimulatorSecret = os.Getenv("RAIL_SIMULATOR_SECRET")
+	railSimulatorDelay  = os.Getenv("RAIL_SIMULATOR_DELAY")
+)
+
+// GetAdapters returns the adapters of the rails in FUNDING_RAILS
+func GetAdapters() map[mBank.Rail]rail.Adapter {
+	adapters := map[mBank.Rail]rail.Adapter{}
+	for _, name := range strings.Split(fundingRails, ",") {
+		switch r := mBank.Rail(strings.TrimSpace(name)); r {
+		case "":
+		case mBank.Rail_SIMULATOR:
+			simulator, err := newSimulator()
+			if err != nil {
+				logrus.WithField("err", err).Error("new simulator failed, the rail is off")
+				continue
+			}
+			adapters[r] = simulator
+		default:
+			logrus.WithField("rail", r).Error("unknown rail in FUNDING_RAILS")
+		}
+	}
+	return adapters
+}
+
+func newSimulator() (*rail.Simulator, error) {
+	callbackURL := railSimulatorCallbackURL
+	if callbackURL == "" {
+		callbackURL = fmt.Sprintf("http://localhost:%s/api/v1/rails/%s/callbacks", apiPort, mBank.Rail_SIMULATOR)
+	}
+
+	// the simulator runs in this service, a random secret is shared by both sides
+	secret := railSimulatorSecret
+	if secret == "" {
+		b := make([]byte, 32)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+		secret = hex.EncodeToString(b)
+	}
+
+	delay := defaultSimulatorDelay
+	if d, err := time.ParseDuration(railSimulatorDelay); err == nil && d >= 0 {
+		delay = d
+	}
+	return rail.NewSimulator(callbackURL, secret, delay, &http.Client{Timeout: httpTimeout}), nil
+}
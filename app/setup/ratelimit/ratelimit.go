@@ -0,0 +1,76 @@
+package ratelimit
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/go-redis/redis/v8"
+	_ "github.com/joho/godotenv/autoload"
+	"github.com/n3k0fi5t/wallet/app/ratelimit"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	// rateLimitBackend is where buckets live, one of "" (in memory) and "redis"
+	rateLimitBackend   = os.Getenv("RATE_LIMIT_BACKEND")
+	rateLimitRedisAddr = os.Getenv("RATE_LIMIT_REDIS_ADDR")
+
+	// defaultPolicy allows more per IP than per account, since users behind NAT share an IP
+	defaultPolicy = ratelimit.Policy{
+		AccountRead:  ratelimit.Limit{Rate: 20, Burst: 40},
+		AccountWrite: ratelimit.Limit{Rate: 5, Burst: 10},
+		IPRead:       ratelimit.Limit{Rate: 50, Burst: 100},
+		IPWrite:      ratelimit.Limit{Rate: 20, Burst: 40},
+	}
+)
+
+// GetLimiter returns the limiter of the configured backend
+func GetLimiter() ratelimit.Limiter {
+	switch rateLimitBackend {
+	case "redis":
+		return ratelimit.NewRedis(redis.NewClient(&redis.Options{Addr: rateLimitRedisAddr}))
+	default:
+		return ratelimit.NewMemory()
+	}
+}
+
+// GetPolicy returns the default policy overridden by RATE_LIMIT_{ACCOUNT,IP}_{READ,WRITE} in "rate:burst" format, "0:0" disables one
+func GetPolicy() ratelimit.Policy {
+	p := defaultPolicy
+	for env, limit := range map[string]*ratelimit.Limit{
+		"RATE_LIMIT_ACCOUNT_READ":  &p.AccountRead,
+		"RATE_LIMIT_ACCOUNT_WRITE": &p.AccountWrite,
+		"RATE_LIMIT_IP_READ":       &p.IPRead,
+		"RATE_LIMIT_IP_WRITE":      &p.IPWrite,
+	} {
+		v := os.Getenv(env)
+		if v == "" {
+			continue
+		}
+		l, err := parseLimit(v)
+		if err != nil {
+			logrus.WithField("err", err).Errorf("invalid %s, use the default", env)
+			continue
+		}
+		*limit = l
+	}
+	return p
+}
+
+func parseLimit(v string) (ratelimit.Limit, error) {
+	parts := strings.Split(v, ":")
+	if len(parts) != 2 {
+		return ratelimit.Limit{}, fmt.Errorf("%q is not rate:burst", v)
+	}
+	rate, err := strconv.ParseFloat(parts[0], 64)
+	if err != nil || rate < 0 {
+		return ratelimit.Limit{}, fmt.Errorf("invalid rate %q", parts[0])
+	}
+	burst, err := strconv.Atoi(parts[1])
+	if err != nil || burst < 0 {
+		return ratelimit.Limit{}, fmt.Errorf("invalid burst %q", parts[1])
+	}
+	return ratelimit.Limit{Rate: rate, Burst: burst}, nil
+}
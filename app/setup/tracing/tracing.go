@@ -0,0 +1,53 @@
+package tracing
+
+import (
+	"context"
+	"os"
+
+	_ "github.com/joho/godotenv/autoload"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+)
+
+const (
+	// ServiceName is the name of this service in traces
+	ServiceName = "wallet"
+)
+
+var (
+	// tracesExporter is one of "", "otlp" and "stdout", the OTLP endpoint is configured by OTEL_EXPORTER_OTLP_ENDPOINT
+	tracesExporter = os.Getenv("OTEL_TRACES_EXPORTER")
+)
+
+// Init sets the global tracer provider and W3C propagators, the returned function flushes spans on shutdown.
+// Spans are created but not exported if no exporter is configured, trace context still propagates
+func Init(ctx context.Context) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	opts := []sdktrace.TracerProviderOption{
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceNameKey.String(ServiceName))),
+	}
+	switch tracesExporter {
+	case "otlp":
+		exp, err := otlptracegrpc.New(ctx)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, sdktrace.WithBatcher(exp))
+	case "stdout":
+		exp, err := stdouttrace.New(stdouttrace.WithPrettyPrint())
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, sdktrace.WithSyncer(exp))
+	}
+
+	tp := sdktrace.NewTracerProvider(opts...)
+	otel.SetTracerProvider(tp)
+	return tp.Shutdown, nil
+}
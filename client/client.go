@@ -0,0 +1,268 @@
+// Package client is the Go client of the wallet REST API.
+//
+// Reads and writes sent WithIdempotencyKey are retried on network errors, 429 and 5xx.
+// Errors of the wallet can be matched with errors.Is, e.g. errors.Is(err, client.ErrBalanceNotEnough).
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	apiPrefix = "/api/v1"
+
+	headerAuthorization  = "Authorization"
+	headerIdempotencyKey = "Idempotency-Key"
+	headerRetryAfter     = "Retry-After"
+
+	defaultTimeout     = 10 * time.Second
+	defaultMaxAttempts = 3
+	defaultBackoff     = 200 * time.Millisecond
+	maxBackoff         = 5 * time.Second
+)
+
+// Client calls wallet APIs as the user of the token, it is safe for concurrent use
+type Client struct {
+	baseURL    string
+	token      string
+	httpClient *http.Client
+
+	// timeout limits each attempt, the caller's context limits the whole call
+	timeout     time.Duration
+	maxAttempts int
+	backoff     time.Duration
+}
+
+// Option configures the Client
+type Option func(*Client)
+
+// WithHTTPClient replaces the default http.Client
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = hc
+	}
+}
+
+// WithTimeout limits each attempt of a call, 0 means no limit other than the context
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = timeout
+	}
+}
+
+// WithRetry sets the attempts of idempotent calls and the backoff before the first retry,
+// the backoff doubles on every retry. maxAttempts 1 disables retries.
+func WithRetry(maxAttempts int, backoff time.Duration) Option {
+	return func(c *Client) {
+		if maxAttempts < 1 {
+			maxAttempts = 1
+		}
+		c.maxAttempts = maxAttempts
+		c.backoff = backoff
+	}
+}
+
+// RequestOption configures a single request
+type RequestOption func(*http.Request)
+
+// WithIdempotencyKey makes the server execute retried writes once, writes with a key are retried by the client
+func WithIdempotencyKey(key string) RequestOption {
+	return func(r *http.Request) {
+		r.Header.Set(headerIdempotencyKey, key)
+	}
+}
+
+// New creates a client of the server at baseURL, e.g. http://localhost:8080
+func New(baseURL, token string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:     strings.TrimRight(baseURL, "/"),
+		token:       token,
+		httpClient:  &http.Client{},
+		timeout:     defaultTimeout,
+		maxAttempts: defaultMaxAttempts,
+		backoff:     defaultBackoff,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+type tradeParam struct {
+	Amount    Money  `json:"amount"`
+	ToAccount string `json:"toAccount,omitempty"`
+}
+
+// Deposit deposits money to the user's account, amount is in the currency of the account
+func (c *Client) Deposit(ctx context.Context, amount Money, opts ...RequestOption) (*TradeResult, error) {
+	res := &TradeResult{}
+	if err := c.do(ctx, http.MethodPost, "/wallet/deposit", nil, &tradeParam{Amount: amount}, res, opts...); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// Withdraw withdraws money from the user's account, amount is in the currency of the account
+func (c *Client) Withdraw(ctx context.Context, amount Money, opts ...RequestOption) (*TradeResult, error) {
+	res := &TradeResult{}
+	if err := c.do(ctx, http.MethodPost, "/wallet/withdraw", nil, &tradeParam{Amount: amount}, res, opts...); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// Transfer transfers money from the user's account to another of the same currency
+func (c *Client) Transfer(ctx context.Context, toAccount string, amount Money, opts ...RequestOption) (*TradeResult, error) {
+	res := &TradeResult{}
+	if err := c.do(ctx, http.MethodPost, "/wallet/transfer", nil, &tradeParam{Amount: amount, ToAccount: toAccount}, res, opts...); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// GetAccount gets the user's account information
+func (c *Client) GetAccount(ctx context.Context) (*Account, error) {
+	res := &Account{}
+	if err := c.do(ctx, http.MethodGet, "/wallet/account", nil, nil, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// ListTransactions lists transaction logs of the user's account, newest first
+func (c *Client) ListTransactions(ctx context.Context, offset, limit int) ([]*Transaction, error) {
+	query := url.Values{}
+	query.Set("offset", strconv.Itoa(offset))
+	query.Set("limit", strconv.Itoa(limit))
+
+	res := struct {
+		Transactions []*Transaction `json:"transactions"`
+	}{}
+	if err := c.do(ctx, http.MethodGet, "/wallet/account/transactions", query, nil, &res); err != nil {
+		return nil, err
+	}
+	return res.Transactions, nil
+}
+
+// GetTrade gets a trade the user takes part in
+func (c *Client) GetTrade(ctx context.Context, tradeID string) (*Trade, error) {
+	res := &Trade{}
+	if err := c.do(ctx, http.MethodGet, "/wallet/trades/"+url.PathEscape(tradeID), nil, nil, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, out interface{}, opts ...RequestOption) error {
+	u := c.baseURL + apiPrefix + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	var payload []byte
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		payload = b
+	}
+
+	for attempt := 1; ; attempt++ {
+		retryable, wait, err := c.attempt(ctx, method, u, payload, out, opts)
+		if err == nil || !retryable || attempt >= c.maxAttempts {
+			return err
+		}
+
+		if wait == 0 {
+			wait = c.backoffOf(attempt)
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// attempt sends the request once, it reports whether the failure can be retried and how long the server asks to wait
+func (c *Client) attempt(ctx context.Context, method, u string, payload []byte, out interface{}, opts []RequestOption) (bool, time.Duration, error) {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	var reader io.Reader
+	if payload != nil {
+		reader = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, reader)
+	if err != nil {
+		return false, 0, err
+	}
+	req.Header.Set(headerAuthorization, c.token)
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for _, opt := range opts {
+		opt(req)
+	}
+
+	// reads are idempotent, writes are idempotent only with a key
+	idempotent := method == http.MethodGet || req.Header.Get(headerIdempotencyKey) != ""
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return idempotent, 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		apiErr := &Error{StatusCode: resp.StatusCode}
+		errResp := struct {
+			ErrCode    string `json:"errCode"`
+			ErrMessage string `json:"errMessage"`
+		}{}
+		if err := json.NewDecoder(resp.Body).Decode(&errResp); err == nil {
+			apiErr.Code = errResp.ErrCode
+			apiErr.Message = errResp.ErrMessage
+		}
+		return idempotent && apiErr.Temporary(), retryAfter(resp), apiErr
+	}
+
+	if out == nil {
+		return false, 0, nil
+	}
+	return false, 0, json.NewDecoder(resp.Body).Decode(out)
+}
+
+// backoffOf returns the jittered backoff before the retry of the attempt
+func (c *Client) backoffOf(attempt int) time.Duration {
+	backoff := c.backoff << uint(attempt-1)
+	if backoff > maxBackoff || backoff <= 0 {
+		backoff = maxBackoff
+	}
+	// jitter in [backoff/2, backoff) spreads retries of concurrent clients
+	return backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+}
+
+// retryAfter returns the wait asked by Retry-After in seconds, 0 if absent
+func retryAfter(resp *http.Response) time.Duration {
+	seconds, err := strconv.Atoi(resp.Header.Get(headerRetryAfter))
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
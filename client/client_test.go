@@ -0,0 +1,499 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/n3k0fi5t/wallet/app/api"
+	"github.com/n3k0fi5t/wallet/app/broker"
+	mdApproval "github.com/n3k0fi5t/wallet/app/models/approval"
+	mdBank "github.com/n3k0fi5t/wallet/app/models/bank"
+	mdWallet "github.com/n3k0fi5t/wallet/app/models/wallet"
+	"github.com/n3k0fi5t/wallet/app/money"
+	mockAudit "github.com/n3k0fi5t/wallet/app/repository/audit/mocks"
+	"github.com/n3k0fi5t/wallet/app/repository/bank"
+	mockAdmin "github.com/n3k0fi5t/wallet/app/service/admin/mocks"
+	mockWebhook "github.com/n3k0fi5t/wallet/app/service/webhook/mocks"
+)
+
+var (
+	mockAccountID1 = "935f871a-660f-4f19-801e-916c04bb0324"
+	mockAccountID2 = "a89b7b78-b9c1-4129-8cff-380bf53f3a49"
+	mockAuth1      = "Tim"
+	mockAuth2      = "Alex"
+	mockIntentID   = "5d2c9a4e-3f61-4a8e-9b0c-7e1f2d3c4b5a"
+
+	// mockFunding is where deposits come from and withdrawals go, it may go negative
+	mockFunding = mdBank.FundingAccountID(mdBank.Rail_DIRECT, "USD")
+)
+
+// fakeWallet keeps balances in memory, it fails the next calls when failures is set
+type fakeWallet struct {
+	mu       sync.Mutex
+	balances map[string]int64
+	trades   map[string][]*mdBank.Transaction
+	keys     map[string]string
+	frozen   map[string]bool
+	broker   broker.Broker
+	failures int
+	calls    int
+	// withdrawals and transfers over threshold are held, zero for no approval policy
+	threshold int64
+}
+
+func newFakeWallet() *fakeWallet {
+	return &fakeWallet{
+		balances: map[string]int64{mockAccountID1: 1000, mockAccountID2: 0},
+		trades:   map[string][]*mdBank.Transaction{},
+		keys:     map[string]string{},
+		frozen:   map[string]bool{},
+		broker:   broker.NewBroker(1),
+	}
+}
+
+func (f *fakeWallet) fail() error {
+	f.calls++
+	if f.failures > 0 {
+		f.failures--
+		return fmt.Errorf("temporary failure")
+	}
+	return nil
+}
+
+func (f *fakeWallet) trade(ctx context.Context, from, to string, amount int64) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.fail(); err != nil {
+		return "", err
+	}
+
+	key := mdBank.IdempotencyKeyFrom(ctx)
+	if tradeID, ok := f.keys[key]; ok && key != "" {
+		return tradeID, nil
+	}
+
+	if amount <= 0 {
+		return "", bank.ErrInvalidDealing
+	} else if from == to {
+		return "", bank.ErrSelfTransfer
+	}
+	if _, ok := f.balances[to]; !ok && to != mockFunding {
+		return "", bank.ErrAccountNotExist
+	}
+	if from != mockFunding {
+		if f.frozen[from] {
+			return "", bank.ErrAccountFrozen
+		} else if f.balances[from] < amount {
+			return "", bank.ErrBalanceNotEnough
+		}
+	}
+
+	f.balances[from] -= amount
+	f.balances[to] += amount
+	tradeID := fmt.Sprintf("trade-%d", len(f.trades)+1)
+	f.trades[tradeID] = []*mdBank.Transaction{
+		{AccountID: from, Action: mdBank.Action_DECREASE, Amount: money.New(amount, "USD"), TradeID: tradeID},
+		{AccountID: to, Action: mdBank.Action_INCREASE, Amount: money.New(amount, "USD"), TradeID: tradeID},
+	}
+	if key != "" {
+		f.keys[key] = tradeID
+	}
+	return tradeID, nil
+}
+
+func (f *fakeWallet) Deposit(ctx context.Context, accountID string, amount money.Money) (string, error) {
+	return f.trade(ctx, mockFunding, accountID, amount.Amount)
+}
+
+// held returns the intent holding the amount when it is over the threshold
+func (f *fakeWallet) held(walletID string, amount money.Money) *mdApproval.Intent {
+	if f.threshold == 0 || amount.Amount <= f.threshold {
+		return nil
+	}
+	return &mdApproval.Intent{IntentID: mockIntentID, WalletID: walletID, Amount: amount, Status: mdApproval.Status_PENDING, ExpiresAtMs: 1650172800000}
+}
+
+func (f *fakeWallet) Withdraw(ctx context.Context, accountID string, amount money.Money) (string, *mdApproval.Intent, error) {
+	if i := f.held(accountID, amount); i != nil {
+		return "", i, nil
+	}
+	tradeID, err := f.trade(ctx, accountID, mockFunding, amount.Amount)
+	return tradeID, nil, err
+}
+
+func (f *fakeWallet) Transfer(ctx context.Context, from, to string, amount money.Money) (string, *mdApproval.Intent, error) {
+	if i := f.held(from, amount); i != nil {
+		return "", i, nil
+	}
+	tradeID, err := f.trade(ctx, from, to, amount.Amount)
+	return tradeID, nil, err
+}
+
+func (f *fakeWallet) GetAccount(ctx context.Context, accountID string) (*mdBank.Account, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.fail(); err != nil {
+		return nil, err
+	}
+	return &mdBank.Account{AccountID: accountID, Balance: money.New(f.balances[accountID], "USD"), CreditLimit: money.New(0, "USD")}, nil
+}
+
+func (f *fakeWallet) ListTransactions(ctx context.Context, accountID string, offset, limit int) ([]*mdBank.Transaction, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	txs := []*mdBank.Transaction{}
+	for _, trade := range f.trades {
+		for _, tx := range trade {
+			if tx.AccountID == accountID {
+				txs = append(txs, tx)
+			}
+		}
+	}
+	return txs, nil
+}
+
+func (f *fakeWallet) GetTrade(ctx context.Context, accountID, tradeID string) ([]*mdBank.Transaction, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, tx := range f.trades[tradeID] {
+		if tx.AccountID == accountID {
+			return f.trades[tradeID], nil
+		}
+	}
+	return nil, bank.ErrTradeNotExist
+}
+
+func (f *fakeWallet) Subscribe(ctx context.Context, accountID string) *broker.Subscription {
+	return f.broker.Subscribe(accountID)
+}
+
+func (f *fakeWallet) ListUpdates(ctx context.Context, accountID string, afterID int64, limit int) ([]*mdBank.Update, error) {
+	return []*mdBank.Update{}, nil
+}
+
+// the client does not manage wallets, users of the fake only have default wallets
+func (f *fakeWallet) ListWallets(ctx context.Context, userID string) ([]*mdBank.Account, error) {
+	account, err := f.GetAccount(ctx, mdBank.DefaultWalletID(userID))
+	if err != nil {
+		return nil, err
+	}
+	account.OwnerID, account.Name = userID, "Main"
+	return []*mdBank.Account{account}, nil
+}
+
+func (f *fakeWallet) CreateWallet(ctx context.Context, userID, name, currency string) (*mdBank.Account, error) {
+	return nil, errors.New("not supported")
+}
+
+func (f *fakeWallet) RenameWallet(ctx context.Context, userID, walletID, name string) (*mdBank.Account, error) {
+	return nil, errors.New("not supported")
+}
+
+func (f *fakeWallet) Move(ctx context.Context, userID, from, to string, amount money.Money) (string, error) {
+	if from != mdBank.DefaultWalletID(userID) || to != mdBank.DefaultWalletID(userID) {
+		return "", bank.ErrAccountNotExist
+	}
+	return f.trade(ctx, from, to, amount.Amount)
+}
+
+// users of the fake are only members of their default wallets
+func (f *fakeWallet) Authorize(ctx context.Context, userID, walletID string) (*mdWallet.Member, error) {
+	if walletID != mdBank.DefaultWalletID(userID) {
+		return nil, bank.ErrAccountNotExist
+	}
+	return &mdWallet.Member{WalletID: walletID, UserID: userID, Role: mdWallet.Role_OWNER}, nil
+}
+
+func (f *fakeWallet) WithdrawAs(ctx context.Context, userID, walletID string, amount money.Money) (string, *mdApproval.Intent, error) {
+	if _, err := f.Authorize(ctx, userID, walletID); err != nil {
+		return "", nil, err
+	}
+	return f.Withdraw(ctx, walletID, amount)
+}
+
+func (f *fakeWallet) TransferAs(ctx context.Context, userID, from, to string, amount money.Money) (string, *mdApproval.Intent, error) {
+	if _, err := f.Authorize(ctx, userID, from); err != nil {
+		return "", nil, err
+	}
+	return f.Transfer(ctx, from, to, amount)
+}
+
+// wallets of the fake have no approval policies
+func (f *fakeWallet) CheckPolicy(ctx context.Context, walletID string, amount money.Money) error {
+	return nil
+}
+
+func (f *fakeWallet) Hold(ctx context.Context, userID, walletID, toAccountID string, amount money.Money) (*mdApproval.Intent, error) {
+	return nil, errors.New("not supported")
+}
+
+func (f *fakeWallet) ListMembers(ctx context.Context, userID, walletID string) ([]*mdWallet.Member, error) {
+	return nil, errors.New("not supported")
+}
+
+func (f *fakeWallet) SetMember(ctx context.Context, userID, walletID, memberID string, role mdWallet.Role, dailyCap money.Money) (*mdWallet.Member, error) {
+	return nil, errors.New("not supported")
+}
+
+func (f *fakeWallet) RemoveMember(ctx context.Context, userID, walletID, memberID string) error {
+	return errors.New("not supported")
+}
+
+func (f *fakeWallet) ListMemberships(ctx context.Context, userID string) ([]*mdWallet.Member, error) {
+	return []*mdWallet.Member{}, nil
+}
+
+type testSuite struct {
+	suite.Suite
+
+	server    *httptest.Server
+	wallet    *fakeWallet
+	mockAudit *mockAudit.Audit
+}
+
+func (s *testSuite) SetupSuite() {
+	gin.SetMode(gin.TestMode)
+}
+
+func (s *testSuite) SetupTest() {
+	s.wallet = newFakeWallet()
+	s.mockAudit = &mockAudit.Audit{}
+	s.server = httptest.NewServer(api.BuildRouter(&api.Services{
+		Wallet:  s.wallet,
+		Admin:   &mockAdmin.Service{},
+		Webhook: &mockWebhook.Service{},
+		Audit:   s.mockAudit,
+	}))
+}
+
+func (s *testSuite) TearDownTest() {
+	s.server.Close()
+	s.mockAudit.AssertExpectations(s.T())
+}
+
+func TestSuite(t *testing.T) {
+	suite.Run(t, new(testSuite))
+}
+
+func usd(amount string) Money {
+	return Money{Amount: amount, Currency: "USD"}
+}
+
+func (s *testSuite) newClient(token string, opts ...Option) *Client {
+	return New(s.server.URL+"/", token, append([]Option{WithRetry(3, time.Millisecond)}, opts...)...)
+}
+
+func (s *testSuite) TestTrade() {
+	ctx := context.Background()
+	c := s.newClient(mockAuth1)
+
+	res, err := c.Deposit(ctx, usd("5.00"))
+	s.Require().NoError(err)
+	s.Require().NotEmpty(res.TradeID)
+
+	_, err = c.Withdraw(ctx, usd("3.00"))
+	s.Require().NoError(err)
+
+	transfer, err := c.Transfer(ctx, mockAccountID2, usd("2.00"))
+	s.Require().NoError(err)
+
+	account, err := c.GetAccount(ctx)
+	s.Require().NoError(err)
+	s.Require().Equal(&Account{AccountID: mockAccountID1, Balance: usd("10.00"), CreditLimit: usd("0.00"), Available: usd("10.00")}, account)
+
+	// the receiver sees the trade
+	trade, err := s.newClient(mockAuth2).GetTrade(ctx, transfer.TradeID)
+	s.Require().NoError(err)
+	s.Require().Equal([]TradeEntry{
+		{AccountID: mockAccountID1, Debit: true, Amount: usd("2.00")},
+		{AccountID: mockAccountID2, Debit: false, Amount: usd("2.00")},
+	}, trade.Entries)
+
+	txs, err := c.ListTransactions(ctx, 0, 10)
+	s.Require().NoError(err)
+	s.Require().Len(txs, 3)
+}
+
+func (s *testSuite) TestHeld() {
+	ctx := context.Background()
+	c := s.newClient(mockAuth1)
+	s.wallet.threshold = 100
+
+	res, err := c.Transfer(ctx, mockAccountID2, usd("2.00"))
+	s.Require().NoError(err)
+	s.Require().True(res.Held())
+	s.Require().Equal(&TradeResult{IntentID: mockIntentID, Status: "PENDING", ExpiresAtMs: 1650172800000}, res)
+
+	res, err = c.Withdraw(ctx, usd("1.00"))
+	s.Require().NoError(err)
+	s.Require().False(res.Held())
+	s.Require().NotEmpty(res.TradeID)
+}
+
+func (s *testSuite) TestTypedErrors() {
+	ctx := context.Background()
+	c := s.newClient(mockAuth1)
+
+	tests := []struct {
+		Desc      string
+		call      func() error
+		ExpErr    error
+		ExpStatus int
+	}{
+		{
+			Desc:      "balance not enough",
+			call:      func() error { _, err := c.Withdraw(ctx, usd("50.00")); return err },
+			ExpErr:    ErrBalanceNotEnough,
+			ExpStatus: http.StatusConflict,
+		},
+		{
+			Desc:      "self transfer",
+			call:      func() error { _, err := c.Transfer(ctx, mockAccountID1, usd("0.01")); return err },
+			ExpErr:    ErrSelfTransfer,
+			ExpStatus: http.StatusBadRequest,
+		},
+		{
+			Desc:      "account not exist",
+			call:      func() error { _, err := c.Transfer(ctx, "unknown", usd("0.01")); return err },
+			ExpErr:    ErrAccountNotExist,
+			ExpStatus: http.StatusNotFound,
+		},
+		{
+			Desc:      "invalid dealing",
+			call:      func() error { _, err := c.Deposit(ctx, usd("-0.01")); return err },
+			ExpErr:    ErrInvalidDealing,
+			ExpStatus: http.StatusBadRequest,
+		},
+		{
+			Desc:      "trade not exist",
+			call:      func() error { _, err := c.GetTrade(ctx, "unknown"); return err },
+			ExpErr:    ErrTradeNotExist,
+			ExpStatus: http.StatusNotFound,
+		},
+		{
+			Desc: "account frozen",
+			call: func() error {
+				s.wallet.frozen[mockAccountID1] = true
+				defer delete(s.wallet.frozen, mockAccountID1)
+				_, err := c.Withdraw(ctx, usd("0.01"))
+				return err
+			},
+			ExpErr:    ErrAccountFrozen,
+			ExpStatus: http.StatusConflict,
+		},
+	}
+
+	for _, t := range tests {
+		err := t.call()
+		s.Require().True(errors.Is(err, t.ExpErr), "%s: %v", t.Desc, err)
+
+		apiErr := &Error{}
+		s.Require().True(errors.As(err, &apiErr), t.Desc)
+		s.Require().Equal(t.ExpStatus, apiErr.StatusCode, t.Desc)
+	}
+}
+
+func (s *testSuite) TestErrorCodes() {
+	tests := []struct {
+		Code   string
+		ExpErr error
+	}{
+		{Code: "PERMISSION_DENIED", ExpErr: ErrPermissionDenied},
+		{Code: "DAILY_CAP_EXCEEDED", ExpErr: ErrDailyCapExceeded},
+		{Code: "APPROVAL_REQUIRED", ExpErr: ErrApprovalRequired},
+		{Code: "NOT_ENOUGH_APPROVERS", ExpErr: ErrNotEnoughApprovers},
+		{Code: "CURRENCY_MISMATCH", ExpErr: ErrCurrencyMismatch},
+		{Code: "QUOTE_EXPIRED", ExpErr: ErrQuoteExpired},
+	}
+
+	for _, t := range tests {
+		err := error(&Error{StatusCode: http.StatusConflict, Code: t.Code})
+		s.Require().True(errors.Is(err, t.ExpErr), t.Code)
+	}
+
+	// unknown codes of newer servers are not typed
+	s.Require().Nil(errors.Unwrap(&Error{StatusCode: http.StatusConflict, Code: "SOMETHING_NEW"}))
+}
+
+func (s *testSuite) TestUnauthorized() {
+	s.mockAudit.On("Record", mock.Anything, mock.Anything, "GET /api/v1/wallet/account", nil, mock.Anything).Return(nil).Once()
+
+	_, err := s.newClient("unknown").GetAccount(context.Background())
+	s.Require().True(errors.Is(err, ErrUnauthorized), err)
+}
+
+func (s *testSuite) TestRetry() {
+	ctx := context.Background()
+	c := s.newClient(mockAuth1)
+
+	// reads are retried
+	s.wallet.failures = 2
+	_, err := c.GetAccount(ctx)
+	s.Require().NoError(err)
+	s.Require().Equal(3, s.wallet.calls)
+
+	// attempts are limited
+	s.wallet.calls, s.wallet.failures = 0, 3
+	_, err = c.GetAccount(ctx)
+	apiErr := &Error{}
+	s.Require().True(errors.As(err, &apiErr))
+	s.Require().Equal(http.StatusInternalServerError, apiErr.StatusCode)
+	s.Require().Equal(3, s.wallet.calls)
+
+	// writes without a key are not retried
+	s.wallet.calls, s.wallet.failures = 0, 1
+	_, err = c.Deposit(ctx, usd("1.00"))
+	s.Require().Error(err)
+	s.Require().Equal(1, s.wallet.calls)
+
+	// writes with a key are retried and executed once
+	s.wallet.calls, s.wallet.failures = 0, 1
+	first, err := c.Deposit(ctx, usd("1.00"), WithIdempotencyKey("retry-1"))
+	s.Require().NoError(err)
+	s.Require().Equal(2, s.wallet.calls)
+	second, err := c.Deposit(ctx, usd("1.00"), WithIdempotencyKey("retry-1"))
+	s.Require().NoError(err)
+	s.Require().Equal(first.TradeID, second.TradeID)
+
+	account, err := c.GetAccount(ctx)
+	s.Require().NoError(err)
+	s.Require().Equal(usd("11.00"), account.Balance)
+}
+
+func (s *testSuite) TestTimeout() {
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer slow.Close()
+
+	// each attempt times out and is retried
+	c := New(slow.URL, mockAuth1, WithTimeout(10*time.Millisecond), WithRetry(2, time.Millisecond))
+	start := time.Now()
+	_, err := c.GetAccount(context.Background())
+	s.Require().Error(err)
+	s.Require().True(time.Since(start) < time.Second)
+
+	// the caller's context limits the whole call
+	c = New(slow.URL, mockAuth1, WithTimeout(0), WithRetry(5, time.Millisecond))
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = c.GetAccount(ctx)
+	s.Require().True(errors.Is(err, context.DeadlineExceeded), err)
+}
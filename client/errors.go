@@ -0,0 +1,195 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Errors of the wallet, match them with errors.Is
+var (
+	// ErrUnauthorized means the token is unknown
+	ErrUnauthorized = errors.New("wallet: unauthorized")
+
+	// ErrInvalidDealing means the amount or accounts of the trade are invalid
+	ErrInvalidDealing = errors.New("wallet: invalid dealing")
+
+	// ErrSelfTransfer means transferring to the user's own account
+	ErrSelfTransfer = errors.New("wallet: self transfer")
+
+	// ErrAccountNotExist means the account does not exist
+	ErrAccountNotExist = errors.New("wallet: account not exist")
+
+	// ErrTradeNotExist means the trade does not exist or the user does not take part in it
+	ErrTradeNotExist = errors.New("wallet: trade not exist")
+
+	// ErrBalanceNotEnough means the account does not have enough money
+	ErrBalanceNotEnough = errors.New("wallet: balance not enough")
+
+	// ErrAccountFrozen means the account is frozen and can not pay out
+	ErrAccountFrozen = errors.New("wallet: account frozen")
+
+	// ErrIdempotencyKeyReused means the idempotency key was used by a different request
+	ErrIdempotencyKeyReused = errors.New("wallet: idempotency key reused")
+
+	// ErrRateLimited means too many requests, it is retried after Retry-After
+	ErrRateLimited = errors.New("wallet: rate limited")
+
+	// ErrCurrencyMismatch means the accounts hold different currencies, convert through an FX quote instead
+	ErrCurrencyMismatch = errors.New("wallet: currency mismatch")
+
+	// ErrBalanceOverflow means the balance after the trade is out of range
+	ErrBalanceOverflow = errors.New("wallet: balance overflow")
+
+	// ErrInvalidCurrency means the currency is not supported
+	ErrInvalidCurrency = errors.New("wallet: invalid currency")
+
+	// ErrInvalidAmount means the amount is not positive
+	ErrInvalidAmount = errors.New("wallet: invalid amount")
+
+	// ErrTooManyWallets means the user opened as many wallets as allowed
+	ErrTooManyWallets = errors.New("wallet: too many wallets")
+
+	// ErrPermissionDenied means the role of the user on the wallet does not allow the request
+	ErrPermissionDenied = errors.New("wallet: permission denied")
+
+	// ErrDailyCapExceeded means the payment exceeds what the member may spend from the wallet today
+	ErrDailyCapExceeded = errors.New("wallet: daily cap exceeded")
+
+	// ErrInvalidMember means the role or daily cap of the member is invalid
+	ErrInvalidMember = errors.New("wallet: invalid member")
+
+	// ErrWalletOwner means the owner of the wallet can not be changed as a member
+	ErrWalletOwner = errors.New("wallet: wallet owner")
+
+	// ErrTooManyMembers means the wallet has as many members as allowed
+	ErrTooManyMembers = errors.New("wallet: too many members")
+
+	// ErrMemberNotExist means the user is not a member of the wallet
+	ErrMemberNotExist = errors.New("wallet: member not exist")
+
+	// ErrApprovalRequired means the payment needs approvals under the policy of the wallet
+	ErrApprovalRequired = errors.New("wallet: approval required")
+
+	// ErrApprovalNotRequired means the payment is under the threshold of the policy, pay it directly
+	ErrApprovalNotRequired = errors.New("wallet: approval not required")
+
+	// ErrInvalidPolicy means the threshold or required approvals of the policy are invalid
+	ErrInvalidPolicy = errors.New("wallet: invalid policy")
+
+	// ErrInvalidApprover means an approver of the policy is not a member able to approve
+	ErrInvalidApprover = errors.New("wallet: invalid approver")
+
+	// ErrPolicyNotExist means the wallet has no approval policy
+	ErrPolicyNotExist = errors.New("wallet: policy not exist")
+
+	// ErrNotEnoughApprovers means fewer approvers than required are eligible to approve the payment
+	ErrNotEnoughApprovers = errors.New("wallet: not enough approvers")
+
+	// ErrIntentNotExist means the payment intent does not exist or the user does not take part in it
+	ErrIntentNotExist = errors.New("wallet: intent not exist")
+
+	// ErrIntentNotPending means the payment intent was decided or expired
+	ErrIntentNotPending = errors.New("wallet: intent not pending")
+
+	// ErrSelfApproval means the requester of the payment intent can not approve it
+	ErrSelfApproval = errors.New("wallet: self approval")
+
+	// ErrAlreadyDecided means the approver decided on the payment intent before
+	ErrAlreadyDecided = errors.New("wallet: already decided")
+
+	// ErrCommentTooLong means the comment of the decision is too long
+	ErrCommentTooLong = errors.New("wallet: comment too long")
+
+	// ErrSameCurrency means the FX quote converts a currency into itself
+	ErrSameCurrency = errors.New("wallet: same currency")
+
+	// ErrDifferentOwners means the FX quote converts between wallets of different users
+	ErrDifferentOwners = errors.New("wallet: different owners")
+
+	// ErrAmountTooSmall means the converted amount rounds down to nothing
+	ErrAmountTooSmall = errors.New("wallet: amount too small")
+
+	// ErrRateNotFound means there is no rate for the currency pair
+	ErrRateNotFound = errors.New("wallet: rate not found")
+
+	// ErrQuoteNotExist means the FX quote does not exist or belongs to another user
+	ErrQuoteNotExist = errors.New("wallet: quote not exist")
+
+	// ErrQuoteExpired means the FX quote expired, request a new one
+	ErrQuoteExpired = errors.New("wallet: quote expired")
+
+	// ErrQuoteNotOpen means the FX quote was executed before
+	ErrQuoteNotOpen = errors.New("wallet: quote not open")
+
+	// ErrInsufficientLiquidity means the FX account can not pay out the converted amount
+	ErrInsufficientLiquidity = errors.New("wallet: insufficient liquidity")
+)
+
+var codeErrors = map[string]error{
+	"INVALID_DEALING":        ErrInvalidDealing,
+	"SELF_TRANSFER":          ErrSelfTransfer,
+	"ACCOUNT_NOT_EXIST":      ErrAccountNotExist,
+	"TRADE_NOT_EXIST":        ErrTradeNotExist,
+	"BALANCE_NOT_ENOUGH":     ErrBalanceNotEnough,
+	"ACCOUNT_FROZEN":         ErrAccountFrozen,
+	"IDEMPOTENCY_KEY_REUSED": ErrIdempotencyKeyReused,
+	"RATE_LIMITED":           ErrRateLimited,
+	"CURRENCY_MISMATCH":      ErrCurrencyMismatch,
+	"BALANCE_OVERFLOW":       ErrBalanceOverflow,
+	"INVALID_CURRENCY":       ErrInvalidCurrency,
+	"INVALID_AMOUNT":         ErrInvalidAmount,
+	"TOO_MANY_WALLETS":       ErrTooManyWallets,
+	"PERMISSION_DENIED":      ErrPermissionDenied,
+	"DAILY_CAP_EXCEEDED":     ErrDailyCapExceeded,
+	"INVALID_MEMBER":         ErrInvalidMember,
+	"WALLET_OWNER":           ErrWalletOwner,
+	"TOO_MANY_MEMBERS":       ErrTooManyMembers,
+	"MEMBER_NOT_EXIST":       ErrMemberNotExist,
+	"APPROVAL_REQUIRED":      ErrApprovalRequired,
+	"APPROVAL_NOT_REQUIRED":  ErrApprovalNotRequired,
+	"INVALID_POLICY":         ErrInvalidPolicy,
+	"INVALID_APPROVER":       ErrInvalidApprover,
+	"POLICY_NOT_EXIST":       ErrPolicyNotExist,
+	"NOT_ENOUGH_APPROVERS":   ErrNotEnoughApprovers,
+	"INTENT_NOT_EXIST":       ErrIntentNotExist,
+	"INTENT_NOT_PENDING":     ErrIntentNotPending,
+	"SELF_APPROVAL":          ErrSelfApproval,
+	"ALREADY_DECIDED":        ErrAlreadyDecided,
+	"COMMENT_TOO_LONG":       ErrCommentTooLong,
+	"SAME_CURRENCY":          ErrSameCurrency,
+	"DIFFERENT_OWNERS":       ErrDifferentOwners,
+	"AMOUNT_TOO_SMALL":       ErrAmountTooSmall,
+	"RATE_NOT_FOUND":         ErrRateNotFound,
+	"QUOTE_NOT_EXIST":        ErrQuoteNotExist,
+	"QUOTE_EXPIRED":          ErrQuoteExpired,
+	"QUOTE_NOT_OPEN":         ErrQuoteNotOpen,
+	"INSUFFICIENT_LIQUIDITY": ErrInsufficientLiquidity,
+}
+
+// Error is returned when the server responds a non-2xx status
+type Error struct {
+	StatusCode int
+	// Code is the machine readable errCode of the response, empty if absent
+	Code    string
+	Message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("wallet: %d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+}
+
+// Unwrap returns the typed error of the response, nil if unknown
+func (e *Error) Unwrap() error {
+	if err, ok := codeErrors[e.Code]; ok {
+		return err
+	} else if e.StatusCode == http.StatusUnauthorized {
+		return ErrUnauthorized
+	}
+	return nil
+}
+
+// Temporary reports whether the request may succeed when retried
+func (e *Error) Temporary() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= http.StatusInternalServerError
+}
@@ -0,0 +1,60 @@
+package client
+
+// TradeResult is the result of deposit, withdraw and transfer. Withdrawals and transfers over the approval threshold
+// of the wallet are held instead, IntentID is set in place of TradeID until the intent is approved or expires at
+// ExpiresAtMs
+type TradeResult struct {
+	TradeID     string `json:"tradeID"`
+	IntentID    string `json:"intentID,omitempty"`
+	Status      string `json:"status,omitempty"`
+	ExpiresAtMs int64  `json:"expiresAtMs,omitempty"`
+}
+
+// Held reports whether the withdrawal or transfer waits for approvals
+func (r *TradeResult) Held() bool {
+	return r.IntentID != ""
+}
+
+// Money is an amount with its currency, Amount is a decimal string in the major unit, e.g. 12.30
+type Money struct {
+	Amount   string `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+// String formats the money for display, e.g. 12.30 USD
+func (m Money) String() string {
+	return m.Amount + " " + m.Currency
+}
+
+// Account is the user's account information
+type Account struct {
+	AccountID string `json:"accountID"`
+	// Balance is in the currency of the account, it is negative while Overdrawn
+	Balance Money `json:"balance"`
+	// Available is Balance plus CreditLimit
+	CreditLimit Money `json:"creditLimit"`
+	Available   Money `json:"available"`
+	Overdrawn   bool  `json:"overdrawn"`
+}
+
+// Transaction is a transaction log of the user's account
+type Transaction struct {
+	TradeID     string `json:"tradeID"`
+	Debit       bool   `json:"debit"`
+	Amount      Money  `json:"amount"`
+	TimestampMs int64  `json:"timestampMs"`
+}
+
+// TradeEntry is one side of the double entries of a trade
+type TradeEntry struct {
+	AccountID string `json:"accountID"`
+	Debit     bool   `json:"debit"`
+	Amount    Money  `json:"amount"`
+}
+
+// Trade is a trade the user takes part in
+type Trade struct {
+	TradeID     string       `json:"tradeID"`
+	TimestampMs int64        `json:"timestampMs"`
+	Entries     []TradeEntry `json:"entries"`
+}
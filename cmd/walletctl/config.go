@@ -0,0 +1,77 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+const (
+	defaultProfile = "default"
+	defaultBaseURL = "http://localhost:8080"
+	configFileName = ".walletctl.json"
+)
+
+// Profile is the server and token used by commands
+type Profile struct {
+	BaseURL string `json:"baseURL"`
+	Token   string `json:"token"`
+}
+
+// Config is the profiles stored in the config file
+type Config struct {
+	Profiles map[string]*Profile `json:"profiles"`
+}
+
+// configPath returns WALLETCTL_CONFIG or ~/.walletctl.json
+func configPath() string {
+	if p := os.Getenv("WALLETCTL_CONFIG"); p != "" {
+		return p
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return configFileName
+	}
+	return filepath.Join(home, configFileName)
+}
+
+// loadConfig reads the config file, a missing file is an empty config
+func loadConfig(path string) (*Config, error) {
+	cfg := &Config{Profiles: map[string]*Profile{}}
+
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return cfg, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(b, cfg); err != nil {
+		return nil, fmt.Errorf("invalid config %s: %v", path, err)
+	}
+	if cfg.Profiles == nil {
+		cfg.Profiles = map[string]*Profile{}
+	}
+	return cfg, nil
+}
+
+// saveConfig writes the config file, it keeps tokens so only the owner can read it
+func saveConfig(path string, cfg *Config) error {
+	b, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, b, 0600)
+}
+
+// profile returns the named profile, the default profile falls back to the local server
+func (cfg *Config) profile(name string) (*Profile, error) {
+	if p, ok := cfg.Profiles[name]; ok {
+		return &Profile{BaseURL: p.BaseURL, Token: p.Token}, nil
+	} else if name == defaultProfile {
+		return &Profile{BaseURL: defaultBaseURL}, nil
+	}
+	return nil, fmt.Errorf("profile %q not found", name)
+}
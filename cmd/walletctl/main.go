@@ -0,0 +1,391 @@
+// walletctl is the command-line client of the wallet REST API
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/n3k0fi5t/wallet/app/money"
+	"github.com/n3k0fi5t/wallet/app/util"
+	"github.com/n3k0fi5t/wallet/client"
+)
+
+const usage = `walletctl calls wallet APIs as the user of the profile token.
+
+Usage:
+  walletctl [flags] <command> [args]
+
+Commands:
+  deposit -amount DECIMAL [-currency CODE] [-idempotency-key KEY]
+  withdraw -amount DECIMAL [-currency CODE] [-idempotency-key KEY]
+  transfer -to ACCOUNT -amount DECIMAL [-currency CODE] [-idempotency-key KEY]
+  balance
+  history [-offset N] [-limit N]
+  trade get TRADE_ID
+  profile set NAME [-base-url URL] [-token TOKEN]
+  profile list
+
+Amounts are decimals in the major unit of the currency of the account, e.g. 12.30.
+Writes send an idempotency key, a generated one is printed so the command can be retried safely.
+
+Flags:
+`
+
+// errUsage means the command line is malformed, the usage is already printed
+var errUsage = errors.New("usage")
+
+// env is shared by commands
+type env struct {
+	ctx        context.Context
+	client     *client.Client
+	printer    *printer
+	stderr     io.Writer
+	config     *Config
+	configPath string
+}
+
+type command func(e *env, args []string) error
+
+var commands = map[string]command{
+	"deposit":  depositCmd,
+	"withdraw": withdrawCmd,
+	"transfer": transferCmd,
+	"balance":  balanceCmd,
+	"history":  historyCmd,
+	"trade":    tradeCmd,
+	"profile":  profileCmd,
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func envOr(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
+
+// run executes the command line and returns the exit code
+func run(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("walletctl", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	profileName := fs.String("profile", envOr("WALLETCTL_PROFILE", defaultProfile), "profile in the config file")
+	baseURL := fs.String("base-url", "", "override base URL of the profile")
+	token := fs.String("token", "", "override token of the profile")
+	output := fs.String("output", outputTable, "output format, table or json")
+	timeout := fs.Duration("timeout", 10*time.Second, "timeout of each request")
+	fs.Usage = func() {
+		fmt.Fprint(stderr, usage)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	cmd, ok := commands[fs.Arg(0)]
+	if !ok {
+		fs.Usage()
+		return 2
+	}
+
+	p, err := newPrinter(stdout, *output)
+	if err != nil {
+		fmt.Fprintln(stderr, "error:", err)
+		return 2
+	}
+
+	path := configPath()
+	cfg, err := loadConfig(path)
+	if err != nil {
+		fmt.Fprintln(stderr, "error:", err)
+		return 1
+	}
+
+	e := &env{
+		printer:    p,
+		stderr:     stderr,
+		config:     cfg,
+		configPath: path,
+	}
+
+	// profile commands manage the config only
+	if fs.Arg(0) != "profile" {
+		prof, err := cfg.profile(*profileName)
+		if err != nil {
+			fmt.Fprintln(stderr, "error:", err)
+			return 1
+		}
+		if *baseURL != "" {
+			prof.BaseURL = *baseURL
+		}
+		if *token != "" {
+			prof.Token = *token
+		}
+		e.client = client.New(prof.BaseURL, prof.Token)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	e.ctx = ctx
+
+	if err := cmd(e, fs.Args()[1:]); err == errUsage {
+		return 2
+	} else if err != nil {
+		fmt.Fprintln(stderr, "error:", err)
+		return 1
+	}
+	return 0
+}
+
+// newFlagSet returns the flag set of a subcommand
+func (e *env) newFlagSet(name, args string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(e.stderr, "Usage: walletctl %s %s\n", name, args)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+func (e *env) parse(fs *flag.FlagSet, args []string, nArgs int) error {
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	} else if fs.NArg() != nArgs {
+		fs.Usage()
+		return errUsage
+	}
+	return nil
+}
+
+type tradeOutput struct {
+	TradeID        string `json:"tradeID,omitempty"`
+	IntentID       string `json:"intentID,omitempty"`
+	IdempotencyKey string `json:"idempotencyKey"`
+}
+
+// trade runs a write with the idempotency key, it generates one if absent
+func (e *env) trade(key string, write func(opt client.RequestOption) (*client.TradeResult, error)) error {
+	if key == "" {
+		var err error
+		if key, err = util.GetUUIDv4(); err != nil {
+			return err
+		}
+	}
+
+	res, err := write(client.WithIdempotencyKey(key))
+	if err != nil {
+		return fmt.Errorf("%v (retry with -idempotency-key %s)", err, key)
+	}
+
+	// payments over the approval threshold are held until approved
+	if res.Held() {
+		out := tradeOutput{IntentID: res.IntentID, IdempotencyKey: key}
+		return e.printer.print(out, "INTENT ID\tIDEMPOTENCY KEY", func(w io.Writer) {
+			fmt.Fprintf(w, "%s\t%s\n", out.IntentID, out.IdempotencyKey)
+		})
+	}
+
+	out := tradeOutput{TradeID: res.TradeID, IdempotencyKey: key}
+	return e.printer.print(out, "TRADE ID\tIDEMPOTENCY KEY", func(w io.Writer) {
+		fmt.Fprintf(w, "%s\t%s\n", out.TradeID, out.IdempotencyKey)
+	})
+}
+
+// parseAmount parses -amount in the major unit of -currency, it has at most the digits of the minor unit
+func parseAmount(fs *flag.FlagSet, amount, currency string) (client.Money, error) {
+	m, err := money.Parse(amount, currency)
+	if err != nil || !m.IsPositive() {
+		fmt.Fprintln(fs.Output(), "-amount must be a positive decimal of -currency, e.g. 12.30")
+		fs.Usage()
+		return client.Money{}, errUsage
+	}
+	return client.Money{Amount: m.Decimal(), Currency: m.Currency}, nil
+}
+
+func depositCmd(e *env, args []string) error {
+	fs := e.newFlagSet("deposit", "-amount DECIMAL [-currency CODE] [-idempotency-key KEY]")
+	amount := fs.String("amount", "", "amount to deposit")
+	currency := fs.String("currency", money.DefaultCurrency, "currency of the amount, the currency of the account")
+	key := fs.String("idempotency-key", "", "reuse the key to retry the same deposit")
+	if err := e.parse(fs, args, 0); err != nil {
+		return err
+	}
+	m, err := parseAmount(fs, *amount, *currency)
+	if err != nil {
+		return err
+	}
+
+	return e.trade(*key, func(opt client.RequestOption) (*client.TradeResult, error) {
+		return e.client.Deposit(e.ctx, m, opt)
+	})
+}
+
+func withdrawCmd(e *env, args []string) error {
+	fs := e.newFlagSet("withdraw", "-amount DECIMAL [-currency CODE] [-idempotency-key KEY]")
+	amount := fs.String("amount", "", "amount to withdraw")
+	currency := fs.String("currency", money.DefaultCurrency, "currency of the amount, the currency of the account")
+	key := fs.String("idempotency-key", "", "reuse the key to retry the same withdrawal")
+	if err := e.parse(fs, args, 0); err != nil {
+		return err
+	}
+	m, err := parseAmount(fs, *amount, *currency)
+	if err != nil {
+		return err
+	}
+
+	return e.trade(*key, func(opt client.RequestOption) (*client.TradeResult, error) {
+		return e.client.Withdraw(e.ctx, m, opt)
+	})
+}
+
+func transferCmd(e *env, args []string) error {
+	fs := e.newFlagSet("transfer", "-to ACCOUNT -amount DECIMAL [-currency CODE] [-idempotency-key KEY]")
+	to := fs.String("to", "", "account to transfer to")
+	amount := fs.String("amount", "", "amount to transfer")
+	currency := fs.String("currency", money.DefaultCurrency, "currency of the amount, the currency of both accounts")
+	key := fs.String("idempotency-key", "", "reuse the key to retry the same transfer")
+	if err := e.parse(fs, args, 0); err != nil {
+		return err
+	}
+	m, err := parseAmount(fs, *amount, *currency)
+	if err != nil {
+		return err
+	} else if *to == "" {
+		fmt.Fprintln(e.stderr, "-to is required")
+		fs.Usage()
+		return errUsage
+	}
+
+	return e.trade(*key, func(opt client.RequestOption) (*client.TradeResult, error) {
+		return e.client.Transfer(e.ctx, *to, m, opt)
+	})
+}
+
+func balanceCmd(e *env, args []string) error {
+	fs := e.newFlagSet("balance", "")
+	if err := e.parse(fs, args, 0); err != nil {
+		return err
+	}
+
+	account, err := e.client.GetAccount(e.ctx)
+	if err != nil {
+		return err
+	}
+	return e.printer.print(account, "ACCOUNT ID\tBALANCE", func(w io.Writer) {
+		fmt.Fprintf(w, "%s\t%s\n", account.AccountID, account.Balance)
+	})
+}
+
+func historyCmd(e *env, args []string) error {
+	fs := e.newFlagSet("history", "[-offset N] [-limit N]")
+	offset := fs.Int("offset", 0, "number of transactions to skip")
+	limit := fs.Int("limit", 20, "number of transactions to list, at most 500")
+	if err := e.parse(fs, args, 0); err != nil {
+		return err
+	}
+
+	txs, err := e.client.ListTransactions(e.ctx, *offset, *limit)
+	if err != nil {
+		return err
+	}
+	return e.printer.print(txs, "TIME\tTRADE ID\tDIRECTION\tAMOUNT", func(w io.Writer) {
+		for _, tx := range txs {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", formatMs(tx.TimestampMs), tx.TradeID, direction(tx.Debit), tx.Amount)
+		}
+	})
+}
+
+func tradeCmd(e *env, args []string) error {
+	fs := e.newFlagSet("trade get", "TRADE_ID")
+	if len(args) == 0 || args[0] != "get" {
+		fs.Usage()
+		return errUsage
+	}
+	if err := e.parse(fs, args[1:], 1); err != nil {
+		return err
+	}
+
+	trade, err := e.client.GetTrade(e.ctx, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	return e.printer.print(trade, "TIME\tTRADE ID\tACCOUNT ID\tDIRECTION\tAMOUNT", func(w io.Writer) {
+		for _, entry := range trade.Entries {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", formatMs(trade.TimestampMs), trade.TradeID, entry.AccountID, direction(entry.Debit), entry.Amount)
+		}
+	})
+}
+
+type profileOutput struct {
+	Name    string `json:"name"`
+	BaseURL string `json:"baseURL"`
+	Token   string `json:"token"`
+}
+
+func profileCmd(e *env, args []string) error {
+	if len(args) > 0 && args[0] == "list" {
+		fs := e.newFlagSet("profile list", "")
+		if err := e.parse(fs, args[1:], 0); err != nil {
+			return err
+		}
+
+		names := make([]string, 0, len(e.config.Profiles))
+		for name := range e.config.Profiles {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		out := make([]profileOutput, 0, len(names))
+		for _, name := range names {
+			p := e.config.Profiles[name]
+			out = append(out, profileOutput{Name: name, BaseURL: p.BaseURL, Token: maskToken(p.Token)})
+		}
+		return e.printer.print(out, "NAME\tBASE URL\tTOKEN", func(w io.Writer) {
+			for _, p := range out {
+				fmt.Fprintf(w, "%s\t%s\t%s\n", p.Name, p.BaseURL, p.Token)
+			}
+		})
+	}
+
+	fs := e.newFlagSet("profile set", "NAME [-base-url URL] [-token TOKEN]")
+	if len(args) < 2 || args[0] != "set" || strings.HasPrefix(args[1], "-") {
+		fs.Usage()
+		return errUsage
+	}
+	name := args[1]
+	baseURL := fs.String("base-url", "", "base URL of the server, e.g. "+defaultBaseURL)
+	token := fs.String("token", "", "user token")
+	if err := e.parse(fs, args[2:], 0); err != nil {
+		return err
+	}
+
+	p, ok := e.config.Profiles[name]
+	if !ok {
+		p = &Profile{BaseURL: defaultBaseURL}
+		e.config.Profiles[name] = p
+	}
+	if *baseURL != "" {
+		p.BaseURL = *baseURL
+	}
+	if *token != "" {
+		p.Token = *token
+	}
+	if err := saveConfig(e.configPath, e.config); err != nil {
+		return err
+	}
+
+	out := profileOutput{Name: name, BaseURL: p.BaseURL, Token: maskToken(p.Token)}
+	return e.printer.print(out, "NAME\tBASE URL\tTOKEN", func(w io.Writer) {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", out.Name, out.BaseURL, out.Token)
+	})
+}
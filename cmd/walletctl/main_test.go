@@ -0,0 +1,181 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/n3k0fi5t/wallet/app/api/wallet"
+	"github.com/n3k0fi5t/wallet/app/middleware"
+	mdApproval "github.com/n3k0fi5t/wallet/app/models/approval"
+	mdBank "github.com/n3k0fi5t/wallet/app/models/bank"
+	mdWallet "github.com/n3k0fi5t/wallet/app/models/wallet"
+	"github.com/n3k0fi5t/wallet/app/money"
+	"github.com/n3k0fi5t/wallet/app/repository/bank"
+	mockSrv "github.com/n3k0fi5t/wallet/app/service/wallet/mocks"
+)
+
+var (
+	mockAccountID1 = "935f871a-660f-4f19-801e-916c04bb0324"
+	mockAccountID2 = "a89b7b78-b9c1-4129-8cff-380bf53f3a49"
+	mockTradeID    = "55b36756-6089-4756-bbd2-b0f66e50ee07"
+	mockIntentID   = "5d2c9a4e-3f61-4a8e-9b0c-7e1f2d3c4b5a"
+	mockOwner      = &mdWallet.Member{WalletID: mockAccountID1, UserID: mockAccountID1, Role: mdWallet.Role_OWNER}
+)
+
+type testSuite struct {
+	suite.Suite
+
+	server  *httptest.Server
+	mockSrv *mockSrv.Service
+	dir     string
+}
+
+func (s *testSuite) SetupSuite() {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	s.mockSrv = &mockSrv.Service{}
+	rg := router.Group("/api/v1")
+	rg.Use(middleware.SetHandleContext())
+	wallet.NewHandler(s.mockSrv).Handle(rg)
+	s.server = httptest.NewServer(router)
+
+	dir, err := ioutil.TempDir("", "walletctl")
+	s.Require().NoError(err)
+	s.dir = dir
+	os.Setenv("WALLETCTL_CONFIG", filepath.Join(dir, "config.json"))
+}
+
+func (s *testSuite) TearDownSuite() {
+	s.server.Close()
+	os.RemoveAll(s.dir)
+	os.Unsetenv("WALLETCTL_CONFIG")
+	s.mockSrv.AssertExpectations(s.T())
+}
+
+func TestSuite(t *testing.T) {
+	suite.Run(t, new(testSuite))
+}
+
+// exec runs walletctl against the test server as the user Tim
+func (s *testSuite) exec(args ...string) (int, string, string) {
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	args = append([]string{"-base-url", s.server.URL, "-token", "Tim"}, args...)
+	code := run(args, stdout, stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func keyedCtx(key string) interface{} {
+	return mock.MatchedBy(func(ctx context.Context) bool {
+		return mdBank.IdempotencyKeyFrom(ctx) == key
+	})
+}
+
+func (s *testSuite) TestTrade() {
+	s.mockSrv.On("Authorize", mock.Anything, mockAccountID1, mockAccountID1).Return(mockOwner, nil).Once()
+	s.mockSrv.On("Deposit", keyedCtx("retry-1"), mockAccountID1, money.New(10000, "USD")).Return(mockTradeID, nil).Once()
+	code, stdout, _ := s.exec("deposit", "-amount", "100", "-idempotency-key", "retry-1")
+	s.Require().Equal(0, code)
+	s.Require().Contains(stdout, mockTradeID)
+	s.Require().Contains(stdout, "retry-1")
+
+	// a key is generated when absent
+	generated := mock.MatchedBy(func(ctx context.Context) bool {
+		return len(mdBank.IdempotencyKeyFrom(ctx)) == 36
+	})
+	s.mockSrv.On("TransferAs", generated, mockAccountID1, mockAccountID1, mockAccountID2, money.New(200, "EUR")).Return(mockTradeID, nil, nil).Once()
+	code, stdout, _ = s.exec("-output", "json", "transfer", "-to", mockAccountID2, "-amount", "2.00", "-currency", "EUR")
+	s.Require().Equal(0, code)
+	out := tradeOutput{}
+	s.Require().NoError(json.Unmarshal([]byte(stdout), &out))
+	s.Require().Equal(mockTradeID, out.TradeID)
+	s.Require().Len(out.IdempotencyKey, 36)
+
+	// held writes print the intent instead
+	s.mockSrv.On("WithdrawAs", keyedCtx("retry-3"), mockAccountID1, mockAccountID1, money.New(5000, "USD")).Return("", &mdApproval.Intent{IntentID: mockIntentID, Status: mdApproval.Status_PENDING}, nil).Once()
+	code, stdout, _ = s.exec("withdraw", "-amount", "50", "-idempotency-key", "retry-3")
+	s.Require().Equal(0, code)
+	s.Require().Contains(stdout, "INTENT ID")
+	s.Require().Contains(stdout, mockIntentID)
+
+	// failed writes print the key to retry with
+	s.mockSrv.On("WithdrawAs", keyedCtx("retry-2"), mockAccountID1, mockAccountID1, money.New(300, "USD")).Return("", nil, bank.ErrBalanceNotEnough).Once()
+	code, _, stderr := s.exec("withdraw", "-amount", "3.00", "-idempotency-key", "retry-2")
+	s.Require().Equal(1, code)
+	s.Require().Contains(stderr, "409")
+	s.Require().Contains(stderr, "-idempotency-key retry-2")
+
+	// bad arguments never call the server
+	code, _, _ = s.exec("withdraw", "-amount", "-1")
+	s.Require().Equal(2, code)
+	code, _, _ = s.exec("withdraw", "-amount", "1.001")
+	s.Require().Equal(2, code)
+	code, _, _ = s.exec("withdraw", "-amount", "1", "-currency", "XYZ")
+	s.Require().Equal(2, code)
+	code, _, _ = s.exec("transfer", "-amount", "1")
+	s.Require().Equal(2, code)
+}
+
+func (s *testSuite) TestQuery() {
+	s.mockSrv.On("Authorize", mock.Anything, mockAccountID1, mockAccountID1).Return(mockOwner, nil).Once()
+	s.mockSrv.On("GetAccount", mock.Anything, mockAccountID1).Return(&mdBank.Account{AccountID: mockAccountID1, Balance: money.New(3345678, "USD")}, nil).Once()
+	code, stdout, _ := s.exec("balance")
+	s.Require().Equal(0, code)
+	s.Require().Contains(stdout, "33456.78 USD")
+
+	s.mockSrv.On("Authorize", mock.Anything, mockAccountID1, mockAccountID1).Return(mockOwner, nil).Once()
+	s.mockSrv.On("ListTransactions", mock.Anything, mockAccountID1, 5, 10).Return([]*mdBank.Transaction{
+		{AccountID: mockAccountID1, Action: mdBank.Action_DECREASE, Amount: money.New(100, "USD"), TimestampMs: 1650000000000, TradeID: mockTradeID},
+	}, nil).Once()
+	code, stdout, _ = s.exec("history", "-offset", "5", "-limit", "10")
+	s.Require().Equal(0, code)
+	s.Require().Contains(stdout, "2022-04-15T05:20:00Z")
+	s.Require().Contains(stdout, "debit")
+
+	s.mockSrv.On("Authorize", mock.Anything, mockAccountID1, mockAccountID1).Return(mockOwner, nil).Once()
+	s.mockSrv.On("GetTrade", mock.Anything, mockAccountID1, mockTradeID).Return([]*mdBank.Transaction{
+		{AccountID: mockAccountID1, Action: mdBank.Action_DECREASE, Amount: money.New(100, "USD"), TimestampMs: 1650000000000, TradeID: mockTradeID},
+		{AccountID: mockAccountID2, Action: mdBank.Action_INCREASE, Amount: money.New(100, "USD"), TimestampMs: 1650000000000, TradeID: mockTradeID},
+	}, nil).Once()
+	code, stdout, _ = s.exec("-output", "json", "trade", "get", mockTradeID)
+	s.Require().Equal(0, code)
+	s.Require().Contains(stdout, `"accountID": "`+mockAccountID2+`"`)
+
+	s.mockSrv.On("Authorize", mock.Anything, mockAccountID1, mockAccountID1).Return(mockOwner, nil).Once()
+	s.mockSrv.On("GetTrade", mock.Anything, mockAccountID1, "unknown").Return(nil, bank.ErrTradeNotExist).Once()
+	code, _, stderr := s.exec("trade", "get", "unknown")
+	s.Require().Equal(1, code)
+	s.Require().Contains(stderr, bank.ErrTradeNotExist.Error())
+
+	code, _, _ = s.exec("trade")
+	s.Require().Equal(2, code)
+}
+
+func (s *testSuite) TestProfile() {
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	s.Require().Equal(0, run([]string{"profile", "set", "staging", "-base-url", s.server.URL, "-token", "Tim"}, stdout, stderr))
+
+	stdout.Reset()
+	s.Require().Equal(0, run([]string{"profile", "list"}, stdout, stderr))
+	s.Require().Contains(stdout.String(), "staging")
+	s.Require().NotContains(stdout.String(), "Tim")
+
+	// commands use the base URL and token of the profile
+	s.mockSrv.On("Authorize", mock.Anything, mockAccountID1, mockAccountID1).Return(mockOwner, nil).Once()
+	s.mockSrv.On("GetAccount", mock.Anything, mockAccountID1).Return(&mdBank.Account{AccountID: mockAccountID1, Balance: money.New(42, "USD"), CreditLimit: money.New(0, "USD")}, nil).Once()
+	stdout.Reset()
+	s.Require().Equal(0, run([]string{"-profile", "staging", "-output", "json", "balance"}, stdout, stderr))
+	s.Require().Contains(stdout.String(), `"amount": "0.42"`)
+
+	s.Require().Equal(1, run([]string{"-profile", "unknown", "balance"}, stdout, stderr))
+	s.Require().Equal(2, run([]string{"unknown"}, stdout, stderr))
+}
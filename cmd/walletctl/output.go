@@ -0,0 +1,60 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+)
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+// printer writes results as JSON or as an aligned table
+type printer struct {
+	w      io.Writer
+	format string
+}
+
+func newPrinter(w io.Writer, format string) (*printer, error) {
+	if format != outputTable && format != outputJSON {
+		return nil, fmt.Errorf("unknown output %q, use %s or %s", format, outputTable, outputJSON)
+	}
+	return &printer{w: w, format: format}, nil
+}
+
+// print writes v as JSON, or the rows of the table with a header
+func (p *printer) print(v interface{}, header string, rows func(w io.Writer)) error {
+	if p.format == outputJSON {
+		enc := json.NewEncoder(p.w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	}
+
+	tw := tabwriter.NewWriter(p.w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, header)
+	rows(tw)
+	return tw.Flush()
+}
+
+func direction(debit bool) string {
+	if debit {
+		return "debit"
+	}
+	return "credit"
+}
+
+func formatMs(ms int64) string {
+	return time.Unix(0, ms*int64(time.Millisecond)).UTC().Format(time.RFC3339)
+}
+
+// maskToken hides the token in outputs
+func maskToken(token string) string {
+	if token == "" {
+		return ""
+	}
+	return token[:1] + "***"
+}
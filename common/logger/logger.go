@@ -0,0 +1,36 @@
+package logger
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+)
+
+type loggerKey struct{}
+
+// WithLogger returns a context carrying the logger of the request, code called with it logs the request's fields
+func WithLogger(ctx context.Context, entry *logrus.Entry) context.Context {
+	return context.WithValue(ctx, loggerKey{}, entry)
+}
+
+// Lookup returns the logger carried by context
+func Lookup(ctx context.Context) (*logrus.Entry, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	entry, ok := ctx.Value(loggerKey{}).(*logrus.Entry)
+	return entry, ok
+}
+
+// FromContext returns the logger carried by context, or the standard logger if absent
+func FromContext(ctx context.Context) *logrus.Entry {
+	if entry, ok := Lookup(ctx); ok {
+		return entry
+	}
+	return logrus.NewEntry(logrus.StandardLogger())
+}
+
+// WithFields returns a context whose logger has the fields as well
+func WithFields(ctx context.Context, fields logrus.Fields) context.Context {
+	return WithLogger(ctx, FromContext(ctx).WithFields(fields))
+}
@@ -0,0 +1,98 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/suite"
+)
+
+type testSuite struct {
+	suite.Suite
+
+	buf *bytes.Buffer
+	log *logrus.Logger
+}
+
+func (s *testSuite) SetupTest() {
+	s.buf = &bytes.Buffer{}
+	s.log = logrus.New()
+	s.log.SetOutput(s.buf)
+	s.log.SetFormatter(&RedactFormatter{Formatter: &logrus.JSONFormatter{}})
+}
+
+func TestSuite(t *testing.T) {
+	suite.Run(t, new(testSuite))
+}
+
+func (s *testSuite) entry() map[string]interface{} {
+	m := map[string]interface{}{}
+	s.Require().NoError(json.Unmarshal(s.buf.Bytes(), &m))
+	return m
+}
+
+func (s *testSuite) TestRedact() {
+	tests := []struct {
+		Desc string
+		In   string
+		Exp  string
+	}{
+		{
+			Desc: "tcp dsn",
+			In:   "open root:p@ss:word@tcp(127.0.0.1:3306)/wallet failed",
+			Exp:  "open root:[REDACTED]@tcp(127.0.0.1:3306)/wallet failed",
+		},
+		{
+			Desc: "unix dsn",
+			In:   "wallet:secret@unix(/tmp/mysql.sock)/wallet",
+			Exp:  "wallet:[REDACTED]@unix(/tmp/mysql.sock)/wallet",
+		},
+		{
+			Desc: "no secret",
+			In:   "connect to 127.0.0.1:3306/wallet",
+			Exp:  "connect to 127.0.0.1:3306/wallet",
+		},
+	}
+
+	for _, t := range tests {
+		s.Require().Equal(t.Exp, Redact(t.In), t.Desc)
+	}
+}
+
+func (s *testSuite) TestRedactFormatter() {
+	s.log.WithFields(logrus.Fields{
+		"Authorization": "Tim",
+		"token":         "Tim",
+		"fullName":      "Tim Chen",
+		"accountID":     "935f871a-660f-4f19-801e-916c04bb0324",
+		"err":           errors.New("dial root:pw@tcp(db:3306)/wallet"),
+	}).Info("open root:pw@tcp(db:3306)/wallet")
+
+	m := s.entry()
+	s.Require().Equal("[REDACTED]", m["Authorization"])
+	s.Require().Equal("[REDACTED]", m["token"])
+	s.Require().Equal("[REDACTED]", m["fullName"])
+	s.Require().Equal("935f871a-660f-4f19-801e-916c04bb0324", m["accountID"])
+	s.Require().Equal("dial root:[REDACTED]@tcp(db:3306)/wallet", m["err"])
+	s.Require().Equal("open root:[REDACTED]@tcp(db:3306)/wallet", m["msg"])
+	s.Require().Equal("info", m["level"])
+	s.Require().NotContains(s.buf.String(), "pw")
+}
+
+func (s *testSuite) TestFromContext() {
+	s.Require().Equal(logrus.StandardLogger(), FromContext(context.Background()).Logger)
+
+	ctx := WithLogger(context.Background(), logrus.NewEntry(s.log))
+	ctx = WithFields(ctx, logrus.Fields{"requestID": "req-1"})
+	ctx = WithFields(ctx, logrus.Fields{"accountID": "935f871a-660f-4f19-801e-916c04bb0324"})
+	FromContext(ctx).Info("trade")
+
+	m := s.entry()
+	s.Require().Equal("req-1", m["requestID"])
+	s.Require().Equal("935f871a-660f-4f19-801e-916c04bb0324", m["accountID"])
+	s.Require().Equal("trade", m["msg"])
+}
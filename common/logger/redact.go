@@ -0,0 +1,64 @@
+package logger
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	redacted = "[REDACTED]"
+)
+
+var (
+	// sensitiveKeys are fields never logged as is, matched case-insensitively
+	sensitiveKeys = map[string]bool{
+		"authorization": true,
+		"token":         true,
+		"password":      true,
+		"secret":        true,
+		"dsn":           true,
+		"fullname":      true,
+		"email":         true,
+		"phone":         true,
+	}
+
+	// dsnPattern matches the password of DSNs like user:password@tcp(host:port)/db, the password may contain @ and :
+	dsnPattern = regexp.MustCompile(`([^\s:/@]+):\S*@(tcp|unix)\(`)
+)
+
+// Redact returns the string without secrets
+func Redact(s string) string {
+	return dsnPattern.ReplaceAllString(s, "$1:"+redacted+"@$2(")
+}
+
+// RedactFormatter redacts sensitive fields and DSN passwords before the inner formatter writes the entry
+type RedactFormatter struct {
+	Formatter logrus.Formatter
+}
+
+func (f *RedactFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	data := make(logrus.Fields, len(entry.Data))
+	for k, v := range entry.Data {
+		switch {
+		case sensitiveKeys[strings.ToLower(k)]:
+			data[k] = redacted
+		default:
+			if s, ok := v.(string); ok {
+				v = Redact(s)
+			} else if err, ok := v.(error); ok {
+				v = Redact(err.Error())
+			}
+			data[k] = v
+		}
+	}
+
+	e := entry.Dup()
+	e.Data = data
+	e.Level = entry.Level
+	e.Message = Redact(entry.Message)
+	e.Caller = entry.Caller
+	e.Buffer = entry.Buffer
+	return f.Formatter.Format(e)
+}
@@ -0,0 +1,34 @@
+package sql
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const (
+	resultCommit      = "commit"
+	resultRollback    = "rollback"
+	resultCommitError = "commit_error"
+	resultBeginError  = "begin_error"
+)
+
+var (
+	transactionsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "sql_transactions_total",
+		Help: "Transactions started by Transactx, by result",
+	}, []string{"result"})
+
+	transactionDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "sql_transaction_duration_seconds",
+		Help:    "Duration of transactions started by Transactx, from begin to commit or rollback",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"result"})
+)
+
+// observeTransaction records the outermost transaction only, joined ones are part of it
+func observeTransaction(result string, start time.Time) {
+	transactionsTotal.WithLabelValues(result).Inc()
+	transactionDuration.WithLabelValues(result).Observe(time.Since(start).Seconds())
+}
@@ -3,17 +3,110 @@ package sql
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/jmoiron/sqlx"
-	"github.com/sirupsen/logrus"
+	"github.com/n3k0fi5t/wallet/common/logger"
 )
 
-// Transactx wraps sqlx trasaction in one function and provide error handling
-func Transactx(context context.Context, db *sqlx.DB, txFunc func(*sqlx.Tx) error) error {
-	var err error
+type txKey struct{}
 
-	tx, err := db.BeginTxx(context, nil)
+type afterCommitKey struct{}
+
+// afterCommit are functions run once the outermost transaction commits
+type afterCommit struct {
+	funcs []func()
+}
+
+// Transactor runs functions in one transaction
+type Transactor interface {
+	// Transact runs txFunc in one transaction, repositories called with the given context join the transaction
+	Transact(ctx context.Context, txFunc func(context.Context) error) error
+}
+
+// NewTransactor ...
+func NewTransactor(db *sqlx.DB) Transactor {
+	return &transactor{
+		db: db,
+	}
+}
+
+type transactor struct {
+	db *sqlx.DB
+}
+
+func (t *transactor) Transact(ctx context.Context, txFunc func(context.Context) error) error {
+	return Transact(ctx, t.db, txFunc)
+}
+
+// Transact runs txFunc in one transaction, repositories called with the given context join the transaction
+func Transact(ctx context.Context, db *sqlx.DB, txFunc func(context.Context) error) error {
+	return TransactCtx(ctx, db, func(ctx context.Context, _ *sqlx.Tx) error {
+		return txFunc(ctx)
+	})
+}
+
+// TransactCtx is Transactx whose txFunc also gets a context carrying the transaction
+func TransactCtx(ctx context.Context, db *sqlx.DB, txFunc func(context.Context, *sqlx.Tx) error) error {
+	hooks := &afterCommit{}
+	if err := transactx(ctx, db, func(ctx context.Context, tx *sqlx.Tx) error {
+		// joined transactions leave hooks to the outermost one
+		if h, _ := ctx.Value(afterCommitKey{}).(*afterCommit); h == nil {
+			ctx = context.WithValue(ctx, afterCommitKey{}, hooks)
+		}
+		return txFunc(context.WithValue(ctx, txKey{}, tx), tx)
+	}); err != nil {
+		return err
+	}
+
+	for _, f := range hooks.funcs {
+		f()
+	}
+	return nil
+}
+
+// AfterCommit runs f once the transaction carried by context commits, f is dropped if it rollbacks.
+// It runs f at once if context does not carry a transaction
+func AfterCommit(ctx context.Context, f func()) {
+	if h, _ := ctx.Value(afterCommitKey{}).(*afterCommit); h != nil {
+		h.funcs = append(h.funcs, f)
+		return
+	}
+	f()
+}
+
+// WithoutTx returns a context which does not carry the transaction, repositories called with it
+// start their own transaction, e.g. to persist something even though the outer transaction rollbacks
+func WithoutTx(ctx context.Context) context.Context {
+	ctx = context.WithValue(ctx, afterCommitKey{}, (*afterCommit)(nil))
+	return context.WithValue(ctx, txKey{}, nil)
+}
+
+// InTx reports whether context carries a transaction started by Transact
+func InTx(ctx context.Context) bool {
+	_, ok := ctx.Value(txKey{}).(*sqlx.Tx)
+	return ok
+}
+
+// Transactx wraps sqlx trasaction in one function and provide error handling.
+// If context carries a transaction started by Transact, txFunc joins it and the outermost one commits or rollbacks
+func Transactx(ctx context.Context, db *sqlx.DB, txFunc func(*sqlx.Tx) error) error {
+	return transactx(ctx, db, func(_ context.Context, tx *sqlx.Tx) error {
+		return txFunc(tx)
+	})
+}
+
+// transactx is Transactx whose txFunc gets the context of the transaction span
+func transactx(ctx context.Context, db *sqlx.DB, txFunc func(context.Context, *sqlx.Tx) error) (err error) {
+	if tx, ok := ctx.Value(txKey{}).(*sqlx.Tx); ok {
+		return txFunc(ctx, tx)
+	}
+
+	ctx, span := startSpan(ctx)
+	start := time.Now()
+	tx, err := db.BeginTxx(ctx, nil)
 	if err != nil {
+		endSpan(span, resultBeginError, err)
 		return err
 	}
 
@@ -31,16 +124,23 @@ func Transactx(context context.Context, db *sqlx.DB, txFunc func(*sqlx.Tx) error
 		// rollback or commit
 		if err != nil {
 			if e := tx.Rollback(); e != nil {
-				logrus.WithField("err", e).Error("sqlx transaction Rollback() fail")
+				logger.FromContext(ctx).WithField("err", e).Error("sqlx transaction Rollback() fail")
 			}
+			observeTransaction(resultRollback, start)
+			endSpan(span, resultRollback, err)
 			return
 		}
 		err = tx.Commit()
 		if err != nil {
-			logrus.WithField("err", err).Error("sqlx transaction Commit() fail")
+			logger.FromContext(ctx).WithField("err", err).Error("sqlx transaction Commit() fail")
+			observeTransaction(resultCommitError, start)
+			endSpan(span, resultCommitError, err)
+			return
 		}
+		observeTransaction(resultCommit, start)
+		endSpan(span, resultCommit, nil)
 	}()
 
-	err = txFunc(tx)
+	err = txFunc(ctx, tx)
 	return err
 }
@@ -0,0 +1,12 @@
+package sqltest
+
+import (
+	"context"
+)
+
+// Transactor runs the function directly, for tests whose repositories are mocked so there is no real transaction
+type Transactor struct{}
+
+func (Transactor) Transact(ctx context.Context, txFunc func(context.Context) error) error {
+	return txFunc(ctx)
+}
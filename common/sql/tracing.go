@@ -0,0 +1,30 @@
+package sql
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	tracerName = "github.com/n3k0fi5t/wallet/common/sql"
+
+	attrResult = attribute.Key("db.transaction.result")
+)
+
+// startSpan starts the span of the outermost transaction, statements executed with the returned context are its children
+func startSpan(ctx context.Context) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, "sql.Transactx")
+}
+
+func endSpan(span trace.Span, result string, err error) {
+	span.SetAttributes(attrResult.String(result))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
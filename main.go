@@ -4,24 +4,57 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
+	"syscall"
 	"time"
 
 	"github.com/n3k0fi5t/wallet/app/api"
+	"github.com/n3k0fi5t/wallet/app/middleware"
+	"github.com/n3k0fi5t/wallet/app/setup/logger"
+	"github.com/n3k0fi5t/wallet/app/setup/tracing"
 	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
 )
 
 var (
-	wait    = flag.Duration("GRACEFULL_TIMEOUT", 15*time.Second, "the duration for which the server gracefully wait for existing connections to finish")
-	apiPort = os.Getenv("API_PORT")
+	wait     = flag.Duration("GRACEFULL_TIMEOUT", 15*time.Second, "the duration for which the server gracefully wait for existing connections to finish")
+	drain    = flag.Duration("DRAIN_DELAY", 5*time.Second, "the duration for which readiness fails before shutdown, so load balancers stop routing new requests")
+	apiPort  = os.Getenv("API_PORT")
+	grpcPort = os.Getenv("GRPC_PORT")
 )
 
 func main() {
 	flag.Parse()
+	logger.Setup()
 
-	rt := api.BuildRouter()
+	shutdownTracing, err := tracing.Init(context.Background())
+	if err != nil {
+		logrus.WithField("err", err).Fatal("tracing.Init failed")
+	}
+
+	services := api.BuildServices()
+	rt := api.BuildRouter(services)
+	gs := api.BuildGRPCServer(services)
+
+	// relay trade events to downstream, dispatch webhooks, release due escrows and expire intents in background
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	workers := sync.WaitGroup{}
+	for _, run := range []func(context.Context){
+		api.BuildRelay().Run,
+		api.GetWebhookService().Run,
+		services.Escrow.Run,
+		services.Approval.Run,
+	} {
+		workers.Add(1)
+		go func(run func(context.Context)) {
+			defer workers.Done()
+			run(workerCtx)
+		}(run)
+	}
 
 	srv := &http.Server{
 		Addr:         fmt.Sprintf("0.0.0.0:%s", apiPort),
@@ -29,27 +62,68 @@ func main() {
 		ReadTimeout:  time.Second * 15,
 		IdleTimeout:  time.Second * 60,
 		Handler:      rt,
+		// event streams outlive WriteTimeout, they extend the deadline of their connection on each write
+		ConnContext: middleware.ConnContext,
 	}
 
 	// Run our server in a goroutine so that main won't be blocked.
 	go func() {
-		if err := srv.ListenAndServe(); err != nil {
+		// Shutdown makes ListenAndServe return ErrServerClosed, the shutdown sequence goes on in main
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 			logrus.WithField("err", err).Fatal("ListenAndServe failed")
-			panic(err)
+		}
+	}()
+
+	// gRPC API runs on its own port
+	lis, err := net.Listen("tcp", fmt.Sprintf("0.0.0.0:%s", grpcPort))
+	if err != nil {
+		logrus.WithField("err", err).Fatal("net.Listen failed")
+	}
+	go func() {
+		if err := gs.Serve(lis); err != nil && err != grpc.ErrServerStopped {
+			logrus.WithField("err", err).Fatal("grpc Serve failed")
 		}
 	}()
 
 	// Graceful Shutdown
 	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, os.Interrupt)
+	// SIGTERM is how docker and kubernetes stop containers
+	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
 
 	<-quit
 
+	// fail readiness first and give load balancers time to notice
+	services.Health.SetShuttingDown()
+	time.Sleep(*drain)
+
 	// Create a deadline to wait for.
 	ctx, cancel := context.WithTimeout(context.Background(), *wait)
 	defer cancel()
 
+	// end event streams, Shutdown does not interrupt them and clients resume on another replica
+	services.Broker.Close()
 	srv.Shutdown(ctx)
+
+	// GracefulStop waits for pending RPCs, force stop when the deadline exceeds
+	grpcStopped := make(chan struct{})
+	go func() {
+		gs.GracefulStop()
+		close(grpcStopped)
+	}()
+	select {
+	case <-grpcStopped:
+	case <-ctx.Done():
+		gs.Stop()
+	}
+
+	// events and deliveries not sent yet stay in DB for the next start
+	stopWorkers()
+	workers.Wait()
+
+	// flush spans not exported yet
+	if err := shutdownTracing(ctx); err != nil {
+		logrus.WithField("err", err).Error("shutdown tracing failed")
+	}
 	logrus.Info("Service shutdown")
 
 	os.Exit(0)
@@ -1,6 +1,25 @@
+Drop Table If Exists WalletMember;
+Drop Table If Exists ApprovalPolicy;
 Drop Table If Exists account;
 Drop Table If Exists user;
 Drop Table If Exists TransactionLog;
+Drop Table If Exists TradeReversal;
+Drop Table If Exists TradeIdempotency;
+Drop Table If Exists AdminActionLog;
+Drop Table If Exists AuditLog;
+Drop Table If Exists Outbox;
+Drop Table If Exists WebhookEndpoint;
+Drop Table If Exists WebhookDelivery;
+Drop Table If Exists PaymentRequest;
+Drop Table If Exists Bill;
+Drop Table If Exists BillShare;
+Drop Table If Exists Escrow;
+Drop Table If Exists TradeIntent;
+Drop Table If Exists TradeApproval;
+Drop Table If Exists TradeExchange;
+Drop Table If Exists FXQuote;
+Drop Table If Exists FundingTransfer;
+Drop Table If Exists SchemaVersion;
 
 CREATE TABLE IF NOT EXISTS user (
    id INT UNSIGNED NOT NULL AUTO_INCREMENT,
@@ -15,6 +34,7 @@ CREATE TABLE IF NOT EXISTS TransactionLog (
 	accountID varchar(50) NOT NULL,
 	action int(10) NOT NULL DEFAULT 0,
 	amount BIGINT NOT NULL DEFAULT 0,
+	currency char(3) NOT NULL,
 	timestampMS BIGINT NOT NULL,
 	tradeID varchar(50) NOT NULL,
 	PRIMARY KEY (id),
@@ -27,40 +47,436 @@ CREATE TABLE IF NOT EXISTS account (
    id INT UNSIGNED NOT NULL AUTO_INCREMENT,
    accountID varchar(50) NOT NULL UNIQUE,
    balance BIGINT NOT NULL DEFAULT 0,
-   PRIMARY KEY (id)
+   status int(10) NOT NULL DEFAULT 0,
+   type int(10) NOT NULL DEFAULT 0,
+   ownerID varchar(50) NULL,
+   name varchar(50) NOT NULL DEFAULT '',
+   currency char(3) NOT NULL DEFAULT 'USD',
+   creditLimit BIGINT NOT NULL DEFAULT 0,
+   allowNegative BOOLEAN NOT NULL DEFAULT FALSE,
+   PRIMARY KEY (id),
+   UNIQUE KEY ownerName (ownerID, name),
+   KEY balance (balance),
+   CONSTRAINT accountOwner FOREIGN KEY (ownerID) REFERENCES user (userID)
+);
+
+-- no account goes below its credit line whoever writes it, unless it is a system account allowed to go negative.
+-- MySQL 5.7 does not enforce CHECK constraints
+DELIMITER //
+CREATE TRIGGER account_credit_insert BEFORE INSERT ON account FOR EACH ROW
+BEGIN
+	IF NEW.creditLimit < 0 OR (NOT NEW.allowNegative AND NEW.balance < -NEW.creditLimit) THEN
+		SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'balance below credit limit';
+	END IF;
+END//
+CREATE TRIGGER account_credit_update BEFORE UPDATE ON account FOR EACH ROW
+BEGIN
+	IF NEW.creditLimit < 0 OR (NOT NEW.allowNegative AND NEW.balance < -NEW.creditLimit) THEN
+		SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'balance below credit limit';
+	END IF;
+END//
+DELIMITER ;
+
+CREATE TABLE IF NOT EXISTS TradeReversal (
+	id INT UNSIGNED NOT NULL AUTO_INCREMENT,
+	tradeID varchar(50) NOT NULL,
+	reversalTradeID varchar(50) NOT NULL,
+	timestampMS BIGINT NOT NULL,
+	PRIMARY KEY (id),
+	UNIQUE KEY tradeID (tradeID)
+);
+
+CREATE TABLE IF NOT EXISTS TradeIdempotency (
+	id INT UNSIGNED NOT NULL AUTO_INCREMENT,
+	idempotencyKey varchar(255) NOT NULL,
+	tradeID varchar(50) NOT NULL,
+	fromAccountID varchar(50) NOT NULL,
+	toAccountID varchar(50) NOT NULL,
+	amount BIGINT NOT NULL,
+	timestampMS BIGINT NOT NULL,
+	PRIMARY KEY (id),
+	UNIQUE KEY idempotencyKey (idempotencyKey)
+);
+
+CREATE TABLE IF NOT EXISTS AdminActionLog (
+	id INT UNSIGNED NOT NULL AUTO_INCREMENT,
+	operatorID varchar(50) NOT NULL,
+	role varchar(20) NOT NULL,
+	action varchar(20) NOT NULL,
+	accountID varchar(50) NOT NULL,
+	tradeID varchar(50) NOT NULL DEFAULT '',
+	amount BIGINT NOT NULL DEFAULT 0,
+	currency char(3) NOT NULL DEFAULT '',
+	reasonCode varchar(20) NOT NULL,
+	note varchar(255) NOT NULL DEFAULT '',
+	timestampMS BIGINT NOT NULL,
+	PRIMARY KEY (id),
+	KEY operatorID (operatorID),
+	KEY accountID (accountID)
+);
+
+CREATE TABLE IF NOT EXISTS AuditLog (
+	id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+	actorType varchar(10) NOT NULL,
+	actorID varchar(50) NOT NULL DEFAULT '',
+	principal varchar(100) NOT NULL DEFAULT '',
+	requestID varchar(50) NOT NULL DEFAULT '',
+	clientIP varchar(45) NOT NULL DEFAULT '',
+	userAgent varchar(255) NOT NULL DEFAULT '',
+	action varchar(20) NOT NULL,
+	resource varchar(100) NOT NULL,
+	`before` TEXT NOT NULL,
+	`after` TEXT NOT NULL,
+	timestampMS BIGINT NOT NULL,
+	PRIMARY KEY (id),
+	KEY actorID (actorID),
+	KEY resource (resource),
+	KEY action (action),
+	KEY timestampMS (timestampMS)
+);
+
+-- AuditLog is append-only
+CREATE TRIGGER AuditLog_no_update BEFORE UPDATE ON AuditLog FOR EACH ROW SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'AuditLog is append-only';
+CREATE TRIGGER AuditLog_no_delete BEFORE DELETE ON AuditLog FOR EACH ROW SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'AuditLog is append-only';
+
+CREATE TABLE IF NOT EXISTS Outbox (
+	id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+	accountID varchar(50) NOT NULL,
+	payload TEXT NOT NULL,
+	createdMs BIGINT NOT NULL,
+	publishedMs BIGINT NULL DEFAULT NULL,
+	leaseUntilMs BIGINT NOT NULL DEFAULT 0,
+	-- deadLetterMs is set when the payload can not be decoded, the event is not published
+	deadLetterMs BIGINT NULL DEFAULT NULL,
+	PRIMARY KEY (id),
+	KEY publishedMs (publishedMs, id)
+);
+
+CREATE TABLE IF NOT EXISTS WebhookEndpoint (
+	id INT UNSIGNED NOT NULL AUTO_INCREMENT,
+	endpointID varchar(50) NOT NULL,
+	accountID varchar(50) NOT NULL,
+	url varchar(2048) NOT NULL,
+	secret varchar(100) NOT NULL,
+	eventTypes varchar(255) NOT NULL,
+	createdMs BIGINT NOT NULL,
+	PRIMARY KEY (id),
+	UNIQUE KEY endpointID (endpointID),
+	KEY accountID (accountID)
+);
+
+CREATE TABLE IF NOT EXISTS WebhookDelivery (
+	id INT UNSIGNED NOT NULL AUTO_INCREMENT,
+	deliveryID varchar(50) NOT NULL,
+	endpointID varchar(50) NOT NULL,
+	accountID varchar(50) NOT NULL,
+	eventID varchar(50) NOT NULL,
+	eventType varchar(20) NOT NULL,
+	payload TEXT NOT NULL,
+	status varchar(10) NOT NULL,
+	attempts int(10) NOT NULL DEFAULT 0,
+	nextAttemptMs BIGINT NOT NULL,
+	lastStatusCode int(10) NOT NULL DEFAULT 0,
+	lastError varchar(255) NOT NULL DEFAULT '',
+	createdMs BIGINT NOT NULL,
+	updatedMs BIGINT NOT NULL,
+	PRIMARY KEY (id),
+	UNIQUE KEY deliveryID (deliveryID),
+	UNIQUE KEY endpointEvent (endpointID, eventID),
+	KEY due (status, nextAttemptMs),
+	KEY accountEndpoint (accountID, endpointID)
+);
+
+CREATE TABLE IF NOT EXISTS PaymentRequest (
+	id INT UNSIGNED NOT NULL AUTO_INCREMENT,
+	requestID varchar(50) NOT NULL,
+	requesterID varchar(50) NOT NULL,
+	payerID varchar(50) NOT NULL,
+	amount BIGINT NOT NULL,
+	currency char(3) NOT NULL,
+	memo varchar(255) NOT NULL DEFAULT '',
+	status varchar(10) NOT NULL,
+	tradeID varchar(50) NOT NULL DEFAULT '',
+	expiresMs BIGINT NOT NULL,
+	createdMs BIGINT NOT NULL,
+	updatedMs BIGINT NOT NULL,
+	PRIMARY KEY (id),
+	UNIQUE KEY requestID (requestID),
+	KEY requester (requesterID, status),
+	KEY payer (payerID, status)
+);
+
+CREATE TABLE IF NOT EXISTS Bill (
+	id INT UNSIGNED NOT NULL AUTO_INCREMENT,
+	billID varchar(50) NOT NULL,
+	organizerID varchar(50) NOT NULL,
+	total BIGINT NOT NULL,
+	currency char(3) NOT NULL,
+	memo varchar(255) NOT NULL DEFAULT '',
+	splitType varchar(10) NOT NULL,
+	status varchar(10) NOT NULL,
+	createdMs BIGINT NOT NULL,
+	updatedMs BIGINT NOT NULL,
+	PRIMARY KEY (id),
+	UNIQUE KEY billID (billID),
+	KEY organizer (organizerID, status)
+);
+
+CREATE TABLE IF NOT EXISTS BillShare (
+	id INT UNSIGNED NOT NULL AUTO_INCREMENT,
+	billID varchar(50) NOT NULL,
+	participantID varchar(50) NOT NULL,
+	amount BIGINT NOT NULL,
+	currency char(3) NOT NULL,
+	status varchar(10) NOT NULL,
+	tradeID varchar(50) NOT NULL DEFAULT '',
+	reminders INT UNSIGNED NOT NULL DEFAULT 0,
+	remindedMs BIGINT NOT NULL DEFAULT 0,
+	createdMs BIGINT NOT NULL,
+	updatedMs BIGINT NOT NULL,
+	PRIMARY KEY (id),
+	UNIQUE KEY share (billID, participantID),
+	KEY participant (participantID, status)
+);
+
+CREATE TABLE IF NOT EXISTS Escrow (
+	id INT UNSIGNED NOT NULL AUTO_INCREMENT,
+	escrowID varchar(50) NOT NULL,
+	accountID varchar(50) NOT NULL,
+	buyerID varchar(50) NOT NULL,
+	sellerID varchar(50) NOT NULL,
+	amount BIGINT NOT NULL,
+	currency char(3) NOT NULL,
+	memo varchar(255) NOT NULL DEFAULT '',
+	status varchar(10) NOT NULL,
+	fundTradeID varchar(50) NOT NULL,
+	settleTradeID varchar(50) NOT NULL DEFAULT '',
+	disputedBy varchar(50) NOT NULL DEFAULT '',
+	releaseAtMs BIGINT NOT NULL,
+	createdMs BIGINT NOT NULL,
+	updatedMs BIGINT NOT NULL,
+	PRIMARY KEY (id),
+	UNIQUE KEY escrowID (escrowID),
+	KEY buyer (buyerID, status),
+	KEY seller (sellerID, status),
+	KEY due (status, releaseAtMs)
+);
+
+CREATE TABLE IF NOT EXISTS WalletMember (
+	id INT UNSIGNED NOT NULL AUTO_INCREMENT,
+	walletID varchar(50) NOT NULL,
+	userID varchar(50) NOT NULL,
+	role varchar(10) NOT NULL,
+	dailyCap BIGINT NOT NULL DEFAULT 0,
+	currency char(3) NOT NULL,
+	spentToday BIGINT NOT NULL DEFAULT 0,
+	spentDay BIGINT NOT NULL DEFAULT 0,
+	createdMs BIGINT NOT NULL,
+	updatedMs BIGINT NOT NULL,
+	PRIMARY KEY (id),
+	UNIQUE KEY member (walletID, userID),
+	KEY membership (userID),
+	CONSTRAINT memberWallet FOREIGN KEY (walletID) REFERENCES account (accountID),
+	CONSTRAINT memberUser FOREIGN KEY (userID) REFERENCES user (userID)
+);
+
+-- approvers are comma separated user IDs, members of a wallet are at most 11 with the owner
+CREATE TABLE IF NOT EXISTS ApprovalPolicy (
+	id INT UNSIGNED NOT NULL AUTO_INCREMENT,
+	walletID varchar(50) NOT NULL,
+	threshold BIGINT NOT NULL,
+	currency char(3) NOT NULL,
+	required INT UNSIGNED NOT NULL,
+	approvers varchar(1024) NOT NULL,
+	updatedBy varchar(50) NOT NULL,
+	createdMs BIGINT NOT NULL,
+	updatedMs BIGINT NOT NULL,
+	PRIMARY KEY (id),
+	UNIQUE KEY walletID (walletID),
+	CONSTRAINT policyWallet FOREIGN KEY (walletID) REFERENCES account (accountID)
 );
 
--- Pseudo user and account as system account
-INSERT INTO user (userID, fullname) VALUES ('c1e395d9-8c00-4124-819a-85b0402900cf', 'PseudoUser');
-INSERT INTO account (balance, accountID) VALUES (9223372036854775807, 'c1e395d9-8c00-4124-819a-85b0402900cf');
+CREATE TABLE IF NOT EXISTS TradeIntent (
+	id INT UNSIGNED NOT NULL AUTO_INCREMENT,
+	intentID varchar(50) NOT NULL,
+	walletID varchar(50) NOT NULL,
+	requestedBy varchar(50) NOT NULL,
+	toAccountID varchar(50) NOT NULL DEFAULT '',
+	amount BIGINT NOT NULL,
+	currency char(3) NOT NULL,
+	holdAccountID varchar(50) NOT NULL,
+	status varchar(10) NOT NULL,
+	required INT UNSIGNED NOT NULL,
+	approvers varchar(1024) NOT NULL,
+	holdTradeID varchar(50) NOT NULL,
+	settleTradeID varchar(50) NOT NULL DEFAULT '',
+	idempotencyKey varchar(255) NOT NULL DEFAULT '',
+	expiresAtMs BIGINT NOT NULL,
+	createdMs BIGINT NOT NULL,
+	updatedMs BIGINT NOT NULL,
+	PRIMARY KEY (id),
+	UNIQUE KEY intentID (intentID),
+	KEY wallet (walletID, status),
+	KEY expiry (status, expiresAtMs),
+	KEY idempotencyKey (idempotencyKey)
+);
+
+CREATE TABLE IF NOT EXISTS TradeApproval (
+	id INT UNSIGNED NOT NULL AUTO_INCREMENT,
+	intentID varchar(50) NOT NULL,
+	approverID varchar(50) NOT NULL,
+	decision varchar(10) NOT NULL,
+	comment varchar(255) NOT NULL DEFAULT '',
+	createdMs BIGINT NOT NULL,
+	PRIMARY KEY (id),
+	UNIQUE KEY approval (intentID, approverID)
+);
+
+-- the rate and spread a multi-leg exchange trade was booked at
+CREATE TABLE IF NOT EXISTS TradeExchange (
+	id INT UNSIGNED NOT NULL AUTO_INCREMENT,
+	tradeID varchar(50) NOT NULL,
+	quoteID varchar(50) NOT NULL,
+	fromCurrency char(3) NOT NULL,
+	toCurrency char(3) NOT NULL,
+	sellAmount BIGINT NOT NULL,
+	buyAmount BIGINT NOT NULL,
+	rate varchar(40) NOT NULL,
+	spreadBps INT UNSIGNED NOT NULL,
+	timestampMs BIGINT NOT NULL,
+	PRIMARY KEY (id),
+	UNIQUE KEY tradeID (tradeID),
+	UNIQUE KEY quoteID (quoteID)
+);
+
+CREATE TABLE IF NOT EXISTS FXQuote (
+	id INT UNSIGNED NOT NULL AUTO_INCREMENT,
+	quoteID varchar(50) NOT NULL,
+	userID varchar(50) NOT NULL,
+	fromWalletID varchar(50) NOT NULL,
+	toWalletID varchar(50) NOT NULL,
+	fromCurrency char(3) NOT NULL,
+	toCurrency char(3) NOT NULL,
+	sellAmount BIGINT NOT NULL,
+	buyAmount BIGINT NOT NULL,
+	rate varchar(40) NOT NULL,
+	spreadBps INT UNSIGNED NOT NULL,
+	status varchar(10) NOT NULL,
+	tradeID varchar(50) NOT NULL DEFAULT '',
+	expiresAtMs BIGINT NOT NULL,
+	createdMs BIGINT NOT NULL,
+	updatedMs BIGINT NOT NULL,
+	PRIMARY KEY (id),
+	UNIQUE KEY quoteID (quoteID),
+	KEY user (userID)
+);
+
+CREATE TABLE IF NOT EXISTS FundingTransfer (
+	id INT UNSIGNED NOT NULL AUTO_INCREMENT,
+	transferID varchar(50) NOT NULL,
+	walletID varchar(50) NOT NULL,
+	userID varchar(50) NOT NULL,
+	direction varchar(10) NOT NULL,
+	rail varchar(20) NOT NULL,
+	amount BIGINT NOT NULL,
+	currency char(3) NOT NULL,
+	status varchar(10) NOT NULL,
+	reference varchar(100) NOT NULL DEFAULT '',
+	failureReason varchar(255) NOT NULL DEFAULT '',
+	clearTradeID varchar(50) NOT NULL DEFAULT '',
+	settleTradeID varchar(50) NOT NULL DEFAULT '',
+	returnTradeID varchar(50) NOT NULL DEFAULT '',
+	refundTradeID varchar(50) NOT NULL DEFAULT '',
+	shortfallTradeID varchar(50) NOT NULL DEFAULT '',
+	shortfallAmount BIGINT NOT NULL DEFAULT 0,
+	createdMs BIGINT NOT NULL,
+	updatedMs BIGINT NOT NULL,
+	PRIMARY KEY (id),
+	UNIQUE KEY transferID (transferID),
+	KEY walletID (walletID)
+);
+
+-- bump the version with SchemaVersion in app/setup/mysql whenever the schema changes
+CREATE TABLE IF NOT EXISTS SchemaVersion (
+	version INT UNSIGNED NOT NULL,
+	appliedMs BIGINT NOT NULL,
+	PRIMARY KEY (version)
+);
+INSERT INTO SchemaVersion (version, appliedMs) VALUES (16, UNIX_TIMESTAMP() * 1000);
+
+-- Chart of system accounts per currency, all balances of a currency add up to zero.
+-- Funding accounts of rails and suspense and write-off may go negative by policy, fee revenue may not
+INSERT INTO account (balance, accountID, type, currency, allowNegative) VALUES (0, 'sys-funding-direct-USD', 4, 'USD', TRUE);
+INSERT INTO account (balance, accountID, type, currency, allowNegative) VALUES (0, 'sys-funding-direct-EUR', 4, 'EUR', TRUE);
+INSERT INTO account (balance, accountID, type, currency, allowNegative) VALUES (0, 'sys-funding-direct-GBP', 4, 'GBP', TRUE);
+INSERT INTO account (balance, accountID, type, currency, allowNegative) VALUES (0, 'sys-funding-direct-TWD', 4, 'TWD', TRUE);
+INSERT INTO account (balance, accountID, type, currency, allowNegative) VALUES (0, 'sys-funding-direct-JPY', 4, 'JPY', TRUE);
+
+INSERT INTO account (balance, accountID, type, currency, allowNegative) VALUES (0, 'sys-funding-simulator-USD', 4, 'USD', TRUE);
+INSERT INTO account (balance, accountID, type, currency, allowNegative) VALUES (0, 'sys-funding-simulator-EUR', 4, 'EUR', TRUE);
+INSERT INTO account (balance, accountID, type, currency, allowNegative) VALUES (0, 'sys-funding-simulator-GBP', 4, 'GBP', TRUE);
+INSERT INTO account (balance, accountID, type, currency, allowNegative) VALUES (0, 'sys-funding-simulator-TWD', 4, 'TWD', TRUE);
+INSERT INTO account (balance, accountID, type, currency, allowNegative) VALUES (0, 'sys-funding-simulator-JPY', 4, 'JPY', TRUE);
+
+-- clearing accounts hold money in flight on a rail, they net to zero once transfers end and never go negative
+INSERT INTO account (balance, accountID, type, currency, allowNegative) VALUES (0, 'sys-clearing-simulator-USD', 4, 'USD', FALSE);
+INSERT INTO account (balance, accountID, type, currency, allowNegative) VALUES (0, 'sys-clearing-simulator-EUR', 4, 'EUR', FALSE);
+INSERT INTO account (balance, accountID, type, currency, allowNegative) VALUES (0, 'sys-clearing-simulator-GBP', 4, 'GBP', FALSE);
+INSERT INTO account (balance, accountID, type, currency, allowNegative) VALUES (0, 'sys-clearing-simulator-TWD', 4, 'TWD', FALSE);
+INSERT INTO account (balance, accountID, type, currency, allowNegative) VALUES (0, 'sys-clearing-simulator-JPY', 4, 'JPY', FALSE);
+
+INSERT INTO account (balance, accountID, type, currency, allowNegative) VALUES (0, 'sys-fee-USD', 4, 'USD', FALSE);
+INSERT INTO account (balance, accountID, type, currency, allowNegative) VALUES (0, 'sys-fee-EUR', 4, 'EUR', FALSE);
+INSERT INTO account (balance, accountID, type, currency, allowNegative) VALUES (0, 'sys-fee-GBP', 4, 'GBP', FALSE);
+INSERT INTO account (balance, accountID, type, currency, allowNegative) VALUES (0, 'sys-fee-TWD', 4, 'TWD', FALSE);
+INSERT INTO account (balance, accountID, type, currency, allowNegative) VALUES (0, 'sys-fee-JPY', 4, 'JPY', FALSE);
+
+INSERT INTO account (balance, accountID, type, currency, allowNegative) VALUES (0, 'sys-suspense-USD', 4, 'USD', TRUE);
+INSERT INTO account (balance, accountID, type, currency, allowNegative) VALUES (0, 'sys-suspense-EUR', 4, 'EUR', TRUE);
+INSERT INTO account (balance, accountID, type, currency, allowNegative) VALUES (0, 'sys-suspense-GBP', 4, 'GBP', TRUE);
+INSERT INTO account (balance, accountID, type, currency, allowNegative) VALUES (0, 'sys-suspense-TWD', 4, 'TWD', TRUE);
+INSERT INTO account (balance, accountID, type, currency, allowNegative) VALUES (0, 'sys-suspense-JPY', 4, 'JPY', TRUE);
+
+INSERT INTO account (balance, accountID, type, currency, allowNegative) VALUES (0, 'sys-write-off-USD', 4, 'USD', TRUE);
+INSERT INTO account (balance, accountID, type, currency, allowNegative) VALUES (0, 'sys-write-off-EUR', 4, 'EUR', TRUE);
+INSERT INTO account (balance, accountID, type, currency, allowNegative) VALUES (0, 'sys-write-off-GBP', 4, 'GBP', TRUE);
+INSERT INTO account (balance, accountID, type, currency, allowNegative) VALUES (0, 'sys-write-off-TWD', 4, 'TWD', TRUE);
+INSERT INTO account (balance, accountID, type, currency, allowNegative) VALUES (0, 'sys-write-off-JPY', 4, 'JPY', TRUE);
+
+-- FX accounts of supported currencies, the treasury funds them through admin adjustments from suspense
+INSERT INTO account (balance, accountID, type, currency) VALUES (0, 'fx-USD', 3, 'USD');
+INSERT INTO account (balance, accountID, type, currency) VALUES (0, 'fx-EUR', 3, 'EUR');
+INSERT INTO account (balance, accountID, type, currency) VALUES (0, 'fx-GBP', 3, 'GBP');
+INSERT INTO account (balance, accountID, type, currency) VALUES (0, 'fx-TWD', 3, 'TWD');
+INSERT INTO account (balance, accountID, type, currency) VALUES (0, 'fx-JPY', 3, 'JPY');
 
--- Seed users and accounts
+-- Seed users and their default wallets
 INSERT INTO user (userID, fullname) VALUES ('935f871a-660f-4f19-801e-916c04bb0324', 'Tim');
-INSERT INTO account (balance, accountID) VALUES (0, '935f871a-660f-4f19-801e-916c04bb0324');
+INSERT INTO account (balance, accountID, ownerID, name) VALUES (0, '935f871a-660f-4f19-801e-916c04bb0324', '935f871a-660f-4f19-801e-916c04bb0324', 'Main');
 
 INSERT INTO user (userID, fullname) VALUES ('a89b7b78-b9c1-4129-8cff-380bf53f3a49', 'Alex');
-INSERT INTO account (balance, accountID) VALUES (0, 'a89b7b78-b9c1-4129-8cff-380bf53f3a49');
+INSERT INTO account (balance, accountID, ownerID, name) VALUES (0, 'a89b7b78-b9c1-4129-8cff-380bf53f3a49', 'a89b7b78-b9c1-4129-8cff-380bf53f3a49', 'Main');
 
 INSERT INTO user (userID, fullname) VALUES ('a98cd0f5-d6b2-4899-a1fb-ddf308d6f5c8', 'Arthur');
-INSERT INTO account (balance, accountID) VALUES (0, 'a98cd0f5-d6b2-4899-a1fb-ddf308d6f5c8');
+INSERT INTO account (balance, accountID, ownerID, name) VALUES (0, 'a98cd0f5-d6b2-4899-a1fb-ddf308d6f5c8', 'a98cd0f5-d6b2-4899-a1fb-ddf308d6f5c8', 'Main');
 
 INSERT INTO user (userID, fullname) VALUES ('a679ac51-08e8-45c7-80d7-019bf9dad64b', 'Ray');
-INSERT INTO account (balance, accountID) VALUES (0, 'a679ac51-08e8-45c7-80d7-019bf9dad64b');
+INSERT INTO account (balance, accountID, ownerID, name) VALUES (0, 'a679ac51-08e8-45c7-80d7-019bf9dad64b', 'a679ac51-08e8-45c7-80d7-019bf9dad64b', 'Main');
 
 INSERT INTO user (userID, fullname) VALUES ('55b36756-6089-4756-bbd2-b0f66e50ee07', 'HD');
-INSERT INTO account (balance, accountID) VALUES (0, '55b36756-6089-4756-bbd2-b0f66e50ee07');
+INSERT INTO account (balance, accountID, ownerID, name) VALUES (0, '55b36756-6089-4756-bbd2-b0f66e50ee07', '55b36756-6089-4756-bbd2-b0f66e50ee07', 'Main');
 
 INSERT INTO user (userID, fullname) VALUES ('5a1e760e-76ea-4709-98ba-e1a701a4d340', 'peko');
-INSERT INTO account (balance, accountID) VALUES (0, '5a1e760e-76ea-4709-98ba-e1a701a4d340');
+INSERT INTO account (balance, accountID, ownerID, name) VALUES (0, '5a1e760e-76ea-4709-98ba-e1a701a4d340', '5a1e760e-76ea-4709-98ba-e1a701a4d340', 'Main');
 
 INSERT INTO user (userID, fullname) VALUES ('201bef83-cc46-4acb-9c25-2eef60a59a9a', 'miko');
-INSERT INTO account (balance, accountID) VALUES (0, '201bef83-cc46-4acb-9c25-2eef60a59a9a');
+INSERT INTO account (balance, accountID, ownerID, name) VALUES (0, '201bef83-cc46-4acb-9c25-2eef60a59a9a', '201bef83-cc46-4acb-9c25-2eef60a59a9a', 'Main');
 
 INSERT INTO user (userID, fullname) VALUES ('1c3e7209-fb42-4643-bfa6-c6a3fb42bf92', 'rushia');
-INSERT INTO account (balance, accountID) VALUES (0, '1c3e7209-fb42-4643-bfa6-c6a3fb42bf92');
+INSERT INTO account (balance, accountID, ownerID, name) VALUES (0, '1c3e7209-fb42-4643-bfa6-c6a3fb42bf92', '1c3e7209-fb42-4643-bfa6-c6a3fb42bf92', 'Main');
 
 INSERT INTO user (userID, fullname) VALUES ('084e135f-78c7-406e-a347-94e38fa55b60', 'gura');
-INSERT INTO account (balance, accountID) VALUES (0, '084e135f-78c7-406e-a347-94e38fa55b60');
+INSERT INTO account (balance, accountID, ownerID, name) VALUES (0, '084e135f-78c7-406e-a347-94e38fa55b60', '084e135f-78c7-406e-a347-94e38fa55b60', 'Main');
 
 INSERT INTO user (userID, fullname) VALUES ('8a180d2b-0965-4095-ba17-a880d196f04d', 'Ame');
-INSERT INTO account (balance, accountID) VALUES (0, '8a180d2b-0965-4095-ba17-a880d196f04d');
+INSERT INTO account (balance, accountID, ownerID, name) VALUES (0, '8a180d2b-0965-4095-ba17-a880d196f04d', '8a180d2b-0965-4095-ba17-a880d196f04d', 'Main');